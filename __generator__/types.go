@@ -48,11 +48,13 @@ type Spec struct {
 }
 
 type Definition struct {
-	Get   string   `yaml:"get"`
-	Set   string   `yaml:"set"`
-	Unset bool     `yaml:"unset"`
-	On    []string `yaml:"on"`
-	Ref   string   `yaml:"reference"`
+	Get     string   `yaml:"get"`
+	Set     string   `yaml:"set"`
+	Unset   bool     `yaml:"unset"`
+	On      []string `yaml:"on"`
+	SetOn   []string `yaml:"set_on"`
+	UnsetOn []string `yaml:"unset_on"`
+	Ref     string   `yaml:"reference"`
 }
 
 func (d *Definition) String() string {
@@ -71,6 +73,12 @@ func (d *Definition) String() string {
 	}
 	buf.WriteString(fmt.Sprintf("Unset: %t,\n", d.Unset))
 	buf.WriteString(fmt.Sprintf("Scopes: %s,\n", strings.Join(d.On, "|")))
+	if len(d.SetOn) > 0 {
+		buf.WriteString(fmt.Sprintf("SetScopes: %s,\n", strings.Join(d.SetOn, "|")))
+	}
+	if len(d.UnsetOn) > 0 {
+		buf.WriteString(fmt.Sprintf("UnsetScopes: %s,\n", strings.Join(d.UnsetOn, "|")))
+	}
 	buf.WriteString(fmt.Sprintf(`Reference: "%s"`+",\n", d.Ref))
 	buf.WriteString("},\n")
 	return buf.String()
@@ -9,6 +9,11 @@ import (
 type Node interface {
 	String() string
 	GetMeta() *Meta
+
+	// Comment accessors, all nodes have these accessors through Meta
+	LeadingComments() Comments
+	TrailingComments() Comments
+	InfixComments() Comments
 }
 
 type Statement interface {
@@ -34,6 +39,21 @@ type Meta struct {
 	Nest     int
 }
 
+// LeadingComments returns comments placed before the node
+func (m *Meta) LeadingComments() Comments {
+	return m.Leading
+}
+
+// TrailingComments returns comments placed after the node on the same line
+func (m *Meta) TrailingComments() Comments {
+	return m.Trailing
+}
+
+// InfixComments returns comments placed inside the node, e.g. before the closing brace
+func (m *Meta) InfixComments() Comments {
+	return m.Infix
+}
+
 func (m *Meta) LeadingComment() string {
 	if len(m.Leading) == 0 {
 		return ""
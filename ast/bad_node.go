@@ -0,0 +1,56 @@
+package ast
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/ysugimoto/falco/token"
+)
+
+// BadStatement is a placeholder of unparseable statement which is emitted in recovery mode.
+// Tokens hold all tokens of the broken range so that formatter could output them as they are.
+type BadStatement struct {
+	*Meta
+	Tokens []token.Token
+}
+
+func (b *BadStatement) statement()     {}
+func (b *BadStatement) GetMeta() *Meta { return b.Meta }
+func (b *BadStatement) String() string {
+	var buf bytes.Buffer
+
+	buf.WriteString(b.LeadingComment())
+	buf.WriteString(indent(b.Nest) + tokensString(b.Tokens))
+	buf.WriteString(b.TrailingComment())
+	buf.WriteString("\n")
+
+	return buf.String()
+}
+
+// BadExpression is a placeholder of unparseable expression which is emitted in recovery mode
+type BadExpression struct {
+	*Meta
+	Tokens []token.Token
+}
+
+func (b *BadExpression) expression()    {}
+func (b *BadExpression) GetMeta() *Meta { return b.Meta }
+func (b *BadExpression) String() string {
+	return b.LeadingInlineComment() + tokensString(b.Tokens) + b.TrailingComment()
+}
+
+func tokensString(tokens []token.Token) string {
+	literals := make([]string, len(tokens))
+	for i, t := range tokens {
+		switch {
+		case t.Type == token.STRING && t.Offset == 4:
+			literals[i] = fmt.Sprintf(`{"%s"}`, t.Literal)
+		case t.Type == token.STRING && t.Offset == 2:
+			literals[i] = fmt.Sprintf(`"%s"`, t.Literal)
+		default:
+			literals[i] = t.Literal
+		}
+	}
+	return strings.Join(literals, " ")
+}
@@ -0,0 +1,119 @@
+package ast
+
+import (
+	"reflect"
+
+	"github.com/ysugimoto/falco/token"
+)
+
+// CloneOption modifies tokens of cloned node
+type CloneOption func(t *token.Token)
+
+// RemapFile changes file origin of all tokens in cloned node
+func RemapFile(file string) CloneOption {
+	return func(t *token.Token) {
+		t.File = file
+	}
+}
+
+// ShiftLine moves line number of all tokens in cloned node
+func ShiftLine(offset int) CloneOption {
+	return func(t *token.Token) {
+		t.Line += offset
+	}
+}
+
+var tokenType = reflect.TypeOf(token.Token{})
+
+// Clone returns deep copy of the node.
+// Shared pointers in the original tree are also shared in the cloned tree,
+// and all tokens including comment tokens are remapped by provided options.
+// This is useful when the same snippet is instantiated multiple times, e.g. include expansion,
+// and each instance needs to carry distinct positions.
+// Note that unexported fields could not be copied via reflection so they are left as zero value in the cloned node.
+func Clone[T Node](node T, opts ...CloneOption) T {
+	c := &cloner{
+		opts:    opts,
+		visited: make(map[visitKey]reflect.Value),
+	}
+	v := reflect.ValueOf(node)
+	if !v.IsValid() {
+		return node
+	}
+	return c.clone(v).Interface().(T) // nolint:forcetypeassert
+}
+
+// visitKey identifies the pointer which has already been cloned.
+// Pointer type is also needed because a pointer to the struct and its first field have the same address
+type visitKey struct {
+	ptr uintptr
+	typ reflect.Type
+}
+
+type cloner struct {
+	opts    []CloneOption
+	visited map[visitKey]reflect.Value
+}
+
+func (c *cloner) clone(v reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return v
+		}
+		key := visitKey{ptr: v.Pointer(), typ: v.Type()}
+		if cloned, ok := c.visited[key]; ok {
+			return cloned
+		}
+		cloned := reflect.New(v.Elem().Type())
+		c.visited[key] = cloned
+		cloned.Elem().Set(c.clone(v.Elem()))
+		return cloned
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+		cloned := reflect.New(v.Type()).Elem()
+		cloned.Set(c.clone(v.Elem()))
+		return cloned
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		cloned := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			cloned.Index(i).Set(c.clone(v.Index(i)))
+		}
+		return cloned
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+		cloned := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			cloned.SetMapIndex(iter.Key(), c.clone(iter.Value()))
+		}
+		return cloned
+	case reflect.Struct:
+		cloned := reflect.New(v.Type()).Elem()
+		if v.Type() == tokenType {
+			t := v.Interface().(token.Token) // nolint:forcetypeassert
+			for i := range c.opts {
+				c.opts[i](&t)
+			}
+			cloned.Set(reflect.ValueOf(t))
+			return cloned
+		}
+		for i := 0; i < v.NumField(); i++ {
+			// Unexported field could not be copied via reflection
+			if !cloned.Field(i).CanSet() {
+				continue
+			}
+			cloned.Field(i).Set(c.clone(v.Field(i)))
+		}
+		return cloned
+	default:
+		return v
+	}
+}
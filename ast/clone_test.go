@@ -0,0 +1,75 @@
+package ast
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/ysugimoto/falco/token"
+)
+
+func TestClone(t *testing.T) {
+	tok := token.Token{Type: token.SET, Literal: "set", Line: 2, Position: 3, File: "snippet.vcl"}
+	stmt := &SetStatement{
+		Meta: New(tok, 1, Comments{
+			&Comment{Token: tok, Value: "// comment"},
+		}),
+		Ident: &Ident{
+			Meta:  New(tok, 1),
+			Value: "req.http.Foo",
+		},
+		Operator: &Operator{
+			Meta:     New(tok, 1),
+			Operator: "=",
+		},
+		Value: &String{
+			Meta:  New(tok, 1),
+			Value: "bar",
+		},
+	}
+
+	cloned := Clone(stmt, RemapFile("included.vcl"), ShiftLine(10))
+
+	if cloned == stmt || cloned.Meta == stmt.Meta || cloned.Ident == stmt.Ident {
+		t.Errorf("Cloned node must not share pointers with the original")
+	}
+	if cloned.String() != stmt.String() {
+		t.Errorf("Cloned node mismatch, expect=%s, actual=%s", stmt.String(), cloned.String())
+	}
+	if cloned.Token.File != "included.vcl" || cloned.Token.Line != 12 {
+		t.Errorf("Token is not remapped: %s", cloned.Token.String())
+	}
+	if c := cloned.Leading[0].Token; c.File != "included.vcl" || c.Line != 12 {
+		t.Errorf("Comment token is not remapped: %s", c.String())
+	}
+	if v := cloned.Value.GetMeta().Token; v.File != "included.vcl" {
+		t.Errorf("Expression token is not remapped: %s", v.String())
+	}
+	if stmt.Token.File != "snippet.vcl" || stmt.Token.Line != 2 {
+		t.Errorf("Original token must not be changed: %s", stmt.Token.String())
+	}
+}
+
+func TestCloneSameAddressDifferentType(t *testing.T) {
+	type inner struct {
+		Value string
+	}
+	type outer struct {
+		Inner    inner
+		Self     *outer
+		InnerRef *inner
+	}
+	o := &outer{Inner: inner{Value: "foo"}}
+	o.Self = o
+	// Pointer to the first field has the same address as the struct
+	o.InnerRef = &o.Inner
+
+	c := &cloner{visited: make(map[visitKey]reflect.Value)}
+	cloned := c.clone(reflect.ValueOf(o)).Interface().(*outer) // nolint:forcetypeassert
+
+	if cloned == o || cloned.Self != cloned {
+		t.Errorf("Shared pointer must be shared in the cloned tree")
+	}
+	if cloned.InnerRef == nil || cloned.InnerRef == o.InnerRef || cloned.InnerRef.Value != "foo" {
+		t.Errorf("Pointer to the first field must be cloned as its own type")
+	}
+}
@@ -0,0 +1,24 @@
+package ast
+
+// Fastly condition types which are configured via UI, API or terraform
+// https://docs.fastly.com/en/guides/using-conditions
+const (
+	RequestCondition  = "REQUEST"
+	CacheCondition    = "CACHE"
+	ResponseCondition = "RESPONSE"
+	PrefetchCondition = "PREFETCH"
+)
+
+// Condition is a root node of standalone condition expression.
+// Condition does not appear in VCL file, it is made from condition string like `req.url ~ "^/foo"`.
+type Condition struct {
+	*Meta
+	Type       string
+	Expression Expression
+}
+
+func (c *Condition) expression()    {}
+func (c *Condition) GetMeta() *Meta { return c.Meta }
+func (c *Condition) String() string {
+	return c.Expression.String()
+}
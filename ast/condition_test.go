@@ -0,0 +1,29 @@
+package ast
+
+import (
+	"testing"
+)
+
+func TestCondition(t *testing.T) {
+	cond := &Condition{
+		Meta: New(T, 0),
+		Type: RequestCondition,
+		Expression: &InfixExpression{
+			Meta:     New(T, 0),
+			Operator: "~",
+			Left: &Ident{
+				Meta:  New(T, 0),
+				Value: "req.url",
+			},
+			Right: &String{
+				Meta:  New(T, 0),
+				Value: "^/foo",
+			},
+		},
+	}
+
+	expect := `(req.url ~ "^/foo")`
+	if cond.String() != expect {
+		t.Errorf("stringer error.\nexpect:\n%s\nactual:\n%s\n", expect, cond.String())
+	}
+}
@@ -8,6 +8,8 @@ type DeclareStatement struct {
 	*Meta
 	Name      *Ident
 	ValueType *Ident
+	// UnknownType is set when the type is not a known VCL type, holds raw type name
+	UnknownType *UnknownType
 }
 
 func (d *DeclareStatement) statement()     {}
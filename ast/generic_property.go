@@ -0,0 +1,30 @@
+package ast
+
+import (
+	"bytes"
+)
+
+// GenericProperty represents unknown declaration property like ".foo = bar;"
+// which is parsed in tolerant mode to keep forward compatibility with new platform features.
+type GenericProperty struct {
+	*Meta
+	Key   *Ident
+	Value Expression
+}
+
+func (g *GenericProperty) statement()     {}
+func (g *GenericProperty) expression()    {}
+func (g *GenericProperty) GetMeta() *Meta { return g.Meta }
+func (g *GenericProperty) String() string {
+	var buf bytes.Buffer
+
+	buf.WriteString(g.LeadingComment())
+	buf.WriteString(indent(g.Nest) + "." + g.Key.String())
+	buf.WriteString(" = ")
+	buf.WriteString(g.Value.String())
+	buf.WriteString(";")
+	buf.WriteString(g.TrailingComment())
+	buf.WriteString("\n")
+
+	return buf.String()
+}
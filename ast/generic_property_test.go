@@ -0,0 +1,27 @@
+package ast
+
+import (
+	"testing"
+)
+
+func TestGenericProperty(t *testing.T) {
+	prop := &GenericProperty{
+		Meta: New(T, 1, comments("// This is comment"), comments("/* This is comment */")),
+		Key: &Ident{
+			Meta:  New(T, 1),
+			Value: "new_field",
+		},
+		Value: &Integer{
+			Meta:  New(T, 1),
+			Value: 10,
+		},
+	}
+
+	expect := `  // This is comment
+  .new_field = 10; /* This is comment */
+`
+
+	if prop.String() != expect {
+		t.Errorf("stringer error.\nexpect:\n%s\nactual:\n%s\n", expect, prop.String())
+	}
+}
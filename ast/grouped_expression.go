@@ -17,6 +17,7 @@ func (g *GroupedExpression) String() string {
 	buf.WriteString("(")
 	buf.WriteString(g.Right.String())
 	buf.WriteString(")")
+	buf.WriteString(g.TrailingComment())
 
 	return buf.String()
 }
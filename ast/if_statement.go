@@ -57,3 +57,51 @@ func (i *IfStatement) alternativeComments() string {
 
 	return buf.String()
 }
+
+// IfBranch is a branch of flattened if statement chain
+type IfBranch struct {
+	// Condition is nil for the last "else" branch
+	Condition   Expression
+	Consequence *BlockStatement
+	// Leading comments of the branch, e.g. comments before "else if" or "else"
+	Leading Comments
+}
+
+// Branches returns if / else if / else chain as a flat list of branches in evaluation order.
+// Nested form which else block only contains an if statement, like:
+//
+//	if (a) { ... } else { if (b) { ... } else { ... } }
+//
+// is also flattened as same as "if (a) { ... } else if (b) { ... } else { ... }".
+func (i *IfStatement) Branches() []*IfBranch {
+	branches := []*IfBranch{
+		{
+			Condition:   i.Condition,
+			Consequence: i.Consequence,
+			Leading:     i.Leading,
+		},
+	}
+	for _, a := range i.Another {
+		branches = append(branches, &IfBranch{
+			Condition:   a.Condition,
+			Consequence: a.Consequence,
+			Leading:     a.Leading,
+		})
+	}
+	if i.Alternative == nil {
+		return branches
+	}
+
+	// Flatten only when the block has no other statements and comments to keep the source meaning
+	if len(i.Alternative.Statements) == 1 && len(i.Alternative.Infix) == 0 {
+		if nested, ok := i.Alternative.Statements[0].(*IfStatement); ok {
+			nestedBranches := nested.Branches()
+			nestedBranches[0].Leading = append(append(Comments{}, i.AlternativeComments...), nestedBranches[0].Leading...)
+			return append(branches, nestedBranches...)
+		}
+	}
+	return append(branches, &IfBranch{
+		Consequence: i.Alternative,
+		Leading:     i.AlternativeComments,
+	})
+}
@@ -72,3 +72,58 @@ else {
 		t.Errorf("stringer error.\nexpect:\n%s\nactual:\n%s\n", expect, ifs.String())
 	}
 }
+
+func TestIfStatementBranches(t *testing.T) {
+	ident := func(v string) *Ident {
+		return &Ident{Meta: New(T, 0), Value: v}
+	}
+	block := func() *BlockStatement {
+		return &BlockStatement{Meta: New(T, 1), Statements: []Statement{}}
+	}
+
+	t.Run("else if chain", func(t *testing.T) {
+		ifs := &IfStatement{
+			Meta:        New(T, 0),
+			Condition:   ident("a"),
+			Consequence: block(),
+			Another: []*IfStatement{
+				{Meta: New(T, 0), Condition: ident("b"), Consequence: block()},
+				{Meta: New(T, 0), Condition: ident("c"), Consequence: block()},
+			},
+			Alternative: block(),
+		}
+		branches := ifs.Branches()
+		if len(branches) != 4 {
+			t.Fatalf("Expected 4 branches, got %d", len(branches))
+		}
+		if branches[2].Condition.String() != "c" || branches[3].Condition != nil {
+			t.Errorf("Unexpected branch order")
+		}
+	})
+
+	t.Run("nested if in else block", func(t *testing.T) {
+		ifs := &IfStatement{
+			Meta:        New(T, 0),
+			Condition:   ident("a"),
+			Consequence: block(),
+			Alternative: &BlockStatement{
+				Meta: New(T, 1),
+				Statements: []Statement{
+					&IfStatement{
+						Meta:        New(T, 1),
+						Condition:   ident("b"),
+						Consequence: block(),
+						Alternative: block(),
+					},
+				},
+			},
+		}
+		branches := ifs.Branches()
+		if len(branches) != 3 {
+			t.Fatalf("Expected 3 branches, got %d", len(branches))
+		}
+		if branches[1].Condition.String() != "b" || branches[2].Condition != nil {
+			t.Errorf("Nested if statement must be flattened")
+		}
+	})
+}
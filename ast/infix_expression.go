@@ -9,6 +9,9 @@ type InfixExpression struct {
 	Left     Expression
 	Operator string
 	Right    Expression
+	// Implicit is true when string concatenation is written without "+" operator like "a" "b".
+	// Operator is always "+" for string concatenation, so use this field to know how it was written.
+	Implicit bool
 }
 
 func (i *InfixExpression) expression()    {}
@@ -18,9 +21,14 @@ func (i *InfixExpression) String() string {
 
 	buf.WriteString("(")
 	buf.WriteString(i.Left.String())
-	buf.WriteString(" " + i.Operator + " ")
+	if i.Implicit {
+		buf.WriteString(" ")
+	} else {
+		buf.WriteString(" " + i.Operator + " ")
+	}
 	buf.WriteString(i.Right.String())
 	buf.WriteString(")")
+	buf.WriteString(i.TrailingComment())
 
 	return buf.String()
 }
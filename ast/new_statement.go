@@ -0,0 +1,26 @@
+package ast
+
+import (
+	"bytes"
+)
+
+// NewStatement represents Varnish VMOD object instantiation like "new rr = directors.round_robin();"
+type NewStatement struct {
+	*Meta
+	Name  *Ident
+	Value Expression
+}
+
+func (n *NewStatement) statement()     {}
+func (n *NewStatement) GetMeta() *Meta { return n.Meta }
+func (n *NewStatement) String() string {
+	var buf bytes.Buffer
+
+	buf.WriteString(n.LeadingComment())
+	buf.WriteString(indent(n.Nest) + "new " + n.Name.String())
+	buf.WriteString(" = " + n.Value.String() + ";")
+	buf.WriteString(n.TrailingComment())
+	buf.WriteString("\n")
+
+	return buf.String()
+}
@@ -0,0 +1,31 @@
+package ast
+
+import (
+	"testing"
+)
+
+func TestNewStatement(t *testing.T) {
+	ns := &NewStatement{
+		Meta: New(T, 1, comments("// This is comment"), comments("// This is comment")),
+		Name: &Ident{
+			Meta:  New(T, 1),
+			Value: "rr",
+		},
+		Value: &FunctionCallExpression{
+			Meta: New(T, 1),
+			Function: &Ident{
+				Meta:  New(T, 1),
+				Value: "directors.round_robin",
+			},
+			Arguments: []Expression{},
+		},
+	}
+
+	expect := `  // This is comment
+  new rr = directors.round_robin(); // This is comment
+`
+
+	if ns.String() != expect {
+		t.Errorf("stringer error.\nexpect:\n%s\nactual:\n%s\n", expect, ns.String())
+	}
+}
@@ -0,0 +1,81 @@
+package ast
+
+import (
+	"fmt"
+
+	"github.com/ysugimoto/falco/token"
+)
+
+// SourceLocation indicates the position in the original source file
+type SourceLocation struct {
+	File     string
+	Line     int
+	Position int
+}
+
+func (s SourceLocation) String() string {
+	file := s.File
+	if file == "" {
+		file = "<main>"
+	}
+	return fmt.Sprintf("%s:%d:%d", file, s.Line, s.Position)
+}
+
+// SourceMap records which include statement expanded each file.
+// After include statements are flattened into the single statement list,
+// any token in the combined AST can be traced back through the include chain to the main VCL.
+type SourceMap struct {
+	includes map[string]*IncludeStatement
+}
+
+func NewSourceMap() *SourceMap {
+	return &SourceMap{
+		includes: make(map[string]*IncludeStatement),
+	}
+}
+
+// Add records that the file is expanded by the include statement.
+// When the same file is included multiple times, the first inclusion is kept.
+func (s *SourceMap) Add(file string, include *IncludeStatement) {
+	if _, ok := s.includes[file]; ok {
+		return
+	}
+	s.includes[file] = include
+}
+
+// IncludedBy returns the include statement which expanded the file
+func (s *SourceMap) IncludedBy(file string) (*IncludeStatement, bool) {
+	include, ok := s.includes[file]
+	return include, ok
+}
+
+// Trace returns the location of the token, followed by the locations of include statements
+// which lead to the token's file, from the nearest one to the main VCL.
+func (s *SourceMap) Trace(t token.Token) []SourceLocation {
+	locations := []SourceLocation{
+		{File: t.File, Line: t.Line, Position: t.Position},
+	}
+
+	visited := map[string]struct{}{}
+	file := t.File
+	for {
+		if _, ok := visited[file]; ok {
+			break
+		}
+		visited[file] = struct{}{}
+
+		include, ok := s.includes[file]
+		if !ok {
+			break
+		}
+		it := include.GetMeta().Token
+		locations = append(locations, SourceLocation{
+			File:     it.File,
+			Line:     it.Line,
+			Position: it.Position,
+		})
+		file = it.File
+	}
+
+	return locations
+}
@@ -0,0 +1,43 @@
+package ast
+
+import (
+	"testing"
+
+	"github.com/ysugimoto/falco/token"
+)
+
+func TestSourceMapTrace(t *testing.T) {
+	sm := NewSourceMap()
+	sm.Add("a.vcl", &IncludeStatement{
+		Meta: New(token.Token{File: "main.vcl", Line: 3, Position: 1}, 0),
+	})
+	sm.Add("b.vcl", &IncludeStatement{
+		Meta: New(token.Token{File: "a.vcl", Line: 10, Position: 3}, 0),
+	})
+
+	locations := sm.Trace(token.Token{File: "b.vcl", Line: 5, Position: 2})
+	expects := []string{"b.vcl:5:2", "a.vcl:10:3", "main.vcl:3:1"}
+	if len(locations) != len(expects) {
+		t.Errorf("Trace length mismatch, expect=%d, actual=%d", len(expects), len(locations))
+		return
+	}
+	for i := range expects {
+		if locations[i].String() != expects[i] {
+			t.Errorf("Trace[%d] mismatch, expect=%s, actual=%s", i, expects[i], locations[i].String())
+		}
+	}
+}
+
+func TestSourceMapTraceCircular(t *testing.T) {
+	sm := NewSourceMap()
+	sm.Add("a.vcl", &IncludeStatement{
+		Meta: New(token.Token{File: "b.vcl", Line: 1, Position: 1}, 0),
+	})
+	sm.Add("b.vcl", &IncludeStatement{
+		Meta: New(token.Token{File: "a.vcl", Line: 1, Position: 1}, 0),
+	})
+
+	if locations := sm.Trace(token.Token{File: "a.vcl", Line: 2, Position: 1}); len(locations) != 3 {
+		t.Errorf("Trace should stop on circular include, got %d locations", len(locations))
+	}
+}
@@ -19,6 +19,9 @@ func (s *SubroutineDeclaration) String() string {
 	buf.WriteString(s.LeadingComment())
 	buf.WriteString("sub ")
 	buf.WriteString(s.Name.String())
+	if s.ReturnType != nil {
+		buf.WriteString(" " + s.ReturnType.String())
+	}
 	buf.WriteString(" " + s.Block.String())
 	buf.WriteString(s.TrailingComment())
 	buf.WriteString("\n")
@@ -32,3 +32,39 @@ sub vcl_recv {
 		t.Errorf("stringer error.\nexpect:\n%s\nactual:\n%s\n", expect, sub.String())
 	}
 }
+
+func TestFunctionalSubroutineStatement(t *testing.T) {
+	var ret Expression = &String{
+		Meta:  New(T, 0),
+		Value: "x",
+	}
+	sub := &SubroutineDeclaration{
+		Meta: New(T, 0),
+		Name: &Ident{
+			Meta:  New(T, 0),
+			Value: "custom_sub",
+		},
+		ReturnType: &Ident{
+			Meta:  New(T, 0),
+			Value: "STRING",
+		},
+		Block: &BlockStatement{
+			Meta: New(T, 0),
+			Statements: []Statement{
+				&ReturnStatement{
+					Meta:             New(T, 1),
+					ReturnExpression: &ret,
+				},
+			},
+		},
+	}
+
+	expect := `sub custom_sub STRING {
+  return("x");
+}
+`
+
+	if sub.String() != expect {
+		t.Errorf("stringer error.\nexpect:\n%s\nactual:\n%s\n", expect, sub.String())
+	}
+}
@@ -0,0 +1,130 @@
+package ast
+
+import (
+	"reflect"
+)
+
+// NodeID is an identifier of the node in the Tree.
+// IDs are assigned in depth-first order starting from 1 for the root,
+// so the same source always produces the same IDs.
+type NodeID int
+
+// Tree holds node IDs and parent links of the AST.
+// Links are stored outside of the nodes because AST is serialized via gob for plugins,
+// which could not encode cyclic references.
+type Tree struct {
+	Root Node
+
+	nodes   []Node
+	ids     map[Node]NodeID
+	parents map[Node]Node
+}
+
+// NewTree walks the AST from root and populates node IDs and parent links.
+// Call this after parsing, and again after the AST is modified.
+func NewTree(root Node) *Tree {
+	t := &Tree{
+		Root:    root,
+		ids:     make(map[Node]NodeID),
+		parents: make(map[Node]Node),
+	}
+	if root != nil {
+		t.add(root, nil)
+	}
+	return t
+}
+
+// ID returns the ID of the node. The second value is false when the node is not in the tree.
+func (t *Tree) ID(node Node) (NodeID, bool) {
+	id, ok := t.ids[node]
+	return id, ok
+}
+
+// Node returns the node which has the ID, or nil when not found
+func (t *Tree) Node(id NodeID) Node {
+	if id < 1 || int(id) > len(t.nodes) {
+		return nil
+	}
+	return t.nodes[id-1]
+}
+
+// Nodes returns all nodes in ID order
+func (t *Tree) Nodes() []Node {
+	return t.nodes
+}
+
+// Parent returns the parent node. The root node and the node which is not in the tree return nil.
+func (t *Tree) Parent(node Node) Node {
+	return t.parents[node]
+}
+
+// Ancestors returns parent nodes from the nearest to the root
+func (t *Tree) Ancestors(node Node) []Node {
+	var ancestors []Node
+	for p := t.Parent(node); p != nil; p = t.Parent(p) {
+		ancestors = append(ancestors, p)
+	}
+	return ancestors
+}
+
+// Subroutine returns the subroutine declaration which contains the node, or nil when the node is outside of subroutines
+func (t *Tree) Subroutine(node Node) *SubroutineDeclaration {
+	for _, p := range t.Ancestors(node) {
+		if sub, ok := p.(*SubroutineDeclaration); ok {
+			return sub
+		}
+	}
+	return nil
+}
+
+func (t *Tree) add(node, parent Node) {
+	if _, ok := t.ids[node]; ok {
+		// Shared node is linked to the first parent
+		return
+	}
+	t.nodes = append(t.nodes, node)
+	t.ids[node] = NodeID(len(t.nodes))
+	if parent != nil {
+		t.parents[node] = parent
+	}
+	t.walk(reflect.ValueOf(node), node)
+}
+
+var nodeType = reflect.TypeOf((*Node)(nil)).Elem()
+
+// walk finds child nodes from node fields in declaration order
+func (t *Tree) walk(v reflect.Value, parent Node) {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return
+		}
+		// Element could be a node, e.g. *Expression field
+		t.child(v.Elem(), parent)
+	case reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			t.child(v.Index(i), parent)
+		}
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			// Embedded Meta holds only token and comments
+			if v.Type().Field(i).Anonymous || !v.Type().Field(i).IsExported() {
+				continue
+			}
+			t.child(v.Field(i), parent)
+		}
+	}
+}
+
+func (t *Tree) child(v reflect.Value, parent Node) {
+	if (v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface) && v.IsNil() {
+		return
+	}
+	if v.Type().Implements(nodeType) {
+		if node, ok := v.Interface().(Node); ok {
+			t.add(node, parent)
+			return
+		}
+	}
+	t.walk(v, parent)
+}
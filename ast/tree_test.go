@@ -0,0 +1,76 @@
+package ast
+
+import (
+	"testing"
+
+	"github.com/ysugimoto/falco/token"
+)
+
+func TestTree(t *testing.T) {
+	set := &SetStatement{
+		Meta: New(token.Null, 1),
+		Ident: &Ident{
+			Meta:  New(token.Null, 1),
+			Value: "req.hash",
+		},
+		Operator: &Operator{
+			Meta:     New(token.Null, 1),
+			Operator: "+=",
+		},
+		Value: &Ident{
+			Meta:  New(token.Null, 1),
+			Value: "req.url",
+		},
+	}
+	sub := &SubroutineDeclaration{
+		Meta: New(token.Null, 0),
+		Name: &Ident{
+			Meta:  New(token.Null, 0),
+			Value: "vcl_hash",
+		},
+		Block: &BlockStatement{
+			Meta:       New(token.Null, 1),
+			Statements: []Statement{set},
+		},
+	}
+	vcl := &VCL{
+		Statements: []Statement{sub},
+	}
+
+	tree := NewTree(vcl)
+
+	if id, ok := tree.ID(vcl); !ok || id != 1 {
+		t.Errorf("Root ID must be 1, got %d", id)
+	}
+	if id, ok := tree.ID(sub); !ok || id != 2 {
+		t.Errorf("Subroutine ID must be 2, got %d", id)
+	}
+	id, ok := tree.ID(set)
+	if !ok {
+		t.Fatalf("Set statement must be in the tree")
+	}
+	if tree.Node(id) != set {
+		t.Errorf("Node lookup by ID mismatch")
+	}
+	if tree.Parent(set) != sub.Block {
+		t.Errorf("Parent of set statement must be the block")
+	}
+	if tree.Parent(set.Value) != set {
+		t.Errorf("Parent of expression must be the set statement")
+	}
+	if tree.Parent(vcl) != nil {
+		t.Errorf("Root must not have parent")
+	}
+	if s := tree.Subroutine(set.Value); s == nil || s.Name.Value != "vcl_hash" {
+		t.Errorf("Enclosing subroutine must be vcl_hash")
+	}
+	if n := len(tree.Ancestors(set)); n != 3 {
+		t.Errorf("Set statement must have 3 ancestors, got %d", n)
+	}
+
+	// IDs are stable for the same tree
+	again := NewTree(vcl)
+	if a, _ := again.ID(set); a != id {
+		t.Errorf("Node ID must be stable, expect=%d, actual=%d", id, a)
+	}
+}
@@ -0,0 +1,15 @@
+package ast
+
+// UnknownType represents a variable type which falco does not know,
+// e.g. newer or vendor-specific type of "declare local" statement.
+// Name holds the raw type name so that linter rules can inspect it.
+type UnknownType struct {
+	*Meta
+	Name string
+}
+
+func (u *UnknownType) expression()    {}
+func (u *UnknownType) GetMeta() *Meta { return u.Meta }
+func (u *UnknownType) String() string {
+	return u.LeadingInlineComment() + u.Name + u.TrailingComment()
+}
@@ -24,3 +24,8 @@ func (v *VCL) String() string {
 func (v *VCL) GetMeta() *Meta {
 	return New(token.Null, 0)
 }
+
+// Root VCL does not have any comments, comments are attached to each statement
+func (v *VCL) LeadingComments() Comments  { return Comments{} }
+func (v *VCL) TrailingComments() Comments { return Comments{} }
+func (v *VCL) InfixComments() Comments    { return Comments{} }
@@ -2,6 +2,9 @@ package ast
 
 import (
 	"fmt"
+	"time"
+
+	"github.com/ysugimoto/falco/token"
 )
 
 type Ident struct {
@@ -48,6 +51,7 @@ func (i *Integer) String() string {
 	return i.LeadingInlineComment() + fmt.Sprintf("%d", i.Value) + i.TrailingComment()
 }
 
+// String holds decoded value, raw literal is available via RawValue()
 type String struct {
 	*Meta
 	Value string
@@ -57,9 +61,17 @@ func (s *String) expression()    {}
 func (s *String) GetMeta() *Meta { return s.Meta }
 func (s *String) String() string {
 	if s.Token.Offset == 4 { // offset=4 means bracket string
-		return s.LeadingComment() + fmt.Sprintf(`{"%s"}`, s.Value) + s.TrailingComment()
+		return s.LeadingComment() + fmt.Sprintf(`{"%s"}`, s.RawValue()) + s.TrailingComment()
 	}
-	return s.LeadingInlineComment() + fmt.Sprintf(`"%s"`, s.Value) + s.TrailingComment()
+	return s.LeadingInlineComment() + fmt.Sprintf(`"%s"`, s.RawValue()) + s.TrailingComment()
+}
+
+// RawValue returns string literal as written in the source, escape sequences are not decoded
+func (s *String) RawValue() string {
+	if s.Token.Type == token.STRING {
+		return s.Token.Literal
+	}
+	return s.Value
 }
 
 type Float struct {
@@ -75,7 +87,8 @@ func (f *Float) String() string {
 
 type RTime struct {
 	*Meta
-	Value string
+	Value    string        // literal text like "1.5s"
+	Duration time.Duration // parsed duration, "d" is 24 hours and "y" is 365 days
 }
 
 func (r *RTime) expression()    {}
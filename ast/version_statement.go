@@ -0,0 +1,24 @@
+package ast
+
+import (
+	"bytes"
+)
+
+// VersionStatement represents Varnish VCL version declaration like "vcl 4.0;"
+type VersionStatement struct {
+	*Meta
+	Version string
+}
+
+func (v *VersionStatement) statement()     {}
+func (v *VersionStatement) GetMeta() *Meta { return v.Meta }
+func (v *VersionStatement) String() string {
+	var buf bytes.Buffer
+
+	buf.WriteString(v.LeadingComment())
+	buf.WriteString(indent(v.Nest) + "vcl " + v.Version + ";")
+	buf.WriteString(v.TrailingComment())
+	buf.WriteString("\n")
+
+	return buf.String()
+}
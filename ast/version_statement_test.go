@@ -0,0 +1,20 @@
+package ast
+
+import (
+	"testing"
+)
+
+func TestVersionStatement(t *testing.T) {
+	vs := &VersionStatement{
+		Meta:    New(T, 0, comments("// This is comment"), comments("// This is comment")),
+		Version: "4.0",
+	}
+
+	expect := `// This is comment
+vcl 4.0; // This is comment
+`
+
+	if vs.String() != expect {
+		t.Errorf("stringer error.\nexpect:\n%s\nactual:\n%s\n", expect, vs.String())
+	}
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/ysugimoto/falco/linter"
+)
+
+const baselineVersion = 1
+
+// Baseline is the set of recorded lint findings which are not reported on subsequent runs.
+// It enables adopting falco to existing VCL codebase, only new findings fail the lint.
+type Baseline struct {
+	Version  int                `json:"version"`
+	Findings []*BaselineFinding `json:"findings"`
+
+	// Remaining count of the findings which could be matched
+	remains map[baselineKey]int
+}
+
+// BaselineFinding is a recorded lint finding.
+// Findings are matched by the file, rule, message and source code of the line,
+// so that they are still matched after the line number is shifted by editing other lines.
+type BaselineFinding struct {
+	File    string `json:"file"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+	Source  string `json:"source"`
+	// Line is recorded for humans to find the finding, not used for matching
+	Line int `json:"line"`
+}
+
+type baselineKey struct {
+	file, rule, message, source string
+}
+
+func (f *BaselineFinding) key() baselineKey {
+	return baselineKey{file: f.File, rule: f.Rule, message: f.Message, source: f.Source}
+}
+
+// loadBaseline loads baseline file. Returns nil if the file does not exist.
+func loadBaseline(path string) (*Baseline, error) {
+	buf, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, errors.WithStack(err)
+	}
+	b := &Baseline{}
+	if err := json.Unmarshal(buf, b); err != nil {
+		return nil, errors.Wrapf(err, "Failed to parse baseline file %s", path)
+	}
+	b.index()
+	return b, nil
+}
+
+func (b *Baseline) index() {
+	b.remains = make(map[baselineKey]int)
+	for _, f := range b.Findings {
+		b.remains[f.key()]++
+	}
+}
+
+// Match returns true if the finding is recorded in the baseline.
+// Each recorded finding matches only once, so the same finding newly added to other lines is reported.
+func (b *Baseline) Match(f *BaselineFinding) bool {
+	k := f.key()
+	if b.remains[k] == 0 {
+		return false
+	}
+	b.remains[k]--
+	return true
+}
+
+// Save writes baseline to the file, findings are sorted to make the file stable for version control
+func (b *Baseline) Save(path string) error {
+	sort.SliceStable(b.Findings, func(i, j int) bool {
+		x, y := b.Findings[i], b.Findings[j]
+		if x.File != y.File {
+			return x.File < y.File
+		}
+		if x.Line != y.Line {
+			return x.Line < y.Line
+		}
+		return x.Rule < y.Rule
+	})
+	buf, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if err := os.WriteFile(path, append(buf, '\n'), 0o644); err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
+
+// baselineFinding converts lint error to baseline finding
+func (r *Runner) baselineFinding(le *linter.LintError) *BaselineFinding {
+	f := &BaselineFinding{
+		File:    relativePath(le.Token.File),
+		Rule:    string(le.Rule),
+		Message: le.Message,
+		Line:    le.Token.Line,
+	}
+	if lx, ok := r.lexers[le.Token.File]; ok {
+		if line, ok := lx.GetLine(le.Token.Line); ok {
+			f.Source = strings.TrimSpace(line)
+		}
+	}
+	return f
+}
+
+// updateBaseline records lint errors to the baseline file except ignored ones
+func (r *Runner) updateBaseline(lintErrors []error) error {
+	b := &Baseline{
+		Version:  baselineVersion,
+		Findings: []*BaselineFinding{},
+	}
+	for _, err := range lintErrors {
+		le, ok := err.(*linter.LintError)
+		if !ok {
+			continue
+		}
+		if v, ok := r.overrides[string(le.Rule)]; ok && v == linter.IGNORE {
+			continue
+		}
+		b.Findings = append(b.Findings, r.baselineFinding(le))
+	}
+	if err := b.Save(r.config.Linter.Baseline); err != nil {
+		return err
+	}
+	b.index()
+	r.baseline = b
+	r.message(white, "Recorded %d finding(s) to baseline file %s\n", len(b.Findings), r.config.Linter.Baseline)
+	return nil
+}
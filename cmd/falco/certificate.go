@@ -0,0 +1,166 @@
+package main
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/ysugimoto/falco/config"
+)
+
+const (
+	localCACertFile = "rootCA.pem"
+	localCAKeyFile  = "rootCA-key.pem"
+)
+
+// Hostnames of the certificate which is issued for the simulator
+var localCertificateHosts = []string{"localhost", "127.0.0.1", "::1"}
+
+// simulatorCertificate returns the certificate for the HTTPS simulator server.
+// When the certificate files are not specified, the certificate is issued by the local CA like mkcert,
+// so the browser trusts the simulator once the CA certificate is installed.
+func simulatorCertificate(sc *config.SimulatorConfig) (tls.Certificate, string, error) {
+	if sc.CertFile != "" || sc.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(sc.CertFile, sc.KeyFile)
+		if err != nil {
+			return tls.Certificate{}, "", errors.WithStack(err)
+		}
+		return cert, "", nil
+	}
+
+	dir := sc.CADir
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return tls.Certificate{}, "", errors.WithStack(err)
+		}
+		dir = filepath.Join(home, ".falco")
+	}
+	ca, caKey, err := loadLocalCA(dir)
+	if err != nil {
+		return tls.Certificate{}, "", errors.WithStack(err)
+	}
+	cert, err := issueCertificate(ca, caKey, localCertificateHosts)
+	if err != nil {
+		return tls.Certificate{}, "", errors.WithStack(err)
+	}
+	return cert, filepath.Join(dir, localCACertFile), nil
+}
+
+// loadLocalCA loads the local CA in the directory, or creates it if not exists
+func loadLocalCA(dir string) (*x509.Certificate, *ecdsa.PrivateKey, error) {
+	certFile := filepath.Join(dir, localCACertFile)
+	keyFile := filepath.Join(dir, localCAKeyFile)
+
+	if _, err := os.Stat(certFile); os.IsNotExist(err) {
+		if err := createLocalCA(certFile, keyFile); err != nil {
+			return nil, nil, errors.WithStack(err)
+		}
+	}
+
+	pair, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, nil, errors.WithStack(err)
+	}
+	key, ok := pair.PrivateKey.(*ecdsa.PrivateKey)
+	if !ok {
+		return nil, nil, errors.New("Local CA key must be ECDSA private key")
+	}
+	ca, err := x509.ParseCertificate(pair.Certificate[0])
+	if err != nil {
+		return nil, nil, errors.WithStack(err)
+	}
+	return ca, key, nil
+}
+
+func createLocalCA(certFile, keyFile string) error {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	serial, err := randomSerialNumber()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	now := time.Now()
+	template := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{Organization: []string{"falco local CA"}, CommonName: "falco local CA"},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.AddDate(10, 0, 0),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		MaxPathLenZero:        true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(certFile), 0o755); err != nil {
+		return errors.WithStack(err)
+	}
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), 0o600); err != nil {
+		return errors.WithStack(err)
+	}
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o644); err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
+
+// issueCertificate issues the server certificate for the hosts which is signed by the CA
+func issueCertificate(ca *x509.Certificate, caKey *ecdsa.PrivateKey, hosts []string) (tls.Certificate, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return tls.Certificate{}, errors.WithStack(err)
+	}
+	serial, err := randomSerialNumber()
+	if err != nil {
+		return tls.Certificate{}, errors.WithStack(err)
+	}
+	now := time.Now()
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{Organization: []string{"falco simulator"}},
+		NotBefore:    now.Add(-time.Hour),
+		// Browsers reject server certificates which are valid for longer than 398 days
+		NotAfter:    now.AddDate(0, 0, 397),
+		KeyUsage:    x509.KeyUsageDigitalSignature,
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	for _, h := range hosts {
+		if ip := net.ParseIP(h); ip != nil {
+			template.IPAddresses = append(template.IPAddresses, ip)
+		} else {
+			template.DNSNames = append(template.DNSNames, h)
+		}
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca, &key.PublicKey, caKey)
+	if err != nil {
+		return tls.Certificate{}, errors.WithStack(err)
+	}
+	return tls.Certificate{
+		Certificate: [][]byte{der, ca.Raw},
+		PrivateKey:  key,
+	}, nil
+}
+
+func randomSerialNumber() (*big.Int, error) {
+	return rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+}
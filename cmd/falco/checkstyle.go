@@ -0,0 +1,65 @@
+package main
+
+import (
+	"encoding/xml"
+
+	"github.com/ysugimoto/falco/linter"
+)
+
+// Checkstyle XML output which Jenkins Warnings plugin, reviewdog and many CI tools could render.
+// Checkstyle format does not have formal specification, follows the format of Checkstyle 4.3 which is widely used.
+const checkstyleVersion = "4.3"
+
+type CheckstyleReport struct {
+	XMLName xml.Name          `xml:"checkstyle"`
+	Version string            `xml:"version,attr"`
+	Files   []*CheckstyleFile `xml:"file"`
+}
+
+type CheckstyleFile struct {
+	Name   string             `xml:"name,attr"`
+	Errors []*CheckstyleError `xml:"error"`
+}
+
+type CheckstyleError struct {
+	Line     int    `xml:"line,attr"`
+	Column   int    `xml:"column,attr,omitempty"`
+	Severity string `xml:"severity,attr"`
+	Message  string `xml:"message,attr"`
+	Source   string `xml:"source,attr"`
+}
+
+// checkstyleSeverities maps linter severity to checkstyle severity
+var checkstyleSeverities = map[linter.Severity]string{
+	linter.ERROR:   "error",
+	linter.WARNING: "warning",
+	linter.INFO:    "info",
+}
+
+// Checkstyle converts the runner result to checkstyle report.
+// Errors are grouped by file and source attribute has the rule name prefixed with "falco.".
+func (r *Runner) Checkstyle(result *RunnerResult) *CheckstyleReport {
+	report := &CheckstyleReport{
+		Version: checkstyleVersion,
+		Files:   []*CheckstyleFile{},
+	}
+
+	files := make(map[string]*CheckstyleFile)
+	for _, e := range r.reportedErrors(result) {
+		name := relativePath(e.Token.File)
+		f, ok := files[name]
+		if !ok {
+			f = &CheckstyleFile{Name: name}
+			files[name] = f
+			report.Files = append(report.Files, f)
+		}
+		f.Errors = append(f.Errors, &CheckstyleError{
+			Line:     e.Token.Line,
+			Column:   e.Token.Position,
+			Severity: checkstyleSeverities[e.Severity],
+			Message:  e.Message,
+			Source:   "falco." + e.Rule,
+		})
+	}
+	return report
+}
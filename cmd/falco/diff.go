@@ -0,0 +1,141 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/ysugimoto/falco/ast"
+	"github.com/ysugimoto/falco/token"
+)
+
+// Hunk header of unified diff, captures start line and line count of the new file
+var hunkHeader = regexp.MustCompile(`^@@ -[0-9]+(?:,[0-9]+)? \+([0-9]+)(?:,([0-9]+))? @@`)
+
+// ChangedLines is the set of lines which are changed since the git ref
+type ChangedLines struct {
+	// Changed line numbers keyed by absolute file path
+	lines map[string]map[int]struct{}
+	// Files which are entirely new, e.g. untracked files
+	files map[string]struct{}
+}
+
+// loadChangedLines collects changed lines in the working tree since the git ref.
+// Untracked files are treated as entirely changed.
+func loadChangedLines(ref string) (*ChangedLines, error) {
+	root, err := git("rev-parse", "--show-toplevel")
+	if err != nil {
+		return nil, err
+	}
+	root = strings.TrimSpace(root)
+
+	diff, err := git("diff", "--unified=0", "--no-color", "--no-ext-diff", ref, "--")
+	if err != nil {
+		return nil, err
+	}
+	changed := parseUnifiedDiff(root, diff)
+
+	untracked, err := git("ls-files", "--others", "--exclude-standard", "--full-name", root)
+	if err != nil {
+		return nil, err
+	}
+	for _, file := range strings.Split(untracked, "\n") {
+		if file = strings.TrimSpace(file); file != "" {
+			changed.files[filepath.Join(root, file)] = struct{}{}
+		}
+	}
+	return changed, nil
+}
+
+func git(args ...string) (string, error) {
+	var stderr bytes.Buffer
+	cmd := exec.Command("git", args...)
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", errors.Errorf("Failed to run git %s: %s", strings.Join(args, " "), strings.TrimSpace(stderr.String()))
+	}
+	return string(out), nil
+}
+
+// parseUnifiedDiff parses the output of "git diff --unified=0" and collects added or modified lines.
+// File paths in the diff are relative to the repository root.
+func parseUnifiedDiff(root, diff string) *ChangedLines {
+	changed := &ChangedLines{
+		lines: make(map[string]map[int]struct{}),
+		files: make(map[string]struct{}),
+	}
+
+	var file string
+	scanner := bufio.NewScanner(strings.NewReader(diff))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "+++ "):
+			file = ""
+			// Deleted file has "/dev/null" as new file
+			if name := strings.TrimPrefix(line, "+++ "); strings.HasPrefix(name, "b/") {
+				file = filepath.Join(root, name[2:])
+			}
+		case strings.HasPrefix(line, "@@ ") && file != "":
+			m := hunkHeader.FindStringSubmatch(line)
+			if m == nil {
+				continue
+			}
+			start, _ := strconv.Atoi(m[1]) // nolint:errcheck
+			count := 1
+			if m[2] != "" {
+				count, _ = strconv.Atoi(m[2]) // nolint:errcheck
+			}
+			if _, ok := changed.lines[file]; !ok {
+				changed.lines[file] = make(map[int]struct{})
+			}
+			// Count is zero when lines are only deleted
+			for i := start; i < start+count; i++ {
+				changed.lines[file][i] = struct{}{}
+			}
+		}
+	}
+	return changed
+}
+
+// Contains returns true if the token is on the changed line.
+// Include statements are also considered via the source map,
+// all findings in the module are changed when the include statement of the module is changed.
+func (c *ChangedLines) Contains(sourceMap *ast.SourceMap, t token.Token) bool {
+	// Findings which could not be located are always reported
+	if t.File == "" {
+		return true
+	}
+	locations := []ast.SourceLocation{{File: t.File, Line: t.Line, Position: t.Position}}
+	if sourceMap != nil {
+		locations = sourceMap.Trace(t)
+	}
+	for _, loc := range locations {
+		file := canonicalPath(loc.File)
+		if _, ok := c.files[file]; ok {
+			return true
+		}
+		if _, ok := c.lines[file][loc.Line]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// canonicalPath returns absolute path without symbolic links to compare with paths from git
+func canonicalPath(file string) string {
+	if abs, err := filepath.Abs(file); err == nil {
+		file = abs
+	}
+	if real, err := filepath.EvalSymlinks(file); err == nil {
+		file = real
+	}
+	return file
+}
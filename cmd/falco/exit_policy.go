@@ -0,0 +1,40 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// exitPolicyViolations returns reasons why linting should exit with nonzero code even if there are no errors.
+// Errors always cause nonzero exit code, and the configuration could add:
+//
+// - severities or rule names in fail_on, e.g. "warning" or "unused/variable"
+// - max_warnings which allows the number of warnings, negative value means unlimited
+//
+// Then warning-heavy pipelines could decrease max_warnings gradually to ratchet quality.
+func (r *Runner) exitPolicyViolations(result *RunnerResult) []string {
+	var violations []string
+	for _, v := range r.config.Linter.FailOn {
+		switch strings.ToLower(v) {
+		case "error":
+			// Errors always cause nonzero exit code
+		case "warning":
+			if result.Warnings > 0 {
+				violations = append(violations, fmt.Sprintf("%d warnings are reported", result.Warnings))
+			}
+		case "info":
+			if result.Infos > 0 {
+				violations = append(violations, fmt.Sprintf("%d recommendations are reported", result.Infos))
+			}
+		default:
+			if n := r.findings[v]; n > 0 {
+				violations = append(violations, fmt.Sprintf("%d findings of rule %s are reported", n, v))
+			}
+		}
+	}
+
+	if limit := r.config.Linter.MaxWarnings; limit >= 0 && result.Warnings > limit {
+		violations = append(violations, fmt.Sprintf("%d warnings exceed max warnings %d", result.Warnings, limit))
+	}
+	return violations
+}
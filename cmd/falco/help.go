@@ -58,6 +58,13 @@ All Flags:
     -v                 : Output lint warnings (verbose)
     -vv                : Output all lint results (very verbose)
     -json              : Output results as JSON (very verbose)
+    -format            : Output lint results in the format, json, sarif, checkstyle or junit
+    -code_frame        : Render errors with source code frame
+    -dialect           : VCL dialect to lint, "fastly" (default) or "varnish4"
+    -placeholder       : Template placeholder delimiters separated by whitespace like "{{ }}"
+    -import_module     : Known module name of import statement, any module is accepted if not specified
+    -max_input_bytes   : Maximum bytes of each VCL file to parse, zero means unlimited
+    -max_tokens        : Maximum tokens of each VCL file to parse, zero means unlimited
 
 Simple linting example:
     falco -I . -vv /path/to/vcl/main.vcl
@@ -81,6 +88,8 @@ Flags:
     -v                 : Output lint warnings (verbose)
     -vv                : Output all lint results (very verbose)
     -json              : Output results as JSON (very verbose)
+    -format            : Output lint results in the format, json, sarif, checkstyle or junit
+    -code_frame        : Render errors with source code frame
 
 Linting with terraform:
     terraform plan -out planned.out
@@ -99,12 +108,19 @@ Flags:
     -r, --remote       : Connect with Fastly API
     -request           : Simulate request config
     -debug             : Enable debug mode
+    --seed             : Seed random values to be reproducible
+    --tls              : Serve HTTPS with the certificate issued by the local CA
+    --cert             : Certificate file of HTTPS server
+    --key              : Private key file of HTTPS server
     --max_backends     : Override max backends limitation
     --max_acls         : Override max acls limitation
 
 Local simulator example:
     falco simulate -I . /path/to/vcl/main.vcl
 
+Local HTTPS simulator example:
+    falco simulate -I . --tls /path/to/vcl/main.vcl
+
 Local debugger example:
     falco simulate -I . -debug /path/to/vcl/main.vcl
 	`))
@@ -137,6 +153,7 @@ Flags:
     -r, --remote       : Connect with Fastly API
     -t, --timeout      : Set timeout to running test
     -f, --filter       : Override glob filter to find test files
+    --seed             : Seed random values to be reproducible
     -json              : Output results as JSON
     -request           : Override request config
     --max_backends     : Override max backends limitation
@@ -160,6 +177,20 @@ Flags:
     -v                 : Output lint warnings (verbose)
     -vv                : Output all lint results (very verbose)
     -json              : Output results as JSON (very verbose)
+    -format            : Output lint results in the format, json, sarif, checkstyle or junit
+    -code_frame        : Render errors with source code frame
+    -dialect           : VCL dialect to lint, "fastly" (default) or "varnish4"
+    -placeholder       : Template placeholder delimiters separated by whitespace like "{{ }}"
+    -import_module     : Known module name of import statement, any module is accepted if not specified
+    -max_input_bytes   : Maximum bytes of each VCL file to parse, zero means unlimited
+    -max_tokens        : Maximum tokens of each VCL file to parse, zero means unlimited
+    -fix               : Apply automatic fixes to the source files
+    -baseline          : Baseline file path (default .falco-baseline.json)
+    -update_baseline   : Record current findings to the baseline file
+    -diff_base         : Report only findings on lines changed since the git ref
+    -fail_on           : Exit with nonzero code on findings of the severity or the rule, e.g. warning
+    -max_warnings      : Exit with nonzero code when warnings exceed the number
+    -summary           : Print counts of findings per rule and file, and elapsed time of rules
 
 Simple linting with very verbose example:
     falco lint -I . -vv /path/to/vcl/main.vcl
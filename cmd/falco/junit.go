@@ -0,0 +1,103 @@
+package main
+
+import (
+	"encoding/xml"
+	"fmt"
+)
+
+// JUnit XML output which Jenkins, GitLab and many CI tools could render as test report.
+// Each file is a test suite and each lint error is a failed test case,
+// and a passing test case is reported when there is no error to show that lint has been run.
+
+type JunitReport struct {
+	XMLName  xml.Name          `xml:"testsuites"`
+	Name     string            `xml:"name,attr"`
+	Tests    int               `xml:"tests,attr"`
+	Failures int               `xml:"failures,attr"`
+	Suites   []*JunitTestSuite `xml:"testsuite"`
+}
+
+type JunitTestSuite struct {
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Cases    []*JunitTestCase `xml:"testcase"`
+}
+
+type JunitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *JunitFailure `xml:"failure,omitempty"`
+}
+
+type JunitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// Junit converts the runner result to JUnit report
+func (r *Runner) Junit(result *RunnerResult) *JunitReport {
+	report := &JunitReport{
+		Name:   "falco",
+		Suites: []*JunitTestSuite{},
+	}
+	// Distinguish reports of terraform planned services
+	if result.Address != "" {
+		report.Name = "falco: " + result.Address
+	} else if result.Service != "" {
+		report.Name = "falco: " + result.Service
+	}
+
+	suites := make(map[string]*JunitTestSuite)
+	for _, e := range r.reportedErrors(result) {
+		file := relativePath(e.Token.File)
+		s, ok := suites[file]
+		if !ok {
+			s = &JunitTestSuite{Name: file}
+			suites[file] = s
+			report.Suites = append(report.Suites, s)
+		}
+		location := fmt.Sprintf("%s:%d:%d", file, e.Token.Line, e.Token.Position)
+		text := fmt.Sprintf("%s: %s\n  --> %s", e.Severity, e.Message, location)
+		if e.Reference != "" {
+			text += "\n  = see: " + e.Reference
+		}
+		if e.Documentation != "" {
+			text += "\n  = rule: " + e.Documentation
+		}
+		if e.Fixable {
+			text += "\n  = fixable: run with -fix option to fix automatically"
+		}
+		s.Cases = append(s.Cases, &JunitTestCase{
+			Name:      fmt.Sprintf("%s at %s", e.Rule, location),
+			ClassName: "falco." + e.Category,
+			Failure: &JunitFailure{
+				Message: e.Message,
+				Type:    string(e.Severity),
+				Text:    text,
+			},
+		})
+		s.Tests++
+		s.Failures++
+	}
+
+	if len(report.Suites) == 0 {
+		name := "lint"
+		if result.Vcl != nil {
+			name = relativePath(result.Vcl.File)
+		}
+		report.Suites = append(report.Suites, &JunitTestSuite{
+			Name:  "falco",
+			Tests: 1,
+			Cases: []*JunitTestCase{
+				{Name: name, ClassName: "falco.lint"},
+			},
+		})
+	}
+	for _, s := range report.Suites {
+		report.Tests += s.Tests
+		report.Failures += s.Failures
+	}
+	return report
+}
@@ -2,13 +2,16 @@ package main
 
 import (
 	"fmt"
+	"io"
 	"math"
 	"os"
 	"path/filepath"
+	"runtime"
 	"strings"
 	"time"
 
 	"encoding/json"
+	"encoding/xml"
 
 	"github.com/fatih/color"
 	"github.com/kyokomi/emoji"
@@ -106,7 +109,7 @@ func main() {
 	}
 
 	if c.Remote {
-		if !c.Json {
+		if !c.MachineReadable() {
 			writeln(cyan, "Remote option supplied. Fetching snippets from Fastly.")
 		}
 		// If remote flag is provided, fetch predefined data from Fastly.
@@ -128,24 +131,40 @@ func main() {
 	}
 
 	var shouldExit bool
+	var jobs []*lintJob
 	for _, v := range resolvers {
+		var header string
 		if name := v.Name(); name != "" {
-			writeln(white, `Lint service of "%s"`, name)
-			writeln(white, strings.Repeat("=", 18+len(name)))
+			title := fmt.Sprintf("Lint service of \"%s\"", name)
+			// Terraform resource address distinguishes services in modules or for_each instances
+			address := resourceAddress(v)
+			if address != "" {
+				title += fmt.Sprintf(" (%s)", address)
+			}
+			header = fmt.Sprintf("%s\n%s\n", title, strings.Repeat("=", len(title)))
 
-			// If fetcher is instance of TerraformFetcher, set name to filter service
+			// If fetcher is instance of TerraformFetcher, set name and address to filter service
 			if fetcher != nil {
 				if t, ok := fetcher.(*terraform.TerraformFetcher); ok {
 					t.SetName(name)
+					t.SetAddress(address)
 				}
 			}
 		}
+		// Runners are created serially because fetcher is stateful
 		runner, err := NewRunner(c, fetcher)
 		if err != nil {
 			writeln(red, err.Error())
 			os.Exit(1)
 		}
 
+		// Linting services are independent, run them in parallel later
+		if action != subcommandTest && action != subcommandSimulate && action != subcommandStats {
+			jobs = append(jobs, &lintJob{runner: runner, resolver: v, header: header})
+			continue
+		}
+
+		write(white, "%s", header)
 		var exitErr error
 		switch action {
 		case subcommandTest:
@@ -154,8 +173,6 @@ func main() {
 			exitErr = runSimulate(runner, v)
 		case subcommandStats:
 			exitErr = runStats(runner, v)
-		default:
-			exitErr = runLint(runner, v)
 		}
 
 		if exitErr == ErrExit {
@@ -163,6 +180,10 @@ func main() {
 		}
 	}
 
+	if len(jobs) > 0 && runLintJobs(jobs, runtime.NumCPU(), output, os.Stdout) {
+		shouldExit = true
+	}
+
 	if shouldExit {
 		os.Exit(1)
 	}
@@ -172,57 +193,95 @@ func runLint(runner *Runner, rslv resolver.Resolver) error {
 	result, err := runner.Run(rslv)
 	if err != nil {
 		if err != ErrParser {
-			writeln(red, err.Error())
+			runner.writeln(red, err.Error())
 		}
 		return ErrExit
 	}
 
-	if runner.config.Json {
-		enc := json.NewEncoder(os.Stdout)
-		enc.SetIndent("", "  ")
-		if err := enc.Encode(result); err != nil {
-			writeln(red, err.Error())
+	if runner.config.MachineReadable() {
+		if err := writeReport(runner, result); err != nil {
+			runner.writeln(red, err.Error())
 			return ErrExit
 		}
 	}
 
-	write(red, ":fire:%d errors, ", result.Errors)
-	write(yellow, ":exclamation:%d warnings, ", result.Warnings)
-	writeln(cyan, ":speaker:%d recommendations.", result.Infos)
+	runner.write(red, ":fire:%d errors, ", result.Errors)
+	runner.write(yellow, ":exclamation:%d warnings, ", result.Warnings)
+	runner.writeln(cyan, ":speaker:%d recommendations.", result.Infos)
+	if result.Summary != nil && !runner.config.MachineReadable() {
+		runner.printSummary(result.Summary)
+	}
 
 	// Display message corresponds to runner result
 	if result.Errors == 0 {
 		switch {
 		case result.Warnings > 0:
-			writeln(white, "VCL lint warnings encountered, but things should run OK :thumbsup:")
+			runner.writeln(white, "VCL lint warnings encountered, but things should run OK :thumbsup:")
 			if runner.level < LevelWarning {
-				writeln(white, "Run command with the -v option to output warnings.")
+				runner.writeln(white, "Run command with the -v option to output warnings.")
 			}
 		case result.Infos > 0:
-			writeln(green, "VCL looks good :sparkles: Some recommendations are available :thumbsup:")
+			runner.writeln(green, "VCL looks good :sparkles: Some recommendations are available :thumbsup:")
 			if runner.level < LevelInfo {
-				writeln(white, "Run command with the -vv option to output recommendations.")
+				runner.writeln(white, "Run command with the -vv option to output recommendations.")
 			}
 		default:
-			writeln(green, "VCL looks great :sparkles:")
+			runner.writeln(green, "VCL looks great :sparkles:")
 		}
 	}
 
 	// if lint error is not zero, stop process
 	if result.Errors > 0 {
 		if len(runner.transformers) > 0 {
-			writeln(white, "Program aborted. Please fix lint errors before transforming.")
+			runner.writeln(white, "Program aborted. Please fix lint errors before transforming.")
+		}
+		return ErrExit
+	}
+
+	// Warnings or specific rules could also stop process by the configuration
+	if violations := runner.exitPolicyViolations(result); len(violations) > 0 {
+		for _, v := range violations {
+			runner.writeln(red, "Lint failed: %s", v)
 		}
 		return ErrExit
 	}
 
 	if err := runner.Transform(result.Vcl); err != nil {
-		writeln(red, err.Error())
+		runner.writeln(red, err.Error())
 		return ErrExit
 	}
 	return nil
 }
 
+// writeReport writes lint result to stdout in the configured format
+func writeReport(runner *Runner, result *RunnerResult) error {
+	switch runner.config.Format {
+	case config.FormatCheckstyle, config.FormatJunit:
+		var report interface{} = runner.Checkstyle(result)
+		if runner.config.Format == config.FormatJunit {
+			report = runner.Junit(result)
+		}
+		if _, err := io.WriteString(runner.stdout, xml.Header); err != nil {
+			return err
+		}
+		enc := xml.NewEncoder(runner.stdout)
+		enc.Indent("", "  ")
+		if err := enc.Encode(report); err != nil {
+			return err
+		}
+		_, err := io.WriteString(runner.stdout, "\n")
+		return err
+	case config.FormatSarif:
+		enc := json.NewEncoder(runner.stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(runner.Sarif(result))
+	default:
+		enc := json.NewEncoder(runner.stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(result)
+	}
+}
+
 func runSimulate(runner *Runner, rslv resolver.Resolver) error {
 	if err := runner.Simulate(rslv); err != nil {
 		writeln(red, "Failed to start local simulator: %s", err.Error())
@@ -240,7 +299,7 @@ func runStats(runner *Runner, rslv resolver.Resolver) error {
 		return ErrExit
 	}
 
-	if runner.config.Json {
+	if runner.config.MachineReadable() {
 		enc := json.NewEncoder(os.Stdout)
 		enc.SetIndent("", "  ")
 		if err := enc.Encode(stats); err != nil {
@@ -272,6 +331,21 @@ func runStats(runner *Runner, rslv resolver.Resolver) error {
 	printStats(strings.Repeat("-", 80))
 	printStats("| %-22s | %51d |", "Directors", stats.Directors)
 	printStats(strings.Repeat("-", 80))
+	if len(stats.Complexity) > 0 {
+		cyclomatic, nesting := stats.Complexity[0], stats.Complexity[0]
+		for _, c := range stats.Complexity[1:] {
+			if c.Cyclomatic > cyclomatic.Cyclomatic {
+				cyclomatic = c
+			}
+			if c.Nesting > nesting.Nesting {
+				nesting = c
+			}
+		}
+		printStats("| %-22s | %51s |", "Max Complexity", fmt.Sprintf("%d (%s)", cyclomatic.Cyclomatic, cyclomatic.Subroutine))
+		printStats(strings.Repeat("-", 80))
+		printStats("| %-22s | %51s |", "Max Nesting Depth", fmt.Sprintf("%d (%s)", nesting.Nesting, nesting.Subroutine))
+		printStats(strings.Repeat("-", 80))
+	}
 	return nil
 }
 
@@ -281,7 +355,7 @@ func runTest(runner *Runner, rslv resolver.Resolver) error {
 		return ErrExit
 	}
 
-	if runner.config.Json {
+	if runner.config.MachineReadable() {
 		enc := json.NewEncoder(os.Stdout)
 		enc.SetIndent("", "  ")
 		if err := enc.Encode(struct {
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"sync"
+
+	"github.com/ysugimoto/falco/resolver"
+)
+
+// lintJob is a lint run of the service.
+// Outputs are buffered while running and flushed in the order of jobs,
+// so that the output is deterministic regardless of the completion order.
+type lintJob struct {
+	runner   *Runner
+	resolver resolver.Resolver
+	header   string
+
+	output bytes.Buffer
+	stdout bytes.Buffer
+	err    error
+}
+
+// runLintJobs lints services across the worker pool and writes outputs in the order of jobs.
+// Returns true if any of jobs fails.
+func runLintJobs(jobs []*lintJob, workers int, output, stdout io.Writer) bool {
+	// Single job does not need to be buffered, output progressively
+	if len(jobs) == 1 {
+		job := jobs[0]
+		job.runner.output = output
+		job.runner.stdout = stdout
+		job.runner.write(white, "%s", job.header)
+		return runLint(job.runner, job.resolver) == ErrExit
+	}
+	if workers < 1 {
+		workers = 1
+	}
+
+	queue := make(chan *lintJob)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range queue {
+				job.runner.output = &job.output
+				job.runner.stdout = &job.stdout
+				job.runner.write(white, "%s", job.header)
+				job.err = runLint(job.runner, job.resolver)
+			}
+		}()
+	}
+	for _, job := range jobs {
+		queue <- job
+	}
+	close(queue)
+	wg.Wait()
+
+	var failed bool
+	for _, job := range jobs {
+		io.Copy(output, &job.output) // nolint:errcheck
+		io.Copy(stdout, &job.stdout) // nolint:errcheck
+		if job.err == ErrExit {
+			failed = true
+		}
+	}
+	return failed
+}
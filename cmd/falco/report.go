@@ -0,0 +1,103 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/ysugimoto/falco/linter"
+	"github.com/ysugimoto/falco/token"
+)
+
+// Rule name for parse errors which do not belong to any lint rule
+const parseErrorRule = "parse-error"
+
+// ReportedError is a lint or parse error to output by reporters
+type ReportedError struct {
+	Severity      linter.Severity
+	Rule          string
+	Category      string
+	Message       string
+	Reference     string
+	Documentation string
+	Fixable       bool
+	Token         token.Token
+}
+
+// reportedErrors returns lint and parse errors in the result ordered by file name and position.
+// Severity overrides are applied and ignored errors are not included.
+func (r *Runner) reportedErrors(result *RunnerResult) []*ReportedError {
+	var files []string
+	for file := range result.LintErrors {
+		files = append(files, file)
+	}
+	sort.Strings(files)
+
+	reported := []*ReportedError{}
+	for _, file := range files {
+		// Lint errors are not ordered by position, e.g. unused declarations
+		errs := append([]*linter.LintError{}, result.LintErrors[file]...)
+		sort.SliceStable(errs, func(i, j int) bool {
+			if errs[i].Token.Line == errs[j].Token.Line {
+				return errs[i].Token.Position < errs[j].Token.Position
+			}
+			return errs[i].Token.Line < errs[j].Token.Line
+		})
+		for _, le := range errs {
+			severity := le.Severity
+			if v, ok := r.overrides[string(le.Rule)]; ok {
+				severity = v
+			}
+			if severity == linter.IGNORE {
+				continue
+			}
+			rule := string(le.Rule)
+			if rule == "" {
+				rule = "unknown"
+			}
+			reported = append(reported, &ReportedError{
+				Severity:      severity,
+				Rule:          rule,
+				Category:      le.Rule.Category(),
+				Message:       le.Message,
+				Reference:     le.Reference,
+				Documentation: le.Rule.Documentation(),
+				Fixable:       le.Fixable(),
+				Token:         le.Token,
+			})
+		}
+	}
+
+	files = files[:0]
+	for file := range result.ParseErrors {
+		files = append(files, file)
+	}
+	sort.Strings(files)
+	for _, file := range files {
+		pe := result.ParseErrors[file]
+		reported = append(reported, &ReportedError{
+			Severity: linter.ERROR,
+			Rule:     parseErrorRule,
+			Category: parseErrorRule,
+			Message:  pe.Message,
+			Token:    pe.Token,
+		})
+	}
+	return reported
+}
+
+// relativePath converts the file path to relative path from working directory if it is under the directory
+func relativePath(file string) string {
+	if !filepath.IsAbs(file) {
+		return file
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		return file
+	}
+	if rel, err := filepath.Rel(wd, file); err == nil && !strings.HasPrefix(rel, "..") {
+		return rel
+	}
+	return file
+}
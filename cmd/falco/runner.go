@@ -2,18 +2,25 @@ package main
 
 import (
 	"bytes"
+	"crypto/tls"
 	"fmt"
+	"io"
 	"net/http"
+	"os"
 	"strings"
+	"time"
 
 	"github.com/fatih/color"
+	"github.com/kyokomi/emoji"
 	"github.com/pkg/errors"
 	"github.com/ysugimoto/falco/ast"
 	"github.com/ysugimoto/falco/config"
 	"github.com/ysugimoto/falco/context"
 	"github.com/ysugimoto/falco/debugger"
+	"github.com/ysugimoto/falco/diagnostics"
 	"github.com/ysugimoto/falco/interpreter"
 	icontext "github.com/ysugimoto/falco/interpreter/context"
+	"github.com/ysugimoto/falco/interpreter/geo"
 	"github.com/ysugimoto/falco/lexer"
 	"github.com/ysugimoto/falco/linter"
 	"github.com/ysugimoto/falco/parser"
@@ -21,13 +28,19 @@ import (
 	"github.com/ysugimoto/falco/resolver"
 	"github.com/ysugimoto/falco/snippets"
 	"github.com/ysugimoto/falco/tester"
+	"github.com/ysugimoto/falco/token"
 	"github.com/ysugimoto/falco/types"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
 )
 
 var (
 	ErrParser = fmt.Errorf("parser error")
 )
 
+// Maximum number of lint and fix iterations to prevent endless fixing
+const maxFixIterations = 10
+
 type Level int
 
 const (
@@ -37,12 +50,18 @@ const (
 )
 
 type RunnerResult struct {
+	// Service name and terraform resource address which findings belong to
+	Service string `json:",omitempty"`
+	Address string `json:",omitempty"`
+
 	Infos    int
 	Warnings int
 	Errors   int
 
 	LintErrors  map[string][]*linter.LintError
 	ParseErrors map[string]*parser.ParseError
+	Complexity  []*linter.Complexity
+	Summary     *LintSummary `json:",omitempty"`
 
 	Vcl *plugin.VCL
 }
@@ -56,6 +75,8 @@ type StatsResult struct {
 	Directors   int    `json:"directors"`
 	Files       int    `json:"files"`
 	Lines       int    `json:"lines"`
+
+	Complexity []*linter.Complexity `json:"complexity"`
 }
 
 type Fetcher interface {
@@ -63,6 +84,7 @@ type Fetcher interface {
 	Dictionaries() ([]*types.RemoteDictionary, error)
 	Acls() ([]*types.RemoteAcl, error)
 	Snippets() ([]*types.RemoteVCL, error)
+	Conditions() ([]*types.RemoteCondition, error)
 }
 
 type RunMode int
@@ -73,20 +95,36 @@ const (
 )
 
 type Runner struct {
-	transformers []*Transformer
-	overrides    map[string]linter.Severity
-	lexers       map[string]*lexer.Lexer
-	snippets     *snippets.Snippets
-	config       *config.Config
-
-	level       Level
-	lintErrors  map[string][]*linter.LintError
-	parseErrors map[string]*parser.ParseError
+	transformers  []*Transformer
+	overrides     map[string]linter.Severity
+	linterOptions []linter.OptionFunc
+	parserOptions []parser.OptionFunc
+	lexers        map[string]*lexer.Lexer
+	sourceMap     *ast.SourceMap
+	snippets      *snippets.Snippets
+	config        *config.Config
+	baseline      *Baseline
+	changedLines  *ChangedLines
+
+	// Writers of messages and machine-readable results, replaced with buffers on parallel linting
+	output io.Writer
+	stdout io.Writer
+
+	level        Level
+	lintErrors   map[string][]*linter.LintError
+	parseErrors  map[string]*parser.ParseError
+	complexities []*linter.Complexity
+	timings      map[string]time.Duration
 
 	// runner result fields
-	infos    int
-	warnings int
-	errors   int
+	infos     int
+	warnings  int
+	errors    int
+	baselined int
+	unchanged int
+	// Count of reported findings keyed by rule name and file name
+	findings     map[string]int
+	fileFindings map[string]int
 }
 
 // Wrap writeln function in order to prevent to write when json mode turns on
@@ -94,20 +132,32 @@ func (r *Runner) message(c *color.Color, format string, args ...interface{}) {
 	// Suppress output when JSON mode turns on
 	// This is because JSON only should display JSON string
 	// so any other messages we must not output
-	if r.config.Json {
+	if r.config.MachineReadable() {
 		return
 	}
-	write(c, format, args...)
+	r.write(c, format, args...)
+}
+
+func (r *Runner) write(c *color.Color, format string, args ...interface{}) {
+	c.Fprint(r.output, emoji.Sprintf(format, args...))
+}
+
+func (r *Runner) writeln(c *color.Color, format string, args ...interface{}) {
+	r.write(c, format+"\n", args...)
 }
 
 func NewRunner(c *config.Config, fetcher snippets.Fetcher) (*Runner, error) {
 	r := &Runner{
-		level:       LevelError,
-		overrides:   make(map[string]linter.Severity),
-		lexers:      make(map[string]*lexer.Lexer),
-		config:      c,
-		lintErrors:  make(map[string][]*linter.LintError),
-		parseErrors: make(map[string]*parser.ParseError),
+		level:        LevelError,
+		overrides:    make(map[string]linter.Severity),
+		lexers:       make(map[string]*lexer.Lexer),
+		config:       c,
+		lintErrors:   make(map[string][]*linter.LintError),
+		parseErrors:  make(map[string]*parser.ParseError),
+		findings:     make(map[string]int),
+		fileFindings: make(map[string]int),
+		output:       output,
+		stdout:       os.Stdout,
 	}
 
 	// If fetch interface is provided, communicate with it
@@ -133,6 +183,26 @@ func NewRunner(c *config.Config, fetcher snippets.Fetcher) (*Runner, error) {
 		r.transformers = append(r.transformers, tf)
 	}
 
+	// Text enclosed by template placeholders is lexed as an identifier so that templated VCL could be linted
+	for _, v := range c.Placeholders {
+		p, err := lexer.ParsePlaceholder(v)
+		if err != nil {
+			return nil, err
+		}
+		r.parserOptions = append(r.parserOptions, parser.WithPlaceholders(p))
+	}
+	// Parser stops with the error when the input exceeds the limits, zero means unlimited
+	if c.MaxInputBytes > 0 {
+		r.parserOptions = append(r.parserOptions, parser.WithMaxInputBytes(c.MaxInputBytes))
+	}
+	if c.MaxTokens > 0 {
+		r.parserOptions = append(r.parserOptions, parser.WithMaxTokens(c.MaxTokens))
+	}
+	// Import statement of unknown module is a parse error when modules are registered
+	if len(c.ImportModules) > 0 {
+		r.parserOptions = append(r.parserOptions, parser.WithImportModules(c.ImportModules...))
+	}
+
 	// Set verbose level
 	if c.Linter.VerboseInfo {
 		r.level = LevelInfo
@@ -140,19 +210,85 @@ func NewRunner(c *config.Config, fetcher snippets.Fetcher) (*Runner, error) {
 		r.level = LevelWarning
 	}
 
+	// Load custom lint rules from Go plugins
+	for _, path := range c.Linter.Plugins {
+		rules, err := linter.LoadPlugin(path)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to load linter plugin %s: %w", path, err)
+		}
+		r.linterOptions = append(r.linterOptions, linter.WithCustomRules(rules...))
+	}
+
+	// Load custom lint rules from WASM modules
+	for _, path := range c.Linter.WasmRules {
+		rule, err := linter.LoadWasmRule(path)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to load WASM rule %s: %w", path, err)
+		}
+		r.linterOptions = append(r.linterOptions, linter.WithCustomRules(rule))
+	}
+
+	// Load recorded findings unless baseline is going to be updated
+	if c.Linter.Baseline != "" && !c.Linter.UpdateBaseline {
+		b, err := loadBaseline(c.Linter.Baseline)
+		if err != nil {
+			return nil, err
+		}
+		r.baseline = b
+	}
+
+	// Report only findings on the lines changed since the git ref
+	if c.Linter.DiffBase != "" {
+		cl, err := loadChangedLines(c.Linter.DiffBase)
+		if err != nil {
+			return nil, err
+		}
+		r.changedLines = cl
+	}
+
+	// Measure elapsed time of rules for the summary report
+	if c.Linter.Summary {
+		r.linterOptions = append(r.linterOptions, linter.WithTiming())
+	}
+
+	if c.Linter.ReportUnusedSuppressions {
+		r.linterOptions = append(r.linterOptions, linter.WithReportUnusedSuppressions())
+	}
+
+	// Increased resource limits are also applied to the linter
+	if c.OverrideMaxBackends > 0 {
+		r.linterOptions = append(r.linterOptions, linter.WithRuleOptions(linter.LIMIT_BACKEND_COUNT, map[string]interface{}{
+			"max": c.OverrideMaxBackends,
+		}))
+	}
+	if c.OverrideMaxAcls > 0 {
+		r.linterOptions = append(r.linterOptions, linter.WithRuleOptions(linter.LIMIT_ACL_COUNT, map[string]interface{}{
+			"max": c.OverrideMaxAcls,
+		}))
+	}
+
 	// Override linter rules
-	for key, value := range c.Linter.Rules {
-		switch strings.ToUpper(value) {
+	for key, rule := range c.Linter.Rules {
+		if rule == nil {
+			continue
+		}
+		if len(rule.Options) > 0 {
+			r.linterOptions = append(r.linterOptions, linter.WithRuleOptions(linter.Rule(key), rule.Options))
+		}
+		if rule.Severity == "" {
+			continue
+		}
+		switch strings.ToUpper(rule.Severity) {
 		case "ERROR":
 			r.overrides[key] = linter.ERROR
 		case "WARNING":
 			r.overrides[key] = linter.WARNING
 		case "INFO":
 			r.overrides[key] = linter.INFO
-		case "IGNORE":
+		case "IGNORE", "OFF":
 			r.overrides[key] = linter.IGNORE
 		default:
-			r.message(yellow, "Level for rule %s has invalid value %s, skipping.\n", key, value)
+			r.message(yellow, "Level for rule %s has invalid value %s, skipping.\n", key, rule.Severity)
 		}
 	}
 
@@ -175,12 +311,23 @@ func (r *Runner) Transform(vcl *plugin.VCL) error {
 }
 
 func (r *Runner) Run(rslv resolver.Resolver) (*RunnerResult, error) {
-	options := []context.Option{context.WithResolver(rslv)}
+	options := []context.Option{
+		context.WithResolver(rslv),
+		context.WithDialect(r.config.Dialect),
+		context.WithParserOptions(r.parserOptions...),
+	}
 	// If remote snippets exists, prepare parse and prepend to main VCL
 	if r.snippets != nil {
 		options = append(options, context.WithSnippets(r.snippets))
 	}
 
+	// Apply automatic fixes before linting so that remaining problems are reported
+	if r.config.Linter.Fix {
+		if err := r.fix(rslv, options); err != nil {
+			return nil, err
+		}
+	}
+
 	main, err := rslv.MainVCL()
 	if err != nil {
 		return nil, err
@@ -189,18 +336,36 @@ func (r *Runner) Run(rslv resolver.Resolver) (*RunnerResult, error) {
 	// Note: this context is not Go context, our parsing context :)
 	ctx := context.New(options...)
 	vcl, err := r.run(ctx, main, RunModeLint)
-	if err != nil && !r.config.Json {
+	if err != nil && !r.config.MachineReadable() {
 		return nil, err
 	}
 
-	return &RunnerResult{
+	result := &RunnerResult{
 		Infos:       r.infos,
 		Warnings:    r.warnings,
 		Errors:      r.errors,
 		LintErrors:  r.lintErrors,
 		ParseErrors: r.parseErrors,
+		Complexity:  r.complexities,
 		Vcl:         vcl,
-	}, nil
+	}
+	if r.config.Linter.Summary {
+		result.Summary = r.summary(result)
+	}
+	// Attribute findings to the terraform planned service
+	if address := resourceAddress(rslv); address != "" {
+		result.Service = rslv.Name()
+		result.Address = address
+	}
+	return result, nil
+}
+
+// resourceAddress returns terraform resource address of the service if the resolver has it
+func resourceAddress(rslv resolver.Resolver) string {
+	if v, ok := rslv.(interface{ Address() string }); ok {
+		return v.Address()
+	}
+	return ""
 }
 
 func (r *Runner) run(ctx *context.Context, main *resolver.VCL, mode RunMode) (*plugin.VCL, error) {
@@ -220,12 +385,15 @@ func (r *Runner) run(ctx *context.Context, main *resolver.VCL, mode RunMode) (*p
 		}
 	}
 
-	lt := linter.New()
+	lt := linter.New(r.linterOptions...)
 	lt.Lint(vcl, ctx)
 
 	for k, v := range lt.Lexers() {
 		r.lexers[k] = v
 	}
+	r.sourceMap = lt.SourceMap()
+	r.complexities = lt.Complexities()
+	r.timings = lt.Timings()
 
 	// If runner is running as stat mode, prevent to output lint result
 	if mode&RunModeStat > 0 {
@@ -240,34 +408,64 @@ func (r *Runner) run(ctx *context.Context, main *resolver.VCL, mode RunMode) (*p
 				file = "in " + pe.Token.File + " "
 			}
 			// Nothing to print to stdout if JSON mode is enabled, exit early.
-			if r.config.Json {
+			if r.config.MachineReadable() {
 				r.parseErrors[pe.Token.File] = pe
 			} else {
 				r.printParseError(lt.FatalError.Lexer, file, pe)
+				r.printIncludeTrace(pe.Token)
 			}
 		}
+		if le, ok := lt.FatalError.Error.(*parser.LimitError); ok {
+			r.message(red, ":boom: %s\n", le.Error())
+			r.printIncludeTrace(le.Token)
+		}
 		return nil, ErrParser
 	}
 
+	if r.config.Linter.UpdateBaseline && r.config.Linter.Baseline != "" {
+		if err := r.updateBaseline(lt.Errors); err != nil {
+			return nil, err
+		}
+	}
+
 	if len(lt.Errors) > 0 {
 		for _, err := range lt.Errors {
 			le, ok := err.(*linter.LintError)
 			if !ok {
 				continue
 			}
+			if r.changedLines != nil && !r.changedLines.Contains(r.sourceMap, le.Token) {
+				r.unchanged++
+				continue
+			}
+			// Findings recorded in the baseline are not reported
+			if r.baseline != nil && r.baseline.Match(r.baselineFinding(le)) {
+				r.baselined++
+				continue
+			}
 			// check severity with overrides
 			severity := le.Severity
 			if v, ok := r.overrides[string(le.Rule)]; ok {
 				severity = v
 			}
 
+			if severity != linter.IGNORE {
+				r.findings[string(le.Rule)]++
+				r.fileFindings[le.Token.File]++
+			}
 			// Store all but ignored linter errors
-			if r.config.Json && severity != linter.IGNORE {
+			if r.config.MachineReadable() && severity != linter.IGNORE {
 				r.lintErrors[le.Token.File] = append(r.lintErrors[le.Token.File], le)
 			}
 			r.printLinterError(r.lexers[main.Name], severity, le)
 		}
 	}
+	if r.unchanged > 0 {
+		r.message(white, "%d finding(s) on lines unchanged since %s are not reported\n", r.unchanged, r.config.Linter.DiffBase)
+	}
+	if r.baselined > 0 {
+		r.message(white, "%d finding(s) recorded in baseline file %s are not reported\n", r.baselined, r.config.Linter.Baseline)
+	}
 
 	return &plugin.VCL{
 		File: main.Name,
@@ -275,9 +473,105 @@ func (r *Runner) run(ctx *context.Context, main *resolver.VCL, mode RunMode) (*p
 	}, nil
 }
 
+// fix lints and applies automatic fixes repeatedly until no more fix could be applied,
+// because fixing some errors might produce new fixable errors, e.g. removing unused subroutine
+// makes subroutines which are called only from it unused.
+func (r *Runner) fix(rslv resolver.Resolver, options []context.Option) error {
+	for i := 0; i < maxFixIterations; i++ {
+		main, err := rslv.MainVCL()
+		if err != nil {
+			return err
+		}
+		// Parse errors are not printed here, they are reported on the following lint run
+		vcl, err := r.parseSilently(main.Name, main.Data)
+		if err != nil {
+			return nil
+		}
+		if r.snippets != nil {
+			for _, snip := range r.snippets.EmbedSnippets() {
+				s, err := r.parseSilently(snip.Name, snip.Data)
+				if err != nil {
+					return nil
+				}
+				vcl.Statements = append(s.Statements, vcl.Statements...)
+			}
+		}
+
+		lt := linter.New(r.linterOptions...)
+		lt.Lint(vcl, context.New(options...))
+		if lt.FatalError != nil {
+			return nil
+		}
+
+		applied, err := r.applyFixes(lt.Errors)
+		if err != nil {
+			return err
+		}
+		if applied == 0 {
+			return nil
+		}
+	}
+	return nil
+}
+
+// applyFixes applies automatic fixes of lint errors to the source files and returns the number of applied fixes.
+// Conflicting fixes are skipped and they are applied on the next iteration.
+func (r *Runner) applyFixes(lintErrors []error) (int, error) {
+	fixes := make(map[string][]*linter.Fix)
+	var files []string
+	for _, err := range lintErrors {
+		le, ok := err.(*linter.LintError)
+		if !ok || le.Fix == nil || le.Token.File == "" {
+			continue
+		}
+		// Ignored rules should not be fixed
+		if v, ok := r.overrides[string(le.Rule)]; ok && v == linter.IGNORE {
+			continue
+		}
+		if _, ok := fixes[le.Token.File]; !ok {
+			files = append(files, le.Token.File)
+		}
+		fixes[le.Token.File] = append(fixes[le.Token.File], le.Fix)
+	}
+
+	var total int
+	for _, file := range files {
+		src, err := os.ReadFile(file)
+		if err != nil {
+			// Snippets and remote modules do not exist on the local filesystem
+			if os.IsNotExist(err) {
+				continue
+			}
+			return total, errors.WithStack(err)
+		}
+		fixed, applied, err := linter.ApplyFixes(string(src), fixes[file])
+		if err != nil {
+			return total, errors.WithStack(err)
+		}
+		if fixed == string(src) {
+			continue
+		}
+		if err := os.WriteFile(file, []byte(fixed), 0o644); err != nil {
+			return total, errors.WithStack(err)
+		}
+		r.message(white, "Applied %d fix(es) to %s\n", applied, file)
+		total += applied
+	}
+	return total, nil
+}
+
+func (r *Runner) newParser(lx *lexer.Lexer) *parser.Parser {
+	return parser.New(lx, append([]parser.OptionFunc{parser.WithDialect(r.config.Dialect)}, r.parserOptions...)...)
+}
+
+func (r *Runner) parseSilently(name, code string) (*ast.VCL, error) {
+	lx := lexer.NewFromString(code, lexer.WithFile(name))
+	return r.newParser(lx).ParseVCL()
+}
+
 func (r *Runner) parseVCL(name, code string) (*ast.VCL, error) {
 	lx := lexer.NewFromString(code, lexer.WithFile(name))
-	p := parser.New(lx)
+	p := r.newParser(lx)
 	vcl, err := p.ParseVCL()
 	if err != nil {
 		lx.NewLine()
@@ -288,11 +582,15 @@ func (r *Runner) parseVCL(name, code string) (*ast.VCL, error) {
 				file = "in " + pe.Token.File + " "
 			}
 			// Nothing to print to stdout if JSON mode is enabled, exit early.
-			if r.config.Json {
+			if r.config.MachineReadable() {
 				r.parseErrors[pe.Token.File] = pe
 			}
 			r.printParseError(lx, file, pe)
 		}
+		// Input exceeds max_input_bytes or max_tokens limit
+		if le, ok := errors.Cause(err).(*parser.LimitError); ok {
+			r.message(red, ":boom: %s\n", le.Error())
+		}
 		return nil, ErrParser
 	}
 
@@ -302,6 +600,10 @@ func (r *Runner) parseVCL(name, code string) (*ast.VCL, error) {
 }
 
 func (r *Runner) printParseError(lx *lexer.Lexer, file string, err *parser.ParseError) {
+	if r.config.CodeFrame {
+		r.message(red, ":boom: %s", diagnostics.Render(lx, err.Diagnostic(), diagnostics.WithContextLines(5)))
+		return
+	}
 	r.message(red, ":boom: %s\n%sat line %d, position %d\n", err.Message, file, err.Token.Line, err.Token.Position)
 
 	problemLine := err.Token.Line
@@ -358,7 +660,15 @@ func (r *Runner) printLinterError(lx *lexer.Lexer, severity linter.Severity, err
 		return
 	}
 
+	if r.config.CodeFrame {
+		r.message(white, "%s", diagnostics.Frame(lx, err.Diagnostic()))
+		r.printIncludeTrace(err.Token)
+		r.message(white, "\n")
+		return
+	}
+
 	r.message(white, "%sat line %d, position %d\n", file, err.Token.Line, err.Token.Position)
+	r.printIncludeTrace(err.Token)
 
 	problemLine := err.Token.Line
 	for l := problemLine - 1; l <= problemLine+1; l++ {
@@ -383,11 +693,32 @@ func (r *Runner) printLinterError(lx *lexer.Lexer, severity linter.Severity, err
 	if err.Reference != "" {
 		r.message(white, "See reference documentation: %s\n", err.Reference)
 	}
+	if err.Documentation != "" {
+		r.message(white, "See rule documentation: %s\n", err.Documentation)
+	}
+	if err.Fixable() && !r.config.Linter.Fix {
+		r.message(white, "This problem could be fixed automatically with -fix option\n")
+	}
 	r.message(white, "\n")
 }
 
+// Print include chain which leads to the token's file, if the token exists in the included module
+func (r *Runner) printIncludeTrace(t token.Token) {
+	if r.sourceMap == nil {
+		return
+	}
+	locations := r.sourceMap.Trace(t)
+	for _, loc := range locations[1:] {
+		r.message(white, "  included from %s\n", loc.String())
+	}
+}
+
 func (r *Runner) Stats(rslv resolver.Resolver) (*StatsResult, error) {
-	options := []context.Option{context.WithResolver(rslv)}
+	options := []context.Option{
+		context.WithResolver(rslv),
+		context.WithDialect(r.config.Dialect),
+		context.WithParserOptions(r.parserOptions...),
+	}
 	// If remote snippets exists, prepare parse and prepend to main VCL
 	if r.snippets != nil {
 		options = append(options, context.WithSnippets(r.snippets))
@@ -412,6 +743,7 @@ func (r *Runner) Stats(rslv resolver.Resolver) (*StatsResult, error) {
 		Backends:    len(ctx.Backends),
 		Acls:        len(ctx.Acls),
 		Directors:   len(ctx.Directors),
+		Complexity:  r.complexities,
 	}
 
 	for _, lx := range r.lexers {
@@ -428,6 +760,7 @@ func (r *Runner) Simulate(rslv resolver.Resolver) error {
 		icontext.WithResolver(rslv),
 		icontext.WithMaxBackends(r.config.OverrideMaxBackends),
 		icontext.WithMaxAcls(r.config.OverrideMaxAcls),
+		icontext.WithProbe(),
 	}
 	if r.snippets != nil {
 		options = append(options, icontext.WithSnippets(r.snippets))
@@ -435,9 +768,30 @@ func (r *Runner) Simulate(rslv resolver.Resolver) error {
 	if sc.OverrideRequest != nil {
 		options = append(options, icontext.WithRequest(sc.OverrideRequest))
 	}
+	if sc.Server != nil {
+		options = append(options, icontext.WithServer(sc.Server))
+	}
+	if sc.Seed != 0 {
+		options = append(options, icontext.WithRandomSeed(sc.Seed))
+	}
+	if sc.Client != nil {
+		options = append(options, icontext.WithClient(sc.Client))
+	}
+	if sc.GeoIP != nil {
+		db, err := geo.New(sc.GeoIP.Overrides, sc.GeoIP.Database)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		if db != nil {
+			options = append(options, icontext.WithGeo(db))
+		}
+	}
 	if r.config.OverrideBackends != nil {
 		options = append(options, icontext.WithOverrideBackends(r.config.OverrideBackends))
 	}
+	if len(r.config.BackendMocks) > 0 {
+		options = append(options, icontext.WithBackendMocks(r.config.BackendMocks))
+	}
 
 	i := interpreter.New(options...)
 
@@ -449,13 +803,30 @@ func (r *Runner) Simulate(rslv resolver.Resolver) error {
 	// Otherwise, simply start simulator server
 	mux := http.NewServeMux()
 	mux.Handle("/", i)
+	mux.Handle("/__falco/purge/", i.PurgeHandler())
 
+	// HTTP/2 is served over TLS, and over cleartext (h2c) for the plain HTTP server
 	s := &http.Server{
-		Handler: mux,
+		Handler: h2c.NewHandler(mux, &http2.Server{}),
 		Addr:    fmt.Sprintf(":%d", sc.Port),
 	}
-	writeln(green, "Simulator server starts on 0.0.0.0:%d", sc.Port)
-	return s.ListenAndServe()
+	if !sc.TLS {
+		writeln(green, "Simulator server starts on 0.0.0.0:%d", sc.Port)
+		return s.ListenAndServe()
+	}
+
+	cert, caFile, err := simulatorCertificate(sc)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	s.TLSConfig = &tls.Config{
+		Certificates: []tls.Certificate{cert},
+	}
+	if caFile != "" {
+		writeln(white, "Certificate is issued by the local CA, trust %s to access from browsers", caFile)
+	}
+	writeln(green, "Simulator server starts on https://0.0.0.0:%d", sc.Port)
+	return s.ListenAndServeTLS("", "")
 }
 
 func (r *Runner) Test(rslv resolver.Resolver) (*tester.TestFactory, error) {
@@ -474,6 +845,9 @@ func (r *Runner) Test(rslv resolver.Resolver) (*tester.TestFactory, error) {
 	if tc.OverrideHost != "" {
 		options = append(options, icontext.WithOverrideHost(tc.OverrideHost))
 	}
+	if len(r.config.BackendMocks) > 0 {
+		options = append(options, icontext.WithBackendMocks(r.config.BackendMocks))
+	}
 
 	i := interpreter.New(options...)
 	r.message(white, "Running tests...")
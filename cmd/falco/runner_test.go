@@ -1,13 +1,21 @@
 package main
 
 import (
+	"bytes"
+	"crypto/x509"
+	"encoding/xml"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
+	"github.com/google/go-cmp/cmp"
+	"github.com/ysugimoto/falco/ast"
 	"github.com/ysugimoto/falco/config"
 	"github.com/ysugimoto/falco/linter"
 	"github.com/ysugimoto/falco/resolver"
 	"github.com/ysugimoto/falco/terraform"
+	"github.com/ysugimoto/falco/token"
 )
 
 type RepoExampleTestMetadata struct {
@@ -330,3 +338,542 @@ func TestRepositoryExamplesJSONMode(t *testing.T) {
 		})
 	}
 }
+
+// Test cases for SARIF mode (-format sarif)
+func TestRepositoryExamplesSarifMode(t *testing.T) {
+	tests := loadRepoExampleTestMetadata()
+	c := &config.Config{
+		Format: config.FormatSarif,
+		Linter: &config.LinterConfig{
+			VerboseWarning: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resolvers, err := resolver.NewFileResolvers(tt.fileName, c.IncludePaths)
+			if err != nil {
+				t.Errorf("Unexpected runner creation error: %s", err)
+				return
+			}
+			r, err := NewRunner(c, nil)
+			if err != nil {
+				t.Errorf("Unexpected runner creation error: %s", err)
+				return
+			}
+			ret, err := r.Run(resolvers[0])
+			if err != nil {
+				t.Errorf("Unexpected error running Run(): %s", err)
+				return
+			}
+
+			log := r.Sarif(ret)
+			if log.Version != "2.1.0" || len(log.Runs) != 1 {
+				t.Errorf("Unexpected SARIF log: %v", log)
+				return
+			}
+			levels := map[string]int{}
+			for _, result := range log.Runs[0].Results {
+				levels[result.Level]++
+				rule := log.Runs[0].Tool.Driver.Rules[result.RuleIndex]
+				if rule.ID != result.RuleID {
+					t.Errorf("Rule index points %s, expects %s", rule.ID, result.RuleID)
+				}
+				region := result.Locations[0].PhysicalLocation.Region
+				if region.StartLine < 1 || region.StartColumn < 1 {
+					t.Errorf("Invalid region: %v", region)
+				}
+			}
+			if levels["note"] != tt.infos {
+				t.Errorf("Expected %d note results, got %d", tt.infos, levels["note"])
+			}
+			if levels["warning"] != tt.warnings {
+				t.Errorf("Expected %d warning results, got %d", tt.warnings, levels["warning"])
+			}
+			if levels["error"] != tt.errors {
+				t.Errorf("Expected %d error results, got %d", tt.errors, levels["error"])
+			}
+		})
+	}
+}
+
+func TestXMLReports(t *testing.T) {
+	c := &config.Config{
+		Format: config.FormatJunit,
+		Linter: &config.LinterConfig{
+			VerboseWarning: true,
+		},
+	}
+	// example 3 has one recommendation
+	resolvers, err := resolver.NewFileResolvers("../../examples/linter/default03.vcl", c.IncludePaths)
+	if err != nil {
+		t.Fatalf("Unexpected runner creation error: %s", err)
+	}
+	r, err := NewRunner(c, nil)
+	if err != nil {
+		t.Fatalf("Unexpected runner creation error: %s", err)
+	}
+	ret, err := r.Run(resolvers[0])
+	if err != nil {
+		t.Fatalf("Unexpected error running Run(): %s", err)
+	}
+
+	t.Run("checkstyle", func(t *testing.T) {
+		report := r.Checkstyle(ret)
+		if len(report.Files) != 1 || len(report.Files[0].Errors) != 1 {
+			t.Errorf("Expected one file with one error, got %v", report.Files)
+			return
+		}
+		e := report.Files[0].Errors[0]
+		if e.Severity != "info" || e.Line < 1 || !strings.HasPrefix(e.Source, "falco.") {
+			t.Errorf("Unexpected checkstyle error: %v", e)
+		}
+	})
+
+	t.Run("junit", func(t *testing.T) {
+		report := r.Junit(ret)
+		if report.Tests != 1 || report.Failures != 1 {
+			t.Errorf("Expected one failed test, got tests=%d failures=%d", report.Tests, report.Failures)
+		}
+		if _, err := xml.Marshal(report); err != nil {
+			t.Errorf("Failed to marshal JUnit report: %s", err)
+		}
+	})
+
+	t.Run("junit without errors", func(t *testing.T) {
+		report := r.Junit(&RunnerResult{})
+		if report.Tests != 1 || report.Failures != 0 {
+			t.Errorf("Expected one passed test, got tests=%d failures=%d", report.Tests, report.Failures)
+		}
+	})
+}
+
+func TestBaseline(t *testing.T) {
+	baseline := filepath.Join(t.TempDir(), "baseline.json")
+	run := func(update bool) *RunnerResult {
+		c := &config.Config{
+			Linter: &config.LinterConfig{
+				VerboseWarning: true,
+				Baseline:       baseline,
+				UpdateBaseline: update,
+			},
+		}
+		// example 3 has one recommendation
+		resolvers, err := resolver.NewFileResolvers("../../examples/linter/default03.vcl", c.IncludePaths)
+		if err != nil {
+			t.Fatalf("Unexpected runner creation error: %s", err)
+		}
+		r, err := NewRunner(c, nil)
+		if err != nil {
+			t.Fatalf("Unexpected runner creation error: %s", err)
+		}
+		ret, err := r.Run(resolvers[0])
+		if err != nil {
+			t.Fatalf("Unexpected error running Run(): %s", err)
+		}
+		return ret
+	}
+
+	if ret := run(false); ret.Infos != 1 {
+		t.Errorf("Infos expects 1 without baseline, got %d", ret.Infos)
+	}
+	if ret := run(true); ret.Infos != 0 {
+		t.Errorf("Infos expects 0 after updating baseline, got %d", ret.Infos)
+	}
+	if ret := run(false); ret.Infos != 0 {
+		t.Errorf("Infos expects 0 with baseline, got %d", ret.Infos)
+	}
+
+	b, err := loadBaseline(baseline)
+	if err != nil {
+		t.Fatalf("Unexpected error loading baseline: %s", err)
+	}
+	if len(b.Findings) != 1 || b.Findings[0].Source == "" {
+		t.Errorf("Expected one finding with source line, got %v", b.Findings)
+		return
+	}
+	// Each recorded finding matches only once
+	f := *b.Findings[0]
+	f.Line += 10
+	if !b.Match(&f) {
+		t.Errorf("Finding should match even if the line is shifted")
+	}
+	if b.Match(&f) {
+		t.Errorf("Finding should not match twice")
+	}
+}
+
+func TestChangedLines(t *testing.T) {
+	root := t.TempDir()
+	diff := `diff --git a/main.vcl b/main.vcl
+index 1111111..2222222 100644
+--- a/main.vcl
++++ b/main.vcl
+@@ -3,0 +4,2 @@ sub vcl_recv {
++  set req.http.A = "1";
++  include "module";
+@@ -10 +12 @@ sub vcl_recv {
+-  set req.http.B = "1";
++  set req.http.B = "2";
+@@ -20,2 +21,0 @@ sub vcl_recv {
+-  set req.http.C = "1";
+-  set req.http.D = "1";
+diff --git a/removed.vcl b/removed.vcl
+deleted file mode 100644
+--- a/removed.vcl
++++ /dev/null
+@@ -1 +0,0 @@
+-sub foo {}
+`
+	changed := parseUnifiedDiff(root, diff)
+	main := filepath.Join(root, "main.vcl")
+	module := filepath.Join(root, "module.vcl")
+
+	for line, expect := range map[int]bool{3: false, 4: true, 5: true, 6: false, 12: true, 21: false} {
+		tok := token.Token{File: main, Line: line}
+		if actual := changed.Contains(nil, tok); actual != expect {
+			t.Errorf("Line %d should be changed=%t", line, expect)
+		}
+	}
+
+	// Finding in the module is changed when its include statement is changed
+	sourceMap := ast.NewSourceMap()
+	sourceMap.Add(module, &ast.IncludeStatement{
+		Meta: ast.New(token.Token{File: main, Line: 5, Position: 3}, 1),
+	})
+	if !changed.Contains(sourceMap, token.Token{File: module, Line: 1}) {
+		t.Errorf("Finding in the newly included module should be changed")
+	}
+	if changed.Contains(nil, token.Token{File: module, Line: 1}) {
+		t.Errorf("Finding in the module should not be changed without source map")
+	}
+}
+
+func TestParallelLint(t *testing.T) {
+	c := &config.Config{
+		Json: true,
+		Linter: &config.LinterConfig{
+			VerboseWarning: true,
+		},
+	}
+	lint := func(workers int) (string, string, bool) {
+		var jobs []*lintJob
+		for _, tt := range loadRepoExampleTestMetadata() {
+			resolvers, err := resolver.NewFileResolvers(tt.fileName, c.IncludePaths)
+			if err != nil {
+				t.Fatalf("Unexpected runner creation error: %s", err)
+			}
+			r, err := NewRunner(c, nil)
+			if err != nil {
+				t.Fatalf("Unexpected runner creation error: %s", err)
+			}
+			jobs = append(jobs, &lintJob{runner: r, resolver: resolvers[0], header: tt.name + "\n"})
+		}
+		var output, stdout bytes.Buffer
+		failed := runLintJobs(jobs, workers, &output, &stdout)
+		return output.String(), stdout.String(), failed
+	}
+
+	serialOutput, serialStdout, serialFailed := lint(1)
+	parallelOutput, parallelStdout, parallelFailed := lint(4)
+	if serialFailed != parallelFailed {
+		t.Errorf("Result mismatch between serial and parallel lint, serial=%t, parallel=%t", serialFailed, parallelFailed)
+	}
+	if !strings.Contains(parallelOutput, "example 4") || !strings.Contains(parallelStdout, "LintErrors") {
+		t.Errorf("Outputs of all services should be written")
+	}
+	if diff := cmp.Diff(serialOutput, parallelOutput); diff != "" {
+		t.Errorf("Output mismatch between serial and parallel lint, diff=%s", diff)
+	}
+	if diff := cmp.Diff(serialStdout, parallelStdout); diff != "" {
+		t.Errorf("Stdout mismatch between serial and parallel lint, diff=%s", diff)
+	}
+}
+
+func TestExitPolicy(t *testing.T) {
+	tests := []struct {
+		name        string
+		failOn      []string
+		maxWarnings int
+		violations  int
+	}{
+		{name: "default", maxWarnings: -1},
+		{name: "fail on info", failOn: []string{"info"}, maxWarnings: -1, violations: 1},
+		{name: "fail on warning", failOn: []string{"warning"}, maxWarnings: -1},
+		{name: "fail on rule", failOn: []string{"regex/matched-value-override"}, maxWarnings: -1, violations: 1},
+		{name: "fail on other rule", failOn: []string{"unused/variable"}, maxWarnings: -1},
+		{name: "max warnings", maxWarnings: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &config.Config{
+				Linter: &config.LinterConfig{
+					FailOn:      tt.failOn,
+					MaxWarnings: tt.maxWarnings,
+				},
+			}
+			// example 3 has one recommendation
+			resolvers, err := resolver.NewFileResolvers("../../examples/linter/default03.vcl", c.IncludePaths)
+			if err != nil {
+				t.Fatalf("Unexpected runner creation error: %s", err)
+			}
+			r, err := NewRunner(c, nil)
+			if err != nil {
+				t.Fatalf("Unexpected runner creation error: %s", err)
+			}
+			ret, err := r.Run(resolvers[0])
+			if err != nil {
+				t.Fatalf("Unexpected error running Run(): %s", err)
+			}
+			if violations := r.exitPolicyViolations(ret); len(violations) != tt.violations {
+				t.Errorf("Expected %d violations, got %v", tt.violations, violations)
+			}
+		})
+	}
+}
+
+func TestLintSummary(t *testing.T) {
+	c := &config.Config{
+		Linter: &config.LinterConfig{
+			Summary: true,
+		},
+	}
+	// example 3 has one recommendation
+	resolvers, err := resolver.NewFileResolvers("../../examples/linter/default03.vcl", c.IncludePaths)
+	if err != nil {
+		t.Fatalf("Unexpected runner creation error: %s", err)
+	}
+	r, err := NewRunner(c, nil)
+	if err != nil {
+		t.Fatalf("Unexpected runner creation error: %s", err)
+	}
+	ret, err := r.Run(resolvers[0])
+	if err != nil {
+		t.Fatalf("Unexpected error running Run(): %s", err)
+	}
+
+	s := ret.Summary
+	if s == nil {
+		t.Fatalf("Expected summary in the result")
+	}
+	if s.Severities["info"] != 1 || s.Severities["warning"] != 0 || s.Severities["error"] != 0 {
+		t.Errorf("Unexpected severities: %v", s.Severities)
+	}
+	if len(s.Rules) == 0 || s.Rules[0].Rule != "regex/matched-value-override" || s.Rules[0].Findings != 1 {
+		t.Errorf("Expected the noisiest rule regex/matched-value-override, got %v", s.Rules)
+	}
+	var timed bool
+	for _, v := range s.Rules {
+		if v.Rule == "core" && v.TimeMs > 0 {
+			timed = true
+		}
+	}
+	if !timed {
+		t.Errorf("Expected elapsed time of core")
+	}
+	if len(s.Files) != 1 || s.Files[0].Findings != 1 || !strings.HasSuffix(s.Files[0].File, "default03.vcl") {
+		t.Errorf("Unexpected files: %v", s.Files)
+	}
+}
+
+func TestSimulatorCertificate(t *testing.T) {
+	dir := t.TempDir()
+	sc := &config.SimulatorConfig{TLS: true, CADir: dir}
+
+	cert, caFile, err := simulatorCertificate(sc)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if caFile != filepath.Join(dir, localCACertFile) {
+		t.Errorf("Unexpected CA file path: %s", caFile)
+	}
+
+	// The local CA is reused for the next certificate
+	ca, err := os.ReadFile(caFile)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if _, _, err := simulatorCertificate(sc); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if reused, err := os.ReadFile(caFile); err != nil || !bytes.Equal(ca, reused) {
+		t.Errorf("Expected local CA to be reused")
+	}
+
+	roots := x509.NewCertPool()
+	if !roots.AppendCertsFromPEM(ca) {
+		t.Fatalf("Failed to load local CA")
+	}
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	for _, host := range localCertificateHosts {
+		if _, err := leaf.Verify(x509.VerifyOptions{DNSName: host, Roots: roots}); err != nil {
+			t.Errorf("Certificate should be valid for %s: %s", host, err)
+		}
+	}
+}
+
+func TestLintTemplatedVCL(t *testing.T) {
+	dir := t.TempDir()
+	main := filepath.Join(dir, "main.vcl")
+	files := map[string]string{
+		main: `
+include "module";
+
+sub vcl_recv {
+  #FASTLY recv
+  call module_recv;
+  return(lookup);
+}`,
+		filepath.Join(dir, "module.vcl"): `
+sub module_recv {
+  set req.http.X-Backend = {{ .Backend }};
+}`,
+	}
+	for name, data := range files {
+		if err := os.WriteFile(name, []byte(data), 0o644); err != nil {
+			t.Fatalf("Failed to write %s: %s", name, err)
+		}
+	}
+
+	c := &config.Config{
+		IncludePaths: []string{dir},
+		Linter:       &config.LinterConfig{},
+	}
+	resolvers, err := resolver.NewFileResolvers(main, c.IncludePaths)
+	if err != nil {
+		t.Fatalf("Unexpected resolver creation error: %s", err)
+	}
+
+	t.Run("placeholders are not configured", func(t *testing.T) {
+		r, err := NewRunner(c, nil)
+		if err != nil {
+			t.Fatalf("Unexpected runner creation error: %s", err)
+		}
+		r.output = &bytes.Buffer{}
+		if _, err := r.Run(resolvers[0]); err == nil {
+			t.Errorf("Expected parse error of the included module")
+		}
+	})
+
+	t.Run("placeholders are configured", func(t *testing.T) {
+		pc := *c
+		pc.Placeholders = []string{"{{ }}"}
+		r, err := NewRunner(&pc, nil)
+		if err != nil {
+			t.Fatalf("Unexpected runner creation error: %s", err)
+		}
+		ret, err := r.Run(resolvers[0])
+		if err != nil {
+			t.Fatalf("Unexpected error running Run(): %s", err)
+		}
+		if ret.Errors != 0 {
+			t.Errorf("Expected no errors, got %d", ret.Errors)
+		}
+	})
+
+	t.Run("invalid placeholder", func(t *testing.T) {
+		pc := *c
+		pc.Placeholders = []string{"{{}}"}
+		if _, err := NewRunner(&pc, nil); err == nil {
+			t.Errorf("Expected runner creation error")
+		}
+	})
+}
+
+func TestLintImportModules(t *testing.T) {
+	dir := t.TempDir()
+	main := filepath.Join(dir, "main.vcl")
+	vcl := `
+import boltsort;
+
+sub vcl_recv {
+  #FASTLY recv
+  return(lookup);
+}`
+	if err := os.WriteFile(main, []byte(vcl), 0o644); err != nil {
+		t.Fatalf("Failed to write %s: %s", main, err)
+	}
+	resolvers, err := resolver.NewFileResolvers(main, nil)
+	if err != nil {
+		t.Fatalf("Unexpected resolver creation error: %s", err)
+	}
+
+	tests := []struct {
+		name    string
+		modules []string
+		isError bool
+	}{
+		{name: "modules are not registered", modules: nil},
+		{name: "known module", modules: []string{"boltsort"}},
+		{name: "unknown module", modules: []string{"std"}, isError: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &config.Config{
+				ImportModules: tt.modules,
+				Linter:        &config.LinterConfig{},
+			}
+			r, err := NewRunner(c, nil)
+			if err != nil {
+				t.Fatalf("Unexpected runner creation error: %s", err)
+			}
+			r.output = &bytes.Buffer{}
+			_, err = r.Run(resolvers[0])
+			if tt.isError && err == nil {
+				t.Errorf("Expected parse error but got nil")
+			} else if !tt.isError && err != nil {
+				t.Errorf("Unexpected error: %s", err)
+			}
+		})
+	}
+}
+
+func TestLintParserLimits(t *testing.T) {
+	resolvers, err := resolver.NewFileResolvers("../../examples/linter/default01.vcl", nil)
+	if err != nil {
+		t.Fatalf("Unexpected resolver creation error: %s", err)
+	}
+
+	tests := []struct {
+		name          string
+		maxInputBytes int64
+		maxTokens     int
+		isError       bool
+	}{
+		{name: "unlimited"},
+		{name: "input bytes exceed the limit", maxInputBytes: 16, isError: true},
+		{name: "tokens exceed the limit", maxTokens: 16, isError: true},
+		{name: "within the limits", maxInputBytes: 1 << 20, maxTokens: 1 << 20},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &config.Config{
+				MaxInputBytes: tt.maxInputBytes,
+				MaxTokens:     tt.maxTokens,
+				Linter:        &config.LinterConfig{},
+			}
+			r, err := NewRunner(c, nil)
+			if err != nil {
+				t.Fatalf("Unexpected runner creation error: %s", err)
+			}
+			buf := &bytes.Buffer{}
+			r.output = buf
+			_, err = r.Run(resolvers[0])
+			if !tt.isError {
+				if err != nil {
+					t.Errorf("Unexpected error: %s", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Errorf("Expected limit error but got nil")
+			} else if !strings.Contains(buf.String(), "exceeds the limit") {
+				t.Errorf("Expected limit error message, got %s", buf.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,185 @@
+package main
+
+import (
+	"path/filepath"
+	"unicode/utf8"
+
+	"github.com/ysugimoto/falco/linter"
+	"github.com/ysugimoto/falco/token"
+)
+
+// SARIF 2.1.0 output for GitHub code scanning and other static analysis dashboards.
+// Only the subset of the specification which falco results need is defined.
+// See https://docs.oasis-open.org/sarif/sarif/v2.1.0/sarif-v2.1.0.html
+const (
+	sarifVersion = "2.1.0"
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+)
+
+type SarifLog struct {
+	Version string      `json:"version"`
+	Schema  string      `json:"$schema"`
+	Runs    []*SarifRun `json:"runs"`
+}
+
+type SarifRun struct {
+	Tool       SarifTool      `json:"tool"`
+	Results    []*SarifResult `json:"results"`
+	ColumnKind string         `json:"columnKind"`
+	// Service which the results belong to, when linting terraform planned services
+	Properties *SarifRunProperties `json:"properties,omitempty"`
+}
+
+type SarifRunProperties struct {
+	Service string `json:"service"`
+	Address string `json:"address,omitempty"`
+}
+
+type SarifTool struct {
+	Driver SarifDriver `json:"driver"`
+}
+
+type SarifDriver struct {
+	Name           string       `json:"name"`
+	Version        string       `json:"version,omitempty"`
+	InformationUri string       `json:"informationUri"`
+	Rules          []*SarifRule `json:"rules"`
+}
+
+type SarifRule struct {
+	ID         string              `json:"id"`
+	HelpUri    string              `json:"helpUri,omitempty"`
+	Properties SarifRuleProperties `json:"properties"`
+}
+
+type SarifRuleProperties struct {
+	Category  string   `json:"category"`
+	Tags      []string `json:"tags"`
+	Reference string   `json:"reference,omitempty"`
+}
+
+type SarifResult struct {
+	RuleID     string                `json:"ruleId"`
+	RuleIndex  int                   `json:"ruleIndex"`
+	Level      string                `json:"level"`
+	Message    SarifMessage          `json:"message"`
+	Locations  []*SarifLocation      `json:"locations"`
+	Properties SarifResultProperties `json:"properties"`
+}
+
+type SarifResultProperties struct {
+	Fixable bool `json:"fixable"`
+}
+
+type SarifMessage struct {
+	Text string `json:"text"`
+}
+
+type SarifLocation struct {
+	PhysicalLocation SarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type SarifPhysicalLocation struct {
+	ArtifactLocation SarifArtifactLocation `json:"artifactLocation"`
+	Region           SarifRegion           `json:"region"`
+}
+
+type SarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type SarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn"`
+	EndColumn   int `json:"endColumn"`
+}
+
+// sarifLevels maps linter severity to SARIF result level
+var sarifLevels = map[linter.Severity]string{
+	linter.ERROR:   "error",
+	linter.WARNING: "warning",
+	linter.INFO:    "note",
+}
+
+// Sarif converts the runner result to SARIF log.
+// Severity overrides are applied and ignored errors are not included.
+func (r *Runner) Sarif(result *RunnerResult) *SarifLog {
+	driver := SarifDriver{
+		Name:           "falco",
+		Version:        version,
+		InformationUri: "https://github.com/ysugimoto/falco",
+		Rules:          []*SarifRule{},
+	}
+	ruleIndex := make(map[string]int)
+
+	results := []*SarifResult{}
+	for _, e := range r.reportedErrors(result) {
+		index, ok := ruleIndex[e.Rule]
+		if !ok {
+			index = len(driver.Rules)
+			ruleIndex[e.Rule] = index
+			// Rule documentation explains the rule, fallback to the reference for parse errors
+			helpUri := e.Documentation
+			if helpUri == "" {
+				helpUri = e.Reference
+			}
+			driver.Rules = append(driver.Rules, &SarifRule{
+				ID:      e.Rule,
+				HelpUri: helpUri,
+				Properties: SarifRuleProperties{
+					Category:  e.Category,
+					Tags:      []string{e.Category},
+					Reference: e.Reference,
+				},
+			})
+		}
+		results = append(results, &SarifResult{
+			RuleID:     e.Rule,
+			RuleIndex:  index,
+			Level:      sarifLevels[e.Severity],
+			Message:    SarifMessage{Text: e.Message},
+			Locations:  []*SarifLocation{sarifLocation(e.Token)},
+			Properties: SarifResultProperties{Fixable: e.Fixable},
+		})
+	}
+
+	run := &SarifRun{
+		Tool:       SarifTool{Driver: driver},
+		Results:    results,
+		ColumnKind: "unicodeCodePoints",
+	}
+	if result.Service != "" {
+		run.Properties = &SarifRunProperties{
+			Service: result.Service,
+			Address: result.Address,
+		}
+	}
+
+	return &SarifLog{
+		Version: sarifVersion,
+		Schema:  sarifSchema,
+		Runs:    []*SarifRun{run},
+	}
+}
+
+// sarifLocation returns the location of the token.
+// File path is relative path from working directory because code scanning resolves it from the repository root.
+func sarifLocation(tok token.Token) *SarifLocation {
+	line, column := tok.Line, tok.Position
+	if line < 1 {
+		line = 1
+	}
+	if column < 1 {
+		column = 1
+	}
+	return &SarifLocation{
+		PhysicalLocation: SarifPhysicalLocation{
+			ArtifactLocation: SarifArtifactLocation{URI: filepath.ToSlash(relativePath(tok.File))},
+			Region: SarifRegion{
+				StartLine:   line,
+				StartColumn: column,
+				EndColumn:   column + utf8.RuneCountInString(tok.Literal),
+			},
+		},
+	}
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"sort"
+	"time"
+)
+
+// Number of rules and files displayed in the summary, JSON output contains all of them
+const maxSummaryItems = 10
+
+// LintSummary is the counts of findings per severity, rule and file, and elapsed time of rules.
+// Teams could find slow rules and noisy rules in tuning their configuration.
+type LintSummary struct {
+	Severities map[string]int `json:"severities"`
+	Rules      []*RuleSummary `json:"rules"`
+	Files      []*FileSummary `json:"files"`
+}
+
+type RuleSummary struct {
+	Rule     string `json:"rule"`
+	Findings int    `json:"findings"`
+	// Elapsed time in milliseconds, rules which do not have dedicated check like type checking are summed up as "core"
+	TimeMs float64 `json:"time_ms"`
+}
+
+type FileSummary struct {
+	File     string `json:"file"`
+	Findings int    `json:"findings"`
+}
+
+// summary aggregates reported findings and timings of the run.
+// Rules are ordered by the number of findings, files are ordered by the number of findings.
+func (r *Runner) summary(result *RunnerResult) *LintSummary {
+	s := &LintSummary{
+		Severities: map[string]int{
+			"error":   result.Errors,
+			"warning": result.Warnings,
+			"info":    result.Infos,
+		},
+		Rules: []*RuleSummary{},
+		Files: []*FileSummary{},
+	}
+
+	rules := make(map[string]*RuleSummary)
+	for rule, n := range r.findings {
+		rules[rule] = &RuleSummary{Rule: rule, Findings: n}
+	}
+	for rule, d := range r.timings {
+		v, ok := rules[rule]
+		if !ok {
+			v = &RuleSummary{Rule: rule}
+			rules[rule] = v
+		}
+		v.TimeMs = float64(d) / float64(time.Millisecond)
+	}
+	for _, v := range rules {
+		s.Rules = append(s.Rules, v)
+	}
+	sort.Slice(s.Rules, func(i, j int) bool {
+		if s.Rules[i].Findings != s.Rules[j].Findings {
+			return s.Rules[i].Findings > s.Rules[j].Findings
+		}
+		return s.Rules[i].Rule < s.Rules[j].Rule
+	})
+
+	for file, n := range r.fileFindings {
+		s.Files = append(s.Files, &FileSummary{File: relativePath(file), Findings: n})
+	}
+	sort.Slice(s.Files, func(i, j int) bool {
+		if s.Files[i].Findings != s.Files[j].Findings {
+			return s.Files[i].Findings > s.Files[j].Findings
+		}
+		return s.Files[i].File < s.Files[j].File
+	})
+	return s
+}
+
+// printSummary displays noisy rules, slow rules and files which have many findings
+func (r *Runner) printSummary(s *LintSummary) {
+	r.writeln(white, "\nSummary:")
+	r.writeln(white, "  Severities: %d errors, %d warnings, %d recommendations", s.Severities["error"], s.Severities["warning"], s.Severities["info"])
+
+	r.writeln(white, "  Noisy rules:")
+	for i, v := range s.Rules {
+		if i == maxSummaryItems || v.Findings == 0 {
+			break
+		}
+		r.writeln(white, "    %-40s %d", v.Rule, v.Findings)
+	}
+
+	slow := append([]*RuleSummary{}, s.Rules...)
+	sort.SliceStable(slow, func(i, j int) bool {
+		return slow[i].TimeMs > slow[j].TimeMs
+	})
+	r.writeln(white, "  Slow rules:")
+	for i, v := range slow {
+		if i == maxSummaryItems || v.TimeMs == 0 {
+			break
+		}
+		r.writeln(white, "    %-40s %.3fms", v.Rule, v.TimeMs)
+	}
+
+	r.writeln(white, "  Files:")
+	for i, v := range s.Files {
+		if i == maxSummaryItems {
+			break
+		}
+		r.writeln(white, "    %-40s %d", v.File, v.Findings)
+	}
+}
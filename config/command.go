@@ -14,12 +14,32 @@ func (c Commands) At(n int) string {
 }
 
 var needValueOptions = map[string]struct{}{
-	"-I":             {},
-	"--include_path": {},
-	"-t":             {},
-	"--transformer":  {},
-	"-f":             {},
-	"--filter":       {},
+	"-I":                {},
+	"--include_path":    {},
+	"-t":                {},
+	"--transformer":     {},
+	"-f":                {},
+	"--filter":          {},
+	"-format":           {},
+	"--format":          {},
+	"-baseline":         {},
+	"--baseline":        {},
+	"-diff_base":        {},
+	"--diff_base":       {},
+	"-fail_on":          {},
+	"--fail_on":         {},
+	"-max_warnings":     {},
+	"--max_warnings":    {},
+	"-dialect":          {},
+	"--dialect":         {},
+	"-placeholder":      {},
+	"--placeholder":     {},
+	"-import_module":    {},
+	"--import_module":   {},
+	"-max_input_bytes":  {},
+	"--max_input_bytes": {},
+	"-max_tokens":       {},
+	"--max_tokens":      {},
 }
 
 func parseCommands(args []string) Commands {
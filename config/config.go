@@ -12,33 +12,156 @@ var (
 	configurationFiles = []string{".falco.yaml", ".falco.yml"}
 )
 
+// Output formats of the results
+const (
+	FormatJson       = "json"
+	FormatSarif      = "sarif"
+	FormatCheckstyle = "checkstyle"
+	FormatJunit      = "junit"
+)
+
 type OverrideBackend struct {
 	Host      string `yaml:"host"`
 	SSL       bool   `yaml:"ssl" default:"true"`
 	Unhealthy bool   `yaml:"unhealthy" default:"false"`
 }
 
+// BackendMock stubs the response of the backend request which matches the conditions,
+// so that the interpreter works without sending any request to the real origin
+type BackendMock struct {
+	Backend  string               `yaml:"backend"`
+	Request  *BackendMockRequest  `yaml:"request"`
+	Response *BackendMockResponse `yaml:"response"`
+}
+
+// BackendMockRequest is the conditions of the backend request, all fields are optional
+type BackendMockRequest struct {
+	Method  string            `yaml:"method"`
+	Path    string            `yaml:"path"`
+	Headers map[string]string `yaml:"headers"`
+}
+
+// BackendMockResponse is the stubbed backend response
+type BackendMockResponse struct {
+	Status   int               `yaml:"status"`
+	Headers  map[string]string `yaml:"headers"`
+	Body     string            `yaml:"body"`
+	BodyFile string            `yaml:"body_file"`
+	Latency  string            `yaml:"latency"`
+}
+
 // Linter configuration
 type LinterConfig struct {
-	VerboseLevel   string            `yaml:"verbose"`
-	VerboseWarning bool              `cli:"v"`
-	VerboseInfo    bool              `cli:"vv"`
-	Rules          map[string]string `yaml:"rules"`
+	VerboseLevel             string                 `yaml:"verbose"`
+	VerboseWarning           bool                   `cli:"v"`
+	VerboseInfo              bool                   `cli:"vv"`
+	Rules                    map[string]*RuleConfig `yaml:"rules"`
+	ReportUnusedSuppressions bool                   `yaml:"report_unused_suppressions"`
+	Plugins                  []string               `yaml:"plugins"`
+	WasmRules                []string               `yaml:"wasm_rules"`
+	Fix                      bool                   `cli:"fix"`
+	Baseline                 string                 `cli:"baseline" yaml:"baseline" default:".falco-baseline.json"`
+	UpdateBaseline           bool                   `cli:"update_baseline"`
+	DiffBase                 string                 `cli:"diff_base"`
+	FailOn                   []string               `cli:"fail_on" yaml:"fail_on"`
+	MaxWarnings              int                    `cli:"max_warnings" yaml:"max_warnings" default:"-1"`
+	Summary                  bool                   `cli:"summary" yaml:"summary"`
+}
+
+// Linter rule configuration, accepts severity string or object form:
+//
+//	rules:
+//	  acl/syntax: error
+//	  table/item-limitation:
+//	    severity: warning
+//	    options:
+//	      max_items: 5000
+type RuleConfig struct {
+	Severity string                 `yaml:"severity"`
+	Options  map[string]interface{} `yaml:"options"`
+}
+
+func (r *RuleConfig) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var severity string
+	if err := unmarshal(&severity); err == nil {
+		r.Severity = severity
+		return nil
+	}
+
+	type raw RuleConfig
+	var v raw
+	if err := unmarshal(&v); err != nil {
+		return errors.WithStack(err)
+	}
+	*r = RuleConfig(v)
+	return nil
 }
 
 // Simulator configuration
 type SimulatorConfig struct {
 	Port         int      `cli:"p,port" yaml:"port" default:"3124"`
-	IsDebug      bool     `cli:"debug"` // Enable only in CLI option
+	Seed         int64    `cli:"seed" yaml:"seed"` // Random seed, zero means unseeded
+	IsDebug      bool     `cli:"debug"`            // Enable only in CLI option
 	IncludePaths []string // Copy from root field
 
+	// Serve HTTPS, the certificate issued by the local CA is used if the certificate files are not specified
+	TLS      bool   `cli:"tls" yaml:"tls"`
+	CertFile string `cli:"cert" yaml:"cert_file"`
+	KeyFile  string `cli:"key" yaml:"key_file"`
+	CADir    string `yaml:"ca_dir"` // Directory where the local CA is stored, default is ~/.falco
+
 	// Override Request configuration
 	OverrideRequest *RequestConfig
+
+	// Server variables of the simulated edge POP
+	Server *ServerConfig `yaml:"server"`
+
+	// Geolocation data of client.geo.* variables
+	GeoIP *GeoIPConfig `yaml:"geoip"`
+
+	// Spoofed client identity of the request
+	Client *ClientConfig `yaml:"client"`
+}
+
+// Server configuration which is exposed as server.* and fastly.ff.* variables in the simulator.
+// Empty fields use the simulator's default values.
+type ServerConfig struct {
+	Datacenter string `yaml:"datacenter"`
+	Region     string `yaml:"region"`
+	Hostname   string `yaml:"hostname"`
+	Pop        string `yaml:"pop"`
+	// Number of Fastly nodes which the request has visited before the simulator
+	Visits int `yaml:"ff_visits"`
+}
+
+// Client configuration which spoofs client.*, tls.client.ja3_md5 and client.bot.* variables in the simulator.
+// Empty fields use the actual request values.
+type ClientConfig struct {
+	IP       string `yaml:"ip"`
+	ASNumber int64  `yaml:"as_number"`
+	ASName   string `yaml:"as_name"`
+	Identity string `yaml:"identity"`
+	JA3MD5   string `yaml:"ja3_md5"`
+	// The request is treated as a bot when the name is not empty
+	BotName string `yaml:"bot_name"`
+	// Emulated protocol of the request like "HTTP/2" or "HTTP/3", the actual protocol is used if empty
+	Protocol      string `yaml:"protocol"`
+	H2Fingerprint string `yaml:"h2_fingerprint"`
+}
+
+// GeoIP configuration which populates client.geo.* variables in the simulator.
+// Overrides file is looked up first, then MaxMind database.
+type GeoIPConfig struct {
+	// Path to YAML or JSON file which has locations keyed by IP address or CIDR network
+	Overrides string `yaml:"overrides"`
+	// Path to MaxMind GeoLite2 (or GeoIP2) City database
+	Database string `yaml:"database"`
 }
 
 // Testing configuration
 type TestConfig struct {
 	Timeout      int      `cli:"t,timeout" yaml:"timeout"`
+	Seed         int64    `cli:"seed" yaml:"seed"` // Random seed, zero means unseeded
 	Filter       string   `cli:"f,filter" default:"*.test.vcl"`
 	IncludePaths []string // Copy from root field
 	OverrideHost string   `yaml:"host"`
@@ -55,7 +178,14 @@ type Config struct {
 	Version      bool     `cli:"V"`
 	Remote       bool     `cli:"r,remote" yaml:"remote"`
 	Json         bool     `cli:"json"`
+	Format       string   `cli:"format" yaml:"format"`
+	CodeFrame    bool     `cli:"code_frame" yaml:"code_frame"`
+	Dialect      string   `cli:"dialect" yaml:"dialect"`
 	Request      string   `cli:"request"`
+	// Template placeholder delimiters separated by whitespace like "{{ }}"
+	Placeholders []string `cli:"placeholder" yaml:"placeholders"`
+	// Known module names of import statement, any module is accepted if empty
+	ImportModules []string `cli:"import_module" yaml:"import_modules"`
 
 	// Remote options, only provided via environment variable
 	FastlyServiceID string `env:"FASTLY_SERVICE_ID"`
@@ -67,10 +197,17 @@ type Config struct {
 	// Override Origin fetching URL
 	OverrideBackends map[string]*OverrideBackend `yaml:"override_backends"`
 
+	// Stub backend responses instead of fetching from Origin
+	BackendMocks []*BackendMock `yaml:"backend_mocks"`
+
 	// Override resource limits
 	OverrideMaxBackends int `cli:"max_backends" yaml:"max_backends"`
 	OverrideMaxAcls     int `cli:"mac_acls" yaml:"max_acls"`
 
+	// Parser input limits for each VCL file, zero means unlimited
+	MaxInputBytes int64 `cli:"max_input_bytes" yaml:"max_input_bytes"`
+	MaxTokens     int   `cli:"max_tokens" yaml:"max_tokens"`
+
 	// Linter configuration
 	Linter *LinterConfig `yaml:"linter"`
 	// Simulator configuration
@@ -112,6 +249,17 @@ func New(args []string) (*Config, error) {
 		c.Linter.VerboseInfo = true
 	}
 
+	// Merge output format flags
+	switch c.Format {
+	case "":
+		if c.Json {
+			c.Format = FormatJson
+		}
+	case FormatJson, FormatSarif, FormatCheckstyle, FormatJunit:
+	default:
+		return nil, errors.Errorf("Unknown output format %s", c.Format)
+	}
+
 	// Load request configuration if provided
 	if c.Request != "" {
 		if rc, err := LoadRequestConfig(c.Request); err == nil {
@@ -127,6 +275,11 @@ func New(args []string) (*Config, error) {
 	return c, nil
 }
 
+// MachineReadable returns true when results are output in a machine-readable format like JSON
+func (c *Config) MachineReadable() bool {
+	return c.Json || c.Format != ""
+}
+
 func findConfigFile() (string, error) {
 	// find up configuration file
 	cwd, err := os.Getwd()
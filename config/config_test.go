@@ -4,6 +4,7 @@ import (
 	"os"
 	"testing"
 
+	"github.com/go-yaml/yaml"
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
 )
@@ -47,16 +48,22 @@ func TestConfigFromCLI(t *testing.T) {
 		Version:  true,
 		Remote:   true,
 		Json:     true,
+		Format:   FormatJson,
 		Commands: Commands{"lint"},
 		Linter: &LinterConfig{
 			VerboseLevel:   "",
 			VerboseWarning: true,
 			VerboseInfo:    true,
+			Baseline:       ".falco-baseline.json",
+			MaxWarnings:    -1,
 		},
 		Simulator: &SimulatorConfig{
 			Port:            3124,
 			IncludePaths:    []string{"."},
 			OverrideRequest: &RequestConfig{},
+			Server:          &ServerConfig{},
+			GeoIP:           &GeoIPConfig{},
+			Client:          &ClientConfig{},
 		},
 		Testing: &TestConfig{
 			Filter:          "*.test.vcl",
@@ -71,6 +78,127 @@ func TestConfigFromCLI(t *testing.T) {
 	}
 }
 
+func TestExitPolicyFromCLI(t *testing.T) {
+	c, err := New([]string{"--fail_on", "warning", "--fail_on", "unused/variable", "--max_warnings", "10", "lint"})
+	if err != nil {
+		t.Fatalf("Failed to initialize config: %s", err)
+	}
+	if diff := cmp.Diff([]string{"warning", "unused/variable"}, c.Linter.FailOn); diff != "" {
+		t.Errorf("Unmatch fail_on, diff=%s", diff)
+	}
+	if c.Linter.MaxWarnings != 10 {
+		t.Errorf("Expected max_warnings 10, got %d", c.Linter.MaxWarnings)
+	}
+	if diff := cmp.Diff(Commands{"lint"}, c.Commands); diff != "" {
+		t.Errorf("Unmatch parsed commands, diff=%s", diff)
+	}
+}
+
+func TestPlaceholdersFromCLI(t *testing.T) {
+	c, err := New([]string{"--placeholder", "{{ }}", "-placeholder", "%{ }", "lint", "main.vcl"})
+	if err != nil {
+		t.Fatalf("Failed to initialize config: %s", err)
+	}
+	if diff := cmp.Diff([]string{"{{ }}", "%{ }"}, c.Placeholders); diff != "" {
+		t.Errorf("Unmatch placeholders, diff=%s", diff)
+	}
+	if diff := cmp.Diff(Commands{"lint", "main.vcl"}, c.Commands); diff != "" {
+		t.Errorf("Unmatch parsed commands, diff=%s", diff)
+	}
+}
+
+func TestDialectFromCLI(t *testing.T) {
+	c, err := New([]string{"--dialect", "varnish4", "lint", "main.vcl"})
+	if err != nil {
+		t.Fatalf("Failed to initialize config: %s", err)
+	}
+	if c.Dialect != "varnish4" {
+		t.Errorf("Unmatch dialect, expect varnish4 but got %s", c.Dialect)
+	}
+	if diff := cmp.Diff(Commands{"lint", "main.vcl"}, c.Commands); diff != "" {
+		t.Errorf("Unmatch parsed commands, diff=%s", diff)
+	}
+}
+
+func TestImportModulesFromCLI(t *testing.T) {
+	c, err := New([]string{"--import_module", "boltsort", "lint", "main.vcl"})
+	if err != nil {
+		t.Fatalf("Failed to initialize config: %s", err)
+	}
+	if diff := cmp.Diff([]string{"boltsort"}, c.ImportModules); diff != "" {
+		t.Errorf("Unmatch import modules, diff=%s", diff)
+	}
+	if diff := cmp.Diff(Commands{"lint", "main.vcl"}, c.Commands); diff != "" {
+		t.Errorf("Unmatch parsed commands, diff=%s", diff)
+	}
+}
+
+func TestParserLimitsFromCLI(t *testing.T) {
+	c, err := New([]string{"--max_input_bytes", "1024", "--max_tokens", "100", "lint", "main.vcl"})
+	if err != nil {
+		t.Fatalf("Failed to initialize config: %s", err)
+	}
+	if c.MaxInputBytes != 1024 {
+		t.Errorf("Expected max_input_bytes 1024, got %d", c.MaxInputBytes)
+	}
+	if c.MaxTokens != 100 {
+		t.Errorf("Expected max_tokens 100, got %d", c.MaxTokens)
+	}
+	if diff := cmp.Diff(Commands{"lint", "main.vcl"}, c.Commands); diff != "" {
+		t.Errorf("Unmatch parsed commands, diff=%s", diff)
+	}
+}
+
+func TestSimulatorTLSFromCLI(t *testing.T) {
+	c, err := New([]string{"--tls", "--cert", "cert.pem", "--key", "key.pem", "simulate"})
+	if err != nil {
+		t.Fatalf("Failed to initialize config: %s", err)
+	}
+	if !c.Simulator.TLS {
+		t.Errorf("Expected tls to be enabled")
+	}
+	if c.Simulator.CertFile != "cert.pem" || c.Simulator.KeyFile != "key.pem" {
+		t.Errorf("Unmatch certificate files, cert=%s, key=%s", c.Simulator.CertFile, c.Simulator.KeyFile)
+	}
+}
+
+func TestOutputFormatFromCLI(t *testing.T) {
+	tests := []struct {
+		args   []string
+		format string
+		isErr  bool
+	}{
+		{args: []string{"lint"}, format: ""},
+		{args: []string{"-json", "lint"}, format: FormatJson},
+		{args: []string{"-format", "junit", "lint"}, format: FormatJunit},
+		{args: []string{"--format", "checkstyle", "lint"}, format: FormatCheckstyle},
+		{args: []string{"-format", "html", "lint"}, isErr: true},
+	}
+
+	for _, tt := range tests {
+		c, err := New(tt.args)
+		if tt.isErr {
+			if err == nil {
+				t.Errorf("Expected error for args %v", tt.args)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Failed to initialize config: %s", err)
+			continue
+		}
+		if c.Format != tt.format {
+			t.Errorf("Unmatch Format field, expect=%s, got=%s", tt.format, c.Format)
+		}
+		if c.MachineReadable() != (tt.format != "") {
+			t.Errorf("MachineReadable should be true for format %s", tt.format)
+		}
+		if diff := cmp.Diff(c.Commands, Commands{"lint"}); diff != "" {
+			t.Errorf("Unmatch parsed commands, diff=%s", diff)
+		}
+	}
+}
+
 func TestConfigFromEnv(t *testing.T) {
 	os.Setenv("FASTLY_SERVICE_ID", "example_service_id")
 	os.Setenv("FASTLY_API_KEY", "example_api_key")
@@ -86,3 +214,69 @@ func TestConfigFromEnv(t *testing.T) {
 		t.Errorf("Unmatch FastlyApiKey field, expect=%s, got=%s", "example_api_key", c.FastlyApiKey)
 	}
 }
+
+func TestRuleConfigFromYaml(t *testing.T) {
+	input := `
+acl/syntax: error
+table/item-limitation:
+  severity: off
+  options:
+    max_items: 5000
+`
+	rules := map[string]*RuleConfig{}
+	if err := yaml.Unmarshal([]byte(input), &rules); err != nil {
+		t.Errorf("Failed to unmarshal rules: %s", err)
+		return
+	}
+
+	expect := map[string]*RuleConfig{
+		"acl/syntax": {Severity: "error"},
+		"table/item-limitation": {
+			Severity: "off",
+			Options:  map[string]interface{}{"max_items": 5000},
+		},
+	}
+	if diff := cmp.Diff(rules, expect); diff != "" {
+		t.Errorf("Unmatch rule configs, diff=%s", diff)
+	}
+}
+
+func TestBackendMocksFromYaml(t *testing.T) {
+	input := `
+- backend: F_*
+  request:
+    method: POST
+    path: /api/*
+    headers:
+      Authorization: "Bearer *"
+  response:
+    status: 201
+    body: '{"ok":true}'
+    latency: 10ms
+- response:
+    body_file: ./fixtures/index.html
+`
+	mocks := []*BackendMock{}
+	if err := yaml.Unmarshal([]byte(input), &mocks); err != nil {
+		t.Errorf("Failed to unmarshal backend mocks: %s", err)
+		return
+	}
+
+	expect := []*BackendMock{
+		{
+			Backend: "F_*",
+			Request: &BackendMockRequest{
+				Method:  "POST",
+				Path:    "/api/*",
+				Headers: map[string]string{"Authorization": "Bearer *"},
+			},
+			Response: &BackendMockResponse{Status: 201, Body: `{"ok":true}`, Latency: "10ms"},
+		},
+		{
+			Response: &BackendMockResponse{BodyFile: "./fixtures/index.html"},
+		},
+	}
+	if diff := cmp.Diff(mocks, expect); diff != "" {
+		t.Errorf("Unmatch backend mocks, diff=%s", diff)
+	}
+}
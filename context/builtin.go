@@ -2537,11 +2537,13 @@ func predefinedVariables() Variables {
 						"%any%": &Object{
 							Items: map[string]*Object{},
 							Value: &Accessor{
-								Get:       types.StringType,
-								Set:       types.StringType,
-								Unset:     true,
-								Scopes:    HIT | ERROR,
-								Reference: "https://developer.fastly.com/reference/vcl/variables/cache-object/obj-http/",
+								Get:         types.StringType,
+								Set:         types.StringType,
+								Unset:       true,
+								Scopes:      HIT | ERROR,
+								SetScopes:   ERROR,
+								UnsetScopes: ERROR,
+								Reference:   "https://developer.fastly.com/reference/vcl/variables/cache-object/obj-http/",
 							},
 						},
 					},
@@ -2583,6 +2585,7 @@ func predefinedVariables() Variables {
 						Set:       types.StringType,
 						Unset:     false,
 						Scopes:    HIT | ERROR,
+						SetScopes: ERROR,
 						Reference: "https://developer.fastly.com/reference/vcl/variables/cache-object/obj-response/",
 					},
 				},
@@ -2613,6 +2616,7 @@ func predefinedVariables() Variables {
 						Set:       types.IntegerType,
 						Unset:     false,
 						Scopes:    HIT | ERROR,
+						SetScopes: ERROR,
 						Reference: "https://developer.fastly.com/reference/vcl/variables/cache-object/obj-status/",
 					},
 				},
@@ -2974,6 +2978,7 @@ func predefinedVariables() Variables {
 						Set:       types.ReqBackendType,
 						Unset:     false,
 						Scopes:    RECV | HASH | HIT | MISS | PASS | FETCH | ERROR | DELIVER | LOG,
+						SetScopes: RECV | HASH | HIT | MISS | PASS | FETCH | ERROR,
 						Reference: "https://developer.fastly.com/reference/vcl/variables/backend-connection/req-backend/",
 					},
 				},
@@ -3474,11 +3479,13 @@ func predefinedVariables() Variables {
 						"%any%": &Object{
 							Items: map[string]*Object{},
 							Value: &Accessor{
-								Get:       types.StringType,
-								Set:       types.StringType,
-								Unset:     true,
-								Scopes:    DELIVER | LOG,
-								Reference: "https://developer.fastly.com/reference/vcl/variables/client-response/resp-http/",
+								Get:         types.StringType,
+								Set:         types.StringType,
+								Unset:       true,
+								Scopes:      DELIVER | LOG,
+								SetScopes:   DELIVER,
+								UnsetScopes: DELIVER,
+								Reference:   "https://developer.fastly.com/reference/vcl/variables/client-response/resp-http/",
 							},
 						},
 					},
@@ -3510,6 +3517,7 @@ func predefinedVariables() Variables {
 						Set:       types.StringType,
 						Unset:     false,
 						Scopes:    DELIVER | LOG,
+						SetScopes: DELIVER,
 						Reference: "https://developer.fastly.com/reference/vcl/variables/client-response/resp-response/",
 					},
 				},
@@ -3551,6 +3559,7 @@ func predefinedVariables() Variables {
 						Set:       types.IntegerType,
 						Unset:     false,
 						Scopes:    DELIVER | LOG,
+						SetScopes: DELIVER,
 						Reference: "https://developer.fastly.com/reference/vcl/variables/client-response/resp-status/",
 					},
 				},
@@ -5,6 +5,7 @@ import (
 	"strings"
 
 	"github.com/ysugimoto/falco/ast"
+	"github.com/ysugimoto/falco/parser"
 	"github.com/ysugimoto/falco/resolver"
 	"github.com/ysugimoto/falco/snippets"
 	"github.com/ysugimoto/falco/types"
@@ -49,15 +50,14 @@ func ScopeString(s int) string {
 }
 
 func ScopesString(s int) string {
-	var sb strings.Builder
-	for i := RECV; i != LOG; i <<= 4 {
+	var scopes []string
+	for i := RECV; i <= LOG; i <<= 4 {
 		scope := ScopeString(s & i)
 		if scope != "UNKNOWN" {
-			sb.WriteString(scope)
-			sb.WriteString(" ")
+			scopes = append(scopes, scope)
 		}
 	}
-	return sb.String()
+	return strings.Join(scopes, " ")
 }
 
 func CanAccessVariableInScope(objScope int, objReference, name string, currentScope int) error {
@@ -76,6 +76,23 @@ func CanAccessVariableInScope(objScope int, objReference, name string, currentSc
 	return nil
 }
 
+// canModifyVariableInScope checks the variable could be set or unset in all of current scopes.
+// Zero scopes means the variable could be modified wherever it is accessible.
+func canModifyVariableInScope(v *Accessor, name, operation string, modifyScopes, currentScope int) error {
+	if modifyScopes == 0 || (modifyScopes&currentScope) == currentScope {
+		return nil
+	}
+	missingScopes := (modifyScopes & currentScope) ^ currentScope
+	message := fmt.Sprintf(
+		`Variable "%s" is read-only in scope of %s, could be %s in %s`,
+		name, ScopesString(missingScopes), operation, ScopesString(modifyScopes),
+	)
+	if v.Reference != "" {
+		message += "\nSee reference documentation: " + v.Reference
+	}
+	return fmt.Errorf(message)
+}
+
 var fastlyReservedSubroutines = map[string]bool{
 	"vcl_recv":    true,
 	"vcl_hash":    true,
@@ -102,11 +119,13 @@ type Object struct {
 }
 
 type Accessor struct {
-	Get       types.Type
-	Set       types.Type
-	Unset     bool
-	Scopes    int
-	Reference string
+	Get         types.Type
+	Set         types.Type
+	Unset       bool
+	Scopes      int
+	SetScopes   int // Scopes which the variable could be set in, zero means all of Scopes
+	UnsetScopes int // Scopes which the variable could be unset in, zero means all of Scopes
+	Reference   string
 }
 
 type Context struct {
@@ -118,6 +137,8 @@ type Context struct {
 	Variables      Variables
 	resolver       resolver.Resolver
 	fastlySnippets *snippets.Snippets
+	dialect        string
+	parserOptions  []parser.OptionFunc
 
 	// public fields
 	Acls              map[string]*types.Acl
@@ -125,6 +146,7 @@ type Context struct {
 	Tables            map[string]*types.Table
 	Directors         map[string]*types.Director
 	Subroutines       map[string]*types.Subroutine
+	Functions         map[string]*types.Subroutine // functional subroutines which have return type
 	Penaltyboxes      map[string]*types.Penaltybox
 	Ratecounters      map[string]*types.Ratecounter
 	Gotos             map[string]*types.Goto
@@ -143,6 +165,7 @@ func New(opts ...Option) *Context {
 		Tables:         make(map[string]*types.Table),
 		Directors:      make(map[string]*types.Director),
 		Subroutines:    make(map[string]*types.Subroutine),
+		Functions:      make(map[string]*types.Subroutine),
 		Penaltyboxes:   make(map[string]*types.Penaltybox),
 		Ratecounters:   make(map[string]*types.Ratecounter),
 		Gotos:          make(map[string]*types.Goto),
@@ -177,6 +200,16 @@ func (c *Context) Resolver() resolver.Resolver {
 	return c.resolver
 }
 
+// Dialect returns VCL dialect, empty means Fastly
+func (c *Context) Dialect() string {
+	return c.dialect
+}
+
+// ParserOptions returns parser options to parse included modules
+func (c *Context) ParserOptions() []parser.OptionFunc {
+	return append([]parser.OptionFunc{parser.WithDialect(c.dialect)}, c.parserOptions...)
+}
+
 func (c *Context) Snippets() *snippets.Snippets {
 	if c.fastlySnippets == nil {
 		c.fastlySnippets = &snippets.Snippets{}
@@ -559,6 +592,11 @@ func (c *Context) Set(name string) (types.Type, error) {
 		return types.NullType, fmt.Errorf(message)
 	}
 
+	// Settable only in some scopes, read-only in others
+	if err := canModifyVariableInScope(obj.Value, name, "set", obj.Value.SetScopes, c.curMode); err != nil {
+		return types.NullType, err
+	}
+
 	// Mark as accessed
 	obj.IsUsed = true
 
@@ -656,6 +694,10 @@ func (c *Context) Unset(name string) error {
 		}
 		return fmt.Errorf(message)
 	}
+	// Unsettable only in some scopes
+	if err := canModifyVariableInScope(obj.Value, name, "unset", obj.Value.UnsetScopes, c.curMode); err != nil {
+		return err
+	}
 
 	// Mark as accessed
 	obj.IsUsed = true
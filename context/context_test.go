@@ -1,6 +1,7 @@
 package context
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/ysugimoto/falco/ast"
@@ -34,6 +35,29 @@ func TestContextSet(t *testing.T) {
 		}
 	})
 
+	t.Run("Error on set to read-only variable in current scope", func(t *testing.T) {
+		c := New()
+		c.Scope(DELIVER)
+		_, err := c.Set("req.backend")
+		if err == nil {
+			t.Errorf("expected error but got nil")
+		} else if !strings.Contains(err.Error(), "could be set in RECV HASH HIT MISS PASS FETCH ERROR") {
+			t.Errorf("error message should describe allowed scopes: %s", err)
+		}
+	})
+
+	t.Run("Error on set in subroutine called from read-only scope", func(t *testing.T) {
+		c := New()
+		c.Scope(DELIVER | LOG)
+		if _, err := c.Set("resp.status"); err == nil {
+			t.Errorf("expected error but got nil")
+		}
+		c.Scope(DELIVER)
+		if _, err := c.Set("resp.status"); err != nil {
+			t.Errorf("expected nil but got error: %s", err)
+		}
+	})
+
 	t.Run("Can return right variable type", func(t *testing.T) {
 		c := New()
 		c.Scope(RECV)
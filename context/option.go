@@ -1,6 +1,7 @@
 package context
 
 import (
+	"github.com/ysugimoto/falco/parser"
 	"github.com/ysugimoto/falco/resolver"
 	"github.com/ysugimoto/falco/snippets"
 )
@@ -18,3 +19,17 @@ func WithSnippets(fs *snippets.Snippets) Option {
 		c.fastlySnippets = fs
 	}
 }
+
+// WithDialect sets VCL dialect to lint, see parser.WithDialect
+func WithDialect(dialect string) Option {
+	return func(c *Context) {
+		c.dialect = dialect
+	}
+}
+
+// WithParserOptions sets additional parser options which are used to parse included modules
+func WithParserOptions(opts ...parser.OptionFunc) Option {
+	return func(c *Context) {
+		c.parserOptions = append(c.parserOptions, opts...)
+	}
+}
@@ -0,0 +1,123 @@
+// Package diagnostics renders parser and linter problems as code frames.
+// The frame shows offending source line with underline span and hint like:
+//
+//	error: Unexpected token "}"
+//	  --> main.vcl:3:1
+//	   |
+//	 2 |     set req.http.Foo = "bar"
+//	 3 | }
+//	   | ^ expects ;
+//	   |
+//	   = see: https://developer.fastly.com/reference/vcl/
+package diagnostics
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/ysugimoto/falco/token"
+)
+
+type Severity string
+
+const (
+	ERROR   Severity = "error"
+	WARNING Severity = "warning"
+	INFO    Severity = "info"
+)
+
+// Source provides source lines of the file, lexer.Lexer satisfies this interface
+type Source interface {
+	GetLine(n int) (string, bool)
+}
+
+// Diagnostic is a problem found at the token
+type Diagnostic struct {
+	Severity Severity
+	Message  string
+	Token    token.Token
+	// Hint is placed next to the underline span
+	Hint string
+	// Notes are printed below the code frame
+	Notes []string
+}
+
+// Render returns code frame string of the diagnostic.
+// If source line is not found, only the message and location are rendered.
+func Render(src Source, d *Diagnostic, opts ...OptionFunc) string {
+	return fmt.Sprintf("%s: %s\n", d.Severity, d.Message) + Frame(src, d, opts...)
+}
+
+// Frame returns code frame string without the message header.
+// This is useful when the caller prints the message in its own format.
+func Frame(src Source, d *Diagnostic, opts ...OptionFunc) string {
+	o := collect(opts)
+	var buf bytes.Buffer
+
+	problemLine := d.Token.Line
+	from := problemLine - o.ContextLines
+	if from < 1 {
+		from = 1
+	}
+	width := len(fmt.Sprint(problemLine))
+	gutter := strings.Repeat(" ", width+1)
+
+	buf.WriteString(fmt.Sprintf("%s--> %s\n", gutter, location(d.Token)))
+	if src == nil {
+		return buf.String()
+	}
+	line, ok := src.GetLine(problemLine)
+	if !ok {
+		return buf.String()
+	}
+
+	buf.WriteString(gutter + " |\n")
+	for l := from; l < problemLine; l++ {
+		if v, ok := src.GetLine(l); ok {
+			buf.WriteString(fmt.Sprintf(" %*d | %s\n", width, l, expandTabs(v, o.TabWidth)))
+		}
+	}
+	buf.WriteString(fmt.Sprintf(" %*d | %s\n", width, problemLine, expandTabs(line, o.TabWidth)))
+
+	// Underline span, tabs before the token are expanded as well as source line
+	runes := []rune(line)
+	col := d.Token.Position - 1
+	if col < 0 {
+		col = 0
+	}
+	if col > len(runes) {
+		col = len(runes)
+	}
+	span := len([]rune(d.Token.Literal)) + d.Token.Offset
+	if span < 1 {
+		span = 1
+	}
+	underline := strings.Repeat(" ", len([]rune(expandTabs(string(runes[:col]), o.TabWidth)))) +
+		strings.Repeat("^", span)
+	if d.Hint != "" {
+		underline += " " + d.Hint
+	}
+	buf.WriteString(fmt.Sprintf("%s | %s\n", gutter, underline))
+
+	if len(d.Notes) > 0 {
+		buf.WriteString(gutter + " |\n")
+		for i := range d.Notes {
+			buf.WriteString(fmt.Sprintf("%s = %s\n", gutter, d.Notes[i]))
+		}
+	}
+
+	return buf.String()
+}
+
+func location(t token.Token) string {
+	file := t.File
+	if file == "" {
+		file = "<input>"
+	}
+	return fmt.Sprintf("%s:%d:%d", file, t.Line, t.Position)
+}
+
+func expandTabs(s string, width int) string {
+	return strings.ReplaceAll(s, "\t", strings.Repeat(" ", width))
+}
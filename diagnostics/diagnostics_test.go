@@ -0,0 +1,69 @@
+package diagnostics
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/ysugimoto/falco/token"
+)
+
+type lines []string
+
+func (l lines) GetLine(n int) (string, bool) {
+	if n < 1 || n > len(l) {
+		return "", false
+	}
+	return l[n-1], true
+}
+
+func TestRender(t *testing.T) {
+	src := lines{
+		"sub vcl_recv {",
+		"\tset req.http.Foo = \"bar\"",
+		"}",
+	}
+
+	t.Run("render code frame with hint and notes", func(t *testing.T) {
+		d := &Diagnostic{
+			Severity: ERROR,
+			Message:  "Missing semicolon",
+			Token: token.Token{
+				Type:     token.STRING,
+				Literal:  "bar",
+				Offset:   2,
+				Line:     2,
+				Position: 21,
+				File:     "main.vcl",
+			},
+			Hint:  `add ";" after this`,
+			Notes: []string{"see: https://developer.fastly.com/reference/vcl/"},
+		}
+		expect := strings.Join([]string{
+			"error: Missing semicolon",
+			"  --> main.vcl:2:21",
+			"   |",
+			" 1 | sub vcl_recv {",
+			` 2 |     set req.http.Foo = "bar"`,
+			`   |                        ^^^^^ add ";" after this`,
+			"   |",
+			"   = see: https://developer.fastly.com/reference/vcl/",
+			"",
+		}, "\n")
+		if diff := cmp.Diff(expect, Render(src, d)); diff != "" {
+			t.Errorf("Render result mismatch, diff=%s", diff)
+		}
+	})
+
+	t.Run("render only location when source line is not found", func(t *testing.T) {
+		d := &Diagnostic{
+			Severity: WARNING,
+			Message:  "Unknown",
+			Token:    token.Token{Literal: "x", Line: 10, Position: 1},
+		}
+		expect := "warning: Unknown\n   --> <input>:10:1\n"
+		if diff := cmp.Diff(expect, Render(src, d)); diff != "" {
+			t.Errorf("Render result mismatch, diff=%s", diff)
+		}
+	})
+}
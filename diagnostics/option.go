@@ -0,0 +1,37 @@
+package diagnostics
+
+type OptionFunc func(o *Option)
+
+// Option controls code frame rendering
+type Option struct {
+	// Number of source lines shown before the problem line
+	ContextLines int
+	// Number of spaces which tab character is expanded to
+	TabWidth int
+}
+
+// WithContextLines changes number of source lines shown before the problem line
+func WithContextLines(n int) OptionFunc {
+	return func(o *Option) {
+		o.ContextLines = n
+	}
+}
+
+// WithTabWidth changes number of spaces for tab expansion
+func WithTabWidth(n int) OptionFunc {
+	return func(o *Option) {
+		o.TabWidth = n
+	}
+}
+
+func collect(opts []OptionFunc) *Option {
+	o := &Option{
+		ContextLines: 1,
+		TabWidth:     4,
+	}
+
+	for i := range opts {
+		opts[i](o)
+	}
+	return o
+}
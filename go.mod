@@ -24,6 +24,7 @@ require (
 	github.com/gdamore/tcell/v2 v2.6.0
 	github.com/gobwas/glob v0.2.3
 	github.com/rivo/tview v0.0.0-20230814110005-ccc2c8119703
+	github.com/tetratelabs/wazero v1.7.3
 	go.elara.ws/pcre v0.0.0-20230805032557-4ce849193f64
 )
 
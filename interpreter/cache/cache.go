@@ -2,16 +2,34 @@
 package cache
 
 import (
+	"bytes"
+	"io"
+	"net/http"
+	"strings"
 	"sync"
 	"time"
-
-	"net/http"
 )
 
 const (
 	LocalDatacenterString = "cache-localsimulator-FALCO"
 )
 
+// Freshness is the state of the cache object on lookup
+type Freshness int
+
+const (
+	// Object is not found, or it has already expired entirely
+	Miss Freshness = iota
+	// Object is within its TTL
+	Fresh
+	// Object is stale but within stale-while-revalidate period, it could be delivered while revalidating.
+	// Only the first lookup in the period returns this state, then following lookups are treated as stale
+	// so that the revalidation is performed as miss.
+	StaleWhileRevalidate
+	// Object is stale but within grace or stale-if-error period, it could be delivered by return(deliver_stale)
+	Stale
+)
+
 type CacheItem struct {
 	Response  *http.Response
 	Expires   time.Time
@@ -19,48 +37,331 @@ type CacheItem struct {
 	Hits      int
 	LastUsed  time.Duration
 
+	// Periods after the expiration which the object could be delivered as stale
+	Grace                time.Duration
+	StaleWhileRevalidate time.Duration
+	StaleIfError         time.Duration
+	// HitForPass is true when the object is created by return(pass) in vcl_fetch,
+	// following requests for the object are passed until it expires
+	HitForPass bool
+	// Request header values which are specified in Vary response header
+	Vary map[string]string
+	// Keys which are specified in Surrogate-Key response header to purge the object by key
+	SurrogateKeys []string
+	// ESI is true when the object is processed as ESI template on delivery
+	ESI bool
+
 	// private
+	mu            sync.Mutex
 	requestedTime time.Time
+	revalidating  bool
+	body          []byte
+}
+
+// NewResponse returns a copy of the cached response, it is safe to be called concurrently
+func (i *CacheItem) NewResponse() *http.Response {
+	resp := *i.Response
+	resp.Header = i.Response.Header.Clone()
+	resp.Trailer = i.Response.Trailer.Clone()
+	resp.Body = io.NopCloser(bytes.NewReader(i.body))
+	return &resp
+}
+
+// Update sets the remaining TTL and grace period of the object, e.g. when obj.ttl is changed in vcl_hit
+func (i *CacheItem) Update(now time.Time, ttl, grace time.Duration) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	i.Expires = now.Add(ttl)
+	i.Grace = grace
+	i.revalidating = false
 }
 
-func (i *CacheItem) Update(d time.Duration) {
-	i.Expires = i.EntryTime.Add(d)
+// Lifetime returns remaining TTL and grace period of the object
+func (i *CacheItem) Lifetime(now time.Time) (time.Duration, time.Duration) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	var ttl time.Duration
+	if d := i.Expires.Sub(now); d > 0 {
+		ttl = d
+	}
+	return ttl, i.Grace
+}
+
+// HitCount returns the number of hits of the object
+func (i *CacheItem) HitCount() int {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	return i.Hits
+}
+
+// Servable returns true if the stale object could be delivered now.
+// On error the object is servable within grace or stale-if-error period, otherwise within grace or stale-while-revalidate period,
+// and the period is limited by the limit.
+func (i *CacheItem) Servable(now time.Time, isError bool, limit time.Duration) bool {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	period := i.StaleWhileRevalidate
+	if isError {
+		period = i.StaleIfError
+	}
+	if i.Grace > period {
+		period = i.Grace
+	}
+	if period > limit {
+		period = limit
+	}
+	return !now.After(i.Expires.Add(period))
+}
+
+// staleUntil returns the time until which the object is kept to be delivered as stale
+func (i *CacheItem) staleUntil() time.Time {
+	stale := i.Grace
+	for _, d := range []time.Duration{i.StaleWhileRevalidate, i.StaleIfError} {
+		if d > stale {
+			stale = d
+		}
+	}
+	return i.Expires.Add(stale)
+}
+
+// markStale expires the object so that it is delivered only within grace or stale periods
+func (i *CacheItem) markStale(now time.Time) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	if i.Expires.After(now) {
+		i.Expires = now
+	}
+	i.revalidating = false
+}
+
+func (i *CacheItem) hasSurrogateKey(key string) bool {
+	for _, k := range i.SurrogateKeys {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
+
+// NewSurrogateKeys returns space separated keys in Surrogate-Key response header
+func NewSurrogateKeys(resp *http.Response) []string {
+	var keys []string
+	for _, v := range resp.Header.Values("Surrogate-Key") {
+		keys = append(keys, strings.Fields(v)...)
+	}
+	return keys
+}
+
+// matches returns true if the request has the same header values which the object varies on
+func (i *CacheItem) matches(r *http.Request) bool {
+	for name, v := range i.Vary {
+		if name == "*" || r.Header.Get(name) != v {
+			return false
+		}
+	}
+	return true
+}
+
+// NewVary returns request header values which the response varies on
+func NewVary(r *http.Request, resp *http.Response) map[string]string {
+	vary := make(map[string]string)
+	for _, v := range resp.Header.Values("Vary") {
+		for _, name := range strings.Split(v, ",") {
+			if name = strings.TrimSpace(name); name == "" {
+				continue
+			}
+			if name == "*" {
+				vary[name] = ""
+				continue
+			}
+			vary[http.CanonicalHeaderKey(name)] = r.Header.Get(name)
+		}
+	}
+	return vary
 }
 
 type Cache struct {
-	storage sync.Map
+	mu sync.Mutex
+	// Variants of the object keyed by hash
+	storage map[string][]*CacheItem
+	// Objects which are being fetched, the channel is closed when the fetch finishes
+	busy map[string]chan struct{}
 }
 
 func New() *Cache {
-	return &Cache{}
+	return &Cache{
+		storage: make(map[string][]*CacheItem),
+		busy:    make(map[string]chan struct{}),
+	}
 }
 
-func (c *Cache) Set(hash string, item *CacheItem) {
+// Busy marks the object as being fetched for request collapsing.
+// If no other request is fetching the object, the request becomes the leader and gets the release function
+// which must be called when the fetch finishes. Otherwise the channel which is closed on the release is returned,
+// then the request should wait for it and look up the object again.
+func (c *Cache) Busy(hash string) (func(), <-chan struct{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if wait, ok := c.busy[hash]; ok {
+		return nil, wait
+	}
+	ch := make(chan struct{})
+	c.busy[hash] = ch
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			c.mu.Lock()
+			defer c.mu.Unlock()
+			delete(c.busy, hash)
+			close(ch)
+		})
+	}, nil
+}
+
+// Set stores the object, the variant which has the same Vary values is replaced
+func (c *Cache) Set(hash string, item *CacheItem) error {
+	// Keep the body to create responses concurrently, the object is not stored if the body could not be read
+	if item.Response != nil && item.Response.Body != nil {
+		body, err := io.ReadAll(item.Response.Body)
+		if err != nil {
+			return err
+		}
+		item.body = body
+		item.Response.Body = nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
 	item.requestedTime = item.EntryTime
-	c.storage.Store(hash, item)
+	variants := c.storage[hash]
+	for idx, v := range variants {
+		if sameVary(v.Vary, item.Vary) {
+			variants[idx] = item
+			return nil
+		}
+	}
+	c.storage[hash] = append(variants, item)
+	return nil
 }
 
-func (c *Cache) Get(hash string) *CacheItem {
-	// Load and cast to *CacheItem
-	v, ok := c.storage.Load(hash)
-	if !ok {
-		return nil
+// Lookup finds the object variant for the request and returns it with its freshness.
+// Objects which are expired entirely are removed.
+func (c *Cache) Lookup(hash string, r *http.Request, now time.Time) (*CacheItem, Freshness) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var found *CacheItem
+	var remains []*CacheItem
+	for _, item := range c.storage[hash] {
+		item.mu.Lock()
+		staleUntil := item.staleUntil()
+		item.mu.Unlock()
+		if now.After(staleUntil) {
+			continue
+		}
+		remains = append(remains, item)
+		if found == nil && item.matches(r) {
+			found = item
+		}
 	}
-	item, ok := v.(*CacheItem)
-	if !ok {
-		return nil
+	if len(remains) == 0 {
+		delete(c.storage, hash)
+	} else {
+		c.storage[hash] = remains
 	}
-	// Check expiration
-	if time.Now().After(item.Expires) {
-		c.storage.Delete(hash)
-		return nil
+	if found == nil {
+		return nil, Miss
+	}
+
+	found.mu.Lock()
+	defer found.mu.Unlock()
+
+	var freshness Freshness
+	switch {
+	case !now.After(found.Expires):
+		freshness = Fresh
+	case !found.revalidating && !now.After(found.Expires.Add(found.StaleWhileRevalidate)):
+		found.revalidating = true
+		freshness = StaleWhileRevalidate
+	default:
+		return found, Stale
 	}
 
 	// Update cache state - increment Hit count, update last used time
-	item.Hits++
-	item.LastUsed = time.Since(item.requestedTime)
-	item.requestedTime = time.Now()
-	return item
+	found.Hits++
+	found.LastUsed = now.Sub(found.requestedTime)
+	found.requestedTime = now
+	return found, freshness
+}
+
+// Purge removes all variants of the object, and returns the number of removed variants
+func (c *Cache) Purge(hash string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	purged := len(c.storage[hash])
+	delete(c.storage, hash)
+	return purged
+}
+
+// SoftPurge marks all variants of the object as stale instead of removing them,
+// so that they could be still delivered within grace or stale periods
+func (c *Cache) SoftPurge(hash string, now time.Time) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, item := range c.storage[hash] {
+		item.markStale(now)
+	}
+	return len(c.storage[hash])
+}
+
+// PurgeKey removes or marks as stale all objects which have the surrogate key, and returns the number of purged objects
+func (c *Cache) PurgeKey(key string, soft bool, now time.Time) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var purged int
+	for hash, variants := range c.storage {
+		var remains []*CacheItem
+		for _, item := range variants {
+			if !item.hasSurrogateKey(key) {
+				remains = append(remains, item)
+				continue
+			}
+			purged++
+			if soft {
+				item.markStale(now)
+				remains = append(remains, item)
+			}
+		}
+		if len(remains) == 0 {
+			delete(c.storage, hash)
+		} else {
+			c.storage[hash] = remains
+		}
+	}
+	return purged
+}
+
+func sameVary(a, b map[string]string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for k, v := range a {
+		if w, ok := b[k]; !ok || v != w {
+			return false
+		}
+	}
+	return true
 }
 
 // Fastly follows its own cache freshness rules
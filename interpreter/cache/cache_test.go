@@ -0,0 +1,107 @@
+package cache
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestCacheLookup(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "http://localhost", nil)
+
+	t.Run("fresh object", func(t *testing.T) {
+		c := New()
+		now := time.Now()
+		c.Set("hash", &CacheItem{EntryTime: now, Expires: now.Add(time.Minute)})
+		for i := 1; i <= 2; i++ {
+			item, freshness := c.Lookup("hash", req, time.Now())
+			if freshness != Fresh {
+				t.Fatalf("Expected fresh object, got %d", freshness)
+			}
+			if item.Hits != i {
+				t.Errorf("Expected hits %d, got %d", i, item.Hits)
+			}
+		}
+		if _, freshness := c.Lookup("other", req, time.Now()); freshness != Miss {
+			t.Errorf("Expected miss, got %d", freshness)
+		}
+	})
+
+	t.Run("stale object", func(t *testing.T) {
+		c := New()
+		now := time.Now()
+		c.Set("hash", &CacheItem{
+			EntryTime:            now.Add(-2 * time.Minute),
+			Expires:              now.Add(-time.Minute),
+			StaleWhileRevalidate: 2 * time.Minute,
+			Grace:                time.Hour,
+		})
+		if _, freshness := c.Lookup("hash", req, time.Now()); freshness != StaleWhileRevalidate {
+			t.Errorf("Expected stale-while-revalidate object, got %d", freshness)
+		}
+		// Revalidation has been started by the first lookup
+		if _, freshness := c.Lookup("hash", req, time.Now()); freshness != Stale {
+			t.Errorf("Expected stale object, got %d", freshness)
+		}
+	})
+
+	t.Run("expired object is removed", func(t *testing.T) {
+		c := New()
+		now := time.Now()
+		c.Set("hash", &CacheItem{
+			EntryTime: now.Add(-2 * time.Minute),
+			Expires:   now.Add(-time.Minute),
+			Grace:     30 * time.Second,
+		})
+		if _, freshness := c.Lookup("hash", req, time.Now()); freshness != Miss {
+			t.Errorf("Expected miss, got %d", freshness)
+		}
+		if _, ok := c.storage["hash"]; ok {
+			t.Errorf("Expected expired object to be removed")
+		}
+	})
+
+	t.Run("vary", func(t *testing.T) {
+		c := New()
+		now := time.Now()
+		resp := &http.Response{Header: http.Header{"Vary": {"Accept-Encoding"}}}
+		gzip := httptest.NewRequest(http.MethodGet, "http://localhost", nil)
+		gzip.Header.Set("Accept-Encoding", "gzip")
+
+		c.Set("hash", &CacheItem{EntryTime: now, Expires: now.Add(time.Minute), Vary: NewVary(gzip, resp)})
+		if _, freshness := c.Lookup("hash", req, time.Now()); freshness != Miss {
+			t.Errorf("Expected miss for the other variant, got %d", freshness)
+		}
+		c.Set("hash", &CacheItem{EntryTime: now, Expires: now.Add(time.Minute), Vary: NewVary(req, resp)})
+		if _, freshness := c.Lookup("hash", gzip, time.Now()); freshness != Fresh {
+			t.Errorf("Expected fresh object, got %d", freshness)
+		}
+		if len(c.storage["hash"]) != 2 {
+			t.Errorf("Expected 2 variants, got %d", len(c.storage["hash"]))
+		}
+	})
+	t.Run("object is not stored when the body could not be read", func(t *testing.T) {
+		c := New()
+		now := time.Now()
+		err := c.Set("hash", &CacheItem{
+			EntryTime: now,
+			Expires:   now.Add(time.Minute),
+			Response:  &http.Response{Body: io.NopCloser(errorReader{})},
+		})
+		if err == nil {
+			t.Errorf("Expected error, got nil")
+		}
+		if _, freshness := c.Lookup("hash", req, time.Now()); freshness != Miss {
+			t.Errorf("Expected miss, got %d", freshness)
+		}
+	})
+}
+
+type errorReader struct{}
+
+func (errorReader) Read(p []byte) (int, error) {
+	return 0, errors.New("read error")
+}
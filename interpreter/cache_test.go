@@ -0,0 +1,292 @@
+package interpreter
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/ysugimoto/falco/interpreter/context"
+	"github.com/ysugimoto/falco/resolver"
+)
+
+func TestCacheLifecycle(t *testing.T) {
+	var fetched int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetched++
+		w.Header().Set("Cache-Control", "max-age=60, stale-while-revalidate=30")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("OK")) // nolint:errcheck
+	}))
+	defer server.Close()
+
+	parsed, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatalf("Test server URL parsing error: %s", err)
+	}
+
+	tests := []struct {
+		name    string
+		vcl     string
+		states  []string
+		fetched int
+	}{
+		{
+			name: "cached response is delivered on hit",
+			vcl: `
+sub vcl_recv {
+	#FASTLY RECV
+	return(lookup);
+}
+sub vcl_fetch {
+	#FASTLY FETCH
+	set beresp.grace = 1h;
+}`,
+			states:  []string{"MISS", "HIT", "HIT"},
+			fetched: 1,
+		},
+		{
+			name: "passed request is not cached",
+			vcl: `
+sub vcl_recv {
+	#FASTLY RECV
+	return(pass);
+}`,
+			states:  []string{"PASS", "PASS"},
+			fetched: 2,
+		},
+		{
+			name: "hit-for-pass object",
+			vcl: `
+sub vcl_recv {
+	#FASTLY RECV
+	return(lookup);
+}
+sub vcl_fetch {
+	#FASTLY FETCH
+	return(pass);
+}`,
+			states:  []string{"MISS", "HITPASS", "HITPASS"},
+			fetched: 3,
+		},
+		{
+			name: "stale object is delivered while revalidating",
+			vcl: `
+sub vcl_recv {
+	#FASTLY RECV
+	return(lookup);
+}
+sub vcl_hit {
+	#FASTLY HIT
+	set obj.ttl = 0s;
+	set obj.grace = 0s;
+}`,
+			states:  []string{"MISS", "HIT", "HIT-STALE", "MISS"},
+			fetched: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fetched = 0
+			ip := New(context.WithResolver(
+				resolver.NewStaticResolver("main", defaultBackend(parsed)+"\n"+tt.vcl),
+			))
+			for _, expect := range tt.states {
+				ip.ServeHTTP(
+					httptest.NewRecorder(),
+					httptest.NewRequest(http.MethodGet, "http://localhost", nil),
+				)
+				if ip.process.Error != nil {
+					t.Fatalf("Unexpected error: %s", ip.process.Error)
+				}
+				if ip.ctx.State != expect {
+					t.Errorf("Expected state %s, got %s", expect, ip.ctx.State)
+				}
+			}
+			if fetched != tt.fetched {
+				t.Errorf("Expected backend fetched %d times, got %d", tt.fetched, fetched)
+			}
+		})
+	}
+}
+
+func TestRequestCollapsing(t *testing.T) {
+	var fetched atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetched.Add(1)
+		// Keep the fetch in flight until all requests arrive
+		time.Sleep(200 * time.Millisecond)
+		w.Header().Set("Cache-Control", "max-age=60")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("OK")) // nolint:errcheck
+	}))
+	defer server.Close()
+
+	parsed, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatalf("Test server URL parsing error: %s", err)
+	}
+
+	tests := []struct {
+		name    string
+		recv    string
+		vcl     string
+		states  map[string]int
+		fetched int32
+	}{
+		{
+			name:    "followers receive the cached object",
+			states:  map[string]int{"MISS": 1, "HIT-WAIT": 4},
+			fetched: 1,
+		},
+		{
+			name: "followers receive hit-for-pass object",
+			vcl: `
+sub vcl_fetch {
+	#FASTLY FETCH
+	return(pass);
+}`,
+			states:  map[string]int{"MISS": 1, "HITPASS-WAIT": 4},
+			fetched: 5,
+		},
+		{
+			name: "pass in vcl_miss disables collapsing",
+			vcl: `
+sub vcl_miss {
+	#FASTLY MISS
+	return(pass);
+}`,
+			states:  map[string]int{"PASS": 5},
+			fetched: 5,
+		},
+		{
+			name:    "req.hash_ignore_busy disables collapsing",
+			recv:    `set req.hash_ignore_busy = true;`,
+			states:  map[string]int{"MISS": 5},
+			fetched: 5,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fetched.Store(0)
+			ip := New(context.WithResolver(resolver.NewStaticResolver("main", defaultBackend(parsed)+`
+sub vcl_recv {
+	#FASTLY RECV
+	`+tt.recv+`
+	return(lookup);
+}
+`+tt.vcl)))
+
+			var mu sync.Mutex
+			var wg sync.WaitGroup
+			states := map[string]int{}
+			for n := 0; n < 5; n++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					f := ip.fork()
+					if err := f.ProcessInit(httptest.NewRequest(http.MethodGet, "http://localhost", nil)); err != nil {
+						t.Errorf("Unexpected init error: %s", err)
+						return
+					}
+					if err := f.ProcessRecv(); err != nil {
+						t.Errorf("Unexpected error: %s", err)
+						return
+					}
+					mu.Lock()
+					states[f.ctx.State]++
+					mu.Unlock()
+				}()
+			}
+			wg.Wait()
+
+			if diff := cmp.Diff(tt.states, states); diff != "" {
+				t.Errorf("States mismatch, diff=%s", diff)
+			}
+			if n := fetched.Load(); n != tt.fetched {
+				t.Errorf("Expected backend fetched %d times, got %d", tt.fetched, n)
+			}
+		})
+	}
+}
+
+func TestStaleIfError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "max-age=60, stale-if-error=60")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("OK")) // nolint:errcheck
+	}))
+	defer server.Close()
+
+	parsed, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatalf("Test server URL parsing error: %s", err)
+	}
+
+	ip := New(context.WithResolver(resolver.NewStaticResolver("main", defaultBackend(parsed)+`
+sub vcl_recv {
+	#FASTLY RECV
+	if (req.http.No-Stale) {
+		set req.max_stale_if_error = 0s;
+	}
+	return(lookup);
+}
+sub vcl_error {
+	#FASTLY ERROR
+	set obj.http.X-Error = fastly.error;
+	if (stale.exists) {
+		return(deliver_stale);
+	}
+	return(deliver);
+}
+sub vcl_deliver {
+	#FASTLY DELIVER
+	set resp.http.X-Stale = if(resp.stale, "1", "0");
+	set resp.http.X-Stale-Is-Error = if(resp.stale.is_error, "1", "0");
+	return(deliver);
+}`)))
+	get := func(header http.Header) *http.Response {
+		req := httptest.NewRequest(http.MethodGet, "http://localhost/", nil)
+		for k, v := range header {
+			req.Header[k] = v
+		}
+		ip.ServeHTTP(httptest.NewRecorder(), req)
+		if ip.process.Error != nil {
+			t.Fatalf("Unexpected error: %s", ip.process.Error)
+		}
+		return ip.ctx.Response
+	}
+
+	get(nil)
+	// Soft purge makes the object stale, then the backend goes down
+	purge := httptest.NewRequest("PURGE", "http://localhost/", nil)
+	purge.Header.Set("Fastly-Soft-Purge", "1")
+	ip.ServeHTTP(httptest.NewRecorder(), purge)
+	server.Close()
+
+	resp := get(nil)
+	if resp.StatusCode != http.StatusOK || ip.ctx.State != "HIT-STALE" {
+		t.Errorf("Expected stale object to be delivered, got status %d, state %s", resp.StatusCode, ip.ctx.State)
+	}
+	if v := resp.Header.Get("X-Stale") + resp.Header.Get("X-Stale-Is-Error"); v != "11" {
+		t.Errorf("Expected resp.stale and resp.stale.is_error to be true, got %s", v)
+	}
+
+	resp = get(http.Header{"No-Stale": {"1"}})
+	if resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("Expected status 503 when stale object is over req.max_stale_if_error, got %d", resp.StatusCode)
+	}
+	if v := resp.Header.Get("X-Error"); v != "ERR_CONNECT" {
+		t.Errorf("Expected fastly.error ERR_CONNECT, got %s", v)
+	}
+	if v := resp.Header.Get("X-Stale"); v != "0" {
+		t.Errorf("Expected resp.stale to be false, got %s", v)
+	}
+}
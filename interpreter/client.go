@@ -0,0 +1,74 @@
+package interpreter
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/ysugimoto/falco/config"
+)
+
+// Request headers which spoof the client identity per request.
+// They are removed from the request before processing VCL.
+const (
+	HeaderClientIP       = "Falco-Client-IP"
+	HeaderClientASNumber = "Falco-Client-AS-Number"
+	HeaderClientASName   = "Falco-Client-AS-Name"
+	HeaderClientIdentity = "Falco-Client-Identity"
+	HeaderClientJA3MD5   = "Falco-Client-JA3-MD5"
+	HeaderClientBotName  = "Falco-Client-Bot-Name"
+
+	HeaderClientProtocol      = "Falco-Client-Protocol"
+	HeaderClientH2Fingerprint = "Falco-Client-H2-Fingerprint"
+)
+
+// clientConfig returns the client configuration which is overridden by the request headers.
+// The request remote address is also replaced when the client IP is spoofed.
+func clientConfig(base *config.ClientConfig, r *http.Request) *config.ClientConfig {
+	cc := &config.ClientConfig{}
+	if base != nil {
+		*cc = *base
+	}
+	for name, field := range map[string]*string{
+		HeaderClientIP:       &cc.IP,
+		HeaderClientASName:   &cc.ASName,
+		HeaderClientIdentity: &cc.Identity,
+		HeaderClientJA3MD5:   &cc.JA3MD5,
+		HeaderClientBotName:  &cc.BotName,
+
+		HeaderClientProtocol:      &cc.Protocol,
+		HeaderClientH2Fingerprint: &cc.H2Fingerprint,
+	} {
+		if v := r.Header.Get(name); v != "" {
+			*field = v
+		}
+		r.Header.Del(name)
+	}
+	if v, err := strconv.ParseInt(r.Header.Get(HeaderClientASNumber), 10, 64); err == nil {
+		cc.ASNumber = v
+	}
+	r.Header.Del(HeaderClientASNumber)
+
+	if ip := net.ParseIP(cc.IP); ip != nil {
+		port := "0"
+		if _, p, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+			port = p
+		}
+		r.RemoteAddr = net.JoinHostPort(ip.String(), port)
+	}
+
+	// Emulate the protocol of the request, e.g. HTTP/3 which the simulator could not serve
+	if cc.Protocol != "" {
+		protocol := cc.Protocol
+		if !strings.Contains(protocol, ".") {
+			protocol += ".0"
+		}
+		if major, minor, ok := http.ParseHTTPVersion(strings.ToUpper(protocol)); ok {
+			r.Proto = fmt.Sprintf("HTTP/%d.%d", major, minor)
+			r.ProtoMajor, r.ProtoMinor = major, minor
+		}
+	}
+	return cc
+}
@@ -0,0 +1,251 @@
+package interpreter
+
+import (
+	"testing"
+
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/ysugimoto/falco/config"
+	"github.com/ysugimoto/falco/interpreter/context"
+	"github.com/ysugimoto/falco/interpreter/geo"
+	"github.com/ysugimoto/falco/resolver"
+)
+
+func TestClientGeo(t *testing.T) {
+	vcl := `
+sub vcl_recv {
+	#FASTLY RECV
+	if (req.http.Override) {
+		set client.geo.ip_override = req.http.Override;
+	}
+	error 600;
+}
+sub vcl_error {
+	#FASTLY ERROR
+	set obj.http.Country = client.geo.country_code;
+	set obj.http.City = client.geo.city;
+	set obj.http.Offset = client.geo.utc_offset;
+	return(deliver);
+}`
+	db, err := geo.NewOverrides(map[string]*geo.Location{
+		"192.0.2.0/24":    {CountryCode: "JP", City: "Tokyo", UtcOffset: 900},
+		"198.51.100.1/32": {CountryCode: "GB"},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	tests := []struct {
+		name     string
+		db       geo.Database
+		override string
+		expect   map[string]string
+	}{
+		{
+			name:   "no geo database",
+			expect: map[string]string{"Country": "unknown", "City": "unknown", "Offset": "0"},
+		},
+		{
+			name:   "lookup client ip",
+			db:     db,
+			expect: map[string]string{"Country": "JP", "City": "Tokyo", "Offset": "900"},
+		},
+		{
+			name:     "lookup overridden ip",
+			db:       db,
+			override: "198.51.100.1",
+			expect:   map[string]string{"Country": "GB", "City": "unknown", "Offset": "0"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ip := New(
+				context.WithResolver(resolver.NewStaticResolver("main", vcl)),
+				context.WithGeo(tt.db),
+			)
+			req := httptest.NewRequest(http.MethodGet, "http://localhost", nil)
+			if tt.override != "" {
+				req.Header.Set("Override", tt.override)
+			}
+			ip.ServeHTTP(httptest.NewRecorder(), req)
+			if ip.process.Error != nil {
+				t.Fatalf("Unexpected error: %s", ip.process.Error)
+			}
+			for name, v := range tt.expect {
+				if got := ip.ctx.Response.Header.Get(name); got != v {
+					t.Errorf("Expected %s to be %s, got %s", name, v, got)
+				}
+			}
+		})
+	}
+}
+
+func TestClientConfig(t *testing.T) {
+	vcl := `
+sub vcl_recv {
+	#FASTLY RECV
+	set req.http.JA3 = tls.client.ja3_md5;
+	error 600;
+}
+sub vcl_error {
+	#FASTLY ERROR
+	set obj.http.IP = client.ip;
+	set obj.http.Identity = client.identity;
+	set obj.http.AS-Number = client.as.number;
+	set obj.http.AS-Name = client.as.name;
+	set obj.http.JA3 = req.http.JA3;
+	set obj.http.Bot = if(client.class.bot, client.bot.name, "none");
+	set obj.http.Header = if(req.http.Falco-Client-IP, "1", "0");
+	return(deliver);
+}`
+	tests := []struct {
+		name   string
+		client *config.ClientConfig
+		header http.Header
+		expect map[string]string
+	}{
+		{
+			name: "default values",
+			expect: map[string]string{
+				"IP": "192.0.2.1", "Identity": "192.0.2.1", "AS-Number": "4294967294", "AS-Name": "Reserved",
+				"JA3": "582a3b42ab84f78a5b376b1e29d6d367", "Bot": "none",
+			},
+		},
+		{
+			name: "configured values",
+			client: &config.ClientConfig{
+				IP: "198.51.100.1", ASNumber: 15169, ASName: "Google", JA3MD5: "e7d705a3286e19ea42f587b344ee6865", BotName: "Googlebot",
+			},
+			expect: map[string]string{
+				"IP": "198.51.100.1", "Identity": "198.51.100.1", "AS-Number": "15169", "AS-Name": "Google",
+				"JA3": "e7d705a3286e19ea42f587b344ee6865", "Bot": "Googlebot",
+			},
+		},
+		{
+			name:   "request headers override configured values",
+			client: &config.ClientConfig{IP: "198.51.100.1", ASNumber: 15169},
+			header: http.Header{
+				HeaderClientIP:       {"2001:db8::1"},
+				HeaderClientASNumber: {"13335"},
+				HeaderClientIdentity: {"user-1"},
+			},
+			expect: map[string]string{
+				"IP": "2001:db8::1", "Identity": "user-1", "AS-Number": "13335", "AS-Name": "Reserved", "Bot": "none",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ip := New(
+				context.WithResolver(resolver.NewStaticResolver("main", vcl)),
+				context.WithClient(tt.client),
+			)
+			req := httptest.NewRequest(http.MethodGet, "http://localhost", nil)
+			for k, v := range tt.header {
+				req.Header.Set(k, v[0])
+			}
+			ip.ServeHTTP(httptest.NewRecorder(), req)
+			if ip.process.Error != nil {
+				t.Fatalf("Unexpected error: %s", ip.process.Error)
+			}
+			for name, v := range tt.expect {
+				if got := ip.ctx.Response.Header.Get(name); got != v {
+					t.Errorf("Expected %s to be %s, got %s", name, v, got)
+				}
+			}
+			if got := ip.ctx.Response.Header.Get("Header"); got != "0" {
+				t.Errorf("Expected spoofing headers to be removed from the request")
+			}
+		})
+	}
+}
+
+func TestProtocol(t *testing.T) {
+	vcl := `
+sub vcl_recv {
+	#FASTLY RECV
+	h3.alt_svc();
+	set req.http.Proto = req.proto;
+	set req.http.H2 = if(fastly_info.is_h2, "1", "0");
+	set req.http.H3 = if(fastly_info.is_h3, "1", "0");
+	set req.http.Fingerprint = fastly_info.h2.fingerprint;
+	set req.http.Transport = transport.type;
+	error 600;
+}
+sub vcl_error {
+	#FASTLY ERROR
+	set obj.http.Proto = req.http.Proto;
+	set obj.http.H2 = req.http.H2;
+	set obj.http.H3 = req.http.H3;
+	set obj.http.Fingerprint = req.http.Fingerprint;
+	set obj.http.Transport = req.http.Transport;
+	return(deliver);
+}`
+	altSvc := `h3=":443";ma=86400,h3-29=":443";ma=86400,h3-27=":443";ma=86400`
+	tests := []struct {
+		name   string
+		url    string
+		client *config.ClientConfig
+		header http.Header
+		expect map[string]string
+	}{
+		{
+			name: "HTTP/1.1 request",
+			url:  "http://localhost/",
+			expect: map[string]string{
+				"Proto": "HTTP/1.1", "H2": "0", "H3": "0", "Fingerprint": "", "Transport": "tcp", "Alt-Svc": "",
+			},
+		},
+		{
+			name:   "HTTP/2 request emulated by configuration",
+			url:    "https://localhost/",
+			client: &config.ClientConfig{Protocol: "HTTP/2"},
+			expect: map[string]string{
+				"Proto": "HTTP/2.0", "H2": "1", "H3": "0", "Fingerprint": "1:65536;2:0;4:6291456;6:262144|15663105|0|m,a,s,p",
+				"Transport": "tcp", "Alt-Svc": altSvc,
+			},
+		},
+		{
+			name:   "HTTP/2 fingerprint overridden by request header",
+			url:    "https://localhost/",
+			client: &config.ClientConfig{Protocol: "HTTP/2"},
+			header: http.Header{HeaderClientH2Fingerprint: {"1:65536;4:131072;5:16384|12517377|3:0:0:201|m,p,a,s"}},
+			expect: map[string]string{
+				"H2": "1", "Fingerprint": "1:65536;4:131072;5:16384|12517377|3:0:0:201|m,p,a,s",
+			},
+		},
+		{
+			name:   "HTTP/3 request emulated by request header",
+			url:    "https://localhost/",
+			header: http.Header{HeaderClientProtocol: {"HTTP/3"}},
+			expect: map[string]string{
+				"Proto": "HTTP/3.0", "H2": "0", "H3": "1", "Fingerprint": "", "Transport": "quic", "Alt-Svc": "",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ip := New(
+				context.WithResolver(resolver.NewStaticResolver("main", vcl)),
+				context.WithClient(tt.client),
+			)
+			req := httptest.NewRequest(http.MethodGet, tt.url, nil)
+			for k, v := range tt.header {
+				req.Header.Set(k, v[0])
+			}
+			ip.ServeHTTP(httptest.NewRecorder(), req)
+			if ip.process.Error != nil {
+				t.Fatalf("Unexpected error: %s", ip.process.Error)
+			}
+			for name, v := range tt.expect {
+				if got := ip.ctx.Response.Header.Get(name); got != v {
+					t.Errorf("Expected %s to be %q, got %q", name, v, got)
+				}
+			}
+		})
+	}
+}
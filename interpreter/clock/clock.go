@@ -0,0 +1,77 @@
+// Package clock provides the current time to the interpreter.
+// The clock could be replaced to control the time deterministically, e.g. for cache expiration tests.
+package clock
+
+import (
+	"sync"
+	"time"
+)
+
+type Clock interface {
+	Now() time.Time
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+// System is the clock which returns the actual system time
+var System Clock = systemClock{}
+
+// Manual is the clock which could be frozen and advanced manually.
+// Until frozen, the clock goes on with the system time shifted by the advanced duration.
+type Manual struct {
+	mu     sync.Mutex
+	frozen bool
+	now    time.Time
+	offset time.Duration
+}
+
+func NewManual() *Manual {
+	return &Manual{}
+}
+
+func (m *Manual) Now() time.Time {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.frozen {
+		return m.now
+	}
+	return time.Now().Add(m.offset)
+}
+
+// Freeze stops the clock at the time
+func (m *Manual) Freeze(t time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.frozen = true
+	m.now = t
+}
+
+// Unfreeze restarts the clock from the frozen time
+func (m *Manual) Unfreeze() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if !m.frozen {
+		return
+	}
+	m.frozen = false
+	m.offset = time.Until(m.now)
+}
+
+// Advance moves the clock forward by the duration
+func (m *Manual) Advance(d time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.frozen {
+		m.now = m.now.Add(d)
+		return
+	}
+	m.offset += d
+}
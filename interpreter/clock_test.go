@@ -0,0 +1,73 @@
+package interpreter
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+
+	"github.com/ysugimoto/falco/interpreter/clock"
+	"github.com/ysugimoto/falco/interpreter/context"
+	"github.com/ysugimoto/falco/resolver"
+)
+
+func TestClock(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "max-age=60")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("OK")) // nolint:errcheck
+	}))
+	defer server.Close()
+
+	parsed, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatalf("Test server URL parsing error: %s", err)
+	}
+
+	clk := clock.NewManual()
+	frozen := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	clk.Freeze(frozen)
+	ip := New(
+		context.WithResolver(resolver.NewStaticResolver("main", defaultBackend(parsed)+`
+sub vcl_recv {
+	#FASTLY RECV
+	return(lookup);
+}
+sub vcl_deliver {
+	#FASTLY DELIVER
+	set resp.http.Now = now.sec;
+	return(deliver);
+}`)),
+		context.WithClock(clk),
+	)
+	get := func() *http.Response {
+		ip.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "http://localhost/", nil))
+		if ip.process.Error != nil {
+			t.Fatalf("Unexpected error: %s", ip.process.Error)
+		}
+		return ip.ctx.Response
+	}
+
+	resp := get()
+	if v := resp.Header.Get("Now"); v != fmt.Sprint(frozen.Unix()) {
+		t.Errorf("Expected now.sec to be frozen time, got %s", v)
+	}
+
+	clk.Advance(30 * time.Second)
+	resp = get()
+	if ip.ctx.State != "HIT" {
+		t.Errorf("Expected object to be fresh before max-age, got state %s", ip.ctx.State)
+	}
+	if v := resp.Header.Get("Age"); v != "30" {
+		t.Errorf("Expected Age to be 30, got %s", v)
+	}
+
+	clk.Advance(time.Minute)
+	get()
+	if ip.ctx.State != "MISS" {
+		t.Errorf("Expected object to be expired after max-age, got state %s", ip.ctx.State)
+	}
+}
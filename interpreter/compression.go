@@ -0,0 +1,177 @@
+package interpreter
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	encodingGzip   = "gzip"
+	encodingBrotli = "br"
+)
+
+// compressBackendResponse compresses the backend response when beresp.gzip or beresp.brotli is enabled in vcl_fetch.
+// Fastly compresses the object before storing it to the cache, and does not compress the response which is already encoded.
+// see: https://developer.fastly.com/reference/vcl/variables/backend-response/beresp-gzip/
+func (i *Interpreter) compressBackendResponse() error {
+	resp := i.ctx.BackendResponse
+	if resp == nil || (!i.ctx.BackendResponseGzip.Value && !i.ctx.BackendResponseBrotli.Value) {
+		return nil
+	}
+	if resp.Header.Get("Content-Encoding") != "" {
+		return nil
+	}
+	// Go standard library does not have brotli encoder, so the object is compressed with gzip instead
+	if i.ctx.BackendResponseBrotli.Value {
+		i.Debugger.Message("Brotli compression is not supported, compress with gzip instead")
+	}
+
+	body, err := readResponseBody(resp)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	compressed, err := gzipEncode(body)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	setResponseBody(resp, compressed)
+	resp.Header.Set("Content-Length", strconv.Itoa(len(compressed)))
+	resp.Header.Set("Content-Encoding", encodingGzip)
+	addVaryHeader(resp.Header, "Accept-Encoding")
+
+	i.Debugger.Message(fmt.Sprintf("Compress backend response with gzip, %d -> %d bytes", len(body), len(compressed)))
+	return nil
+}
+
+// decompressResponse decodes the gzip encoded response body so that ESI could be processed.
+// Returns false if the response is encoded by the unsupported encoding.
+func decompressResponse(resp *http.Response) (bool, error) {
+	switch strings.ToLower(resp.Header.Get("Content-Encoding")) {
+	case "":
+		return true, nil
+	case encodingGzip:
+		body, err := readResponseBody(resp)
+		if err != nil {
+			return false, errors.WithStack(err)
+		}
+		decoded, err := gzipDecode(body)
+		if err != nil {
+			return false, errors.WithStack(err)
+		}
+		setResponseBody(resp, decoded)
+		resp.Header.Del("Content-Encoding")
+		resp.Header.Del("Content-Length")
+		return true, nil
+	default:
+		return false, nil
+	}
+}
+
+// negotiateEncoding encodes the client response to the encoding of the object following Accept-Encoding request header.
+// The gzip object is decompressed on the fly for the client which does not accept gzip,
+// and the object which is decompressed for ESI is compressed again for the client which accepts gzip.
+func (i *Interpreter) negotiateEncoding(encoding string) error {
+	resp := i.ctx.Response
+	if encoding != encodingGzip {
+		return nil
+	}
+
+	accepted := acceptsEncoding(i.ctx.Request.Header.Get("Accept-Encoding"), encodingGzip)
+	current := strings.ToLower(resp.Header.Get("Content-Encoding"))
+	switch {
+	case accepted && current == "":
+		body, err := readResponseBody(resp)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		compressed, err := gzipEncode(body)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		setResponseBody(resp, compressed)
+		resp.Header.Set("Content-Encoding", encodingGzip)
+	case !accepted && current == encodingGzip:
+		if _, err := decompressResponse(resp); err != nil {
+			return errors.WithStack(err)
+		}
+		i.Debugger.Message("Client does not accept gzip, decompress the response")
+	default:
+		return nil
+	}
+	// The transcoded response is streamed so Content-Length is not known
+	resp.Header.Del("Content-Length")
+	return nil
+}
+
+// acceptsEncoding reports whether Accept-Encoding header value accepts the encoding, q=0 means not acceptable
+func acceptsEncoding(accept, encoding string) bool {
+	for _, v := range strings.Split(accept, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(v), ";")
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name != encoding && name != "*" {
+			continue
+		}
+		q, found := strings.CutPrefix(strings.TrimSpace(params), "q=")
+		if !found {
+			return true
+		}
+		if f, err := strconv.ParseFloat(q, 64); err == nil && f > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func addVaryHeader(h http.Header, name string) {
+	for _, v := range strings.Split(h.Get("Vary"), ",") {
+		if strings.EqualFold(strings.TrimSpace(v), name) {
+			return
+		}
+	}
+	appendHeaderValue(h, "Vary", name)
+}
+
+func readResponseBody(resp *http.Response) ([]byte, error) {
+	if resp.Body == nil {
+		return nil, nil
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	resp.Body.Close()
+	return body, nil
+}
+
+func setResponseBody(resp *http.Response, body []byte) {
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	resp.ContentLength = int64(len(body))
+}
+
+func gzipEncode(body []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	if _, err := w.Write(body); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if err := w.Close(); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return buf.Bytes(), nil
+}
+
+func gzipDecode(body []byte) ([]byte, error) {
+	r, err := gzip.NewReader(bytes.NewReader(body))
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	defer r.Close()
+	return io.ReadAll(r)
+}
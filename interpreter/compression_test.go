@@ -0,0 +1,132 @@
+package interpreter
+
+import (
+	"compress/gzip"
+	"io"
+	"strings"
+	"testing"
+
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/ysugimoto/falco/interpreter/context"
+	"github.com/ysugimoto/falco/interpreter/value"
+	"github.com/ysugimoto/falco/interpreter/variable"
+	"github.com/ysugimoto/falco/resolver"
+)
+
+func TestCompression(t *testing.T) {
+	plain := strings.Repeat("falco simulator ", 64)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "max-age=60")
+		switch r.URL.Path {
+		case "/plain":
+			w.Write([]byte(plain)) // nolint:errcheck
+		case "/esi":
+			// Origin responds gzip encoded body regardless of Accept-Encoding
+			w.Header().Set("Content-Encoding", "gzip")
+			gz := gzip.NewWriter(w)
+			gz.Write([]byte(`<esi:include src="/fragment"/>|esi`)) // nolint:errcheck
+			gz.Close()
+		case "/fragment":
+			w.Write([]byte("fragment")) // nolint:errcheck
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	parsed, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatalf("Test server URL parsing error: %s", err)
+	}
+
+	vcl := defaultBackend(parsed) + `
+sub vcl_recv {
+	#FASTLY RECV
+	return(lookup);
+}
+sub vcl_fetch {
+	#FASTLY FETCH
+	if (req.url.path == "/plain") {
+		set beresp.gzip = true;
+	}
+	if (req.url.path == "/esi") {
+		set beresp.do_esi = true;
+	}
+}`
+
+	tests := []struct {
+		name           string
+		path           string
+		acceptEncoding string
+		encoding       string
+		expect         string
+	}{
+		{name: "compressed by beresp.gzip", path: "/plain", acceptEncoding: "gzip, br", encoding: "gzip", expect: plain},
+		{name: "decompressed for the client which does not accept gzip", path: "/plain", encoding: "", expect: plain},
+		{name: "gzip is not acceptable by q=0", path: "/plain", acceptEncoding: "gzip;q=0", encoding: "", expect: plain},
+		{name: "ESI on gzip encoded origin response", path: "/esi", acceptEncoding: "gzip", encoding: "gzip", expect: "fragment|esi"},
+		{name: "ESI on gzip encoded origin response without gzip", path: "/esi", encoding: "", expect: "fragment|esi"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ip := New(context.WithResolver(resolver.NewStaticResolver("main", vcl)))
+			// Process twice to check the compressed object is also delivered on cache hit
+			for _, state := range []string{"MISS", "HIT"} {
+				req := httptest.NewRequest(http.MethodGet, "http://localhost"+tt.path, nil)
+				if tt.acceptEncoding != "" {
+					req.Header.Set("Accept-Encoding", tt.acceptEncoding)
+				}
+				if err := ip.ProcessInit(req); err != nil {
+					t.Fatalf("Unexpected init error: %s", err)
+				}
+				if err := ip.ProcessRecv(); err != nil {
+					t.Fatalf("Unexpected error: %s", err)
+				}
+				if ip.ctx.State != state {
+					t.Errorf("Expected state %s, got %s", state, ip.ctx.State)
+				}
+				if v := ip.ctx.Response.Header.Get("Content-Encoding"); v != tt.encoding {
+					t.Errorf("Content-Encoding mismatch, expect=%q, got=%q", tt.encoding, v)
+				}
+				var body io.Reader = ip.ctx.Response.Body
+				if tt.encoding == "gzip" {
+					if body, err = gzip.NewReader(body); err != nil {
+						t.Fatalf("Unexpected gzip error: %s", err)
+					}
+				}
+				decoded, err := io.ReadAll(body)
+				if err != nil {
+					t.Fatalf("Unexpected read error: %s", err)
+				}
+				if diff := cmp.Diff(tt.expect, string(decoded)); diff != "" {
+					t.Errorf("Body mismatch, diff=%s", diff)
+				}
+			}
+		})
+	}
+
+	t.Run("Vary header and body size of compressed object", func(t *testing.T) {
+		ip := New(context.WithResolver(resolver.NewStaticResolver("main", vcl)))
+		req := httptest.NewRequest(http.MethodGet, "http://localhost/plain", nil)
+		req.Header.Set("Accept-Encoding", "gzip")
+		ip.ServeHTTP(httptest.NewRecorder(), req)
+		if ip.process.Error != nil {
+			t.Fatalf("Unexpected error: %s", ip.process.Error)
+		}
+		if v := ip.ctx.Response.Header.Get("Vary"); v != "Accept-Encoding" {
+			t.Errorf("Vary header mismatch, got=%q", v)
+		}
+		written, err := variable.NewLogScopeVariables(ip.ctx).Get(context.LogScope, "resp.body_bytes_written")
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		if size := value.Unwrap[*value.Integer](written).Value; size == 0 || size >= int64(len(plain)) {
+			t.Errorf("resp.body_bytes_written should be compressed size, got=%d", size)
+		}
+	})
+}
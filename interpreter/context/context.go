@@ -1,12 +1,15 @@
 package context
 
 import (
+	"math/rand"
 	"net/http"
 	"time"
 
 	"github.com/ysugimoto/falco/ast"
 	"github.com/ysugimoto/falco/config"
 	"github.com/ysugimoto/falco/interpreter/cache"
+	"github.com/ysugimoto/falco/interpreter/clock"
+	"github.com/ysugimoto/falco/interpreter/geo"
 	"github.com/ysugimoto/falco/interpreter/value"
 	"github.com/ysugimoto/falco/resolver"
 	"github.com/ysugimoto/falco/snippets"
@@ -57,7 +60,14 @@ type Context struct {
 	OverrideMaxBackends int
 	OverrideMaxAcls     int
 	OverrideRequest     *config.RequestConfig
+	Server              *config.ServerConfig
+	Geo                 geo.Database
+	Client              *config.ClientConfig
+	Clock               clock.Clock
+	Random              *rand.Rand
 	OverrideBackends    map[string]*config.OverrideBackend
+	BackendMocks        []*config.BackendMock
+	Probe               bool
 
 	Request          *http.Request
 	BackendRequest   *http.Request
@@ -68,6 +78,12 @@ type Context struct {
 	RequestEndTime   time.Time
 	RequestStartTime time.Time
 	CacheHitItem     *cache.CacheItem
+	// Stale object which could be delivered by return(deliver_stale)
+	StaleItem *cache.CacheItem
+	// Shield POP name which processes the request, empty on the edge POP
+	ShieldPOP string
+	// Backend which the backend request is sent to, the director is resolved to one of its backends
+	OriginBackend *value.Backend
 
 	// Interpreter states, following variables could be set in each subroutine directives
 	Restarts                            int
@@ -136,7 +152,6 @@ type Context struct {
 	// For testing fields
 	// Stored subroutine return state
 	ReturnState *value.String
-	FixedTime   *time.Time
 
 	// Regex captured values like "re.group.N" and local declared variables are volatile,
 	// reset this when process is outgoing for each subroutines
@@ -151,6 +166,32 @@ type Context struct {
 	// However, Fastly document says the esi will be triggered when esi statement is executed in FETCH directive.
 	// see: https://developer.fastly.com/reference/vcl/statements/esi/
 	TriggerESI bool
+
+	// Marker that the connection is upgraded to WebSocket by return(upgrade) in RECV directive
+	Upgrade bool
+
+	// Size of the response body which is counted while writing to the client before LOG directive
+	ResponseBodyBytesWritten int64
+
+	// Segmented caching state of the outer or inner request, nil when segmented caching is not processed
+	SegmentedCaching *SegmentedCaching
+}
+
+// SegmentedCaching holds the state of segmented caching.
+// The outer request is split into inner requests which fetch and cache each block of the object.
+// see: https://developer.fastly.com/learning/concepts/segmented-caching/
+type SegmentedCaching struct {
+	IsInner        bool
+	BlockNumber    int64
+	BlockSize      int64
+	TotalBlocks    int64
+	CompleteLength int64
+	// Requested range which is rounded to the block boundaries
+	RangeLow  int64
+	RangeHigh int64
+	Completed bool
+	Failed    bool
+	Error     string
 }
 
 func New(options ...Option) *Context {
@@ -164,6 +205,7 @@ func New(options ...Option) *Context {
 		Gotos:               make(map[string]*ast.GotoStatement),
 		SubroutineFunctions: make(map[string]*ast.SubroutineDeclaration),
 		OverrideBackends:    make(map[string]*config.OverrideBackend),
+		Clock:               clock.System,
 
 		CacheHitItem:                        nil,
 		RequestStartTime:                    time.Now(),
@@ -206,8 +248,8 @@ func New(options ...Option) *Context {
 		WafSesionFixationScore:              &value.Integer{},
 		WafSeverity:                         &value.Integer{},
 		WafXSSScore:                         &value.Integer{},
-		BetweenBytesTimeout:                 &value.RTime{},
-		ConnectTimeout:                      &value.RTime{},
+		BetweenBytesTimeout:                 &value.RTime{Value: 10 * time.Second},
+		ConnectTimeout:                      &value.RTime{Value: time.Second},
 		FirstByteTimeout:                    &value.RTime{Value: 15 * time.Second},
 		BackendResponseGzip:                 &value.Boolean{},
 		BackendResponseBrotli:               &value.Boolean{},
@@ -240,3 +282,17 @@ func New(options ...Option) *Context {
 
 	return ctx
 }
+
+// Now returns the current time of the clock.
+// The system time is used when the context is not created by New, e.g. in unit tests.
+func (c *Context) Now() time.Time {
+	if c == nil || c.Clock == nil {
+		return time.Now()
+	}
+	return c.Clock.Now()
+}
+
+// Since returns the duration elapsed since t on the clock
+func (c *Context) Since(t time.Time) time.Duration {
+	return c.Now().Sub(t)
+}
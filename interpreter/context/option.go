@@ -2,6 +2,8 @@ package context
 
 import (
 	"github.com/ysugimoto/falco/config"
+	"github.com/ysugimoto/falco/interpreter/clock"
+	"github.com/ysugimoto/falco/interpreter/geo"
 	"github.com/ysugimoto/falco/resolver"
 	"github.com/ysugimoto/falco/snippets"
 )
@@ -44,8 +46,53 @@ func WithOverrideBackends(ov map[string]*config.OverrideBackend) Option {
 	}
 }
 
+func WithBackendMocks(mocks []*config.BackendMock) Option {
+	return func(c *Context) {
+		c.BackendMocks = mocks
+	}
+}
+
 func WithOverrideHost(host string) Option {
 	return func(c *Context) {
 		c.OriginalHost = host
 	}
 }
+
+func WithServer(s *config.ServerConfig) Option {
+	return func(c *Context) {
+		c.Server = s
+	}
+}
+
+func WithClient(cc *config.ClientConfig) Option {
+	return func(c *Context) {
+		c.Client = cc
+	}
+}
+
+func WithClock(clk clock.Clock) Option {
+	return func(c *Context) {
+		c.Clock = clk
+	}
+}
+
+// WithRandomSeed makes random values reproducible, the generator is shared between requests
+func WithRandomSeed(seed int64) Option {
+	r := NewRandom(seed)
+	return func(c *Context) {
+		c.Random = r
+	}
+}
+
+func WithGeo(db geo.Database) Option {
+	return func(c *Context) {
+		c.Geo = db
+	}
+}
+
+// WithProbe enables backend health probes which are sent to the backends declaring .probe
+func WithProbe() Option {
+	return func(c *Context) {
+		c.Probe = true
+	}
+}
@@ -0,0 +1,50 @@
+package context
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// lockedSource is the random source which is safe for concurrent use.
+// The seeded source is shared between requests so that the sequence is reproducible through the process.
+type lockedSource struct {
+	mu  sync.Mutex
+	src rand.Source64
+}
+
+func (s *lockedSource) Int63() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.src.Int63()
+}
+
+func (s *lockedSource) Uint64() uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.src.Uint64()
+}
+
+func (s *lockedSource) Seed(seed int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.src.Seed(seed)
+}
+
+// NewRandom returns the random number generator which is seeded by the seed
+func NewRandom(seed int64) *rand.Rand {
+	return rand.New(&lockedSource{
+		src: rand.NewSource(seed).(rand.Source64), // nolint:forcetypeassert
+	})
+}
+
+var defaultRandom = NewRandom(time.Now().UnixNano())
+
+// Rand returns the random number generator which is used for random functions and director selection.
+// The generator is not seeded unless configured.
+func (c *Context) Rand() *rand.Rand {
+	if c == nil || c.Random == nil {
+		return defaultRandom
+	}
+	return c.Random
+}
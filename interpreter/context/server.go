@@ -0,0 +1,74 @@
+package context
+
+import (
+	"strings"
+)
+
+// Default values of server variables on the edge POP
+const (
+	defaultDatacenter = "FALCO"
+	defaultHostname   = "cache-localsimulator"
+	defaultRegion     = "US"
+)
+
+// Datacenter returns the datacenter code of the POP which processes the request,
+// e.g. "IAD" for the shield POP "iad-va-us"
+func (c *Context) Datacenter() string {
+	if c.ShieldPOP != "" {
+		code, _, _ := strings.Cut(c.ShieldPOP, "-")
+		return strings.ToUpper(code)
+	}
+	if c.Server != nil && c.Server.Datacenter != "" {
+		return c.Server.Datacenter
+	}
+	return defaultDatacenter
+}
+
+// Identity returns the cache node name which processes the request
+func (c *Context) Identity() string {
+	if c.ShieldPOP != "" {
+		return defaultHostname + "-" + c.Datacenter()
+	}
+	if c.Server != nil && c.Server.Hostname != "" {
+		return c.Server.Hostname
+	}
+	return defaultHostname
+}
+
+// Pop returns the POP name, which is the same as the datacenter unless configured
+func (c *Context) Pop() string {
+	if c.ShieldPOP == "" && c.Server != nil && c.Server.Pop != "" {
+		return c.Server.Pop
+	}
+	return c.Datacenter()
+}
+
+// Region returns the region of the POP
+func (c *Context) Region() string {
+	if c.ShieldPOP == "" && c.Server != nil && c.Server.Region != "" {
+		return c.Server.Region
+	}
+	return defaultRegion
+}
+
+// Visits returns the number of Fastly nodes which the request has visited before this POP.
+// The shield POP counts the edge POP which forwarded the request.
+func (c *Context) Visits() int64 {
+	var visits int64
+	if c.Server != nil {
+		visits = int64(c.Server.Visits)
+	}
+	if c.ShieldPOP != "" {
+		visits++
+	}
+	return visits
+}
+
+// PopVisits returns the number of Fastly nodes in this POP which the request has visited before.
+// Configured visits are assumed to be in the edge POP, so the shield POP is visited first.
+func (c *Context) PopVisits() int64 {
+	if c.ShieldPOP != "" || c.Server == nil {
+		return 0
+	}
+	return int64(c.Server.Visits)
+}
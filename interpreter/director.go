@@ -5,7 +5,7 @@ import (
 	"encoding/binary"
 	"fmt"
 	"math"
-	"math/rand"
+	"net"
 	"net/http"
 	"sort"
 	"strconv"
@@ -153,6 +153,31 @@ func (i *Interpreter) setDirectorConfigProperty(conf *value.DirectorConfig, prop
 			conf.VNodesPerNode = int(v.Value)
 		}
 		return nil
+	case "shield":
+		if conf.Type != DIRECTORTYPE_SHIELD {
+			return exception.Runtime(
+				&prop.GetMeta().Token,
+				".shield field must be present only in shield director type",
+			)
+		}
+		if v, ok := prop.Value.(*ast.String); !ok {
+			return exception.Runtime(&prop.GetMeta().Token, ".shield value must be string")
+		} else {
+			conf.Shield = v.Value
+		}
+		return nil
+	case "is_ssl":
+		// Connection to the shield POP is not simulated so the value is only validated
+		if conf.Type != DIRECTORTYPE_SHIELD {
+			return exception.Runtime(
+				&prop.GetMeta().Token,
+				".is_ssl field must be present only in shield director type",
+			)
+		}
+		if _, ok := prop.Value.(*ast.Boolean); !ok {
+			return exception.Runtime(&prop.GetMeta().Token, ".is_ssl value must be boolean")
+		}
+		return nil
 	}
 	return exception.Runtime(&prop.GetMeta().Token, "Unexpected director property '%s' found", prop.Key.Value)
 }
@@ -246,6 +271,8 @@ func (i *Interpreter) createDirectorRequest(ctx *context.Context, dc *value.Dire
 		backend, err = i.directorBackendClient(dc)
 	case DIRECTORTYPE_CHASH:
 		backend, err = i.directorBackendConsistentHash(dc)
+	case DIRECTORTYPE_SHIELD:
+		return i.createShieldRequest(ctx, dc)
 	default:
 		return nil, exception.System("Unexpected director type '%s' provided", dc.Type)
 	}
@@ -257,24 +284,33 @@ func (i *Interpreter) createDirectorRequest(ctx *context.Context, dc *value.Dire
 }
 
 func (i *Interpreter) canDetermineBackend(dc *value.DirectorConfig) error {
-	// Check healthy backends and quorum wight is not reached
-	var healthyBackends int
-	for _, v := range dc.Backends {
-		if !v.Backend.Healthy.Load() {
-			continue
-		}
-		healthyBackends++
-	}
-	// There is no healthy backend or healthyBackends is less than quorum
-	if healthyBackends == 0 {
+	// Quorum is the percentage of the cumulative weight of healthy backends
+	healthyWeight, totalWeight := dc.HealthyWeight()
+
+	// There is no healthy backend or healthy weight is less than quorum
+	if healthyWeight == 0 {
 		return ErrAllBackendsFailed
 	}
-	if int((float64(healthyBackends)/float64(len(dc.Backends)))*100) < dc.Quorum {
+	if healthyWeight*100 < dc.Quorum*totalWeight {
 		return ErrQuorumWeightNotReached
 	}
 	return nil
 }
 
+// clientIdentity returns client.identity value which is used for client director and chash director
+func (i *Interpreter) clientIdentity() string {
+	if i.ctx.ClientIdentity != nil {
+		return i.ctx.ClientIdentity.Value
+	}
+	if i.ctx.Client != nil && i.ctx.Client.Identity != "" {
+		return i.ctx.Client.Identity
+	}
+	if host, _, err := net.SplitHostPort(i.ctx.Request.RemoteAddr); err == nil {
+		return host
+	}
+	return i.ctx.Request.RemoteAddr
+}
+
 // Random director
 // https://developer.fastly.com/reference/vcl/declarations/director/#random
 func (i *Interpreter) directorBackendRandom(dc *value.DirectorConfig) (*value.Backend, error) {
@@ -292,24 +328,23 @@ func (i *Interpreter) directorBackendRandom(dc *value.DirectorConfig) (*value.Ba
 			continue
 		}
 
-		lottery := make([]int, 1000)
-		var current int
-		for index, v := range dc.Backends {
-			// Skip if backend is unhealthy
-			if !v.Backend.Healthy.Load() {
+		// Choose healthy backend in proportion to its weight
+		var total int
+		for _, v := range dc.Backends {
+			if v.Backend.IsHealthy() {
+				total += v.EffectiveWeight()
+			}
+		}
+		n := i.ctx.Rand().Intn(total)
+		for _, v := range dc.Backends {
+			if !v.Backend.IsHealthy() {
 				continue
 			}
-			for i := 0; i < v.Weight; i++ {
-				lottery[current] = index
-				current++
+			if n < v.EffectiveWeight() {
+				return v.Backend, nil
 			}
+			n -= v.EffectiveWeight()
 		}
-
-		rand.New(rand.NewSource(time.Now().Unix()))
-		lottery = lottery[0:current]
-		item := dc.Backends[lottery[rand.Intn(current)]]
-
-		return item.Backend, nil
 	}
 
 	return nil, ErrQuorumWeightNotReached
@@ -319,7 +354,7 @@ func (i *Interpreter) directorBackendRandom(dc *value.DirectorConfig) (*value.Ba
 // https://developer.fastly.com/reference/vcl/declarations/director/#fallback
 func (i *Interpreter) directorBackendFallback(dc *value.DirectorConfig) (*value.Backend, error) {
 	for _, v := range dc.Backends {
-		if v.Backend.Healthy.Load() {
+		if v.Backend.IsHealthy() {
 			return v.Backend, nil
 		}
 	}
@@ -331,26 +366,59 @@ func (i *Interpreter) directorBackendFallback(dc *value.DirectorConfig) (*value.
 // https://developer.fastly.com/reference/vcl/declarations/director/#content
 func (i *Interpreter) directorBackendHash(dc *value.DirectorConfig) (*value.Backend, error) {
 	// Hash should be calauclated based on request hash, means the same as cache object key
-	hash := sha256.Sum256([]byte(i.ctx.RequestHash.Value))
-
-	return i.getBackendByHash(dc, hash[:])
+	return i.getBackendByHash(dc, i.ctx.RequestHash.Value)
 }
 
 // Client director
 // https://developer.fastly.com/reference/vcl/declarations/director/#client
 func (i *Interpreter) directorBackendClient(dc *value.DirectorConfig) (*value.Backend, error) {
-	var identity string
-	if i.ctx.ClientIdentity != nil {
-		identity = i.ctx.ClientIdentity.Value
-	} else {
-		identity = i.ctx.Request.RemoteAddr
-		if idx := strings.LastIndex(identity, ":"); idx != -1 {
-			identity = identity[:idx]
+	return i.getBackendByHash(dc, i.clientIdentity())
+}
+
+// Default number of vnodes of each backend on the ring of chash director
+const defaultVNodesPerNode = 256
+
+// chashRing is the ring of chash director which has vnodes of all backends, and the vnode refers the backend by index.
+// The positions depend only on .seed, .vnodes_per_node and .id so that the ring is shared between requests.
+type chashRing []chashVNode
+
+type chashVNode struct {
+	point uint32
+	index int
+}
+
+func newChashRing(dc *value.DirectorConfig) chashRing {
+	vnodesPerNode := dc.VNodesPerNode
+	if vnodesPerNode == 0 {
+		vnodesPerNode = defaultVNodesPerNode
+	}
+	ring := make(chashRing, 0, len(dc.Backends)*vnodesPerNode)
+	for index, v := range dc.Backends {
+		for n := 0; n < vnodesPerNode; n++ {
+			ring = append(ring, chashVNode{
+				point: chashPoint(dc.Seed, fmt.Sprintf("%s-%d", v.Id, n)),
+				index: index,
+			})
 		}
 	}
-	hash := sha256.Sum256([]byte(identity))
+	sort.SliceStable(ring, func(i, j int) bool {
+		return ring[i].point < ring[j].point
+	})
+	return ring
+}
 
-	return i.getBackendByHash(dc, hash[:])
+// getChashRing returns the cached ring of the director
+func (i *Interpreter) getChashRing(dc *value.DirectorConfig) chashRing {
+	ids := make([]string, len(dc.Backends))
+	for index, v := range dc.Backends {
+		ids[index] = v.Id
+	}
+	signature := fmt.Sprintf("%d:%d:%s", dc.Seed, dc.VNodesPerNode, strings.Join(ids, ","))
+	if ring, ok := i.chashRings.Load(signature); ok {
+		return ring.(chashRing) // nolint:forcetypeassert
+	}
+	ring, _ := i.chashRings.LoadOrStore(signature, newChashRing(dc))
+	return ring.(chashRing) // nolint:forcetypeassert
 }
 
 // Consistent Hashing director
@@ -360,96 +428,54 @@ func (i *Interpreter) directorBackendConsistentHash(dc *value.DirectorConfig) (*
 		return nil, err
 	}
 
-	var circles []uint32
-	hashTable := make(map[uint32]*value.Backend)
-
-	var healthyBackends int
-	max := uint32(math.Pow(10, 4)) // max 10000
-	// Put backends to the circles
-	for _, v := range dc.Backends {
-		if !v.Backend.Healthy.Load() {
-			continue
-		}
-		healthyBackends++
-		// typically loop three times in order to find suitable ring position
-		for i := 0; i < 3; i++ {
-			buf := make([]byte, 4)
-			binary.BigEndian.PutUint32(buf, dc.Seed)
-			hash := sha256.New() // TODO: consider to user hash/fnv for getting performance guarantee
-			hash.Write(buf)
-			hash.Write([]byte(v.Backend.Value.Name.Value))
-			hash.Write([]byte(fmt.Sprint(i)))
-			h := hash.Sum(nil)
-			num := binary.BigEndian.Uint32(h[:8]) % max
-			hashTable[num] = v.Backend
-			circles = append(circles, num)
-		}
+	key := i.ctx.RequestHash.Value
+	if dc.Key == "client" {
+		key = i.clientIdentity()
 	}
-
-	// Sort slice for binary search
-	sort.Slice(circles, func(i, j int) bool {
-		return circles[i] < circles[j]
+	ring := i.getChashRing(dc)
+	point := chashPoint(dc.Seed, key)
+	start := sort.Search(len(ring), func(i int) bool {
+		return ring[i].point >= point
 	})
 
-	var hashKey [32]byte
-	switch dc.Key {
-	case "object":
-		hashKey = sha256.Sum256([]byte(i.ctx.RequestHash.Value))
-	default: // same as client
-		var identity string
-		if i.ctx.ClientIdentity != nil {
-			identity = i.ctx.ClientIdentity.Value
-		} else {
-			identity = i.ctx.Request.RemoteAddr
-			if idx := strings.LastIndex(identity, ":"); idx != -1 {
-				identity = identity[:idx]
-			}
+	// Find the first healthy vnode clockwise, so that only keys of unhealthy backends are moved
+	for n := 0; n < len(ring); n++ {
+		v := dc.Backends[ring[(start+n)%len(ring)].index]
+		if v.Backend.IsHealthy() {
+			return v.Backend, nil
 		}
-		hashKey = sha256.Sum256([]byte(identity))
-	}
-
-	key := binary.BigEndian.Uint32(hashKey[:8]) % max
-	index := sort.Search(len(circles), func(i int) bool {
-		return circles[i] >= key
-	})
-	if index == len(circles) {
-		index = 0
 	}
+	return nil, ErrAllBackendsFailed
+}
 
-	return hashTable[circles[index]], nil
+// chashPoint returns the position on the ring of chash director
+func chashPoint(seed uint32, key string) uint32 {
+	buf := make([]byte, 4)
+	binary.BigEndian.PutUint32(buf, seed)
+	h := sha256.Sum256(append(buf, key...))
+	return binary.BigEndian.Uint32(h[:4])
 }
 
-func (i *Interpreter) getBackendByHash(dc *value.DirectorConfig, hash []byte) (*value.Backend, error) {
+// getBackendByHash chooses the healthy backend for the key by weighted rendezvous hashing.
+// The same key always chooses the same backend while backends are healthy,
+// and only keys of unhealthy backends are moved to other backends in proportion to their weight.
+func (i *Interpreter) getBackendByHash(dc *value.DirectorConfig, key string) (*value.Backend, error) {
 	if err := i.canDetermineBackend(dc); err != nil {
 		return nil, err
 	}
 
 	var target *value.Backend
-	for m := 4; m <= 16; m += 2 {
-		max := uint64(math.Pow(10, float64(m)))
-		num := binary.BigEndian.Uint64(hash[:8]) % max
-
-		for _, v := range dc.Backends {
-			if !v.Backend.Healthy.Load() {
-				continue
-			}
-			bh := sha256.Sum256([]byte(v.Backend.Value.String()))
-			b := binary.BigEndian.Uint64(bh[:8])
-			if b%(max*10) >= num && b%(max*10) < num+max {
-				target = v.Backend
-				goto DETERMINED
-			}
+	var best float64
+	for _, v := range dc.Backends {
+		if !v.Backend.IsHealthy() {
+			continue
 		}
-	}
-DETERMINED:
-
-	// When target is not determined, use first healthy backend
-	if target == nil {
-		for _, v := range dc.Backends {
-			if !v.Backend.Healthy.Load() {
-				continue
-			}
-			return v.Backend, nil
+		h := sha256.Sum256([]byte(key + "\x00" + v.Backend.Value.Name.Value))
+		// Uniform value in (0, 1) from the upper 53 bits of the hash
+		u := (float64(binary.BigEndian.Uint64(h[:8])>>11) + 0.5) / (1 << 53)
+		score := -float64(v.EffectiveWeight()) / math.Log(u)
+		if target == nil || score > best {
+			target, best = v.Backend, score
 		}
 	}
 	return target, nil
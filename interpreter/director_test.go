@@ -90,7 +90,7 @@ director test random {
 }
 
 func TestGetDirectorConfigShield(t *testing.T) {
-	director := "director test shield { }"
+	director := `director test shield { .shield = "iad-va-us"; .is_ssl = true; }`
 	ip, err := createTestInterpreter(director)
 	if err != nil {
 		t.Errorf("Failed to create interpreter: %s", err)
@@ -102,6 +102,7 @@ func TestGetDirectorConfigShield(t *testing.T) {
 		Retries: 0,
 		Name:    "test",
 		Type:    "shield",
+		Shield:  "iad-va-us",
 	}
 	if diff := cmp.Diff(expect, d, cmpopts.IgnoreFields(value.Backend{}, "Healthy")); diff != "" {
 		t.Errorf("getDirectorConfig returns diff: %s", diff)
@@ -135,6 +136,27 @@ director test random {
 		}
 	})
 
+	t.Run("Quorum is calculated by weight", func(t *testing.T) {
+		ip, err := createTestInterpreter(director)
+		if err != nil {
+			t.Errorf("Failed to create interpreter: %s", err)
+		}
+		d := ip.ctx.Backends["test"].Director
+
+		// healthy weight is 2/4 although healthy backend count is 1/3
+		ip.ctx.Backends["test02"].Healthy.Store(false)
+		ip.ctx.Backends["test03"].Healthy.Store(false)
+
+		r, err := ip.directorBackendRandom(d)
+		if err != nil {
+			t.Errorf("Random director backend determination failed: %s", err)
+			return
+		}
+		if r != ip.ctx.Backends["test01"] {
+			t.Errorf("Only healthy test01 backend should be determined")
+		}
+	})
+
 	t.Run("Fair randomness", func(t *testing.T) {
 		ip, err := createTestInterpreter(director)
 		if err != nil {
@@ -363,14 +385,14 @@ director test client {
 		b01 := results[ip.ctx.Backends["test01"]] / 100
 		b02 := results[ip.ctx.Backends["test02"]] / 100
 		b03 := results[ip.ctx.Backends["test03"]] / 100
-		if b01 != 100 {
-			t.Errorf("test01 backend determined 100%% probability, got %d%%", b01)
+		if b01 != 0 {
+			t.Errorf("test01 backend determined 0%% probability, got %d%%", b01)
 		}
 		if b02 != 0 {
 			t.Errorf("test02 backend determined 0%% probability, got %d%%", b02)
 		}
-		if b03 != 0 {
-			t.Errorf("test03 backend determined 0%% probability, got %d%%", b03)
+		if b03 != 100 {
+			t.Errorf("test03 backend determined 100%% probability, got %d%%", b03)
 		}
 	})
 }
@@ -472,14 +494,50 @@ director test chash {
 		b01 := results[ip.ctx.Backends["test01"]] / 100
 		b02 := results[ip.ctx.Backends["test02"]] / 100
 		b03 := results[ip.ctx.Backends["test03"]] / 100
-		if b01 != 100 {
-			t.Errorf("test01 backend determined 100%% probability, got %d%%", b01)
+		if b01 != 0 {
+			t.Errorf("test01 backend determined 0%% probability, got %d%%", b01)
 		}
-		if b02 != 0 {
-			t.Errorf("test02 backend determined 0%% probability, got %d%%", b02)
+		if b02 != 100 {
+			t.Errorf("test02 backend determined 100%% probability, got %d%%", b02)
 		}
 		if b03 != 0 {
 			t.Errorf("test03 backend determined 0%% probability, got %d%%", b03)
 		}
 	})
+
+	t.Run("Only keys of unhealthy backend are moved", func(t *testing.T) {
+		ip, err := createTestInterpreter(director)
+		if err != nil {
+			t.Errorf("Failed to create interpreter: %s", err)
+		}
+		d := ip.ctx.Backends["test"].Director
+
+		keys := make([]string, 1000)
+		before := make([]*value.Backend, len(keys))
+		for i := range keys {
+			keys[i] = fmt.Sprintf("/path/%d", i)
+			ip.ctx.RequestHash = &value.String{Value: keys[i]}
+			before[i], err = ip.directorBackendConsistentHash(d)
+			if err != nil {
+				t.Errorf("Chash director backend determination failed: %s", err)
+				return
+			}
+		}
+
+		ip.ctx.Backends["test02"].Healthy.Store(false)
+		for i := range keys {
+			ip.ctx.RequestHash = &value.String{Value: keys[i]}
+			after, err := ip.directorBackendConsistentHash(d)
+			if err != nil {
+				t.Errorf("Chash director backend determination failed: %s", err)
+				return
+			}
+			if before[i] != ip.ctx.Backends["test02"] && after != before[i] {
+				t.Errorf("Key %s should not be moved from %s to %s", keys[i], before[i].Value.Name.Value, after.Value.Name.Value)
+			}
+			if after == ip.ctx.Backends["test02"] {
+				t.Errorf("Key %s should not be assigned to unhealthy backend", keys[i])
+			}
+		}
+	})
 }
@@ -2,126 +2,336 @@ package interpreter
 
 import (
 	"bytes"
-	"context"
+	"fmt"
 	"io"
 	"net/http"
 	"net/url"
-	"path/filepath"
 	"regexp"
 	"strings"
-	"time"
 
+	"github.com/pkg/errors"
 	"github.com/ysugimoto/falco/interpreter/exception"
 )
 
+// Fastly processes nested ESI includes up to 5 levels
+// see: https://developer.fastly.com/reference/vcl/statements/esi/
+const maxEsiDepth = 5
+
 var (
-	esiIncludeRegex = regexp.MustCompile(`<esi:include\s*src=['"]([^'"]+)['"]\s*/?\s*>`)
-	esiRemoveStart  = []byte("<esi:remove>")
-	esiRemoveEnd    = []byte("</esi:remove>")
+	esiTagRegex      = regexp.MustCompile(`^<esi:([a-z]+)((?:\s+[a-z]+\s*=\s*(?:"[^"]*"|'[^']*'))*)\s*(/?)>`)
+	esiAttributes    = regexp.MustCompile(`([a-z]+)\s*=\s*(?:"([^"]*)"|'([^']*)')`)
+	esiVariableRegex = regexp.MustCompile(`\$\(([A-Z_]+)(?:\{([^}]*)\})?\)`)
+	esiTagStart      = []byte("<esi:")
+	esiCommentStart  = []byte("<!--esi")
+	esiCommentEnd    = []byte("-->")
 )
 
+// executeESI processes ESI tags in the client response body
 func (i *Interpreter) executeESI() error {
 	resp := i.ctx.Response
 	if resp == nil {
 		return exception.System("Client Response is nil")
 	}
+	// ESI is processed on the decompressed body
+	if ok, err := decompressResponse(resp); err != nil {
+		return errors.WithStack(err)
+	} else if !ok {
+		i.Debugger.Message(fmt.Sprintf("ESI could not be processed on %s encoded response", resp.Header.Get("Content-Encoding")))
+		return nil
+	}
 
 	var respBody bytes.Buffer
 	if _, err := respBody.ReadFrom(resp.Body); err != nil {
 		return err
 	}
 
-	body := respBody.Bytes()
-	req := i.ctx.Request
-	ctx := i.ctx.Request.Context()
-	var parsed []byte
+	parsed, err := i.processESI(respBody.Bytes())
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(parsed))
+	resp.ContentLength = int64(len(parsed))
+	resp.Header.Del("Content-Length")
+	return nil
+}
+
+// processESI processes following ESI markups in the body:
+// - <esi:include src="..." alt="..." onerror="continue"/> is replaced with the response of the sub-request
+// - <esi:remove>...</esi:remove> and <esi:comment text="..."/> are removed
+// - <esi:choose> is replaced with the first <esi:when> whose test expression is true, or <esi:otherwise>
+// - <!--esi ... --> is replaced with its processed content
+func (i *Interpreter) processESI(body []byte) ([]byte, error) {
+	var out bytes.Buffer
 	for {
-		match := esiIncludeRegex.FindSubmatchIndex(body)
-		if len(match) == 0 {
-			break
-		}
-		previous := body[0:match[0]]
-		src := body[match[2]:match[3]]
-		body = body[match[1]:]
-		parsed = append(parsed, previous...)
-
-		// resolve inclusion
-		cloned := req.Clone(ctx)
-		partial, err := executeEsiInclude(ctx, cloned, src)
-		if err != nil {
-			// If ESI inclusion failed, find <esi:remove> tag and use its nodeText
-			index := bytes.Index(body, esiRemoveStart)
-			if index == -1 {
-				break
+		index := esiIndex(body)
+		if index == -1 {
+			out.Write(body)
+			return out.Bytes(), nil
+		}
+		out.Write(body[:index])
+		body = body[index:]
+
+		if bytes.HasPrefix(body, esiCommentStart) {
+			end := bytes.Index(body, esiCommentEnd)
+			if end == -1 {
+				return nil, exception.Runtime(nil, "Syntax error: does not seem to close <!--esi")
+			}
+			inner, err := i.processESI(body[len(esiCommentStart):end])
+			if err != nil {
+				return nil, errors.WithStack(err)
+			}
+			out.Write(inner)
+			body = body[end+len(esiCommentEnd):]
+			continue
+		}
+
+		match := esiTagRegex.FindSubmatch(body)
+		if match == nil {
+			return nil, exception.Runtime(nil, "Syntax error: invalid ESI tag %s", esiSnippet(body))
+		}
+		name := string(match[1])
+		attrs := esiTagAttributes(match[2])
+		body = body[len(match[0]):]
+
+		var content []byte
+		if len(match[3]) == 0 {
+			var err error
+			if content, body, err = esiBlock(body, name); err != nil {
+				return nil, errors.WithStack(err)
 			}
-			body = body[index+len(esiRemoveStart):]
+		}
 
-			// Find </esi:remove> tag
-			index = bytes.Index(body, esiRemoveEnd)
-			if index == -1 {
-				return exception.Runtime(nil, "Syntax error: does not seem to close </esi:remove>")
+		switch name {
+		case "include":
+			partial, err := i.esiInclude(attrs)
+			if err != nil {
+				return nil, errors.WithStack(err)
 			}
-			parsed = append(parsed, body[0:index]...)
-			body = body[index+len(esiRemoveEnd):]
-		} else {
-			parsed = append(parsed, partial...)
-			// If ESI inclusion succeeded, find <esi:remove>...</esi:remove> tag and remove it
-			index := bytes.Index(body, esiRemoveStart)
-			if index == -1 {
-				continue
+			out.Write(partial)
+		case "remove", "comment":
+			// Nothing to output
+		case "choose":
+			branch, err := i.esiChoose(content)
+			if err != nil {
+				return nil, errors.WithStack(err)
 			}
-			body = body[index+len(esiRemoveStart):]
-			index = bytes.Index(body, esiRemoveEnd)
-			if index == -1 {
-				return exception.Runtime(nil, "Syntax error: does not seem to close </esi:remove>")
+			inner, err := i.processESI(branch)
+			if err != nil {
+				return nil, errors.WithStack(err)
 			}
-			body = body[index+len(esiRemoveEnd):]
+			out.Write(inner)
+		default:
+			return nil, exception.Runtime(nil, "ESI tag <esi:%s> is not supported", name)
 		}
 	}
+}
 
-	// remaining bytes
-	if len(body) > 0 {
-		parsed = append(parsed, body...)
+// esiIndex returns the index of the next ESI tag or ESI comment
+func esiIndex(body []byte) int {
+	tag := bytes.Index(body, esiTagStart)
+	comment := bytes.Index(body, esiCommentStart)
+	if tag == -1 || (comment != -1 && comment < tag) {
+		return comment
 	}
-	resp.Body = io.NopCloser(bytes.NewReader(parsed))
-	return nil
+	return tag
 }
 
-func executeEsiInclude(ctx context.Context, req *http.Request, includeUrl []byte) ([]byte, error) {
-	ctx, timeout := context.WithTimeout(ctx, 10*time.Second)
-	defer timeout()
+// esiSnippet returns the beginning of the body for error messages
+func esiSnippet(body []byte) string {
+	if end := bytes.IndexByte(body, '>'); end != -1 {
+		return string(body[:end+1])
+	}
+	return string(body)
+}
 
-	if err := resolveIncludeURL(req, string(includeUrl)); err != nil {
-		return nil, err
+func esiTagAttributes(src []byte) map[string]string {
+	attrs := make(map[string]string)
+	for _, m := range esiAttributes.FindAllSubmatch(src, -1) {
+		if len(m[2]) > 0 {
+			attrs[string(m[1])] = string(m[2])
+		} else {
+			attrs[string(m[1])] = string(m[3])
+		}
 	}
+	return attrs
+}
 
-	resp, err := http.DefaultClient.Do(req.WithContext(ctx))
-	if err != nil {
-		return nil, err
+// esiBlock returns the content until the closing tag of the name and the remaining body.
+// Nested tags which have the same name are skipped.
+func esiBlock(body []byte, name string) ([]byte, []byte, error) {
+	open := regexp.MustCompile(`<esi:` + name + `[\s>]|</esi:` + name + `>`)
+	depth := 1
+	var offset int
+	for {
+		loc := open.FindIndex(body[offset:])
+		if loc == nil {
+			return nil, nil, exception.Runtime(nil, "Syntax error: does not seem to close </esi:%s>", name)
+		}
+		start, end := offset+loc[0], offset+loc[1]
+		if body[start+1] == '/' {
+			depth--
+		} else {
+			depth++
+		}
+		if depth == 0 {
+			return body[:start], body[end:], nil
+		}
+		offset = end
+	}
+}
+
+// esiInclude requests the fragment of src attribute, or alt attribute if src fails.
+// The failure is ignored when onerror="continue" is specified.
+func (i *Interpreter) esiInclude(attrs map[string]string) ([]byte, error) {
+	for _, src := range []string{attrs["src"], attrs["alt"]} {
+		if src == "" {
+			continue
+		}
+		partial, err := i.esiSubRequest(i.esiVariables(src))
+		if err == nil {
+			return partial, nil
+		}
+		i.Debugger.Message(fmt.Sprintf("ESI include %s failed: %s", src, err))
+	}
+	if attrs["onerror"] == "continue" {
+		return nil, nil
+	}
+	return nil, exception.Runtime(nil, "Failed to include ESI fragment %s", attrs["src"])
+}
+
+// esiSubRequest processes the request for the fragment through the VCL flow.
+// The sub-request shares the cache with the parent request.
+func (i *Interpreter) esiSubRequest(src string) ([]byte, error) {
+	if i.esiDepth >= maxEsiDepth {
+		return nil, exception.Runtime(nil, "ESI include depth exceeds %d", maxEsiDepth)
+	}
+
+	req := i.ctx.Request.Clone(i.ctx.Request.Context())
+	if err := resolveIncludeURL(req, src); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	sub := i.fork()
+	sub.esiDepth = i.esiDepth + 1
+
+	i.Debugger.Message(fmt.Sprintf("ESI sub-request %s =========>", req.URL.String()))
+	defer i.Debugger.Message(fmt.Sprintf("<========= ESI sub-request %s finished", req.URL.String()))
+	if err := sub.ProcessInit(req); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	// Sub-request is processed on the same server
+	sub.ctx.Server = i.ctx.Server
+	sub.ctx.Client = i.ctx.Client
+	if err := sub.ProcessRecv(); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	resp := sub.ctx.Response
+	if resp == nil {
+		return nil, exception.System("ESI sub-request %s has no response", src)
+	}
+	if resp.StatusCode >= http.StatusBadRequest {
+		return nil, exception.Runtime(nil, "ESI sub-request %s responds status code %d", src, resp.StatusCode)
 	}
-	defer resp.Body.Close()
 	var buf bytes.Buffer
 	if _, err := buf.ReadFrom(resp.Body); err != nil {
-		return nil, err
+		return nil, errors.WithStack(err)
 	}
 	return buf.Bytes(), nil
 }
 
-func resolveIncludeURL(req *http.Request, path string) error {
-	switch {
-	case strings.HasPrefix(path, "http://"), strings.HasPrefix(path, "https://"):
-		parsed, err := url.Parse(path)
+func resolveIncludeURL(req *http.Request, src string) error {
+	ref, err := url.Parse(src)
+	if err != nil {
+		return err
+	}
+	req.URL = req.URL.ResolveReference(ref)
+	if ref.Host != "" {
+		req.Host = ref.Host
+	}
+	return nil
+}
+
+// esiChoose returns the content of the first <esi:when> whose test expression is true,
+// or <esi:otherwise> if nothing matches
+func (i *Interpreter) esiChoose(body []byte) ([]byte, error) {
+	var otherwise []byte
+	for {
+		index := bytes.Index(body, esiTagStart)
+		if index == -1 {
+			return otherwise, nil
+		}
+		body = body[index:]
+		match := esiTagRegex.FindSubmatch(body)
+		if match == nil || len(match[3]) > 0 {
+			return nil, exception.Runtime(nil, "Syntax error: invalid ESI tag %s in <esi:choose>", esiSnippet(body))
+		}
+		name := string(match[1])
+		attrs := esiTagAttributes(match[2])
+		content, remains, err := esiBlock(body[len(match[0]):], name)
 		if err != nil {
-			return err
+			return nil, errors.WithStack(err)
 		}
-		req.URL = parsed
-		req.Header.Set("Host", parsed.Host)
-		return nil
-	case strings.HasPrefix(path, "/"):
-		req.URL.Path = path
-		return nil
-	default:
-		req.URL.Path = filepath.Join(req.URL.Path, path)
-		return nil
+		body = remains
+
+		switch name {
+		case "when":
+			ok, err := i.esiTest(attrs["test"])
+			if err != nil {
+				return nil, errors.WithStack(err)
+			}
+			if ok {
+				return content, nil
+			}
+		case "otherwise":
+			otherwise = content
+		default:
+			return nil, exception.Runtime(nil, "ESI tag <esi:%s> is not allowed in <esi:choose>", name)
+		}
+	}
+}
+
+// esiVariables replaces ESI variables like $(HTTP_COOKIE{name}) in the string
+func (i *Interpreter) esiVariables(src string) string {
+	return esiVariableRegex.ReplaceAllStringFunc(src, func(v string) string {
+		m := esiVariableRegex.FindStringSubmatch(v)
+		return i.esiVariable(m[1], m[2])
+	})
+}
+
+// esiVariable returns the value of ESI variable, an empty string is returned for unknown variables
+func (i *Interpreter) esiVariable(name, key string) string {
+	req := i.ctx.Request
+	switch name {
+	case "HTTP_COOKIE":
+		if key == "" {
+			return req.Header.Get("Cookie")
+		}
+		if c, err := req.Cookie(key); err == nil {
+			return c.Value
+		}
+		return ""
+	case "QUERY_STRING":
+		if key == "" {
+			return req.URL.RawQuery
+		}
+		return req.URL.Query().Get(key)
+	case "HTTP_HOST":
+		return req.Host
+	case "HTTP_ACCEPT_LANGUAGE":
+		if key == "" {
+			return req.Header.Get("Accept-Language")
+		}
+		for _, lang := range strings.Split(req.Header.Get("Accept-Language"), ",") {
+			lang, _, _ = strings.Cut(strings.TrimSpace(lang), ";")
+			if strings.EqualFold(lang, key) || strings.HasPrefix(strings.ToLower(lang), strings.ToLower(key)+"-") {
+				return "true"
+			}
+		}
+		return ""
+	}
+	if header, ok := strings.CutPrefix(name, "HTTP_"); ok {
+		return req.Header.Get(strings.ReplaceAll(header, "_", "-"))
 	}
+	return ""
 }
@@ -0,0 +1,227 @@
+package interpreter
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/ysugimoto/falco/interpreter/exception"
+)
+
+type esiTokenKind int
+
+const (
+	esiValue esiTokenKind = iota
+	esiOperator
+)
+
+type esiToken struct {
+	kind  esiTokenKind
+	value string
+}
+
+// Operators of ESI test expression, longer operators must come first
+var esiOperators = []string{"==", "!=", "<=", ">=", "&&", "||", "<", ">", "&", "|", "!", "(", ")"}
+
+// esiTest evaluates test attribute of <esi:when>, e.g. $(HTTP_COOKIE{group}) == 'beta' & !($(QUERY_STRING{debug}))
+// Variables are compared as numbers if both sides are numeric, otherwise compared as strings.
+// Single value is true when it is not empty.
+func (i *Interpreter) esiTest(expr string) (bool, error) {
+	tokens, err := i.esiTokenize(expr)
+	if err != nil {
+		return false, err
+	}
+	p := &esiExpression{tokens: tokens, expr: expr}
+	v, err := p.or()
+	if err != nil {
+		return false, err
+	}
+	if p.pos < len(p.tokens) {
+		return false, p.error()
+	}
+	return v, nil
+}
+
+func (i *Interpreter) esiTokenize(expr string) ([]esiToken, error) {
+	var tokens []esiToken
+	for pos := 0; pos < len(expr); {
+		rest := expr[pos:]
+		switch {
+		case rest[0] == ' ' || rest[0] == '\t' || rest[0] == '\n':
+			pos++
+			continue
+		case strings.HasPrefix(rest, "$("):
+			loc := esiVariableRegex.FindStringSubmatchIndex(rest)
+			if loc == nil || loc[0] != 0 {
+				return nil, exception.Runtime(nil, "Syntax error: invalid ESI variable in %s", expr)
+			}
+			var key string
+			if loc[4] != -1 {
+				key = rest[loc[4]:loc[5]]
+			}
+			tokens = append(tokens, esiToken{kind: esiValue, value: i.esiVariable(rest[loc[2]:loc[3]], key)})
+			pos += loc[1]
+			continue
+		case rest[0] == '\'':
+			end := strings.IndexByte(rest[1:], '\'')
+			if end == -1 {
+				return nil, exception.Runtime(nil, "Syntax error: unterminated string in %s", expr)
+			}
+			tokens = append(tokens, esiToken{kind: esiValue, value: rest[1 : end+1]})
+			pos += end + 2
+			continue
+		}
+
+		var matched bool
+		for _, op := range esiOperators {
+			if strings.HasPrefix(rest, op) {
+				tokens = append(tokens, esiToken{kind: esiOperator, value: op})
+				pos += len(op)
+				matched = true
+				break
+			}
+		}
+		if matched {
+			continue
+		}
+
+		// Bare literal like number
+		end := strings.IndexAny(rest, " \t\n=!<>&|()")
+		if end == -1 {
+			end = len(rest)
+		}
+		if end == 0 {
+			return nil, exception.Runtime(nil, "Syntax error: unexpected character %q in %s", rest[0], expr)
+		}
+		tokens = append(tokens, esiToken{kind: esiValue, value: rest[:end]})
+		pos += end
+	}
+	return tokens, nil
+}
+
+type esiExpression struct {
+	tokens []esiToken
+	pos    int
+	expr   string
+}
+
+func (p *esiExpression) error() error {
+	return exception.Runtime(nil, "Syntax error: invalid ESI test expression %s", p.expr)
+}
+
+// operator consumes the next token if it is one of the operators
+func (p *esiExpression) operator(ops ...string) (string, bool) {
+	if p.pos >= len(p.tokens) || p.tokens[p.pos].kind != esiOperator {
+		return "", false
+	}
+	for _, op := range ops {
+		if p.tokens[p.pos].value == op {
+			p.pos++
+			return op, true
+		}
+	}
+	return "", false
+}
+
+func (p *esiExpression) or() (bool, error) {
+	left, err := p.and()
+	if err != nil {
+		return false, err
+	}
+	for {
+		if _, ok := p.operator("|", "||"); !ok {
+			return left, nil
+		}
+		right, err := p.and()
+		if err != nil {
+			return false, err
+		}
+		left = left || right
+	}
+}
+
+func (p *esiExpression) and() (bool, error) {
+	left, err := p.unary()
+	if err != nil {
+		return false, err
+	}
+	for {
+		if _, ok := p.operator("&", "&&"); !ok {
+			return left, nil
+		}
+		right, err := p.unary()
+		if err != nil {
+			return false, err
+		}
+		left = left && right
+	}
+}
+
+func (p *esiExpression) unary() (bool, error) {
+	if _, ok := p.operator("!"); ok {
+		v, err := p.unary()
+		return !v, err
+	}
+	if _, ok := p.operator("("); ok {
+		v, err := p.or()
+		if err != nil {
+			return false, err
+		}
+		if _, ok := p.operator(")"); !ok {
+			return false, p.error()
+		}
+		return v, nil
+	}
+	return p.comparison()
+}
+
+func (p *esiExpression) comparison() (bool, error) {
+	left, ok := p.value()
+	if !ok {
+		return false, p.error()
+	}
+	op, ok := p.operator("==", "!=", "<=", ">=", "<", ">")
+	if !ok {
+		return left != "", nil
+	}
+	right, ok := p.value()
+	if !ok {
+		return false, p.error()
+	}
+
+	var cmp int
+	l, lerr := strconv.ParseFloat(left, 64)
+	r, rerr := strconv.ParseFloat(right, 64)
+	switch {
+	case lerr == nil && rerr == nil && l < r:
+		cmp = -1
+	case lerr == nil && rerr == nil && l > r:
+		cmp = 1
+	case lerr == nil && rerr == nil:
+		cmp = 0
+	default:
+		cmp = strings.Compare(left, right)
+	}
+
+	switch op {
+	case "==":
+		return cmp == 0, nil
+	case "!=":
+		return cmp != 0, nil
+	case "<=":
+		return cmp <= 0, nil
+	case ">=":
+		return cmp >= 0, nil
+	case "<":
+		return cmp < 0, nil
+	default:
+		return cmp > 0, nil
+	}
+}
+
+func (p *esiExpression) value() (string, bool) {
+	if p.pos >= len(p.tokens) || p.tokens[p.pos].kind != esiValue {
+		return "", false
+	}
+	p.pos++
+	return p.tokens[p.pos-1].value, true
+}
@@ -0,0 +1,131 @@
+package interpreter
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/ysugimoto/falco/interpreter/context"
+	"github.com/ysugimoto/falco/resolver"
+)
+
+func TestESI(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "max-age=60")
+		switch r.URL.Path {
+		case "/":
+			w.Write([]byte(strings.Join([]string{ // nolint:errcheck
+				`<esi:include src="/fragment?name=$(QUERY_STRING{name})"/>`,
+				`<esi:remove>not processed</esi:remove>`,
+				`<esi:comment text="comment"/>`,
+				`<esi:choose>`,
+				`  <esi:when test="$(HTTP_COOKIE{group}) == 'beta' && !$(HTTP_X_DISABLED)">beta<esi:include src="fragment"/></esi:when>`,
+				`  <esi:otherwise>stable</esi:otherwise>`,
+				`</esi:choose>`,
+				`<!--esi <esi:include src="/missing" onerror="continue"/>-->`,
+			}, "|")))
+		case "/fragment":
+			w.Write([]byte("fragment:" + r.URL.Query().Get("name"))) // nolint:errcheck
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	parsed, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatalf("Test server URL parsing error: %s", err)
+	}
+
+	vcl := defaultBackend(parsed) + `
+sub vcl_recv {
+	#FASTLY RECV
+	return(lookup);
+}
+sub vcl_fetch {
+	#FASTLY FETCH
+	if (req.url.path == "/") {
+		set beresp.do_esi = true;
+	}
+}`
+
+	tests := []struct {
+		name   string
+		cookie string
+		expect string
+	}{
+		{name: "otherwise", expect: "fragment:falco|||stable|"},
+		{name: "when", cookie: "group=beta", expect: "fragment:falco|||betafragment:|"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ip := New(context.WithResolver(resolver.NewStaticResolver("main", vcl)))
+			// Process twice to check ESI is also processed on cache hit
+			for _, state := range []string{"MISS", "HIT"} {
+				req := httptest.NewRequest(http.MethodGet, "http://localhost/?name=falco", nil)
+				if tt.cookie != "" {
+					req.Header.Set("Cookie", tt.cookie)
+				}
+				if err := ip.ProcessInit(req); err != nil {
+					t.Fatalf("Unexpected init error: %s", err)
+				}
+				if err := ip.ProcessRecv(); err != nil {
+					t.Fatalf("Unexpected error: %s", err)
+				}
+				if ip.ctx.State != state {
+					t.Errorf("Expected state %s, got %s", state, ip.ctx.State)
+				}
+				body, err := io.ReadAll(ip.ctx.Response.Body)
+				if err != nil {
+					t.Fatalf("Unexpected read error: %s", err)
+				}
+				if diff := cmp.Diff(tt.expect, strings.Join(strings.Fields(string(body)), "")); diff != "" {
+					t.Errorf("ESI result mismatch, diff=%s", diff)
+				}
+			}
+		})
+	}
+}
+
+func TestESITestExpression(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "http://localhost/?page=3", nil)
+	req.Header.Set("Accept-Language", "ja-JP,en;q=0.8")
+	ip := &Interpreter{ctx: context.New()}
+	ip.ctx.Request = req
+
+	tests := []struct {
+		expr   string
+		expect bool
+		isErr  bool
+	}{
+		{expr: "$(QUERY_STRING{page}) > 2", expect: true},
+		{expr: "$(QUERY_STRING{page}) == '10'", expect: false},
+		{expr: "$(HTTP_ACCEPT_LANGUAGE{ja}) & !($(HTTP_ACCEPT_LANGUAGE{fr}) | $(HTTP_HOST) != 'localhost')", expect: true},
+		{expr: "$(HTTP_COOKIE{id})", expect: false},
+		{expr: "$(HTTP_HOST) ==", isErr: true},
+		{expr: "('a' == 'a'", isErr: true},
+	}
+
+	for _, tt := range tests {
+		actual, err := ip.esiTest(tt.expr)
+		if tt.isErr {
+			if err == nil {
+				t.Errorf("Expected error for %s", tt.expr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Unexpected error for %s: %s", tt.expr, err)
+			continue
+		}
+		if actual != tt.expect {
+			t.Errorf("Expected %t for %s, got %t", tt.expect, tt.expr, actual)
+		}
+	}
+}
@@ -3,6 +3,7 @@ package exception
 import (
 	"fmt"
 
+	"github.com/ysugimoto/falco/ast"
 	"github.com/ysugimoto/falco/token"
 )
 
@@ -17,6 +18,8 @@ type Exception struct {
 	Type    Type
 	Token   *token.Token
 	Message string
+	// Include statements which lead to the file of the token, from the nearest one to the main VCL
+	IncludedFrom []ast.SourceLocation
 }
 
 func (e *Exception) Error() string {
@@ -33,6 +36,9 @@ func (e *Exception) Error() string {
 
 		out = fmt.Sprintf("[%s] %s%s at line: %d, position: %d", e.Type, e.Message, file, t.Line, t.Position)
 	}
+	for _, loc := range e.IncludedFrom {
+		out += fmt.Sprintf("\n  included from %s", loc.String())
+	}
 
 	// SystemException means problem of falco implementation
 	// Output additional message that report URL :-)
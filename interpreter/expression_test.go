@@ -143,7 +143,8 @@ func TestPrefixExpression(t *testing.T) {
 				expression: &ast.PrefixExpression{
 					Operator: "-",
 					Right: &ast.RTime{
-						Value: "1d",
+						Value:    "1d",
+						Duration: 24 * time.Hour,
 					},
 					Meta: &ast.Meta{
 						Token: token.Token{Type: token.MINUS},
@@ -3,9 +3,6 @@
 package builtin
 
 import (
-	"math/rand"
-	"time"
-
 	"github.com/ysugimoto/falco/interpreter/context"
 	"github.com/ysugimoto/falco/interpreter/function/errors"
 	"github.com/ysugimoto/falco/interpreter/value"
@@ -44,8 +41,7 @@ func Randombool(ctx *context.Context, args ...value.Value) (value.Value, error)
 		return &value.Boolean{Value: false}, nil
 	}
 
-	r := rand.New(rand.NewSource(time.Now().UnixNano()))
-	rv := r.Float64()
+	rv := ctx.Rand().Float64()
 	ratio := float64(numerator.Value) / float64(denominator.Value)
 
 	return &value.Boolean{Value: rv < ratio}, nil
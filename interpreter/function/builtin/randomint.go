@@ -3,9 +3,6 @@
 package builtin
 
 import (
-	"math/rand"
-	"time"
-
 	"github.com/ysugimoto/falco/interpreter/context"
 	"github.com/ysugimoto/falco/interpreter/function/errors"
 	"github.com/ysugimoto/falco/interpreter/value"
@@ -40,8 +37,7 @@ func Randomint(ctx *context.Context, args ...value.Value) (value.Value, error) {
 	from := value.Unwrap[*value.Integer](args[0])
 	to := value.Unwrap[*value.Integer](args[1])
 
-	r := rand.New(rand.NewSource(time.Now().UnixNano()))
-	rv := r.Int63n(to.Value - from.Value + 1)
+	rv := ctx.Rand().Int63n(to.Value - from.Value + 1)
 
 	return &value.Integer{
 		Value: rv + from.Value,
@@ -5,6 +5,7 @@ package builtin
 import (
 	"testing"
 
+	"github.com/google/go-cmp/cmp"
 	"github.com/ysugimoto/falco/interpreter/context"
 	"github.com/ysugimoto/falco/interpreter/value"
 )
@@ -44,3 +45,22 @@ func Test_Randomint(t *testing.T) {
 		}
 	}
 }
+
+func Test_Randomint_Seeded_Context(t *testing.T) {
+	generate := func() []int64 {
+		ctx := &context.Context{Random: context.NewRandom(42)}
+		var values []int64
+		for i := 0; i < 10; i++ {
+			ret, err := Randomint(ctx, &value.Integer{Value: 0}, &value.Integer{Value: 1000})
+			if err != nil {
+				t.Fatalf("Unexpected error: %s", err)
+			}
+			values = append(values, value.Unwrap[*value.Integer](ret).Value)
+		}
+		return values
+	}
+
+	if diff := cmp.Diff(generate(), generate()); diff != "" {
+		t.Errorf("Expected the same values with the same seed, diff=%s", diff)
+	}
+}
@@ -3,9 +3,6 @@
 package builtin
 
 import (
-	"math/rand"
-	"time"
-
 	"github.com/ysugimoto/falco/interpreter/context"
 	"github.com/ysugimoto/falco/interpreter/function/errors"
 	"github.com/ysugimoto/falco/interpreter/value"
@@ -48,11 +45,11 @@ func Randomstr(ctx *context.Context, args ...value.Value) (value.Value, error) {
 		characters = []rune(value.Unwrap[*value.String](args[1]).Value)
 	}
 
-	rand.Seed(time.Now().UnixNano())
+	r := ctx.Rand()
 	ret := make([]rune, int(length.Value))
 
 	for i := 0; i < int(length.Value); i++ {
-		ret[i] = characters[rand.Intn(len(characters)-1)]
+		ret[i] = characters[r.Intn(len(characters)-1)]
 	}
 
 	return &value.String{Value: string(ret)}, nil
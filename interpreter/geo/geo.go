@@ -0,0 +1,74 @@
+// Package geo provides the geolocation of the client IP for client.geo.* variables
+package geo
+
+import (
+	"net"
+
+	"github.com/pkg/errors"
+)
+
+// Location is the geolocation data which corresponds to client.geo.* variables
+type Location struct {
+	City             string  `yaml:"city" json:"city"`
+	ConnSpeed        string  `yaml:"conn_speed" json:"conn_speed"`
+	ConnType         string  `yaml:"conn_type" json:"conn_type"`
+	ContinentCode    string  `yaml:"continent_code" json:"continent_code"`
+	CountryCode      string  `yaml:"country_code" json:"country_code"`
+	CountryCode3     string  `yaml:"country_code3" json:"country_code3"`
+	CountryName      string  `yaml:"country_name" json:"country_name"`
+	PostalCode       string  `yaml:"postal_code" json:"postal_code"`
+	ProxyDescription string  `yaml:"proxy_description" json:"proxy_description"`
+	ProxyType        string  `yaml:"proxy_type" json:"proxy_type"`
+	Region           string  `yaml:"region" json:"region"`
+	Latitude         float64 `yaml:"latitude" json:"latitude"`
+	Longitude        float64 `yaml:"longitude" json:"longitude"`
+	AreaCode         int64   `yaml:"area_code" json:"area_code"`
+	MetroCode        int64   `yaml:"metro_code" json:"metro_code"`
+	UtcOffset        int64   `yaml:"utc_offset" json:"utc_offset"`
+	TimeZone         string  `yaml:"time_zone" json:"time_zone"` // e.g. Asia/Tokyo, takes precedence over UtcOffset
+}
+
+// Database looks up the location of the IP address, nil is returned if the location is not found
+type Database interface {
+	Lookup(ip net.IP) (*Location, error)
+}
+
+// Databases looks up the location in order and returns the first one found
+type Databases []Database
+
+func (d Databases) Lookup(ip net.IP) (*Location, error) {
+	for _, db := range d {
+		loc, err := db.Lookup(ip)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		if loc != nil {
+			return loc, nil
+		}
+	}
+	return nil, nil
+}
+
+// New returns the database which looks up the overrides file first, then MaxMind database.
+// Empty path is skipped, and nil is returned when both are empty.
+func New(overrides, maxmind string) (Database, error) {
+	var dbs Databases
+	if overrides != "" {
+		o, err := LoadOverrides(overrides)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		dbs = append(dbs, o)
+	}
+	if maxmind != "" {
+		m, err := OpenMaxMind(maxmind)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		dbs = append(dbs, m)
+	}
+	if len(dbs) == 0 {
+		return nil, nil
+	}
+	return dbs, nil
+}
@@ -0,0 +1,218 @@
+package geo
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// Minimal MaxMind DB encoder for testing
+func encodeControl(typ, size int) []byte {
+	if typ > 7 {
+		return []byte{byte(size), byte(typ - 7)}
+	}
+	return []byte{byte(typ<<5 | size)}
+}
+
+func encode(v interface{}) []byte {
+	var buf bytes.Buffer
+	switch t := v.(type) {
+	case string:
+		buf.Write(encodeControl(typeString, len(t)))
+		buf.WriteString(t)
+	case float64:
+		buf.Write(encodeControl(typeDouble, 8))
+		b := make([]byte, 8)
+		binary.BigEndian.PutUint64(b, math.Float64bits(t))
+		buf.Write(b)
+	case int:
+		buf.Write(encodeControl(typeUint32, 4))
+		b := make([]byte, 4)
+		binary.BigEndian.PutUint32(b, uint32(t))
+		buf.Write(b)
+	case []interface{}:
+		buf.Write(encodeControl(typeArray, len(t)))
+		for _, item := range t {
+			buf.Write(encode(item))
+		}
+	case map[string]interface{}:
+		buf.Write(encodeControl(typeMap, len(t)))
+		for key, val := range t {
+			buf.Write(encode(key))
+			buf.Write(encode(val))
+		}
+	}
+	return buf.Bytes()
+}
+
+// buildMaxMind builds IPv4 database which has single node:
+// 0.0.0.0/1 is not found and 128.0.0.0/1 points to the record
+func buildMaxMind(record map[string]interface{}) []byte {
+	var buf bytes.Buffer
+	nodeCount := 1
+	buf.Write([]byte{0, 0, byte(nodeCount)})      // left: not found
+	buf.Write([]byte{0, 0, byte(nodeCount + 16)}) // right: data offset 0
+	buf.Write(make([]byte, dataSectionSeparator))
+	buf.Write(encode(record))
+	buf.Write(metadataMarker)
+	buf.Write(encode(map[string]interface{}{
+		"node_count":  nodeCount,
+		"record_size": 24,
+		"ip_version":  4,
+	}))
+	return buf.Bytes()
+}
+
+func TestMaxMind(t *testing.T) {
+	db, err := NewMaxMind(buildMaxMind(map[string]interface{}{
+		"city":         map[string]interface{}{"names": map[string]interface{}{"en": "Tokyo"}},
+		"continent":    map[string]interface{}{"code": "AS"},
+		"country":      map[string]interface{}{"iso_code": "JP", "names": map[string]interface{}{"en": "Japan"}},
+		"postal":       map[string]interface{}{"code": "100-0001"},
+		"subdivisions": []interface{}{map[string]interface{}{"iso_code": "13"}},
+		"location": map[string]interface{}{
+			"latitude":   35.6893,
+			"longitude":  139.6899,
+			"metro_code": 100,
+		},
+	}))
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+		return
+	}
+
+	loc, err := db.Lookup(net.ParseIP("192.0.2.1"))
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+		return
+	}
+	expect := &Location{
+		City:          "Tokyo",
+		ContinentCode: "AS",
+		CountryCode:   "JP",
+		CountryName:   "Japan",
+		PostalCode:    "100-0001",
+		Region:        "13",
+		Latitude:      35.6893,
+		Longitude:     139.6899,
+		MetroCode:     100,
+	}
+	if diff := cmp.Diff(expect, loc); diff != "" {
+		t.Errorf("Location mismatch, diff=%s", diff)
+	}
+
+	loc, err = db.Lookup(net.ParseIP("10.0.0.1"))
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+		return
+	}
+	if loc != nil {
+		t.Errorf("Expect location is not found, got %v", loc)
+	}
+}
+
+func TestMaxMindCorruptedSearchTree(t *testing.T) {
+	buf := buildMaxMind(map[string]interface{}{})
+	db, err := NewMaxMind(buf)
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+		return
+	}
+	// Search tree claims more nodes than the file has
+	db.nodeCount = uint(len(buf))
+	if _, err := db.Lookup(net.ParseIP("192.0.2.1")); err == nil {
+		t.Errorf("Expected error, got nil")
+	}
+}
+
+func TestMaxMindCorruptedDataSection(t *testing.T) {
+	nested := encode("foo")
+	for i := 0; i < maxDataDepth; i++ {
+		nested = append(encodeControl(typeArray, 1), nested...)
+	}
+
+	tests := []struct {
+		name string
+		buf  []byte
+	}{
+		{name: "pointer to pointer", buf: []byte{typePointer << 5, 0x02, typePointer << 5, 0x00}},
+		{name: "pointer to itself", buf: []byte{typePointer << 5, 0x00}},
+		{name: "too deeply nested", buf: nested},
+		{name: "map size exceeds the data section", buf: []byte{typeMap<<5 | 31, 0xff, 0xff, 0xff}},
+		{name: "array size exceeds the data section", buf: append(encodeControl(typeArray, 28), encode("foo")...)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := (&decoder{buf: tt.buf}).decode(0); err == nil {
+				t.Errorf("Expected error, got nil")
+			}
+		})
+	}
+}
+
+func TestUtcOffset(t *testing.T) {
+	tests := []struct {
+		name   string
+		tz     string
+		now    time.Time
+		expect int64
+	}{
+		{name: "positive offset", tz: "Asia/Tokyo", now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), expect: 900},
+		{name: "negative offset", tz: "America/New_York", now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), expect: -500},
+		{name: "daylight saving time", tz: "America/New_York", now: time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC), expect: -400},
+		{name: "half hour offset", tz: "Asia/Kolkata", now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), expect: 530},
+		{name: "unknown time zone", tz: "Unknown/Zone", now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), expect: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if offset := UtcOffset(tt.tz, tt.now); offset != tt.expect {
+				t.Errorf("Expected %d, got %d", tt.expect, offset)
+			}
+		})
+	}
+}
+
+func TestOverrides(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "geo.yaml")
+	content := `
+"192.0.2.0/24":
+  country_code: JP
+"192.0.2.1":
+  country_code: US
+"*":
+  country_code: GB
+`
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Errorf("Unexpected error: %s", err)
+		return
+	}
+	db, err := New(path, "")
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+		return
+	}
+
+	tests := map[string]string{
+		"192.0.2.1":   "US",
+		"192.0.2.100": "JP",
+		"10.0.0.1":    "GB",
+	}
+	for ip, expect := range tests {
+		loc, err := db.Lookup(net.ParseIP(ip))
+		if err != nil {
+			t.Errorf("Unexpected error: %s", err)
+			continue
+		}
+		if loc == nil || loc.CountryCode != expect {
+			t.Errorf("Country code mismatch for %s, expect=%s, got=%v", ip, expect, loc)
+		}
+	}
+}
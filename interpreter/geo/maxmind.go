@@ -0,0 +1,414 @@
+package geo
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"net"
+	"os"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// MaxMind DB has the metadata after this marker at the end of the file
+// see: https://maxmind.github.io/MaxMind-DB/
+var metadataMarker = []byte("\xab\xcd\xefMaxMind.com")
+
+// Data section starts after 16 bytes separator of the search tree
+const dataSectionSeparator = 16
+
+// MaxMind is the reader of MaxMind DB file like GeoLite2-City
+type MaxMind struct {
+	buf        []byte
+	data       []byte
+	nodeCount  uint
+	recordSize uint
+	ipVersion  uint
+	ipv4Start  uint
+}
+
+// OpenMaxMind reads MaxMind DB file
+func OpenMaxMind(path string) (*MaxMind, error) {
+	buf, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return NewMaxMind(buf)
+}
+
+// NewMaxMind returns the reader of MaxMind DB content
+func NewMaxMind(buf []byte) (*MaxMind, error) {
+	idx := bytes.LastIndex(buf, metadataMarker)
+	if idx == -1 {
+		return nil, errors.New("Invalid MaxMind DB: metadata is not found")
+	}
+	v, _, err := (&decoder{buf: buf[idx+len(metadataMarker):]}).decode(0)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	meta, ok := v.(map[string]interface{})
+	if !ok {
+		return nil, errors.New("Invalid MaxMind DB: metadata is not a map")
+	}
+
+	m := &MaxMind{
+		buf:        buf,
+		nodeCount:  uint(toUint(meta["node_count"])),
+		recordSize: uint(toUint(meta["record_size"])),
+		ipVersion:  uint(toUint(meta["ip_version"])),
+	}
+	switch m.recordSize {
+	case 24, 28, 32:
+	default:
+		return nil, errors.Errorf("Invalid MaxMind DB: unsupported record size %d", m.recordSize)
+	}
+	treeSize := m.nodeCount * m.recordSize / 4
+	if treeSize+dataSectionSeparator > uint(idx) {
+		return nil, errors.New("Invalid MaxMind DB: search tree exceeds the file")
+	}
+	m.data = buf[treeSize+dataSectionSeparator : idx]
+
+	// IPv4 addresses are stored in ::/96 subtree of IPv6 database
+	if m.ipVersion == 6 {
+		for i := 0; i < 96 && m.ipv4Start < m.nodeCount; i++ {
+			if m.ipv4Start, err = m.record(m.ipv4Start, 0); err != nil {
+				return nil, errors.WithStack(err)
+			}
+		}
+	}
+	return m, nil
+}
+
+// record returns the left (bit is 0) or right (bit is 1) record of the node
+func (m *MaxMind) record(node, bit uint) (uint, error) {
+	// Each node has two records
+	offset := node * m.recordSize / 4
+	if offset+m.recordSize/4 > uint(len(m.buf)) {
+		return 0, errors.Errorf("Invalid MaxMind DB: node %d exceeds the file", node)
+	}
+	b := m.buf[offset:]
+	switch m.recordSize {
+	case 24:
+		b = b[bit*3:]
+		return uint(b[0])<<16 | uint(b[1])<<8 | uint(b[2]), nil
+	case 28:
+		if bit == 0 {
+			return uint(b[3]&0xf0)<<20 | uint(b[0])<<16 | uint(b[1])<<8 | uint(b[2]), nil
+		}
+		return uint(b[3]&0x0f)<<24 | uint(b[4])<<16 | uint(b[5])<<8 | uint(b[6]), nil
+	default:
+		return uint(binary.BigEndian.Uint32(b[bit*4:])), nil
+	}
+}
+
+// Record returns the raw record of the IP address, nil is returned if the record is not found
+func (m *MaxMind) Record(ip net.IP) (interface{}, error) {
+	node := uint(0)
+	if v4 := ip.To4(); v4 != nil {
+		ip = v4
+		node = m.ipv4Start
+	} else if m.ipVersion == 4 {
+		return nil, nil
+	} else {
+		ip = ip.To16()
+	}
+
+	var err error
+	for i := 0; i < len(ip)*8 && node < m.nodeCount; i++ {
+		bit := (ip[i/8] >> (7 - uint(i%8))) & 1
+		if node, err = m.record(node, uint(bit)); err != nil {
+			return nil, errors.WithStack(err)
+		}
+	}
+	switch {
+	case node == m.nodeCount:
+		return nil, nil
+	case node < m.nodeCount:
+		return nil, errors.New("Invalid MaxMind DB: search tree does not end")
+	}
+	v, _, err := (&decoder{buf: m.data}).decode(node - m.nodeCount - dataSectionSeparator)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return v, nil
+}
+
+// Lookup returns the location of the IP address from City database record
+func (m *MaxMind) Lookup(ip net.IP) (*Location, error) {
+	v, err := m.Record(ip)
+	if err != nil || v == nil {
+		return nil, err
+	}
+	return &Location{
+		City:          toString(field(v, "city", "names", "en")),
+		ContinentCode: toString(field(v, "continent", "code")),
+		CountryCode:   toString(field(v, "country", "iso_code")),
+		CountryName:   toString(field(v, "country", "names", "en")),
+		PostalCode:    toString(field(v, "postal", "code")),
+		Region:        toString(field(v, "subdivisions", 0, "iso_code")),
+		Latitude:      toFloat(field(v, "location", "latitude")),
+		Longitude:     toFloat(field(v, "location", "longitude")),
+		MetroCode:     int64(toUint(field(v, "location", "metro_code"))),
+		TimeZone:      toString(field(v, "location", "time_zone")),
+	}, nil
+}
+
+// field returns the nested value of the record by map keys or array indexes
+func field(v interface{}, path ...interface{}) interface{} {
+	for _, p := range path {
+		switch key := p.(type) {
+		case string:
+			m, ok := v.(map[string]interface{})
+			if !ok {
+				return nil
+			}
+			v = m[key]
+		case int:
+			a, ok := v.([]interface{})
+			if !ok || key >= len(a) {
+				return nil
+			}
+			v = a[key]
+		}
+	}
+	return v
+}
+
+func toString(v interface{}) string {
+	s, _ := v.(string) // nolint:errcheck
+	return s
+}
+
+func toUint(v interface{}) uint64 {
+	u, _ := v.(uint64) // nolint:errcheck
+	return u
+}
+
+func toFloat(v interface{}) float64 {
+	switch f := v.(type) {
+	case float64:
+		return f
+	case float32:
+		return float64(f)
+	}
+	return 0
+}
+
+// UtcOffset returns UTC offset of the time zone at the time in Fastly format, e.g. 900 for +09:00
+func UtcOffset(tz string, now time.Time) int64 {
+	if tz == "" {
+		return 0
+	}
+	loc, err := time.LoadLocation(tz)
+	if err != nil {
+		return 0
+	}
+	_, sec := now.In(loc).Zone()
+	sign := int64(1)
+	if sec < 0 {
+		sign, sec = -1, -sec
+	}
+	return sign * int64(sec/3600*100+sec%3600/60)
+}
+
+// Maximum nesting depth of maps, arrays and pointers, same as libmaxminddb
+const maxDataDepth = 512
+
+// decoder decodes MaxMind DB data section
+// see: https://maxmind.github.io/MaxMind-DB/#output-data-section
+type decoder struct {
+	buf   []byte
+	depth int
+}
+
+const (
+	typeExtended = iota
+	typePointer
+	typeString
+	typeDouble
+	typeBytes
+	typeUint16
+	typeUint32
+	typeMap
+	typeInt32
+	typeUint64
+	typeUint128
+	typeArray
+	typeContainer
+	typeEndMarker
+	typeBoolean
+	typeFloat
+)
+
+func (d *decoder) bytes(offset, n uint) ([]byte, error) {
+	if offset+n > uint(len(d.buf)) {
+		return nil, errors.New("Invalid MaxMind DB: data exceeds the data section")
+	}
+	return d.buf[offset : offset+n], nil
+}
+
+// decode returns the value at the offset and the offset of the next value
+func (d *decoder) decode(offset uint) (interface{}, uint, error) {
+	b, err := d.bytes(offset, 1)
+	if err != nil {
+		return nil, 0, err
+	}
+	ctrl := b[0]
+	offset++
+
+	// Corrupted file may have cyclic pointers or deeply nested data, stop before exhausting the stack
+	d.depth++
+	defer func() { d.depth-- }()
+	if d.depth > maxDataDepth {
+		return nil, 0, errors.New("Invalid MaxMind DB: data is nested too deeply")
+	}
+
+	typ := uint(ctrl >> 5)
+	if typ == typePointer {
+		pointer, next, err := d.pointer(ctrl, offset)
+		if err != nil {
+			return nil, 0, err
+		}
+		// Pointer to pointer is not valid in the specification
+		if b, err = d.bytes(pointer, 1); err != nil {
+			return nil, 0, err
+		}
+		if b[0]>>5 == typePointer {
+			return nil, 0, errors.New("Invalid MaxMind DB: pointer points to another pointer")
+		}
+		v, _, err := d.decode(pointer)
+		return v, next, err
+	}
+	if typ == typeExtended {
+		if b, err = d.bytes(offset, 1); err != nil {
+			return nil, 0, err
+		}
+		typ = 7 + uint(b[0])
+		offset++
+	}
+
+	size, offset, err := d.size(ctrl, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	switch typ {
+	case typeMap, typeArray:
+		// Size is not trusted for allocation, each entry takes one byte at least
+		if size > uint(len(d.buf))-offset {
+			return nil, 0, errors.New("Invalid MaxMind DB: size exceeds the data section")
+		}
+	}
+
+	switch typ {
+	case typeMap:
+		m := make(map[string]interface{}, size)
+		for i := uint(0); i < size; i++ {
+			var key, val interface{}
+			if key, offset, err = d.decode(offset); err != nil {
+				return nil, 0, err
+			}
+			if val, offset, err = d.decode(offset); err != nil {
+				return nil, 0, err
+			}
+			m[toString(key)] = val
+		}
+		return m, offset, nil
+	case typeArray:
+		a := make([]interface{}, 0, size)
+		for i := uint(0); i < size; i++ {
+			var val interface{}
+			if val, offset, err = d.decode(offset); err != nil {
+				return nil, 0, err
+			}
+			a = append(a, val)
+		}
+		return a, offset, nil
+	case typeBoolean:
+		return size != 0, offset, nil
+	}
+
+	if b, err = d.bytes(offset, size); err != nil {
+		return nil, 0, err
+	}
+	next := offset + size
+	switch typ {
+	case typeString:
+		return string(b), next, nil
+	case typeBytes:
+		return append([]byte{}, b...), next, nil
+	case typeDouble:
+		if size != 8 {
+			return nil, 0, errors.New("Invalid MaxMind DB: double size must be 8")
+		}
+		return math.Float64frombits(binary.BigEndian.Uint64(b)), next, nil
+	case typeFloat:
+		if size != 4 {
+			return nil, 0, errors.New("Invalid MaxMind DB: float size must be 4")
+		}
+		return math.Float32frombits(binary.BigEndian.Uint32(b)), next, nil
+	case typeUint16, typeUint32, typeUint64, typeUint128:
+		// uint128 value is truncated to lower 64 bits
+		var u uint64
+		for _, v := range b {
+			u = u<<8 | uint64(v)
+		}
+		return u, next, nil
+	case typeInt32:
+		var u uint32
+		for _, v := range b {
+			u = u<<8 | uint32(v)
+		}
+		return int64(int32(u)), next, nil
+	}
+	return nil, 0, errors.Errorf("Invalid MaxMind DB: unsupported data type %d", typ)
+}
+
+// size returns the payload size of the control byte and the offset of the payload
+func (d *decoder) size(ctrl byte, offset uint) (uint, uint, error) {
+	size := uint(ctrl & 0x1f)
+	if size < 29 {
+		return size, offset, nil
+	}
+	n := size - 28
+	b, err := d.bytes(offset, n)
+	if err != nil {
+		return 0, 0, err
+	}
+	var v uint
+	for _, c := range b {
+		v = v<<8 | uint(c)
+	}
+	switch size {
+	case 29:
+		return 29 + v, offset + n, nil
+	case 30:
+		return 285 + v, offset + n, nil
+	default:
+		return 65821 + v, offset + n, nil
+	}
+}
+
+// pointer returns the offset which the pointer points to and the offset of the next value
+func (d *decoder) pointer(ctrl byte, offset uint) (uint, uint, error) {
+	n := uint((ctrl>>3)&0x03) + 1
+	b, err := d.bytes(offset, n)
+	if err != nil {
+		return 0, 0, err
+	}
+	var v uint
+	if n < 4 {
+		v = uint(ctrl & 0x07)
+	}
+	for _, c := range b {
+		v = v<<8 | uint(c)
+	}
+	switch n {
+	case 2:
+		v += 2048
+	case 3:
+		v += 526336
+	}
+	return v, offset + n, nil
+}
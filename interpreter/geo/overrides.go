@@ -0,0 +1,93 @@
+package geo
+
+import (
+	"encoding/json"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-yaml/yaml"
+	"github.com/pkg/errors"
+)
+
+// Key of the location which is used for IP addresses which do not match any networks
+const defaultNetwork = "*"
+
+// Overrides is the user specified locations keyed by IP address or CIDR network, for example:
+//
+//	"192.0.2.0/24":
+//	  city: Tokyo
+//	  country_code: JP
+//	"*":
+//	  country_code: US
+type Overrides struct {
+	networks  []*net.IPNet
+	locations []*Location
+	fallback  *Location
+}
+
+// LoadOverrides loads overrides from YAML or JSON file
+func LoadOverrides(path string) (*Overrides, error) {
+	buf, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	raw := make(map[string]*Location)
+	switch filepath.Ext(path) {
+	case ".json":
+		err = json.Unmarshal(buf, &raw)
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(buf, &raw)
+	default:
+		return nil, errors.Errorf("Unsupported geo overrides file %s, must be YAML or JSON", path)
+	}
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return NewOverrides(raw)
+}
+
+// NewOverrides returns overrides from locations keyed by IP address, CIDR network or "*"
+func NewOverrides(locations map[string]*Location) (*Overrides, error) {
+	o := &Overrides{}
+	for key, loc := range locations {
+		if key == defaultNetwork {
+			o.fallback = loc
+			continue
+		}
+		if !strings.Contains(key, "/") {
+			if strings.Contains(key, ":") {
+				key += "/128"
+			} else {
+				key += "/32"
+			}
+		}
+		_, network, err := net.ParseCIDR(key)
+		if err != nil {
+			return nil, errors.Errorf("Invalid network %s in geo overrides: %s", key, err)
+		}
+		o.networks = append(o.networks, network)
+		o.locations = append(o.locations, loc)
+	}
+	return o, nil
+}
+
+// Lookup returns the location of the most specific network which contains the IP address
+func (o *Overrides) Lookup(ip net.IP) (*Location, error) {
+	var found *Location
+	prefix := -1
+	for i, network := range o.networks {
+		if !network.Contains(ip) {
+			continue
+		}
+		if ones, _ := network.Mask.Size(); ones > prefix {
+			found, prefix = o.locations[i], ones
+		}
+	}
+	if found != nil {
+		return found, nil
+	}
+	return o.fallback, nil
+}
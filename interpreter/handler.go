@@ -1,6 +1,7 @@
 package interpreter
 
 import (
+	"io"
 	"net/http"
 
 	"github.com/pkg/errors"
@@ -9,7 +10,18 @@ import (
 )
 
 // Implements http.Handler
+// Interpreter holds the state of the processing request, so concurrent requests are processed by forked interpreters
+// which share the cache. Then concurrent misses for the same object are collapsed.
 func (i *Interpreter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !i.serving.CompareAndSwap(false, true) {
+		i.fork().serveHTTP(w, r)
+		return
+	}
+	defer i.serving.Store(false)
+	i.serveHTTP(w, r)
+}
+
+func (i *Interpreter) serveHTTP(w http.ResponseWriter, r *http.Request) {
 	i.Debugger.Message("Request Incoming =========>")
 	defer i.Debugger.Message("<========= Request finished")
 
@@ -17,9 +29,12 @@ func (i *Interpreter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
+	// The simulator responds the process result instead of the body, so the body is only counted
+	i.client = io.Discard
 
 	handleError := func(err error) {
 		// If debug is true, print with stacktrace
+		i.traceInclude(err)
 		i.process.Error = err
 		if re, ok := errors.Cause(err).(*exception.Exception); ok {
 			i.Debugger.Message(re.Error())
@@ -30,12 +45,26 @@ func (i *Interpreter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 
 	if err := i.ProcessRecv(); err != nil {
 		handleError(err)
+	} else if i.ctx.Upgrade {
+		// Upgraded connection is tunneled to the backend, vcl_log is executed after the connection is closed
+		if err := i.serveUpgrade(w); err != nil {
+			handleError(err)
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+		if i.ctx.Response != nil {
+			if err := i.ProcessLog(); err != nil {
+				handleError(err)
+			}
+		}
+		return
 	} else if err := limitations.CheckFastlyResponseLimit(i.ctx.Response); err != nil {
 		handleError(err)
 	}
 
 	i.process.Restarts = i.ctx.Restarts
 	i.process.Backend = i.ctx.Backend
+	i.process.BodyBytes = i.ctx.ResponseBodyBytesWritten
 	if i.process.Error != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 	} else {
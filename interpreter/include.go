@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/pkg/errors"
 	"github.com/ysugimoto/falco/ast"
 	"github.com/ysugimoto/falco/interpreter/exception"
 	ex "github.com/ysugimoto/falco/interpreter/exception"
@@ -11,6 +12,16 @@ import (
 	"github.com/ysugimoto/falco/parser"
 )
 
+// traceInclude fills the include chain of the runtime exception from the source map,
+// so that the exception raised in the included file could be traced back to the main VCL
+func (i *Interpreter) traceInclude(err error) {
+	re, ok := errors.Cause(err).(*exception.Exception)
+	if !ok || re.Token == nil {
+		return
+	}
+	re.IncludedFrom = i.sourceMap.Trace(*re.Token)[1:]
+}
+
 func (i *Interpreter) resolveIncludeStatement(statements []ast.Statement, isRoot bool) ([]ast.Statement, error) {
 	var resolved []ast.Statement
 	for _, stmt := range statements {
@@ -51,6 +62,7 @@ func (i *Interpreter) includeSnippet(include *ast.IncludeStatement, isRoot bool)
 	if !ok {
 		return nil, fmt.Errorf("Failed to include VCL snippets '%s'", include.Module.Value)
 	}
+	i.sourceMap.Add(include.Module.Value, include)
 	if isRoot {
 		return loadRootVCL(include.Module.Value, snip.Data)
 	}
@@ -62,6 +74,7 @@ func (i *Interpreter) includeFile(include *ast.IncludeStatement, isRoot bool) ([
 	if err != nil {
 		return nil, fmt.Errorf("Failed to include VCL module '%s'", include.Module.Value)
 	}
+	i.sourceMap.Add(module.Name, include)
 
 	if isRoot {
 		return loadRootVCL(module.Name, module.Data)
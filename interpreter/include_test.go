@@ -0,0 +1,80 @@
+package interpreter
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/ysugimoto/falco/ast"
+	"github.com/ysugimoto/falco/interpreter/context"
+	"github.com/ysugimoto/falco/interpreter/exception"
+	"github.com/ysugimoto/falco/resolver"
+)
+
+// includeResolver resolves included modules from the map
+type includeResolver struct {
+	main    string
+	modules map[string]string
+}
+
+func (r *includeResolver) MainVCL() (*resolver.VCL, error) {
+	return &resolver.VCL{Name: "main.vcl", Data: r.main}, nil
+}
+
+func (r *includeResolver) Resolve(stmt *ast.IncludeStatement) (*resolver.VCL, error) {
+	name := stmt.Module.Value + ".vcl"
+	data, ok := r.modules[name]
+	if !ok {
+		return nil, errors.Errorf("Module %s is not found", name)
+	}
+	return &resolver.VCL{Name: name, Data: data}, nil
+}
+
+func (r *includeResolver) Name() string { return "" }
+
+func TestRuntimeExceptionInIncludedFile(t *testing.T) {
+	ip := New(context.WithResolver(&includeResolver{
+		main: `
+backend F_origin {
+  .host = "example.com";
+}
+include "recv";`,
+		modules: map[string]string{
+			"recv.vcl": `
+include "helper";
+sub vcl_recv {
+  #FASTLY RECV
+  call helper;
+}`,
+			"helper.vcl": `
+sub helper {
+  synthetic "not allowed in RECV scope";
+}`,
+		},
+	}))
+	if err := ip.TestProcessInit(httptest.NewRequest("GET", "http://localhost", nil)); err != nil {
+		t.Fatalf("Unexpected initialize error: %s", err)
+	}
+
+	err := ip.ProcessTestSubroutine(context.RecvScope, ip.ctx.Subroutines["vcl_recv"])
+	re, ok := errors.Cause(err).(*exception.Exception)
+	if !ok {
+		t.Fatalf("Expected runtime exception but got %v", err)
+	}
+	if re.Token.File != "helper.vcl" {
+		t.Errorf("Exception should be raised in helper.vcl, got %s", re.Token.File)
+	}
+	expects := []string{"recv.vcl:2:1", "main.vcl:5:1"}
+	if len(re.IncludedFrom) != len(expects) {
+		t.Fatalf("Unexpected include chain: %v", re.IncludedFrom)
+	}
+	for i, loc := range re.IncludedFrom {
+		if loc.String() != expects[i] {
+			t.Errorf("Include chain[%d] expects %s, got %s", i, expects[i], loc.String())
+		}
+	}
+	if !strings.Contains(re.Error(), "included from recv.vcl:2:1") {
+		t.Errorf("Include chain should be reported: %s", re.Error())
+	}
+}
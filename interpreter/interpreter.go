@@ -1,10 +1,12 @@
 package interpreter
 
 import (
+	"bytes"
 	"fmt"
 	"io"
 	"net/http"
 	"strings"
+	"sync"
 	"sync/atomic"
 	"time"
 
@@ -27,21 +29,57 @@ type Interpreter struct {
 
 	options []context.Option
 
-	ctx      *context.Context
-	process  *process.Process
-	cache    *cache.Cache
-	Debugger Debugger
+	ctx       *context.Context
+	process   *process.Process
+	cache     *cache.Cache
+	shields   *shieldPOPs
+	sourceMap *ast.SourceMap
+	Debugger  Debugger
+
+	// rings of chash directors, shared between forked interpreters
+	chashRings *sync.Map
+	// health probes of backends, shared between forked interpreters
+	probes *backendProbes
+
+	// nest level of ESI sub-request, zero for the client request
+	esiDepth int
+	// releases requests which wait for the fetch of this request, nil if this request is not the leader
+	collapse func()
+	// true while the interpreter processes the request in ServeHTTP
+	serving atomic.Bool
+	// receives the response body before vcl_log, nil for sub-requests like ESI which return the response to the caller
+	client io.Writer
 }
 
 func New(options ...context.Option) *Interpreter {
 	return &Interpreter{
-		options:   options,
-		cache:     cache.New(),
-		localVars: variable.LocalVariables{},
-		Debugger:  DefaultDebugger{},
+		options:    options,
+		cache:      cache.New(),
+		shields:    newShieldPOPs(),
+		chashRings: &sync.Map{},
+		probes:     newBackendProbes(),
+		sourceMap:  ast.NewSourceMap(),
+		localVars:  variable.LocalVariables{},
+		Debugger:   DefaultDebugger{},
 	}
 }
 
+// fork returns a new interpreter which shares the cache with this interpreter
+func (i *Interpreter) fork() *Interpreter {
+	ip := New(i.options...)
+	ip.cache = i.cache
+	ip.shields = i.shields
+	ip.chashRings = i.chashRings
+	ip.probes = i.probes
+	ip.Debugger = i.Debugger
+	return ip
+}
+
+// SourceMap returns the map to trace flattened statements back to the include chain
+func (i *Interpreter) SourceMap() *ast.SourceMap {
+	return i.sourceMap
+}
+
 func (i *Interpreter) SetScope(scope context.Scope) {
 	i.ctx.Scope = scope
 	switch scope {
@@ -67,12 +105,23 @@ func (i *Interpreter) SetScope(scope context.Scope) {
 }
 
 func (i *Interpreter) restart() error {
+	// Restarted request looks up again, must not wait for itself
+	i.releaseCollapse()
 	i.ctx.Restarts++
 	i.Debugger.Message(fmt.Sprintf("Restarted (%d) time", i.ctx.Restarts))
+	// Streamed body which is not delivered must be released
+	if i.isStreaming() {
+		i.ctx.BackendResponse.Body.Close()
+	}
 	i.ctx.BackendRequest = nil
 	i.ctx.BackendResponse = nil
 	i.ctx.Object = nil
 	i.ctx.Response = nil
+	i.ctx.CacheHitItem = nil
+	i.ctx.StaleItem = nil
+	i.ctx.Stale.Value = false
+	i.ctx.StaleIsError.Value = false
+	i.ctx.StaleIsRevalidating.Value = false
 
 	if err := i.ProcessRecv(); err != nil {
 		return err
@@ -115,9 +164,11 @@ func (i *Interpreter) ProcessInit(r *http.Request) error {
 			vcl.Statements = append(s.Statements, vcl.Statements...)
 		}
 	}
-	ctx.RequestStartTime = time.Now()
+	ctx.RequestStartTime = ctx.Now()
 	i.ctx = ctx
 	i.ctx.Request = r
+	i.ctx.Server = serverConfig(i.ctx.Server, r)
+	i.ctx.Client = clientConfig(i.ctx.Client, r)
 
 	// OriginalHost value may be overridden. If not empty, set the request value
 	if i.ctx.OriginalHost == "" {
@@ -221,16 +272,20 @@ func (i *Interpreter) ProcessBackends(statements []ast.Statement) error {
 			continue
 		}
 		i.Debugger.Run(stmt)
-		h := &atomic.Bool{}
-		h.Store(true)
+		if _, ok := i.ctx.Backends[t.Name.Value]; ok {
+			return exception.Runtime(&t.Token, "Backend %s is duplicated", t.Name.Value)
+		}
+		backend := &value.Backend{Value: t, Literal: true}
+		h, err := i.backendHealth(backend)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		backend.Healthy = h
 		// Determine default backend
 		if i.ctx.Backend == nil {
 			i.ctx.Backend = &value.Backend{Value: t, Literal: true, Healthy: h}
 		}
-		if _, ok := i.ctx.Backends[t.Name.Value]; ok {
-			return exception.Runtime(&t.Token, "Backend %s is duplicated", t.Name.Value)
-		}
-		i.ctx.Backends[t.Name.Value] = &value.Backend{Value: t, Literal: true, Healthy: h}
+		i.ctx.Backends[t.Name.Value] = backend
 	}
 	return nil
 }
@@ -267,20 +322,58 @@ func (i *Interpreter) ProcessRecv() error {
 		err = i.ProcessError()
 	case RESTART:
 		err = i.restart()
+	case UPGRADE:
+		i.Debugger.Message(fmt.Sprintf("Move state: %s -> UPGRADE", i.ctx.Scope))
+		err = i.ProcessUpgrade()
 	case LOOKUP, NONE:
 		i.Debugger.Message(fmt.Sprintf("Move state: %s -> HASH", i.ctx.Scope))
 		if err = i.ProcessHash(); err != nil {
 			return errors.WithStack(err)
 		}
-		if v := i.cache.Get(i.ctx.RequestHash.Value); v != nil {
+		if isPurgeRequest(i.ctx.Request) {
+			i.Debugger.Message(fmt.Sprintf("Move state: %s -> PURGE", i.ctx.Scope))
+			i.purge()
+			return nil
+		}
+		// Outer request of segmented caching fetches the object by inner requests for each block
+		if i.ctx.EnableSegmentedCaching.Value && i.ctx.SegmentedCaching == nil && i.ctx.Request.Method == http.MethodGet {
+			i.Debugger.Message(fmt.Sprintf("Move state: %s -> SEGMENTED CACHING", i.ctx.Scope))
+			err = i.processSegmentedCaching()
+			break
+		}
+		v, freshness, waited := i.lookup()
+		defer i.releaseCollapse()
+
+		// Requests which waited for the collapsed fetch are marked as suffix
+		var wait string
+		if waited {
+			wait = "-WAIT"
+		}
+		switch {
+		case freshness == cache.Fresh && v.HitForPass:
+			// Hit-for-pass object, the request is passed until the object expires
+			i.ctx.State = "HITPASS" + wait
+			i.Debugger.Message(fmt.Sprintf("Move state: %s -> PASS", i.ctx.Scope))
+			err = i.ProcessPass()
+		case freshness == cache.Fresh, freshness == cache.StaleWhileRevalidate:
 			i.process.Cached = true
-			i.ctx.State = "HIT"
+			i.ctx.State = "HIT" + wait
+			if freshness == cache.StaleWhileRevalidate {
+				i.ctx.State = "HIT-STALE"
+				i.ctx.Stale.Value = true
+				i.ctx.StaleIsRevalidating.Value = true
+			}
 			i.ctx.CacheHitItem = v
-			i.ctx.Object = i.cloneResponse(v.Response)
+			i.ctx.TriggerESI = v.ESI
+			i.ctx.Object = v.NewResponse()
 			i.Debugger.Message(fmt.Sprintf("Move state: %s -> HIT", i.ctx.Scope))
 			err = i.ProcessHit()
-		} else {
-			i.ctx.State = "MISS"
+		default:
+			// Stale object is kept to be delivered by return(deliver_stale)
+			if freshness == cache.Stale && !v.HitForPass {
+				i.ctx.StaleItem = v
+			}
+			i.ctx.State = "MISS" + wait
 			i.Debugger.Message(fmt.Sprintf("Move state: %s -> MISS", i.ctx.Scope))
 			err = i.ProcessMiss()
 		}
@@ -300,6 +393,40 @@ func (i *Interpreter) ProcessRecv() error {
 	return nil
 }
 
+// lookup finds the cached object for the request, and returns true as the last value if the request waited for the other request.
+// Concurrent misses for the same object are collapsed: the first request becomes the leader which fetches the object,
+// and following requests wait for the fetch then look up again, so that they receive the cached object or hit-for-pass object.
+// req.hash_ignore_busy disables collapsing.
+func (i *Interpreter) lookup() (*cache.CacheItem, cache.Freshness, bool) {
+	if i.ctx.HashAlwaysMiss.Value {
+		return nil, cache.Miss, false
+	}
+
+	var waited bool
+	for {
+		v, freshness := i.cache.Lookup(i.ctx.RequestHash.Value, i.ctx.Request, i.ctx.Now())
+		if freshness == cache.Fresh || freshness == cache.StaleWhileRevalidate || i.ctx.HashIgnoreBusy.Value {
+			return v, freshness, waited
+		}
+		release, wait := i.cache.Busy(i.ctx.RequestHash.Value)
+		if wait == nil {
+			i.collapse = release
+			return v, freshness, waited
+		}
+		i.Debugger.Message("Waiting for the request which fetches the same object")
+		<-wait
+		waited = true
+	}
+}
+
+// releaseCollapse wakes up requests which wait for the fetch of this request
+func (i *Interpreter) releaseCollapse() {
+	if i.collapse != nil {
+		i.collapse()
+		i.collapse = nil
+	}
+}
+
 func (i *Interpreter) ProcessHash() error {
 	i.SetScope(context.HashScope)
 
@@ -323,6 +450,10 @@ func (i *Interpreter) ProcessHash() error {
 			)
 		}
 	}
+	// Each block of segmented caching is cached as the individual object
+	if sc := i.ctx.SegmentedCaching; sc != nil && sc.IsInner {
+		i.ctx.RequestHash.Value += fmt.Sprintf("#segment:%d:%d", sc.BlockSize, sc.BlockNumber)
+	}
 	return nil
 }
 
@@ -342,6 +473,11 @@ func (i *Interpreter) ProcessMiss() error {
 	if err != nil {
 		return errors.WithStack(err)
 	}
+	// The whole object is fetched when Fastly processes the range by itself
+	if i.isRangeHandled() {
+		i.ctx.BackendRequest.Header.Del("Range")
+		i.ctx.BackendRequest.Header.Del("If-Range")
+	}
 
 	// Simulate Fastly statement lifecycle
 	// see: https://developer.fastly.com/learning/vcl/using/#the-vcl-request-lifecycle
@@ -360,8 +496,10 @@ func (i *Interpreter) ProcessMiss() error {
 	switch state {
 	case DELIVER_STALE:
 		i.Debugger.Message(fmt.Sprintf("Move state: %s -> DELIVER", i.ctx.Scope))
-		err = i.ProcessDeliver()
+		err = i.deliverStale(false)
 	case PASS:
+		// Passed request does not collapse, waiting requests fetch by themselves
+		i.releaseCollapse()
 		i.Debugger.Message(fmt.Sprintf("Move state: %s -> PASS", i.ctx.Scope))
 		err = i.ProcessPass()
 	case ERROR:
@@ -387,6 +525,11 @@ func (i *Interpreter) ProcessMiss() error {
 func (i *Interpreter) ProcessHit() error {
 	i.SetScope(context.HitScope)
 
+	// Expose remaining lifetime of the cache object as obj.ttl and obj.grace
+	ttl, grace := i.ctx.CacheHitItem.Lifetime(i.ctx.Now())
+	i.ctx.ObjectTTL = &value.RTime{Value: ttl}
+	i.ctx.ObjectGrace = &value.RTime{Value: grace}
+
 	// Simulate Fastly statement lifecycle
 	// see: https://developer.fastly.com/learning/vcl/using/#the-vcl-request-lifecycle
 	var err error
@@ -402,9 +545,9 @@ func (i *Interpreter) ProcessHit() error {
 		}
 	}
 
-	// Update cache lifetime because cache object statue may be changed by setting obj.ttl
-	if i.ctx.ObjectTTL.Value > 0 {
-		i.ctx.CacheHitItem.Update(i.ctx.ObjectTTL.Value)
+	// Update cache lifetime because cache object statue may be changed by setting obj.ttl and obj.grace
+	if i.ctx.ObjectTTL.Value != ttl || i.ctx.ObjectGrace.Value != grace {
+		i.ctx.CacheHitItem.Update(i.ctx.Now(), i.ctx.ObjectTTL.Value, i.ctx.ObjectGrace.Value)
 	}
 
 	switch state {
@@ -436,6 +579,9 @@ func (i *Interpreter) ProcessHit() error {
 
 func (i *Interpreter) ProcessPass() error {
 	i.SetScope(context.PassScope)
+	if !strings.HasPrefix(i.ctx.State, "HITPASS") {
+		i.ctx.State = "PASS"
+	}
 
 	if i.ctx.Backend == nil {
 		return exception.Runtime(nil, "No backend determined in PASS")
@@ -450,6 +596,11 @@ func (i *Interpreter) ProcessPass() error {
 	if err != nil {
 		return errors.WithStack(err)
 	}
+	// The whole object is fetched when Fastly processes the range by itself
+	if i.isRangeHandled() {
+		i.ctx.BackendRequest.Header.Del("Range")
+		i.ctx.BackendRequest.Header.Del("If-Range")
+	}
 
 	// Simulate Fastly statement lifecycle
 	// see: https://developer.fastly.com/learning/vcl/using/#the-vcl-request-lifecycle
@@ -498,39 +649,27 @@ func (i *Interpreter) ProcessFetch() error {
 	var err error
 	i.ctx.BackendResponse, err = i.sendBackendRequest(i.ctx.Backend)
 	if err != nil {
-		return errors.WithStack(err)
+		return i.processBackendFailure(err)
 	}
 
 	// Mark request process has ended
-	i.ctx.RequestEndTime = time.Now()
+	i.ctx.RequestEndTime = i.ctx.Now()
 
 	// Set cacheable strategy
 	isCacheable := cache.IsCacheableStatusCode(i.ctx.BackendResponse.StatusCode)
+	// Partial content of the block is cached for segmented caching
+	if sc := i.ctx.SegmentedCaching; sc != nil && sc.IsInner && i.ctx.BackendResponse.StatusCode == http.StatusPartialContent {
+		isCacheable = true
+	}
 	i.ctx.BackendResponseCacheable = &value.Boolean{Value: isCacheable}
 	if isCacheable {
 		i.ctx.BackendResponseTTL = &value.RTime{
 			Value: i.determineCacheTTL(i.ctx.BackendResponse),
 		}
 	}
-	// TODO: consider stale-white-revalidate and stale-if-error TTL
-
-	// Consider cache, create client response from backend response
-	defer func() {
-		resp := i.cloneResponse(i.ctx.BackendResponse)
-		// Note: compare BackendResponseCacheable value
-		// because this value will be changed by user in vcl_fetch directive
-		if i.ctx.BackendResponseCacheable.Value {
-			if i.ctx.BackendResponseTTL.Value.Seconds() > 0 {
-				now := time.Now()
-				i.cache.Set(i.ctx.RequestHash.String(), &cache.CacheItem{
-					Response:  resp,
-					Expires:   now.Add(i.ctx.BackendResponseTTL.Value),
-					EntryTime: now,
-				})
-			}
-		}
-		i.ctx.Response = resp
-	}()
+	swr, sie := i.determineStaleTTL(i.ctx.BackendResponse)
+	i.ctx.BackendResponseStaleWhileRevalidate = &value.RTime{Value: swr}
+	i.ctx.BackendResponseStaleIfError = &value.RTime{Value: sie}
 
 	// Simulate Fastly statement lifecycle
 	// see: https://developer.fastly.com/learning/vcl/using/#the-vcl-request-lifecycle
@@ -546,10 +685,33 @@ func (i *Interpreter) ProcessFetch() error {
 		}
 	}
 
+	// The object is buffered entirely before delivering unless beresp.do_stream is enabled
+	if !i.isStreaming() {
+		if err := bufferBackendResponse(i.ctx.BackendResponse); err != nil {
+			i.ctx.BackendResponse = nil
+			return i.processBackendFailure(err)
+		}
+	}
+
+	// Compress the object before storing it to the cache
+	if state == DELIVER || state == PASS {
+		if err := i.compressBackendResponse(); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+
+	// Store the object to the cache, note that values could be changed by user in vcl_fetch directive
+	if err := i.storeCache(state); err != nil {
+		return errors.WithStack(err)
+	}
+
 	switch state {
-	case DELIVER, DELIVER_STALE, PASS:
+	case DELIVER, PASS:
 		i.Debugger.Message(fmt.Sprintf("Move state: %s -> DELIVER", i.ctx.Scope))
 		err = i.ProcessDeliver()
+	case DELIVER_STALE:
+		i.Debugger.Message(fmt.Sprintf("Move state: %s -> DELIVER", i.ctx.Scope))
+		err = i.deliverStale(i.ctx.BackendResponse.StatusCode >= 500)
 	case ERROR:
 		i.Debugger.Message(fmt.Sprintf("Move state: %s -> ERROR", i.ctx.Scope))
 		err = i.ProcessError()
@@ -569,6 +731,26 @@ func (i *Interpreter) ProcessFetch() error {
 	return nil
 }
 
+// processBackendFailure moves to vcl_error with 503 status when the backend could not respond,
+// then the stale object could be delivered by return(deliver_stale)
+func (i *Interpreter) processBackendFailure(err error) error {
+	var failure *backendFailure
+	if !errors.As(err, &failure) {
+		return errors.WithStack(err)
+	}
+	i.Debugger.Message(failure.Error())
+	i.ctx.FastlyError = &value.String{Value: failure.code}
+	i.ctx.ObjectStatus = &value.Integer{Value: http.StatusServiceUnavailable}
+	i.ctx.ObjectResponse = &value.String{Value: failure.response}
+	i.Debugger.Message(fmt.Sprintf("Move state: %s -> ERROR", i.ctx.Scope))
+	return i.ProcessError()
+}
+
+// isStreaming reports whether the backend response is streamed to the client by beresp.do_stream
+func (i *Interpreter) isStreaming() bool {
+	return i.ctx.BackendResponse != nil && i.ctx.BackendResponseDoStream.Value
+}
+
 func (i *Interpreter) ProcessError() error {
 	i.SetScope(context.ErrorScope)
 
@@ -617,6 +799,9 @@ func (i *Interpreter) ProcessError() error {
 	case DELIVER:
 		i.Debugger.Message(fmt.Sprintf("Move state: %s -> DELIVER", i.ctx.Scope))
 		err = i.ProcessDeliver()
+	case DELIVER_STALE:
+		i.Debugger.Message(fmt.Sprintf("Move state: %s -> DELIVER", i.ctx.Scope))
+		err = i.deliverStale(true)
 	case RESTART:
 		err = i.restart()
 	default:
@@ -637,12 +822,16 @@ func (i *Interpreter) ProcessDeliver() error {
 	i.SetScope(context.DeliverScope)
 
 	if i.ctx.Response == nil {
-		if i.ctx.BackendResponse != nil {
+		if i.isStreaming() {
+			i.ctx.Response = i.streamResponse(i.ctx.BackendResponse)
+		} else if i.ctx.BackendResponse != nil {
 			i.ctx.Response = i.cloneResponse(i.ctx.BackendResponse)
 		} else if i.ctx.Object != nil {
 			i.ctx.Response = i.cloneResponse(i.ctx.Object)
 		}
 	}
+	// The client receives the first byte when the response starts to be delivered
+	i.process.MarkFirstByte()
 
 	// Simulate Fastly statement lifecycle
 	// see: https://developer.fastly.com/learning/vcl/using/#the-vcl-request-lifecycle
@@ -663,21 +852,37 @@ func (i *Interpreter) ProcessDeliver() error {
 	case RESTART:
 		err = i.restart()
 	case LOG, DELIVER:
+		encoding := strings.ToLower(i.ctx.Response.Header.Get("Content-Encoding"))
 		// When ESI is triggered in FETCH directive, execute ESI
-		if i.ctx.TriggerESI {
+		if i.ctx.TriggerESI || i.ctx.BackendResponseDoESI.Value {
 			if err := i.executeESI(); err != nil {
 				return errors.WithStack(err)
 			}
 		}
+		if err := i.negotiateEncoding(encoding); err != nil {
+			return errors.WithStack(err)
+		}
+		if err := i.processRange(); err != nil {
+			return errors.WithStack(err)
+		}
 
-		// Add Fastly related server info but values are falco's one
-		i.ctx.Response.Header.Set("X-Served-By", cache.LocalDatacenterString)
-		i.ctx.Response.Header.Set("X-Cache", i.ctx.State)
+		// Add Fastly related server info but values are falco's one.
+		// Values are appended to the ones which the shield POP has set like Fastly does, e.g. "MISS, HIT"
+		servedBy := cache.LocalDatacenterString
+		if i.ctx.ShieldPOP != "" {
+			servedBy = i.ctx.Identity()
+		}
+		xCache := "MISS"
+		if strings.HasPrefix(i.ctx.State, "HIT") && !strings.HasPrefix(i.ctx.State, "HITPASS") {
+			xCache = "HIT"
+		}
+		appendHeaderValue(i.ctx.Response.Header, "X-Served-By", servedBy)
+		appendHeaderValue(i.ctx.Response.Header, "X-Cache", xCache)
 
 		// Additionally set cache related headers
 		if i.ctx.CacheHitItem != nil {
-			i.ctx.Response.Header.Set("X-Cache-Hits", fmt.Sprint(i.ctx.CacheHitItem.Hits))
-			i.ctx.Response.Header.Set("Age", fmt.Sprintf("%.0f", time.Since(i.ctx.CacheHitItem.EntryTime).Seconds()))
+			i.ctx.Response.Header.Set("X-Cache-Hits", fmt.Sprint(i.ctx.CacheHitItem.HitCount()))
+			i.ctx.Response.Header.Set("Age", fmt.Sprintf("%.0f", i.ctx.Since(i.ctx.CacheHitItem.EntryTime).Seconds()))
 		} else {
 			i.ctx.Response.Header.Set("X-Cache-Hits", "0")
 		}
@@ -688,7 +893,7 @@ func (i *Interpreter) ProcessDeliver() error {
 				fmt.Sprintf("(D %s 0) (F %s 0)", cache.LocalDatacenterString, cache.LocalDatacenterString),
 			)
 			cacheHit := "M"
-			if i.ctx.State == "HIT" {
+			if xCache == "HIT" {
 				cacheHit = "H"
 			}
 			i.ctx.Response.Header.Set(
@@ -696,7 +901,12 @@ func (i *Interpreter) ProcessDeliver() error {
 				fmt.Sprintf("(%s %s %.3f %.3f %d)", cacheHit, cache.LocalDatacenterString, 0.000, 0.000, 0),
 			)
 		}
+		// h3.alt_svc() advertises HTTP/3 to the client which connects over TLS
+		if i.ctx.H3AltSvc && i.ctx.Request.TLS != nil && i.ctx.Request.ProtoMajor < 3 {
+			i.ctx.Response.Header.Set("Alt-Svc", `h3=":443";ma=86400,h3-29=":443";ma=86400,h3-27=":443";ma=86400`)
+		}
 
+		i.writeResponseBody()
 		i.Debugger.Message(fmt.Sprintf("Move state: %s -> LOG", i.ctx.Scope))
 		err = i.ProcessLog()
 	default:
@@ -713,6 +923,24 @@ func (i *Interpreter) ProcessDeliver() error {
 	return nil
 }
 
+// writeResponseBody writes the response body to the client through the counting writer,
+// so that vcl_log could refer the delivered size without buffering the body
+func (i *Interpreter) writeResponseBody() {
+	if i.client == nil || i.ctx.Response == nil || i.ctx.Response.Body == nil {
+		return
+	}
+	w := &countingWriter{Writer: i.client}
+	_, err := io.Copy(w, i.ctx.Response.Body)
+	i.ctx.Response.Body.Close()
+	i.ctx.Response.Body = http.NoBody
+	i.ctx.ResponseBodyBytesWritten = w.n
+	// The response is truncated when the origin fails while streaming
+	if err != nil {
+		i.Debugger.Message(fmt.Sprintf("Response body is truncated: %s", err))
+		i.process.Error = err
+	}
+}
+
 func (i *Interpreter) ProcessLog() error {
 	i.SetScope(context.LogScope)
 
@@ -736,6 +964,93 @@ func (i *Interpreter) ProcessLog() error {
 	return nil
 }
 
+// storeCache stores the backend response to the cache following the state which vcl_fetch returns.
+// Passed requests are not cached, and return(pass) in vcl_fetch creates hit-for-pass object
+// so that following requests are passed until the object expires.
+func (i *Interpreter) storeCache(state State) error {
+	// Waiting requests look up the object after it is stored
+	defer i.releaseCollapse()
+
+	if strings.HasPrefix(i.ctx.State, "PASS") || strings.HasPrefix(i.ctx.State, "HITPASS") {
+		return nil
+	}
+
+	ttl := i.ctx.BackendResponseTTL.Value
+	switch state {
+	case DELIVER:
+		if !i.ctx.BackendResponseCacheable.Value || ttl <= 0 {
+			return nil
+		}
+	case PASS:
+		if ttl <= 0 {
+			return nil
+		}
+	default:
+		// Response is not cached on error, restart and deliver_stale
+		return nil
+	}
+
+	now := i.ctx.Now()
+	item := &cache.CacheItem{
+		Expires:              now.Add(ttl),
+		EntryTime:            now,
+		Grace:                i.ctx.BackendResponseGrace.Value,
+		StaleWhileRevalidate: i.ctx.BackendResponseStaleWhileRevalidate.Value,
+		StaleIfError:         i.ctx.BackendResponseStaleIfError.Value,
+		HitForPass:           state == PASS,
+		Vary:                 cache.NewVary(i.ctx.Request, i.ctx.BackendResponse),
+		SurrogateKeys:        cache.NewSurrogateKeys(i.ctx.BackendResponse),
+		ESI:                  i.ctx.TriggerESI || i.ctx.BackendResponseDoESI.Value,
+	}
+	hash := i.ctx.RequestHash.Value
+
+	// Streamed object is stored to the cache after the whole body is delivered to the client
+	if i.isStreaming() {
+		resp := *i.ctx.BackendResponse
+		resp.Header = resp.Header.Clone()
+		i.ctx.BackendResponse.Body = &cacheFillBody{
+			ReadCloser: i.ctx.BackendResponse.Body,
+			done: func(body []byte) {
+				resp.Body = io.NopCloser(bytes.NewReader(body))
+				item.Response = &resp
+				// Body is already read, then the object is always stored
+				i.cache.Set(hash, item) // nolint:errcheck
+			},
+		}
+		return nil
+	}
+	item.Response = i.cloneResponse(i.ctx.BackendResponse)
+	if err := i.cache.Set(hash, item); err != nil {
+		return errors.WithMessage(err, "Failed to store the object to the cache")
+	}
+	return nil
+}
+
+// deliverStale delivers the stale object which is found on lookup by return(deliver_stale).
+// On error the object is delivered within grace or stale-if-error period limited by req.max_stale_if_error,
+// otherwise within grace or stale-while-revalidate period limited by req.max_stale_while_revalidate.
+// The response is delivered as usual if stale object does not exist or it is too old.
+func (i *Interpreter) deliverStale(isError bool) error {
+	limit := i.ctx.MaxStaleWhileRevalidate.Value
+	if isError {
+		limit = i.ctx.MaxStaleIfError.Value
+	}
+	if i.ctx.StaleItem == nil || !i.ctx.StaleItem.Servable(i.ctx.Now(), isError, limit) {
+		i.Debugger.Message("Stale object is not available")
+		return i.ProcessDeliver()
+	}
+
+	i.process.Cached = true
+	i.ctx.State = "HIT-STALE"
+	i.ctx.CacheHitItem = i.ctx.StaleItem
+	i.ctx.TriggerESI = i.ctx.StaleItem.ESI
+	i.ctx.Stale.Value = true
+	i.ctx.StaleIsError.Value = isError
+	i.ctx.Object = i.ctx.StaleItem.NewResponse()
+	i.ctx.Response = i.ctx.StaleItem.NewResponse()
+	return i.ProcessDeliver()
+}
+
 var expiresValueLayout = "Mon, 02 Jan 2006 15:04:05 MST"
 
 func (i *Interpreter) determineCacheTTL(resp *http.Response) time.Duration {
@@ -760,8 +1075,33 @@ func (i *Interpreter) determineCacheTTL(resp *http.Response) time.Duration {
 	}
 	if v := resp.Header.Get("Expires"); v != "" {
 		if d, err := time.Parse(expiresValueLayout, v); err == nil {
-			return time.Until(d)
+			return d.Sub(i.ctx.Now())
 		}
 	}
 	return time.Duration(2 * time.Minute)
 }
+
+// determineStaleTTL returns stale-while-revalidate and stale-if-error periods of the response,
+// Surrogate-Control takes precedence over Cache-Control as same as TTL
+func (i *Interpreter) determineStaleTTL(resp *http.Response) (time.Duration, time.Duration) {
+	var swr, sie time.Duration
+	for _, name := range []string{"Cache-Control", "Surrogate-Control"} {
+		for _, directive := range strings.Split(resp.Header.Get(name), ",") {
+			key, val, found := strings.Cut(strings.TrimSpace(directive), "=")
+			if !found {
+				continue
+			}
+			dur, err := time.ParseDuration(strings.Trim(val, `"`) + "s")
+			if err != nil {
+				continue
+			}
+			switch strings.ToLower(key) {
+			case "stale-while-revalidate":
+				swr = dur
+			case "stale-if-error":
+				sie = dur
+			}
+		}
+	}
+	return swr, sie
+}
@@ -169,3 +169,48 @@ func TestProcessBackends(t *testing.T) {
 		}
 	})
 }
+
+func TestRandomSeed(t *testing.T) {
+	vcl := `
+director random_director random {
+	{ .backend = F_origin_0; .weight = 1; }
+	{ .backend = F_origin_1; .weight = 1; }
+}
+sub vcl_recv {
+	#FASTLY RECV
+	set req.backend = random_director;
+	error 600;
+}
+sub vcl_error {
+	#FASTLY ERROR
+	set obj.http.Random = randomstr(16) + ":" + randomint(0, 1000) + ":" + if(randombool(1, 2), "1", "0");
+	return(deliver);
+}`
+	backends := `
+backend F_origin_0 { .host = "example.com"; }
+backend F_origin_1 { .host = "example.org"; }
+`
+	run := func() []string {
+		ip := New(
+			context.WithResolver(resolver.NewStaticResolver("main", backends+vcl)),
+			context.WithRandomSeed(42),
+		)
+		var values []string
+		for n := 0; n < 5; n++ {
+			ip.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "http://localhost", nil))
+			if ip.process.Error != nil {
+				t.Fatalf("Unexpected error: %s", ip.process.Error)
+			}
+			values = append(values, ip.ctx.Response.Header.Get("Random"))
+		}
+		return values
+	}
+
+	first := run()
+	if diff := cmp.Diff(first, run()); diff != "" {
+		t.Errorf("Expected the same random values with the same seed, diff=%s", diff)
+	}
+	if first[0] == first[1] {
+		t.Errorf("Expected random values to change between requests, got %v", first)
+	}
+}
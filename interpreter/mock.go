@@ -0,0 +1,150 @@
+package interpreter
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gobwas/glob"
+	"github.com/pkg/errors"
+	"github.com/ysugimoto/falco/config"
+	icontext "github.com/ysugimoto/falco/interpreter/context"
+	"github.com/ysugimoto/falco/interpreter/exception"
+)
+
+// findBackendMock returns the first backend mock in the configuration order which matches the backend request
+func findBackendMock(ctx *icontext.Context, backendName string, req *http.Request) (*config.BackendMock, error) {
+	for _, mock := range ctx.BackendMocks {
+		if matched, err := matchBackendMock(mock, backendName, req); err != nil {
+			return nil, errors.WithStack(err)
+		} else if matched {
+			return mock, nil
+		}
+	}
+	return nil, nil
+}
+
+// hasBackendMock reports whether any backend mock targets the backend regardless of the request
+func hasBackendMock(ctx *icontext.Context, backendName string) (bool, error) {
+	for _, mock := range ctx.BackendMocks {
+		if matched, err := matchGlob(mock.Backend, backendName); err != nil {
+			return false, errors.WithStack(err)
+		} else if matched {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// matchBackendMock reports whether the backend request satisfies all conditions of the mock.
+// Backend name, path and header values accept glob pattern, and the empty condition matches anything.
+func matchBackendMock(mock *config.BackendMock, backendName string, req *http.Request) (bool, error) {
+	if matched, err := matchGlob(mock.Backend, backendName); err != nil || !matched {
+		return false, err
+	}
+	cond := mock.Request
+	if cond == nil {
+		return true, nil
+	}
+	if cond.Method != "" && !strings.EqualFold(cond.Method, req.Method) {
+		return false, nil
+	}
+	if matched, err := matchGlob(cond.Path, req.URL.Path); err != nil || !matched {
+		return false, err
+	}
+	for name, pattern := range cond.Headers {
+		if len(req.Header.Values(name)) == 0 {
+			return false, nil
+		}
+		if matched, err := matchGlob(pattern, req.Header.Get(name)); err != nil || !matched {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+func matchGlob(pattern, s string) (bool, error) {
+	if pattern == "" {
+		return true, nil
+	}
+	p, err := glob.Compile(pattern)
+	if err != nil {
+		return false, exception.System("Invalid glob pattern is provided: %s, %s", pattern, err)
+	}
+	return p.Match(s), nil
+}
+
+// mockBackendResponse creates the stubbed backend response after waiting for the latency.
+// The latency which exceeds first_byte_timeout fails the backend request like the real origin does.
+func (i *Interpreter) mockBackendResponse(mock *config.BackendMock, req *http.Request) (*http.Response, error) {
+	status := http.StatusOK
+	header := http.Header{}
+	var body []byte
+	var latency time.Duration
+
+	if r := mock.Response; r != nil {
+		if r.Status != 0 {
+			status = r.Status
+		}
+		for name, val := range r.Headers {
+			header.Set(name, val)
+		}
+		body = []byte(r.Body)
+		if r.BodyFile != "" {
+			b, err := os.ReadFile(r.BodyFile)
+			if err != nil {
+				return nil, exception.System("Failed to read body file of the backend mock: %s", err)
+			}
+			body = b
+		}
+		if r.Latency != "" {
+			d, err := time.ParseDuration(r.Latency)
+			if err != nil {
+				return nil, exception.System("Invalid latency is provided for the backend mock: %s, %s", r.Latency, err)
+			}
+			latency = d
+		}
+	}
+
+	wait := latency
+	timeout := i.ctx.FirstByteTimeout.Value
+	if timeout > 0 && latency > timeout {
+		wait = timeout
+	}
+	if wait > 0 {
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, &backendFailure{code: "ERR_CONNECT", response: "Backend unavailable, connection failed", err: req.Context().Err()}
+		}
+	}
+	if wait != latency {
+		return nil, &backendFailure{
+			code:     "ERR_FIRST_BYTE_TIMEOUT",
+			response: "first byte timeout",
+			err:      errors.Errorf("Latency %s of the backend mock exceeds first_byte_timeout", latency),
+		}
+	}
+
+	if header.Get("Content-Length") == "" {
+		header.Set("Content-Length", strconv.Itoa(len(body)))
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", status, http.StatusText(status)),
+		StatusCode:    status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
@@ -0,0 +1,107 @@
+package interpreter
+
+import (
+	"io"
+	"testing"
+
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/ysugimoto/falco/config"
+	"github.com/ysugimoto/falco/interpreter/context"
+	"github.com/ysugimoto/falco/resolver"
+)
+
+func TestBackendMock(t *testing.T) {
+	vcl := `
+backend F_origin {
+	.host = "origin.invalid";
+	.port = "443";
+	.ssl = true;
+	.first_byte_timeout = 50ms;
+}
+sub vcl_recv {
+	#FASTLY RECV
+	return(pass);
+}
+sub vcl_error {
+	#FASTLY ERROR
+	set obj.http.X-Error = fastly.error;
+	return(deliver);
+}`
+
+	mocks := []*config.BackendMock{
+		{
+			Backend: "F_*",
+			Request: &config.BackendMockRequest{
+				Method:  "POST",
+				Path:    "/api/*",
+				Headers: map[string]string{"Authorization": "Bearer *"},
+			},
+			Response: &config.BackendMockResponse{
+				Status:  http.StatusCreated,
+				Headers: map[string]string{"Content-Type": "application/json"},
+				Body:    `{"ok":true}`,
+			},
+		},
+		{
+			Request:  &config.BackendMockRequest{Path: "/slow"},
+			Response: &config.BackendMockResponse{Latency: "100ms"},
+		},
+		{
+			Backend:  "F_origin",
+			Response: &config.BackendMockResponse{Body: "fallback"},
+		},
+	}
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		header map[string]string
+		status int
+		body   string
+		error  string
+	}{
+		{name: "all conditions match", method: http.MethodPost, path: "/api/users", header: map[string]string{"Authorization": "Bearer token"}, status: http.StatusCreated, body: `{"ok":true}`},
+		{name: "header does not match", method: http.MethodPost, path: "/api/users", status: http.StatusOK, body: "fallback"},
+		{name: "method does not match", method: http.MethodGet, path: "/api/users", header: map[string]string{"Authorization": "Bearer token"}, status: http.StatusOK, body: "fallback"},
+		{name: "latency exceeds first byte timeout", method: http.MethodGet, path: "/slow", status: http.StatusServiceUnavailable, error: "ERR_FIRST_BYTE_TIMEOUT"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ip := New(
+				context.WithResolver(resolver.NewStaticResolver("main", vcl)),
+				context.WithBackendMocks(mocks),
+			)
+			req := httptest.NewRequest(tt.method, "http://localhost"+tt.path, nil)
+			for k, v := range tt.header {
+				req.Header.Set(k, v)
+			}
+			if err := ip.ProcessInit(req); err != nil {
+				t.Fatalf("Unexpected init error: %s", err)
+			}
+			if err := ip.ProcessRecv(); err != nil {
+				t.Fatalf("Unexpected error: %s", err)
+			}
+			resp := ip.ctx.Response
+			if resp.StatusCode != tt.status {
+				t.Errorf("Expected status %d, got %d", tt.status, resp.StatusCode)
+			}
+			if v := resp.Header.Get("X-Error"); v != tt.error {
+				t.Errorf("Expected fastly.error %s, got %s", tt.error, v)
+			}
+			if tt.body == "" {
+				return
+			}
+			body, err := io.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatalf("Unexpected body reading error: %s", err)
+			}
+			if string(body) != tt.body {
+				t.Errorf("Expected body %s, got %s", tt.body, string(body))
+			}
+		})
+	}
+}
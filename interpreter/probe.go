@@ -0,0 +1,299 @@
+package interpreter
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/ysugimoto/falco/ast"
+	"github.com/ysugimoto/falco/interpreter/exception"
+	"github.com/ysugimoto/falco/interpreter/value"
+)
+
+// Default values of the probe
+// see: https://developer.fastly.com/reference/vcl/declarations/backend/#health-checks
+const (
+	defaultProbeExpectedResponse = 200
+	defaultProbeInterval         = 5 * time.Second
+	defaultProbeTimeout          = 2 * time.Second
+	defaultProbeWindow           = 5
+	defaultProbeThreshold        = 3
+)
+
+type probeConfig struct {
+	dummy            bool
+	method           string
+	path             string
+	header           http.Header
+	expectedResponse int64
+	interval         time.Duration
+	timeout          time.Duration
+	window           int64
+	threshold        int64
+	initial          int64
+}
+
+// backendProbe holds the health status of the backend which is updated by the probe.
+// The status is shared between requests, and the probe is sent when the interval passed on the interpreter clock.
+type backendProbe struct {
+	mu      sync.Mutex
+	config  *probeConfig
+	healthy *atomic.Bool
+	results []bool // probe results in the window, the last one is the latest
+	next    time.Time
+}
+
+func newBackendProbe(c *probeConfig) *backendProbe {
+	p := &backendProbe{
+		config:  c,
+		healthy: &atomic.Bool{},
+	}
+	// Initial probes are treated as succeeded
+	for n := int64(0); n < c.initial && n < c.window; n++ {
+		p.results = append(p.results, true)
+	}
+	p.healthy.Store(p.succeeded() >= c.threshold)
+	return p
+}
+
+func (p *backendProbe) succeeded() int64 {
+	var count int64
+	for _, ok := range p.results {
+		if ok {
+			count++
+		}
+	}
+	return count
+}
+
+// record adds the probe result to the window and updates the health status
+func (p *backendProbe) record(ok bool) {
+	p.results = append(p.results, ok)
+	if over := int64(len(p.results)) - p.config.window; over > 0 {
+		p.results = p.results[over:]
+	}
+	p.healthy.Store(p.succeeded() >= p.config.threshold)
+}
+
+// backendProbes holds probes of each backend, shared between forked interpreters
+type backendProbes struct {
+	mu     sync.Mutex
+	probes map[string]*backendProbe
+}
+
+func newBackendProbes() *backendProbes {
+	return &backendProbes{
+		probes: make(map[string]*backendProbe),
+	}
+}
+
+func (b *backendProbes) get(name string, c *probeConfig) *backendProbe {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	p, ok := b.probes[name]
+	if !ok {
+		p = newBackendProbe(c)
+		b.probes[name] = p
+	}
+	return p
+}
+
+// backendHealth returns the health status of the backend.
+// The backend is unhealthy when the override configuration says so,
+// and the backend which has .probe runs the probe if the interval has passed.
+func (i *Interpreter) backendHealth(backend *value.Backend) (*atomic.Bool, error) {
+	healthy := &atomic.Bool{}
+	healthy.Store(true)
+
+	overrideBackend, err := getOverrideBackend(i.ctx, backend.Value.Name.Value)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if overrideBackend != nil && overrideBackend.Unhealthy {
+		healthy.Store(false)
+		return healthy, nil
+	}
+	if !i.ctx.Probe {
+		return healthy, nil
+	}
+	// Mocked backend does not have the real origin to probe
+	if mocked, err := hasBackendMock(i.ctx, backend.Value.Name.Value); err != nil {
+		return nil, errors.WithStack(err)
+	} else if mocked {
+		return healthy, nil
+	}
+
+	var probe *ast.BackendProbeObject
+	for _, v := range backend.Value.Properties {
+		if o, ok := v.Value.(*ast.BackendProbeObject); ok && v.Key.Value == "probe" {
+			probe = o
+		}
+	}
+	if probe == nil {
+		return healthy, nil
+	}
+
+	c, err := i.getProbeConfig(backend, probe)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	p := i.probes.get(backend.Value.Name.Value, c)
+	if err := i.runProbe(backend, p); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return p.healthy, nil
+}
+
+func (i *Interpreter) getProbeConfig(backend *value.Backend, probe *ast.BackendProbeObject) (*probeConfig, error) {
+	c := &probeConfig{
+		method:           http.MethodHead,
+		path:             "/",
+		header:           http.Header{},
+		expectedResponse: defaultProbeExpectedResponse,
+		interval:         defaultProbeInterval,
+		timeout:          defaultProbeTimeout,
+		window:           defaultProbeWindow,
+		threshold:        defaultProbeThreshold,
+		initial:          -1,
+	}
+
+	for _, prop := range probe.Values {
+		// Request lines should be parsed before concatenation
+		if prop.Key.Value == "request" {
+			if err := parseProbeRequest(c, prop.Value); err != nil {
+				return nil, errors.WithStack(err)
+			}
+			continue
+		}
+
+		v, err := i.ProcessExpression(prop.Value, false)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		switch prop.Key.Value {
+		case "dummy":
+			c.dummy = value.Unwrap[*value.Boolean](v).Value
+		case "expected_response":
+			c.expectedResponse = value.Unwrap[*value.Integer](v).Value
+		case "interval":
+			c.interval = value.Unwrap[*value.RTime](v).Value
+		case "timeout":
+			c.timeout = value.Unwrap[*value.RTime](v).Value
+		case "window":
+			c.window = value.Unwrap[*value.Integer](v).Value
+		case "threshold":
+			c.threshold = value.Unwrap[*value.Integer](v).Value
+		case "initial":
+			c.initial = value.Unwrap[*value.Integer](v).Value
+		}
+	}
+
+	// The backend starts as healthy unless .initial is specified
+	if c.initial < 0 {
+		c.initial = c.threshold
+	}
+	if c.header.Get("Host") == "" {
+		if _, host, err := i.backendOrigin(i.ctx, backend); err == nil {
+			c.header.Set("Host", host)
+		}
+	}
+	return c, nil
+}
+
+// parseProbeRequest parses request lines of the probe like "GET / HTTP/1.1" "Host: example.com"
+func parseProbeRequest(c *probeConfig, exp ast.Expression) error {
+	var lines []string
+	var collect func(exp ast.Expression)
+	collect = func(exp ast.Expression) {
+		switch t := exp.(type) {
+		case *ast.String:
+			lines = append(lines, t.Value)
+		case *ast.GroupedExpression:
+			collect(t.Right)
+		case *ast.InfixExpression:
+			collect(t.Left)
+			collect(t.Right)
+		}
+	}
+	collect(exp)
+	if len(lines) == 0 {
+		return nil
+	}
+
+	fields := strings.Fields(lines[0])
+	if len(fields) < 2 {
+		return exception.Runtime(nil, "Invalid probe request line: %s", lines[0])
+	}
+	c.method, c.path = fields[0], fields[1]
+	for _, line := range lines[1:] {
+		name, val, found := strings.Cut(line, ":")
+		if !found {
+			return exception.Runtime(nil, "Invalid probe request header: %s", line)
+		}
+		c.header.Set(strings.TrimSpace(name), strings.TrimSpace(val))
+	}
+	return nil
+}
+
+// runProbe sends the probe to the backend when the interval has passed since the previous probe.
+// Dummy probe is never sent so the backend keeps the initial health status.
+func (i *Interpreter) runProbe(backend *value.Backend, p *backendProbe) error {
+	if p.config.dummy {
+		return nil
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := i.ctx.Now()
+	if now.Before(p.next) {
+		return nil
+	}
+	p.next = now.Add(p.config.interval)
+
+	origin, _, err := i.backendOrigin(i.ctx, backend)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	ctx, timeout := context.WithTimeout(context.Background(), p.config.timeout)
+	defer timeout()
+
+	req, err := http.NewRequestWithContext(ctx, p.config.method, origin+p.config.path, nil)
+	if err != nil {
+		return exception.Runtime(nil, "Failed to create probe request: %s", err)
+	}
+	req.Header = p.config.header.Clone()
+	req.Host = req.Header.Get("Host")
+
+	client := http.DefaultClient
+	if req.URL.Scheme == HTTPS_SCHEME {
+		transport := &http.Transport{}
+		if transport.TLSClientConfig, err = i.backendTLSConfig(backend, req.URL.Hostname()); err != nil {
+			return errors.WithStack(err)
+		}
+		defer transport.CloseIdleConnections()
+		client = &http.Client{Transport: transport}
+	}
+
+	var status int
+	resp, err := client.Do(req)
+	if err == nil {
+		io.Copy(io.Discard, resp.Body) // nolint: errcheck
+		resp.Body.Close()
+		status = resp.StatusCode
+	}
+	p.record(int64(status) == p.config.expectedResponse)
+
+	i.Debugger.Message(fmt.Sprintf(
+		"Probe backend (%s) %s responds status code %d, healthy: %t",
+		backend.Value.Name.Value, req.URL.String(), status, p.healthy.Load(),
+	))
+	return nil
+}
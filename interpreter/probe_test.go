@@ -0,0 +1,118 @@
+package interpreter
+
+import (
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+
+	"github.com/ysugimoto/falco/interpreter/clock"
+	"github.com/ysugimoto/falco/interpreter/context"
+	"github.com/ysugimoto/falco/resolver"
+)
+
+func TestBackendProbe(t *testing.T) {
+	var status atomic.Int32
+	status.Store(http.StatusOK)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/health" {
+			w.WriteHeader(int(status.Load()))
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	parsed, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatalf("Test server URL parsing error: %s", err)
+	}
+
+	vcl := fmt.Sprintf(`
+backend F_origin {
+	.host = "%s";
+	.port = "%s";
+	.probe = {
+		.request = "GET /health HTTP/1.1" "Host: example.com";
+		.interval = 10s;
+		.window = 2;
+		.threshold = 1;
+		.initial = 1;
+	}
+}
+backend F_fallback {
+	.host = "%s";
+	.port = "%s";
+}
+director fallback_director fallback {
+	{ .backend = F_origin; }
+	{ .backend = F_fallback; }
+}
+sub vcl_recv {
+	#FASTLY RECV
+	set req.backend = fallback_director;
+	error 600;
+}
+sub vcl_error {
+	#FASTLY ERROR
+	set obj.http.Healthy = backend.F_origin.healthy;
+	set obj.http.Director-Healthy = director.fallback_director.healthy;
+	return(deliver);
+}`, parsed.Hostname(), parsed.Port(), parsed.Hostname(), parsed.Port())
+
+	clk := clock.NewManual()
+	clk.Freeze(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	ip := New(
+		context.WithResolver(resolver.NewStaticResolver("main", vcl)),
+		context.WithClock(clk),
+		context.WithProbe(),
+	)
+	healthy := func() string {
+		ip.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "http://localhost/", nil))
+		if ip.process.Error != nil {
+			t.Fatalf("Unexpected error: %s", ip.process.Error)
+		}
+		if v := ip.ctx.Response.Header.Get("Director-Healthy"); v != "1" {
+			t.Errorf("Expected fallback director to be healthy, got %s", v)
+		}
+		return ip.ctx.Response.Header.Get("Healthy")
+	}
+
+	if v := healthy(); v != "1" {
+		t.Errorf("Expected backend to be healthy on the first probe, got %s", v)
+	}
+
+	// Probe is not sent until the interval passes
+	status.Store(http.StatusServiceUnavailable)
+	if v := healthy(); v != "1" {
+		t.Errorf("Expected backend to keep healthy before the interval, got %s", v)
+	}
+
+	// Both results in the window have failed after two probes
+	clk.Advance(10 * time.Second)
+	healthy()
+	clk.Advance(10 * time.Second)
+	if v := healthy(); v != "0" {
+		t.Errorf("Expected backend to be unhealthy after failed probes, got %s", v)
+	}
+	if ip.ctx.Backend.Director == nil {
+		t.Fatalf("Expected req.backend to be the director")
+	}
+	backend, err := ip.directorBackendFallback(ip.ctx.Backend.Director)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if backend.Value.Name.Value != "F_fallback" {
+		t.Errorf("Expected fallback director to choose F_fallback, got %s", backend.Value.Name.Value)
+	}
+
+	status.Store(http.StatusOK)
+	clk.Advance(10 * time.Second)
+	if v := healthy(); v != "1" {
+		t.Errorf("Expected backend to recover after the succeeded probe, got %s", v)
+	}
+}
@@ -1,9 +1,7 @@
 package process
 
 import (
-	"bytes"
 	"encoding/json"
-	"io"
 	"net/http"
 	"strings"
 	"time"
@@ -12,14 +10,17 @@ import (
 )
 
 type Process struct {
-	Flows     []*Flow
-	Logs      []*Log
-	Restarts  int
-	Backend   *value.Backend
-	Cached    bool
-	Error     error
-	StartTime int64
-	Response  *http.Response
+	Flows         []*Flow
+	Logs          []*Log
+	Restarts      int
+	Backend       *value.Backend
+	Cached        bool
+	Error         error
+	StartTime     int64
+	FirstByteTime int64
+	Response      *http.Response
+	// Size of the response body which is written to the client
+	BodyBytes int64
 }
 
 func New() *Process {
@@ -30,24 +31,24 @@ func New() *Process {
 	}
 }
 
+// MarkFirstByte records the time when the response starts to be delivered to the client
+func (p *Process) MarkFirstByte() {
+	if p.FirstByteTime == 0 {
+		p.FirstByteTime = time.Now().UnixMicro()
+	}
+}
+
 func (p *Process) Finalize(resp *http.Response) ([]byte, error) {
 	var backend string
 	if p.Backend != nil {
-		backend = p.Backend.Value.Name.Value
+		backend = p.Backend.String()
 	}
 
 	var statusCode int
-	var buf bytes.Buffer
 	headers := make(map[string]string)
 
 	if resp != nil {
 		statusCode = resp.StatusCode
-		if _, err := buf.ReadFrom(resp.Body); err != nil {
-			return nil, err
-		}
-		// rewind response body - may not need but guard from another body retrieving
-		resp.Body = io.NopCloser(bytes.NewReader(buf.Bytes()))
-
 		for key, val := range resp.Header {
 			if len(val) == 0 {
 				continue
@@ -56,6 +57,11 @@ func (p *Process) Finalize(resp *http.Response) ([]byte, error) {
 		}
 	}
 
+	var ttfb int64
+	if p.FirstByteTime > 0 {
+		ttfb = p.FirstByteTime - p.StartTime
+	}
+
 	return json.MarshalIndent(struct {
 		Flows          []*Flow `json:"flows"`
 		Logs           []*Log  `json:"logs"`
@@ -64,10 +70,12 @@ func (p *Process) Finalize(resp *http.Response) ([]byte, error) {
 		Cached         bool    `json:"cached"`
 		ElapsedTimeUs  int64   `json:"elapsed_time_us"`
 		ElapsedTimeMs  int64   `json:"elapsed_time_ms"`
+		TTFBUs         int64   `json:"time_to_first_byte_us"`
+		TTFBMs         int64   `json:"time_to_first_byte_ms"`
 		Error          error   `json:"error,omitempty"`
 		ClientResponse struct {
 			StatusCode    int               `json:"status_code"`
-			ResponseBytes int               `json:"body_bytes"`
+			ResponseBytes int64             `json:"body_bytes"`
 			Headers       map[string]string `json:"headers"`
 		} `json:"client_response"`
 	}{
@@ -78,14 +86,16 @@ func (p *Process) Finalize(resp *http.Response) ([]byte, error) {
 		Cached:        false,
 		ElapsedTimeUs: time.Now().UnixMicro() - p.StartTime,
 		ElapsedTimeMs: time.Now().UnixMilli() - (p.StartTime / 1000),
+		TTFBUs:        ttfb,
+		TTFBMs:        ttfb / 1000,
 		Error:         p.Error,
 		ClientResponse: struct {
 			StatusCode    int               `json:"status_code"`
-			ResponseBytes int               `json:"body_bytes"`
+			ResponseBytes int64             `json:"body_bytes"`
 			Headers       map[string]string `json:"headers"`
 		}{
 			StatusCode:    statusCode,
-			ResponseBytes: len(buf.Bytes()),
+			ResponseBytes: p.BodyBytes,
 			Headers:       headers,
 		},
 	}, "", "  ")
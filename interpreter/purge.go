@@ -0,0 +1,97 @@
+package interpreter
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+)
+
+// Request methods which purge the cached object of the URL.
+// Fastly passes PURGE requests to VCL as FASTLYPURGE so both methods are accepted.
+var purgeMethods = map[string]struct{}{
+	"PURGE":       {},
+	"FASTLYPURGE": {},
+}
+
+func isPurgeRequest(r *http.Request) bool {
+	_, ok := purgeMethods[strings.ToUpper(r.Method)]
+	return ok
+}
+
+// purge removes the cached object which has the request hash and responds the result like Fastly API does.
+// Fastly-Soft-Purge request header marks the object as stale instead of removing it.
+// see: https://developer.fastly.com/reference/api/purging/
+func (i *Interpreter) purge() {
+	hash := i.ctx.RequestHash.Value
+	soft := i.ctx.Request.Header.Get("Fastly-Soft-Purge") == "1"
+
+	// Purge is propagated to all POPs
+	var purged int
+	for _, c := range i.caches() {
+		if soft {
+			purged += c.SoftPurge(hash, i.ctx.Now())
+		} else {
+			purged += c.Purge(hash)
+		}
+	}
+	i.ctx.State = "PURGE"
+	i.Debugger.Message(fmt.Sprintf("Purged %d object(s) for hash %s (soft=%t)", purged, hash, soft))
+
+	body := purgeResult()
+	i.ctx.Response = &http.Response{
+		StatusCode: http.StatusOK,
+		Status:     http.StatusText(http.StatusOK),
+		Proto:      "HTTP/1.1",
+		ProtoMajor: 1,
+		ProtoMinor: 1,
+		Header: http.Header{
+			"Content-Type": {"application/json"},
+		},
+		Body:          io.NopCloser(strings.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       i.ctx.Request,
+	}
+}
+
+// PurgeSurrogateKey purges cached objects which have the key in Surrogate-Key response header,
+// soft purge marks the objects as stale instead of removing them. Returns the number of purged objects.
+func (i *Interpreter) PurgeSurrogateKey(key string, soft bool) int {
+	var purged int
+	for _, c := range i.caches() {
+		purged += c.PurgeKey(key, soft, i.ctx.Now())
+	}
+	i.Debugger.Message(fmt.Sprintf("Purged %d object(s) for surrogate key %s (soft=%t)", purged, key, soft))
+	return purged
+}
+
+// PurgeHandler returns the handler which purges by surrogate key like Fastly API does.
+// The key is the last segment of the request path, e.g. POST /__falco/purge/{key},
+// and Fastly-Soft-Purge: 1 request header performs soft purge.
+// see: https://developer.fastly.com/reference/api/purging/#purge-tag
+func (i *Interpreter) PurgeHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		key := path.Base(r.URL.Path)
+		if key == "" || key == "/" || key == "." {
+			http.Error(w, "Surrogate key is required", http.StatusBadRequest)
+			return
+		}
+		i.PurgeSurrogateKey(key, r.Header.Get("Fastly-Soft-Purge") == "1")
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(purgeResult())) // nolint:errcheck
+	})
+}
+
+// purgeResult returns the response body of the purge as same as Fastly API
+func purgeResult() string {
+	return fmt.Sprintf(`{"status": "ok", "id": "falco-%d"}`, time.Now().UnixNano())
+}
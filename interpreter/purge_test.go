@@ -0,0 +1,172 @@
+package interpreter
+
+import (
+	"strings"
+	"testing"
+
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+
+	"github.com/ysugimoto/falco/interpreter/context"
+	"github.com/ysugimoto/falco/resolver"
+)
+
+func TestPurge(t *testing.T) {
+	var fetched int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetched++
+		w.Header().Set("Cache-Control", "max-age=60")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("OK")) // nolint:errcheck
+	}))
+	defer server.Close()
+
+	parsed, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatalf("Test server URL parsing error: %s", err)
+	}
+
+	vcl := defaultBackend(parsed) + `
+sub vcl_recv {
+	#FASTLY RECV
+	if (req.http.X-Refresh) {
+		set req.hash_always_miss = true;
+	}
+	return(lookup);
+}
+sub vcl_fetch {
+	#FASTLY FETCH
+	set beresp.grace = 1h;
+}
+sub vcl_miss {
+	#FASTLY MISS
+	if (stale.exists) {
+		set req.http.X-Stale = "1";
+	}
+}`
+
+	type step struct {
+		method string
+		header string
+		state  string
+	}
+	tests := []struct {
+		name    string
+		steps   []step
+		fetched int
+		stale   bool
+	}{
+		{
+			name: "purge",
+			steps: []step{
+				{method: http.MethodGet, state: "MISS"},
+				{method: "PURGE", state: "PURGE"},
+				{method: http.MethodGet, state: "MISS"},
+				{method: http.MethodGet, state: "HIT"},
+			},
+			fetched: 2,
+		},
+		{
+			name: "soft purge keeps stale object",
+			steps: []step{
+				{method: http.MethodGet, state: "MISS"},
+				{method: "PURGE", header: "Fastly-Soft-Purge", state: "PURGE"},
+				{method: http.MethodGet, state: "MISS"},
+			},
+			fetched: 2,
+			stale:   true,
+		},
+		{
+			name: "hash_always_miss",
+			steps: []step{
+				{method: http.MethodGet, state: "MISS"},
+				{method: http.MethodGet, header: "X-Refresh", state: "MISS"},
+				{method: http.MethodGet, state: "HIT"},
+			},
+			fetched: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fetched = 0
+			ip := New(context.WithResolver(resolver.NewStaticResolver("main", vcl)))
+			for _, s := range tt.steps {
+				req := httptest.NewRequest(s.method, "http://localhost", nil)
+				if s.header != "" {
+					req.Header.Set(s.header, "1")
+				}
+				ip.ServeHTTP(httptest.NewRecorder(), req)
+				if ip.process.Error != nil {
+					t.Fatalf("Unexpected error: %s", ip.process.Error)
+				}
+				if ip.ctx.State != s.state {
+					t.Errorf("Expected state %s, got %s", s.state, ip.ctx.State)
+				}
+			}
+			if fetched != tt.fetched {
+				t.Errorf("Expected backend fetched %d times, got %d", tt.fetched, fetched)
+			}
+			if tt.stale && ip.ctx.Request.Header.Get("X-Stale") != "1" {
+				t.Errorf("Expected stale object exists after soft purge")
+			}
+		})
+	}
+}
+
+func TestPurgeSurrogateKey(t *testing.T) {
+	var fetched int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetched++
+		w.Header().Set("Cache-Control", "max-age=60")
+		w.Header().Set("Surrogate-Key", "all page"+strings.ReplaceAll(r.URL.Path, "/", "-"))
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("OK")) // nolint:errcheck
+	}))
+	defer server.Close()
+
+	parsed, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatalf("Test server URL parsing error: %s", err)
+	}
+
+	ip := New(context.WithResolver(resolver.NewStaticResolver("main", defaultBackend(parsed)+`
+sub vcl_recv {
+	#FASTLY RECV
+	return(lookup);
+}`)))
+	get := func(path string) string {
+		ip.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "http://localhost"+path, nil))
+		if ip.process.Error != nil {
+			t.Fatalf("Unexpected error: %s", ip.process.Error)
+		}
+		return ip.ctx.State
+	}
+	purge := func(key string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		ip.PurgeHandler().ServeHTTP(w, httptest.NewRequest(http.MethodPost, "http://localhost/__falco/purge/"+key, nil))
+		return w
+	}
+
+	get("/a")
+	get("/b")
+	if w := purge("page-a"); w.Code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", w.Code)
+	}
+	if state := get("/a"); state != "MISS" {
+		t.Errorf("Expected purged object to be missed, got %s", state)
+	}
+	if state := get("/b"); state != "HIT" {
+		t.Errorf("Expected object which does not have the key to be hit, got %s", state)
+	}
+	if n := ip.PurgeSurrogateKey("all", false); n != 2 {
+		t.Errorf("Expected 2 objects purged, got %d", n)
+	}
+	if state := get("/b"); state != "MISS" {
+		t.Errorf("Expected purged object to be missed, got %s", state)
+	}
+	if fetched != 4 {
+		t.Errorf("Expected backend fetched 4 times, got %d", fetched)
+	}
+}
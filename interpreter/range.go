@@ -0,0 +1,141 @@
+package interpreter
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// byteRange represents the single byte range which is resolved with the object size, end is inclusive
+type byteRange struct {
+	start int64
+	end   int64
+}
+
+// parseRange parses Range request header value against the object size.
+// Returns nil if the header should be ignored, Fastly serves the entire object for
+// invalid syntax, other units and multiple ranges. ok is false if the range is not satisfiable.
+func parseRange(header string, size int64) (r *byteRange, ok bool) {
+	spec, found := strings.CutPrefix(strings.TrimSpace(header), "bytes=")
+	if !found || strings.Contains(spec, ",") {
+		return nil, true
+	}
+	first, last, found := strings.Cut(strings.TrimSpace(spec), "-")
+	if !found {
+		return nil, true
+	}
+
+	// Suffix range like "bytes=-500" means the last 500 bytes
+	if first == "" {
+		n, err := strconv.ParseInt(last, 10, 64)
+		if err != nil || n < 0 {
+			return nil, true
+		}
+		if n == 0 || size == 0 {
+			return nil, false
+		}
+		if n > size {
+			n = size
+		}
+		return &byteRange{start: size - n, end: size - 1}, true
+	}
+
+	start, err := strconv.ParseInt(first, 10, 64)
+	if err != nil || start < 0 {
+		return nil, true
+	}
+	end := size - 1
+	if last != "" {
+		if end, err = strconv.ParseInt(last, 10, 64); err != nil || end < start {
+			return nil, true
+		}
+		if end >= size {
+			end = size - 1
+		}
+	}
+	if start >= size {
+		return nil, false
+	}
+	return &byteRange{start: start, end: end}, true
+}
+
+// isRangeHandled reports whether Fastly processes Range request header by itself.
+// Then the whole object is fetched from the origin and the range is sliced on deliver.
+// Passed request forwards Range header to the origin unless req.enable_range_on_pass is enabled.
+// see: https://developer.fastly.com/reference/vcl/variables/client-request/req-enable-range-on-pass/
+func (i *Interpreter) isRangeHandled() bool {
+	// Segmented caching fetches the range of each block and serves the range by itself
+	if i.ctx.SegmentedCaching != nil {
+		return false
+	}
+	if strings.HasPrefix(i.ctx.State, "PASS") || strings.HasPrefix(i.ctx.State, "HITPASS") {
+		return i.ctx.EnableRangeOnPass.Value
+	}
+	return true
+}
+
+// processRange responds the partial content of the object for Range request header.
+// 206 Partial Content is responded for the satisfiable range, and 416 Range Not Satisfiable otherwise.
+func (i *Interpreter) processRange() error {
+	resp := i.ctx.Response
+	if resp.StatusCode != http.StatusOK || i.ctx.IsLocallyGenerated.Value || !i.isRangeHandled() {
+		return nil
+	}
+	if resp.Header.Get("Accept-Ranges") == "" {
+		resp.Header.Set("Accept-Ranges", "bytes")
+	}
+
+	req := i.ctx.Request
+	header := req.Header.Get("Range")
+	if header == "" || req.Method != http.MethodGet {
+		return nil
+	}
+	// If-Range serves the entire object when the object has been changed
+	if v := req.Header.Get("If-Range"); v != "" && v != resp.Header.Get("ETag") && v != resp.Header.Get("Last-Modified") {
+		return nil
+	}
+
+	return i.sliceRange(resp, header)
+}
+
+// sliceRange replaces the response with the partial content of the range
+func (i *Interpreter) sliceRange(resp *http.Response, header string) error {
+	body, err := readResponseBody(resp)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	size := int64(len(body))
+	r, ok := parseRange(header, size)
+	if !ok {
+		notSatisfiable(resp, size)
+		i.Debugger.Message(fmt.Sprintf("Range %s is not satisfiable for %d bytes object", header, size))
+		return nil
+	}
+	if r == nil {
+		setResponseBody(resp, body)
+		return nil
+	}
+	partialContent(resp, r, size, body[r.start:r.end+1])
+	return nil
+}
+
+// partialContent makes the response 206 Partial Content with the body of the range
+func partialContent(resp *http.Response, r *byteRange, size int64, body []byte) {
+	resp.StatusCode = http.StatusPartialContent
+	resp.Status = fmt.Sprintf("%d %s", resp.StatusCode, http.StatusText(resp.StatusCode))
+	resp.Header.Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", r.start, r.end, size))
+	resp.Header.Set("Content-Length", strconv.Itoa(len(body)))
+	setResponseBody(resp, body)
+}
+
+// notSatisfiable makes the response 416 Range Not Satisfiable
+func notSatisfiable(resp *http.Response, size int64) {
+	resp.StatusCode = http.StatusRequestedRangeNotSatisfiable
+	resp.Status = fmt.Sprintf("%d %s", resp.StatusCode, http.StatusText(resp.StatusCode))
+	resp.Header.Set("Content-Range", fmt.Sprintf("bytes */%d", size))
+	resp.Header.Set("Content-Length", "0")
+	setResponseBody(resp, nil)
+}
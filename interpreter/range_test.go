@@ -0,0 +1,140 @@
+package interpreter
+
+import (
+	"io"
+	"sync/atomic"
+	"testing"
+
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/ysugimoto/falco/interpreter/context"
+	"github.com/ysugimoto/falco/resolver"
+)
+
+func TestParseRange(t *testing.T) {
+	tests := []struct {
+		header string
+		expect *byteRange
+		ok     bool
+	}{
+		{header: "bytes=0-9", expect: &byteRange{start: 0, end: 9}, ok: true},
+		{header: "bytes=10-", expect: &byteRange{start: 10, end: 99}, ok: true},
+		{header: "bytes=-10", expect: &byteRange{start: 90, end: 99}, ok: true},
+		{header: "bytes=-200", expect: &byteRange{start: 0, end: 99}, ok: true},
+		{header: "bytes=50-200", expect: &byteRange{start: 50, end: 99}, ok: true},
+		{header: "bytes=100-", ok: false},
+		{header: "bytes=-0", ok: false},
+		{header: "bytes=0-9,20-29", ok: true},
+		{header: "bytes=9-0", ok: true},
+		{header: "items=0-9", ok: true},
+		{header: "bytes=a-b", ok: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.header, func(t *testing.T) {
+			r, ok := parseRange(tt.header, 100)
+			if ok != tt.ok {
+				t.Errorf("Expected satisfiable %t, got %t", tt.ok, ok)
+			}
+			if (tt.expect == nil) != (r == nil) || (r != nil && *r != *tt.expect) {
+				t.Errorf("Range mismatch, expect=%v, got=%v", tt.expect, r)
+			}
+		})
+	}
+}
+
+func TestRangeRequest(t *testing.T) {
+	body := "0123456789"
+	var received atomic.Value
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received.Store(r.Header.Get("Range"))
+		w.Header().Set("Cache-Control", "max-age=60")
+		w.Header().Set("ETag", `"v1"`)
+		if r.Header.Get("Range") != "" {
+			w.Header().Set("Content-Range", "bytes 0-1/10")
+			w.WriteHeader(http.StatusPartialContent)
+			w.Write([]byte(body[:2])) // nolint:errcheck
+			return
+		}
+		w.Write([]byte(body)) // nolint:errcheck
+	}))
+	defer server.Close()
+
+	parsed, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatalf("Test server URL parsing error: %s", err)
+	}
+
+	vcl := defaultBackend(parsed) + `
+sub vcl_recv {
+	#FASTLY RECV
+	if (req.http.Pass) {
+		if (req.http.Enable-Range) {
+			set req.enable_range_on_pass = true;
+		}
+		return(pass);
+	}
+	return(lookup);
+}`
+
+	tests := []struct {
+		name         string
+		header       map[string]string
+		status       int
+		contentRange string
+		body         string
+		forwarded    string
+	}{
+		{name: "entire object", status: http.StatusOK, body: body},
+		{name: "partial content", header: map[string]string{"Range": "bytes=2-5"}, status: http.StatusPartialContent, contentRange: "bytes 2-5/10", body: "2345"},
+		{name: "suffix range", header: map[string]string{"Range": "bytes=-3"}, status: http.StatusPartialContent, contentRange: "bytes 7-9/10", body: "789"},
+		{name: "not satisfiable", header: map[string]string{"Range": "bytes=20-"}, status: http.StatusRequestedRangeNotSatisfiable, contentRange: "bytes */10"},
+		{name: "multiple ranges are ignored", header: map[string]string{"Range": "bytes=0-1,3-4"}, status: http.StatusOK, body: body},
+		{name: "If-Range matches", header: map[string]string{"Range": "bytes=0-0", "If-Range": `"v1"`}, status: http.StatusPartialContent, contentRange: "bytes 0-0/10", body: "0"},
+		{name: "If-Range does not match", header: map[string]string{"Range": "bytes=0-0", "If-Range": `"v0"`}, status: http.StatusOK, body: body},
+		{name: "Range is forwarded on pass", header: map[string]string{"Range": "bytes=0-1", "Pass": "1"}, status: http.StatusPartialContent, contentRange: "bytes 0-1/10", body: "01", forwarded: "bytes=0-1"},
+		{name: "range on pass is enabled", header: map[string]string{"Range": "bytes=8-", "Pass": "1", "Enable-Range": "1"}, status: http.StatusPartialContent, contentRange: "bytes 8-9/10", body: "89"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ip := New(context.WithResolver(resolver.NewStaticResolver("main", vcl)))
+			// Process twice to check the range is also served from the cached object
+			for n := 0; n < 2; n++ {
+				received.Store("")
+				req := httptest.NewRequest(http.MethodGet, "http://localhost/", nil)
+				for k, v := range tt.header {
+					req.Header.Set(k, v)
+				}
+				if err := ip.ProcessInit(req); err != nil {
+					t.Fatalf("Unexpected init error: %s", err)
+				}
+				if err := ip.ProcessRecv(); err != nil {
+					t.Fatalf("Unexpected error: %s", err)
+				}
+				resp := ip.ctx.Response
+				if resp.StatusCode != tt.status {
+					t.Errorf("Expected status %d, got %d", tt.status, resp.StatusCode)
+				}
+				if v := resp.Header.Get("Content-Range"); v != tt.contentRange {
+					t.Errorf("Expected Content-Range %q, got %q", tt.contentRange, v)
+				}
+				got, err := io.ReadAll(resp.Body)
+				if err != nil {
+					t.Fatalf("Unexpected read error: %s", err)
+				}
+				if diff := cmp.Diff(tt.body, string(got)); diff != "" {
+					t.Errorf("Body mismatch, diff=%s", diff)
+				}
+				if n == 0 {
+					if v := received.Load().(string); v != tt.forwarded {
+						t.Errorf("Expected Range header to origin %q, got %q", tt.forwarded, v)
+					}
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,187 @@
+package interpreter
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/ysugimoto/falco/interpreter/context"
+	"github.com/ysugimoto/falco/interpreter/exception"
+)
+
+// Fastly splits the object into 1MB blocks by default
+// see: https://developer.fastly.com/reference/vcl/variables/segmented-caching/segmented-caching-block-size/
+const defaultSegmentBlockSize = 1024 * 1024
+
+// segment is the block of the object which is fetched by the inner request
+type segment struct {
+	resp  *http.Response
+	body  []byte
+	state string
+}
+
+// processSegmentedCaching processes the outer request of segmented caching.
+// The object is split into blocks and each block is fetched through the VCL flow by the inner request with Range header,
+// so that each block is cached individually and the requested range is served from partially cached object.
+func (i *Interpreter) processSegmentedCaching() error {
+	blockSize := i.ctx.SegmentedCacheingBlockSize.Value
+	if blockSize <= 0 {
+		blockSize = defaultSegmentBlockSize
+	}
+	sc := &context.SegmentedCaching{BlockSize: blockSize}
+	i.ctx.SegmentedCaching = sc
+
+	// The first block tells the complete length of the object, the block which includes the range start is fetched first
+	header := i.ctx.Request.Header.Get("Range")
+	startBlock := rangeStartBlock(header, blockSize)
+	first, err := i.fetchSegment(startBlock, blockSize)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	i.ctx.State = first.state
+
+	// Origin which does not support range request responds the entire object, then the range is sliced from it
+	size, ok := completeLength(first.resp)
+	if !ok {
+		i.Debugger.Message("Origin does not respond partial content, segmented caching is not applied")
+		sc.Failed = true
+		sc.Error = fmt.Sprintf("Unexpected status code %d for the segment", first.resp.StatusCode)
+		setResponseBody(first.resp, first.body)
+		i.ctx.Response = first.resp
+		if first.resp.StatusCode == http.StatusOK && header != "" {
+			if err := i.sliceRange(i.ctx.Response, header); err != nil {
+				return errors.WithStack(err)
+			}
+		}
+		return i.ProcessDeliver()
+	}
+	sc.CompleteLength = size
+	sc.TotalBlocks = (size + blockSize - 1) / blockSize
+
+	resp := first.resp
+	resp.StatusCode = http.StatusOK
+	resp.Status = fmt.Sprintf("%d %s", resp.StatusCode, http.StatusText(resp.StatusCode))
+	resp.Header.Del("Content-Range")
+	resp.Header.Set("Accept-Ranges", "bytes")
+	i.ctx.Response = resp
+
+	r, ok := parseRange(header, size)
+	if !ok {
+		notSatisfiable(resp, size)
+		return i.ProcessDeliver()
+	}
+	low, high := int64(0), size-1
+	if r != nil {
+		low, high = r.start, r.end
+	}
+	sc.RangeLow = low / blockSize * blockSize
+	sc.RangeHigh = (high/blockSize+1)*blockSize - 1
+	if sc.RangeHigh >= size {
+		sc.RangeHigh = size - 1
+	}
+
+	// Fetch remaining blocks which cover the range
+	var body bytes.Buffer
+	for block := low / blockSize; block <= high/blockSize; block++ {
+		s := first
+		if block != startBlock {
+			if s, err = i.fetchSegment(block, blockSize); err != nil {
+				return errors.WithStack(err)
+			}
+			if s.resp.StatusCode != http.StatusPartialContent {
+				sc.Failed = true
+				sc.Error = fmt.Sprintf("Unexpected status code %d for the segment %d", s.resp.StatusCode, block)
+				return exception.Runtime(nil, "Segmented caching failed to fetch block %d: %s", block, sc.Error)
+			}
+			if !strings.HasPrefix(s.state, "HIT") {
+				i.ctx.State = s.state
+			}
+		}
+		body.Write(s.body)
+	}
+	sc.Completed = true
+
+	content := body.Bytes()[low-sc.RangeLow : high-sc.RangeLow+1]
+	if r != nil {
+		partialContent(resp, r, size, content)
+	} else {
+		resp.Header.Set("Content-Length", strconv.FormatInt(size, 10))
+		setResponseBody(resp, content)
+	}
+	i.Debugger.Message(fmt.Sprintf(
+		"Segmented caching delivers %d-%d/%d bytes from %d block(s)", low, high, size, high/blockSize-low/blockSize+1,
+	))
+	return i.ProcessDeliver()
+}
+
+// fetchSegment processes the inner request for the block through the VCL flow.
+// The inner request shares the cache with the outer request, and the block is cached individually.
+func (i *Interpreter) fetchSegment(block, blockSize int64) (*segment, error) {
+	req := i.ctx.Request.Clone(i.ctx.Request.Context())
+	start := block * blockSize
+	req.Header.Set("Range", fmt.Sprintf("bytes=%d-%d", start, start+blockSize-1))
+	req.Header.Del("If-Range")
+
+	sub := i.fork()
+	i.Debugger.Message(fmt.Sprintf("Segmented caching inner request for block %d =========>", block))
+	defer i.Debugger.Message(fmt.Sprintf("<========= Segmented caching inner request for block %d finished", block))
+	if err := sub.ProcessInit(req); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	// Inner request is processed on the same server
+	sub.ctx.Server = i.ctx.Server
+	sub.ctx.Client = i.ctx.Client
+	sub.ctx.SegmentedCaching = &context.SegmentedCaching{
+		IsInner:     true,
+		BlockNumber: block,
+		BlockSize:   blockSize,
+	}
+	if err := sub.ProcessRecv(); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	resp := sub.ctx.Response
+	if resp == nil {
+		return nil, exception.System("Segmented caching inner request for block %d has no response", block)
+	}
+	body, err := readResponseBody(resp)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return &segment{resp: resp, body: body, state: sub.ctx.State}, nil
+}
+
+// rangeStartBlock returns the block number which includes the start of the range,
+// suffix range needs the complete length so that starts from the first block
+func rangeStartBlock(header string, blockSize int64) int64 {
+	spec, found := strings.CutPrefix(strings.TrimSpace(header), "bytes=")
+	if !found {
+		return 0
+	}
+	first, _, _ := strings.Cut(spec, "-")
+	start, err := strconv.ParseInt(strings.TrimSpace(first), 10, 64)
+	if err != nil || start < 0 {
+		return 0
+	}
+	return start / blockSize
+}
+
+// completeLength returns the complete length of the object from Content-Range header of the partial content,
+// or the not satisfiable response which is responded for the block beyond the object
+func completeLength(resp *http.Response) (int64, bool) {
+	if resp.StatusCode != http.StatusPartialContent && resp.StatusCode != http.StatusRequestedRangeNotSatisfiable {
+		return 0, false
+	}
+	_, length, found := strings.Cut(resp.Header.Get("Content-Range"), "/")
+	if !found {
+		return 0, false
+	}
+	size, err := strconv.ParseInt(length, 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return size, true
+}
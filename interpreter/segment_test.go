@@ -0,0 +1,108 @@
+package interpreter
+
+import (
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/ysugimoto/falco/interpreter/context"
+	"github.com/ysugimoto/falco/resolver"
+)
+
+func TestSegmentedCaching(t *testing.T) {
+	body := "0123456789abcdefghij"
+	var mu sync.Mutex
+	var fetched []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		fetched = append(fetched, r.Header.Get("Range"))
+		mu.Unlock()
+		w.Header().Set("Cache-Control", "max-age=60")
+		http.ServeContent(w, r, "", time.Time{}, strings.NewReader(body))
+	}))
+	defer server.Close()
+
+	parsed, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatalf("Test server URL parsing error: %s", err)
+	}
+
+	vcl := defaultBackend(parsed) + `
+sub vcl_recv {
+	#FASTLY RECV
+	set req.enable_segmented_caching = true;
+	set segmented_caching.block_size = 8;
+	return(lookup);
+}`
+
+	// Requests are processed in order on the same interpreter, so blocks are cached partially
+	tests := []struct {
+		name         string
+		rangeHeader  string
+		status       int
+		contentRange string
+		body         string
+		fetched      []string
+		state        string
+		rangeLow     int64
+		rangeHigh    int64
+	}{
+		{name: "first block", rangeHeader: "bytes=2-5", status: http.StatusPartialContent, contentRange: "bytes 2-5/20", body: "2345", fetched: []string{"bytes=0-7"}, state: "MISS", rangeLow: 0, rangeHigh: 7},
+		{name: "range across blocks", rangeHeader: "bytes=6-12", status: http.StatusPartialContent, contentRange: "bytes 6-12/20", body: "6789abc", fetched: []string{"bytes=8-15"}, state: "MISS", rangeLow: 0, rangeHigh: 15},
+		{name: "entire object", status: http.StatusOK, body: body, fetched: []string{"bytes=16-23"}, state: "MISS", rangeLow: 0, rangeHigh: 19},
+		{name: "entire object from cached blocks", status: http.StatusOK, body: body, state: "HIT", rangeLow: 0, rangeHigh: 19},
+		{name: "suffix range", rangeHeader: "bytes=-3", status: http.StatusPartialContent, contentRange: "bytes 17-19/20", body: "hij", state: "HIT", rangeLow: 16, rangeHigh: 19},
+		{name: "not satisfiable", rangeHeader: "bytes=30-", status: http.StatusRequestedRangeNotSatisfiable, contentRange: "bytes */20", fetched: []string{"bytes=24-31"}, state: "MISS"},
+	}
+
+	ip := New(context.WithResolver(resolver.NewStaticResolver("main", vcl)))
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fetched = nil
+			req := httptest.NewRequest(http.MethodGet, "http://localhost/", nil)
+			if tt.rangeHeader != "" {
+				req.Header.Set("Range", tt.rangeHeader)
+			}
+			if err := ip.ProcessInit(req); err != nil {
+				t.Fatalf("Unexpected init error: %s", err)
+			}
+			if err := ip.ProcessRecv(); err != nil {
+				t.Fatalf("Unexpected error: %s", err)
+			}
+			resp := ip.ctx.Response
+			if resp.StatusCode != tt.status {
+				t.Errorf("Expected status %d, got %d", tt.status, resp.StatusCode)
+			}
+			if v := resp.Header.Get("Content-Range"); v != tt.contentRange {
+				t.Errorf("Expected Content-Range %q, got %q", tt.contentRange, v)
+			}
+			got, err := io.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatalf("Unexpected read error: %s", err)
+			}
+			if diff := cmp.Diff(tt.body, string(got)); diff != "" {
+				t.Errorf("Body mismatch, diff=%s", diff)
+			}
+			if diff := cmp.Diff(tt.fetched, fetched); diff != "" {
+				t.Errorf("Fetched ranges mismatch, diff=%s", diff)
+			}
+			if ip.ctx.State != tt.state {
+				t.Errorf("Expected state %s, got %s", tt.state, ip.ctx.State)
+			}
+			sc := ip.ctx.SegmentedCaching
+			if sc == nil || sc.IsInner || sc.CompleteLength != 20 || sc.TotalBlocks != 3 {
+				t.Fatalf("Unexpected segmented caching state %+v", sc)
+			}
+			if sc.RangeLow != tt.rangeLow || sc.RangeHigh != tt.rangeHigh {
+				t.Errorf("Expected rounded range %d-%d, got %d-%d", tt.rangeLow, tt.rangeHigh, sc.RangeLow, sc.RangeHigh)
+			}
+		})
+	}
+}
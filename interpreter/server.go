@@ -0,0 +1,42 @@
+package interpreter
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/ysugimoto/falco/config"
+)
+
+// Request headers which override the server configuration per request.
+// They are removed from the request before processing VCL.
+const (
+	HeaderServerDatacenter = "Falco-Server-Datacenter"
+	HeaderServerRegion     = "Falco-Server-Region"
+	HeaderServerHostname   = "Falco-Server-Hostname"
+	HeaderServerPop        = "Falco-Server-Pop"
+	HeaderFFVisits         = "Falco-FF-Visits"
+)
+
+// serverConfig returns the server configuration which is overridden by the request headers
+func serverConfig(base *config.ServerConfig, r *http.Request) *config.ServerConfig {
+	sc := &config.ServerConfig{}
+	if base != nil {
+		*sc = *base
+	}
+	for name, field := range map[string]*string{
+		HeaderServerDatacenter: &sc.Datacenter,
+		HeaderServerRegion:     &sc.Region,
+		HeaderServerHostname:   &sc.Hostname,
+		HeaderServerPop:        &sc.Pop,
+	} {
+		if v := r.Header.Get(name); v != "" {
+			*field = v
+		}
+		r.Header.Del(name)
+	}
+	if v, err := strconv.Atoi(r.Header.Get(HeaderFFVisits)); err == nil {
+		sc.Visits = v
+	}
+	r.Header.Del(HeaderFFVisits)
+	return sc
+}
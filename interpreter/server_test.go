@@ -0,0 +1,87 @@
+package interpreter
+
+import (
+	"testing"
+
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/ysugimoto/falco/config"
+	"github.com/ysugimoto/falco/interpreter/context"
+	"github.com/ysugimoto/falco/resolver"
+)
+
+func TestServerConfig(t *testing.T) {
+	vcl := `
+sub vcl_recv {
+	#FASTLY RECV
+	error 600;
+}
+sub vcl_error {
+	#FASTLY ERROR
+	set obj.http.Datacenter = server.datacenter;
+	set obj.http.Region = server.region;
+	set obj.http.Hostname = server.hostname;
+	set obj.http.Pop = server.pop;
+	set obj.http.Visits = fastly.ff.visits_this_service;
+	set obj.http.Header = if(req.http.Falco-Server-Region, "1", "0");
+	return(deliver);
+}`
+	tests := []struct {
+		name   string
+		server *config.ServerConfig
+		header http.Header
+		expect map[string]string
+	}{
+		{
+			name: "default values",
+			expect: map[string]string{
+				"Datacenter": "FALCO", "Region": "US", "Hostname": "cache-localsimulator", "Pop": "FALCO", "Visits": "0",
+			},
+		},
+		{
+			name:   "configured values",
+			server: &config.ServerConfig{Datacenter: "NRT", Region: "APAC", Hostname: "cache-nrt1", Visits: 1},
+			expect: map[string]string{
+				"Datacenter": "NRT", "Region": "APAC", "Hostname": "cache-nrt1", "Pop": "NRT", "Visits": "1",
+			},
+		},
+		{
+			name:   "request headers override configured values",
+			server: &config.ServerConfig{Datacenter: "NRT", Region: "APAC"},
+			header: http.Header{
+				HeaderServerRegion: {"EU"},
+				HeaderServerPop:    {"LHR"},
+				HeaderFFVisits:     {"2"},
+			},
+			expect: map[string]string{
+				"Datacenter": "NRT", "Region": "EU", "Hostname": "cache-localsimulator", "Pop": "LHR", "Visits": "2",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ip := New(
+				context.WithResolver(resolver.NewStaticResolver("main", vcl)),
+				context.WithServer(tt.server),
+			)
+			req := httptest.NewRequest(http.MethodGet, "http://localhost", nil)
+			for k, v := range tt.header {
+				req.Header.Set(k, v[0])
+			}
+			ip.ServeHTTP(httptest.NewRecorder(), req)
+			if ip.process.Error != nil {
+				t.Fatalf("Unexpected error: %s", ip.process.Error)
+			}
+			for name, v := range tt.expect {
+				if got := ip.ctx.Response.Header.Get(name); got != v {
+					t.Errorf("Expected %s to be %s, got %s", name, v, got)
+				}
+			}
+			if got := ip.ctx.Response.Header.Get("Header"); got != "0" {
+				t.Errorf("Expected override headers to be removed from the request")
+			}
+		})
+	}
+}
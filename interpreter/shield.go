@@ -0,0 +1,117 @@
+package interpreter
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+	"github.com/ysugimoto/falco/interpreter/cache"
+	"github.com/ysugimoto/falco/interpreter/context"
+	"github.com/ysugimoto/falco/interpreter/exception"
+	"github.com/ysugimoto/falco/interpreter/value"
+)
+
+// shieldPOPs holds the cache of each shield POP, which is separated from the edge POP cache
+type shieldPOPs struct {
+	mu     sync.Mutex
+	caches map[string]*cache.Cache
+}
+
+func newShieldPOPs() *shieldPOPs {
+	return &shieldPOPs{
+		caches: make(map[string]*cache.Cache),
+	}
+}
+
+func (s *shieldPOPs) cache(pop string) *cache.Cache {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c, ok := s.caches[pop]
+	if !ok {
+		c = cache.New()
+		s.caches[pop] = c
+	}
+	return c
+}
+
+func (s *shieldPOPs) all() []*cache.Cache {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	caches := make([]*cache.Cache, 0, len(s.caches))
+	for _, c := range s.caches {
+		caches = append(caches, c)
+	}
+	return caches
+}
+
+// createShieldRequest creates the backend request which is forwarded to the shield POP of the director.
+// The request has Fastly-FF header so that VCL could know the request comes from Fastly node.
+// see: https://developer.fastly.com/learning/concepts/shielding/
+func (i *Interpreter) createShieldRequest(ctx *context.Context, dc *value.DirectorConfig) (*http.Request, error) {
+	if dc.Shield == "" {
+		return nil, exception.Runtime(nil, "Shield director '%s' does not have .shield property", dc.Name)
+	}
+	if strings.EqualFold(ctx.ShieldPOP, dc.Shield) {
+		return nil, exception.Runtime(
+			nil,
+			"Shield director '%s' is selected on its own shield POP %s, the request must be sent to the origin",
+			dc.Name, dc.Shield,
+		)
+	}
+
+	i.Debugger.Message(fmt.Sprintf("Fetching shield POP (%s) %s", dc.Shield, ctx.Request.URL.String()))
+	req := ctx.Request.Clone(ctx.Request.Context())
+	req.Header.Add("Fastly-FF", ctx.Identity())
+	return req, nil
+}
+
+// sendShieldRequest processes the backend request through the VCL flow on the shield POP,
+// and returns the response which the shield POP delivers as the backend response
+func (i *Interpreter) sendShieldRequest(dc *value.DirectorConfig) (*http.Response, error) {
+	shield := i.fork()
+	shield.cache = i.shields.cache(strings.ToLower(dc.Shield))
+
+	req := i.ctx.BackendRequest.Clone(i.ctx.Request.Context())
+	i.Debugger.Message(fmt.Sprintf("Shield request %s on %s =========>", req.URL.String(), dc.Shield))
+	defer i.Debugger.Message(fmt.Sprintf("<========= Shield request %s on %s finished", req.URL.String(), dc.Shield))
+
+	if err := shield.ProcessInit(req); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	shield.ctx.ShieldPOP = dc.Shield
+	shield.ctx.Server = i.ctx.Server
+	shield.ctx.Client = i.ctx.Client
+	if err := shield.ProcessRecv(); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	resp := shield.ctx.Response
+	if resp == nil {
+		return nil, exception.System("Shield POP %s has no response", dc.Shield)
+	}
+	var buf bytes.Buffer
+	if _, err := buf.ReadFrom(resp.Body); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(buf.Bytes()))
+	return resp, nil
+}
+
+// caches returns the edge POP cache and all shield POP caches
+func (i *Interpreter) caches() []*cache.Cache {
+	return append([]*cache.Cache{i.cache}, i.shields.all()...)
+}
+
+// appendHeaderValue appends the value to the comma separated header value
+func appendHeaderValue(h http.Header, name, v string) {
+	if prev := h.Get(name); prev != "" {
+		v = prev + ", " + v
+	}
+	h.Set(name, v)
+}
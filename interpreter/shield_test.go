@@ -0,0 +1,107 @@
+package interpreter
+
+import (
+	"testing"
+
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/ysugimoto/falco/interpreter/cache"
+	"github.com/ysugimoto/falco/interpreter/context"
+	"github.com/ysugimoto/falco/resolver"
+)
+
+func TestShield(t *testing.T) {
+	var fetched int
+	var origin http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetched++
+		origin = r.Header.Clone()
+		w.Header().Set("Cache-Control", "max-age=60")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("OK")) // nolint:errcheck
+	}))
+	defer server.Close()
+
+	parsed, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatalf("Test server URL parsing error: %s", err)
+	}
+
+	ip := New(context.WithResolver(resolver.NewStaticResolver("main", defaultBackend(parsed)+`
+director ssl_shield_iad_va_us shield {
+	.shield = "iad-va-us";
+	.is_ssl = true;
+}
+sub vcl_recv {
+	#FASTLY RECV
+	if (server.identity !~ "-IAD$" && req.http.Fastly-FF !~ "-IAD") {
+		set req.backend = ssl_shield_iad_va_us;
+	}
+	return(lookup);
+}
+sub vcl_miss {
+	#FASTLY MISS
+	set bereq.http.X-Visits = fastly.ff.visits_this_service;
+	set bereq.http.X-Is-Shield = if(req.backend.is_shield, "1", "0");
+	set bereq.http.X-Is-Origin = if(req.backend.is_origin, "1", "0");
+	return(fetch);
+}
+sub vcl_deliver {
+	#FASTLY DELIVER
+	add resp.http.X-Datacenter = server.datacenter;
+	return(deliver);
+}`)))
+	get := func(method string) http.Header {
+		ip.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(method, "http://localhost/a", nil))
+		if ip.process.Error != nil {
+			t.Fatalf("Unexpected error: %s", ip.process.Error)
+		}
+		return ip.ctx.Response.Header
+	}
+
+	h := get(http.MethodGet)
+	if v := h.Get("X-Cache"); v != "MISS, MISS" {
+		t.Errorf("Expected X-Cache MISS, MISS, got %s", v)
+	}
+	if v := h.Get("X-Served-By"); v != "cache-localsimulator-IAD, cache-localsimulator-FALCO" {
+		t.Errorf("Unexpected X-Served-By %s", v)
+	}
+	if diff := cmp.Diff([]string{"IAD", "FALCO"}, h.Values("X-Datacenter")); diff != "" {
+		t.Errorf("Datacenter mismatch, diff=%s", diff)
+	}
+	expect := map[string]string{
+		"Fastly-FF":   "cache-localsimulator",
+		"X-Visits":    "2",
+		"X-Is-Shield": "0",
+		"X-Is-Origin": "1",
+	}
+	for name, v := range expect {
+		if origin.Get(name) != v {
+			t.Errorf("Expected origin request header %s to be %s, got %s", name, v, origin.Get(name))
+		}
+	}
+
+	// Edge POP misses but shield POP hits
+	ip.cache = cache.New()
+	if v := get(http.MethodGet).Get("X-Cache"); v != "HIT, MISS" {
+		t.Errorf("Expected X-Cache HIT, MISS, got %s", v)
+	}
+	if v := get(http.MethodGet).Get("X-Cache"); v != "HIT, HIT" {
+		t.Errorf("Expected X-Cache HIT, HIT, got %s", v)
+	}
+	if fetched != 1 {
+		t.Errorf("Expected origin fetched once, got %d", fetched)
+	}
+
+	// Purge is propagated to the shield POP
+	get("PURGE")
+	if v := get(http.MethodGet).Get("X-Cache"); v != "MISS, MISS" {
+		t.Errorf("Expected X-Cache MISS, MISS after purge, got %s", v)
+	}
+	if fetched != 2 {
+		t.Errorf("Expected origin fetched twice, got %d", fetched)
+	}
+}
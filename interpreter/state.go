@@ -13,6 +13,7 @@ const (
 	FETCH          State = "fetch"
 	DELIVER_STALE  State = "deliver_stale"
 	LOG            State = "log"
+	UPGRADE        State = "upgrade"
 	END            State = "end"
 	INTERNAL_ERROR State = "_internal_error_"
 	BARE_RETURN    State = "_bare_return_"
@@ -38,6 +39,8 @@ func (s State) String() string {
 		return "deliver_stale"
 	case LOG:
 		return "log"
+	case UPGRADE:
+		return "upgrade"
 	case END:
 		return "end"
 	case INTERNAL_ERROR:
@@ -59,5 +62,6 @@ var stateMap = map[string]State{
 	"fetch":         FETCH,
 	"deliver_stale": DELIVER_STALE,
 	"log":           LOG,
+	"upgrade":       UPGRADE,
 	"end":           END,
 }
@@ -15,6 +15,7 @@ import (
 func (i *Interpreter) ProcessTestSubroutine(scope context.Scope, sub *ast.SubroutineDeclaration) error {
 	i.SetScope(scope)
 	if _, err := i.ProcessSubroutine(sub, DebugPass); err != nil {
+		i.traceInclude(err)
 		return errors.WithStack(err)
 	}
 	return nil
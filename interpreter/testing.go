@@ -7,6 +7,7 @@ import (
 	"strings"
 
 	"github.com/pkg/errors"
+	icontext "github.com/ysugimoto/falco/interpreter/context"
 )
 
 const testBackendResponseBody = "falco_test_response"
@@ -40,3 +41,8 @@ func (i *Interpreter) TestProcessInit(r *http.Request) error {
 	i.ctx.Object = i.cloneResponse(i.ctx.BackendResponse)
 	return nil
 }
+
+// TestRandomSeed seeds random values in the current test case to be reproducible
+func (i *Interpreter) TestRandomSeed(seed int64) {
+	i.ctx.Random = icontext.NewRandom(seed)
+}
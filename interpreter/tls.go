@@ -0,0 +1,127 @@
+package interpreter
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+
+	"github.com/pkg/errors"
+	"github.com/ysugimoto/falco/ast"
+	"github.com/ysugimoto/falco/interpreter/exception"
+	"github.com/ysugimoto/falco/interpreter/value"
+)
+
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// backendTLSConfig creates TLS configuration from ssl_* and *_tls_version properties of the backend.
+// see: https://developer.fastly.com/reference/vcl/declarations/backend/
+func (i *Interpreter) backendTLSConfig(backend *value.Backend, hostname string) (*tls.Config, error) {
+	props := map[string]string{}
+	for _, key := range []string{
+		"ssl_sni_hostname",
+		"ssl_cert_hostname",
+		"min_tls_version",
+		"max_tls_version",
+		"ssl_ca_cert",
+		"ssl_client_cert",
+		"ssl_client_key",
+	} {
+		v, err := i.getBackendProperty(backend.Value.Properties, key)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		} else if v != nil {
+			props[key] = value.Unwrap[*value.String](v).Value
+		}
+	}
+
+	c := &tls.Config{
+		ServerName: hostname,
+	}
+	if v, ok := props["ssl_sni_hostname"]; ok {
+		c.ServerName = v
+	}
+
+	for key, dst := range map[string]*uint16{
+		"min_tls_version": &c.MinVersion,
+		"max_tls_version": &c.MaxVersion,
+	} {
+		v, ok := props[key]
+		if !ok {
+			continue
+		}
+		version, ok := tlsVersions[v]
+		if !ok {
+			return nil, exception.Runtime(nil, "Invalid %s '%s' for backend %s", key, v, backend)
+		}
+		*dst = version
+	}
+
+	// Client certificate for mutual TLS
+	cert, hasCert := props["ssl_client_cert"]
+	key, hasKey := props["ssl_client_key"]
+	if hasCert != hasKey {
+		return nil, exception.Runtime(nil, "Both ssl_client_cert and ssl_client_key must be specified for backend %s", backend)
+	} else if hasCert {
+		pair, err := tls.X509KeyPair([]byte(cert), []byte(key))
+		if err != nil {
+			return nil, exception.Runtime(nil, "Failed to load client certificate for backend %s: %s", backend, err)
+		}
+		c.Certificates = []tls.Certificate{pair}
+	}
+
+	// ssl_check_cert = never skips the verification of the origin certificate
+	if checkCert := backendIdentProperty(backend.Value.Properties, "ssl_check_cert"); checkCert == "never" {
+		c.InsecureSkipVerify = true // nolint:gosec
+		return c, nil
+	}
+
+	var roots *x509.CertPool
+	if v, ok := props["ssl_ca_cert"]; ok {
+		roots = x509.NewCertPool()
+		if !roots.AppendCertsFromPEM([]byte(v)) {
+			return nil, exception.Runtime(nil, "Failed to load ssl_ca_cert for backend %s", backend)
+		}
+	}
+	certHostname, ok := props["ssl_cert_hostname"]
+	if !ok {
+		c.RootCAs = roots
+		return c, nil
+	}
+
+	// The certificate is verified with ssl_cert_hostname which may differ from SNI hostname,
+	// so skip the default verification and verify the chain manually
+	c.InsecureSkipVerify = true // nolint:gosec
+	c.VerifyConnection = func(cs tls.ConnectionState) error {
+		if len(cs.PeerCertificates) == 0 {
+			return errors.New("Origin does not present any certificate")
+		}
+		opts := x509.VerifyOptions{
+			DNSName:       certHostname,
+			Roots:         roots,
+			Intermediates: x509.NewCertPool(),
+		}
+		for _, cert := range cs.PeerCertificates[1:] {
+			opts.Intermediates.AddCert(cert)
+		}
+		_, err := cs.PeerCertificates[0].Verify(opts)
+		return err
+	}
+	return c, nil
+}
+
+// backendIdentProperty returns the identifier value of the backend property like .ssl_check_cert = always;
+func backendIdentProperty(props []*ast.BackendProperty, key string) string {
+	for _, v := range props {
+		if v.Key.Value != key {
+			continue
+		}
+		if ident, ok := v.Value.(*ast.Ident); ok {
+			return ident.Value
+		}
+	}
+	return ""
+}
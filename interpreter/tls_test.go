@@ -0,0 +1,178 @@
+package interpreter
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"testing"
+	"time"
+
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+
+	"github.com/ysugimoto/falco/interpreter/context"
+	"github.com/ysugimoto/falco/resolver"
+)
+
+func TestBackendTLS(t *testing.T) {
+	// Client certificate for mutual TLS
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %s", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "falco"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("Failed to create certificate: %s", err)
+	}
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("Failed to marshal key: %s", err)
+	}
+	clientCert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("Failed to parse certificate: %s", err)
+	}
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(clientCert)
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("SNI", r.TLS.ServerName)
+		w.WriteHeader(http.StatusOK)
+	}))
+	server.TLS = &tls.Config{
+		ClientAuth: tls.RequireAndVerifyClientCert,
+		ClientCAs:  clientCAs,
+		MaxVersion: tls.VersionTLS12,
+	}
+	server.StartTLS()
+	defer server.Close()
+
+	parsed, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatalf("Test server URL parsing error: %s", err)
+	}
+	pemString := func(typ string, b []byte) string {
+		return string(pem.EncodeToMemory(&pem.Block{Type: typ, Bytes: b}))
+	}
+	caCert := pemString("CERTIFICATE", server.Certificate().Raw)
+	clientCertPEM := pemString("CERTIFICATE", der)
+	clientKeyPEM := pemString("EC PRIVATE KEY", keyDer)
+
+	tests := []struct {
+		name       string
+		properties string
+		status     int
+		sni        string
+	}{
+		{
+			name:       "pinned CA, certificate hostname and client certificate",
+			properties: `.ssl_cert_hostname = "example.com"; .ssl_sni_hostname = "origin.example.com"; .ssl_ca_cert = {"` + caCert + `"}; .ssl_client_cert = {"` + clientCertPEM + `"}; .ssl_client_key = {"` + clientKeyPEM + `"};`,
+			status:     http.StatusOK,
+			sni:        "origin.example.com",
+		},
+		{
+			name:       "certificate hostname mismatch",
+			properties: `.ssl_cert_hostname = "example.org"; .ssl_ca_cert = {"` + caCert + `"}; .ssl_client_cert = {"` + clientCertPEM + `"}; .ssl_client_key = {"` + clientKeyPEM + `"};`,
+			status:     http.StatusServiceUnavailable,
+		},
+		{
+			name:       "client certificate is not presented",
+			properties: `.ssl_cert_hostname = "example.com"; .ssl_ca_cert = {"` + caCert + `"};`,
+			status:     http.StatusServiceUnavailable,
+		},
+		{
+			name:       "minimum TLS version is not supported by origin",
+			properties: `.ssl_check_cert = never; .min_tls_version = "1.3"; .ssl_client_cert = {"` + clientCertPEM + `"}; .ssl_client_key = {"` + clientKeyPEM + `"};`,
+			status:     http.StatusServiceUnavailable,
+		},
+		{
+			name:       "skip certificate check",
+			properties: `.ssl_check_cert = never; .ssl_client_cert = {"` + clientCertPEM + `"}; .ssl_client_key = {"` + clientKeyPEM + `"};`,
+			status:     http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vcl := fmt.Sprintf(`
+backend F_origin {
+	.host = "%s";
+	.port = "%s";
+	.ssl = true;
+	%s
+}
+sub vcl_recv {
+	#FASTLY RECV
+	return(pass);
+}`, parsed.Hostname(), parsed.Port(), tt.properties)
+			ip := New(context.WithResolver(resolver.NewStaticResolver("main", vcl)))
+			ip.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "http://localhost/", nil))
+			if ip.process.Error != nil {
+				t.Fatalf("Unexpected error: %s", ip.process.Error)
+			}
+			if ip.ctx.Response.StatusCode != tt.status {
+				t.Errorf("Expected status %d, got %d", tt.status, ip.ctx.Response.StatusCode)
+			}
+			if tt.sni != "" && ip.ctx.Response.Header.Get("SNI") != tt.sni {
+				t.Errorf("Expected SNI %s, got %s", tt.sni, ip.ctx.Response.Header.Get("SNI"))
+			}
+		})
+	}
+}
+
+func TestTLSRequest(t *testing.T) {
+	vcl := `
+sub vcl_recv {
+	#FASTLY RECV
+	set req.http.Protocol = req.protocol;
+	set req.http.Servername = tls.client.servername;
+	set req.http.TLS-Protocol = tls.client.protocol;
+	error 600;
+}
+sub vcl_error {
+	#FASTLY ERROR
+	set obj.http.Protocol = req.http.Protocol;
+	set obj.http.Servername = req.http.Servername;
+	set obj.http.TLS-Protocol = req.http.TLS-Protocol;
+	return(deliver);
+}`
+	tests := []struct {
+		url    string
+		expect map[string]string
+	}{
+		{
+			url:    "http://localhost/",
+			expect: map[string]string{"Protocol": "http", "Servername": "", "TLS-Protocol": ""},
+		},
+		{
+			url:    "https://localhost/",
+			expect: map[string]string{"Protocol": "https", "Servername": "localhost", "TLS-Protocol": "TLSv1.2"},
+		},
+	}
+	for _, tt := range tests {
+		ip := New(context.WithResolver(resolver.NewStaticResolver("main", vcl)))
+		ip.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, tt.url, nil))
+		if ip.process.Error != nil {
+			t.Fatalf("Unexpected error: %s", ip.process.Error)
+		}
+		for key, expect := range tt.expect {
+			if v := ip.ctx.Response.Header.Get(key); v != expect {
+				t.Errorf("%s: expected %s to be %q, got %q", tt.url, key, expect, v)
+			}
+		}
+	}
+}
@@ -5,9 +5,10 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"sync/atomic"
 	"time"
 
-	"crypto/tls"
+	"net"
 	"net/http"
 
 	"github.com/gobwas/glob"
@@ -22,6 +23,18 @@ import (
 
 const HTTPS_SCHEME = "https"
 
+// backendFailure is the error that the backend could not respond.
+// Fastly does not run vcl_fetch for the failure and moves to vcl_error with 503 status.
+type backendFailure struct {
+	code     string // fastly.error value
+	response string // obj.response value
+	err      error
+}
+
+func (e *backendFailure) Error() string {
+	return fmt.Sprintf("Failed to retrieve backend response: %s", e.err)
+}
+
 func getOverrideBackend(ctx *icontext.Context, backendName string) (*config.OverrideBackend, error) {
 	for key, val := range ctx.OverrideBackends {
 		p, err := glob.Compile(key)
@@ -36,10 +49,11 @@ func getOverrideBackend(ctx *icontext.Context, backendName string) (*config.Over
 	return nil, nil
 }
 
-func (i *Interpreter) createBackendRequest(ctx *icontext.Context, backend *value.Backend) (*http.Request, error) {
+// backendOrigin returns the origin URL like "https://example.com:443" and the host of the backend
+func (i *Interpreter) backendOrigin(ctx *icontext.Context, backend *value.Backend) (string, string, error) {
 	var port string
 	if v, err := i.getBackendProperty(backend.Value.Properties, "port"); err != nil {
-		return nil, errors.WithStack(err)
+		return "", "", errors.WithStack(err)
 	} else if v != nil {
 		port = value.Unwrap[*value.String](v).Value
 	}
@@ -47,7 +61,7 @@ func (i *Interpreter) createBackendRequest(ctx *icontext.Context, backend *value
 	// Get override backend host from configuration
 	overrideBackend, err := getOverrideBackend(ctx, backend.Value.Name.Value)
 	if err != nil {
-		return nil, errors.WithStack(err)
+		return "", "", errors.WithStack(err)
 	}
 
 	// scheme may be overrided by config
@@ -58,7 +72,7 @@ func (i *Interpreter) createBackendRequest(ctx *icontext.Context, backend *value
 		}
 	} else {
 		if v, err := i.getBackendProperty(backend.Value.Properties, "ssl"); err != nil {
-			return nil, errors.WithStack(err)
+			return "", "", errors.WithStack(err)
 		} else if v != nil {
 			if value.Unwrap[*value.Boolean](v).Value {
 				scheme = HTTPS_SCHEME
@@ -72,21 +86,14 @@ func (i *Interpreter) createBackendRequest(ctx *icontext.Context, backend *value
 		host = overrideBackend.Host
 	} else {
 		if v, err := i.getBackendProperty(backend.Value.Properties, "host"); err != nil {
-			return nil, errors.WithStack(err)
+			return "", "", errors.WithStack(err)
 		} else if v != nil {
 			host = value.Unwrap[*value.String](v).Value
 		} else {
-			return nil, exception.Runtime(nil, "Failed to find host for backend %s", backend)
+			return "", "", exception.Runtime(nil, "Failed to find host for backend %s", backend)
 		}
 	}
 
-	var alwaysHost bool
-	if v, err := i.getBackendProperty(backend.Value.Properties, "always_use_host_header"); err != nil {
-		return nil, errors.WithStack(err)
-	} else if v != nil {
-		alwaysHost = value.Unwrap[*value.Boolean](v).Value
-	}
-
 	if port == "" {
 		if scheme == HTTPS_SCHEME {
 			port = "443"
@@ -94,8 +101,38 @@ func (i *Interpreter) createBackendRequest(ctx *icontext.Context, backend *value
 			port = "80"
 		}
 	}
+	return fmt.Sprintf("%s://%s:%s", scheme, host, port), host, nil
+}
+
+func (i *Interpreter) createBackendRequest(ctx *icontext.Context, backend *value.Backend) (*http.Request, error) {
+	origin, host, err := i.backendOrigin(ctx, backend)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	ctx.OriginBackend = backend
 
-	url := fmt.Sprintf("%s://%s:%s%s", scheme, host, port, i.ctx.Request.URL.Path)
+	// Backend timeouts could be overridden by bereq variables in vcl_miss and vcl_pass
+	timeouts := map[string]*value.RTime{
+		"connect_timeout":       ctx.ConnectTimeout,
+		"first_byte_timeout":    ctx.FirstByteTimeout,
+		"between_bytes_timeout": ctx.BetweenBytesTimeout,
+	}
+	for key, timeout := range timeouts {
+		if v, err := i.getBackendProperty(backend.Value.Properties, key); err != nil {
+			return nil, errors.WithStack(err)
+		} else if v != nil {
+			timeout.Value = value.Unwrap[*value.RTime](v).Value
+		}
+	}
+
+	var alwaysHost bool
+	if v, err := i.getBackendProperty(backend.Value.Properties, "always_use_host_header"); err != nil {
+		return nil, errors.WithStack(err)
+	} else if v != nil {
+		alwaysHost = value.Unwrap[*value.Boolean](v).Value
+	}
+
+	url := origin + i.ctx.Request.URL.Path
 	query := i.ctx.Request.URL.Query()
 	if v := query.Encode(); v != "" {
 		url += "?" + v
@@ -103,7 +140,7 @@ func (i *Interpreter) createBackendRequest(ctx *icontext.Context, backend *value
 
 	// Debug message
 	var suffix string
-	if overrideBackend != nil {
+	if v, _ := getOverrideBackend(ctx, backend.Value.Name.Value); v != nil {
 		suffix = " (overrided by config)"
 	}
 	i.Debugger.Message(
@@ -127,52 +164,134 @@ func (i *Interpreter) createBackendRequest(ctx *icontext.Context, backend *value
 }
 
 func (i *Interpreter) sendBackendRequest(backend *value.Backend) (*http.Response, error) {
-	fbt, err := i.getBackendProperty(backend.Value.Properties, "first_byte_timeout")
-	if err != nil {
-		return nil, errors.WithStack(err)
+	if backend.IsShield() {
+		return i.sendShieldRequest(backend.Director)
 	}
 
-	firstByteTimeout := 15 * time.Second
-	if fbt != nil {
-		firstByteTimeout = value.Unwrap[*value.RTime](fbt).Value
-	}
-
-	ctx, timeout := context.WithTimeout(i.ctx.Request.Context(), firstByteTimeout)
-	defer timeout()
-
+	ctx, cancel := context.WithCancel(i.ctx.Request.Context())
 	req := i.ctx.BackendRequest.Clone(ctx)
 
 	// Check Fastly limitations
-	if err := limitations.CheckFastlyRequestLimit(req); err != nil {
+	err := limitations.CheckFastlyRequestLimit(req)
+	if err != nil {
+		cancel()
 		return nil, errors.WithStack(err)
 	}
 
-	client := http.DefaultClient
+	// Respond the stubbed response without sending the request to the origin if the backend mock matches
+	origin := i.ctx.OriginBackend
+	if origin == nil {
+		origin = backend
+	}
+	if mock, err := findBackendMock(i.ctx, origin.Value.Name.Value, req); err != nil {
+		cancel()
+		return nil, errors.WithStack(err)
+	} else if mock != nil {
+		defer cancel()
+		resp, err := i.mockBackendResponse(mock, req)
+		if err != nil {
+			return nil, err
+		}
+		i.Debugger.Message(fmt.Sprintf("Backend (%s) is mocked, responds status code %d", origin, resp.StatusCode))
+		return resp, nil
+	}
+
+	transport := &http.Transport{
+		DialContext: (&net.Dialer{
+			Timeout: i.ctx.ConnectTimeout.Value,
+		}).DialContext,
+		ResponseHeaderTimeout: i.ctx.FirstByteTimeout.Value,
+		// Keep the encoded response as it is like Fastly does
+		DisableCompression: true,
+	}
+	// The connection is released when the response body is closed
+	release := func() {
+		cancel()
+		transport.CloseIdleConnections()
+	}
 	if req.URL.Scheme == HTTPS_SCHEME {
-		client = &http.Client{
-			Transport: &http.Transport{
-				TLSClientConfig: &tls.Config{
-					ServerName: req.URL.Hostname(),
-				},
-			},
+		if transport.TLSClientConfig, err = i.backendTLSConfig(origin, req.URL.Hostname()); err != nil {
+			release()
+			return nil, errors.WithStack(err)
 		}
 	}
-	resp, err := client.Do(req)
+
+	resp, err := (&http.Client{Transport: transport}).Do(req)
 	if err != nil {
-		return nil, exception.Runtime(nil, "Failed to retrieve backend response: %s", err)
+		release()
+		var opErr *net.OpError
+		var netErr net.Error
+		switch {
+		case errors.As(err, &opErr) && opErr.Op == "dial" && opErr.Timeout():
+			return nil, &backendFailure{code: "ERR_CONNECT_TIMEOUT", response: "Connection timed out", err: err}
+		case errors.As(err, &netErr) && netErr.Timeout():
+			return nil, &backendFailure{code: "ERR_FIRST_BYTE_TIMEOUT", response: "first byte timeout", err: err}
+		}
+		return nil, &backendFailure{code: "ERR_CONNECT", response: "Backend unavailable, connection failed", err: err}
 	}
 
 	// Debug message
-	i.Debugger.Message(fmt.Sprintf("Backend (%s) responds status code %d", backend.Value.Name.Value, resp.StatusCode))
+	i.Debugger.Message(fmt.Sprintf("Backend (%s) responds status code %d", backend, resp.StatusCode))
 
-	// read all response body to suppress memory leak
-	var buf bytes.Buffer
-	if _, err = buf.ReadFrom(resp.Body); err != nil {
-		return nil, errors.WithStack(err)
+	// Response body is not read here, it is buffered after vcl_fetch unless beresp.do_stream is enabled
+	resp.Body = &backendBody{
+		body:    resp.Body,
+		timeout: i.ctx.BetweenBytesTimeout.Value,
+		release: release,
+	}
+	return resp, nil
+}
+
+// backendBody is the backend response body which is read from the origin on demand.
+// The request is canceled when the next bytes do not arrive within between_bytes_timeout.
+type backendBody struct {
+	body     io.ReadCloser
+	timeout  time.Duration
+	timer    *time.Timer
+	timedOut atomic.Bool
+	release  func()
+}
+
+func (b *backendBody) Read(p []byte) (int, error) {
+	if b.timer == nil && b.timeout > 0 {
+		b.timer = time.AfterFunc(b.timeout, func() {
+			b.timedOut.Store(true)
+			b.release()
+		})
+	}
+
+	n, err := b.body.Read(p)
+	if n > 0 && b.timer != nil {
+		b.timer.Reset(b.timeout)
+	}
+	if err != nil && b.timer != nil {
+		b.timer.Stop()
 	}
+	if err != nil && err != io.EOF && b.timedOut.Load() {
+		return n, &backendFailure{code: "ERR_BETWEEN_BYTES_TIMEOUT", response: "between bytes timeout", err: err}
+	}
+	return n, err
+}
+
+func (b *backendBody) Close() error {
+	if b.timer != nil {
+		b.timer.Stop()
+	}
+	err := b.body.Close()
+	b.release()
+	return err
+}
+
+// bufferBackendResponse reads all backend response body to deliver it after the origin finishes responding
+func bufferBackendResponse(resp *http.Response) error {
+	var buf bytes.Buffer
+	_, err := buf.ReadFrom(resp.Body)
 	resp.Body.Close()
+	if err != nil {
+		return errors.WithStack(err)
+	}
 	resp.Body = io.NopCloser(bytes.NewReader(buf.Bytes()))
-	return resp, nil
+	return nil
 }
 
 func (i *Interpreter) getBackendProperty(props []*ast.BackendProperty, key string) (value.Value, error) {
@@ -195,6 +314,45 @@ func (i *Interpreter) getBackendProperty(props []*ast.BackendProperty, key strin
 	return val, nil
 }
 
+// streamResponse creates the client response which takes over the streamed body of the backend response
+// so that the body is read from the origin while delivering without buffering
+func (i *Interpreter) streamResponse(resp *http.Response) *http.Response {
+	v := *resp
+	v.Header = resp.Header.Clone()
+	v.Trailer = resp.Trailer.Clone()
+	resp.Body = http.NoBody
+	return &v
+}
+
+// cacheFillBody passes through the streamed body, and calls done with the whole body when it is read to the end
+type cacheFillBody struct {
+	io.ReadCloser
+	buf  bytes.Buffer
+	done func([]byte)
+}
+
+func (b *cacheFillBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.buf.Write(p[:n])
+	if err == io.EOF && b.done != nil {
+		b.done(b.buf.Bytes())
+		b.done = nil
+	}
+	return n, err
+}
+
+// countingWriter counts the bytes which are written through it
+type countingWriter struct {
+	io.Writer
+	n int64
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	n, err := w.Writer.Write(p)
+	w.n += int64(n)
+	return n, err
+}
+
 func (i *Interpreter) cloneResponse(resp *http.Response) *http.Response {
 	// rewind body reader
 	var buf bytes.Buffer
@@ -0,0 +1,235 @@
+package interpreter
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/ysugimoto/falco/interpreter/context"
+	"github.com/ysugimoto/falco/resolver"
+)
+
+func TestBackendTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/first_byte":
+			time.Sleep(200 * time.Millisecond)
+			w.WriteHeader(http.StatusOK)
+		case "/between_bytes":
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte("partial")) // nolint:errcheck
+			w.(http.Flusher).Flush()
+			time.Sleep(200 * time.Millisecond)
+			w.Write([]byte("body")) // nolint:errcheck
+		default:
+			w.WriteHeader(http.StatusOK)
+		}
+	}))
+	defer server.Close()
+
+	parsed, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatalf("Test server URL parsing error: %s", err)
+	}
+
+	vcl := fmt.Sprintf(`
+backend F_origin {
+	.host = "%s";
+	.port = "%s";
+	.first_byte_timeout = 50ms;
+	.between_bytes_timeout = 50ms;
+}
+sub vcl_recv {
+	#FASTLY RECV
+	return(pass);
+}
+sub vcl_pass {
+	#FASTLY PASS
+	if (req.http.Extend) {
+		set bereq.first_byte_timeout = 1s;
+		set bereq.between_bytes_timeout = 1s;
+	}
+	return(pass);
+}
+sub vcl_error {
+	#FASTLY ERROR
+	set obj.http.X-Error = fastly.error;
+	set obj.http.X-Response = obj.response;
+	return(deliver);
+}`, parsed.Hostname(), parsed.Port())
+
+	tests := []struct {
+		name     string
+		path     string
+		extend   bool
+		status   int
+		error    string
+		response string
+	}{
+		{name: "first byte timeout", path: "/first_byte", status: http.StatusServiceUnavailable, error: "ERR_FIRST_BYTE_TIMEOUT", response: "first byte timeout"},
+		{name: "between bytes timeout", path: "/between_bytes", status: http.StatusServiceUnavailable, error: "ERR_BETWEEN_BYTES_TIMEOUT", response: "between bytes timeout"},
+		{name: "first byte timeout extended by bereq", path: "/first_byte", extend: true, status: http.StatusOK},
+		{name: "between bytes timeout extended by bereq", path: "/between_bytes", extend: true, status: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ip := New(context.WithResolver(resolver.NewStaticResolver("main", vcl)))
+			req := httptest.NewRequest(http.MethodGet, "http://localhost"+tt.path, nil)
+			if tt.extend {
+				req.Header.Set("Extend", "1")
+			}
+			ip.ServeHTTP(httptest.NewRecorder(), req)
+			if ip.process.Error != nil {
+				t.Fatalf("Unexpected error: %s", ip.process.Error)
+			}
+			resp := ip.ctx.Response
+			if resp.StatusCode != tt.status {
+				t.Errorf("Expected status %d, got %d", tt.status, resp.StatusCode)
+			}
+			if v := resp.Header.Get("X-Error"); v != tt.error {
+				t.Errorf("Expected fastly.error %s, got %s", tt.error, v)
+			}
+			if v := resp.Header.Get("X-Response"); v != tt.response {
+				t.Errorf("Expected obj.response %s, got %s", tt.response, v)
+			}
+		})
+	}
+}
+
+func TestBackendStreaming(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "max-age=60")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("first|")) // nolint:errcheck
+		w.(http.Flusher).Flush()
+		if r.URL.Path == "/slow" {
+			time.Sleep(200 * time.Millisecond)
+		}
+		w.Write([]byte("second")) // nolint:errcheck
+	}))
+	defer server.Close()
+
+	parsed, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatalf("Test server URL parsing error: %s", err)
+	}
+
+	vcl := fmt.Sprintf(`
+backend F_origin {
+	.host = "%s";
+	.port = "%s";
+	.between_bytes_timeout = 50ms;
+}
+sub vcl_recv {
+	#FASTLY RECV
+	if (req.http.Pass) {
+		return(pass);
+	}
+	return(lookup);
+}
+sub vcl_fetch {
+	#FASTLY FETCH
+	if (req.http.Stream) {
+		set beresp.do_stream = true;
+	}
+}
+sub vcl_log {
+	#FASTLY LOG
+	log resp.body_bytes_written;
+}`, parsed.Hostname(), parsed.Port())
+
+	type output struct {
+		Logs []struct {
+			Message string `json:"message"`
+		} `json:"logs"`
+		ClientResponse struct {
+			BodyBytes int64 `json:"body_bytes"`
+		} `json:"client_response"`
+	}
+
+	tests := []struct {
+		name     string
+		path     string
+		header   map[string]string
+		streamed bool
+		bytes    int64
+		hasError bool
+	}{
+		{name: "buffered response", path: "/", bytes: 12},
+		{name: "streamed response", path: "/", header: map[string]string{"Stream": "1", "Pass": "1"}, streamed: true, bytes: 12},
+		{name: "streamed response is truncated by between bytes timeout", path: "/slow", header: map[string]string{"Stream": "1", "Pass": "1"}, streamed: true, bytes: 6, hasError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ip := New(context.WithResolver(resolver.NewStaticResolver("main", vcl)))
+			req := httptest.NewRequest(http.MethodGet, "http://localhost"+tt.path, nil)
+			for k, v := range tt.header {
+				req.Header.Set(k, v)
+			}
+			if err := ip.ProcessInit(req); err != nil {
+				t.Fatalf("Unexpected init error: %s", err)
+			}
+			if err := ip.ProcessRecv(); err != nil {
+				t.Fatalf("Unexpected error: %s", err)
+			}
+			// Streamed body is not read until the response is delivered to the client
+			if _, ok := ip.ctx.Response.Body.(*backendBody); ok != tt.streamed {
+				t.Errorf("Expected response body streamed %t, got %t", tt.streamed, ok)
+			}
+
+			ip = New(context.WithResolver(resolver.NewStaticResolver("main", vcl)))
+			rec := httptest.NewRecorder()
+			ip.ServeHTTP(rec, req)
+			var o output
+			if err := json.Unmarshal(rec.Body.Bytes(), &o); err != nil {
+				t.Fatalf("Unexpected JSON error: %s", err)
+			}
+			if o.ClientResponse.BodyBytes != tt.bytes {
+				t.Errorf("Expected body bytes %d, got %d", tt.bytes, o.ClientResponse.BodyBytes)
+			}
+			// vcl_log is executed after the body is written to the client
+			if len(o.Logs) != 1 || o.Logs[0].Message != fmt.Sprint(tt.bytes) {
+				t.Errorf("Expected resp.body_bytes_written %d in vcl_log, got %v", tt.bytes, o.Logs)
+			}
+			if (ip.process.Error != nil) != tt.hasError {
+				t.Errorf("Expected error %t, got %v", tt.hasError, ip.process.Error)
+			}
+		})
+	}
+
+	t.Run("streamed object is cached after delivered", func(t *testing.T) {
+		ip := New(context.WithResolver(resolver.NewStaticResolver("main", vcl)))
+		for _, state := range []string{"MISS", "HIT"} {
+			req := httptest.NewRequest(http.MethodGet, "http://localhost/", nil)
+			req.Header.Set("Stream", "1")
+			ip.ServeHTTP(httptest.NewRecorder(), req)
+			if ip.process.Error != nil {
+				t.Fatalf("Unexpected error: %s", ip.process.Error)
+			}
+			if ip.ctx.State != state {
+				t.Errorf("Expected state %s, got %s", state, ip.ctx.State)
+			}
+		}
+		req := httptest.NewRequest(http.MethodGet, "http://localhost/", nil)
+		item, _ := ip.cache.Lookup(ip.ctx.RequestHash.Value, req, time.Now())
+		if item == nil {
+			t.Fatalf("Expected streamed object is cached")
+		}
+		body, err := io.ReadAll(item.NewResponse().Body)
+		if err != nil {
+			t.Fatalf("Unexpected read error: %s", err)
+		}
+		if diff := cmp.Diff("first|second", string(body)); diff != "" {
+			t.Errorf("Cached body mismatch, diff=%s", diff)
+		}
+	})
+}
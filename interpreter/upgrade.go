@@ -0,0 +1,134 @@
+package interpreter
+
+import (
+	"bufio"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/ysugimoto/falco/interpreter/context"
+	"github.com/ysugimoto/falco/interpreter/exception"
+)
+
+// ProcessUpgrade prepares the backend request for the upgraded connection by return(upgrade).
+// The connection is passed through to the backend, so vcl_pass, vcl_fetch and vcl_deliver are not executed.
+// see: https://developer.fastly.com/learning/concepts/real-time-messaging/websockets-tunnel/
+func (i *Interpreter) ProcessUpgrade() error {
+	i.SetScope(context.PassScope)
+	i.ctx.State = "PASS"
+
+	if i.ctx.Backend == nil {
+		return exception.Runtime(nil, "No backend determined in UPGRADE")
+	}
+
+	var err error
+	if i.ctx.Backend.Director != nil {
+		i.ctx.BackendRequest, err = i.createDirectorRequest(i.ctx, i.ctx.Backend.Director)
+	} else {
+		i.ctx.BackendRequest, err = i.createBackendRequest(i.ctx, i.ctx.Backend)
+	}
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	i.ctx.Upgrade = true
+	return nil
+}
+
+// serveUpgrade tunnels the client connection to the backend.
+// The backend response is returned to the client as it is, and both connections are piped after 101 Switching Protocols.
+// vcl_log is executed when the connection is closed.
+func (i *Interpreter) serveUpgrade(w http.ResponseWriter) error {
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		return exception.System("Connection could not be upgraded on %s", i.ctx.Request.Proto)
+	}
+
+	req := i.ctx.BackendRequest
+	backendConn, err := i.dialUpgradeBackend(req)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer backendConn.Close()
+
+	i.Debugger.Message(fmt.Sprintf("Upgrade connection to backend (%s) %s", i.ctx.Backend, req.URL.String()))
+	if err := req.Write(backendConn); err != nil {
+		return errors.WithStack(err)
+	}
+	if i.ctx.FirstByteTimeout.Value > 0 {
+		backendConn.SetReadDeadline(time.Now().Add(i.ctx.FirstByteTimeout.Value)) // nolint:errcheck
+	}
+	backendReader := bufio.NewReader(backendConn)
+	resp, err := http.ReadResponse(backendReader, req)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	backendConn.SetReadDeadline(time.Time{}) // nolint:errcheck
+	i.Debugger.Message(fmt.Sprintf("Backend (%s) responds status code %d", i.ctx.Backend, resp.StatusCode))
+
+	clientConn, clientBuffer, err := hijacker.Hijack()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer clientConn.Close()
+
+	// Backend does not accept upgrading, respond as a normal response
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		defer resp.Body.Close()
+		i.ctx.Response = resp
+		return errors.WithStack(resp.Write(clientConn))
+	}
+
+	fmt.Fprintf(clientConn, "HTTP/%d.%d %s\r\n", resp.ProtoMajor, resp.ProtoMinor, resp.Status)
+	if err := resp.Header.Write(clientConn); err != nil {
+		return errors.WithStack(err)
+	}
+	if _, err := io.WriteString(clientConn, "\r\n"); err != nil {
+		return errors.WithStack(err)
+	}
+	i.ctx.Response = resp
+
+	// Pipe both connections including bytes which have been buffered already
+	done := make(chan struct{}, 2)
+	go func() {
+		io.Copy(backendConn, clientBuffer) // nolint:errcheck
+		done <- struct{}{}
+	}()
+	go func() {
+		io.Copy(clientConn, backendReader) // nolint:errcheck
+		done <- struct{}{}
+	}()
+	<-done
+	i.Debugger.Message("Upgraded connection is closed")
+	return nil
+}
+
+// dialUpgradeBackend connects to the backend of the request within connect_timeout
+func (i *Interpreter) dialUpgradeBackend(req *http.Request) (net.Conn, error) {
+	host := req.URL.Host
+	dialer := &net.Dialer{Timeout: i.ctx.ConnectTimeout.Value}
+	if req.URL.Scheme != HTTPS_SCHEME {
+		conn, err := dialer.Dial("tcp", host)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		return conn, nil
+	}
+
+	origin := i.ctx.OriginBackend
+	if origin == nil {
+		origin = i.ctx.Backend
+	}
+	config, err := i.backendTLSConfig(origin, req.URL.Hostname())
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	conn, err := tls.DialWithDialer(dialer, "tcp", host, config)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return conn, nil
+}
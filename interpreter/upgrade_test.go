@@ -0,0 +1,107 @@
+package interpreter
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/ysugimoto/falco/interpreter/context"
+	"github.com/ysugimoto/falco/resolver"
+)
+
+func TestUpgrade(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.EqualFold(r.Header.Get("Upgrade"), "echo") {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		conn, rw, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		rw.WriteString("HTTP/1.1 101 Switching Protocols\r\nUpgrade: echo\r\nConnection: Upgrade\r\n\r\n") // nolint:errcheck
+		rw.Flush()
+		io.Copy(conn, rw) // nolint:errcheck
+	}))
+	defer backend.Close()
+
+	parsed, err := url.Parse(backend.URL)
+	if err != nil {
+		t.Fatalf("Test server URL parsing error: %s", err)
+	}
+
+	vcl := defaultBackend(parsed) + `
+sub vcl_recv {
+	#FASTLY RECV
+	if (req.http.Upgrade) {
+		return(upgrade);
+	}
+	return(pass);
+}
+sub vcl_deliver {
+	#FASTLY DELIVER
+	set resp.http.Delivered = "1";
+}`
+
+	ip := New(context.WithResolver(resolver.NewStaticResolver("main", vcl)))
+	server := httptest.NewServer(ip)
+	defer server.Close()
+
+	tests := []struct {
+		name    string
+		upgrade string
+		status  int
+	}{
+		{name: "upgraded connection is tunneled", upgrade: "echo", status: http.StatusSwitchingProtocols},
+		{name: "backend does not accept upgrading", upgrade: "unknown", status: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn, err := net.Dial("tcp", server.Listener.Addr().String())
+			if err != nil {
+				t.Fatalf("Failed to connect: %s", err)
+			}
+			defer conn.Close()
+			conn.SetDeadline(time.Now().Add(5 * time.Second)) // nolint:errcheck
+
+			fmt.Fprintf(conn, "GET /ws HTTP/1.1\r\nHost: localhost\r\nConnection: Upgrade\r\nUpgrade: %s\r\n\r\n", tt.upgrade)
+			reader := bufio.NewReader(conn)
+			resp, err := http.ReadResponse(reader, nil)
+			if err != nil {
+				t.Fatalf("Failed to read response: %s", err)
+			}
+			if resp.StatusCode != tt.status {
+				t.Fatalf("Expected status %d, got %d", tt.status, resp.StatusCode)
+			}
+			// vcl_deliver is not executed for the upgraded connection
+			if v := resp.Header.Get("Delivered"); v != "" {
+				t.Errorf("Unexpected Delivered header %q", v)
+			}
+			if resp.StatusCode != http.StatusSwitchingProtocols {
+				return
+			}
+
+			if _, err := conn.Write([]byte("hello")); err != nil {
+				t.Fatalf("Failed to write: %s", err)
+			}
+			buf := make([]byte, 5)
+			if _, err := io.ReadFull(reader, buf); err != nil {
+				t.Fatalf("Failed to read: %s", err)
+			}
+			if diff := cmp.Diff("hello", string(buf)); diff != "" {
+				t.Errorf("Echo mismatch, diff=%s", diff)
+			}
+		})
+	}
+}
@@ -8,6 +8,7 @@ type DirectorConfig struct {
 	Key           string // only exists on chash
 	Seed          uint32 // only exists on chash
 	VNodesPerNode int    // only exists on chash
+	Shield        string // only exists on shield, the POP name like "iad-va-us"
 	Backends      []*DirectorConfigBackend
 }
 
@@ -16,3 +17,28 @@ type DirectorConfigBackend struct {
 	Id      string
 	Weight  int
 }
+
+// EffectiveWeight returns the weight of the backend, the backend which does not have .weight counts as 1
+func (d *DirectorConfigBackend) EffectiveWeight() int {
+	if d.Weight > 0 {
+		return d.Weight
+	}
+	return 1
+}
+
+// HealthyWeight returns the cumulative weight of healthy backends and the total weight of all backends
+func (dc *DirectorConfig) HealthyWeight() (healthy, total int) {
+	for _, v := range dc.Backends {
+		total += v.EffectiveWeight()
+		if v.Backend.IsHealthy() {
+			healthy += v.EffectiveWeight()
+		}
+	}
+	return healthy, total
+}
+
+// IsHealthy returns true when the healthy backends reach the quorum of the director
+func (dc *DirectorConfig) IsHealthy() bool {
+	healthy, total := dc.HealthyWeight()
+	return healthy > 0 && healthy*100 >= dc.Quorum*total
+}
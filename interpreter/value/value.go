@@ -241,7 +241,24 @@ func (v *Backend) String() string {
 func (v *Backend) Type() Type      { return BackendType }
 func (v *Backend) IsLiteral() bool { return v.Literal }
 func (v *Backend) Copy() Value {
-	return &Backend{Value: v.Value, Director: v.Director, Literal: v.Literal}
+	return &Backend{Value: v.Value, Director: v.Director, Literal: v.Literal, Healthy: v.Healthy}
+}
+
+// IsHealthy returns true if the backend is healthy, the director is healthy when its quorum is reached
+func (v *Backend) IsHealthy() bool {
+	if v == nil {
+		return false
+	}
+	if v.Director != nil {
+		return v.Director.IsHealthy()
+	}
+	// Backend which is not declared in VCL does not have health status
+	return v.Healthy == nil || v.Healthy.Load()
+}
+
+// IsShield returns true if the backend is the shield director which routes requests to the shield POP
+func (v *Backend) IsShield() bool {
+	return v != nil && v.Director != nil && v.Director.Type == "shield"
 }
 
 type Acl struct {
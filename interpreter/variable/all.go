@@ -22,6 +22,7 @@ import (
 	"github.com/pkg/errors"
 	"github.com/rs/xid"
 	"github.com/ysugimoto/falco/interpreter/context"
+	"github.com/ysugimoto/falco/interpreter/geo"
 	"github.com/ysugimoto/falco/interpreter/limitations"
 	"github.com/ysugimoto/falco/interpreter/value"
 )
@@ -47,6 +48,9 @@ func (v *AllScopeVariables) Get(s context.Scope, name string) (value.Value, erro
 	case BEREQ_IS_CLUSTERING:
 		return &value.Boolean{Value: false}, nil
 	case CLIENT_CLASS_BOT:
+		if v.ctx.Client != nil && v.ctx.Client.BotName != "" {
+			return &value.Boolean{Value: true}, nil
+		}
 		ua := uasurfer.Parse(req.Header.Get("User-Agent"))
 		return &value.Boolean{Value: ua.IsBot()}, nil
 	case CLIENT_CLASS_BROWSER:
@@ -63,13 +67,9 @@ func (v *AllScopeVariables) Get(s context.Scope, name string) (value.Value, erro
 		CLIENT_CLASS_MASQUERADING,
 		CLIENT_CLASS_SPAM,
 		CLIENT_PLATFORM_MEDIAPLAYER,
-		REQ_BACKEND_IS_SHIELD,
 		REQ_IS_BACKGROUND_FETCH,
 		REQ_IS_CLUSTERING,
 		REQ_IS_ESI_SUBREQ,
-		RESP_STALE,
-		RESP_STALE_IS_ERROR,
-		RESP_STALE_IS_REVALIDATING,
 		WORKSPACE_OVERFLOWED:
 		return &value.Boolean{Value: false}, nil
 
@@ -106,12 +106,20 @@ func (v *AllScopeVariables) Get(s context.Scope, name string) (value.Value, erro
 		return &value.Boolean{Value: req.ProtoMajor == 2}, nil
 	case FASTLY_INFO_IS_H3:
 		return &value.Boolean{Value: req.ProtoMajor == 3}, nil
+	case FASTLY_INFO_H2_FINGERPRINT:
+		if req.ProtoMajor != 2 {
+			return &value.String{Value: ""}, nil
+		}
+		if v.ctx.Client != nil && v.ctx.Client.H2Fingerprint != "" {
+			return &value.String{Value: v.ctx.Client.H2Fingerprint}, nil
+		}
+		// Tentative fingerprint of Chrome browser
+		return &value.String{Value: "1:65536;2:0;4:6291456;6:262144|15663105|0|m,a,s,p"}, nil
 	case FASTLY_INFO_HOST_HEADER:
 		return &value.String{Value: v.ctx.OriginalHost}, nil
 
-	// Backend is always healthy on simulator
 	case REQ_BACKEND_HEALTHY:
-		return &value.Boolean{Value: true}, nil
+		return &value.Boolean{Value: v.ctx.Backend.IsHealthy()}, nil
 
 	case REQ_IS_SSL:
 		return &value.Boolean{Value: req.TLS != nil}, nil
@@ -121,12 +129,14 @@ func (v *AllScopeVariables) Get(s context.Scope, name string) (value.Value, erro
 			protocol = "https"
 		}
 		return &value.String{Value: protocol}, nil
-	case CLIENT_GEO_LATITUDE:
-		return &value.Float{Value: 37.7786941}, nil
-	case CLIENT_GEO_LONGITUDE:
-		return &value.Float{Value: -122.3981452}, nil
+	case CLIENT_GEO_LATITUDE, CLIENT_GEO_LONGITUDE:
+		loc, err := v.clientGeo(req)
+		if err != nil {
+			return value.Null, errors.WithStack(err)
+		}
+		return &value.Float{Value: geoFloat(loc, name)}, nil
 	case FASTLY_ERROR:
-		return &value.String{Value: ""}, nil
+		return v.ctx.FastlyError, nil
 	case MATH_1_PI:
 		return &value.Float{Value: 1 / math.Pi}, nil
 	case MATH_2_PI:
@@ -176,11 +186,17 @@ func (v *AllScopeVariables) Get(s context.Scope, name string) (value.Value, erro
 	case MATH_TAU:
 		return &value.Float{Value: math.Pi * 2}, nil
 
-	// AS Number always indicates "Reserved" defined by RFC7300
+	// AS Number indicates "Reserved" defined by RFC7300 unless spoofed
 	// see: https://datatracker.ietf.org/doc/html/rfc7300
 	case CLIENT_AS_NUMBER:
+		if v.ctx.Client != nil && v.ctx.Client.ASNumber > 0 {
+			return &value.Integer{Value: v.ctx.Client.ASNumber}, nil
+		}
 		return &value.Integer{Value: 4294967294}, nil
 	case CLIENT_AS_NAME:
+		if v.ctx.Client != nil && v.ctx.Client.ASName != "" {
+			return &value.String{Value: v.ctx.Client.ASName}, nil
+		}
 		return &value.String{Value: "Reserved"}, nil
 
 	// Client display infos are unknown. Always returns -1
@@ -189,11 +205,15 @@ func (v *AllScopeVariables) Get(s context.Scope, name string) (value.Value, erro
 		CLIENT_DISPLAY_WIDTH:
 		return &value.Integer{Value: -1}, nil
 
-	// Client geo values always return 0
+	// Client geo values return 0 when the location is not found
 	case CLIENT_GEO_AREA_CODE,
 		CLIENT_GEO_METRO_CODE,
 		CLIENT_GEO_UTC_OFFSET:
-		return &value.Integer{Value: 0}, nil
+		loc, err := v.clientGeo(req)
+		if err != nil {
+			return value.Null, errors.WithStack(err)
+		}
+		return &value.Integer{Value: geoInteger(loc, name, v.ctx.Now())}, nil
 
 	// Alias of client.geo.utc_offset
 	case CLIENT_GEO_GMT_OFFSET:
@@ -217,22 +237,33 @@ func (v *AllScopeVariables) Get(s context.Scope, name string) (value.Value, erro
 			return &value.Integer{Value: num}, nil
 		}
 
+	// Set when the stale object is delivered
+	case RESP_STALE:
+		return v.ctx.Stale, nil
+	case RESP_STALE_IS_ERROR:
+		return v.ctx.StaleIsError, nil
+	case RESP_STALE_IS_REVALIDATING:
+		return v.ctx.StaleIsRevalidating, nil
+
+	case REQ_BACKEND_IS_SHIELD:
+		return &value.Boolean{Value: v.ctx.Backend.IsShield()}, nil
+
 	// Client requests always returns 1, means new connection is coming
 	case CLIENT_REQUESTS:
 		return &value.Integer{Value: 1}, nil
 
-	// Returns tentative value because falco does not support Edge POP
+	// Visits could be configured, and the shield POP counts the edge POP which forwarded the request
 	case FASTLY_FF_VISITS_THIS_POP:
-		return &value.Integer{Value: 1}, nil
+		return &value.Integer{Value: v.ctx.PopVisits() + 1}, nil
 
-	// Returns common value -- do not consider of clustering
+	// Returns common value -- do not consider of clustering.
 	// see: https://developer.fastly.com/reference/vcl/variables/miscellaneous/fastly-ff-visits-this-service/
 	case FASTLY_FF_VISITS_THIS_SERVICE:
 		switch s {
 		case context.MissScope, context.HitScope, context.FetchScope:
-			return &value.Integer{Value: 1}, nil
+			return &value.Integer{Value: v.ctx.Visits() + 1}, nil
 		default:
-			return &value.Integer{Value: 0}, nil
+			return &value.Integer{Value: v.ctx.Visits()}, nil
 		}
 
 	// Returns tentative value -- you may know your customer_id in the contraction :-)
@@ -283,7 +314,7 @@ func (v *AllScopeVariables) Get(s context.Scope, name string) (value.Value, erro
 	case SERVER_PORT:
 		return &value.Integer{Value: int64(3124)}, nil // fixed server port number
 	case SERVER_POP:
-		return &value.String{Value: "FALCO"}, nil // Intend to set string not exists in Fastly POP certainly
+		return &value.String{Value: v.ctx.Pop()}, nil // Default value intends not to exist in Fastly POP certainly
 
 	// workspace related values respects Fastly fiddle one
 	case WORKSPACE_BYTES_FREE:
@@ -318,7 +349,7 @@ func (v *AllScopeVariables) Get(s context.Scope, name string) (value.Value, erro
 		return &value.IP{Value: addr}, nil
 
 	case REQ_BACKEND:
-		return &value.Backend{Value: v.ctx.Backend.Value, Director: v.ctx.Backend.Director}, nil
+		return &value.Backend{Value: v.ctx.Backend.Value, Director: v.ctx.Backend.Director, Healthy: v.ctx.Backend.Healthy}, nil
 	case REQ_GRACE:
 		return v.Get(s, "req.max_stale_if_error")
 
@@ -329,8 +360,11 @@ func (v *AllScopeVariables) Get(s context.Scope, name string) (value.Value, erro
 		return v.ctx.MaxStaleWhileRevalidate, nil
 
 	case TIME_ELAPSED:
-		return &value.RTime{Value: time.Since(v.ctx.RequestStartTime)}, nil
+		return &value.RTime{Value: v.ctx.Since(v.ctx.RequestStartTime)}, nil
 	case CLIENT_BOT_NAME:
+		if v.ctx.Client != nil && v.ctx.Client.BotName != "" {
+			return &value.String{Value: v.ctx.Client.BotName}, nil
+		}
 		ua := uasurfer.Parse(req.Header.Get("User-Agent"))
 		if !ua.IsBot() {
 			return &value.String{Value: ""}, nil
@@ -360,7 +394,6 @@ func (v *AllScopeVariables) Get(s context.Scope, name string) (value.Value, erro
 		CLIENT_GEO_COUNTRY_NAME_ASCII,
 		CLIENT_GEO_COUNTRY_NAME_LATIN1,
 		CLIENT_GEO_COUNTRY_NAME_UTF8,
-		CLIENT_GEO_IP_OVERRIDE,
 		CLIENT_GEO_POSTAL_CODE,
 		CLIENT_GEO_PROXY_DESCRIPTION,
 		CLIENT_GEO_PROXY_TYPE,
@@ -368,25 +401,29 @@ func (v *AllScopeVariables) Get(s context.Scope, name string) (value.Value, erro
 		CLIENT_GEO_REGION_ASCII,
 		CLIENT_GEO_REGION_LATIN1,
 		CLIENT_GEO_REGION_UTF8:
-		return &value.String{Value: "unknown"}, nil
+		loc, err := v.clientGeo(req)
+		if err != nil {
+			return value.Null, errors.WithStack(err)
+		}
+		return &value.String{Value: geoString(loc, name)}, nil
+	case CLIENT_GEO_IP_OVERRIDE:
+		return v.ctx.ClientGeoIpOverride, nil
 
 	case CLIENT_IDENTITY:
-		if v.ctx.ClientIdentity == nil {
-			// default as client.ip
-			idx := strings.LastIndex(req.RemoteAddr, ":")
-			if idx == -1 {
-				return &value.String{Value: req.RemoteAddr}, nil
-			}
-			return &value.String{Value: req.RemoteAddr[:idx]}, nil
+		if v.ctx.ClientIdentity != nil {
+			return v.ctx.ClientIdentity, nil
 		}
-		return v.ctx.ClientIdentity, nil
+		if v.ctx.Client != nil && v.ctx.Client.Identity != "" {
+			return &value.String{Value: v.ctx.Client.Identity}, nil
+		}
+		// default as client.ip
+		if ip := clientIP(req); ip != nil {
+			return &value.String{Value: ip.String()}, nil
+		}
+		return &value.String{Value: req.RemoteAddr}, nil
 
 	case CLIENT_IP:
-		idx := strings.LastIndex(req.RemoteAddr, ":")
-		if idx == -1 {
-			return &value.IP{Value: net.ParseIP(req.RemoteAddr)}, nil
-		}
-		return &value.IP{Value: net.ParseIP(req.RemoteAddr[:idx])}, nil
+		return &value.IP{Value: clientIP(req)}, nil
 
 	case CLIENT_OS_NAME:
 		ua := uasurfer.Parse(req.Header.Get("User-Agent"))
@@ -407,11 +444,7 @@ func (v *AllScopeVariables) Get(s context.Scope, name string) (value.Value, erro
 	case LF:
 		return &value.String{Value: "\n"}, nil
 	case NOW_SEC:
-		// For testing - if fixed time is injected, return it
-		if v.ctx.FixedTime != nil {
-			return &value.String{Value: fmt.Sprint(v.ctx.FixedTime.Unix())}, nil
-		}
-		return &value.String{Value: fmt.Sprint(time.Now().Unix())}, nil
+		return &value.String{Value: fmt.Sprint(v.ctx.Now().Unix())}, nil
 	case REQ_BODY:
 		switch req.Method {
 		case http.MethodPatch, http.MethodPost, http.MethodPut:
@@ -536,36 +569,34 @@ func (v *AllScopeVariables) Get(s context.Scope, name string) (value.Value, erro
 	case REQ_XID:
 		return &value.String{Value: xid.New().String()}, nil
 
-	// Fixed values
+	// Server values could be configured
 	case SERVER_DATACENTER:
-		return &value.String{Value: "FALCO"}, nil
-	case SERVER_HOSTNAME:
-		return &value.String{Value: "cache-localsimulator"}, nil
-	case SERVER_IDENTITY:
-		return &value.String{Value: "cache-localsimulator"}, nil
+		return &value.String{Value: v.ctx.Datacenter()}, nil
+	case SERVER_HOSTNAME, SERVER_IDENTITY:
+		return &value.String{Value: v.ctx.Identity()}, nil
 	case SERVER_REGION:
-		return &value.String{Value: "US"}, nil
+		return &value.String{Value: v.ctx.Region()}, nil
 	case STALE_EXISTS:
-		return v.ctx.StaleContents, nil
+		return &value.Boolean{Value: v.ctx.StaleItem != nil}, nil
 	case TIME_ELAPSED_MSEC:
 		return &value.String{
-			Value: fmt.Sprint(time.Since(v.ctx.RequestStartTime).Milliseconds()),
+			Value: fmt.Sprint(v.ctx.Since(v.ctx.RequestStartTime).Milliseconds()),
 		}, nil
 	case TIME_ELAPSED_MSEC_FRAC:
 		return &value.String{
-			Value: fmt.Sprintf("%03d", time.Since(v.ctx.RequestStartTime).Milliseconds()),
+			Value: fmt.Sprintf("%03d", v.ctx.Since(v.ctx.RequestStartTime).Milliseconds()),
 		}, nil
 	case TIME_ELAPSED_SEC:
 		return &value.String{
-			Value: fmt.Sprint(int64(time.Since(v.ctx.RequestStartTime).Seconds())),
+			Value: fmt.Sprint(int64(v.ctx.Since(v.ctx.RequestStartTime).Seconds())),
 		}, nil
 	case TIME_ELAPSED_USEC:
 		return &value.String{
-			Value: fmt.Sprint(time.Since(v.ctx.RequestStartTime).Microseconds()),
+			Value: fmt.Sprint(v.ctx.Since(v.ctx.RequestStartTime).Microseconds()),
 		}, nil
 	case TIME_ELAPSED_USEC_FRAC:
 		return &value.String{
-			Value: fmt.Sprintf("%06d", time.Since(v.ctx.RequestStartTime).Microseconds()),
+			Value: fmt.Sprintf("%06d", v.ctx.Since(v.ctx.RequestStartTime).Microseconds()),
 		}, nil
 	case TIME_START_MSEC:
 		return &value.String{
@@ -588,11 +619,7 @@ func (v *AllScopeVariables) Get(s context.Scope, name string) (value.Value, erro
 			Value: fmt.Sprint(v.ctx.RequestStartTime.UnixMicro() % 1000000),
 		}, nil
 	case NOW:
-		// For testing - if fixed time is injected, return it
-		if v.ctx.FixedTime != nil {
-			return &value.Time{Value: *v.ctx.FixedTime}, nil
-		}
-		return &value.Time{Value: time.Now()}, nil
+		return &value.Time{Value: v.ctx.Now()}, nil
 	case TIME_START:
 		return &value.Time{Value: v.ctx.RequestStartTime}, nil
 	}
@@ -625,6 +652,13 @@ func (v *AllScopeVariables) getFromRegex(name string) value.Value {
 		return getRequestHeaderValue(v.ctx.Request, match[1])
 	}
 
+	// Backend and director health matching
+	if match := backendHealthyRegex.FindStringSubmatch(name); match != nil {
+		if b, ok := v.ctx.Backends[match[1]]; ok {
+			return &value.Boolean{Value: b.IsHealthy()}
+		}
+	}
+
 	// Ratecounter variable matching
 	if match := rateCounterRegex.FindStringSubmatch(name); match != nil {
 		var val float64
@@ -787,3 +821,97 @@ func (v *AllScopeVariables) Unset(s context.Scope, name string) error {
 }
 
 var _ Variable = &AllScopeVariables{}
+
+func clientIP(req *http.Request) net.IP {
+	if host, _, err := net.SplitHostPort(req.RemoteAddr); err == nil {
+		return net.ParseIP(host)
+	}
+	return net.ParseIP(req.RemoteAddr)
+}
+
+// clientGeo looks up the location of client.geo.ip_override, or client.ip if not overridden.
+// nil is returned when geo database is not configured or the location is not found
+func (v *AllScopeVariables) clientGeo(req *http.Request) (*geo.Location, error) {
+	if v.ctx.Geo == nil {
+		return nil, nil
+	}
+	ip := net.ParseIP(v.ctx.ClientGeoIpOverride.Value)
+	if ip == nil {
+		ip = clientIP(req)
+	}
+	if ip == nil {
+		return nil, nil
+	}
+	loc, err := v.ctx.Geo.Lookup(ip)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return loc, nil
+}
+
+func geoString(loc *geo.Location, name string) string {
+	if loc == nil {
+		return "unknown"
+	}
+	var v string
+	switch name {
+	case CLIENT_GEO_CITY, CLIENT_GEO_CITY_ASCII, CLIENT_GEO_CITY_LATIN1, CLIENT_GEO_CITY_UTF8:
+		v = loc.City
+	case CLIENT_GEO_CONN_SPEED:
+		v = loc.ConnSpeed
+	case CLIENT_GEO_CONN_TYPE:
+		v = loc.ConnType
+	case CLIENT_GEO_CONTINENT_CODE:
+		v = loc.ContinentCode
+	case CLIENT_GEO_COUNTRY_CODE:
+		v = loc.CountryCode
+	case CLIENT_GEO_COUNTRY_CODE3:
+		v = loc.CountryCode3
+	case CLIENT_GEO_COUNTRY_NAME, CLIENT_GEO_COUNTRY_NAME_ASCII,
+		CLIENT_GEO_COUNTRY_NAME_LATIN1, CLIENT_GEO_COUNTRY_NAME_UTF8:
+		v = loc.CountryName
+	case CLIENT_GEO_POSTAL_CODE:
+		v = loc.PostalCode
+	case CLIENT_GEO_PROXY_DESCRIPTION:
+		v = loc.ProxyDescription
+	case CLIENT_GEO_PROXY_TYPE:
+		v = loc.ProxyType
+	case CLIENT_GEO_REGION, CLIENT_GEO_REGION_ASCII, CLIENT_GEO_REGION_LATIN1, CLIENT_GEO_REGION_UTF8:
+		v = loc.Region
+	}
+	if v == "" {
+		return "unknown"
+	}
+	return v
+}
+
+func geoFloat(loc *geo.Location, name string) float64 {
+	switch {
+	case loc == nil && name == CLIENT_GEO_LATITUDE:
+		return 37.7786941
+	case loc == nil:
+		return -122.3981452
+	case name == CLIENT_GEO_LATITUDE:
+		return loc.Latitude
+	default:
+		return loc.Longitude
+	}
+}
+
+func geoInteger(loc *geo.Location, name string, now time.Time) int64 {
+	if loc == nil {
+		return 0
+	}
+	switch name {
+	case CLIENT_GEO_AREA_CODE:
+		return loc.AreaCode
+	case CLIENT_GEO_METRO_CODE:
+		return loc.MetroCode
+	default:
+		// Offset changes by the daylight saving time so it is calculated on the interpreter clock
+		if loc.TimeZone != "" {
+			return geo.UtcOffset(loc.TimeZone, now)
+		}
+		return loc.UtcOffset
+	}
+}
@@ -7,7 +7,6 @@ import (
 	"net"
 	"strconv"
 	"strings"
-	"time"
 
 	"net/http"
 	"net/netip"
@@ -81,14 +80,14 @@ func (v *DeliverScopeVariables) Get(s context.Scope, name string) (value.Value,
 	// TODO: should be able to get from context after object checked
 	case OBJ_AGE:
 		if v.ctx.CacheHitItem != nil {
-			return &value.RTime{Value: time.Since(v.ctx.CacheHitItem.EntryTime)}, nil
+			return &value.RTime{Value: v.ctx.Since(v.ctx.CacheHitItem.EntryTime)}, nil
 		}
 		return &value.RTime{Value: 0}, nil // 0s
 	case OBJ_CACHEABLE:
 		return v.ctx.BackendResponseCacheable, nil
 	case OBJ_ENTERED:
 		if v.ctx.CacheHitItem != nil {
-			return &value.RTime{Value: time.Since(v.ctx.CacheHitItem.EntryTime)}, nil
+			return &value.RTime{Value: v.ctx.Since(v.ctx.CacheHitItem.EntryTime)}, nil
 		}
 		return &value.RTime{Value: 0}, nil
 	case OBJ_GRACE:
@@ -190,7 +189,7 @@ func (v *DeliverScopeVariables) Get(s context.Scope, name string) (value.Value,
 		// TODO: this logic is only calculate response - request time.
 		// It means that is not correct RTIME value because TTFB is the first byte from response.
 		return &value.RTime{
-			Value: time.Since(v.ctx.RequestEndTime),
+			Value: v.ctx.Since(v.ctx.RequestEndTime),
 		}, nil
 
 	case TIME_END:
@@ -232,7 +231,7 @@ func (v *DeliverScopeVariables) Get(s context.Scope, name string) (value.Value,
 	} else if val != nil {
 		return val, nil
 	}
-	if val, err := GetTLSVariable(v.ctx.Request.TLS, name); err != nil {
+	if val, err := GetTLSVariable(v.ctx, name); err != nil {
 		return value.Null, errors.WithStack(err)
 	} else if val != nil {
 		return val, nil
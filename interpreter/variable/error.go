@@ -6,7 +6,6 @@ import (
 	"net/http"
 	"strconv"
 	"strings"
-	"time"
 
 	"github.com/pkg/errors"
 	"github.com/ysugimoto/falco/interpreter/context"
@@ -47,14 +46,14 @@ func (v *ErrorScopeVariables) Get(s context.Scope, name string) (value.Value, er
 	// TODO: should be able to get from context after object checked
 	case OBJ_AGE:
 		if v.ctx.CacheHitItem != nil {
-			return &value.RTime{Value: time.Since(v.ctx.CacheHitItem.EntryTime)}, nil
+			return &value.RTime{Value: v.ctx.Since(v.ctx.CacheHitItem.EntryTime)}, nil
 		}
 		return &value.RTime{Value: 0}, nil // 0s
 	case OBJ_CACHEABLE:
 		return v.ctx.BackendResponseCacheable, nil
 	case OBJ_ENTERED:
 		if v.ctx.CacheHitItem != nil {
-			return &value.RTime{Value: time.Since(v.ctx.CacheHitItem.EntryTime)}, nil
+			return &value.RTime{Value: v.ctx.Since(v.ctx.CacheHitItem.EntryTime)}, nil
 		}
 		return &value.RTime{Value: 0}, nil
 	case OBJ_GRACE:
@@ -75,12 +74,8 @@ func (v *ErrorScopeVariables) Get(s context.Scope, name string) (value.Value, er
 		return &value.String{Value: v.ctx.Object.Proto}, nil
 	case OBJ_RESPONSE:
 		return v.ctx.ObjectResponse, nil
-	case OBJ_STALE_IF_ERROR:
-		// alias for obj.grace
-		return v.ctx.ObjectGrace, nil
-	case OBJ_STALE_WHILE_REVALIDATE:
-		// Return fixed value because we don't support SWR yet
-		return &value.RTime{Value: 60 * time.Second}, nil
+	case OBJ_STALE_IF_ERROR, OBJ_STALE_WHILE_REVALIDATE:
+		return GetObjectStalePeriod(v.ctx, name), nil
 	case OBJ_STATUS:
 		return &value.Integer{Value: int64(v.ctx.Object.StatusCode)}, nil
 	case OBJ_TTL:
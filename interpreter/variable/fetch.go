@@ -212,9 +212,8 @@ func (v *FetchScopeVariables) Get(s context.Scope, name string) (value.Value, er
 	case FASTLY_INFO_IS_CLUSTER_SHIELD:
 		return &value.Boolean{Value: false}, nil
 
-	// Always true because simulator could not simulate origin-shielding
 	case REQ_BACKEND_IS_ORIGIN:
-		return &value.Boolean{Value: true}, nil
+		return GetBackendIsOrigin(v.ctx), nil
 	// Digest ratio will return fixed value
 	case REQ_DIGEST_RATIO:
 		return &value.Float{Value: 0.4}, nil
@@ -3,7 +3,6 @@ package variable
 import (
 	"io"
 	"strings"
-	"time"
 
 	"net/http"
 
@@ -31,14 +30,14 @@ func (v *HitScopeVariables) Get(s context.Scope, name string) (value.Value, erro
 	// FIXME should be able to get from actual backend request
 	case OBJ_AGE:
 		if v.ctx.CacheHitItem != nil {
-			return &value.RTime{Value: time.Since(v.ctx.CacheHitItem.EntryTime)}, nil
+			return &value.RTime{Value: v.ctx.Since(v.ctx.CacheHitItem.EntryTime)}, nil
 		}
 		return &value.RTime{Value: 0}, nil // 0s
 	case OBJ_CACHEABLE:
 		return v.ctx.BackendResponseCacheable, nil
 	case OBJ_ENTERED:
 		if v.ctx.CacheHitItem != nil {
-			return &value.RTime{Value: time.Since(v.ctx.CacheHitItem.EntryTime)}, nil
+			return &value.RTime{Value: v.ctx.Since(v.ctx.CacheHitItem.EntryTime)}, nil
 		}
 		return &value.RTime{Value: 0}, nil
 	case OBJ_GRACE:
@@ -59,12 +58,8 @@ func (v *HitScopeVariables) Get(s context.Scope, name string) (value.Value, erro
 		return &value.String{Value: v.ctx.Object.Proto}, nil
 	case OBJ_RESPONSE:
 		return v.ctx.ObjectResponse, nil
-	case OBJ_STALE_IF_ERROR:
-		// alias for obj.grace
-		return v.ctx.ObjectGrace, nil
-	case OBJ_STALE_WHILE_REVALIDATE:
-		// Return fixed value because we don't support SWR yet
-		return &value.RTime{Value: 60 * time.Second}, nil
+	case OBJ_STALE_IF_ERROR, OBJ_STALE_WHILE_REVALIDATE:
+		return GetObjectStalePeriod(v.ctx, name), nil
 	case OBJ_STATUS:
 		return &value.Integer{Value: int64(v.ctx.Object.StatusCode)}, nil
 	case OBJ_TTL:
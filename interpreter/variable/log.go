@@ -7,7 +7,6 @@ import (
 	"net"
 	"strconv"
 	"strings"
-	"time"
 
 	"net/http"
 	"net/netip"
@@ -80,14 +79,14 @@ func (v *LogScopeVariables) Get(s context.Scope, name string) (value.Value, erro
 
 	case OBJ_AGE:
 		if v.ctx.CacheHitItem != nil {
-			return &value.RTime{Value: time.Since(v.ctx.CacheHitItem.EntryTime)}, nil
+			return &value.RTime{Value: v.ctx.Since(v.ctx.CacheHitItem.EntryTime)}, nil
 		}
 		return &value.RTime{Value: 0}, nil // 0s
 	case OBJ_CACHEABLE:
 		return v.ctx.BackendResponseCacheable, nil
 	case OBJ_ENTERED:
 		if v.ctx.CacheHitItem != nil {
-			return &value.RTime{Value: time.Since(v.ctx.CacheHitItem.EntryTime)}, nil
+			return &value.RTime{Value: v.ctx.Since(v.ctx.CacheHitItem.EntryTime)}, nil
 		}
 		return &value.RTime{Value: 0}, nil
 	case OBJ_GRACE:
@@ -104,12 +103,8 @@ func (v *LogScopeVariables) Get(s context.Scope, name string) (value.Value, erro
 			return &value.RTime{Value: v.ctx.CacheHitItem.LastUsed}, nil
 		}
 		return &value.RTime{Value: 0}, nil
-	case OBJ_STALE_IF_ERROR:
-		// alias for obj.grace
-		return v.ctx.ObjectGrace, nil
-	case OBJ_STALE_WHILE_REVALIDATE:
-		// Return fixed value because we don't support SWR yet
-		return &value.RTime{Value: 60 * time.Second}, nil
+	case OBJ_STALE_IF_ERROR, OBJ_STALE_WHILE_REVALIDATE:
+		return GetObjectStalePeriod(v.ctx, name), nil
 	case OBJ_TTL:
 		return v.ctx.ObjectTTL, nil
 
@@ -178,9 +173,11 @@ func (v *LogScopeVariables) Get(s context.Scope, name string) (value.Value, erro
 	case REQ_DIGEST_RATIO:
 		return &value.Float{Value: 0.4}, nil
 
-	// FIXME: We need to send actual request to the backend
+	// Body size of the delivered response, which is encoded by Content-Encoding
 	case RESP_BODY_BYTES_WRITTEN:
-		return &value.Integer{Value: 0}, nil
+		return &value.Integer{Value: v.ctx.ResponseBodyBytesWritten}, nil
+
+	// FIXME: We need to send actual request to the backend
 	case RESP_BYTES_WRITTEN:
 		return &value.Integer{Value: 0}, nil
 	case RESP_COMPLETED:
@@ -227,60 +224,61 @@ func (v *LogScopeVariables) Get(s context.Scope, name string) (value.Value, erro
 		// TODO: this logic is only calculate response - request time.
 		// It means that is not correct RTIME value because TFB is the first byte from response.
 		return &value.RTime{
-			Value: time.Since(v.ctx.RequestEndTime),
+			Value: v.ctx.Since(v.ctx.RequestEndTime),
 		}, nil
 
-	// FIXME: segmented_caching related variables is just fake value
+	// Segmented caching does not purge and cancel blocks in simulator
 	case SEGMENTED_CACHING_AUTOPURGED:
 		return &value.Boolean{Value: false}, nil
 	case SEGMENTED_CACHING_BLOCK_NUMBER:
-		return &value.Integer{Value: 1}, nil
+		return &value.Integer{Value: v.segmentedCaching().BlockNumber}, nil
 	case SEGMENTED_CACHING_BLOCK_SIZE:
-		return &value.Integer{Value: 1}, nil
+		return &value.Integer{Value: v.segmentedCaching().BlockSize}, nil
 	case SEGMENTED_CACHING_CANCELLED: // nolint: misspell
 		return &value.Boolean{Value: false}, nil
 	case SEGMENTED_CACHING_CLIENT_REQ_IS_OPEN_ENDED:
-		return &value.Boolean{Value: false}, nil
+		_, high, found := strings.Cut(strings.TrimPrefix(req.Header.Get("Range"), "bytes="), "-")
+		return &value.Boolean{Value: found && high == ""}, nil
 	case SEGMENTED_CACHING_CLIENT_REQ_IS_RANGE:
 		return &value.Boolean{Value: req.Header.Get("Range") != ""}, nil
 	case SEGMENTED_CACHING_CLIENT_REQ_RANGE_HIGH:
-		spl := strings.SplitN(req.Header.Get("Range"), "-", 2)
-		if len(spl) != 2 {
+		_, high, found := strings.Cut(strings.TrimPrefix(req.Header.Get("Range"), "bytes="), "-")
+		if !found || high == "" {
 			return &value.Integer{Value: 0}, nil
 		}
-		high, err := strconv.ParseInt(spl[1], 10, 64)
+		v, err := strconv.ParseInt(high, 10, 64)
 		if err != nil {
 			return value.Null, errors.WithStack(err)
 		}
-		return &value.Integer{Value: high}, nil
+		return &value.Integer{Value: v}, nil
 	case SEGMENTED_CACHING_CLIENT_REQ_RANGE_LOW:
-		spl := strings.SplitN(req.Header.Get("Range"), "-", 2)
-		if len(spl) != 2 {
+		low, _, found := strings.Cut(strings.TrimPrefix(req.Header.Get("Range"), "bytes="), "-")
+		if !found || low == "" {
 			return &value.Integer{Value: 0}, nil
 		}
-		low, err := strconv.ParseInt(spl[0], 10, 64)
+		v, err := strconv.ParseInt(low, 10, 64)
 		if err != nil {
 			return value.Null, errors.WithStack(err)
 		}
-		return &value.Integer{Value: low}, nil
+		return &value.Integer{Value: v}, nil
 	case SEGMENTED_CACHING_COMPLETED:
-		return &value.Boolean{Value: false}, nil
+		return &value.Boolean{Value: v.segmentedCaching().Completed}, nil
 	case SEGMENTED_CACHING_ERROR:
-		return &value.String{Value: ""}, nil
+		return &value.String{Value: v.segmentedCaching().Error}, nil
 	case SEGMENTED_CACHING_FAILED:
-		return &value.Boolean{Value: false}, nil
+		return &value.Boolean{Value: v.segmentedCaching().Failed}, nil
 	case SEGMENTED_CACHING_IS_INNER_REQ:
-		return &value.Boolean{Value: false}, nil
+		return &value.Boolean{Value: v.ctx.SegmentedCaching != nil && v.ctx.SegmentedCaching.IsInner}, nil
 	case SEGMENTED_CACHING_IS_OUTER_REQ:
-		return &value.Boolean{Value: true}, nil
+		return &value.Boolean{Value: v.ctx.SegmentedCaching != nil && !v.ctx.SegmentedCaching.IsInner}, nil
 	case SEGMENTED_CACHING_OBJ_COMPLETE_LENGTH:
-		return &value.Integer{Value: 0}, nil
+		return &value.Integer{Value: v.segmentedCaching().CompleteLength}, nil
 	case SEGMENTED_CACHING_ROUNDED_REQ_RANGE_HIGH:
-		return &value.Integer{Value: 0}, nil
+		return &value.Integer{Value: v.segmentedCaching().RangeHigh}, nil
 	case SEGMENTED_CACHING_ROUNDED_REQ_RANGE_LOW:
-		return &value.Integer{Value: 0}, nil
+		return &value.Integer{Value: v.segmentedCaching().RangeLow}, nil
 	case SEGMENTED_CACHING_TOTAL_BLOCKS:
-		return &value.Integer{Value: 0}, nil
+		return &value.Integer{Value: v.segmentedCaching().TotalBlocks}, nil
 	}
 
 	// Look up shared variables
@@ -294,7 +292,7 @@ func (v *LogScopeVariables) Get(s context.Scope, name string) (value.Value, erro
 	} else if val != nil {
 		return val, nil
 	}
-	if val, err := GetTLSVariable(v.ctx.Request.TLS, name); err != nil {
+	if val, err := GetTLSVariable(v.ctx, name); err != nil {
 		return value.Null, errors.WithStack(err)
 	} else if val != nil {
 		return val, nil
@@ -435,3 +433,11 @@ func (v *LogScopeVariables) Unset(s context.Scope, name string) error {
 	v.ctx.Response.Header.Del(match[1])
 	return nil
 }
+
+// segmentedCaching returns the segmented caching state, or zero state when segmented caching is not processed
+func (v *LogScopeVariables) segmentedCaching() *context.SegmentedCaching {
+	if v.ctx.SegmentedCaching == nil {
+		return &context.SegmentedCaching{}
+	}
+	return v.ctx.SegmentedCaching
+}
@@ -71,16 +71,15 @@ func (v *MissScopeVariables) Get(s context.Scope, name string) (value.Value, err
 	case BEREQ_URL_QS:
 		return &value.String{Value: bereq.URL.RawQuery}, nil
 
-	// Always 1 because simulator could not simulate pop behavior
+	// Simulator does not simulate clustering in the POP
 	case FASTLY_FF_VISITS_THIS_POP_THIS_SERVICE:
-		return &value.Integer{Value: 1}, nil
+		return &value.Integer{Value: v.ctx.PopVisits() + 1}, nil
 	// Always false because simulator could not simulate origin-shielding
 	case FASTLY_INFO_IS_CLUSTER_SHIELD:
 		return &value.Boolean{Value: false}, nil
 
-	// We simulate request is always pass to the origin, not consider shielding
 	case REQ_BACKEND_IS_ORIGIN:
-		return &value.Boolean{Value: true}, nil
+		return GetBackendIsOrigin(v.ctx), nil
 	// Digest ratio will return fixed value
 	case REQ_DIGEST_RATIO:
 		return &value.Float{Value: 0.4}, nil
@@ -67,9 +67,8 @@ func (v *PassScopeVariables) Get(s context.Scope, name string) (value.Value, err
 		return &value.String{Value: bereq.URL.Path}, nil
 	case BEREQ_URL_QS:
 		return &value.String{Value: bereq.URL.RawQuery}, nil
-	// We simulate request is always pass to the origin, not consider shielding
 	case REQ_BACKEND_IS_ORIGIN:
-		return &value.Boolean{Value: true}, nil
+		return GetBackendIsOrigin(v.ctx), nil
 	// Digest ratio will return fixed value
 	case REQ_DIGEST_RATIO:
 		return &value.Float{Value: 0.4}, nil
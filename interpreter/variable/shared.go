@@ -1,7 +1,6 @@
 package variable
 
 import (
-	"crypto/tls"
 	"fmt"
 	"time"
 
@@ -102,7 +101,8 @@ func GetTCPInfoVariable(name string) (value.Value, error) {
 
 // TODO: consider we need to construct TLS server manually instead of net/http server
 // Temporaly return tentative data found in Fastly fiddle
-func GetTLSVariable(s *tls.ConnectionState, name string) (value.Value, error) {
+func GetTLSVariable(ctx *context.Context, name string) (value.Value, error) {
+	s := ctx.Request.TLS
 	switch name {
 	case TLS_CLIENT_CIPHER:
 		if s == nil {
@@ -130,6 +130,9 @@ func GetTLSVariable(s *tls.ConnectionState, name string) (value.Value, error) {
 	case TLS_CLIENT_IANA_CHOSEN_CIPHER_ID:
 		return &value.Integer{Value: 49199}, nil
 	case TLS_CLIENT_JA3_MD5:
+		if ctx.Client != nil && ctx.Client.JA3MD5 != "" {
+			return &value.String{Value: ctx.Client.JA3MD5}, nil
+		}
 		return &value.String{Value: "582a3b42ab84f78a5b376b1e29d6d367"}, nil
 	case TLS_CLIENT_PROTOCOL:
 		if s == nil {
@@ -137,8 +140,12 @@ func GetTLSVariable(s *tls.ConnectionState, name string) (value.Value, error) {
 		}
 		return &value.String{Value: TLSVersionNameMap[s.Version]}, nil
 
-	case TLS_CLIENT_SERVERNAME,
-		TLS_CLIENT_TLSEXTS_LIST,
+	case TLS_CLIENT_SERVERNAME:
+		if s == nil {
+			return &value.String{Value: ""}, nil
+		}
+		return &value.String{Value: s.ServerName}, nil
+	case TLS_CLIENT_TLSEXTS_LIST,
 		TLS_CLIENT_TLSEXTS_LIST_SHA,
 		TLS_CLIENT_TLSEXTS_LIST_TXT,
 		TLS_CLIENT_TLSEXTS_SHA:
@@ -148,7 +155,10 @@ func GetTLSVariable(s *tls.ConnectionState, name string) (value.Value, error) {
 	case TRANSPORT_BW_ESTIMATE:
 		return &value.Integer{Value: 0}, nil
 	case TRANSPORT_TYPE:
-		// TODO: will be "quic" if we have support quic protocol
+		// HTTP/3 is only emulated but the request is treated as it comes over QUIC
+		if ctx.Request.ProtoMajor == 3 {
+			return &value.String{Value: "quic"}, nil
+		}
 		return &value.String{Value: "tcp"}, nil
 	}
 	return nil, nil
@@ -303,3 +313,24 @@ func SetWafVariables(ctx *context.Context, name, operator string, val value.Valu
 	}
 	return false, nil
 }
+
+// GetBackendIsOrigin returns req.backend.is_origin value,
+// request is sent to the origin unless the backend is the shield director
+func GetBackendIsOrigin(ctx *context.Context) *value.Boolean {
+	return &value.Boolean{Value: !ctx.Backend.IsShield()}
+}
+
+// GetObjectStalePeriod returns obj.stale_if_error or obj.stale_while_revalidate value,
+// which are the periods of the cached object, or the backend response if the object is not delivered from cache
+func GetObjectStalePeriod(ctx *context.Context, name string) *value.RTime {
+	if item := ctx.CacheHitItem; item != nil {
+		if name == OBJ_STALE_IF_ERROR {
+			return &value.RTime{Value: item.StaleIfError}
+		}
+		return &value.RTime{Value: item.StaleWhileRevalidate}
+	}
+	if name == OBJ_STALE_IF_ERROR {
+		return &value.RTime{Value: ctx.BackendResponseStaleIfError.Value}
+	}
+	return &value.RTime{Value: ctx.BackendResponseStaleWhileRevalidate.Value}
+}
@@ -23,6 +23,7 @@ var (
 	objectHttpHeaderRegex          = regexp.MustCompile(`^obj\.http\.(.+)`)
 	rateCounterRegex               = regexp.MustCompile(`ratecounter\.([^\.]+)\.(.+)`)
 	regexMatchedRegex              = regexp.MustCompile(`re\.group\.([0-9]+)`)
+	backendHealthyRegex            = regexp.MustCompile(`^(?:backend|director)\.([^\.]+)\.healthy$`)
 )
 
 func doAssign(left value.Value, operator string, right value.Value) error {
@@ -19,6 +19,13 @@ type Lexer struct {
 	file   string
 	peeks  []token.Token
 	isEOF  bool
+
+	// input size guard, zero maxBytes means unlimited
+	bytes    int64
+	maxBytes int64
+	exceeded bool
+
+	placeholders []Placeholder
 }
 
 func New(r io.Reader, opts ...OptionFunc) *Lexer {
@@ -28,6 +35,8 @@ func New(r io.Reader, opts ...OptionFunc) *Lexer {
 		line:   1,
 		buffer: new(bytes.Buffer),
 		file:   o.Filename,
+
+		placeholders: o.Placeholders,
 	}
 	l.readChar()
 	return l
@@ -37,13 +46,62 @@ func NewFromString(input string, opts ...OptionFunc) *Lexer {
 	return New(strings.NewReader(input), opts...)
 }
 
+// Reset discards lexer state and reuses allocated buffers for the new input
+func (l *Lexer) Reset(r io.Reader, opts ...OptionFunc) {
+	o := collect(opts)
+	l.r.Reset(r)
+	l.char = 0x00
+	l.line = 1
+	l.index = 0
+	l.buffer.Reset()
+	l.stack = l.stack[:0]
+	l.file = o.Filename
+	l.peeks = l.peeks[:0]
+	l.isEOF = false
+	l.bytes = 0
+	l.maxBytes = 0
+	l.exceeded = false
+	l.placeholders = o.Placeholders
+	l.readChar()
+}
+
+// Limit caps total bytes to read from the input.
+// After the limit is exceeded, lexer stops reading and yields EOF token,
+// the caller could check it via Exceeded().
+func (l *Lexer) Limit(maxBytes int64) {
+	l.maxBytes = maxBytes
+}
+
+// SetPlaceholders adds template placeholder delimiters, see WithPlaceholders
+func (l *Lexer) SetPlaceholders(placeholders ...Placeholder) {
+	l.placeholders = append(l.placeholders, placeholders...)
+}
+
+// Exceeded returns true when the input exceeds the limit which is set by Limit()
+func (l *Lexer) Exceeded() bool {
+	return l.exceeded
+}
+
 func (l *Lexer) readChar() {
-	r, _, err := l.r.ReadRune()
+	if l.exceeded {
+		l.char = 0x00
+		l.index += 1
+		return
+	}
+	r, size, err := l.r.ReadRune()
 	if err != nil {
 		l.char = 0x00
 		l.index += 1
 		return
 	}
+	l.bytes += int64(size)
+	if l.maxBytes > 0 && l.bytes > l.maxBytes {
+		// Treat as EOF to stop reading untrusted large input
+		l.exceeded = true
+		l.char = 0x00
+		l.index += 1
+		return
+	}
 	if l.char == 0x0A { // LF
 		l.NewLine()
 	}
@@ -85,6 +143,19 @@ func (l *Lexer) PeekToken() token.Token {
 	return t
 }
 
+// Tokens yields all tokens including COMMENT and LF to the callback until EOF token is yielded
+// or the callback returns false. This is useful for external tools like syntax highlighters
+// which need tokens with position information without building AST.
+// The callback signature is compatible with iter.Seq so it can be used as range-over-func iterator.
+func (l *Lexer) Tokens(yield func(t token.Token) bool) {
+	for {
+		t := l.NextToken()
+		if !yield(t) || t.Type == token.EOF {
+			return
+		}
+	}
+}
+
 // nolint: funlen,gocognit,gocyclo
 func (l *Lexer) NextToken() token.Token {
 	var t token.Token
@@ -98,6 +169,21 @@ func (l *Lexer) NextToken() token.Token {
 	l.skipWhitespace()
 
 	index, line := l.index, l.line
+
+	// Template placeholder is treated as an opaque identifier
+	if p, ok := l.matchPlaceholder(); ok {
+		t = newToken(token.IDENT, l.char, line, index)
+		t.Literal = l.readPlaceholder(p)
+		t.File = l.file
+		t.Placeholder = true
+		// Unterminated placeholder reaches to EOF, report as illegal token
+		if !strings.HasSuffix(t.Literal, p.Close) {
+			t.Type = token.ILLEGAL
+			t.Unterminated = true
+		}
+		return t
+	}
+
 	switch l.char {
 	case '=':
 		if l.peekChar() == '=' {
@@ -124,6 +210,7 @@ func (l *Lexer) NextToken() token.Token {
 			t = newToken(token.STRING, l.char, line, index)
 			t.Literal = l.readBracketString()
 			t.Offset = 4 // {" and "}
+			t.Unterminated = l.char == 0x00
 		} else {
 			t = newToken(token.LEFT_BRACE, l.char, line, index)
 		}
@@ -141,6 +228,7 @@ func (l *Lexer) NextToken() token.Token {
 		t = newToken(token.STRING, l.char, line, index)
 		t.Literal = l.readString()
 		t.Offset = 2 // a couple of "
+		t.Unterminated = l.char == 0x00
 	case ';':
 		t = newToken(token.SEMICOLON, l.char, line, index)
 	case '.':
@@ -159,6 +247,7 @@ func (l *Lexer) NextToken() token.Token {
 		case '*': // "/*"
 			t = newToken(token.COMMENT, l.char, line, index)
 			t.Literal = l.readMultiComment()
+			t.Unterminated = l.char == 0x00
 		default:
 			t = newToken(token.SLASH, l.char, line, index)
 		}
@@ -449,6 +538,41 @@ func (l *Lexer) readBracketString() string {
 	return string(rs)
 }
 
+func (l *Lexer) matchPlaceholder() (Placeholder, bool) {
+	for _, p := range l.placeholders {
+		if p.Open == "" || p.Close == "" || l.char != rune(p.Open[0]) {
+			continue
+		}
+		if len(p.Open) == 1 {
+			return p, true
+		}
+		b, err := l.r.Peek(len(p.Open) - 1)
+		if err != nil {
+			continue
+		}
+		if string(b) == p.Open[1:] {
+			return p, true
+		}
+	}
+	return Placeholder{}, false
+}
+
+func (l *Lexer) readPlaceholder(p Placeholder) string {
+	var rs []rune
+	for i := 0; i < len(p.Open); i++ {
+		rs = append(rs, l.char)
+		l.readChar()
+	}
+	for l.char != 0x00 {
+		rs = append(rs, l.char)
+		l.readChar()
+		if strings.HasSuffix(string(rs), p.Close) {
+			break
+		}
+	}
+	return string(rs)
+}
+
 func (l *Lexer) readNumber() string {
 	var rs []rune
 	for isDigit(l.char) {
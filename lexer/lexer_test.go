@@ -590,6 +590,44 @@ func TestEscapeSequence(t *testing.T) {
 	}
 }
 
+func TestUnterminated(t *testing.T) {
+	tests := []struct {
+		input  string
+		expect bool
+	}{
+		{input: `"foo"`, expect: false},
+		{input: `"foo`, expect: true},
+		{input: `{"foo"}`, expect: false},
+		{input: `{"foo"`, expect: true},
+		{input: `/* foo */`, expect: false},
+		{input: `/* foo`, expect: true},
+	}
+
+	for i, tt := range tests {
+		tok := NewFromString(tt.input).NextToken()
+		if tok.Unterminated != tt.expect {
+			t.Errorf(`Tests[%d] unterminated flag expects %t but got %t`, i, tt.expect, tok.Unterminated)
+		}
+	}
+}
+
+func TestLimit(t *testing.T) {
+	l := NewFromString(`set req.http.Foo = "bar";`)
+	l.Limit(10)
+
+	var last token.Token
+	l.Tokens(func(tok token.Token) bool {
+		last = tok
+		return true
+	})
+	if !l.Exceeded() {
+		t.Errorf("Expected limit is exceeded")
+	}
+	if last.Type != token.EOF || last.Position > 12 {
+		t.Errorf("Expected lexer stops reading at limit, got %v", last)
+	}
+}
+
 func TestPeekToken(t *testing.T) {
 	input := `set var.expires`
 	l := NewFromString(input)
@@ -614,3 +652,86 @@ func TestPeekToken(t *testing.T) {
 		t.Errorf(`Assertion failed, diff= %s`, diff)
 	}
 }
+
+func TestTemplatePlaceholder(t *testing.T) {
+	input := `set req.http.Host = {{ .Host }} %{BACKEND_NAME};`
+	expects := []token.Token{
+		{Type: token.SET, Literal: "set", Line: 1, Position: 1},
+		{Type: token.IDENT, Literal: "req.http.Host", Line: 1, Position: 5},
+		{Type: token.ASSIGN, Literal: "=", Line: 1, Position: 19},
+		{Type: token.IDENT, Literal: "{{ .Host }}", Line: 1, Position: 21, Placeholder: true},
+		{Type: token.IDENT, Literal: "%{BACKEND_NAME}", Line: 1, Position: 33, Placeholder: true},
+		{Type: token.SEMICOLON, Literal: ";", Line: 1, Position: 48},
+		{Type: token.EOF, Literal: "", Line: 1, Position: 49},
+	}
+
+	l := NewFromString(input, WithPlaceholders(DefaultPlaceholders...))
+	for i, tt := range expects {
+		tok := l.NextToken()
+
+		if diff := cmp.Diff(tt, tok, cmpopts.IgnoreFields(token.Token{}, "Offset")); diff != "" {
+			t.Errorf(`Tests[%d] failed, diff= %s`, i, diff)
+		}
+	}
+}
+
+func TestUnterminatedTemplatePlaceholder(t *testing.T) {
+	input := `set req.http.Host = {{ .Host ;`
+	l := NewFromString(input, WithPlaceholders(DefaultPlaceholders...))
+	for i := 0; i < 3; i++ {
+		l.NextToken()
+	}
+	expect := token.Token{Type: token.ILLEGAL, Literal: "{{ .Host ;", Line: 1, Position: 21, Unterminated: true, Placeholder: true}
+	if diff := cmp.Diff(expect, l.NextToken(), cmpopts.IgnoreFields(token.Token{}, "Offset")); diff != "" {
+		t.Errorf(`Assertion failed, diff= %s`, diff)
+	}
+}
+
+func TestParsePlaceholder(t *testing.T) {
+	p, err := ParsePlaceholder("%{ }")
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+		return
+	}
+	if diff := cmp.Diff(Placeholder{Open: "%{", Close: "}"}, p); diff != "" {
+		t.Errorf(`Assertion failed, diff= %s`, diff)
+	}
+	if _, err := ParsePlaceholder("{{}}"); err == nil {
+		t.Errorf("Expected error but got nil")
+	}
+}
+
+func TestTokens(t *testing.T) {
+	input := `set req.http.Foo = "bar"; // comment
+`
+	expects := []token.Token{
+		{Type: token.SET, Literal: "set", Line: 1, Position: 1},
+		{Type: token.IDENT, Literal: "req.http.Foo", Line: 1, Position: 5},
+		{Type: token.ASSIGN, Literal: "=", Line: 1, Position: 18},
+		{Type: token.STRING, Literal: "bar", Line: 1, Position: 20},
+		{Type: token.SEMICOLON, Literal: ";", Line: 1, Position: 25},
+		{Type: token.COMMENT, Literal: "// comment", Line: 1, Position: 27},
+		{Type: token.LF, Literal: "\n", Line: 1, Position: 37},
+		{Type: token.EOF, Literal: "", Line: 1, Position: 38},
+	}
+
+	var tokens []token.Token
+	NewFromString(input).Tokens(func(t token.Token) bool {
+		tokens = append(tokens, t)
+		return true
+	})
+	if diff := cmp.Diff(expects, tokens, cmpopts.IgnoreFields(token.Token{}, "Offset")); diff != "" {
+		t.Errorf(`Tokens failed, diff= %s`, diff)
+	}
+
+	t.Run("stop iteration", func(t *testing.T) {
+		var count int
+		NewFromString(input).Tokens(func(t token.Token) bool {
+			count++
+			return count < 2
+		})
+		if count != 2 {
+			t.Errorf("Expected iteration stops at 2, got %d", count)
+		}
+	})
+}
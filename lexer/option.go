@@ -1,18 +1,53 @@
 package lexer
 
+import (
+	"fmt"
+	"strings"
+)
+
 type OptionFunc func(o *Option)
 
 type Option struct {
-	Filename string
+	Filename     string
+	Placeholders []Placeholder
 	// more field if exists
 }
 
+// Placeholder represents template placeholder delimiters like "%{" and "}".
+// When placeholders are provided, lexer treats enclosed text as opaque IDENT token
+// so that templated VCL could be parsed, linted and formatted.
+type Placeholder struct {
+	Open  string
+	Close string
+}
+
+// Commonly used template placeholder delimiters
+var DefaultPlaceholders = []Placeholder{
+	{Open: "%{", Close: "}"},
+	{Open: "{{", Close: "}}"},
+}
+
+// ParsePlaceholder parses whitespace separated opening and closing delimiters like "{{ }}"
+func ParsePlaceholder(s string) (Placeholder, error) {
+	fields := strings.Fields(s)
+	if len(fields) != 2 {
+		return Placeholder{}, fmt.Errorf(`Invalid placeholder "%s", opening and closing delimiters must be separated by whitespace like "{{ }}"`, s)
+	}
+	return Placeholder{Open: fields[0], Close: fields[1]}, nil
+}
+
 func WithFile(filename string) OptionFunc {
 	return func(o *Option) {
 		o.Filename = filename
 	}
 }
 
+func WithPlaceholders(placeholders ...Placeholder) OptionFunc {
+	return func(o *Option) {
+		o.Placeholders = append(o.Placeholders, placeholders...)
+	}
+}
+
 func collect(opts []OptionFunc) *Option {
 	o := &Option{
 		Filename: "",
@@ -0,0 +1,101 @@
+package linter
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/ysugimoto/falco/ast"
+)
+
+type aclEntry struct {
+	cidr    *ast.AclCidr
+	network *net.IPNet
+	inverse bool
+}
+
+func (e *aclEntry) String() string {
+	prefix := ""
+	if e.inverse {
+		prefix = "!"
+	}
+	return prefix + e.network.String()
+}
+
+// contains returns true when all addresses of other entry are contained in the entry
+func (e *aclEntry) contains(other *aclEntry) bool {
+	ones, bits := e.network.Mask.Size()
+	otherOnes, otherBits := other.network.Mask.Size()
+	return bits == otherBits && ones <= otherOnes && e.network.Contains(other.network.IP)
+}
+
+func (e *aclEntry) equals(other *aclEntry) bool {
+	return e.contains(other) && other.contains(e)
+}
+
+func parseAclEntry(cidr *ast.AclCidr) *aclEntry {
+	ip := net.ParseIP(cidr.IP.Value)
+	if ip == nil {
+		return nil
+	}
+	bits := 128
+	if v4 := ip.To4(); v4 != nil {
+		ip, bits = v4, 32
+	}
+	ones := bits
+	if cidr.Mask != nil {
+		ones = int(cidr.Mask.Value)
+	}
+	if ones < 0 || ones > bits {
+		return nil
+	}
+	mask := net.CIDRMask(ones, bits)
+	return &aclEntry{
+		cidr:    cidr,
+		network: &net.IPNet{IP: ip.Mask(mask), Mask: mask},
+		inverse: cidr.Inverse != nil && cidr.Inverse.Value,
+	}
+}
+
+// lintAclOverlap reports ACL entries which are duplicated, covered by earlier entries,
+// and negated entries which never exclude any addresses because no other entry matches them.
+func (l *Linter) lintAclOverlap(decl *ast.AclDeclaration) {
+	var entries []*aclEntry
+	for _, cidr := range decl.CIDRs {
+		// Invalid entry is reported as syntax error
+		if e := parseAclEntry(cidr); e != nil {
+			entries = append(entries, e)
+		}
+	}
+
+	for i, e := range entries {
+		var message string
+		for _, prev := range entries[:i] {
+			switch {
+			case prev.equals(e) && prev.inverse == e.inverse:
+				message = fmt.Sprintf("ACL entry %s is duplicated with the entry at line %d", e, prev.cidr.GetMeta().Token.Line)
+			case prev.equals(e):
+				message = fmt.Sprintf("ACL entry %s conflicts with the entry %s at line %d", e, prev, prev.cidr.GetMeta().Token.Line)
+			case prev.contains(e) && prev.inverse == e.inverse:
+				message = fmt.Sprintf("ACL entry %s is a subset of the entry %s at line %d", e, prev, prev.cidr.GetMeta().Token.Line)
+			default:
+				continue
+			}
+			break
+		}
+		if message == "" && e.inverse && !containedInPositiveEntry(e, entries) {
+			message = fmt.Sprintf("Negated ACL entry %s never matches because no other entry includes its addresses", e)
+		}
+		if message != "" {
+			l.Error(AclEntryOverlap(e.cidr.GetMeta(), message).Match(ACL_OVERLAP))
+		}
+	}
+}
+
+func containedInPositiveEntry(e *aclEntry, entries []*aclEntry) bool {
+	for _, other := range entries {
+		if other != e && !other.inverse && other.contains(e) {
+			return true
+		}
+	}
+	return false
+}
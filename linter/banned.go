@@ -0,0 +1,84 @@
+package linter
+
+import (
+	"strings"
+
+	"github.com/ysugimoto/falco/ast"
+)
+
+// Kinds of identifiers which could be banned by banned/identifier rule options
+const (
+	bannedFunction = "function"
+	bannedVariable = "variable"
+	bannedHeader   = "header"
+	bannedBackend  = "backend"
+)
+
+// bannedOptionKeys maps the kind to the rule option key which holds banned names and custom messages
+var bannedOptionKeys = map[string]string{
+	bannedFunction: "functions",
+	bannedVariable: "variables",
+	bannedHeader:   "headers",
+	bannedBackend:  "backends",
+}
+
+// bannedMessage returns the custom message of banned identifier, names are compared case-insensitively
+func (l *Linter) bannedMessage(kind, name string) (string, bool) {
+	for banned, message := range l.stringMapRuleOption(BANNED_IDENTIFIER, bannedOptionKeys[kind]) {
+		if strings.EqualFold(banned, name) {
+			return message, true
+		}
+	}
+	return "", false
+}
+
+// lintBannedIdentifiers reports functions, variables, headers and backends which are denylisted in the rule options
+func (l *Linter) lintBannedIdentifiers(decl *ast.SubroutineDeclaration) {
+	defer l.measure(BANNED_IDENTIFIER)()
+	if len(l.option.RuleOptions[BANNED_IDENTIFIER]) == 0 {
+		return
+	}
+
+	for _, n := range ast.NewTree(decl.Block).Nodes() {
+		switch t := n.(type) {
+		case *ast.FunctionCallExpression:
+			if message, ok := l.bannedMessage(bannedFunction, t.Function.Value); ok {
+				l.Error(BannedIdentifier(t.Function.GetMeta(), bannedFunction, t.Function.Value, message).Match(BANNED_IDENTIFIER))
+			}
+		case *ast.Ident:
+			l.lintBannedIdent(t)
+		}
+	}
+}
+
+func (l *Linter) lintBannedIdent(ident *ast.Ident) {
+	if _, ok := isDeclarationIdent(ident); ok {
+		l.lintBannedBackend(ident)
+		return
+	}
+	if message, ok := l.bannedMessage(bannedVariable, ident.Value); ok {
+		l.Error(BannedIdentifier(ident.GetMeta(), bannedVariable, ident.Value, message).Match(BANNED_IDENTIFIER))
+		return
+	}
+
+	// Header is banned in any of req, resp, bereq, beresp and obj
+	index := strings.Index(ident.Value, ".http.")
+	if index < 0 {
+		return
+	}
+	name := ident.Value[index+6:]
+	// Subfield like req.http.Cookie:session
+	if i := strings.Index(name, ":"); i >= 0 {
+		name = name[:i]
+	}
+	if message, ok := l.bannedMessage(bannedHeader, name); ok {
+		l.Error(BannedIdentifier(ident.GetMeta(), bannedHeader, name, message).Match(BANNED_IDENTIFIER))
+	}
+}
+
+// lintBannedBackend reports the reference of banned backend, used for subroutines and director declarations
+func (l *Linter) lintBannedBackend(ident *ast.Ident) {
+	if message, ok := l.bannedMessage(bannedBackend, ident.Value); ok {
+		l.Error(BannedIdentifier(ident.GetMeta(), bannedBackend, ident.Value, message).Match(BANNED_IDENTIFIER))
+	}
+}
@@ -0,0 +1,84 @@
+package linter
+
+import (
+	"github.com/ysugimoto/falco/ast"
+	"github.com/ysugimoto/falco/context"
+	"github.com/ysugimoto/falco/parser"
+)
+
+// propagateCallScopes propagates scopes of subroutines which declare its scope, e.g. state-machine subroutines,
+// to the subroutines which they call, through call statements and functional subroutine calls.
+// Returns the union of scopes that each subroutine could be called in.
+// Subroutines which declare its scope explicitly are not affected.
+func propagateCallScopes(statements []ast.Statement, ctx *context.Context) map[string]int {
+	decls := make(map[string]*ast.SubroutineDeclaration)
+	for _, stmt := range statements {
+		if decl, ok := stmt.(*ast.SubroutineDeclaration); ok {
+			decls[decl.Name.Value] = decl
+		}
+	}
+
+	scopes := make(map[string]int)
+	explicit := make(map[string]struct{})
+	var queue []string
+	for name, decl := range decls {
+		if scope := explicitScope(decl, ctx); scope > 0 {
+			scopes[name] = scope
+			explicit[name] = struct{}{}
+			queue = append(queue, name)
+		}
+	}
+
+	for len(queue) > 0 {
+		caller := queue[0]
+		queue = queue[1:]
+		for _, callee := range calledSubroutines(decls[caller]) {
+			if _, ok := decls[callee]; !ok {
+				continue
+			}
+			if _, ok := explicit[callee]; ok {
+				continue
+			}
+			if merged := scopes[callee] | scopes[caller]; merged != scopes[callee] {
+				scopes[callee] = merged
+				queue = append(queue, callee)
+			}
+		}
+	}
+	return scopes
+}
+
+// calledSubroutines returns names of subroutines which are called in the subroutine
+func calledSubroutines(decl *ast.SubroutineDeclaration) []string {
+	var names []string
+	for _, n := range ast.NewTree(decl.Block).Nodes() {
+		switch t := n.(type) {
+		case *ast.CallStatement:
+			names = append(names, t.Subroutine.Value)
+		case *ast.FunctionCallExpression:
+			names = append(names, t.Function.Value)
+		}
+	}
+	return names
+}
+
+func explicitScope(decl *ast.SubroutineDeclaration, ctx *context.Context) int {
+	if ctx.Dialect() == parser.DialectVarnish4 {
+		if v, ok := varnish4SubroutineScopes[decl.Name.Value]; ok {
+			return v
+		}
+	}
+	return getSubroutineExplicitScope(decl)
+}
+
+// subroutineScope returns scopes which the subroutine is linted in.
+// Explicit scope is used firstly, then scopes of its callers, and RECV as default.
+func (l *Linter) subroutineScope(decl *ast.SubroutineDeclaration, ctx *context.Context) int {
+	if scope := explicitScope(decl, ctx); scope > 0 {
+		return scope
+	}
+	if scope := l.callScopes[decl.Name.Value]; scope > 0 {
+		return scope
+	}
+	return context.RECV
+}
@@ -0,0 +1,89 @@
+package linter
+
+import (
+	"strings"
+
+	"github.com/ysugimoto/falco/ast"
+)
+
+// Headers whose values are case-insensitive by HTTP specification
+var caseInsensitiveHeaders = map[string]struct{}{
+	"host":              {},
+	"accept-encoding":   {},
+	"connection":        {},
+	"content-encoding":  {},
+	"content-type":      {},
+	"transfer-encoding": {},
+	"upgrade":           {},
+	"x-forwarded-proto": {},
+}
+
+// Variables whose values are case-sensitive
+var caseSensitiveVariables = map[string]struct{}{
+	"req.method":         {},
+	"req.url":            {},
+	"req.url.path":       {},
+	"req.url.dirname":    {},
+	"req.url.basename":   {},
+	"req.url.qs":         {},
+	"bereq.method":       {},
+	"bereq.url":          {},
+	"bereq.url.path":     {},
+	"bereq.url.dirname":  {},
+	"bereq.url.basename": {},
+	"bereq.url.qs":       {},
+}
+
+// lintCaseSensitivity reports string comparisons in the condition which likely have wrong case sensitivity.
+// "==" and "!=" compare strings case-sensitively, but some header values should be compared case-insensitively,
+// and vice versa, case-insensitive regex matching on case-sensitive values like URL path could match unexpected values.
+func (l *Linter) lintCaseSensitivity(cond ast.Expression) {
+	for _, n := range ast.NewTree(cond).Nodes() {
+		infix, ok := n.(*ast.InfixExpression)
+		if !ok {
+			continue
+		}
+		ident, ok := infix.Left.(*ast.Ident)
+		if !ok {
+			continue
+		}
+		literal, ok := infix.Right.(*ast.String)
+		if !ok {
+			continue
+		}
+
+		switch infix.Operator {
+		case "==", "!=":
+			header, ok := caseInsensitiveHeader(ident.Value)
+			if !ok || literal.Value == "" {
+				continue
+			}
+			// Clients send hostname in lowercase in practice, then exact match with lowercase hostname is allowed
+			if strings.EqualFold(header, "host") && literal.Value == strings.ToLower(literal.Value) {
+				continue
+			}
+			l.Error(CaseSensitiveComparison(infix.GetMeta(), header).Match(COMPARISON_CASE_SENSITIVITY))
+		case "~", "!~":
+			if _, ok := caseSensitiveVariables[ident.Value]; ok && strings.HasPrefix(literal.Value, "(?i)") {
+				l.Error(CaseInsensitiveMatch(infix.GetMeta(), ident.Value).Match(COMPARISON_CASE_SENSITIVITY))
+			}
+		}
+	}
+}
+
+// caseInsensitiveHeader returns header name when the variable is the header whose value is case-insensitive
+func caseInsensitiveHeader(name string) (string, bool) {
+	index := strings.Index(name, ".http.")
+	if index < 0 {
+		return "", false
+	}
+	header := name[index+6:]
+	// Subfield like req.http.Content-Type:charset could be compared as it is
+	if strings.Contains(header, ":") {
+		return "", false
+	}
+	if _, ok := caseInsensitiveHeaders[strings.ToLower(header)]; !ok {
+		return "", false
+	}
+	return header, true
+}
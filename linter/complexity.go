@@ -0,0 +1,91 @@
+package linter
+
+import (
+	"github.com/ysugimoto/falco/ast"
+)
+
+const (
+	defaultMaxCyclomaticComplexity = 20
+	defaultMaxNestingDepth         = 5
+)
+
+// Complexity is complexity metrics of the subroutine
+type Complexity struct {
+	Subroutine string `json:"subroutine"`
+	File       string `json:"file"`
+	Line       int    `json:"line"`
+	// Number of linearly independent paths, 1 + number of branches and boolean operators
+	Cyclomatic int `json:"cyclomatic"`
+	// Maximum depth of nested blocks, statements in the subroutine block is zero
+	Nesting int `json:"nesting"`
+}
+
+// Complexities returns complexity metrics of linted subroutines
+func (l *Linter) Complexities() []*Complexity {
+	return l.complexities
+}
+
+// measureComplexity calculates complexity metrics of the subroutine
+func measureComplexity(decl *ast.SubroutineDeclaration) *Complexity {
+	c := &Complexity{
+		Subroutine: decl.Name.Value,
+		File:       decl.GetMeta().Token.File,
+		Line:       decl.GetMeta().Token.Line,
+		Cyclomatic: 1,
+		Nesting:    nestingDepth(decl.Block.Statements, 0),
+	}
+
+	for _, n := range ast.NewTree(decl.Block).Nodes() {
+		switch t := n.(type) {
+		// Else-if branches are also IfStatement node
+		case *ast.IfStatement, *ast.IfExpression:
+			c.Cyclomatic++
+		case *ast.InfixExpression:
+			if t.Operator == "&&" || t.Operator == "||" {
+				c.Cyclomatic++
+			}
+		}
+	}
+	return c
+}
+
+// nestingDepth returns the maximum depth of nested blocks in the statements
+func nestingDepth(statements []ast.Statement, depth int) int {
+	deepest := depth
+	nest := func(block *ast.BlockStatement) {
+		if block == nil {
+			return
+		}
+		if d := nestingDepth(block.Statements, depth+1); d > deepest {
+			deepest = d
+		}
+	}
+
+	for _, stmt := range statements {
+		switch t := stmt.(type) {
+		case *ast.BlockStatement:
+			nest(t)
+		case *ast.IfStatement:
+			nest(t.Consequence)
+			for _, a := range t.Another {
+				nest(a.Consequence)
+			}
+			nest(t.Alternative)
+		}
+	}
+	return deepest
+}
+
+// lintComplexity reports the subroutine which exceeds complexity thresholds and records its metrics
+func (l *Linter) lintComplexity(decl *ast.SubroutineDeclaration) {
+	defer l.measure(Rule("complexity"))()
+	c := measureComplexity(decl)
+	l.complexities = append(l.complexities, c)
+
+	if max := l.intRuleOption(COMPLEXITY_CYCLOMATIC, "max", defaultMaxCyclomaticComplexity); c.Cyclomatic > max {
+		l.Error(TooComplex(decl.Name.GetMeta(), decl.Name.Value, "cyclomatic complexity", c.Cyclomatic, max).Match(COMPLEXITY_CYCLOMATIC))
+	}
+	if max := l.intRuleOption(COMPLEXITY_NESTING, "max", defaultMaxNestingDepth); c.Nesting > max {
+		l.Error(TooComplex(decl.Name.GetMeta(), decl.Name.Value, "nesting depth", c.Nesting, max).Match(COMPLEXITY_NESTING))
+	}
+}
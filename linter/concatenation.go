@@ -0,0 +1,125 @@
+package linter
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/ysugimoto/falco/ast"
+	"github.com/ysugimoto/falco/context"
+)
+
+const (
+	// Self-appending to the same variable more than this count in a subroutine is reported
+	defaultMaxSelfAppends = 5
+	// Concatenation which has more operands than this count in a single expression is reported
+	defaultMaxConcatenationOperands = 20
+)
+
+// lintConcatenations reports string concatenations which allocate workspace memory inefficiently.
+// Each concatenation allocates a new string in workspace and the old value is not freed until the request ends, so:
+// - repeated self-appending like `set req.http.X = req.http.X + ...` allocates the whole value each time
+// - self-appending to request headers in vcl_recv is repeated on every restart because request headers persist
+// - very long single concatenation allocates large intermediate strings
+// Appending values by add statement and joining them by std.collect() is recommended instead.
+func (l *Linter) lintConcatenations(ctx *context.Context) {
+	defer l.measure(CONCATENATION_INEFFICIENT)()
+
+	var decls []*ast.SubroutineDeclaration
+	var restarts bool
+	for _, s := range ctx.Subroutines {
+		if s.Decl == nil {
+			continue
+		}
+		decls = append(decls, s.Decl)
+		for _, n := range ast.NewTree(s.Decl.Block).Nodes() {
+			if _, ok := n.(*ast.RestartStatement); ok {
+				restarts = true
+			}
+		}
+	}
+	sort.Slice(decls, func(i, j int) bool {
+		return lessMeta(decls[i].GetMeta(), decls[j].GetMeta())
+	})
+
+	maxAppends := l.intRuleOption(CONCATENATION_INEFFICIENT, "max_appends", defaultMaxSelfAppends)
+	maxOperands := l.intRuleOption(CONCATENATION_INEFFICIENT, "max_operands", defaultMaxConcatenationOperands)
+	for _, decl := range decls {
+		inRecv := l.subroutineScope(decl, ctx)&context.RECV > 0
+		tree := ast.NewTree(decl.Block)
+		appends := make(map[string]int)
+		for _, n := range tree.Nodes() {
+			set, ok := n.(*ast.SetStatement)
+			if !ok {
+				continue
+			}
+			if operands := concatenationOperands(set.Value); operands > maxOperands {
+				l.Error(LongConcatenation(set.Value.GetMeta(), operands, maxOperands).Match(CONCATENATION_INEFFICIENT))
+			}
+			if !isSelfAppend(set) {
+				continue
+			}
+			name := strings.ToLower(set.Ident.Value)
+			appends[name]++
+			if appends[name] == maxAppends+1 {
+				l.Error(RepeatedSelfAppend(set.GetMeta(), set.Ident.Value, appends[name]).Match(CONCATENATION_INEFFICIENT))
+			}
+			if restarts && inRecv && strings.HasPrefix(name, "req.http.") && !guardedByRestarts(tree, set) {
+				l.Error(SelfAppendOnRestart(set.GetMeta(), set.Ident.Value).Match(CONCATENATION_INEFFICIENT))
+			}
+		}
+	}
+}
+
+// isSelfAppend returns true if the statement appends the value to the variable itself
+// like `set req.http.X = req.http.X + "value"` or `set req.http.X += "value"`
+func isSelfAppend(set *ast.SetStatement) bool {
+	if set.Operator != nil && set.Operator.Operator == "+=" {
+		return true
+	}
+	exp := set.Value
+	for {
+		switch t := exp.(type) {
+		case *ast.GroupedExpression:
+			exp = t.Right
+			continue
+		case *ast.InfixExpression:
+			if t.Operator != "+" {
+				return false
+			}
+			exp = t.Left
+			continue
+		case *ast.Ident:
+			return exp != set.Value && strings.EqualFold(t.Value, set.Ident.Value)
+		}
+		return false
+	}
+}
+
+// concatenationOperands returns the number of operands in the concatenation
+func concatenationOperands(exp ast.Expression) int {
+	switch t := exp.(type) {
+	case *ast.GroupedExpression:
+		return concatenationOperands(t.Right)
+	case *ast.InfixExpression:
+		if t.Operator == "+" {
+			return concatenationOperands(t.Left) + concatenationOperands(t.Right)
+		}
+	}
+	return 1
+}
+
+// guardedByRestarts returns true if the statement is in the branch which checks req.restarts
+func guardedByRestarts(tree *ast.Tree, node ast.Node) bool {
+	for _, p := range tree.Ancestors(node) {
+		stmt, ok := p.(*ast.IfStatement)
+		if !ok {
+			continue
+		}
+		for _, n := range ast.NewTree(stmt.Condition).Nodes() {
+			if ident, ok := n.(*ast.Ident); ok && ident.Value == "req.restarts" {
+				return true
+			}
+		}
+	}
+	return false
+}
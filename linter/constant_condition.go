@@ -0,0 +1,252 @@
+package linter
+
+import (
+	"github.com/ysugimoto/falco/ast"
+	"github.com/ysugimoto/falco/types"
+)
+
+// Predefined variables which always have the same value
+var knownConstants = map[string]int64{
+	"math.FLOAT_DIG":        15,
+	"math.FLOAT_MANT_DIG":   53,
+	"math.FLOAT_MAX_10_EXP": 308,
+	"math.FLOAT_MAX_EXP":    1024,
+	"math.FLOAT_MIN_10_EXP": -307,
+	"math.FLOAT_MIN_EXP":    -1021,
+	"math.FLOAT_RADIX":      2,
+	"math.INTEGER_BIT":      64,
+	"math.INTEGER_MAX":      9223372036854775807,
+	"math.INTEGER_MIN":      -9223372036854775808,
+}
+
+// Status code variables could have the value between 100 and 999
+var statusVariables = map[string]struct{}{
+	"resp.status":   {},
+	"beresp.status": {},
+	"obj.status":    {},
+}
+
+const (
+	minStatusCode = 100
+	maxStatusCode = 999
+)
+
+// constant is a value of the expression which could be evaluated statically
+type constant struct {
+	Type    types.Type
+	String  string
+	Integer int64
+	Float   float64
+	Bool    bool
+}
+
+func (c *constant) isNumeric() bool {
+	return c.Type == types.IntegerType || c.Type == types.FloatType || c.Type == types.RTimeType
+}
+
+// lintConstantCondition reports the condition which is always true or always false
+func (l *Linter) lintConstantCondition(cond ast.Expression) {
+	if result, ok := foldCondition(cond); ok {
+		l.Error(ConstantCondition(cond.GetMeta(), result).Match(CONDITION_CONSTANT))
+	}
+}
+
+// foldCondition evaluates the condition statically, the second value is false when the result depends on runtime values
+func foldCondition(exp ast.Expression) (bool, bool) {
+	switch t := exp.(type) {
+	case *ast.GroupedExpression:
+		return foldCondition(t.Right)
+	case *ast.PrefixExpression:
+		if t.Operator != "!" {
+			return false, false
+		}
+		v, ok := foldCondition(t.Right)
+		return !v, ok
+	case *ast.InfixExpression:
+		switch t.Operator {
+		case "&&":
+			left, lok := foldCondition(t.Left)
+			right, rok := foldCondition(t.Right)
+			// Either side is false, whole condition is false regardless of the other side
+			if (lok && !left) || (rok && !right) {
+				return false, true
+			}
+			return true, lok && rok
+		case "||":
+			left, lok := foldCondition(t.Left)
+			right, rok := foldCondition(t.Right)
+			if (lok && left) || (rok && right) {
+				return true, true
+			}
+			return false, lok && rok
+		}
+		if v, ok := foldStatusComparison(t); ok {
+			return v, true
+		}
+	}
+
+	c, ok := foldConstant(exp)
+	if !ok || c.Type != types.BoolType {
+		return false, false
+	}
+	return c.Bool, true
+}
+
+// foldConstant evaluates the expression which consists of literals and known constants
+func foldConstant(exp ast.Expression) (*constant, bool) {
+	switch t := exp.(type) {
+	case *ast.Boolean:
+		return &constant{Type: types.BoolType, Bool: t.Value}, true
+	case *ast.Integer:
+		return &constant{Type: types.IntegerType, Integer: t.Value, Float: float64(t.Value)}, true
+	case *ast.Float:
+		return &constant{Type: types.FloatType, Float: t.Value}, true
+	case *ast.RTime:
+		return &constant{Type: types.RTimeType, Float: t.Duration.Seconds()}, true
+	case *ast.String:
+		return &constant{Type: types.StringType, String: t.Value}, true
+	case *ast.Ident:
+		v, ok := knownConstants[t.Value]
+		if !ok {
+			return nil, false
+		}
+		return &constant{Type: types.IntegerType, Integer: v, Float: float64(v)}, true
+	case *ast.GroupedExpression:
+		return foldConstant(t.Right)
+	case *ast.PrefixExpression:
+		right, ok := foldConstant(t.Right)
+		if !ok {
+			return nil, false
+		}
+		switch {
+		case t.Operator == "!" && right.Type == types.BoolType:
+			return &constant{Type: types.BoolType, Bool: !right.Bool}, true
+		case t.Operator == "-" && right.isNumeric():
+			return &constant{Type: right.Type, Integer: -right.Integer, Float: -right.Float}, true
+		}
+		return nil, false
+	case *ast.InfixExpression:
+		left, ok := foldConstant(t.Left)
+		if !ok {
+			return nil, false
+		}
+		right, ok := foldConstant(t.Right)
+		if !ok {
+			return nil, false
+		}
+		return foldInfix(t.Operator, left, right)
+	}
+	return nil, false
+}
+
+func foldInfix(operator string, left, right *constant) (*constant, bool) {
+	result := func(v bool) (*constant, bool) {
+		return &constant{Type: types.BoolType, Bool: v}, true
+	}
+
+	switch operator {
+	case "+":
+		if left.Type == types.StringType && right.Type == types.StringType {
+			return &constant{Type: types.StringType, String: left.String + right.String}, true
+		}
+	case "==", "!=":
+		if left.Type != right.Type {
+			return nil, false
+		}
+		var equal bool
+		switch left.Type {
+		case types.StringType:
+			equal = left.String == right.String
+		case types.BoolType:
+			equal = left.Bool == right.Bool
+		case types.IntegerType:
+			equal = left.Integer == right.Integer
+		default:
+			equal = left.Float == right.Float
+		}
+		return result(equal == (operator == "=="))
+	case "<", ">", "<=", ">=":
+		if !left.isNumeric() || !right.isNumeric() {
+			return nil, false
+		}
+		if left.Type == types.IntegerType && right.Type == types.IntegerType {
+			return result(compareInteger(operator, left.Integer, right.Integer))
+		}
+		return result(compareFloat(operator, left.Float, right.Float))
+	case "&&":
+		if left.Type == types.BoolType && right.Type == types.BoolType {
+			return result(left.Bool && right.Bool)
+		}
+	case "||":
+		if left.Type == types.BoolType && right.Type == types.BoolType {
+			return result(left.Bool || right.Bool)
+		}
+	}
+	return nil, false
+}
+
+// foldStatusComparison evaluates comparison of status code variable and integer,
+// which is always true or false when the integer is out of range of status code
+func foldStatusComparison(exp *ast.InfixExpression) (bool, bool) {
+	ident, ok := exp.Left.(*ast.Ident)
+	if !ok {
+		return false, false
+	}
+	if _, ok := statusVariables[ident.Value]; !ok {
+		return false, false
+	}
+	right, ok := foldConstant(exp.Right)
+	if !ok || right.Type != types.IntegerType {
+		return false, false
+	}
+
+	// Result is constant when the comparison has the same result for both of the minimum and maximum
+	lower := compareInteger(exp.Operator, minStatusCode, right.Integer)
+	upper := compareInteger(exp.Operator, maxStatusCode, right.Integer)
+	inRange := right.Integer >= minStatusCode && right.Integer <= maxStatusCode
+	switch exp.Operator {
+	case "==", "!=":
+		if inRange {
+			return false, false
+		}
+		return exp.Operator == "!=", true
+	case "<", ">", "<=", ">=":
+		if lower != upper {
+			return false, false
+		}
+		return lower, true
+	}
+	return false, false
+}
+
+func compareInteger(operator string, left, right int64) bool {
+	switch operator {
+	case "==":
+		return left == right
+	case "!=":
+		return left != right
+	case "<":
+		return left < right
+	case ">":
+		return left > right
+	case "<=":
+		return left <= right
+	case ">=":
+		return left >= right
+	}
+	return false
+}
+
+func compareFloat(operator string, left, right float64) bool {
+	switch operator {
+	case "<":
+		return left < right
+	case ">":
+		return left > right
+	case "<=":
+		return left <= right
+	case ">=":
+		return left >= right
+	}
+	return false
+}
@@ -0,0 +1,58 @@
+package linter
+
+import (
+	"sync"
+
+	"github.com/ysugimoto/falco/ast"
+	"github.com/ysugimoto/falco/context"
+)
+
+// CustomRule is the interface for user-defined lint rules, e.g. organization's naming conventions.
+// Check is called for every node which linter visits, including declarations, statements and expressions.
+// Returned errors could omit Severity and Rule, then Severity() and Name() are used.
+type CustomRule interface {
+	Name() Rule
+	Severity() Severity
+	Check(node ast.Node, ctx *context.Context) []*LintError
+}
+
+var (
+	customRulesMu sync.RWMutex
+	customRules   []CustomRule
+)
+
+// Register registers custom rules globally, all linters created after registration check them.
+// This is typically called from init() of the package which is compiled into custom falco binary.
+func Register(rules ...CustomRule) {
+	customRulesMu.Lock()
+	defer customRulesMu.Unlock()
+
+	customRules = append(customRules, rules...)
+}
+
+func registeredRules() []CustomRule {
+	customRulesMu.RLock()
+	defer customRulesMu.RUnlock()
+
+	return append([]CustomRule{}, customRules...)
+}
+
+func (l *Linter) checkCustomRules(node ast.Node, ctx *context.Context) {
+	for _, rule := range l.customRules {
+		done := l.measure(rule.Name())
+		errs := rule.Check(node, ctx)
+		done()
+		for _, err := range errs {
+			if err == nil {
+				continue
+			}
+			if err.Severity == "" {
+				err.Severity = rule.Severity()
+			}
+			if err.Rule == "" {
+				err.Rule = rule.Name()
+			}
+			l.Error(err)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package linter
+
+import (
+	"github.com/ysugimoto/falco/ast"
+)
+
+// Local variables are scoped to the whole subroutine in Fastly, not to the block which declares them.
+// Users who expect block scope write declarations which do not work as intended:
+// - re-declaration in nested block does not shadow the outer declaration, it is a duplicated declaration
+// - variable declared in nested block is still accessible after the block, but it is not set when the block is not executed
+
+// outerDeclaration returns the earlier declaration of the same variable in the subroutine
+// when the declare statement is placed in nested block, or nil
+func outerDeclaration(stmt *ast.DeclareStatement, decl *ast.SubroutineDeclaration) *ast.DeclareStatement {
+	if decl == nil {
+		return nil
+	}
+	tree := ast.NewTree(decl.Block)
+	if nestedBlock(tree, decl, stmt) == nil {
+		return nil
+	}
+	for _, n := range tree.Nodes() {
+		d, ok := n.(*ast.DeclareStatement)
+		if ok && d != stmt && d.Name.Value == stmt.Name.Value && lessMeta(d.GetMeta(), stmt.GetMeta()) {
+			return d
+		}
+	}
+	return nil
+}
+
+// nestedBlock returns the nearest block which encloses the node if it is not the subroutine block, or nil
+func nestedBlock(tree *ast.Tree, decl *ast.SubroutineDeclaration, node ast.Node) *ast.BlockStatement {
+	for _, p := range tree.Ancestors(node) {
+		if block, ok := p.(*ast.BlockStatement); ok {
+			if block == decl.Block {
+				return nil
+			}
+			return block
+		}
+	}
+	return nil
+}
+
+// lintBlockScopedDeclarations reports variables which are declared in nested block but used outside of the block
+func (l *Linter) lintBlockScopedDeclarations(decl *ast.SubroutineDeclaration) {
+	defer l.measure(DECLARE_STATEMENT_SHADOWED)()
+
+	tree := ast.NewTree(decl.Block)
+	nodes := tree.Nodes()
+	for _, n := range nodes {
+		d, ok := n.(*ast.DeclareStatement)
+		if !ok {
+			continue
+		}
+		// Re-declaration of the outer variable is reported on linting the statement
+		block := nestedBlock(tree, decl, d)
+		if block == nil || outerDeclaration(d, decl) != nil {
+			continue
+		}
+		for _, v := range nodes {
+			ident, ok := v.(*ast.Ident)
+			if !ok || ident.Value != d.Name.Value || ident == d.Name || encloses(tree, block, ident) {
+				continue
+			}
+			l.Error(BlockScopedDeclaration(d.Name.GetMeta(), d.Name.Value, ident.GetMeta().Token.Line).Match(DECLARE_STATEMENT_SHADOWED))
+			break
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package linter
+
+import (
+	"github.com/ysugimoto/falco/ast"
+)
+
+// deprecation describes deprecated variable or function and its recommended replacement.
+// Mechanical is true when the name could be replaced with the replacement as it is.
+type deprecation struct {
+	Replacement string
+	Mechanical  bool
+}
+
+// Deprecated variables and functions in Fastly VCL
+var deprecations = map[string]deprecation{
+	// Variables
+	"req.request":               {Replacement: "req.method", Mechanical: true},
+	"bereq.request":             {Replacement: "bereq.method", Mechanical: true},
+	"geoip.area_code":           {Replacement: "client.geo.area_code", Mechanical: true},
+	"geoip.city":                {Replacement: "client.geo.city", Mechanical: true},
+	"geoip.city.ascii":          {Replacement: "client.geo.city.ascii", Mechanical: true},
+	"geoip.city.latin1":         {Replacement: "client.geo.city.latin1", Mechanical: true},
+	"geoip.city.utf8":           {Replacement: "client.geo.city.utf8", Mechanical: true},
+	"geoip.continent_code":      {Replacement: "client.geo.continent_code", Mechanical: true},
+	"geoip.country_code":        {Replacement: "client.geo.country_code", Mechanical: true},
+	"geoip.country_code3":       {Replacement: "client.geo.country_code3", Mechanical: true},
+	"geoip.country_name":        {Replacement: "client.geo.country_name", Mechanical: true},
+	"geoip.country_name.ascii":  {Replacement: "client.geo.country_name.ascii", Mechanical: true},
+	"geoip.country_name.latin1": {Replacement: "client.geo.country_name.latin1", Mechanical: true},
+	"geoip.country_name.utf8":   {Replacement: "client.geo.country_name.utf8", Mechanical: true},
+	"geoip.ip_override":         {Replacement: "client.geo.ip_override", Mechanical: true},
+	"geoip.latitude":            {Replacement: "client.geo.latitude", Mechanical: true},
+	"geoip.longitude":           {Replacement: "client.geo.longitude", Mechanical: true},
+	"geoip.metro_code":          {Replacement: "client.geo.metro_code", Mechanical: true},
+	"geoip.postal_code":         {Replacement: "client.geo.postal_code", Mechanical: true},
+	"geoip.region":              {Replacement: "client.geo.region", Mechanical: true},
+	"geoip.region.ascii":        {Replacement: "client.geo.region.ascii", Mechanical: true},
+	"geoip.region.latin1":       {Replacement: "client.geo.region.latin1", Mechanical: true},
+	"geoip.region.utf8":         {Replacement: "client.geo.region.utf8", Mechanical: true},
+	// BOOL flag could not be replaced, client IP should be assigned to client.geo.ip_override
+	"geoip.use_x_forwarded_for": {Replacement: "client.geo.ip_override"},
+
+	// Functions
+	"boltsort.sort": {Replacement: "querystring.sort", Mechanical: true},
+}
+
+// lintDeprecations reports deprecated variables and functions which are used in the subroutine
+func (l *Linter) lintDeprecations(decl *ast.SubroutineDeclaration) {
+	defer l.measure(DEPRECATED)()
+	for _, n := range ast.NewTree(decl.Block).Nodes() {
+		ident, ok := n.(*ast.Ident)
+		if !ok {
+			continue
+		}
+		d, ok := deprecations[ident.Value]
+		if !ok {
+			continue
+		}
+		err := Deprecated(ident.GetMeta(), ident.Value, d.Replacement)
+		if d.Mechanical {
+			err.Fix = &Fix{
+				Description: "replace with " + d.Replacement,
+				Replace: []*Replacement{
+					{Token: ident.GetMeta().Token, Text: d.Replacement},
+				},
+			}
+		}
+		l.Error(err.Match(DEPRECATED))
+	}
+}
@@ -0,0 +1,85 @@
+package linter
+
+import (
+	"github.com/ysugimoto/falco/ast"
+	"github.com/ysugimoto/falco/context"
+	"github.com/ysugimoto/falco/parser"
+	"github.com/ysugimoto/falco/types"
+)
+
+// Rules which check Fastly specific behavior, they are not reported for Varnish VCL
+var fastlyOnlyRules = map[Rule]struct{}{
+	SUBROUTINE_BOILERPLATE_MACRO: {},
+	NAMING_CONVENTION:            {},
+	ERROR_STATEMENT_CODE:         {},
+	LIMIT_SYNTHETIC_SIZE:         {},
+	LIMIT_HEADER_COUNT:           {},
+	LIMIT_HEADER_SIZE:            {},
+	LIMIT_WORKSPACE:              {},
+	LIMIT_ACL_ENTRIES:            {},
+	LIMIT_ACL_COUNT:              {},
+	LIMIT_BACKEND_COUNT:          {},
+	LIMIT_DIRECTOR_COUNT:         {},
+	LIMIT_SURROGATE_KEY:          {},
+	LIMIT_TABLE_SIZE:             {},
+	ESI_MISCONFIGURATION:         {},
+	CACHE_IMPLICIT_TTL:           {},
+	HASH_OUTSIDE_VCL_HASH:        {},
+	HASH_MISSING_KEY:             {},
+	TAINT_UNESCAPED_OUTPUT:       {},
+}
+
+// Legal return actions of Varnish 4 builtin subroutines
+// https://varnish-cache.org/docs/4.1/reference/states.html
+var varnish4ReturnActions = map[string][]string{
+	"vcl_recv":             {"hash", "pass", "pipe", "synth", "purge"},
+	"vcl_pipe":             {"pipe", "synth"},
+	"vcl_pass":             {"fetch", "synth", "restart"},
+	"vcl_hash":             {"lookup"},
+	"vcl_purge":            {"synth", "restart"},
+	"vcl_hit":              {"deliver", "miss", "fetch", "pass", "synth", "restart"},
+	"vcl_miss":             {"fetch", "pass", "synth", "restart"},
+	"vcl_deliver":          {"deliver", "synth", "restart"},
+	"vcl_synth":            {"deliver", "restart"},
+	"vcl_backend_fetch":    {"fetch", "abandon"},
+	"vcl_backend_response": {"deliver", "retry", "abandon"},
+	"vcl_backend_error":    {"deliver", "retry", "abandon"},
+	"vcl_init":             {"ok", "fail"},
+	"vcl_fini":             {"ok"},
+}
+
+func isVarnishDialect(ctx *context.Context) bool {
+	return ctx.Dialect() == parser.DialectVarnish4
+}
+
+// Varnish returns the action with arguments like "synth(404)", only the action name is compared
+func returnAction(exp ast.Expression) string {
+	if fn, ok := exp.(*ast.FunctionCallExpression); ok {
+		return fn.Function.Value
+	}
+	return exp.String()
+}
+
+func (l *Linter) lintVarnishReturnStatement(stmt *ast.ReturnStatement, ctx *context.Context) {
+	// Return action of custom subroutine depends on the caller so it is not checked
+	if ctx.CurrentSubroutine == nil || stmt.ReturnExpression == nil {
+		return
+	}
+	expects, ok := varnish4ReturnActions[ctx.CurrentSubroutine.Name.Value]
+	if !ok {
+		return
+	}
+	if !expectState(returnAction(*stmt.ReturnExpression), expects...) {
+		l.Error(InvalidReturnState(
+			(*stmt.ReturnExpression).GetMeta(), ctx.CurrentSubroutine.Name.Value, (*stmt.ReturnExpression).String(), expects...,
+		).Match(RESTART_STATEMENT_SCOPE))
+	}
+}
+
+// Functions are defined for Fastly so Varnish VMOD function is not checked except its arguments
+func (l *Linter) lintVarnishFunctionArguments(args []ast.Expression, ctx *context.Context) types.Type {
+	for i := range args {
+		l.lint(args[i], ctx)
+	}
+	return types.NeverType
+}
@@ -0,0 +1,135 @@
+package linter
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/ysugimoto/falco/ast"
+	"github.com/ysugimoto/falco/context"
+)
+
+// Maximum value of chash director's vnodes_per_node
+// https://developer.fastly.com/reference/vcl/declarations/director/#consistent-hashing
+const maxVnodesPerNode = 8_388_608
+
+func (l *Linter) lintDirectorHashKey(prop *ast.DirectorProperty, rule Rule) {
+	if ident, ok := prop.Value.(*ast.Ident); ok && (ident.Value == "object" || ident.Value == "client") {
+		return
+	}
+	err := &LintError{
+		Severity: ERROR,
+		Token:    prop.Value.GetMeta().Token,
+		Message:  fmt.Sprintf("Director key must be either of object or client but %s is specified", prop.Value),
+	}
+	l.Error(err.Match(rule))
+}
+
+// lintDirectorSanity checks values of director properties are consistent with its backends.
+// Types of the values are checked in lintDirectorProperty so invalid typed values are skipped here.
+// nolint: gocognit
+func (l *Linter) lintDirectorSanity(decl *ast.DirectorDeclaration, ctx *context.Context) {
+	dps, ok := DirectorPropertyTypes[decl.DirectorType.Value]
+	if !ok {
+		return
+	}
+
+	var backends, totalWeight int
+	names := make(map[string]struct{})
+	ids := make(map[string]struct{})
+	var props []*ast.DirectorProperty
+	for _, p := range decl.Properties {
+		switch t := p.(type) {
+		case *ast.DirectorProperty:
+			props = append(props, t)
+		case *ast.DirectorBackendObject:
+			backends++
+			for _, v := range t.Values {
+				switch v.Key.Value {
+				case "backend":
+					ident, ok := v.Value.(*ast.Ident)
+					if !ok {
+						continue
+					}
+					if _, ok := names[ident.Value]; ok {
+						l.Error(InvalidDirectorValue(
+							v.Value.GetMeta(), fmt.Sprintf("Backend %s is duplicated in director %s", ident.Value, decl.Name.Value),
+						).Match(dps.Rule))
+					}
+					names[ident.Value] = struct{}{}
+					if b, ok := ctx.Backends[ident.Value]; ok && b.DirectorDecl != nil {
+						l.Error(InvalidDirectorValue(
+							v.Value.GetMeta(), fmt.Sprintf("%s is a director, director could not be used as a backend of director", ident.Value),
+						).Match(dps.Rule))
+					}
+				case "weight":
+					if i, ok := v.Value.(*ast.Integer); ok {
+						if i.Value <= 0 {
+							l.Error(InvalidDirectorValue(
+								v.Value.GetMeta(), fmt.Sprintf("Backend weight must be greater than zero but %d is specified", i.Value),
+							).Match(dps.Rule))
+						} else {
+							totalWeight += int(i.Value)
+						}
+					}
+				case "id":
+					if s, ok := v.Value.(*ast.String); ok {
+						if _, ok := ids[s.Value]; ok {
+							l.Error(InvalidDirectorValue(
+								v.Value.GetMeta(), fmt.Sprintf(`Backend id "%s" is duplicated in director %s`, s.Value, decl.Name.Value),
+							).Match(dps.Rule))
+						}
+						ids[s.Value] = struct{}{}
+					}
+				}
+			}
+		}
+	}
+
+	for _, prop := range props {
+		switch prop.Key.Value {
+		case "quorum":
+			s, ok := prop.Value.(*ast.String)
+			if !ok {
+				continue
+			}
+			quorum, err := strconv.Atoi(strings.TrimSuffix(s.Value, "%"))
+			switch {
+			case err != nil || !strings.HasSuffix(s.Value, "%"):
+				l.Error(InvalidDirectorValue(
+					prop.Value.GetMeta(), fmt.Sprintf(`Quorum must be a percentage like "50%%" but "%s" is specified`, s.Value),
+				).Match(dps.Rule))
+			case quorum < 0 || quorum > 100:
+				l.Error(InvalidDirectorValue(
+					prop.Value.GetMeta(), fmt.Sprintf("Quorum must be between 0%% and 100%% but %d%% is specified", quorum),
+				).Match(dps.Rule))
+			case quorum > 0 && backends > 0 && totalWeight == 0 && decl.DirectorType.Value != "chash":
+				l.Error(InvalidDirectorValue(
+					prop.Value.GetMeta(), "Quorum could never be reached because all backends have no weight",
+				).Match(dps.Rule))
+			}
+		case "retries":
+			i, ok := prop.Value.(*ast.Integer)
+			if !ok {
+				continue
+			}
+			if i.Value < 0 || int(i.Value) > backends {
+				l.Error(InvalidDirectorValue(
+					prop.Value.GetMeta(), fmt.Sprintf("Retries must be between 0 and the number of backends %d but %d is specified", backends, i.Value),
+				).Match(dps.Rule))
+			}
+		case "vnodes_per_node":
+			if i, ok := prop.Value.(*ast.Integer); ok && (i.Value <= 0 || i.Value > maxVnodesPerNode) {
+				l.Error(InvalidDirectorValue(
+					prop.Value.GetMeta(), fmt.Sprintf("vnodes_per_node must be between 1 and %d but %d is specified", maxVnodesPerNode, i.Value),
+				).Match(dps.Rule))
+			}
+		case "seed":
+			if i, ok := prop.Value.(*ast.Integer); ok && (i.Value < 0 || i.Value > 0xFFFFFFFF) {
+				l.Error(InvalidDirectorValue(
+					prop.Value.GetMeta(), fmt.Sprintf("Seed must be 32bit unsigned integer but %d is specified", i.Value),
+				).Match(dps.Rule))
+			}
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package linter
+
+import (
+	"strings"
+
+	"github.com/ysugimoto/falco/ast"
+)
+
+// lintDuplicateBranches reports if/else-if chains which have the same condition or the same body in multiple branches,
+// which usually indicates copy-paste error
+func (l *Linter) lintDuplicateBranches(stmt *ast.IfStatement) {
+	type branch struct {
+		condition ast.Expression
+		body      *ast.BlockStatement
+		meta      *ast.Meta
+	}
+	branches := []branch{{condition: stmt.Condition, body: stmt.Consequence, meta: stmt.GetMeta()}}
+	for _, a := range stmt.Another {
+		branches = append(branches, branch{condition: a.Condition, body: a.Consequence, meta: a.GetMeta()})
+	}
+	if stmt.Alternative != nil {
+		branches = append(branches, branch{body: stmt.Alternative, meta: stmt.Alternative.GetMeta()})
+	}
+
+	conditions := make(map[string]int)
+	bodies := make(map[string]int)
+	for _, b := range branches {
+		if b.condition != nil {
+			key, negated := conditionKey(b.condition)
+			if negated {
+				key = "!" + key
+			}
+			if line, ok := conditions[key]; ok {
+				l.Error(DuplicateCondition(b.condition.GetMeta(), line).Match(DUPLICATE_BRANCH))
+			} else {
+				conditions[key] = b.meta.Token.Line
+			}
+		}
+
+		// Empty bodies are often intentional to skip other branches,
+		// and regex captured values differ by the condition even if the bodies are the same
+		if len(b.body.Statements) == 0 || usesRegexGroup(b.body) {
+			continue
+		}
+		key := blockKey(b.body)
+		if line, ok := bodies[key]; ok {
+			l.Error(DuplicateBranchBody(b.meta, line).Match(DUPLICATE_BRANCH))
+		} else {
+			bodies[key] = b.meta.Token.Line
+		}
+	}
+}
+
+// blockKey returns the statements string of the block to compare with other blocks
+func blockKey(block *ast.BlockStatement) string {
+	var buf strings.Builder
+	for _, stmt := range block.Statements {
+		buf.WriteString(stmt.String())
+	}
+	return buf.String()
+}
+
+// usesRegexGroup returns true when the block reads regex captured variables
+func usesRegexGroup(block *ast.BlockStatement) bool {
+	for _, n := range ast.NewTree(block).Nodes() {
+		if ident, ok := n.(*ast.Ident); ok && strings.HasPrefix(ident.Value, "re.group.") {
+			return true
+		}
+	}
+	return false
+}
@@ -1,10 +1,12 @@
 package linter
 
 import (
+	"encoding/json"
 	"fmt"
 	"strings"
 
 	"github.com/ysugimoto/falco/ast"
+	"github.com/ysugimoto/falco/diagnostics"
 	"github.com/ysugimoto/falco/lexer"
 	"github.com/ysugimoto/falco/token"
 	"github.com/ysugimoto/falco/types"
@@ -25,19 +27,48 @@ type LintError struct {
 	Message   string
 	Reference string
 	Rule      Rule
+	// Category and documentation URL of the rule
+	Category      string
+	Documentation string
+	// Fix is set when the error could be fixed automatically
+	Fix *Fix `json:"-"`
 }
 
 func (l *LintError) Match(r Rule) *LintError {
 	l.Rule = r
 	l.Reference = r.Reference()
+	l.Category = r.Category()
+	l.Documentation = r.Documentation()
 	return l
 }
 
+// Fixable returns true if the error could be fixed automatically by "falco lint -fix"
+func (e *LintError) Fixable() bool {
+	return e.Fix != nil
+}
+
+// MarshalJSON encodes the error with fixability flag
+func (e *LintError) MarshalJSON() ([]byte, error) {
+	type alias LintError
+	return json.Marshal(struct {
+		*alias
+		Fixable bool
+	}{
+		alias:   (*alias)(e),
+		Fixable: e.Fixable(),
+	})
+}
+
 func (e *LintError) Ref(url string) *LintError {
 	e.Reference = url
 	return e
 }
 
+func (e *LintError) WithFix(f *Fix) *LintError {
+	e.Fix = f
+	return e
+}
+
 func (e *LintError) Error() string {
 	var rule, ref, file string
 
@@ -58,6 +89,35 @@ func (e *LintError) Error() string {
 	return msg
 }
 
+// Diagnostic converts the error to render code frame
+func (e *LintError) Diagnostic() *diagnostics.Diagnostic {
+	d := &diagnostics.Diagnostic{
+		Message: e.Message,
+		Token:   e.Token,
+	}
+	switch e.Severity {
+	case WARNING:
+		d.Severity = diagnostics.WARNING
+	case INFO:
+		d.Severity = diagnostics.INFO
+	default:
+		d.Severity = diagnostics.ERROR
+	}
+	if e.Rule != "" {
+		d.Message += fmt.Sprintf(" (%s)", e.Rule)
+	}
+	if e.Reference != "" {
+		d.Notes = append(d.Notes, "see: "+e.Reference)
+	}
+	if e.Documentation != "" {
+		d.Notes = append(d.Notes, "rule: "+e.Documentation)
+	}
+	if e.Fixable() {
+		d.Notes = append(d.Notes, "fixable: run with -fix option to fix automatically")
+	}
+	return d
+}
+
 func InvalidName(m *ast.Meta, name, ident string) *LintError {
 	return &LintError{
 		Severity: ERROR,
@@ -92,18 +152,25 @@ func UndefinedVariable(m *ast.Meta, name string) *LintError {
 	}
 }
 
-func UndefinedAcl(m *ast.Meta, name string) *LintError {
-	return &LintError{
-		Severity: ERROR,
-		Token:    m.Token,
-		Message:  fmt.Sprintf(`ACL "%s" is not defined`, name),
-	}
+// Display names of declaration kinds in the message
+var referenceKindNames = map[string]string{
+	"":                   "Identifier",
+	referenceBackend:     "Backend",
+	referenceAcl:         "ACL",
+	referenceTable:       "Table",
+	referencePenaltybox:  "Penaltybox",
+	referenceRatecounter: "Ratecounter",
 }
-func UndefinedBackend(m *ast.Meta, name string) *LintError {
+
+func UndefinedReference(m *ast.Meta, kind, name string, candidates []string) *LintError {
+	message := fmt.Sprintf(`%s "%s" is not defined`, referenceKindNames[kind], name)
+	if len(candidates) > 0 {
+		message += fmt.Sprintf(", did you mean %s?", strings.Join(candidates, " or "))
+	}
 	return &LintError{
 		Severity: ERROR,
 		Token:    m.Token,
-		Message:  fmt.Sprintf(`Backend "%s" is not defined`, name),
+		Message:  message,
 	}
 }
 
@@ -203,6 +270,22 @@ func InvalidTypeComparison(m *ast.Meta, left, right types.Type) *LintError {
 	}
 }
 
+func InvalidTypeConcatenation(m *ast.Meta, actual types.Type) *LintError {
+	return &LintError{
+		Severity: ERROR,
+		Token:    m.Token,
+		Message:  fmt.Sprintf("%s type cannot use in string concatenation", actual.String()),
+	}
+}
+
+func InvalidRegularExpressionOperand(m *ast.Meta, actual types.Type) *LintError {
+	return &LintError{
+		Severity: ERROR,
+		Token:    m.Token,
+		Message:  fmt.Sprintf("Regular expression must be a STRING literal, got %s expression", actual.String()),
+	}
+}
+
 func ImplicitTypeConversion(m *ast.Meta, from, to types.Type) *LintError {
 	return &LintError{
 		Severity: INFO,
@@ -219,6 +302,14 @@ func UndefinedBackendProperty(m *ast.Meta, name string) *LintError {
 	}
 }
 
+func InvalidDirectorValue(m *ast.Meta, message string) *LintError {
+	return &LintError{
+		Severity: ERROR,
+		Token:    m.Token,
+		Message:  message,
+	}
+}
+
 func UndefinedDirectorProperty(m *ast.Meta, name, dt string) *LintError {
 	return &LintError{
 		Severity: ERROR,
@@ -291,6 +382,414 @@ func UnusedDeclaration(m *ast.Meta, name, declType string) *LintError {
 	}
 }
 
+func UnreadVariable(m *ast.Meta, name string) *LintError {
+	return &LintError{
+		Severity: WARNING,
+		Token:    m.Token,
+		Message:  fmt.Sprintf(`Variable "%s" is assigned but never read`, name),
+	}
+}
+
+func UnusedAssignment(m *ast.Meta, name string) *LintError {
+	return &LintError{
+		Severity: WARNING,
+		Token:    m.Token,
+		Message:  fmt.Sprintf(`Value assigned to "%s" is never read`, name),
+	}
+}
+
+func NotSetRead(m *ast.Meta, name string) *LintError {
+	return &LintError{
+		Severity: WARNING,
+		Token:    m.Token,
+		Message:  fmt.Sprintf(`"%s" is read but it is not set on any path`, name),
+	}
+}
+
+func MaybeNotSetRead(m *ast.Meta, name string) *LintError {
+	return &LintError{
+		Severity: INFO,
+		Token:    m.Token,
+		Message:  fmt.Sprintf(`"%s" is read but it may not be set on some paths`, name),
+	}
+}
+
+func InvalidRegex(m *ast.Meta, err error) *LintError {
+	return &LintError{
+		Severity: ERROR,
+		Token:    m.Token,
+		Message:  "regex string is invalid, " + err.Error(),
+	}
+}
+
+func IncompatibleRegex(m *ast.Meta, message string) *LintError {
+	return &LintError{
+		Severity: WARNING,
+		Token:    m.Token,
+		Message:  "regex behaves differently in Fastly's PCRE: " + message,
+	}
+}
+
+func CatastrophicBacktracking(m *ast.Meta, risk string) *LintError {
+	return &LintError{
+		Severity: WARNING,
+		Token:    m.Token,
+		Message:  "regex may cause catastrophic backtracking on unmatched input due to " + risk,
+	}
+}
+
+func AclEntryOverlap(m *ast.Meta, message string) *LintError {
+	return &LintError{
+		Severity: WARNING,
+		Token:    m.Token,
+		Message:  message,
+	}
+}
+
+func LimitExceeded(m *ast.Meta, subject, unit string, max int) *LintError {
+	return &LintError{
+		Severity: WARNING,
+		Token:    m.Token,
+		Message:  fmt.Sprintf("%s, exceeds Fastly's limit of %d %s", subject, max, unit),
+	}
+}
+
+func RestartLoop(m *ast.Meta, reason string) *LintError {
+	return &LintError{
+		Severity: WARNING,
+		Token:    m.Token,
+		Message:  "Restart loop, the request is restarted until it exceeds max restarts: " + reason,
+	}
+}
+
+func GotoLoop(m *ast.Meta, destination string, infinite bool) *LintError {
+	message := fmt.Sprintf(`goto jumps backward to "%s", it could loop`, destination)
+	if infinite {
+		message = fmt.Sprintf(`goto jumps backward to "%s" and no statement leaves the loop, it loops infinitely`, destination)
+	}
+	return &LintError{
+		Severity: WARNING,
+		Token:    m.Token,
+		Message:  message,
+	}
+}
+
+func GotoIntoBlock(m *ast.Meta, destination string) *LintError {
+	return &LintError{
+		Severity: WARNING,
+		Token:    m.Token,
+		Message:  fmt.Sprintf(`goto jumps into the middle of the nested block to "%s", the condition of the block is bypassed`, destination),
+	}
+}
+
+func GotoSkipsDeclaration(m *ast.Meta, destination, variable string) *LintError {
+	return &LintError{
+		Severity: WARNING,
+		Token:    m.Token,
+		Message:  fmt.Sprintf(`goto to "%s" skips the declaration of "%s" which is used after the destination`, destination, variable),
+	}
+}
+
+func EsiWithoutGuard(m *ast.Meta) *LintError {
+	return &LintError{
+		Severity: WARNING,
+		Token:    m.Token,
+		Message:  "ESI is enabled without condition, ESI processing is applied to all responses including non-HTML contents. Consider checking beresp.http.Content-Type",
+	}
+}
+
+func EsiWithStreaming(m *ast.Meta) *LintError {
+	return &LintError{
+		Severity: WARNING,
+		Token:    m.Token,
+		Message:  "ESI is not processed on the streaming miss response, beresp.do_stream should not be enabled with ESI",
+	}
+}
+
+func EsiWithGzip(m *ast.Meta) *LintError {
+	return &LintError{
+		Severity: WARNING,
+		Token:    m.Token,
+		Message:  "ESI could not be processed on the compressed response, beresp.gzip should not be enabled with ESI",
+	}
+}
+
+func BannedIdentifier(m *ast.Meta, kind, name, message string) *LintError {
+	msg := fmt.Sprintf(`%s "%s" is banned`, kind, name)
+	if message != "" {
+		msg += ": " + message
+	}
+	return &LintError{
+		Severity: ERROR,
+		Token:    m.Token,
+		Message:  msg,
+	}
+}
+
+func SyntheticWithoutDeliver(m *ast.Meta) *LintError {
+	return &LintError{
+		Severity: WARNING,
+		Token:    m.Token,
+		Message:  "synthetic response is not followed by return(deliver), the response could be overwritten",
+	}
+}
+
+func SyntheticDiscarded(m *ast.Meta, by string) *LintError {
+	return &LintError{
+		Severity: WARNING,
+		Token:    m.Token,
+		Message:  fmt.Sprintf("synthetic response is discarded by %s, return(deliver) is expected", by),
+	}
+}
+
+func SyntheticInternalStatus(m *ast.Meta, code int64) *LintError {
+	return &LintError{
+		Severity: WARNING,
+		Token:    m.Token,
+		Message:  fmt.Sprintf("synthetic response is served with internal status code %d, set valid obj.status for the client", code),
+	}
+}
+
+func ImplicitTTL(m *ast.Meta) *LintError {
+	return &LintError{
+		Severity: WARNING,
+		Token:    m.Token,
+		Message:  "Response is cached without deciding TTL on this path, TTL is derived from origin headers or default TTL. Set beresp.ttl, handle Cache-Control header or return(pass)",
+	}
+}
+
+func HashOutsideVclHash(m *ast.Meta, scope string) *LintError {
+	return &LintError{
+		Severity: WARNING,
+		Token:    m.Token,
+		Message:  fmt.Sprintf("req.hash is modified in scope %s, the cache key is computed in vcl_hash and the modification does not affect cache lookup", scope),
+	}
+}
+
+func HashMissingKey(m *ast.Meta, missing []string) *LintError {
+	return &LintError{
+		Severity: WARNING,
+		Token:    m.Token,
+		Message:  fmt.Sprintf("vcl_hash does not add %s to req.hash, different resources could share the same cache object", strings.Join(missing, " and ")),
+	}
+}
+
+func ShadowedDeclaration(m *ast.Meta, name string, line int) *LintError {
+	return &LintError{
+		Severity: ERROR,
+		Token:    m.Token,
+		Message: fmt.Sprintf(
+			`Variable "%s" is already declared at line %d, local variables are scoped to the whole subroutine so the declaration in nested block does not shadow it`,
+			name, line,
+		),
+	}
+}
+
+func BlockScopedDeclaration(m *ast.Meta, name string, line int) *LintError {
+	return &LintError{
+		Severity: WARNING,
+		Token:    m.Token,
+		Message: fmt.Sprintf(
+			`Variable "%s" is declared in nested block but used outside of the block at line %d, it is not set when the block is not executed. Declare it at the top of the subroutine`,
+			name, line,
+		),
+	}
+}
+
+func RepeatedSelfAppend(m *ast.Meta, name string, count int) *LintError {
+	return &LintError{
+		Severity: WARNING,
+		Token:    m.Token,
+		Message: fmt.Sprintf(
+			"%s is appended to itself %d times, each concatenation allocates the whole value in workspace. Consider add statements and std.collect()",
+			name, count,
+		),
+	}
+}
+
+func SelfAppendOnRestart(m *ast.Meta, name string) *LintError {
+	return &LintError{
+		Severity: WARNING,
+		Token:    m.Token,
+		Message: fmt.Sprintf(
+			"%s is appended again on every restart because request headers persist across restarts. Check req.restarts or consider add statements and std.collect()",
+			name,
+		),
+	}
+}
+
+func LongConcatenation(m *ast.Meta, operands, max int) *LintError {
+	return &LintError{
+		Severity: WARNING,
+		Token:    m.Token,
+		Message: fmt.Sprintf(
+			"String concatenation has %d operands which exceeds %d, intermediate strings are allocated in workspace. Consider splitting values by add statements and std.collect()",
+			operands, max,
+		),
+	}
+}
+
+func TaintedOutput(m *ast.Meta, source, sink string) *LintError {
+	return &LintError{
+		Severity: WARNING,
+		Token:    m.Token,
+		Message: fmt.Sprintf(
+			"User controlled %s flows into %s without escaping, it could inject headers or forge logs. Consider escaping by urlencode() or validating the value",
+			source, sink,
+		),
+	}
+}
+
+func MissingReturn(m *ast.Meta, name string) *LintError {
+	return &LintError{
+		Severity: ERROR,
+		Token:    m.Token,
+		Message:  fmt.Sprintf("Function %s does not return a value on all control-flow paths", name),
+	}
+}
+
+func HeaderTypo(m *ast.Meta, name, suggestion string) *LintError {
+	return &LintError{
+		Severity: WARNING,
+		Token:    m.Token,
+		Message:  fmt.Sprintf(`Header "%s" may be a typo of "%s"`, name, suggestion),
+	}
+}
+
+func Deprecated(m *ast.Meta, name, replacement string) *LintError {
+	return &LintError{
+		Severity: WARNING,
+		Token:    m.Token,
+		Message:  fmt.Sprintf(`"%s" is deprecated, use "%s" instead`, name, replacement),
+	}
+}
+
+func ConstantCondition(m *ast.Meta, result bool) *LintError {
+	return &LintError{
+		Severity: WARNING,
+		Token:    m.Token,
+		Message:  fmt.Sprintf("Condition is always %t", result),
+	}
+}
+
+func UnreachableCode(m *ast.Meta) *LintError {
+	return &LintError{
+		Severity: WARNING,
+		Token:    m.Token,
+		Message:  "Unreachable code, the statement is never executed",
+	}
+}
+
+func UnreachableBranch(m *ast.Meta) *LintError {
+	return &LintError{
+		Severity: WARNING,
+		Token:    m.Token,
+		Message:  "Unreachable branch, earlier conditions cover this branch",
+	}
+}
+
+func DuplicateCondition(m *ast.Meta, line int) *LintError {
+	return &LintError{
+		Severity: WARNING,
+		Token:    m.Token,
+		Message:  fmt.Sprintf("Condition is the same as the branch at line %d", line),
+	}
+}
+
+func DuplicateBranchBody(m *ast.Meta, line int) *LintError {
+	return &LintError{
+		Severity: WARNING,
+		Token:    m.Token,
+		Message:  fmt.Sprintf("Branch body is identical to the branch at line %d", line),
+	}
+}
+
+func TooComplex(m *ast.Meta, name, metric string, value, max int) *LintError {
+	return &LintError{
+		Severity: INFO,
+		Token:    m.Token,
+		Message:  fmt.Sprintf("Subroutine %s has %s %d, exceeds %d. Consider splitting it into smaller subroutines", name, metric, value, max),
+	}
+}
+
+func NamingConvention(m *ast.Meta, kind, name, pattern string) *LintError {
+	return &LintError{
+		Severity: WARNING,
+		Token:    m.Token,
+		Message:  fmt.Sprintf(`%s name "%s" does not match naming convention %s`, kind, name, pattern),
+	}
+}
+
+func ReservedName(m *ast.Meta, kind, name, prefix string) *LintError {
+	return &LintError{
+		Severity: WARNING,
+		Token:    m.Token,
+		Message:  fmt.Sprintf(`%s name "%s" uses prefix "%s" which is reserved for Fastly generated names`, kind, name, prefix),
+	}
+}
+
+func InvalidNamingPattern(m *ast.Meta, kind, pattern string, err error) *LintError {
+	return &LintError{
+		Severity: ERROR,
+		Token:    m.Token,
+		Message:  fmt.Sprintf(`Invalid naming convention pattern "%s" for %s: %s`, pattern, kind, err),
+	}
+}
+
+func CaseSensitiveComparison(m *ast.Meta, header string) *LintError {
+	return &LintError{
+		Severity: INFO,
+		Token:    m.Token,
+		Message: fmt.Sprintf(
+			`Value of %s header is case-insensitive but compared case-sensitively, consider std.tolower() or ~ "(?i)^...$"`,
+			header,
+		),
+	}
+}
+
+func CaseInsensitiveMatch(m *ast.Meta, name string) *LintError {
+	return &LintError{
+		Severity: INFO,
+		Token:    m.Token,
+		Message:  fmt.Sprintf("%s is case-sensitive but matched case-insensitively by (?i) flag", name),
+	}
+}
+
+func SuspiciousDuration(m *ast.Meta, message string) *LintError {
+	return &LintError{
+		Severity: WARNING,
+		Token:    m.Token,
+		Message:  message,
+	}
+}
+
+func AssignmentInCondition(m *ast.Meta) *LintError {
+	return &LintError{
+		Severity: ERROR,
+		Token:    m.Token,
+		Message:  `Assignment operator "=" could not be used in condition, use "==" to compare`,
+	}
+}
+
+func NegationPrecedence(m *ast.Meta, operator, negated string) *LintError {
+	return &LintError{
+		Severity: WARNING,
+		Token:    m.Token,
+		Message: fmt.Sprintf(
+			`Negation "!" applies only to the left operand of "%s", use "%s" operator to negate the comparison`,
+			operator, negated,
+		),
+	}
+}
+
+func UnusedSuppression(c *ast.Comment) *LintError {
+	return &LintError{
+		Severity: WARNING,
+		Token:    c.Token,
+		Message:  fmt.Sprintf(`Ignore comment "%s" does not suppress any errors`, strings.TrimSpace(c.Value)),
+	}
+}
+
 func UnusedExternalDeclaration(name, declType string) *LintError {
 	return &LintError{
 		Severity: WARNING,
@@ -353,3 +852,19 @@ type FatalError struct {
 	Lexer *lexer.Lexer
 	Error error
 }
+
+func InvalidEscape(m *ast.Meta, err error) *LintError {
+	return &LintError{
+		Severity: WARNING,
+		Token:    m.Token,
+		Message:  err.Error(),
+	}
+}
+
+func UnknownProperty(m *ast.Meta, name string) *LintError {
+	return &LintError{
+		Severity: WARNING,
+		Token:    m.Token,
+		Message:  fmt.Sprintf(`Unknown property "%s" is not supported by falco, value is not validated`, name),
+	}
+}
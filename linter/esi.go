@@ -0,0 +1,88 @@
+package linter
+
+import (
+	"strings"
+
+	"github.com/ysugimoto/falco/ast"
+)
+
+// lintEsi reports ESI processing configurations in the subroutine which Fastly could not process as expected:
+// - enabling ESI without condition, ESI processing is applied to all responses including binary contents
+// - enabling ESI with streaming miss, ESI is not processed on streamed responses
+// - enabling ESI with gzip compression, Fastly could not process ESI on compressed responses
+func (l *Linter) lintEsi(decl *ast.SubroutineDeclaration) {
+	defer l.measure(ESI_MISCONFIGURATION)()
+	tree := ast.NewTree(decl.Block)
+
+	var enables, streams, gzips []ast.Node
+	for _, n := range tree.Nodes() {
+		switch t := n.(type) {
+		case *ast.EsiStatement:
+			enables = append(enables, t)
+		case *ast.SetStatement:
+			if !isTrueLiteral(t.Value) {
+				continue
+			}
+			switch strings.ToLower(t.Ident.Value) {
+			case "beresp.do_esi":
+				enables = append(enables, t)
+			case "beresp.do_stream":
+				streams = append(streams, t)
+			case "beresp.gzip":
+				gzips = append(gzips, t)
+			}
+		}
+	}
+
+	for _, n := range enables {
+		if !hasIfStatementAncestor(tree, n) {
+			l.Error(EsiWithoutGuard(n.GetMeta()).Match(ESI_MISCONFIGURATION))
+		}
+	}
+	for _, n := range streams {
+		if combined(tree, n, enables) {
+			l.Error(EsiWithStreaming(n.GetMeta()).Match(ESI_MISCONFIGURATION))
+		}
+	}
+	for _, n := range gzips {
+		if combined(tree, n, enables) {
+			l.Error(EsiWithGzip(n.GetMeta()).Match(ESI_MISCONFIGURATION))
+		}
+	}
+}
+
+// combined returns true if the node could be executed together with any of others,
+// nodes in the different branches of the same if statement are never executed together.
+func combined(tree *ast.Tree, node ast.Node, others []ast.Node) bool {
+	ancestors := make(map[ast.Node]struct{})
+	for _, p := range tree.Ancestors(node) {
+		ancestors[p] = struct{}{}
+	}
+	for _, o := range others {
+		for _, p := range tree.Ancestors(o) {
+			if _, ok := ancestors[p]; !ok {
+				continue
+			}
+			// The nearest common ancestor is the if statement when they are in the different branches
+			if _, ok := p.(*ast.IfStatement); !ok {
+				return true
+			}
+			break
+		}
+	}
+	return false
+}
+
+func isTrueLiteral(exp ast.Expression) bool {
+	b, ok := exp.(*ast.Boolean)
+	return ok && b.Value
+}
+
+func hasIfStatementAncestor(tree *ast.Tree, node ast.Node) bool {
+	for _, p := range tree.Ancestors(node) {
+		if _, ok := p.(*ast.IfStatement); ok {
+			return true
+		}
+	}
+	return false
+}
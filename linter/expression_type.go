@@ -0,0 +1,179 @@
+package linter
+
+import (
+	"github.com/ysugimoto/falco/ast"
+	"github.com/ysugimoto/falco/types"
+)
+
+// Type checking of operators in expressions.
+// Fastly accepts some implicit type conversions for each operator but rejects others on compilation,
+// the accepted operand types are summarized as following:
+//
+// Operator        | Left operand               | Right operand
+// ================|============================|==================================================
+// ==, !=          | STRING                     | STRING
+//                 | INTEGER                    | INTEGER
+//                 | FLOAT                      | FLOAT
+//                 | BOOL                       | BOOL
+//                 | TIME                       | TIME
+//                 | RTIME                      | RTIME
+//                 | IP                         | IP, STRING literal (parsed as IP address)
+//                 | BACKEND                    | BACKEND (req.backend is treated as BACKEND)
+//                 | ID                         | ID
+// >, >=, <, <=    | INTEGER                    | INTEGER, RTIME
+//                 | FLOAT, RTIME               | INTEGER, FLOAT, RTIME
+//                 | TIME                       | TIME
+// ~, !~           | STRING                     | STRING literal (regular expression)
+//                 | STRING, IP                 | ACL (STRING is parsed as IP address)
+// +               | STRING, INTEGER, FLOAT, BOOL, TIME, RTIME, IP (stringified with implicit conversion)
+// &&, ||, !       | BOOL, STRING (evaluated as truthy or falsy)
+// - (prefix)      | INTEGER, FLOAT, RTIME
+//
+// See: https://developer.fastly.com/reference/vcl/operators/
+// See: https://developer.fastly.com/reference/vcl/types/
+
+// Types which could be compared with equality operators
+var equalityOperandTypes = []types.Type{
+	types.StringType,
+	types.IntegerType,
+	types.FloatType,
+	types.BoolType,
+	types.TimeType,
+	types.RTimeType,
+	types.IPType,
+	types.BackendType,
+	types.IDType,
+}
+
+// Types which could be compared with greater/less than operators
+var relationalOperandTypes = []types.Type{
+	types.IntegerType,
+	types.FloatType,
+	types.RTimeType,
+	types.TimeType,
+}
+
+// Types which could be converted to STRING implicitly on string concatenation
+var concatenationOperandTypes = []types.Type{
+	types.IntegerType,
+	types.FloatType,
+	types.BoolType,
+	types.TimeType,
+	types.RTimeType,
+	types.IPType,
+	types.ReqBackendType,
+}
+
+// Types which could be evaluated as truthy or falsy
+var logicalOperandTypes = []types.Type{
+	types.BoolType,
+	types.StringType,
+}
+
+// Lint equality operator of "==" and "!=".
+// Assignment operator in condition is reported as mistake of "==", then type check as equal operator.
+func (l *Linter) lintEqualityOperator(exp *ast.InfixExpression, left, right types.Type) {
+	// Cast req.backend to standard backend type for comparisons
+	// Fiddle demonstrating these comparisons are valid:
+	// https://fiddle.fastly.dev/fiddle/06865e2d
+	if left == types.ReqBackendType {
+		left = types.BackendType
+	}
+	if right == types.ReqBackendType {
+		right = types.BackendType
+	}
+
+	if !expectType(left, equalityOperandTypes...) {
+		l.Error(InvalidTypeExpression(exp.GetMeta(), left, equalityOperandTypes...).Match(OPERATOR_CONDITIONAL))
+		return
+	}
+	if left == right {
+		return
+	}
+	// IP could be compared with string literal which is parsed as IP address
+	if _, ok := exp.Right.(*ast.String); ok && left == types.IPType && right == types.StringType {
+		return
+	}
+	l.Error(InvalidTypeComparison(exp.GetMeta(), left, right).Match(OPERATOR_CONDITIONAL))
+}
+
+// Lint greater/less than operator of ">", ">=", "<" and "<="
+func (l *Linter) lintRelationalOperator(exp *ast.InfixExpression, left, right types.Type) {
+	switch left {
+	case types.IntegerType:
+		// When left type is INTEGER, right type must be INTEGER or RTIME
+		if !expectType(right, types.IntegerType, types.RTimeType) {
+			l.Error(InvalidTypeExpression(exp.GetMeta(), right, types.IntegerType, types.RTimeType).Match(OPERATOR_CONDITIONAL))
+		}
+	case types.FloatType, types.RTimeType:
+		// When left type is FLOAT or RTIME, right type must be INTEGER or FLOAT or RTIME
+		if !expectType(right, types.IntegerType, types.FloatType, types.RTimeType) {
+			l.Error(InvalidTypeExpression(exp.GetMeta(), right, types.IntegerType, types.FloatType, types.RTimeType).Match(OPERATOR_CONDITIONAL))
+		}
+	case types.TimeType:
+		// TIME could be compared only with TIME
+		if right != types.TimeType {
+			l.Error(InvalidTypeComparison(exp.GetMeta(), left, right).Match(OPERATOR_CONDITIONAL))
+		}
+	default:
+		l.Error(InvalidTypeExpression(exp.GetMeta(), left, relationalOperandTypes...).Match(OPERATOR_CONDITIONAL))
+	}
+}
+
+// Lint regular expression and ACL match operator of "~" and "!~"
+func (l *Linter) lintMatchOperator(exp *ast.InfixExpression, left, right types.Type) {
+	switch left {
+	case types.StringType:
+		// STRING could be matched with ACL entries as IP address
+		if right == types.AclType {
+			return
+		}
+		// Regular expression is compiled on compilation, then it must be a string literal
+		v, ok := exp.Right.(*ast.String)
+		if !ok {
+			l.Error(InvalidRegularExpressionOperand(exp.Right.GetMeta(), right).Match(OPERATOR_CONDITIONAL))
+			return
+		}
+		// And regex must be valid
+		l.lintRegex(v)
+	case types.IPType:
+		// IP is matched with ACL entries
+		if right != types.AclType {
+			l.Error(InvalidTypeExpression(exp.Right.GetMeta(), right, types.AclType).Match(OPERATOR_CONDITIONAL))
+		}
+	default:
+		l.Error(InvalidTypeExpression(exp.GetMeta(), left, types.StringType, types.IPType).Match(OPERATOR_CONDITIONAL))
+	}
+}
+
+// Lint string concatenation operator of "+".
+// VCL accepts other types with implicit type conversion as following:
+// STRING  -> raw string
+// INTEGER -> stringify
+// FLOAT   -> stringify
+// IP      -> stringify
+// TIME    -> stringify (GMT string)
+// RTIME   -> stringify (GMT string)
+// BOOL    -> 0 (false) or 1 (true)
+func (l *Linter) lintConcatenationOperator(exp *ast.InfixExpression, left, right types.Type) {
+	for _, t := range []types.Type{left, right} {
+		switch {
+		case t == types.StringType:
+			continue
+		case expectType(t, concatenationOperandTypes...):
+			l.Error(ImplicitTypeConversion(exp.GetMeta(), t, types.StringType))
+		default:
+			l.Error(InvalidTypeConcatenation(exp.GetMeta(), t).Match(OPERATOR_CONDITIONAL))
+		}
+	}
+}
+
+// Lint logical operator of "&&" and "||" which compares left and right with truthy or falsy
+func (l *Linter) lintLogicalExpression(exp *ast.InfixExpression, left, right types.Type) {
+	if !expectType(left, logicalOperandTypes...) {
+		l.Error(InvalidTypeExpression(exp.Left.GetMeta(), left, logicalOperandTypes...).Match(OPERATOR_CONDITIONAL))
+	}
+	if !expectType(right, logicalOperandTypes...) {
+		l.Error(InvalidTypeExpression(exp.Right.GetMeta(), right, logicalOperandTypes...).Match(OPERATOR_CONDITIONAL))
+	}
+}
@@ -0,0 +1,288 @@
+package linter
+
+import (
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+	"github.com/ysugimoto/falco/ast"
+	"github.com/ysugimoto/falco/token"
+)
+
+// Default indentation of inserted lines when it could not be determined from the source
+const defaultFixIndent = "  "
+
+// Fix is an automatic fix for the lint error, supports removing statements, replacing tokens and inserting lines.
+// All edits of the fix are applied together, or none of them are applied if they conflict with other fixes.
+type Fix struct {
+	Description string
+	Remove      []ast.Statement
+	Replace     []*Replacement
+	Insert      []*Insertion
+}
+
+// Replacement replaces the literal of the token with the text
+type Replacement struct {
+	Token token.Token
+	Text  string
+}
+
+// Insertion inserts the text as a new line after the line of the token.
+// The line is indented as same as the next line, or one level deeper than the line of the token
+// if the next line is empty or closes the block.
+type Insertion struct {
+	Token token.Token
+	Text  string
+}
+
+// removeDeclaration returns the fix which removes unused declaration entirely
+func removeDeclaration(decl ast.Statement) *Fix {
+	return &Fix{
+		Description: "remove the unused declaration",
+		Remove:      []ast.Statement{decl},
+	}
+}
+
+type editRange struct {
+	start, end int
+	text       string
+}
+
+// ApplyFixes applies fixes to the source and returns fixed source with the number of applied fixes.
+// Statements are removed from its start token to terminating semicolon or closing brace,
+// and the line is removed entirely if nothing remains.
+// Replacements are applied only when the source still has the token literal at the position.
+// Fixes are applied in order, and the fix which overlaps with already applied edits is skipped
+// so that it could be applied on the next run against the fixed source.
+func ApplyFixes(src string, fixes []*Fix) (string, int, error) {
+	lines := lineOffsets(src)
+
+	var picked []editRange
+	var applied int
+	for _, f := range fixes {
+		ranges, err := fixRanges(src, lines, f)
+		if err != nil {
+			return "", 0, errors.WithStack(err)
+		}
+		if conflicts(picked, ranges) {
+			continue
+		}
+		for _, r := range ranges {
+			// Same edit could be made by multiple fixes, e.g. same statement is removed
+			if !contains(picked, r) {
+				picked = append(picked, r)
+			}
+		}
+		applied++
+	}
+
+	// Apply from the end of source not to shift offsets.
+	// Insertions at the same offset keep its order.
+	sort.SliceStable(picked, func(i, j int) bool {
+		return picked[i].start > picked[j].start
+	})
+	for _, r := range picked {
+		src = src[:r.start] + r.text + src[r.end:]
+	}
+	return src, applied, nil
+}
+
+// fixRanges converts edits of the fix to byte ranges of the source
+func fixRanges(src string, lines []int, f *Fix) ([]editRange, error) {
+	var ranges []editRange
+	for _, r := range f.Replace {
+		tok := r.Token
+		if tok.Line < 1 || tok.Line > len(lines) {
+			return nil, errors.Errorf("Token position line %d is out of source", tok.Line)
+		}
+		start := charOffset(src, lines[tok.Line-1], tok.Position)
+		if !strings.HasPrefix(src[start:], tok.Literal) {
+			return nil, errors.Errorf("Token %s is not found at line %d", tok.Literal, tok.Line)
+		}
+		ranges = append(ranges, editRange{start: start, end: start + len(tok.Literal), text: r.Text})
+	}
+	for _, stmt := range f.Remove {
+		tok := stmt.GetMeta().Token
+		if tok.Line < 1 || tok.Line > len(lines) {
+			return nil, errors.Errorf("Statement position line %d is out of source", tok.Line)
+		}
+		start := charOffset(src, lines[tok.Line-1], tok.Position)
+		end, ok := statementEnd(src, start)
+		if !ok {
+			return nil, errors.Errorf("Could not find end of statement at line %d", tok.Line)
+		}
+		ranges = append(ranges, expandToLine(src, start, end))
+	}
+	for _, in := range f.Insert {
+		tok := in.Token
+		if tok.Line < 1 || tok.Line > len(lines) {
+			return nil, errors.Errorf("Token position line %d is out of source", tok.Line)
+		}
+		start := charOffset(src, lines[tok.Line-1], tok.Position)
+		if !strings.HasPrefix(src[start:], tok.Literal) {
+			return nil, errors.Errorf("Token %s is not found at line %d", tok.Literal, tok.Line)
+		}
+		ranges = append(ranges, insertLine(src, start+len(tok.Literal)-1, in.Text))
+	}
+	return ranges, nil
+}
+
+// conflicts returns true if any of ranges overlaps with picked ranges.
+// Identical ranges do not conflict because they make the same edit.
+func conflicts(picked, ranges []editRange) bool {
+	for _, r := range ranges {
+		for _, p := range picked {
+			if r == p {
+				continue
+			}
+			// Insertions at the same offset are ambiguous in order
+			if r.start == p.start && (r.start == r.end || p.start == p.end) {
+				return true
+			}
+			if r.start < p.end && p.start < r.end {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func contains(ranges []editRange, r editRange) bool {
+	for _, v := range ranges {
+		if v == r {
+			return true
+		}
+	}
+	return false
+}
+
+// insertLine makes the range which inserts the text as a new line after the token
+func insertLine(src string, start int, text string) editRange {
+	lineStart := strings.LastIndexByte(src[:start], '\n') + 1
+	line := src[lineStart:]
+	lineIndent := line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+
+	lineEnd := strings.IndexByte(src[start:], '\n')
+	if lineEnd < 0 {
+		// Token is on the last line, insert at the end of source
+		return editRange{start: len(src), end: len(src), text: "\n" + lineIndent + defaultFixIndent + text}
+	}
+	lineEnd += start
+
+	// Something follows the token on the same line, break the line after the token
+	if rest := strings.TrimSpace(src[start:lineEnd]); strings.ContainsAny(rest[1:], "{};") {
+		offset := start + 1
+		indent := lineIndent + defaultFixIndent
+		// Spaces after the token are replaced with the indentation
+		spaces := len(src[offset:lineEnd]) - len(strings.TrimLeft(src[offset:lineEnd], " \t"))
+		return editRange{start: offset, end: offset + spaces, text: "\n" + indent + text + "\n" + indent}
+	}
+
+	offset := lineEnd + 1
+	next := src[offset:]
+	if i := strings.IndexByte(next, '\n'); i >= 0 {
+		next = next[:i]
+	}
+	indent := next[:len(next)-len(strings.TrimLeft(next, " \t"))]
+	if trimmed := strings.TrimSpace(next); trimmed == "" || strings.HasPrefix(trimmed, "}") {
+		indent = lineIndent + defaultFixIndent
+	}
+	return editRange{start: offset, end: offset, text: indent + text + "\n"}
+}
+
+// lineOffsets returns byte offsets of the line starts
+func lineOffsets(src string) []int {
+	offsets := []int{0}
+	for i := 0; i < len(src); i++ {
+		if src[i] == '\n' {
+			offsets = append(offsets, i+1)
+		}
+	}
+	return offsets
+}
+
+// charOffset converts 1-based rune position in the line to byte offset
+func charOffset(src string, lineStart, position int) int {
+	offset := lineStart
+	for i := 1; i < position && offset < len(src); i++ {
+		_, size := utf8.DecodeRuneInString(src[offset:])
+		offset += size
+	}
+	return offset
+}
+
+// statementEnd finds the offset after terminating semicolon or closing brace of the declaration,
+// skipping strings and comments
+func statementEnd(src string, start int) (int, bool) {
+	var depth int
+	for i := start; i < len(src); i++ {
+		switch {
+		case src[i] == ';' && depth == 0:
+			return i + 1, true
+		case src[i] == '{' && !strings.HasPrefix(src[i:], `{"`):
+			depth++
+		case src[i] == '}':
+			depth--
+			if depth == 0 {
+				return i + 1, true
+			}
+			if depth < 0 {
+				return 0, false
+			}
+		case strings.HasPrefix(src[i:], `{"`):
+			end := strings.Index(src[i+2:], `"}`)
+			if end < 0 {
+				return 0, false
+			}
+			i += end + 3
+		case src[i] == '"':
+			end := strings.IndexByte(src[i+1:], '"')
+			if end < 0 {
+				return 0, false
+			}
+			i += end + 1
+		case strings.HasPrefix(src[i:], "/*"):
+			end := strings.Index(src[i+2:], "*/")
+			if end < 0 {
+				return 0, false
+			}
+			i += end + 3
+		case strings.HasPrefix(src[i:], "//"), src[i] == '#':
+			end := strings.IndexByte(src[i:], '\n')
+			if end < 0 {
+				return 0, false
+			}
+			i += end
+		}
+	}
+	return 0, false
+}
+
+// expandToLine expands the range to the whole line when the line contains only the statement and its comment
+func expandToLine(src string, start, end int) editRange {
+	lineStart := strings.LastIndexByte(src[:start], '\n') + 1
+	if strings.TrimSpace(src[lineStart:start]) != "" {
+		return editRange{start: start, end: end}
+	}
+	lineEnd := strings.IndexByte(src[end:], '\n')
+	if lineEnd < 0 {
+		lineEnd = len(src) - end
+	} else {
+		lineEnd++ // include LF
+	}
+	// Trailing line comment belongs to the statement
+	rest := strings.TrimSpace(src[end : end+lineEnd])
+	if rest != "" && !strings.HasPrefix(rest, "#") && !strings.HasPrefix(rest, "//") {
+		return editRange{start: start, end: end}
+	}
+	end += lineEnd
+
+	// Remove following blank line too not to leave consecutive blank lines
+	if lineStart == 0 || strings.HasSuffix(strings.TrimRight(src[:lineStart], " \t"), "\n\n") {
+		if next := strings.IndexByte(src[end:], '\n'); next >= 0 && strings.TrimSpace(src[end:end+next]) == "" {
+			end += next + 1
+		}
+	}
+	return editRange{start: lineStart, end: end}
+}
@@ -83,7 +83,15 @@ func (l *Linter) lintFunctionArguments(fn *context.BuiltinFunction, calledFn fun
 	}
 
 	for i, v := range argTypes {
+		// Declaration arguments like TABLE or ACL must be declared in any included files or snippets
+		if kind, ok := referenceKinds[v]; ok && !l.lintReference(calledFn.arguments[i], kind, ctx) {
+			continue
+		}
 		arg := l.lint(calledFn.arguments[i], ctx)
+		// Unknown type like template placeholder is not checked
+		if arg == types.NeverType {
+			continue
+		}
 
 		if t, ok := implicitCoersionTable[v]; ok {
 			if !expectType(arg, append(t, v)...) {
@@ -0,0 +1,79 @@
+package linter
+
+import (
+	"strings"
+
+	"github.com/ysugimoto/falco/ast"
+)
+
+// lintDangerousGotos reports goto statements which Fastly accepts but routinely cause logic bugs:
+// - jumping into the middle of nested block, the condition of the block is bypassed
+// - skipping over declare statements whose variables are used after the destination
+// Jumping backward is reported by goto-loop rule.
+func (l *Linter) lintDangerousGotos(decl *ast.SubroutineDeclaration) {
+	defer l.measure(GOTO_DANGEROUS)()
+	tree := ast.NewTree(decl.Block)
+	nodes := tree.Nodes()
+
+	destinations := make(map[string]*ast.GotoDestinationStatement)
+	var declares []*ast.DeclareStatement
+	var idents []*ast.Ident
+	for _, n := range nodes {
+		switch t := n.(type) {
+		case *ast.GotoDestinationStatement:
+			destinations[strings.TrimSuffix(t.Name.Value, ":")] = t
+		case *ast.DeclareStatement:
+			declares = append(declares, t)
+		case *ast.Ident:
+			if strings.HasPrefix(t.Value, "var.") {
+				idents = append(idents, t)
+			}
+		}
+	}
+
+	for _, n := range nodes {
+		g, ok := n.(*ast.GotoStatement)
+		if !ok {
+			continue
+		}
+		dest, ok := destinations[g.Destination.Value]
+		if !ok || !lessMeta(g.GetMeta(), dest.GetMeta()) {
+			continue
+		}
+
+		// The block of the destination must enclose the goto statement
+		if !encloses(tree, tree.Parent(dest), g) {
+			l.Error(GotoIntoBlock(g.GetMeta(), g.Destination.Value).Match(GOTO_DANGEROUS))
+			continue
+		}
+
+		for _, d := range declares {
+			if !lessMeta(g.GetMeta(), d.GetMeta()) || !lessMeta(d.GetMeta(), dest.GetMeta()) {
+				continue
+			}
+			if usedAfter(idents, d.Name.Value, dest.GetMeta()) {
+				l.Error(GotoSkipsDeclaration(g.GetMeta(), g.Destination.Value, d.Name.Value).Match(GOTO_DANGEROUS))
+			}
+		}
+	}
+}
+
+// encloses returns true if the block is an ancestor of the node
+func encloses(tree *ast.Tree, block, node ast.Node) bool {
+	for _, p := range tree.Ancestors(node) {
+		if p == block {
+			return true
+		}
+	}
+	return false
+}
+
+// usedAfter returns true if the variable is referenced after the position
+func usedAfter(idents []*ast.Ident, name string, m *ast.Meta) bool {
+	for _, ident := range idents {
+		if ident.Value == name && lessMeta(m, ident.GetMeta()) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,60 @@
+package linter
+
+import (
+	"strings"
+
+	"github.com/ysugimoto/falco/ast"
+	"github.com/ysugimoto/falco/context"
+)
+
+// lintHashOutsideVclHash reports modification of req.hash outside of vcl_hash.
+// Fastly accepts it in vcl_error but the cache key has already been computed, so it does not affect the cache lookup.
+// Modification in other scopes is reported as the scope error.
+func (l *Linter) lintHashOutsideVclHash(stmt *ast.SetStatement, ctx *context.Context) {
+	defer l.measure(HASH_OUTSIDE_VCL_HASH)()
+	if stmt.Ident.Value != "req.hash" || ctx.Mode()&context.HASH != 0 {
+		return
+	}
+	l.Error(HashOutsideVclHash(stmt.Ident.GetMeta(), context.ScopeString(ctx.Mode())).Match(HASH_OUTSIDE_VCL_HASH))
+}
+
+// lintHashMissingKey reports vcl_hash which does not add the URL or the host to req.hash.
+// Then different resources could share the same cache object, the intentional case should be suppressed by ignore comment.
+func (l *Linter) lintHashMissingKey(decl *ast.SubroutineDeclaration) {
+	defer l.measure(HASH_MISSING_KEY)()
+	if decl.Name.Value != "vcl_hash" {
+		return
+	}
+
+	var url, host bool
+	for _, n := range ast.NewTree(decl.Block).Nodes() {
+		set, ok := n.(*ast.SetStatement)
+		if !ok || set.Ident.Value != "req.hash" {
+			continue
+		}
+		for _, v := range ast.NewTree(set.Value).Nodes() {
+			ident, ok := v.(*ast.Ident)
+			if !ok {
+				continue
+			}
+			name := strings.ToLower(ident.Value)
+			switch {
+			case strings.HasPrefix(name, "req.url"):
+				url = true
+			case name == "req.http.host":
+				host = true
+			}
+		}
+	}
+
+	var missing []string
+	if !url {
+		missing = append(missing, "req.url")
+	}
+	if !host {
+		missing = append(missing, "req.http.host")
+	}
+	if len(missing) > 0 {
+		l.Error(HashMissingKey(decl.Name.GetMeta(), missing).Match(HASH_MISSING_KEY))
+	}
+}
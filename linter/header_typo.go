@@ -0,0 +1,207 @@
+package linter
+
+import (
+	"strings"
+
+	"github.com/ysugimoto/falco/ast"
+)
+
+// Standard HTTP headers and Fastly specific headers, used to find typos of header names
+var knownHeaders = []string{
+	// Standard headers
+	"Accept",
+	"Accept-Charset",
+	"Accept-Encoding",
+	"Accept-Language",
+	"Accept-Ranges",
+	"Access-Control-Allow-Credentials",
+	"Access-Control-Allow-Headers",
+	"Access-Control-Allow-Methods",
+	"Access-Control-Allow-Origin",
+	"Access-Control-Expose-Headers",
+	"Access-Control-Max-Age",
+	"Access-Control-Request-Headers",
+	"Access-Control-Request-Method",
+	"Age",
+	"Allow",
+	"Authorization",
+	"Cache-Control",
+	"Connection",
+	"Content-Disposition",
+	"Content-Encoding",
+	"Content-Language",
+	"Content-Length",
+	"Content-Location",
+	"Content-Range",
+	"Content-Security-Policy",
+	"Content-Type",
+	"Cookie",
+	"Date",
+	"ETag",
+	"Expect",
+	"Expires",
+	"Forwarded",
+	"From",
+	"Host",
+	"If-Match",
+	"If-Modified-Since",
+	"If-None-Match",
+	"If-Range",
+	"If-Unmodified-Since",
+	"Keep-Alive",
+	"Last-Modified",
+	"Link",
+	"Location",
+	"Max-Forwards",
+	"Origin",
+	"Pragma",
+	"Proxy-Authenticate",
+	"Proxy-Authorization",
+	"Range",
+	"Referer",
+	"Referrer-Policy",
+	"Retry-After",
+	"Server",
+	"Set-Cookie",
+	"Strict-Transport-Security",
+	"TE",
+	"Trailer",
+	"Transfer-Encoding",
+	"Upgrade",
+	"User-Agent",
+	"Vary",
+	"Via",
+	"WWW-Authenticate",
+	"X-Content-Type-Options",
+	"X-Forwarded-For",
+	"X-Forwarded-Host",
+	"X-Forwarded-Proto",
+	"X-Forwarded-Server",
+	"X-Frame-Options",
+	"X-Real-IP",
+	"X-Requested-With",
+	"X-XSS-Protection",
+	// Fastly specific headers
+	"Fastly-Client-IP",
+	"Fastly-Debug",
+	"Fastly-Debug-Digest",
+	"Fastly-Debug-Path",
+	"Fastly-Debug-TTL",
+	"Fastly-FF",
+	"Fastly-Key",
+	"Fastly-No-Shield",
+	"Fastly-Orig-Accept-Encoding",
+	"Fastly-SSL",
+	"Fastly-Soft-Purge",
+	"Surrogate-Capability",
+	"Surrogate-Control",
+	"Surrogate-Key",
+	"X-Cache",
+	"X-Cache-Hits",
+	"X-Served-By",
+	"X-Timer",
+}
+
+// Header names shorter than this length are not checked because they easily match other names
+const minTypoHeaderLength = 5
+
+// lintHeaderTypos reports header names which are not known but very similar to known headers
+func (l *Linter) lintHeaderTypos(decl *ast.SubroutineDeclaration) {
+	defer l.measure(HEADER_TYPO)()
+	allowed := make(map[string]struct{})
+	for _, name := range l.stringsRuleOption(HEADER_TYPO, "allow") {
+		allowed[strings.ToLower(name)] = struct{}{}
+	}
+
+	for _, n := range ast.NewTree(decl.Block).Nodes() {
+		ident, ok := n.(*ast.Ident)
+		if !ok {
+			continue
+		}
+		index := strings.Index(ident.Value, ".http.")
+		if index < 0 {
+			continue
+		}
+		name := ident.Value[index+6:]
+		// Subfield like req.http.Cookie:session
+		if i := strings.Index(name, ":"); i >= 0 {
+			name = name[:i]
+		}
+		if _, ok := allowed[strings.ToLower(name)]; ok {
+			continue
+		}
+		if suggestion := similarHeader(name); suggestion != "" {
+			l.Error(HeaderTypo(ident.GetMeta(), name, suggestion).Match(HEADER_TYPO))
+		}
+	}
+}
+
+// similarHeader returns known header name which is near-miss of the name, or empty string
+func similarHeader(name string) string {
+	if len(name) < minTypoHeaderLength {
+		return ""
+	}
+	lower := strings.ToLower(name)
+	// Allow one edit for short names, two edits for long names
+	threshold := 1
+	if len(name) >= 12 {
+		threshold = 2
+	}
+
+	var suggestion string
+	for _, header := range knownHeaders {
+		h := strings.ToLower(header)
+		if h == lower {
+			return ""
+		}
+		if suggestion != "" || abs(len(h)-len(lower)) > threshold {
+			continue
+		}
+		if editDistance(h, lower) <= threshold {
+			suggestion = header
+		}
+	}
+	return suggestion
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+// editDistance calculates Levenshtein distance which also counts transposition of adjacent characters as one edit
+func editDistance(a, b string) int {
+	prev2 := make([]int, len(b)+1)
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = minInt(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				cur[j] = minInt(cur[j], prev2[j-2]+1)
+			}
+		}
+		prev2, prev, cur = prev, cur, prev2
+	}
+	return prev[len(b)]
+}
+
+func minInt(values ...int) int {
+	m := values[0]
+	for _, v := range values[1:] {
+		if v < m {
+			m = v
+		}
+	}
+	return m
+}
@@ -24,6 +24,10 @@ var BackendPropertyTypes = map[string]types.Type{
 	"min_tls_version":          types.StringType,
 	"ssl_check_cert":           types.IDType,
 	"ssl_sni_hostname":         types.StringType,
+	"ssl_ca_cert":              types.StringType,
+	"ssl_client_cert":          types.StringType,
+	"ssl_client_key":           types.StringType,
+	"ssl_ciphers":              types.StringType,
 	"between_bytes_timeout":    types.RTimeType,
 	"connect_timeout":          types.RTimeType,
 	"first_byte_timeout":       types.RTimeType,
@@ -89,7 +93,7 @@ var DirectorPropertyTypes = map[string]DirectorProps{
 	"chash": {
 		Rule: DIRECTOR_PROPS_CHASH,
 		Props: map[string]types.Type{
-			"key":             types.BackendType, // TODO: need accept object or client
+			"key":             types.IDType, // object or client
 			"seed":            types.IntegerType,
 			"vnodes_per_node": types.IntegerType,
 			"quorum":          types.StringType,
@@ -330,7 +334,9 @@ func annotations(comments ast.Comments) []string {
 	return rv
 }
 
-func getSubroutineCallScope(s *ast.SubroutineDeclaration) int {
+// getSubroutineExplicitScope returns scopes which are declared by subroutine name or annotations,
+// returns zero when the subroutine does not declare any scopes.
+func getSubroutineExplicitScope(s *ast.SubroutineDeclaration) int {
 	// Detect phase from subroutine name
 	switch {
 	case strings.HasSuffix(s.Name.Value, "_recv"):
@@ -378,12 +384,21 @@ func getSubroutineCallScope(s *ast.SubroutineDeclaration) int {
 			scopes |= context.LOG
 		}
 	}
-	if scopes == 0 {
-		return context.RECV
-	}
 	return scopes
 }
 
+// Varnish 4.x builtin subroutines which have different name from Fastly
+var varnish4SubroutineScopes = map[string]int{
+	"vcl_init":             context.RECV,
+	"vcl_fini":             context.RECV,
+	"vcl_purge":            context.RECV,
+	"vcl_pipe":             context.PASS,
+	"vcl_backend_fetch":    context.MISS | context.PASS,
+	"vcl_backend_response": context.FETCH,
+	"vcl_backend_error":    context.ERROR,
+	"vcl_synth":            context.ERROR,
+}
+
 func getFastlySubroutineScope(name string) string {
 	switch name {
 	case "vcl_recv":
@@ -14,10 +14,112 @@ const (
 	falcoIgnoreEnd      = "falco-ignore-end"
 )
 
+// suppression represents single ignore comment.
+// Signature could be followed by comma separated rule names and the reason like:
+//
+// // falco-ignore unused/variable,condition/literal legacy code
+//
+// Then only specified rules are suppressed. Empty rules suppress all errors.
+type suppression struct {
+	comment *ast.Comment
+	rules   map[Rule]struct{}
+	used    bool
+}
+
+func (s *suppression) match(rule Rule) bool {
+	if len(s.rules) == 0 {
+		return true
+	}
+	_, ok := s.rules[rule]
+	return ok
+}
+
 type ignore struct {
-	ignoreNextLine bool
-	ignoreThisLine bool
-	ignoreRange    bool
+	ignoreNextLine *suppression
+	ignoreThisLine *suppression
+	ignoreRanges   []*suppression
+
+	// all suppressions keyed by comment to report unused ones
+	suppressions map[*ast.Comment]*suppression
+	order        []*suppression
+}
+
+func newIgnore() *ignore {
+	return &ignore{
+		suppressions: make(map[*ast.Comment]*suppression),
+	}
+}
+
+// parse finds ignore signature in the comment and returns the suppression
+func (i *ignore) parse(c *ast.Comment) (string, *suppression) {
+	line := strings.TrimRight(strings.TrimLeft(c.String(), "#@*/ "), "*/ ")
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return "", nil
+	}
+
+	signature := fields[0]
+	switch signature {
+	case falcoIgnoreNextLine, falcoIgnoreThisLine, falcoIgnoreStart, falcoIgnoreEnd:
+	default:
+		return "", nil
+	}
+
+	// Same comment may be set up multiple times, e.g. snippets
+	if s, ok := i.suppressions[c]; ok {
+		return signature, s
+	}
+	s := &suppression{comment: c}
+	if len(fields) > 1 && isRuleList(fields[1]) {
+		s.rules = make(map[Rule]struct{})
+		for _, r := range strings.Split(fields[1], ",") {
+			if r != "" {
+				s.rules[Rule(r)] = struct{}{}
+			}
+		}
+	}
+	if signature != falcoIgnoreEnd {
+		i.suppressions[c] = s
+		i.order = append(i.order, s)
+	}
+	return signature, s
+}
+
+// isRuleList returns true when the word is comma separated rule names,
+// otherwise the word is a part of reason.
+func isRuleList(word string) bool {
+	for _, r := range strings.Split(word, ",") {
+		if r == "" {
+			continue
+		}
+		if _, ok := references[Rule(r)]; ok {
+			continue
+		}
+		if !strings.Contains(r, "/") {
+			return false
+		}
+	}
+	return true
+}
+
+func (i *ignore) setupLeading(comments ast.Comments) {
+	for _, c := range comments {
+		signature, s := i.parse(c)
+		switch signature {
+		case falcoIgnoreNextLine:
+			i.ignoreNextLine = s
+		case falcoIgnoreStart:
+			i.ignoreRanges = append(i.ignoreRanges, s)
+		case falcoIgnoreEnd:
+			i.endRange()
+		}
+	}
+}
+
+func (i *ignore) endRange() {
+	if len(i.ignoreRanges) > 0 {
+		i.ignoreRanges = i.ignoreRanges[:len(i.ignoreRanges)-1]
+	}
 }
 
 // Setup ignores for common statements, declarations.
@@ -30,31 +132,20 @@ type ignore struct {
 // trailing comments accept falco-ignore
 func (i *ignore) SetupStatement(meta *ast.Meta) {
 	// Find ignore signature in leading comments
-	for _, c := range meta.Leading {
-		line := strings.TrimLeft(c.String(), "#@*/ ")
-		switch {
-		case strings.HasPrefix(line, falcoIgnoreNextLine):
-			i.ignoreNextLine = true
-		case strings.HasPrefix(line, falcoIgnoreStart):
-			i.ignoreRange = true
-		case strings.HasPrefix(line, falcoIgnoreEnd):
-			i.ignoreRange = false
-		}
-	}
+	i.setupLeading(meta.Leading)
 
 	// Find ignore signature in trailing comments
 	for _, c := range meta.Trailing {
-		line := strings.TrimLeft(c.String(), "#@*/ ")
-		if strings.HasPrefix(line, falcoIgnoreThisLine) {
-			i.ignoreThisLine = true
+		if signature, s := i.parse(c); signature == falcoIgnoreThisLine {
+			i.ignoreThisLine = s
 		}
 	}
 }
 
 // Clean up common statements, declarations
 func (i *ignore) TeardownStatement() {
-	i.ignoreNextLine = false
-	i.ignoreThisLine = false
+	i.ignoreNextLine = nil
+	i.ignoreThisLine = nil
 }
 
 // Block statement is special, the comment placing is following:
@@ -69,30 +160,40 @@ func (i *ignore) TeardownStatement() {
 //
 // So we need to divide parsing leading and trailing comment by setup and teardown
 func (i *ignore) SetupBlockStatement(meta *ast.Meta) {
-	for _, c := range meta.Leading {
-		line := strings.TrimLeft(c.String(), "#@*/ ")
-		switch {
-		case strings.HasPrefix(line, falcoIgnoreNextLine):
-			i.ignoreNextLine = true
-		case strings.HasPrefix(line, falcoIgnoreStart):
-			i.ignoreRange = true
-		case strings.HasPrefix(line, falcoIgnoreEnd):
-			i.ignoreRange = false
-		}
-	}
+	i.setupLeading(meta.Leading)
 }
+
 func (i *ignore) TeardownBlockStatement(meta *ast.Meta) {
-	i.ignoreNextLine = false
-	i.ignoreThisLine = false
+	i.ignoreNextLine = nil
+	i.ignoreThisLine = nil
 
 	for _, c := range meta.Trailing {
-		line := strings.TrimLeft(c.String(), "#@*/ ")
-		if strings.HasPrefix(line, falcoIgnoreEnd) {
-			i.ignoreRange = false
+		if signature, _ := i.parse(c); signature == falcoIgnoreEnd {
+			i.endRange()
 		}
 	}
 }
 
-func (i *ignore) IsEnable() bool {
-	return i.ignoreNextLine || i.ignoreThisLine || i.ignoreRange
+// Suppress returns true when the rule is suppressed by active ignore comments,
+// and marks matched suppressions as used.
+func (i *ignore) Suppress(rule Rule) bool {
+	var suppressed bool
+	for _, s := range append([]*suppression{i.ignoreNextLine, i.ignoreThisLine}, i.ignoreRanges...) {
+		if s != nil && s.match(rule) {
+			s.used = true
+			suppressed = true
+		}
+	}
+	return suppressed
+}
+
+// Unused returns ignore comments which did not suppress any errors
+func (i *ignore) Unused() []*ast.Comment {
+	var unused []*ast.Comment
+	for _, s := range i.order {
+		if !s.used {
+			unused = append(unused, s.comment)
+		}
+	}
+	return unused
 }
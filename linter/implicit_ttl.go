@@ -0,0 +1,165 @@
+package linter
+
+import (
+	"strings"
+
+	"github.com/ysugimoto/falco/ast"
+	"github.com/ysugimoto/falco/context"
+)
+
+// Response headers which control the cache TTL, keyed by lower-cased variable name
+var cacheControlHeaders = map[string]struct{}{
+	"beresp.http.cache-control":     {},
+	"beresp.http.surrogate-control": {},
+	"beresp.http.expires":           {},
+	"beresp.http.edge-control":      {},
+}
+
+// lintImplicitTTL reports paths of vcl_fetch which deliver the response without deciding the cache TTL.
+// On these paths the TTL is derived from origin headers or Fastly's default TTL of 3600s which often surprises users.
+// The decision is made by setting beresp.ttl, handling cache control headers, disabling cache with beresp.cacheable
+// or return(pass). Many teams rely on origin headers, so it is checked only when "enabled" rule option is set.
+func (l *Linter) lintImplicitTTL(decl *ast.SubroutineDeclaration, ctx *context.Context) {
+	defer l.measure(CACHE_IMPLICIT_TTL)()
+	if decl.Name.Value != "vcl_fetch" || !l.boolRuleOption(CACHE_IMPLICIT_TTL, "enabled") {
+		return
+	}
+	t := &ttlAnalysis{ctx: ctx, called: make(map[string]bool)}
+	if !t.block(decl.Block, false) {
+		l.Error(ImplicitTTL(decl.Name.GetMeta()).Match(CACHE_IMPLICIT_TTL))
+	}
+	for _, ret := range t.implicit {
+		l.Error(ImplicitTTL(ret.GetMeta()).Match(CACHE_IMPLICIT_TTL))
+	}
+}
+
+type ttlAnalysis struct {
+	ctx *context.Context
+	// Cache of called subroutines which decide the cache TTL
+	called map[string]bool
+	// Return statements which deliver the response without deciding the cache TTL
+	implicit []*ast.ReturnStatement
+}
+
+// block returns true if the TTL is decided at the end of the block, or the block does not reach the end
+func (t *ttlAnalysis) block(b *ast.BlockStatement, decided bool) bool {
+	for _, stmt := range b.Statements {
+		switch s := stmt.(type) {
+		case *ast.ReturnStatement:
+			if !decided && returnsDeliver(s) {
+				t.implicit = append(t.implicit, s)
+			}
+			return true
+		case *ast.ErrorStatement, *ast.RestartStatement:
+			return true
+		case *ast.BlockStatement:
+			decided = t.block(s, decided)
+		case *ast.IfStatement:
+			decided = t.ifStatement(s, decided)
+		default:
+			decided = decided || t.decides(stmt)
+		}
+	}
+	return decided
+}
+
+func (t *ttlAnalysis) ifStatement(stmt *ast.IfStatement, decided bool) bool {
+	// Inspecting cache control headers in the condition is the decision of the TTL for all branches
+	decided = decided || readsCacheControl(stmt.Condition)
+	all := t.block(stmt.Consequence, decided)
+	for _, a := range stmt.Another {
+		d := decided || readsCacheControl(a.Condition)
+		all = t.block(a.Consequence, d) && all
+	}
+	if stmt.Alternative != nil {
+		return t.block(stmt.Alternative, decided) && all
+	}
+	return decided && all
+}
+
+// decides returns true if the statement decides the cache TTL
+func (t *ttlAnalysis) decides(stmt ast.Statement) bool {
+	var name string
+	switch s := stmt.(type) {
+	case *ast.SetStatement:
+		name = strings.ToLower(s.Ident.Value)
+		if name == "beresp.ttl" {
+			return true
+		}
+		if name == "beresp.cacheable" {
+			return !isTrueLiteral(s.Value)
+		}
+	case *ast.AddStatement:
+		name = strings.ToLower(s.Ident.Value)
+	case *ast.UnsetStatement:
+		name = strings.ToLower(s.Ident.Value)
+	case *ast.RemoveStatement:
+		name = strings.ToLower(s.Ident.Value)
+	case *ast.CallStatement:
+		return t.calledDecides(s.Subroutine.Value)
+	}
+	return isCacheControlHeader(name)
+}
+
+// calledDecides returns true if the called subroutine decides the cache TTL in any statement.
+// Paths of the called subroutine are not traced, it is trusted to decide the TTL.
+func (t *ttlAnalysis) calledDecides(name string) bool {
+	if v, ok := t.called[name]; ok {
+		return v
+	}
+	// Mark as not decided during the analysis to prevent infinite recursion
+	t.called[name] = false
+	sub, ok := t.ctx.Subroutines[name]
+	if !ok || sub.Decl == nil {
+		return false
+	}
+	for _, n := range ast.NewTree(sub.Decl.Block).Nodes() {
+		switch s := n.(type) {
+		case *ast.ReturnStatement:
+			if s.ReturnExpression != nil && !returnsDeliver(s) {
+				t.called[name] = true
+			}
+		case *ast.IfStatement:
+			if readsCacheControl(s.Condition) {
+				t.called[name] = true
+			}
+		case ast.Statement:
+			if t.decides(s) {
+				t.called[name] = true
+			}
+		}
+		if t.called[name] {
+			return true
+		}
+	}
+	return false
+}
+
+// returnsDeliver returns true if the return statement delivers the response to cache,
+// return statement without state in the subroutine called from vcl_fetch returns to the caller
+func returnsDeliver(stmt *ast.ReturnStatement) bool {
+	if stmt.ReturnExpression == nil {
+		return false
+	}
+	ident, ok := (*stmt.ReturnExpression).(*ast.Ident)
+	return ok && ident.Value == "deliver"
+}
+
+func readsCacheControl(cond ast.Expression) bool {
+	for _, n := range ast.NewTree(cond).Nodes() {
+		if ident, ok := n.(*ast.Ident); ok && isCacheControlHeader(ident.Value) {
+			return true
+		}
+	}
+	return false
+}
+
+// isCacheControlHeader returns true if the variable is the cache control header including subfield like Cache-Control:max-age
+func isCacheControlHeader(name string) bool {
+	name = strings.ToLower(name)
+	if index := strings.Index(name, ":"); index > 0 {
+		name = name[:index]
+	}
+	_, ok := cacheControlHeaders[name]
+	return ok
+}
@@ -0,0 +1,316 @@
+package linter
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ysugimoto/falco/ast"
+	"github.com/ysugimoto/falco/context"
+)
+
+// Fastly's documented platform limits
+// See https://docs.fastly.com/en/guides/resource-limits
+const (
+	defaultMaxSyntheticBytes    = 64 * 1024
+	defaultMaxHeaderCount       = 96
+	defaultMaxHeaderBytes       = 69 * 1024
+	defaultMaxWorkspaceBytes    = 64 * 1024
+	defaultMaxAclEntries        = 1000
+	defaultMaxAcls              = 1000
+	defaultMaxBackends          = 5
+	defaultMaxSurrogateKeyBytes = 1024
+	defaultMaxSurrogateKeysSize = 16 * 1024
+	defaultMaxTableKeyBytes     = 256
+	defaultMaxTableValueBytes   = 8000
+	defaultMaxTableTotalBytes   = 1024 * 1024
+)
+
+// staticStringLength returns byte length of the string which is known statically,
+// dynamic values in the concatenation are counted as zero.
+func staticStringLength(exp ast.Expression) int {
+	switch t := exp.(type) {
+	case *ast.String:
+		return len(t.Value)
+	case *ast.GroupedExpression:
+		return staticStringLength(t.Right)
+	case *ast.InfixExpression:
+		if t.Operator == "+" {
+			return staticStringLength(t.Left) + staticStringLength(t.Right)
+		}
+	}
+	return 0
+}
+
+func isConcatenation(exp ast.Expression) bool {
+	switch t := exp.(type) {
+	case *ast.GroupedExpression:
+		return isConcatenation(t.Right)
+	case *ast.InfixExpression:
+		return t.Operator == "+"
+	}
+	return false
+}
+
+func (l *Linter) lintAclEntryLimit(decl *ast.AclDeclaration) {
+	defer l.measure(LIMIT_ACL_ENTRIES)()
+	if max := l.intRuleOption(LIMIT_ACL_ENTRIES, "max", defaultMaxAclEntries); len(decl.CIDRs) > max {
+		l.Error(LimitExceeded(
+			decl.Name.GetMeta(), fmt.Sprintf(`ACL "%s" has %d entries`, decl.Name.Value, len(decl.CIDRs)), "entries", max,
+		).Match(LIMIT_ACL_ENTRIES))
+	}
+}
+
+func (l *Linter) lintSyntheticLimit(value ast.Expression, base64 bool) {
+	defer l.measure(LIMIT_SYNTHETIC_SIZE)()
+	size := staticStringLength(value)
+	if base64 {
+		size = size * 3 / 4
+	}
+	if max := l.intRuleOption(LIMIT_SYNTHETIC_SIZE, "max_bytes", defaultMaxSyntheticBytes); size > max {
+		l.Error(LimitExceeded(
+			value.GetMeta(), fmt.Sprintf("Synthetic response is %d bytes", size), "bytes", max,
+		).Match(LIMIT_SYNTHETIC_SIZE))
+	}
+}
+
+// lintAssignmentLimits checks size limits of the value which is assigned to the variable by set or add statement
+func (l *Linter) lintAssignmentLimits(name string, value ast.Expression) {
+	defer l.measure(Rule("limit"))()
+	size := staticStringLength(value)
+
+	if isConcatenation(value) {
+		if max := l.intRuleOption(LIMIT_WORKSPACE, "max_bytes", defaultMaxWorkspaceBytes); size > max {
+			l.Error(LimitExceeded(
+				value.GetMeta(), fmt.Sprintf("String concatenation for %s allocates %d bytes in workspace", name, size), "bytes", max,
+			).Match(LIMIT_WORKSPACE))
+		}
+	}
+
+	if !strings.Contains(name, ".http.") {
+		return
+	}
+	if max := l.intRuleOption(LIMIT_HEADER_SIZE, "max_bytes", defaultMaxHeaderBytes); size > max {
+		l.Error(LimitExceeded(
+			value.GetMeta(), fmt.Sprintf("Header %s value is %d bytes", name, size), "bytes", max,
+		).Match(LIMIT_HEADER_SIZE))
+	}
+
+	if !strings.EqualFold(name[strings.Index(name, ".http.")+6:], "Surrogate-Key") {
+		return
+	}
+	if max := l.intRuleOption(LIMIT_SURROGATE_KEY, "max_header_bytes", defaultMaxSurrogateKeysSize); size > max {
+		l.Error(LimitExceeded(
+			value.GetMeta(), fmt.Sprintf("Surrogate-Key header is %d bytes", size), "bytes", max,
+		).Match(LIMIT_SURROGATE_KEY))
+	}
+	// Each key in the literal string
+	max := l.intRuleOption(LIMIT_SURROGATE_KEY, "max_key_bytes", defaultMaxSurrogateKeyBytes)
+	for _, s := range stringLiterals(value) {
+		for _, key := range strings.Fields(s.Value) {
+			if len(key) > max {
+				l.Error(LimitExceeded(
+					s.GetMeta(), fmt.Sprintf("Surrogate key %.32s... is %d bytes", key, len(key)), "bytes", max,
+				).Match(LIMIT_SURROGATE_KEY))
+			}
+		}
+	}
+}
+
+func stringLiterals(exp ast.Expression) []*ast.String {
+	switch t := exp.(type) {
+	case *ast.String:
+		return []*ast.String{t}
+	case *ast.GroupedExpression:
+		return stringLiterals(t.Right)
+	case *ast.InfixExpression:
+		if t.Operator == "+" {
+			return append(stringLiterals(t.Left), stringLiterals(t.Right)...)
+		}
+	}
+	return nil
+}
+
+// lintResourceLimits checks counts of declarations and headers in whole VCLs
+func (l *Linter) lintResourceLimits(ctx *context.Context) {
+	defer l.measure(Rule("limit"))()
+	var acls, backends, directors []*ast.Meta
+	var externalAcls, externalBackends int
+	for _, a := range ctx.Acls {
+		if a.Decl == nil {
+			externalAcls++
+		} else {
+			acls = append(acls, a.Decl.Name.GetMeta())
+		}
+	}
+	for _, b := range ctx.Backends {
+		switch {
+		case b.DirectorDecl != nil:
+			directors = append(directors, b.DirectorDecl.Name.GetMeta())
+		case b.BackendDecl != nil:
+			backends = append(backends, b.BackendDecl.Name.GetMeta())
+		default:
+			externalBackends++
+		}
+	}
+
+	if max := l.intRuleOption(LIMIT_ACL_COUNT, "max", defaultMaxAcls); len(acls)+externalAcls > max {
+		m := exceededDeclaration(acls, externalAcls, max)
+		l.Error(LimitExceeded(m, fmt.Sprintf("%d ACLs are declared", len(acls)+externalAcls), "ACLs", max).Match(LIMIT_ACL_COUNT))
+	}
+	if max := l.intRuleOption(LIMIT_BACKEND_COUNT, "max", defaultMaxBackends); len(backends)+externalBackends > max {
+		m := exceededDeclaration(backends, externalBackends, max)
+		l.Error(LimitExceeded(m, fmt.Sprintf("%d backends are declared", len(backends)+externalBackends), "backends", max).Match(LIMIT_BACKEND_COUNT))
+	}
+	// Fastly does not publish default director limit, only checked when the option is specified
+	if max := l.intRuleOption(LIMIT_DIRECTOR_COUNT, "max", 0); max > 0 && len(directors) > max {
+		m := exceededDeclaration(directors, 0, max)
+		l.Error(LimitExceeded(m, fmt.Sprintf("%d directors are declared", len(directors)), "directors", max).Match(LIMIT_DIRECTOR_COUNT))
+	}
+
+	l.lintHeaderCountLimit(ctx)
+	l.lintTableSizeLimit(ctx)
+}
+
+// exceededDeclaration returns the declaration which exceeds the limit in source order
+func exceededDeclaration(metas []*ast.Meta, external, max int) *ast.Meta {
+	sort.SliceStable(metas, func(i, j int) bool {
+		return lessMeta(metas[i], metas[j])
+	})
+	index := max - external
+	if index < 0 {
+		index = 0
+	}
+	if index >= len(metas) {
+		return &ast.Meta{}
+	}
+	return metas[index]
+}
+
+func lessMeta(a, b *ast.Meta) bool {
+	if a.Token.File != b.Token.File {
+		return a.Token.File < b.Token.File
+	}
+	if a.Token.Line != b.Token.Line {
+		return a.Token.Line < b.Token.Line
+	}
+	return a.Token.Position < b.Token.Position
+}
+
+// lintHeaderCountLimit counts distinct header names which are set for each HTTP object
+func (l *Linter) lintHeaderCountLimit(ctx *context.Context) {
+	var blocks []*ast.SubroutineDeclaration
+	for _, s := range ctx.Subroutines {
+		blocks = append(blocks, s.Decl)
+	}
+	for _, s := range ctx.Functions {
+		blocks = append(blocks, s.Decl)
+	}
+	var assigns []*ast.Ident
+	for _, b := range blocks {
+		for _, n := range ast.NewTree(b).Nodes() {
+			switch t := n.(type) {
+			case *ast.SetStatement:
+				assigns = append(assigns, t.Ident)
+			case *ast.AddStatement:
+				assigns = append(assigns, t.Ident)
+			}
+		}
+	}
+	sort.SliceStable(assigns, func(i, j int) bool {
+		return lessMeta(assigns[i].GetMeta(), assigns[j].GetMeta())
+	})
+
+	max := l.intRuleOption(LIMIT_HEADER_COUNT, "max", defaultMaxHeaderCount)
+	headers := make(map[string]map[string]struct{})
+	reported := make(map[string]struct{})
+	for _, ident := range assigns {
+		name := ident.Value
+		index := strings.Index(name, ".http.")
+		if index < 0 {
+			continue
+		}
+		object := name[:index]
+		header := strings.ToLower(name[index+6:])
+		// Subfield like req.http.Cookie:foo is the same header
+		if i := strings.Index(header, ":"); i >= 0 {
+			header = header[:i]
+		}
+		if _, ok := headers[object]; !ok {
+			headers[object] = make(map[string]struct{})
+		}
+		headers[object][header] = struct{}{}
+		if _, ok := reported[object]; !ok && len(headers[object]) > max {
+			reported[object] = struct{}{}
+			l.Error(LimitExceeded(ident.GetMeta(), fmt.Sprintf("%s headers are set for %d names", object, len(headers[object])), "headers", max).Match(LIMIT_HEADER_COUNT))
+		}
+	}
+}
+
+// lintTableSizeLimit checks size of each table item, and total bytes of all tables
+// because table declarations are compiled into the VCL which has size limit.
+func (l *Linter) lintTableSizeLimit(ctx *context.Context) {
+	maxKey := l.intRuleOption(LIMIT_TABLE_SIZE, "max_key_bytes", defaultMaxTableKeyBytes)
+	maxValue := l.intRuleOption(LIMIT_TABLE_SIZE, "max_value_bytes", defaultMaxTableValueBytes)
+
+	type tableSize struct {
+		name  string
+		bytes int
+	}
+	var sizes []tableSize
+	var total int
+	var decls []*ast.TableDeclaration
+	for _, t := range ctx.Tables {
+		if t.Decl != nil {
+			decls = append(decls, t.Decl)
+		}
+	}
+	sort.Slice(decls, func(i, j int) bool {
+		return lessMeta(decls[i].GetMeta(), decls[j].GetMeta())
+	})
+
+	for _, decl := range decls {
+		var bytes int
+		for _, p := range decl.Properties {
+			value := len(p.Value.String())
+			if s, ok := p.Value.(*ast.String); ok {
+				value = len(s.Value)
+			}
+			if len(p.Key.Value) > maxKey {
+				l.Error(LimitExceeded(
+					p.Key.GetMeta(), fmt.Sprintf("Table %s key is %d bytes", decl.Name.Value, len(p.Key.Value)), "bytes", maxKey,
+				).Match(LIMIT_TABLE_SIZE))
+			}
+			if value > maxValue {
+				l.Error(LimitExceeded(
+					p.Value.GetMeta(), fmt.Sprintf("Table %s value for %s is %d bytes", decl.Name.Value, p.Key.Value, value), "bytes", maxValue,
+				).Match(LIMIT_TABLE_SIZE))
+			}
+			bytes += len(p.Key.Value) + value
+		}
+		sizes = append(sizes, tableSize{name: decl.Name.Value, bytes: bytes})
+		total += bytes
+	}
+
+	maxTotal := l.intRuleOption(LIMIT_TABLE_SIZE, "max_total_bytes", defaultMaxTableTotalBytes)
+	if total <= maxTotal || len(decls) == 0 {
+		return
+	}
+	// Summarize the largest tables to find which table should be reduced
+	sort.SliceStable(sizes, func(i, j int) bool {
+		return sizes[i].bytes > sizes[j].bytes
+	})
+	var summary []string
+	for i, s := range sizes {
+		if i == 3 {
+			summary = append(summary, "...")
+			break
+		}
+		summary = append(summary, fmt.Sprintf("%s: %d bytes", s.name, s.bytes))
+	}
+	l.Error(LimitExceeded(
+		decls[0].Name.GetMeta(),
+		fmt.Sprintf("Tables use %d bytes in total (%s)", total, strings.Join(summary, ", ")),
+		"bytes", maxTotal,
+	).Match(LIMIT_TABLE_SIZE))
+}
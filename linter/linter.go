@@ -3,7 +3,9 @@ package linter
 import (
 	"fmt"
 	"net"
+	"regexp"
 	"strings"
+	"time"
 
 	"github.com/pkg/errors"
 	"github.com/ysugimoto/falco/ast"
@@ -13,20 +15,42 @@ import (
 	"github.com/ysugimoto/falco/snippets"
 	"github.com/ysugimoto/falco/token"
 	"github.com/ysugimoto/falco/types"
-	regexp "go.elara.ws/pcre"
 )
 
 type Linter struct {
 	Errors         []error
 	FatalError     *FatalError
 	includexLexers map[string]*lexer.Lexer
+	sourceMap      *ast.SourceMap
 	ignore         *ignore
-}
-
-func New() *Linter {
+	option         *Option
+	customRules    []CustomRule
+	// VCL dialect of the linting program, Fastly specific rules are not reported for Varnish
+	dialect string
+
+	// declarations which are reachable from state-machine subroutines, nil means not analyzed
+	live map[ast.Node]struct{}
+	// scopes which subroutine could be called in through call chains from state-machine subroutines
+	callScopes map[string]int
+	// complexity metrics of linted subroutines
+	complexities []*Complexity
+	// compiled naming convention patterns keyed by declaration kind
+	namingPatterns map[string]*regexp.Regexp
+	// elapsed time of checks keyed by rule name
+	timings map[string]time.Duration
+	// true while the check is measured
+	measuring bool
+}
+
+func New(opts ...OptionFunc) *Linter {
+	o := collect(opts)
 	return &Linter{
 		includexLexers: make(map[string]*lexer.Lexer),
-		ignore:         &ignore{},
+		sourceMap:      ast.NewSourceMap(),
+		ignore:         newIgnore(),
+		option:         o,
+		customRules:    append(registeredRules(), o.CustomRules...),
+		timings:        make(map[string]time.Duration),
 	}
 }
 
@@ -34,9 +58,17 @@ func (l *Linter) Lexers() map[string]*lexer.Lexer {
 	return l.includexLexers
 }
 
+// SourceMap returns the map to trace flattened statements back to the include chain
+func (l *Linter) SourceMap() *ast.SourceMap {
+	return l.sourceMap
+}
+
 func (l *Linter) Error(err error) {
 	if le, ok := err.(*LintError); ok {
-		if !l.ignore.IsEnable() {
+		if _, ok := fastlyOnlyRules[le.Rule]; ok && l.dialect == parser.DialectVarnish4 {
+			return
+		}
+		if !l.ignore.Suppress(le.Rule) {
 			l.Errors = append(l.Errors, le)
 		}
 	} else {
@@ -54,8 +86,18 @@ func (l *Linter) Lint(node ast.Node, ctx *context.Context) types.Type {
 	if ctx == nil {
 		ctx = context.New()
 	}
+	l.dialect = ctx.Dialect()
+	defer l.measureCore()()
 
 	l.lint(node, ctx)
+	// Conditions could refer declarations like ACLs, then lint them before checking unused declarations
+	conditions := l.lintRemoteConditions(ctx)
+
+	// Find declarations which are reachable from state-machine subroutines and conditions
+	l.live = liveDeclarations(ctx, conditions)
+
+	// Find reads of local variables and headers which may not be set through call chains
+	l.lintUninitializedReads(ctx)
 
 	// After whole VCLs have been linted in main VCL, check all definitions are exactly used.
 	l.lintUnusedTables(ctx)
@@ -65,85 +107,107 @@ func (l *Linter) Lint(node ast.Node, ctx *context.Context) types.Type {
 	l.lintUnusedGotos(ctx)
 	l.lintUnusedPenaltyboxes(ctx)
 	l.lintUnusedRatecounters(ctx)
+	l.lintResourceLimits(ctx)
+	l.lintConcatenations(ctx)
+
+	if l.option.ReportUnusedSuppressions {
+		for _, c := range l.ignore.Unused() {
+			l.Error(UnusedSuppression(c).Match(UNUSED_SUPPRESSION))
+		}
+	}
 
 	return types.NeverType
 }
 
 func (l *Linter) lintUnusedTables(ctx *context.Context) {
+	defer l.measure(UNUSED_DECLARATION)()
 	for key, t := range ctx.Tables {
-		if t.IsUsed {
+		if t.IsUsed && (t.Decl == nil || l.isLive(t.Decl)) {
 			continue
 		}
 		if t.Decl == nil {
 			l.Error(UnusedExternalDeclaration(key, "table").Match(UNUSED_DECLARATION))
 		} else {
-			l.Error(UnusedDeclaration(t.Decl.GetMeta(), t.Name, "table").Match(UNUSED_DECLARATION))
+			l.Error(UnusedDeclaration(t.Decl.GetMeta(), t.Name, "table").Match(UNUSED_DECLARATION).WithFix(removeDeclaration(t.Decl)))
 		}
 	}
 }
 
 func (l *Linter) lintUnusedAcls(ctx *context.Context) {
+	defer l.measure(UNUSED_DECLARATION)()
 	for key, a := range ctx.Acls {
-		if a.IsUsed {
+		if a.IsUsed && (a.Decl == nil || l.isLive(a.Decl)) {
 			continue
 		}
 		if a.Decl == nil {
 			l.Error(UnusedExternalDeclaration(key, "acl").Match(UNUSED_DECLARATION))
 		} else {
-			l.Error(UnusedDeclaration(a.Decl.GetMeta(), a.Decl.Name.Value, "acl").Match(UNUSED_DECLARATION))
+			l.Error(UnusedDeclaration(a.Decl.GetMeta(), a.Decl.Name.Value, "acl").Match(UNUSED_DECLARATION).WithFix(removeDeclaration(a.Decl)))
 		}
 	}
 }
 
 func (l *Linter) lintUnusedBackends(ctx *context.Context) {
+	defer l.measure(UNUSED_DECLARATION)()
 	for key, b := range ctx.Backends {
-		if b.IsUsed {
+		if b.IsUsed && l.isLiveBackend(b) {
 			continue
 		}
 		if b.DirectorDecl != nil {
-			l.Error(UnusedDeclaration(b.DirectorDecl.GetMeta(), b.DirectorDecl.Name.Value, "director").Match(UNUSED_DECLARATION))
+			l.Error(UnusedDeclaration(b.DirectorDecl.GetMeta(), b.DirectorDecl.Name.Value, "director").Match(UNUSED_DECLARATION).WithFix(removeDeclaration(b.DirectorDecl)))
 		} else {
 			if b.BackendDecl == nil {
 				l.Error(UnusedExternalDeclaration(key, "backend").Match(UNUSED_DECLARATION))
 			} else {
-				l.Error(UnusedDeclaration(b.BackendDecl.GetMeta(), b.BackendDecl.Name.Value, "backend").Match(UNUSED_DECLARATION))
+				l.Error(UnusedDeclaration(b.BackendDecl.GetMeta(), b.BackendDecl.Name.Value, "backend").Match(UNUSED_DECLARATION).WithFix(removeDeclaration(b.BackendDecl)))
 			}
 		}
 	}
 }
 
 func (l *Linter) lintUnusedSubroutines(ctx *context.Context) {
+	defer l.measure(UNUSED_DECLARATION)()
+	for _, s := range ctx.Functions {
+		if s.IsUsed && l.isLive(s.Decl) {
+			continue
+		}
+		l.Error(UnusedDeclaration(s.Decl.GetMeta(), s.Decl.Name.Value, "subroutine").Match(UNUSED_DECLARATION).WithFix(removeDeclaration(s.Decl)))
+	}
+
 	for _, s := range ctx.Subroutines {
-		if s.IsUsed {
+		if s.IsUsed && l.isLive(s.Decl) {
 			continue
 		}
-		// If subroutine name is fastly's one, it's ok to be unused
-		if context.IsFastlySubroutine(s.Decl.Name.Value) {
+		// If subroutine name is fastly's one or Varnish builtin one on Varnish dialect, it's ok to be unused
+		if isEntrySubroutine(s.Decl.Name.Value, ctx) {
 			continue
 		}
-		l.Error(UnusedDeclaration(s.Decl.GetMeta(), s.Decl.Name.Value, "subroutine").Match(UNUSED_DECLARATION))
+		l.Error(UnusedDeclaration(s.Decl.GetMeta(), s.Decl.Name.Value, "subroutine").Match(UNUSED_DECLARATION).WithFix(removeDeclaration(s.Decl)))
 	}
 }
 
 func (l *Linter) lintUnusedPenaltyboxes(ctx *context.Context) {
+	defer l.measure(UNUSED_DECLARATION)()
 	for _, p := range ctx.Penaltyboxes {
-		if p.IsUsed {
+		if p.IsUsed && l.isLive(p.Decl) {
 			continue
 		}
-		l.Error(UnusedDeclaration(p.Decl.GetMeta(), p.Decl.Name.Value, "penaltybox").Match(UNUSED_DECLARATION))
+		l.Error(UnusedDeclaration(p.Decl.GetMeta(), p.Decl.Name.Value, "penaltybox").Match(UNUSED_DECLARATION).WithFix(removeDeclaration(p.Decl)))
 	}
 }
 
 func (l *Linter) lintUnusedRatecounters(ctx *context.Context) {
+	defer l.measure(UNUSED_DECLARATION)()
 	for _, rc := range ctx.Ratecounters {
-		if rc.IsUsed {
+		if rc.IsUsed && l.isLive(rc.Decl) {
 			continue
 		}
-		l.Error(UnusedDeclaration(rc.Decl.GetMeta(), rc.Decl.Name.Value, "ratecounter").Match(UNUSED_DECLARATION))
+		l.Error(UnusedDeclaration(rc.Decl.GetMeta(), rc.Decl.Name.Value, "ratecounter").Match(UNUSED_DECLARATION).WithFix(removeDeclaration(rc.Decl)))
 	}
 }
 
 func (l *Linter) lintUnusedVariables(ctx *context.Context) {
+	defer l.measure(UNUSED_VARIABLE)()
 	v, ok := ctx.Variables["var"]
 	if !ok {
 		return
@@ -158,6 +222,7 @@ func (l *Linter) lintUnusedVariables(ctx *context.Context) {
 }
 
 func (l *Linter) lintUnusedGotos(ctx *context.Context) {
+	defer l.measure(UNUSED_GOTO)()
 	for _, s := range ctx.Gotos {
 		if s.IsUsed {
 			continue
@@ -168,10 +233,16 @@ func (l *Linter) lintUnusedGotos(ctx *context.Context) {
 }
 
 func (l *Linter) lint(node ast.Node, ctx *context.Context) types.Type {
+	if len(l.customRules) > 0 {
+		l.checkCustomRules(node, ctx)
+	}
+
 	switch t := node.(type) {
 	// Root program
 	case *ast.VCL:
 		return l.lintVCL(t, ctx)
+	case *ast.Condition:
+		return l.lintCondition(t, ctx)
 
 	// Declarations
 	// Note: root declaration has already added in linter context.
@@ -189,12 +260,16 @@ func (l *Linter) lint(node ast.Node, ctx *context.Context) types.Type {
 		return l.lintPenaltyboxDeclaration(t)
 	case *ast.RatecounterDeclaration:
 		return l.lintRatecounterDeclaration(t)
+	case *ast.GenericProperty:
+		return l.lintGenericProperty(t)
 
 	// Statements
 	case *ast.BlockStatement:
 		return l.lintBlockStatement(t, ctx)
 	case *ast.ImportStatement:
 		return l.lintImportStatement(t, ctx)
+	case *ast.NewStatement:
+		return l.lintNewStatement(t, ctx)
 	case *ast.IncludeStatement:
 		return l.lintIncludeStatement(t, ctx)
 	case *ast.DeclareStatement:
@@ -268,6 +343,9 @@ func (l *Linter) lintVCL(vcl *ast.VCL, ctx *context.Context) types.Type {
 	statements := l.resolveIncludeStatements(vcl.Statements, ctx, true)
 
 	// https://github.com/ysugimoto/falco/issues/50
+	// Subroutines which do not declare its scope are linted in the scopes of its callers
+	l.callScopes = propagateCallScopes(statements, ctx)
+
 	// To support subroutine hoisting, add root statements to context firstly and lint each statements after that.
 	statements = l.factoryRootDeclarations(statements, ctx)
 
@@ -286,10 +364,10 @@ func (l *Linter) lintStatement(s ast.Statement, ctx *context.Context) {
 	l.lint(s, ctx)
 }
 
-func (l *Linter) loadSnippetVCL(file, content string) []ast.Statement {
+func (l *Linter) loadSnippetVCL(file, content string, ctx *context.Context) []ast.Statement {
 	lx := lexer.NewFromString(content, lexer.WithFile(file))
 	l.includexLexers[file] = lx
-	statements, err := parser.New(lx).ParseSnippetVCL()
+	statements, err := parser.New(lx, ctx.ParserOptions()...).ParseSnippetVCL()
 	if err != nil {
 		lx.NewLine()
 		l.FatalError = &FatalError{
@@ -301,10 +379,10 @@ func (l *Linter) loadSnippetVCL(file, content string) []ast.Statement {
 	return statements
 }
 
-func (l *Linter) loadVCL(file, content string) []ast.Statement {
+func (l *Linter) loadVCL(file, content string, ctx *context.Context) []ast.Statement {
 	lx := lexer.NewFromString(content, lexer.WithFile(file))
 	l.includexLexers[file] = lx
-	vcl, err := parser.New(lx).ParseVCL()
+	vcl, err := parser.New(lx, ctx.ParserOptions()...).ParseVCL()
 	if err != nil {
 		lx.NewLine()
 		l.FatalError = &FatalError{
@@ -360,11 +438,12 @@ func (l *Linter) resolveSnippetInclusion(
 		return statements
 	}
 
+	l.sourceMap.Add(include.Module.Value, include)
 	// snippet could not have nested include statement
 	if isRoot {
-		return l.loadVCL(include.Module.Value, snip.Data)
+		return l.loadVCL(include.Module.Value, snip.Data, ctx)
 	}
-	return l.loadSnippetVCL(include.Module.Value, snip.Data)
+	return l.loadSnippetVCL(include.Module.Value, snip.Data, ctx)
 }
 
 // Module (file) inclusion
@@ -386,10 +465,11 @@ func (l *Linter) resolveFileInclusion(
 		return statements
 	}
 
+	l.sourceMap.Add(module.Name, include)
 	if isRoot {
-		statements = l.loadVCL(module.Name, module.Data)
+		statements = l.loadVCL(module.Name, module.Data, ctx)
 	} else {
-		statements = l.loadSnippetVCL(module.Name, module.Data)
+		statements = l.loadSnippetVCL(module.Name, module.Data, ctx)
 	}
 	return l.resolveIncludeStatements(statements, ctx, isRoot)
 }
@@ -422,6 +502,9 @@ func (l *Linter) factoryRootDeclarations(statements []ast.Statement, ctx *contex
 		case *ast.ImportStatement:
 			// @ysugimoto skipped. import statement no longer used?
 			continue
+		case *ast.VersionStatement:
+			// Varnish VCL version declaration, nothing to lint
+			continue
 		case *ast.DirectorDeclaration:
 			if err := ctx.AddDirector(t.Name.Value, &types.Director{Decl: t}); err != nil {
 				e := &LintError{
@@ -483,13 +566,15 @@ func (l *Linter) factoryRootDeclarations(statements []ast.Statement, ctx *contex
 					l.Error(err.Match(SUBROUTINE_INVALID_RETURN_TYPE))
 				}
 
-				if err := ctx.AddUserDefinedFunction(t.Name.Value, getSubroutineCallScope(t), returnType); err != nil {
+				if err := ctx.AddUserDefinedFunction(t.Name.Value, l.subroutineScope(t, ctx), returnType); err != nil {
 					err := &LintError{
 						Severity: ERROR,
 						Token:    t.Name.GetMeta().Token,
 						Message:  err.Error(),
 					}
 					l.Error(err.Match(SUBROUTINE_DUPLICATED))
+				} else {
+					ctx.Functions[t.Name.Value] = &types.Subroutine{Decl: t, Body: t.Block}
 				}
 			} else {
 				err := ctx.AddSubroutine(t.Name.Value, &types.Subroutine{Decl: t, Body: t.Block})
@@ -535,6 +620,10 @@ func (l *Linter) lintAclDeclaration(decl *ast.AclDeclaration) types.Type {
 	if !isValidName(decl.Name.Value) {
 		l.Error(InvalidName(decl.Name.GetMeta(), decl.Name.Value, "acl").Match(ACL_SYNTAX))
 	}
+	l.lintNaming(namingAcl, decl.Name)
+
+	l.lintAclEntryLimit(decl)
+	l.lintAclOverlap(decl)
 
 	// CIDRs validity
 	for _, cidr := range decl.CIDRs {
@@ -563,6 +652,7 @@ func (l *Linter) lintBackendDeclaration(decl *ast.BackendDeclaration, ctx *conte
 	if !isValidName(decl.Name.Value) {
 		l.Error(InvalidName(decl.Name.GetMeta(), decl.Name.Value, "backend").Match(BACKEND_SYNTAX))
 	}
+	l.lintNaming(namingBackend, decl.Name)
 
 	// lint property definitions
 	for i := range decl.Properties {
@@ -606,6 +696,7 @@ func (l *Linter) lintBackendProperty(prop *ast.BackendProperty, ctx *context.Con
 			}
 			l.Error(err.Match(BACKEND_PROBER_CONFIGURATION))
 		}
+		l.lintBackendProbe(t)
 
 	default:
 		// Otherwise, simply compare key type
@@ -626,6 +717,15 @@ func (l *Linter) lintImportStatement(stmt *ast.ImportStatement, ctx *context.Con
 	return types.NeverType
 }
 
+// VMOD objects are not known, so only validate the object name and register it as an identifier
+func (l *Linter) lintNewStatement(stmt *ast.NewStatement, ctx *context.Context) types.Type {
+	if !isValidName(stmt.Name.Value) {
+		l.Error(InvalidName(stmt.Name.GetMeta(), stmt.Name.Value, "new").Match(NEW_STATEMENT_SYNTAX))
+	}
+	ctx.Identifiers[stmt.Name.Value] = struct{}{}
+	return types.NeverType
+}
+
 func (l *Linter) lintIncludeStatement(stmt *ast.IncludeStatement, ctx *context.Context) types.Type {
 	// On linter, dendent module may not parse and lint.
 	// These should be parsed and linted on other process.
@@ -637,8 +737,10 @@ func (l *Linter) lintDirectorDeclaration(decl *ast.DirectorDeclaration, ctx *con
 	if !isValidName(decl.Name.Value) {
 		l.Error(InvalidName(decl.Name.GetMeta(), decl.Name.Value, "director").Match(DIRECTOR_SYNTAX))
 	}
+	l.lintNaming(namingDirector, decl.Name)
 
 	l.lintDirectorProperty(decl, ctx)
+	l.lintDirectorSanity(decl, ctx)
 
 	return types.NeverType
 }
@@ -678,14 +780,8 @@ func (l *Linter) lintDirectorProperty(decl *ast.DirectorDeclaration, ctx *contex
 						l.Error(InvalidType(v.Key.GetMeta(), v.Key.Value, vv, types.IDType).Match(dps.Rule))
 						continue
 					}
-					if _, ok := ctx.Backends[ident.Value]; !ok {
-						err := &LintError{
-							Severity: ERROR,
-							Token:    v.Token,
-							Message:  fmt.Sprintf("Backend %s is not declared", ident.Value),
-						}
-						l.Error(err.Match(BACKEND_NOTFOUND))
-					}
+					l.lintReference(ident, referenceBackend, ctx)
+					l.lintBannedBackend(ident)
 				} else {
 					val := l.lint(v.Value, ctx)
 					if vv != val {
@@ -720,6 +816,11 @@ func (l *Linter) lintDirectorProperty(decl *ast.DirectorDeclaration, ctx *contex
 				).Match(dps.Rule))
 				continue
 			}
+			// chash key accepts special identifiers, object or client
+			if t.Key.Value == "key" {
+				l.lintDirectorHashKey(t, dps.Rule)
+				continue
+			}
 			val := l.lint(t.Value, ctx)
 			if vv != val {
 				l.Error(InvalidType(t.Value.GetMeta(), t.Key.Value, vv, val).Match(dps.Rule))
@@ -743,15 +844,16 @@ func (l *Linter) lintTableDeclaration(decl *ast.TableDeclaration, ctx *context.C
 	if !isValidName(decl.Name.Value) {
 		l.Error(InvalidName(decl.Name.GetMeta(), decl.Name.Value, "table").Match(TABLE_SYNTAX))
 	}
+	l.lintNaming(namingTable, decl.Name)
 
 	// Table item is limited under 1000 by default
 	// https://developer.fastly.com/reference/vcl/declarations/table/#limitations
-	// But user can increase limitation by contacting to support.
-	if len(decl.Properties) > 1000 {
+	// But user can increase limitation by contacting to support, so the limit is configurable by "max_items" option.
+	if maxItems := l.intRuleOption(TABLE_ITEM_LIMITATION, "max_items", 1000); len(decl.Properties) > maxItems {
 		err := &LintError{
 			Severity: WARNING,
 			Token:    decl.Name.GetMeta().Token,
-			Message:  fmt.Sprintf(`Table "%s" items are limited to 1000`, decl.Name.Value),
+			Message:  fmt.Sprintf(`Table "%s" items are limited to %d`, decl.Name.Value, maxItems),
 		}
 		l.Error(err.Match(TABLE_ITEM_LIMITATION))
 	}
@@ -765,7 +867,12 @@ func (l *Linter) lintTableDeclaration(decl *ast.TableDeclaration, ctx *context.C
 	}
 
 	// validate table property
+	keys := make(map[string]struct{})
 	for _, p := range decl.Properties {
+		if _, ok := keys[p.Key.Value]; ok {
+			l.Error(Duplicated(p.Key.GetMeta(), p.Key.Value, "table key").Match(TABLE_DUPLICATED_KEY))
+		}
+		keys[p.Key.Value] = struct{}{}
 		l.lintTableProperty(p, valueType, ctx)
 	}
 
@@ -780,10 +887,8 @@ func (l *Linter) lintTableProperty(prop *ast.TableProperty, tableType types.Type
 			l.Error(InvalidTypeConversion(prop.Value.GetMeta(), "ID").Match(TABLE_SYNTAX))
 			return
 		}
-		if a, ok := ctx.Acls[ident.Value]; !ok {
-			l.Error(UndefinedAcl(ident.GetMeta(), ident.Value))
-		} else {
-			a.IsUsed = true
+		if l.lintReference(ident, referenceAcl, ctx) {
+			ctx.Acls[ident.Value].IsUsed = true
 		}
 	case types.BackendType:
 		ident, ok := prop.Value.(*ast.Ident)
@@ -791,15 +896,13 @@ func (l *Linter) lintTableProperty(prop *ast.TableProperty, tableType types.Type
 			l.Error(InvalidTypeConversion(prop.Value.GetMeta(), "ID").Match(TABLE_SYNTAX))
 			return
 		}
-		if b, ok := ctx.Backends[ident.Value]; !ok {
-			l.Error(UndefinedBackend(ident.GetMeta(), ident.Value))
-		} else {
-			b.IsUsed = true
+		if l.lintReference(ident, referenceBackend, ctx) {
+			ctx.Backends[ident.Value].IsUsed = true
 		}
 	default:
 		vt := l.lint(prop.Value, ctx)
 		if vt != tableType {
-			l.Error(InvalidType(prop.Value.GetMeta(), prop.Key.Value, tableType, vt))
+			l.Error(InvalidType(prop.Value.GetMeta(), prop.Key.Value, tableType, vt).Match(TABLE_TYPE_VARIATION))
 		}
 	}
 }
@@ -809,8 +912,9 @@ func (l *Linter) lintSubRoutineDeclaration(decl *ast.SubroutineDeclaration, ctx
 	if !isValidName(decl.Name.Value) {
 		l.Error(InvalidName(decl.Name.GetMeta(), decl.Name.Value, "sub").Match(SUBROUTINE_SYNTAX))
 	}
+	l.lintNaming(namingSubroutine, decl.Name)
 
-	scope := getSubroutineCallScope(decl)
+	scope := l.subroutineScope(decl, ctx)
 	var cc *context.Context
 	if decl.ReturnType != nil {
 		returnType := ValueTypeMap[decl.ReturnType.Value]
@@ -823,14 +927,43 @@ func (l *Linter) lintSubRoutineDeclaration(decl *ast.SubroutineDeclaration, ctx
 	defer func() {
 		// Lint declared variables are used
 		l.lintUnusedVariables(ctx)
+		// Lint values of local variables are read
+		l.lintUnusedLocalAssignments(decl)
+		// Lint control flows which loop
+		l.lintRestartLoop(decl)
+		l.lintGotoLoop(decl)
+		l.lintDangerousGotos(decl)
+		// Lint local variables which are expected to be block scoped
+		l.lintBlockScopedDeclarations(decl)
+		// Lint ESI processing configurations
+		l.lintEsi(decl)
+		// Lint synthetic responses are delivered
+		l.lintSyntheticResponses(decl)
+		l.lintTaintedOutputs(decl, ctx)
+		// Lint cache TTL is decided on all paths of vcl_fetch
+		l.lintImplicitTTL(decl, ctx)
+		// Lint header names which look like typos
+		l.lintHeaderTypos(decl)
+		// Lint denylisted identifiers in the configuration
+		l.lintBannedIdentifiers(decl)
+		// Lint deprecated variables and functions
+		l.lintDeprecations(decl)
+		// Lint subroutine complexity
+		l.lintComplexity(decl)
+		// Lint suspicious durations
+		l.lintRTimeSanity(decl)
 		cc.Restore()
 	}()
 
 	// If fastly reserved subroutine name (e.g vcl_recv, vcl_fetch, etc),
 	// validate fastly specific boilerplate macro is embedded like "FASTLY recv"
-	if scope := getFastlySubroutineScope(decl.Name.Value); scope != "" {
+	// Varnish VCL does not have the macro
+	if scope := getFastlySubroutineScope(decl.Name.Value); scope != "" && ctx.Dialect() != parser.DialectVarnish4 {
 		l.lintFastlyBoilerPlateMacro(decl, ctx, scope)
 	}
+	// Lint cache key contains the URL and the host.
+	// Lint before the block in order to be suppressed by ignore comment of the declaration
+	l.lintHashMissingKey(decl)
 
 	// Store current subroutine in order to be able to access via statements inside
 	ctx.CurrentSubroutine = decl
@@ -841,6 +974,11 @@ func (l *Linter) lintSubRoutineDeclaration(decl *ast.SubroutineDeclaration, ctx
 
 	l.lint(decl.Block, cc)
 
+	// Functional subroutine must return a value on all control-flow paths
+	if decl.ReturnType != nil && !alwaysReturns(decl.Block) {
+		l.Error(MissingReturn(decl.Name.GetMeta(), decl.Name.Value).Match(SUBROUTINE_MISSING_RETURN))
+	}
+
 	// We are done linting inside the previous scope so
 	// we dont need the return type anymore
 	cc.ReturnType = nil
@@ -888,7 +1026,7 @@ func (l *Linter) lintPenaltyboxDeclaration(decl *ast.PenaltyboxDeclaration) type
 		l.Error(InvalidName(decl.Name.GetMeta(), decl.Name.Value, "penaltybox").Match(PENALTYBOX_SYNTAX))
 	}
 
-	if len(decl.Block.Statements) > 0 {
+	if l.lintGenericProperties(decl.Block) > 0 {
 		l.Error(NonEmptyPenaltyboxBlock(decl.GetMeta(), decl.Name.Value).Match(PENALTYBOX_NONEMPTY_BLOCK))
 	}
 
@@ -901,13 +1039,32 @@ func (l *Linter) lintRatecounterDeclaration(decl *ast.RatecounterDeclaration) ty
 		l.Error(InvalidName(decl.Name.GetMeta(), decl.Name.Value, "ratecounter").Match(RATECOUNTER_SYNTAX))
 	}
 
-	if len(decl.Block.Statements) > 0 {
+	if l.lintGenericProperties(decl.Block) > 0 {
 		l.Error(NonEmptyRatecounterBlock(decl.GetMeta(), decl.Name.Value).Match(RATECOUNTER_NONEMPTY_BLOCK))
 	}
 
 	return types.NeverType
 }
 
+// Unknown property is parsed in tolerant mode, falco could not validate it
+func (l *Linter) lintGenericProperty(prop *ast.GenericProperty) types.Type {
+	l.Error(UnknownProperty(prop.Key.GetMeta(), prop.Key.Value).Match(DECLARATION_UNKNOWN_PROPERTY))
+	return types.NeverType
+}
+
+// lintGenericProperties lints unknown properties in declaration block and returns count of other statements
+func (l *Linter) lintGenericProperties(block *ast.BlockStatement) int {
+	var count int
+	for _, stmt := range block.Statements {
+		if prop, ok := stmt.(*ast.GenericProperty); ok {
+			l.lintGenericProperty(prop)
+			continue
+		}
+		count++
+	}
+	return count
+}
+
 func (l *Linter) lintFastlyBoilerPlateMacro(sub *ast.SubroutineDeclaration, ctx *context.Context, scope string) {
 	phrase := strings.ToUpper("FASTLY " + scope)
 
@@ -921,7 +1078,7 @@ func (l *Linter) lintFastlyBoilerPlateMacro(sub *ast.SubroutineDeclaration, ctx
 	// visit all statement comments and find "FASTLY [phase]" comment
 	if hasFastlyBoilerPlateMacro(sub.Block.InfixComment(), phrase) {
 		for _, s := range scopedSnippets {
-			resolved = append(resolved, l.loadSnippetVCL("snippet::"+s.Name, s.Data)...)
+			resolved = append(resolved, l.loadSnippetVCL("snippet::"+s.Name, s.Data, ctx)...)
 		}
 		sub.Block.Statements = append(resolved, sub.Block.Statements...)
 		return
@@ -932,7 +1089,7 @@ func (l *Linter) lintFastlyBoilerPlateMacro(sub *ast.SubroutineDeclaration, ctx
 		if hasFastlyBoilerPlateMacro(stmt.LeadingComment(), phrase) && !found {
 			// Macro found but embedding snippets should do only once
 			for _, s := range scopedSnippets {
-				resolved = append(resolved, l.loadSnippetVCL("snippet::"+s.Name, s.Data)...)
+				resolved = append(resolved, l.loadSnippetVCL("snippet::"+s.Name, s.Data, ctx)...)
 			}
 			found = true
 		}
@@ -953,6 +1110,12 @@ func (l *Linter) lintFastlyBoilerPlateMacro(sub *ast.SubroutineDeclaration, ctx
 			`Subroutine "%s" is missing Fastly boilerplate comment "%s" inside definition`, sub.Name.Value, phrase,
 		),
 	}
+	err.Fix = &Fix{
+		Description: `insert "#` + phrase + `" comment`,
+		Insert: []*Insertion{
+			{Token: sub.Block.GetMeta().Token, Text: "#" + phrase},
+		},
+	}
 	l.Error(err.Match(SUBROUTINE_BOILERPLATE_MACRO))
 }
 
@@ -961,10 +1124,14 @@ func (l *Linter) lintBlockStatement(block *ast.BlockStatement, ctx *context.Cont
 	defer l.ignore.TeardownBlockStatement(block.GetMeta())
 
 	statements := l.resolveIncludeStatements(block.Statements, ctx, false)
+	unreachable := unreachableStatements(statements)
 	for _, stmt := range statements {
 		func(v ast.Statement, c *context.Context) {
 			l.ignore.SetupStatement(v.GetMeta())
 			defer l.ignore.TeardownStatement()
+			if _, ok := unreachable[v]; ok {
+				l.Error(UnreachableCode(v.GetMeta()).Match(UNREACHABLE_CODE))
+			}
 			l.lint(v, c)
 		}(stmt, ctx)
 	}
@@ -977,6 +1144,7 @@ func (l *Linter) lintDeclareStatement(stmt *ast.DeclareStatement, ctx *context.C
 	if !isValidVariableName(stmt.Name.Value) {
 		l.Error(InvalidName(stmt.Name.GetMeta(), stmt.Name.Value, "declare local").Match(DECLARE_STATEMENT_SYNTAX))
 	}
+	l.lintNaming(namingVariable, stmt.Name)
 	// user defined variable must start with "var."
 	if !strings.HasPrefix(stmt.Name.Value, "var.") {
 		err := &LintError{
@@ -987,17 +1155,23 @@ func (l *Linter) lintDeclareStatement(stmt *ast.DeclareStatement, ctx *context.C
 		l.Error(err.Match(DECLARE_STATEMENT_SYNTAX))
 	}
 
-	vt, ok := ValueTypeMap[stmt.ValueType.Value]
-	if !ok {
+	// Parser keeps unknown type with its raw type name like "STRING[]" instead of aborting
+	if u := stmt.UnknownType; u != nil {
 		err := &LintError{
 			Severity: ERROR,
-			Token:    stmt.ValueType.GetMeta().Token,
-			Message:  fmt.Sprintf("Unexpected variable type found: %s", stmt.ValueType.Value),
+			Token:    u.GetMeta().Token,
+			Message:  fmt.Sprintf("Unexpected variable type found: %s", u.Name),
 		}
 		l.Error(err.Match(DECLARE_STATEMENT_INVALID_TYPE))
 	}
+	vt := ValueTypeMap[stmt.ValueType.Value]
 
 	if err := ctx.Declare(stmt.Name.Value, vt, stmt.GetMeta()); err != nil {
+		// Declaration in nested block is expected to shadow the outer one but it is duplicated
+		if outer := outerDeclaration(stmt, ctx.CurrentSubroutine); outer != nil {
+			l.Error(ShadowedDeclaration(stmt.Name.GetMeta(), stmt.Name.Value, outer.GetMeta().Token.Line).Match(DECLARE_STATEMENT_SHADOWED))
+			return types.NeverType
+		}
 		err := &LintError{
 			Severity: ERROR,
 			Token:    stmt.Name.GetMeta().Token,
@@ -1020,12 +1194,20 @@ func (l *Linter) lintSetStatement(stmt *ast.SetStatement, ctx *context.Context)
 
 	left, err := ctx.Set(stmt.Ident.Value)
 	if err != nil {
-		err := &LintError{
-			Severity: ERROR,
-			Token:    stmt.Ident.GetMeta().Token,
-			Message:  err.Error(),
+		// Variables are defined for Fastly so Varnish variable has unknown type
+		if isVarnishDialect(ctx) {
+			left = types.NeverType
+		} else {
+			err := &LintError{
+				Severity: ERROR,
+				Token:    stmt.Ident.GetMeta().Token,
+				Message:  err.Error(),
+			}
+			l.Error(err)
 		}
-		l.Error(err)
+	}
+	if err == nil {
+		l.lintHashOutsideVclHash(stmt, ctx)
 	}
 
 	if err := isValidStatementExpression(stmt.Value); err != nil {
@@ -1037,6 +1219,11 @@ func (l *Linter) lintSetStatement(stmt *ast.SetStatement, ctx *context.Context)
 		l.Error(err.Match(OPERATOR_ASSIGNMENT))
 	}
 
+	// Backend must be declared in any included files or snippets
+	if kind, ok := referenceKinds[left]; ok && !l.lintReference(stmt.Value, kind, ctx) {
+		return types.NeverType
+	}
+
 	right := l.lint(stmt.Value, ctx)
 
 	// Fastly has various assignment operators and required correspond types for each operator
@@ -1045,18 +1232,22 @@ func (l *Linter) lintSetStatement(stmt *ast.SetStatement, ctx *context.Context)
 	// Above document is not enough to explain for other types... actually more complex type comparison may occur.
 	// We investigated type comparison and summarized.
 	// See: https://docs.google.com/spreadsheets/d/16xRPugw9ubKA1nXHIc5ysVZKokLLhysI-jAu3qbOFJ8/edit#gid=0
-	switch stmt.Operator.Operator {
-	case "+=", "-=":
-		l.lintAddSubOperator(stmt.Operator, left, right, isLiteralExpression(stmt.Value))
-	case "*=", "/=", "%=":
-		l.lintArithmeticOperator(stmt.Operator, left, right, isLiteralExpression(stmt.Value))
-	case "|=", "&=", "^=", "<<=", ">>=", "rol=", "ror=":
-		l.lintBitwiseOperator(stmt.Operator, left, right)
-	case "||=", "&&=":
-		l.lintLogicalOperator(stmt.Operator, left, right)
-	default: // "="
-		l.lintAssignOperator(stmt.Operator, stmt.Ident.Value, left, right, isLiteralExpression(stmt.Value))
-	}
+	// Unknown type like template placeholder is not checked
+	if left != types.NeverType && right != types.NeverType {
+		switch stmt.Operator.Operator {
+		case "+=", "-=":
+			l.lintAddSubOperator(stmt.Operator, left, right, isLiteralExpression(stmt.Value))
+		case "*=", "/=", "%=":
+			l.lintArithmeticOperator(stmt.Operator, left, right, isLiteralExpression(stmt.Value))
+		case "|=", "&=", "^=", "<<=", ">>=", "rol=", "ror=":
+			l.lintBitwiseOperator(stmt.Operator, left, right)
+		case "||=", "&&=":
+			l.lintLogicalOperator(stmt.Operator, left, right)
+		default: // "="
+			l.lintAssignOperator(stmt.Operator, stmt.Ident.Value, left, right, isLiteralExpression(stmt.Value))
+		}
+	}
+	l.lintAssignmentLimits(stmt.Ident.Value, stmt.Value)
 
 	return types.NeverType
 }
@@ -1071,7 +1262,7 @@ func (l *Linter) lintUnsetStatement(stmt *ast.UnsetStatement, ctx *context.Conte
 		l.Error(ProtectedHTTPHeader(stmt.Ident.GetMeta(), stmt.Ident.Value))
 	}
 
-	if err := ctx.Unset(stmt.Ident.Value); err != nil {
+	if err := ctx.Unset(stmt.Ident.Value); err != nil && !isVarnishDialect(ctx) {
 		l.Error(&LintError{
 			Severity: ERROR,
 			Token:    stmt.Ident.GetMeta().Token,
@@ -1092,7 +1283,7 @@ func (l *Linter) lintRemoveStatement(stmt *ast.RemoveStatement, ctx *context.Con
 		l.Error(ProtectedHTTPHeader(stmt.Ident.GetMeta(), stmt.Ident.Value))
 	}
 
-	if err := ctx.Unset(stmt.Ident.Value); err != nil {
+	if err := ctx.Unset(stmt.Ident.Value); err != nil && !isVarnishDialect(ctx) {
 		l.Error(&LintError{
 			Severity: ERROR,
 			Token:    stmt.Ident.GetMeta().Token,
@@ -1105,6 +1296,8 @@ func (l *Linter) lintRemoveStatement(stmt *ast.RemoveStatement, ctx *context.Con
 
 func (l *Linter) lintIfStatement(stmt *ast.IfStatement, ctx *context.Context) types.Type {
 	l.lintIfCondition(stmt.Condition, ctx)
+	l.lintUnreachableBranches(stmt)
+	l.lintDuplicateBranches(stmt)
 
 	// push regex captured variables
 	if err := pushRegexGroupVars(stmt.Condition, ctx); err != nil {
@@ -1137,6 +1330,56 @@ func (l *Linter) lintIfStatement(stmt *ast.IfStatement, ctx *context.Context) ty
 	return types.NeverType
 }
 
+// Fastly condition is evaluated in the subroutine corresponding to its type
+var conditionScopes = map[string]int{
+	ast.RequestCondition:  context.RECV,
+	ast.CacheCondition:    context.FETCH,
+	ast.ResponseCondition: context.DELIVER,
+	ast.PrefetchCondition: context.MISS,
+}
+
+// lintRemoteConditions parses and lints Fastly conditions which are fetched from API or terraform,
+// and returns parsed conditions
+func (l *Linter) lintRemoteConditions(ctx *context.Context) []*ast.Condition {
+	var conditions []*ast.Condition
+	for _, c := range ctx.Snippets().Conditions {
+		file := "condition::" + c.Name
+		lx := lexer.NewFromString(c.Data, lexer.WithFile(file))
+		l.includexLexers[file] = lx
+		cond, err := parser.New(lx, ctx.ParserOptions()...).ParseCondition(c.Type)
+		if err != nil {
+			lx.NewLine()
+			l.FatalError = &FatalError{
+				Lexer: lx,
+				Error: errors.Cause(err),
+			}
+			return conditions
+		}
+		l.lint(cond, ctx)
+		conditions = append(conditions, cond)
+	}
+	return conditions
+}
+
+func (l *Linter) lintCondition(cond *ast.Condition, ctx *context.Context) types.Type {
+	mode, ok := conditionScopes[cond.Type]
+	if !ok {
+		err := &LintError{
+			Severity: ERROR,
+			Token:    cond.GetMeta().Token,
+			Message:  fmt.Sprintf("Unexpected condition type: %s", cond.Type),
+		}
+		l.Error(err.Match(CONDITION_TYPE))
+		return types.NeverType
+	}
+
+	ctx.Scope(mode)
+	l.lintIfCondition(cond.Expression, ctx)
+	ctx.Restore()
+
+	return types.BoolType
+}
+
 func (l *Linter) lintIfCondition(cond ast.Expression, ctx *context.Context) {
 	// Note: if condtion expression accepts STRING or BOOL (evaluate as truthy/falsy), but forbid to use literal.
 	//
@@ -1153,10 +1396,13 @@ func (l *Linter) lintIfCondition(cond ast.Expression, ctx *context.Context) {
 		}
 		l.Error(err.Match(CONDITION_LITERAL))
 	}
+	l.lintConstantCondition(cond)
+	l.lintCaseSensitivity(cond)
+	l.lintOperatorConfusion(cond)
 
 	cc := l.lint(cond, ctx)
-	// Condition expression return type must be BOOL or STRING
-	if !expectType(cc, types.StringType, types.BoolType) {
+	// Condition expression return type must be BOOL or STRING, unknown type like template placeholder is not checked
+	if cc != types.NeverType && !expectType(cc, types.StringType, types.BoolType) {
 		l.Error(&LintError{
 			Severity: ERROR,
 			Token:    cond.GetMeta().Token,
@@ -1212,11 +1458,16 @@ func (l *Linter) lintAddStatement(stmt *ast.AddStatement, ctx *context.Context)
 
 	left, err := ctx.Get(stmt.Ident.Value)
 	if err != nil {
-		l.Error(&LintError{
-			Severity: ERROR,
-			Token:    stmt.Ident.GetMeta().Token,
-			Message:  err.Error(),
-		})
+		// Variables are defined for Fastly so Varnish variable has unknown type
+		if isVarnishDialect(ctx) {
+			left = types.NeverType
+		} else {
+			l.Error(&LintError{
+				Severity: ERROR,
+				Token:    stmt.Ident.GetMeta().Token,
+				Message:  err.Error(),
+			})
+		}
 	}
 
 	if err := isValidStatementExpression(stmt.Value); err != nil {
@@ -1239,7 +1490,10 @@ func (l *Linter) lintAddStatement(stmt *ast.AddStatement, ctx *context.Context)
 		}
 		l.Error(err.Match(OPERATOR_ASSIGNMENT))
 	}
-	l.lintAssignOperator(stmt.Operator, stmt.Ident.Value, left, right, isLiteralExpression(stmt.Value))
+	if left != types.NeverType && right != types.NeverType {
+		l.lintAssignOperator(stmt.Operator, stmt.Ident.Value, left, right, isLiteralExpression(stmt.Value))
+	}
+	l.lintAssignmentLimits(stmt.Ident.Value, stmt.Value)
 
 	return types.NeverType
 }
@@ -1356,6 +1610,11 @@ func (l *Linter) lintReturnStatement(stmt *ast.ReturnStatement, ctx *context.Con
 		return types.NeverType
 	}
 
+	if isVarnishDialect(ctx) {
+		l.lintVarnishReturnStatement(stmt, ctx)
+		return types.NeverType
+	}
+
 	// legal return actions are different in subroutine.
 	// https://developer.fastly.com/learning/vcl/using/#the-vcl-request-lifecycle
 	expects := make([]string, 0, 3)
@@ -1363,7 +1622,9 @@ func (l *Linter) lintReturnStatement(stmt *ast.ReturnStatement, ctx *context.Con
 	switch ctx.Mode() {
 	case context.RECV:
 		// https://developer.fastly.com/reference/vcl/subroutines/recv/
-		expects = append(expects, "lookup", "pass", "error", "restart")
+		// return(upgrade) passes through WebSocket connection to the backend
+		// https://developer.fastly.com/learning/concepts/real-time-messaging/websockets-tunnel/
+		expects = append(expects, "lookup", "pass", "error", "restart", "upgrade")
 	case context.HASH:
 		// https://developer.fastly.com/reference/vcl/subroutines/hash/
 		expects = append(expects, "hash")
@@ -1423,10 +1684,15 @@ func (l *Linter) lintSyntheticStatement(stmt *ast.SyntheticStatement, ctx *conte
 	}
 
 	l.lint(stmt.Value, ctx)
+	l.lintSyntheticLimit(stmt.Value, false)
 	return types.NeverType
 }
 
 func (l *Linter) lintIdent(exp *ast.Ident, ctx *context.Context) types.Type {
+	// Template placeholder does not have the type until the template is rendered
+	if exp.GetMeta().Token.Placeholder {
+		return types.NeverType
+	}
 	v, err := ctx.Get(exp.Value)
 	if err != nil {
 		if b, ok := ctx.Backends[exp.Value]; ok {
@@ -1459,6 +1725,17 @@ func (l *Linter) lintIdent(exp *ast.Ident, ctx *context.Context) types.Type {
 			return types.IDType
 		}
 
+		// Identifier which does not have the dot may be a reference to the declaration
+		if _, ok := isDeclarationIdent(exp); ok {
+			l.Error(UndefinedReference(exp.GetMeta(), "", exp.Value, referenceCandidates(ctx, exp.Value)).Match(REFERENCE_UNDEFINED))
+			return types.NeverType
+		}
+
+		// Variables are defined for Fastly so Varnish variable has unknown type
+		if isVarnishDialect(ctx) {
+			return types.NeverType
+		}
+
 		// Convert to lint error
 		l.Error(&LintError{
 			Severity: ERROR,
@@ -1482,6 +1759,7 @@ func (l *Linter) lintSyntheticBase64Statement(stmt *ast.SyntheticBase64Statement
 
 	// TODO: check decodable string
 	l.lint(stmt.Value, ctx)
+	l.lintSyntheticLimit(stmt.Value, true)
 
 	return types.NeverType
 }
@@ -1508,6 +1786,12 @@ func (l *Linter) lintInteger(exp *ast.Integer) types.Type {
 }
 
 func (l *Linter) lintString(exp *ast.String) types.Type {
+	// Only double-quoted string has escape sequences
+	if exp.Token.Offset == 2 {
+		if _, err := parser.DecodeString(exp.RawValue()); err != nil {
+			l.Error(InvalidEscape(exp.GetMeta(), err).Match(STRING_INVALID_ESCAPE))
+		}
+	}
 	return types.StringType
 }
 
@@ -1527,7 +1811,10 @@ func (l *Linter) lintPrefixExpression(exp *ast.PrefixExpression, ctx *context.Co
 	switch exp.Operator {
 	case "!":
 		// The bang operator case is pre-checked in isValidConditionExpression and isValidStatmentExpression
-		return right
+		if !expectType(right, logicalOperandTypes...) {
+			l.Error(InvalidTypeExpression(exp.GetMeta(), right, logicalOperandTypes...).Match(OPERATOR_CONDITIONAL))
+		}
+		return types.BoolType
 	case "-":
 		if !expectType(right, types.IntegerType, types.FloatType, types.RTimeType) {
 			l.Error(InvalidTypeExpression(
@@ -1558,103 +1845,31 @@ func (l *Linter) lintInfixExpression(exp *ast.InfixExpression, ctx *context.Cont
 	if left == types.NeverType {
 		return left
 	}
+	// Identifier in the right of match operator must be ACL
+	if (exp.Operator == "~" || exp.Operator == "!~") && !l.lintReference(exp.Right, referenceAcl, ctx) {
+		return types.BoolType
+	}
 	right := l.lint(exp.Right, ctx)
 	if right == types.NeverType {
 		return right
 	}
 
 	switch exp.Operator {
-	case "==", "!=":
-		// Cast req.backend to standard backend type for comparisons
-		// Fiddle demonstrating these comparisons are valid:
-		// https://fiddle.fastly.dev/fiddle/06865e2d
-		if left == types.ReqBackendType {
-			left = types.BackendType
-		}
-		if right == types.ReqBackendType {
-			right = types.BackendType
-		}
-		// Equal operator could compare any types but both left and right type must be the same.
-		if left != right {
-			l.Error(InvalidTypeComparison(exp.GetMeta(), left, right).Match(OPERATOR_CONDITIONAL))
-		}
+	// Assignment operator in condition is reported as mistake of "==", type check as equal operator
+	case "==", "!=", "=":
+		l.lintEqualityOperator(exp, left, right)
 		return types.BoolType
 	case ">", ">=", "<", "<=":
-		// Greater/Less than operator only could compare with INTEGER, FLOAT, or RTIME type
-		switch left {
-		case types.IntegerType:
-			// When left type is INTEGER, right type must be INTEGER or RTIME
-			if !expectType(right, types.IntegerType, types.RTimeType) {
-				l.Error(InvalidTypeExpression(exp.GetMeta(), right, types.IntegerType, types.RTimeType).Match(OPERATOR_CONDITIONAL))
-			}
-		case types.FloatType, types.RTimeType:
-			// When left type is FLOAT or RTIME, right type must be INTEGER or FLOAT or RTIME
-			if !expectType(right, types.IntegerType, types.FloatType, types.RTimeType) {
-				l.Error(InvalidTypeExpression(exp.GetMeta(), right, types.IntegerType, types.FloatType, types.RTimeType).Match(OPERATOR_CONDITIONAL))
-			}
-		default:
-			l.Error(InvalidTypeExpression(exp.GetMeta(), left, types.IntegerType, types.FloatType, types.RTimeType).Match(OPERATOR_CONDITIONAL))
-		}
+		l.lintRelationalOperator(exp, left, right)
 		return types.BoolType
 	case "~", "!~":
-		// Regex operator could compare only STRING,  IP or ACL type
-		if !expectType(left, types.StringType, types.IPType, types.AclType) {
-			l.Error(InvalidTypeExpression(exp.GetMeta(), left, types.StringType, types.IPType, types.AclType).Match(OPERATOR_CONDITIONAL))
-		} else if !expectType(right, types.StringType, types.IPType, types.AclType) {
-			l.Error(InvalidTypeExpression(exp.GetMeta(), right, types.StringType, types.IPType, types.AclType).Match(OPERATOR_CONDITIONAL))
-		}
-		// And, if right expression is STRING, regex must be valid
-		if v, ok := exp.Right.(*ast.String); ok {
-			if _, err := regexp.Compile(v.Value); err != nil {
-				err := &LintError{
-					Severity: ERROR,
-					Token:    exp.Right.GetMeta().Token,
-					Message:  "regex string is invalid, " + err.Error(),
-				}
-				l.Error(err)
-			}
-		}
+		l.lintMatchOperator(exp, left, right)
 		return types.BoolType
 	case "+":
-		// Plus operator behaves string concatenation.
-		// VCL accepts other types with implicit type conversion as following:
-		// IDENT   -> point value
-		// STRING  -> raw string
-		// INTEGER -> stringify
-		// FLOAT   -> stringify
-		// IP      -> stringify
-		// TIME    -> stringify (GMT string)
-		// RTIME   -> stringify (GMT string)
-		// BOOL    -> 0 (false) or 1 (true)
-		switch left {
-		case types.AclType, types.BackendType:
-			err := &LintError{
-				Severity: ERROR,
-				Token:    exp.GetMeta().Token,
-				Message:  "ACL or BACKEND type cannot use in string concatenation",
-			}
-			l.Error(err.Match(OPERATOR_CONDITIONAL))
-		case types.StringType:
-			break
-		default:
-			l.Error(ImplicitTypeConversion(exp.GetMeta(), left, types.StringType))
-		}
-
-		switch right {
-		case types.AclType, types.BackendType:
-			l.Error(&LintError{
-				Severity: ERROR,
-				Token:    exp.GetMeta().Token,
-				Message:  "ACL or BACKEND type cannot use in string concatenation",
-			})
-		case types.StringType:
-			break
-		default:
-			l.Error(ImplicitTypeConversion(exp.GetMeta(), right, types.StringType))
-		}
+		l.lintConcatenationOperator(exp, left, right)
 		return types.StringType
 	case "&&", "||":
-		// AND / OR operator compares left and right with truthy or falsy
+		l.lintLogicalExpression(exp, left, right)
 		return types.BoolType
 	default:
 		return types.NeverType
@@ -1702,7 +1917,9 @@ func (l *Linter) lintIfExpression(exp *ast.IfExpression, ctx *context.Context) t
 
 func (l *Linter) lintFunctionCallExpression(exp *ast.FunctionCallExpression, ctx *context.Context) types.Type {
 	fn, err := ctx.GetFunction(exp.Function.Value)
-	if err != nil {
+	if err != nil && isVarnishDialect(ctx) {
+		return l.lintVarnishFunctionArguments(exp.Arguments, ctx)
+	} else if err != nil {
 		l.Error(&LintError{
 			Severity: ERROR,
 			Token:    exp.Function.GetMeta().Token,
@@ -1711,6 +1928,18 @@ func (l *Linter) lintFunctionCallExpression(exp *ast.FunctionCallExpression, ctx
 		return types.NeverType
 	}
 
+	// Mark functional subroutine is called
+	if s, ok := ctx.Functions[exp.Function.Value]; ok && fn.IsUserDefinedFunction {
+		s.IsUsed = true
+	}
+
+	// Regex pattern argument must be valid
+	if _, ok := regexFunctions[exp.Function.Value]; ok && len(exp.Arguments) > 1 {
+		if v, ok := exp.Arguments[1].(*ast.String); ok {
+			l.lintRegex(v)
+		}
+	}
+
 	return l.lintFunctionArguments(fn, functionMeta{
 		name:      exp.Function.String(),
 		token:     exp.Function.GetMeta().Token,
@@ -1721,7 +1950,9 @@ func (l *Linter) lintFunctionCallExpression(exp *ast.FunctionCallExpression, ctx
 
 func (l *Linter) lintFunctionStatement(exp *ast.FunctionCallStatement, ctx *context.Context) types.Type {
 	fn, err := ctx.GetFunction(exp.Function.Value)
-	if err != nil {
+	if err != nil && isVarnishDialect(ctx) {
+		return l.lintVarnishFunctionArguments(exp.Arguments, ctx)
+	} else if err != nil {
 		l.Error(&LintError{
 			Severity: ERROR,
 			Token:    exp.Function.GetMeta().Token,
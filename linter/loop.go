@@ -0,0 +1,152 @@
+package linter
+
+import (
+	"strings"
+
+	"github.com/ysugimoto/falco/ast"
+	"github.com/ysugimoto/falco/context"
+)
+
+// lintRestartLoop reports restart statements which always run again after restarting,
+// so the request is restarted until it exceeds the max restarts.
+func (l *Linter) lintRestartLoop(decl *ast.SubroutineDeclaration) {
+	defer l.measure(RESTART_LOOP)()
+	if !context.IsFastlySubroutine(decl.Name.Value) {
+		return
+	}
+
+	// Restart statement on the top level is always executed
+	for _, stmt := range decl.Block.Statements {
+		if _, ok := stmt.(*ast.RestartStatement); ok {
+			l.Error(RestartLoop(stmt.GetMeta(), "restart is executed unconditionally").Match(RESTART_LOOP))
+		}
+		if isTerminated(stmt) {
+			break
+		}
+	}
+
+	// vcl_recv is executed again with the same request after restart,
+	// then conditions which depend only on unmodified request values are evaluated as the same result.
+	// The condition may be intended to be retried until max restarts, so it is checked only when enabled
+	if decl.Name.Value != "vcl_recv" || !l.boolRuleOption(RESTART_LOOP, "conditions") {
+		return
+	}
+	modified := make(map[string]struct{})
+	for _, n := range ast.NewTree(decl.Block).Nodes() {
+		switch t := n.(type) {
+		case *ast.SetStatement:
+			modified[strings.ToLower(t.Ident.Value)] = struct{}{}
+		case *ast.AddStatement:
+			modified[strings.ToLower(t.Ident.Value)] = struct{}{}
+		case *ast.UnsetStatement:
+			modified[strings.ToLower(t.Ident.Value)] = struct{}{}
+		case *ast.RemoveStatement:
+			modified[strings.ToLower(t.Ident.Value)] = struct{}{}
+		case *ast.CallStatement:
+			// Called subroutine may modify the request
+			return
+		}
+	}
+	l.lintConditionalRestart(decl.Block.Statements, nil, modified)
+}
+
+func (l *Linter) lintConditionalRestart(statements []ast.Statement, conditions []ast.Expression, modified map[string]struct{}) {
+	for _, stmt := range statements {
+		switch t := stmt.(type) {
+		case *ast.RestartStatement:
+			if len(conditions) > 0 && isInvariantOnRestart(conditions, modified) {
+				l.Error(RestartLoop(
+					t.GetMeta(), "restart condition does not change after restart, consider checking req.restarts",
+				).Match(RESTART_LOOP))
+			}
+		case *ast.BlockStatement:
+			l.lintConditionalRestart(t.Statements, conditions, modified)
+		case *ast.IfStatement:
+			// Only the first branch is checked because other branches depend on the negation of earlier conditions
+			l.lintConditionalRestart(t.Consequence.Statements, append(conditions, t.Condition), modified)
+		}
+	}
+}
+
+// isInvariantOnRestart returns true when all conditions consist of request values which are not modified.
+// Function call may return different value like randombool() so it is treated as variant.
+func isInvariantOnRestart(conditions []ast.Expression, modified map[string]struct{}) bool {
+	for _, cond := range conditions {
+		for _, n := range ast.NewTree(cond).Nodes() {
+			switch t := n.(type) {
+			case *ast.FunctionCallExpression:
+				return false
+			case *ast.Ident:
+				name := strings.ToLower(t.Value)
+				if !strings.HasPrefix(name, "req.") || name == "req.restarts" || strings.HasPrefix(name, "req.backend") {
+					return false
+				}
+				if _, ok := modified[name]; ok {
+					return false
+				}
+			}
+		}
+	}
+	return true
+}
+
+// lintGotoLoop reports goto statements which jump backward to the destination.
+// The loop is infinite when no statement between the destination and goto could leave the loop.
+func (l *Linter) lintGotoLoop(decl *ast.SubroutineDeclaration) {
+	defer l.measure(GOTO_LOOP)()
+	blocks := []*ast.BlockStatement{decl.Block}
+	for _, n := range ast.NewTree(decl.Block).Nodes() {
+		if b, ok := n.(*ast.BlockStatement); ok && b != decl.Block {
+			blocks = append(blocks, b)
+		}
+	}
+
+	destinations := make(map[string]*ast.GotoDestinationStatement)
+	for _, b := range blocks {
+		for _, stmt := range b.Statements {
+			if d, ok := stmt.(*ast.GotoDestinationStatement); ok {
+				destinations[strings.TrimSuffix(d.Name.Value, ":")] = d
+			}
+		}
+	}
+
+	for _, b := range blocks {
+		for i, stmt := range b.Statements {
+			g, ok := stmt.(*ast.GotoStatement)
+			if !ok {
+				continue
+			}
+			dest, ok := destinations[g.Destination.Value]
+			if !ok || !lessMeta(dest.GetMeta(), g.GetMeta()) {
+				continue
+			}
+			if index := statementIndex(b.Statements[:i], dest); index >= 0 && !canLeaveLoop(b.Statements[index+1:i]) {
+				l.Error(GotoLoop(g.GetMeta(), g.Destination.Value, true).Match(GOTO_LOOP))
+			} else {
+				l.Error(GotoLoop(g.GetMeta(), g.Destination.Value, false).Match(GOTO_LOOP))
+			}
+		}
+	}
+}
+
+func statementIndex(statements []ast.Statement, target ast.Statement) int {
+	for i := range statements {
+		if statements[i] == target {
+			return i
+		}
+	}
+	return -1
+}
+
+// canLeaveLoop returns true when statements contain any statement which exits the flow
+func canLeaveLoop(statements []ast.Statement) bool {
+	for _, stmt := range statements {
+		for _, n := range ast.NewTree(stmt).Nodes() {
+			switch n.(type) {
+			case *ast.ReturnStatement, *ast.ErrorStatement, *ast.RestartStatement, *ast.GotoStatement:
+				return true
+			}
+		}
+	}
+	return false
+}
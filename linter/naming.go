@@ -0,0 +1,76 @@
+package linter
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/ysugimoto/falco/ast"
+	"github.com/ysugimoto/falco/context"
+)
+
+// Kinds of named declarations which naming convention could be configured via rule option
+const (
+	namingSubroutine = "subroutine"
+	namingBackend    = "backend"
+	namingAcl        = "acl"
+	namingTable      = "table"
+	namingDirector   = "director"
+	namingVariable   = "variable"
+)
+
+// Prefix of backend names which Fastly generates from the service configuration
+const fastlyBackendPrefix = "F_"
+
+// lintNaming reports the name which does not match configured naming convention, or uses prefix reserved by Fastly
+func (l *Linter) lintNaming(kind string, ident *ast.Ident) {
+	name := ident.Value
+	if kind == namingVariable {
+		name = strings.TrimPrefix(name, "var.")
+	}
+
+	switch {
+	case kind == namingSubroutine && strings.HasPrefix(name, "vcl_"):
+		// Only state-machine subroutines could use "vcl_" prefix
+		if _, ok := varnish4SubroutineScopes[name]; !ok && !context.IsFastlySubroutine(name) {
+			l.Error(ReservedName(ident.GetMeta(), kind, name, "vcl_").Match(NAMING_CONVENTION))
+		}
+	case kind != namingBackend && strings.HasPrefix(name, fastlyBackendPrefix):
+		l.Error(ReservedName(ident.GetMeta(), kind, name, fastlyBackendPrefix).Match(NAMING_CONVENTION))
+	}
+
+	pattern := l.namingPattern(kind, ident.GetMeta())
+	if pattern == nil {
+		return
+	}
+	// State-machine subroutines are not named by users
+	if kind == namingSubroutine && context.IsFastlySubroutine(name) {
+		return
+	}
+	if !pattern.MatchString(name) {
+		l.Error(NamingConvention(ident.GetMeta(), kind, name, pattern.String()).Match(NAMING_CONVENTION))
+	}
+}
+
+// namingPattern returns compiled naming convention pattern for the kind, or nil if not configured.
+// Invalid pattern is reported at the first name which is checked.
+func (l *Linter) namingPattern(kind string, m *ast.Meta) *regexp.Regexp {
+	if l.namingPatterns == nil {
+		l.namingPatterns = make(map[string]*regexp.Regexp)
+	}
+	if p, ok := l.namingPatterns[kind]; ok {
+		return p
+	}
+
+	var pattern *regexp.Regexp
+	if v, ok := l.option.RuleOptions[NAMING_CONVENTION][kind].(string); ok && v != "" {
+		p, err := regexp.Compile(v)
+		if err != nil {
+			l.Error(InvalidNamingPattern(m, kind, v, err).Match(NAMING_CONVENTION))
+		} else {
+			pattern = p
+		}
+	}
+	// Cache invalid pattern as nil not to report it repeatedly
+	l.namingPatterns[kind] = pattern
+	return pattern
+}
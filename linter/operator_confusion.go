@@ -0,0 +1,55 @@
+package linter
+
+import (
+	"github.com/ysugimoto/falco/ast"
+)
+
+// Negated comparison operators, used to fix negation which applies only to the left operand
+var negatedComparisonOperators = map[string]string{
+	"==": "!=",
+	"!=": "==",
+	"~":  "!~",
+	"!~": "~",
+}
+
+// lintOperatorConfusion reports operators in the condition which behave differently from the intent
+func (l *Linter) lintOperatorConfusion(cond ast.Expression) {
+	for _, n := range ast.NewTree(cond).Nodes() {
+		infix, ok := n.(*ast.InfixExpression)
+		if !ok {
+			continue
+		}
+
+		// if (req.http.Foo = "bar") is mistake of "=="
+		if infix.Operator == "=" {
+			err := AssignmentInCondition(infix.GetMeta())
+			err.Fix = &Fix{
+				Description: `replace "=" with "=="`,
+				Replace: []*Replacement{
+					{Token: infix.GetMeta().Token, Text: "=="},
+				},
+			}
+			l.Error(err.Match(OPERATOR_CONFUSION))
+			continue
+		}
+
+		// if (!req.http.Foo == "bar") negates only req.http.Foo, not the comparison
+		prefix, ok := infix.Left.(*ast.PrefixExpression)
+		if !ok || prefix.Operator != "!" {
+			continue
+		}
+		negated, ok := negatedComparisonOperators[infix.Operator]
+		if !ok {
+			continue
+		}
+		err := NegationPrecedence(prefix.GetMeta(), infix.Operator, negated)
+		err.Fix = &Fix{
+			Description: `use "` + negated + `" operator instead of negation`,
+			Replace: []*Replacement{
+				{Token: prefix.GetMeta().Token, Text: ""},
+				{Token: infix.GetMeta().Token, Text: negated},
+			},
+		}
+		l.Error(err.Match(OPERATOR_CONFUSION))
+	}
+}
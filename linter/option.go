@@ -0,0 +1,128 @@
+package linter
+
+type OptionFunc func(o *Option)
+
+type Option struct {
+	// Rule specific options which are keyed by rule name, e.g. "table/item-limitation"
+	RuleOptions map[Rule]map[string]interface{}
+	// Report ignore comments which do not suppress any errors
+	ReportUnusedSuppressions bool
+	// Additional rules for this linter, see CustomRule
+	CustomRules []CustomRule
+	// Measure elapsed time of each check
+	Timing bool
+	// more field if exists
+}
+
+// WithRuleOptions sets options for the rule, available options are described in docs/rules.md
+func WithRuleOptions(rule Rule, options map[string]interface{}) OptionFunc {
+	return func(o *Option) {
+		if o.RuleOptions == nil {
+			o.RuleOptions = make(map[Rule]map[string]interface{})
+		}
+		o.RuleOptions[rule] = options
+	}
+}
+
+// WithReportUnusedSuppressions reports ignore comments which do not suppress any errors
+func WithReportUnusedSuppressions() OptionFunc {
+	return func(o *Option) {
+		o.ReportUnusedSuppressions = true
+	}
+}
+
+// WithCustomRules adds custom rules to the linter
+func WithCustomRules(rules ...CustomRule) OptionFunc {
+	return func(o *Option) {
+		o.CustomRules = append(o.CustomRules, rules...)
+	}
+}
+
+// WithTiming measures elapsed time of each check, results are available via Linter.Timings()
+func WithTiming() OptionFunc {
+	return func(o *Option) {
+		o.Timing = true
+	}
+}
+
+func collect(opts []OptionFunc) *Option {
+	o := &Option{}
+
+	for i := range opts {
+		opts[i](o)
+	}
+	return o
+}
+
+// intRuleOption returns integer option value for the rule, or default value if not specified
+func (l *Linter) intRuleOption(rule Rule, key string, defaultValue int) int {
+	v, ok := l.option.RuleOptions[rule][key]
+	if !ok {
+		return defaultValue
+	}
+	switch t := v.(type) {
+	case int:
+		return t
+	case int64:
+		return int(t)
+	case float64:
+		return int(t)
+	}
+	return defaultValue
+}
+
+// boolRuleOption returns boolean option value for the rule, or false if not specified
+func (l *Linter) boolRuleOption(rule Rule, key string) bool {
+	v, ok := l.option.RuleOptions[rule][key].(bool)
+	return ok && v
+}
+
+// stringsRuleOption returns string list option value for the rule, or nil if not specified
+func (l *Linter) stringsRuleOption(rule Rule, key string) []string {
+	v, ok := l.option.RuleOptions[rule][key]
+	if !ok {
+		return nil
+	}
+	switch t := v.(type) {
+	case []string:
+		return t
+	case []interface{}:
+		var values []string
+		for i := range t {
+			if s, ok := t[i].(string); ok {
+				values = append(values, s)
+			}
+		}
+		return values
+	}
+	return nil
+}
+
+// stringMapRuleOption returns string map option value for the rule, or nil if not specified.
+// YAML decoder provides nested map as map[interface{}]interface{} so keys are also converted.
+func (l *Linter) stringMapRuleOption(rule Rule, key string) map[string]string {
+	v, ok := l.option.RuleOptions[rule][key]
+	if !ok {
+		return nil
+	}
+	values := make(map[string]string)
+	switch t := v.(type) {
+	case map[string]string:
+		return t
+	case map[string]interface{}:
+		for k, v := range t {
+			s, _ := v.(string)
+			values[k] = s
+		}
+	case map[interface{}]interface{}:
+		for k, v := range t {
+			name, ok := k.(string)
+			if !ok {
+				continue
+			}
+			s, _ := v.(string)
+			values[name] = s
+		}
+	}
+	return values
+}
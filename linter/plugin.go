@@ -0,0 +1,35 @@
+package linter
+
+import (
+	goplugin "plugin"
+
+	"github.com/pkg/errors"
+)
+
+// PluginSymbol is the symbol name which Go plugin must export.
+// The symbol could be a variable of []linter.CustomRule or a function which returns it:
+//
+//	var Rules = []linter.CustomRule{&NamingRule{}}
+const PluginSymbol = "Rules"
+
+// LoadPlugin opens Go plugin which is built with "go build -buildmode=plugin" and returns its custom rules.
+// Note that the plugin must be built with the same Go version and falco module version.
+func LoadPlugin(path string) ([]CustomRule, error) {
+	p, err := goplugin.Open(path)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	sym, err := p.Lookup(PluginSymbol)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	switch v := sym.(type) {
+	case *[]CustomRule:
+		return *v, nil
+	case func() []CustomRule:
+		return v(), nil
+	default:
+		return nil, errors.Errorf("Plugin %s exports unexpected type %T for %s", path, sym, PluginSymbol)
+	}
+}
@@ -0,0 +1,88 @@
+package linter
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ysugimoto/falco/ast"
+)
+
+// lintBackendProbe checks probe settings which are contradictory each other.
+// Only literal values are checked, and pair of settings are compared only when both are declared.
+func (l *Linter) lintBackendProbe(probe *ast.BackendProbeObject) {
+	values := make(map[string]*ast.BackendProperty)
+	for _, v := range probe.Values {
+		values[v.Key.Value] = v
+	}
+	report := func(v *ast.BackendProperty, message string) {
+		err := &LintError{
+			Severity: WARNING,
+			Token:    v.Key.GetMeta().Token,
+			Message:  message,
+		}
+		l.Error(err.Match(BACKEND_PROBER_CONFIGURATION))
+	}
+
+	if threshold, window := probeInteger(values["threshold"]), probeInteger(values["window"]); threshold != nil && window != nil {
+		if threshold.Value > window.Value {
+			report(values["threshold"], fmt.Sprintf(
+				"Probe threshold %d is greater than window %d, the backend never becomes healthy", threshold.Value, window.Value,
+			))
+		}
+	}
+
+	if interval, timeout := probeRTime(values["interval"]), probeRTime(values["timeout"]); interval != nil && timeout != nil {
+		if interval.Duration < timeout.Duration {
+			report(values["interval"], fmt.Sprintf(
+				"Probe interval %s is shorter than timeout %s, next probe starts before the previous one times out", interval.Value, timeout.Value,
+			))
+		}
+	}
+
+	if code := probeInteger(values["expected_response"]); code != nil && (code.Value < 100 || code.Value > 599) {
+		report(values["expected_response"], fmt.Sprintf(
+			"Probe expected_response %d is not a valid HTTP status code between 100 and 599", code.Value,
+		))
+	}
+
+	if v, ok := values["request"]; ok {
+		if message := probeRequestProblem(v.Value); message != "" {
+			report(v, message)
+		}
+	}
+}
+
+func probeInteger(v *ast.BackendProperty) *ast.Integer {
+	if v == nil {
+		return nil
+	}
+	i, _ := v.Value.(*ast.Integer)
+	return i
+}
+
+func probeRTime(v *ast.BackendProperty) *ast.RTime {
+	if v == nil {
+		return nil
+	}
+	r, _ := v.Value.(*ast.RTime)
+	return r
+}
+
+// probeRequestProblem checks the request lines of the probe.
+// Each string literal is a line of the request, HTTP/1.1 request must have Host header.
+// Request which has only the request line does not declare headers, then it is not checked.
+func probeRequestProblem(exp ast.Expression) string {
+	lines := stringLiterals(exp)
+	if len(lines) < 2 {
+		return ""
+	}
+	if !strings.HasSuffix(strings.TrimSpace(lines[0].Value), "HTTP/1.1") {
+		return ""
+	}
+	for _, line := range lines[1:] {
+		if name, _, found := strings.Cut(line.Value, ":"); found && strings.EqualFold(strings.TrimSpace(name), "Host") {
+			return ""
+		}
+	}
+	return "Probe request does not have Host header, HTTP/1.1 request requires it"
+}
@@ -0,0 +1,138 @@
+package linter
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ysugimoto/falco/ast"
+	"github.com/ysugimoto/falco/context"
+	"github.com/ysugimoto/falco/types"
+)
+
+// Kinds of declarations which are referenced by identifiers
+const (
+	referenceBackend     = "backend"
+	referenceAcl         = "acl"
+	referenceTable       = "table"
+	referencePenaltybox  = "penaltybox"
+	referenceRatecounter = "ratecounter"
+)
+
+// Maximum number of near-match candidates in the message
+const maxReferenceCandidates = 3
+
+// referenceKinds maps expected types to the kind of declaration
+var referenceKinds = map[types.Type]string{
+	types.BackendType:    referenceBackend,
+	types.ReqBackendType: referenceBackend,
+	types.AclType:        referenceAcl,
+	types.TableType:      referenceTable,
+}
+
+// declaredNames returns names of the declarations of the kind.
+// Declarations are collected from the whole include graph and snippets before linting statements,
+// so the reference in any file could be resolved.
+func declaredNames(ctx *context.Context, kind string) []string {
+	var names []string
+	switch kind {
+	case referenceBackend:
+		// Backends also contain directors
+		for name := range ctx.Backends {
+			names = append(names, name)
+		}
+	case referenceAcl:
+		for name := range ctx.Acls {
+			names = append(names, name)
+		}
+	case referenceTable:
+		for name := range ctx.Tables {
+			names = append(names, name)
+		}
+	case referencePenaltybox:
+		for name := range ctx.Penaltyboxes {
+			names = append(names, name)
+		}
+	case referenceRatecounter:
+		for name := range ctx.Ratecounters {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// isDeclarationIdent returns true if the expression is an identifier which could refer the declaration.
+// Variables like req.http.Foo or var.foo have the dot in the name.
+func isDeclarationIdent(exp ast.Expression) (*ast.Ident, bool) {
+	ident, ok := exp.(*ast.Ident)
+	if !ok || strings.Contains(ident.Value, ".") {
+		return nil, false
+	}
+	return ident, true
+}
+
+// lintReference checks the identifier refers the declaration of the kind.
+// Returns false with reporting near-match candidates when the declaration does not exist.
+func (l *Linter) lintReference(exp ast.Expression, kind string, ctx *context.Context) bool {
+	ident, ok := isDeclarationIdent(exp)
+	if !ok {
+		return true
+	}
+	for _, name := range declaredNames(ctx, kind) {
+		if name == ident.Value {
+			return true
+		}
+	}
+
+	err := UndefinedReference(ident.GetMeta(), kind, ident.Value, referenceCandidates(ctx, ident.Value, kind))
+	if kind == referenceBackend {
+		l.Error(err.Match(BACKEND_NOTFOUND))
+	} else {
+		l.Error(err.Match(REFERENCE_UNDEFINED))
+	}
+	return false
+}
+
+// referenceCandidates returns declarations which have similar name to the undefined reference,
+// candidates are formatted with the kind like `backend "F_origin"` and ordered by the similarity.
+func referenceCandidates(ctx *context.Context, name string, kinds ...string) []string {
+	if len(kinds) == 0 {
+		kinds = []string{referenceBackend, referenceAcl, referenceTable, referencePenaltybox, referenceRatecounter}
+	}
+	// Allow more edits for long names
+	threshold := len(name) / 3
+	if threshold < 1 {
+		threshold = 1
+	}
+
+	type candidate struct {
+		text     string
+		distance int
+	}
+	var candidates []candidate
+	lower := strings.ToLower(name)
+	for _, kind := range kinds {
+		for _, v := range declaredNames(ctx, kind) {
+			d := editDistance(lower, strings.ToLower(v))
+			if d > threshold {
+				continue
+			}
+			candidates = append(candidates, candidate{text: fmt.Sprintf(`%s "%s"`, kind, v), distance: d})
+		}
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].distance != candidates[j].distance {
+			return candidates[i].distance < candidates[j].distance
+		}
+		return candidates[i].text < candidates[j].text
+	})
+
+	var texts []string
+	for i := range candidates {
+		if i == maxReferenceCandidates {
+			break
+		}
+		texts = append(texts, candidates[i].text)
+	}
+	return texts
+}
@@ -0,0 +1,108 @@
+package linter
+
+import (
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/ysugimoto/falco/ast"
+	pcre "go.elara.ws/pcre"
+)
+
+// Functions which accept regex pattern at the second argument
+var regexFunctions = map[string]struct{}{
+	"regsub":    {},
+	"regsuball": {},
+}
+
+var emptyLowerBoundQuantifier = regexp.MustCompile(`^\{,[0-9]+\}`)
+
+// lintRegex checks the regex literal is valid in Fastly's PCRE,
+// and reports constructs which are valid in Go RE2 that the simulator uses, but behave differently in PCRE
+func (l *Linter) lintRegex(exp *ast.String) {
+	if _, err := pcre.Compile(exp.Value); err != nil {
+		l.Error(InvalidRegex(exp.GetMeta(), err).Match(REGEX_SYNTAX))
+		return
+	}
+	for _, message := range pcreIncompatibilities(exp.Value) {
+		l.Error(IncompatibleRegex(exp.GetMeta(), message).Match(REGEX_PCRE_INCOMPATIBLE))
+	}
+	for _, risk := range backtrackRisks(exp.Value) {
+		l.Error(CatastrophicBacktracking(exp.GetMeta(), risk).Match(REGEX_CATASTROPHIC_BACKTRACKING))
+	}
+}
+
+// pcreIncompatibilities scans the regex pattern and returns messages of incompatible constructs.
+// Fastly compiles regex without UTF mode so multibyte character is treated as byte sequence.
+func pcreIncompatibilities(pattern string) []string {
+	var messages []string
+	seen := make(map[string]struct{})
+	report := func(message string) {
+		if _, ok := seen[message]; !ok {
+			seen[message] = struct{}{}
+			messages = append(messages, message)
+		}
+	}
+
+	var inClass bool
+	for i := 0; i < len(pattern); {
+		r, size := utf8.DecodeRuneInString(pattern[i:])
+		switch {
+		case r == '\\':
+			if i+1 >= len(pattern) {
+				return messages
+			}
+			next := pattern[i+1]
+			switch next {
+			case 'Q':
+				// Quoted sequence until \E
+				end := strings.Index(pattern[i+2:], `\E`)
+				if end < 0 {
+					return messages
+				}
+				i += end + 4
+				continue
+			case 'v':
+				report(`"\v" matches vertical tab only in Go RE2, but any vertical whitespace character in PCRE`)
+			}
+			_, s := utf8.DecodeRuneInString(pattern[i+1:])
+			i += 1 + s
+			continue
+		case inClass:
+			if strings.HasPrefix(pattern[i:], "[:") {
+				// POSIX character class like [:alpha:]
+				if end := strings.Index(pattern[i:], ":]"); end > 0 {
+					i += end + 2
+					continue
+				}
+			}
+			if r == ']' {
+				inClass = false
+			} else if r >= utf8.RuneSelf {
+				report("Multibyte character in character class matches each byte in PCRE, but whole character in Go RE2")
+			}
+		case r == '[':
+			inClass = true
+			// Leading "]" or "^]" is literal
+			j := i + 1
+			if j < len(pattern) && pattern[j] == '^' {
+				j++
+			}
+			if j < len(pattern) && pattern[j] == ']' {
+				j++
+			}
+			i = j
+			continue
+		case r == '{':
+			if m := emptyLowerBoundQuantifier.FindString(pattern[i:]); m != "" {
+				report(`"` + m + `" is literal in Go RE2, but quantifier from zero in PCRE2 10.43 or later`)
+			}
+		case r >= utf8.RuneSelf:
+			if i+size < len(pattern) && strings.ContainsRune("*+?{", rune(pattern[i+size])) {
+				report("Quantifier after multibyte character repeats only the last byte in PCRE, but whole character in Go RE2")
+			}
+		}
+		i += size
+	}
+	return messages
+}
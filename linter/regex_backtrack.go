@@ -0,0 +1,606 @@
+package linter
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Heuristic detection of regex which may cause catastrophic backtracking on PCRE.
+// Go regexp/syntax could not be used because it simplifies alternations and character classes,
+// so the pattern is parsed by minimal PCRE parser which treats the pattern as byte sequence like Fastly does.
+
+type reKind int
+
+const (
+	reChar   reKind = iota // single byte which matches the charset
+	reEmpty                // zero-width assertion or empty
+	reConcat               // sequence
+	reAlt                  // alternation
+	reRepeat               // quantified expression
+	reAtomic               // atomic group or possessive quantifier, never backtracks
+)
+
+type reCharset [4]uint64
+
+func (c *reCharset) add(b byte) {
+	c[b/64] |= 1 << (b % 64)
+}
+
+func (c *reCharset) addRange(from, to byte) {
+	for i := int(from); i <= int(to); i++ {
+		c.add(byte(i))
+	}
+}
+
+func (c *reCharset) union(o reCharset) {
+	for i := range c {
+		c[i] |= o[i]
+	}
+}
+
+func (c *reCharset) negate() {
+	for i := range c {
+		c[i] = ^c[i]
+	}
+}
+
+func (c reCharset) intersects(o reCharset) bool {
+	for i := range c {
+		if c[i]&o[i] != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+type reNode struct {
+	kind       reKind
+	chars      reCharset
+	subs       []*reNode
+	min, max   int // max is -1 when unbounded
+	start, end int // offset in the pattern
+}
+
+type reParser struct {
+	pattern string
+	pos     int
+}
+
+// parseBacktrackPattern parses the pattern, returns nil when the pattern contains unsupported syntax
+func parseBacktrackPattern(pattern string) *reNode {
+	p := &reParser{pattern: pattern}
+	node, ok := p.parseAlt()
+	if !ok || p.pos != len(pattern) {
+		return nil
+	}
+	return node
+}
+
+func (p *reParser) eof() bool {
+	return p.pos >= len(p.pattern)
+}
+
+func (p *reParser) peek() byte {
+	return p.pattern[p.pos]
+}
+
+func (p *reParser) parseAlt() (*reNode, bool) {
+	start := p.pos
+	var subs []*reNode
+	for {
+		n, ok := p.parseConcat()
+		if !ok {
+			return nil, false
+		}
+		subs = append(subs, n)
+		if p.eof() || p.peek() != '|' {
+			break
+		}
+		p.pos++
+	}
+	if len(subs) == 1 {
+		return subs[0], true
+	}
+	return &reNode{kind: reAlt, subs: subs, start: start, end: p.pos}, true
+}
+
+func (p *reParser) parseConcat() (*reNode, bool) {
+	start := p.pos
+	var subs []*reNode
+	for !p.eof() && p.peek() != '|' && p.peek() != ')' {
+		n, ok := p.parseRepeat()
+		if !ok {
+			return nil, false
+		}
+		subs = append(subs, n)
+	}
+	if len(subs) == 1 {
+		return subs[0], true
+	}
+	return &reNode{kind: reConcat, subs: subs, start: start, end: p.pos}, true
+}
+
+func (p *reParser) parseRepeat() (*reNode, bool) {
+	start := p.pos
+	atom, ok := p.parseAtom()
+	if !ok {
+		return nil, false
+	}
+	for !p.eof() {
+		min, max := -1, -1
+		switch p.peek() {
+		case '*':
+			min = 0
+			p.pos++
+		case '+':
+			min = 1
+			p.pos++
+		case '?':
+			min, max = 0, 1
+			p.pos++
+		case '{':
+			var ok bool
+			if min, max, ok = p.parseBounds(); !ok {
+				return atom, true
+			}
+		default:
+			return atom, true
+		}
+		atom = &reNode{kind: reRepeat, subs: []*reNode{atom}, min: min, max: max, start: start}
+		if !p.eof() {
+			switch p.peek() {
+			case '?': // lazy quantifier also backtracks
+				p.pos++
+			case '+': // possessive quantifier
+				p.pos++
+				atom = &reNode{kind: reAtomic, subs: []*reNode{atom}, start: start}
+			}
+		}
+		atom.end = p.pos
+	}
+	return atom, true
+}
+
+// parseBounds parses {n}, {n,} and {n,m} quantifier, otherwise "{" is literal
+func (p *reParser) parseBounds() (int, int, bool) {
+	end := strings.IndexByte(p.pattern[p.pos:], '}')
+	if end < 0 {
+		return 0, 0, false
+	}
+	spec := p.pattern[p.pos+1 : p.pos+end]
+	from, to, hasComma := strings.Cut(spec, ",")
+	min, err := strconv.Atoi(from)
+	if err != nil {
+		return 0, 0, false
+	}
+	max := min
+	if hasComma {
+		max = -1
+		if to != "" {
+			if max, err = strconv.Atoi(to); err != nil {
+				return 0, 0, false
+			}
+		}
+	}
+	p.pos += end + 1
+	return min, max, true
+}
+
+func (p *reParser) parseAtom() (*reNode, bool) {
+	start := p.pos
+	c := p.peek()
+	p.pos++
+	node := &reNode{kind: reChar, start: start}
+	switch c {
+	case '(':
+		return p.parseGroup(start)
+	case '[':
+		if !p.parseClass(&node.chars) {
+			return nil, false
+		}
+	case '.':
+		node.chars.negate()
+		node.chars[0] &^= 1 << '\n'
+	case '^', '$':
+		node.kind = reEmpty
+	case '\\':
+		if p.eof() {
+			return nil, false
+		}
+		if p.peek() == 'Q' {
+			// Quoted sequence is a sequence of literals
+			end := strings.Index(p.pattern[p.pos:], `\E`)
+			if end < 0 {
+				end = len(p.pattern) - p.pos
+			}
+			node.kind = reConcat
+			for _, b := range []byte(p.pattern[p.pos+1 : p.pos+end]) {
+				var cs reCharset
+				cs.add(b)
+				node.subs = append(node.subs, &reNode{kind: reChar, chars: cs})
+			}
+			p.pos += end + 2
+			if p.pos > len(p.pattern) {
+				p.pos = len(p.pattern)
+			}
+			break
+		}
+		if !p.parseEscape(node, false) {
+			return nil, false
+		}
+	default:
+		node.chars.add(c)
+	}
+	node.end = p.pos
+	return node, true
+}
+
+func (p *reParser) parseGroup(start int) (*reNode, bool) {
+	kind := reConcat
+	if strings.HasPrefix(p.pattern[p.pos:], "?") {
+		rest := p.pattern[p.pos+1:]
+		switch {
+		case strings.HasPrefix(rest, ":"):
+			p.pos += 2
+		case strings.HasPrefix(rest, ">"):
+			kind = reAtomic
+			p.pos += 2
+		case strings.HasPrefix(rest, "="), strings.HasPrefix(rest, "!"):
+			kind = reEmpty
+			p.pos += 2
+		case strings.HasPrefix(rest, "<="), strings.HasPrefix(rest, "<!"):
+			kind = reEmpty
+			p.pos += 3
+		case strings.HasPrefix(rest, "P<"), strings.HasPrefix(rest, "<"), strings.HasPrefix(rest, "'"):
+			// Named group
+			end := strings.IndexAny(rest, ">'")
+			if end < 0 {
+				return nil, false
+			}
+			p.pos += end + 2
+		default:
+			// Inline flags like (?i) or (?i:...)
+			end := strings.IndexAny(rest, ":)")
+			if end < 0 {
+				return nil, false
+			}
+			p.pos += end + 1
+			if rest[end] == ')' {
+				p.pos++
+				return &reNode{kind: reEmpty, start: start, end: p.pos}, true
+			}
+			p.pos++
+		}
+	}
+
+	inner, ok := p.parseAlt()
+	if !ok || p.eof() || p.peek() != ')' {
+		return nil, false
+	}
+	p.pos++
+	switch kind {
+	case reAtomic, reEmpty:
+		return &reNode{kind: kind, subs: []*reNode{inner}, start: start, end: p.pos}, true
+	}
+	// Capturing or non-capturing group is transparent
+	inner.start, inner.end = start, p.pos
+	return inner, true
+}
+
+func (p *reParser) parseClass(cs *reCharset) bool {
+	var negate bool
+	if !p.eof() && p.peek() == '^' {
+		negate = true
+		p.pos++
+	}
+	first := true
+	for !p.eof() {
+		c := p.peek()
+		if c == ']' && !first {
+			p.pos++
+			if negate {
+				cs.negate()
+			}
+			return true
+		}
+		first = false
+		if strings.HasPrefix(p.pattern[p.pos:], "[:") {
+			if end := strings.Index(p.pattern[p.pos:], ":]"); end > 0 {
+				addPosixClass(cs, p.pattern[p.pos+2:p.pos+end])
+				p.pos += end + 2
+				continue
+			}
+		}
+		p.pos++
+		from := c
+		if c == '\\' {
+			if p.eof() {
+				return false
+			}
+			n := &reNode{kind: reChar}
+			if !p.parseEscape(n, true) {
+				return false
+			}
+			cs.union(n.chars)
+			continue
+		}
+		// Range like a-z
+		if p.pos+1 < len(p.pattern) && p.peek() == '-' && p.pattern[p.pos+1] != ']' {
+			to := p.pattern[p.pos+1]
+			p.pos += 2
+			if to == '\\' || to < from {
+				return false
+			}
+			cs.addRange(from, to)
+			continue
+		}
+		cs.add(from)
+	}
+	return false
+}
+
+func addPosixClass(cs *reCharset, name string) {
+	switch strings.TrimPrefix(name, "^") {
+	case "alpha":
+		cs.addRange('a', 'z')
+		cs.addRange('A', 'Z')
+	case "digit":
+		cs.addRange('0', '9')
+	case "alnum":
+		cs.addRange('a', 'z')
+		cs.addRange('A', 'Z')
+		cs.addRange('0', '9')
+	case "space":
+		cs.addRange('\t', '\r')
+		cs.add(' ')
+	default:
+		// Conservatively treat unknown class as any characters
+		cs.negate()
+	}
+}
+
+// parseEscape parses escape sequence after backslash
+func (p *reParser) parseEscape(node *reNode, inClass bool) bool {
+	c := p.peek()
+	p.pos++
+	cs := &node.chars
+	switch c {
+	case 'd', 'D':
+		cs.addRange('0', '9')
+	case 'w', 'W':
+		cs.addRange('a', 'z')
+		cs.addRange('A', 'Z')
+		cs.addRange('0', '9')
+		cs.add('_')
+	case 's', 'S':
+		cs.addRange('\t', '\r')
+		cs.add(' ')
+	case 'h', 'H':
+		cs.add('\t')
+		cs.add(' ')
+	case 'v', 'V':
+		cs.addRange('\n', '\r')
+	case 'b', 'B', 'A', 'z', 'Z', 'G':
+		if inClass {
+			cs.add('\b')
+			return true
+		}
+		node.kind = reEmpty
+		return true
+	case 'n':
+		cs.add('\n')
+	case 'r':
+		cs.add('\r')
+	case 't':
+		cs.add('\t')
+	case 'f':
+		cs.add('\f')
+	case 'e':
+		cs.add(0x1b)
+	case 'a':
+		cs.add(0x07)
+	case 'x':
+		code, ok := p.parseHex()
+		if !ok {
+			return false
+		}
+		cs.add(code)
+	case '1', '2', '3', '4', '5', '6', '7', '8', '9':
+		// Back reference could match anything
+		cs.negate()
+		return true
+	default:
+		if c != '_' && isAlphaNumeric(rune(c)) {
+			// Unsupported escape like \p{..}, treat as any characters
+			cs.negate()
+			return true
+		}
+		cs.add(c)
+	}
+	switch c {
+	case 'D', 'W', 'S', 'H', 'V':
+		cs.negate()
+	}
+	return true
+}
+
+func (p *reParser) parseHex() (byte, bool) {
+	var digits string
+	if !p.eof() && p.peek() == '{' {
+		end := strings.IndexByte(p.pattern[p.pos:], '}')
+		if end < 0 {
+			return 0, false
+		}
+		digits = p.pattern[p.pos+1 : p.pos+end]
+		p.pos += end + 1
+	} else {
+		end := p.pos
+		for end < len(p.pattern) && end < p.pos+2 && strings.IndexByte("0123456789abcdefABCDEF", p.pattern[end]) >= 0 {
+			end++
+		}
+		digits = p.pattern[p.pos:end]
+		p.pos = end
+	}
+	if digits == "" {
+		return 0, true
+	}
+	v, err := strconv.ParseUint(digits, 16, 8)
+	if err != nil {
+		return 0, false
+	}
+	return byte(v), true
+}
+
+// first returns the charset which could be matched at the first byte
+func (n *reNode) first() reCharset {
+	var cs reCharset
+	switch n.kind {
+	case reChar:
+		return n.chars
+	case reConcat:
+		for _, s := range n.subs {
+			cs.union(s.first())
+			if !s.nullable() {
+				break
+			}
+		}
+	case reAlt, reRepeat, reAtomic:
+		for _, s := range n.subs {
+			cs.union(s.first())
+		}
+	}
+	return cs
+}
+
+// nullable returns true when the node could match empty string
+func (n *reNode) nullable() bool {
+	switch n.kind {
+	case reChar:
+		return false
+	case reConcat:
+		for _, s := range n.subs {
+			if !s.nullable() {
+				return false
+			}
+		}
+		return true
+	case reAlt:
+		for _, s := range n.subs {
+			if s.nullable() {
+				return true
+			}
+		}
+		return false
+	case reRepeat:
+		return n.min == 0 || n.subs[0].nullable()
+	case reAtomic:
+		return n.subs[0].nullable()
+	}
+	return true
+}
+
+// backtrackRisks returns the parts of pattern which may cause catastrophic backtracking
+func backtrackRisks(pattern string) []string {
+	root := parseBacktrackPattern(pattern)
+	if root == nil {
+		return nil
+	}
+	var risks []string
+	var walk func(n *reNode)
+	walk = func(n *reNode) {
+		if n.kind == reAtomic {
+			return
+		}
+		if n.kind == reRepeat && n.max == -1 {
+			body := n.subs[0]
+			part := pattern[n.start:n.end]
+			if hasTrailingQuantifier(body, body.first()) {
+				risks = append(risks, `nested quantifier "`+part+`"`)
+				return
+			}
+			if body.kind == reAlt && hasOverlappingAlternatives(body) {
+				risks = append(risks, `overlapping alternation "`+part+`"`)
+				return
+			}
+		}
+		for _, s := range n.subs {
+			walk(s)
+		}
+	}
+	walk(root)
+	return risks
+}
+
+// hasTrailingQuantifier returns true when the body of outer quantifier could end with unbounded quantifier
+// which overlaps with the start of the next iteration, like (a+)+ or (\w+\s?)*
+func hasTrailingQuantifier(n *reNode, outer reCharset) bool {
+	switch n.kind {
+	case reRepeat:
+		return n.max == -1 && n.first().intersects(outer)
+	case reConcat:
+		for i := len(n.subs) - 1; i >= 0; i-- {
+			if hasTrailingQuantifier(n.subs[i], outer) {
+				return true
+			}
+			if !n.subs[i].nullable() {
+				return false
+			}
+		}
+	case reAlt:
+		for _, s := range n.subs {
+			if hasTrailingQuantifier(s, outer) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// hasOverlappingAlternatives returns true when two alternatives could match the same prefix, like (a|ab)* or (\w|\d)+
+func hasOverlappingAlternatives(n *reNode) bool {
+	seqs := make([][]reCharset, 0, len(n.subs))
+	for _, s := range n.subs {
+		if seq := charsetSequence(s); len(seq) > 0 {
+			seqs = append(seqs, seq)
+		}
+	}
+	for i := 0; i < len(seqs); i++ {
+		for j := i + 1; j < len(seqs); j++ {
+			if sequencesOverlap(seqs[i], seqs[j]) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// charsetSequence returns charsets of leading fixed bytes,
+// the last one is the first charset of variable length expression if exists
+func charsetSequence(n *reNode) []reCharset {
+	subs := []*reNode{n}
+	if n.kind == reConcat {
+		subs = n.subs
+	}
+	var seq []reCharset
+	for _, s := range subs {
+		switch s.kind {
+		case reChar:
+			seq = append(seq, s.chars)
+		case reEmpty:
+			continue
+		default:
+			return append(seq, s.first())
+		}
+	}
+	return seq
+}
+
+// sequencesOverlap returns true when one sequence could match a prefix of another
+func sequencesOverlap(a, b []reCharset) bool {
+	for k := 0; k < len(a) && k < len(b); k++ {
+		if !a[k].intersects(b[k]) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,37 @@
+package linter
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestBacktrackRisks(t *testing.T) {
+	tests := []struct {
+		pattern string
+		expect  []string
+	}{
+		{pattern: `^/foo/(bar|baz)/.*\.(png|jpe?g)$`},
+		{pattern: `^([a-z]+\.)+[a-z]+$`},
+		{pattern: `^(?:www\.)?example\.com$`},
+		{pattern: `^(a|b)*$`},
+		{pattern: `^(foo|far)+$`},
+		{pattern: `^(?>a+)+$`},
+		{pattern: `^(a++)+$`},
+		{pattern: `\Q(a+)+\E`},
+		{pattern: `^(a+)+$`, expect: []string{`nested quantifier "(a+)+"`}},
+		{pattern: `^(\w+\s?)*$`, expect: []string{`nested quantifier "(\w+\s?)*"`}},
+		{pattern: `^(?:x*,?)*$`, expect: []string{`nested quantifier "(?:x*,?)*"`}},
+		{pattern: `^(a|ab)*c$`, expect: []string{`overlapping alternation "(a|ab)*"`}},
+		{pattern: `^(\w|\d)+$`, expect: []string{`overlapping alternation "(\w|\d)+"`}},
+		{pattern: `^([^/]+|/)*$`, expect: []string{`nested quantifier "([^/]+|/)*"`}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern, func(t *testing.T) {
+			if diff := cmp.Diff(tt.expect, backtrackRisks(tt.pattern)); diff != "" {
+				t.Errorf("Backtrack risks mismatch, diff=%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,175 @@
+package linter
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ysugimoto/falco/ast"
+	"github.com/ysugimoto/falco/token"
+	"github.com/ysugimoto/falco/types"
+)
+
+// Grace period longer than this duration is suspicious
+const maxGracePeriod = 365 * 24 * time.Hour
+
+// Cache durations of the object which must not be negative
+var cacheDurationVariables = map[string]struct{}{
+	"beresp.ttl":                    {},
+	"beresp.grace":                  {},
+	"beresp.stale_if_error":         {},
+	"beresp.stale_while_revalidate": {},
+	"obj.ttl":                       {},
+	"obj.grace":                     {},
+	"obj.stale_if_error":            {},
+	"obj.stale_while_revalidate":    {},
+}
+
+// Directives which allow shared caches to store the response, keyed by lower-cased header variable.
+// Cache-Control max-age is also used by browsers so only s-maxage is considered.
+var sharedCacheDirectives = map[string]*regexp.Regexp{
+	"beresp.http.surrogate-control": regexp.MustCompile(`(?i)(?:^|[\s,])max-age\s*=\s*([0-9]+)`),
+	"beresp.http.cache-control":     regexp.MustCompile(`(?i)(?:^|[\s,])s-maxage\s*=\s*([0-9]+)`),
+}
+
+// lintRTimeSanity reports suspicious durations in the subroutine
+func (l *Linter) lintRTimeSanity(decl *ast.SubroutineDeclaration) {
+	defer l.measure(RTIME_SANITY)()
+	for _, n := range ast.NewTree(decl.Block).Nodes() {
+		if infix, ok := n.(*ast.InfixExpression); ok {
+			l.lintRTimeUnitTypo(infix)
+		}
+	}
+	l.lintCacheDurations(decl.Block.Statements)
+	l.lintZeroTTLWithCacheHeaders(decl)
+}
+
+// lintRTimeUnitTypo reports RTIME literal which is immediately followed by identifier like "5mn" or "10sec".
+// Lexer reads "5m" as RTIME and "n" as identifier, then they are concatenated implicitly.
+func (l *Linter) lintRTimeUnitTypo(infix *ast.InfixExpression) {
+	rtime, ok := infix.Left.(*ast.RTime)
+	if !ok {
+		return
+	}
+	ident, ok := infix.Right.(*ast.Ident)
+	if !ok {
+		return
+	}
+	lt, rt := rtime.GetMeta().Token, ident.GetMeta().Token
+	if lt.Line != rt.Line || lt.Position+len(lt.Literal) != rt.Position {
+		return
+	}
+	l.Error(SuspiciousDuration(
+		rtime.GetMeta(),
+		fmt.Sprintf(`Duration "%s%s" has unknown unit, available units are ms, s, m, h, d and y`, rtime.Value, ident.Value),
+	).Match(RTIME_SANITY))
+}
+
+// lintCacheDurations tracks constant cache durations in sequential statements,
+// and reports negative durations and too long grace period
+func (l *Linter) lintCacheDurations(statements []ast.Statement) {
+	known := make(map[string]float64)
+
+	for _, stmt := range statements {
+		set, ok := stmt.(*ast.SetStatement)
+		if !ok {
+			// Durations could be changed in other blocks or subroutines
+			known = make(map[string]float64)
+			switch t := stmt.(type) {
+			case *ast.BlockStatement:
+				l.lintCacheDurations(t.Statements)
+			case *ast.IfStatement:
+				l.lintCacheDurations(t.Consequence.Statements)
+				for _, a := range t.Another {
+					l.lintCacheDurations(a.Consequence.Statements)
+				}
+				if t.Alternative != nil {
+					l.lintCacheDurations(t.Alternative.Statements)
+				}
+			}
+			continue
+		}
+
+		name := set.Ident.Value
+		if _, ok := cacheDurationVariables[name]; !ok {
+			continue
+		}
+		c, ok := foldConstant(set.Value)
+		if !ok || c.Type != types.RTimeType {
+			delete(known, name)
+			continue
+		}
+
+		seconds := c.Float
+		switch set.Operator.Token.Type {
+		case token.ADDITION, token.SUBTRACTION:
+			prev, ok := known[name]
+			if !ok {
+				continue
+			}
+			if set.Operator.Token.Type == token.ADDITION {
+				seconds = prev + seconds
+			} else {
+				seconds = prev - seconds
+			}
+		case token.ASSIGN:
+		default:
+			delete(known, name)
+			continue
+		}
+		known[name] = seconds
+
+		if seconds < 0 {
+			l.Error(SuspiciousDuration(
+				set.Value.GetMeta(),
+				fmt.Sprintf("%s becomes negative duration %s", name, formatSeconds(seconds)),
+			).Match(RTIME_SANITY))
+		}
+		if strings.HasSuffix(name, ".grace") && seconds > maxGracePeriod.Seconds() {
+			l.Error(SuspiciousDuration(
+				set.Value.GetMeta(),
+				fmt.Sprintf("%s is longer than 1 year (%s)", name, formatSeconds(seconds)),
+			).Match(RTIME_SANITY))
+		}
+	}
+}
+
+// lintZeroTTLWithCacheHeaders reports zero TTL in the subroutine which also sets headers to cache the object in shared caches.
+// The object is not cached by Fastly even though headers say it is cacheable.
+func (l *Linter) lintZeroTTLWithCacheHeaders(decl *ast.SubroutineDeclaration) {
+	var zeroTTL *ast.SetStatement
+	var cacheable bool
+	for _, n := range ast.NewTree(decl.Block).Nodes() {
+		set, ok := n.(*ast.SetStatement)
+		if !ok {
+			continue
+		}
+		switch strings.ToLower(set.Ident.Value) {
+		case "beresp.ttl":
+			if c, ok := foldConstant(set.Value); ok && c.Type == types.RTimeType && c.Float == 0 && zeroTTL == nil {
+				zeroTTL = set
+			}
+		case "beresp.http.surrogate-control", "beresp.http.cache-control":
+			directive := sharedCacheDirectives[strings.ToLower(set.Ident.Value)]
+			for _, s := range stringLiterals(set.Value) {
+				for _, m := range directive.FindAllStringSubmatch(s.Value, -1) {
+					if v, err := strconv.Atoi(m[1]); err == nil && v > 0 {
+						cacheable = true
+					}
+				}
+			}
+		}
+	}
+	if zeroTTL != nil && cacheable {
+		l.Error(SuspiciousDuration(
+			zeroTTL.Value.GetMeta(),
+			"beresp.ttl is 0s but caching headers in the same subroutine allow caching",
+		).Match(RTIME_SANITY))
+	}
+}
+
+func formatSeconds(seconds float64) string {
+	return time.Duration(seconds * float64(time.Second)).String()
+}
@@ -1,5 +1,13 @@
 package linter
 
+import (
+	"strings"
+	"unicode"
+)
+
+// Base URL of the rule documentation, rule name is appended as the heading anchor
+const ruleDocumentationURL = "https://github.com/ysugimoto/falco/blob/main/docs/rules.md#"
+
 type Rule string
 
 func (r Rule) Reference() string {
@@ -9,9 +17,38 @@ func (r Rule) Reference() string {
 	return ""
 }
 
+// Category returns the category of the rule which is the prefix of the rule name like "acl" of "acl/syntax",
+// or the rule name itself if it does not have a prefix
+func (r Rule) Category() string {
+	if index := strings.Index(string(r), "/"); index > 0 {
+		return string(r)[:index]
+	}
+	return string(r)
+}
+
+// Documentation returns URL of the rule explanation in docs/rules.md
+func (r Rule) Documentation() string {
+	if r == "" {
+		return ""
+	}
+	// Build heading anchor as same as GitHub does
+	anchor := strings.Map(func(c rune) rune {
+		switch {
+		case c == ' ':
+			return '-'
+		case unicode.IsLetter(c), unicode.IsDigit(c), c == '-', c == '_':
+			return unicode.ToLower(c)
+		default:
+			return -1
+		}
+	}, string(r))
+	return ruleDocumentationURL + anchor
+}
+
 const (
 	ACL_SYNTAX                           = "acl/syntax"
 	ACL_DUPLICATED                       = "acl/duplicated"
+	ACL_OVERLAP                          = "acl/overlap"
 	BACKEND_SYNTAX                       = "backend/syntax"
 	BACKEND_DUPLICATED                   = "backend/duplicated"
 	BACKEND_NOTFOUND                     = "backend/notfound"
@@ -28,10 +65,12 @@ const (
 	TABLE_TYPE_VARIATION                 = "table/type-variation"
 	TABLE_ITEM_LIMITATION                = "table/item-limitation"
 	TABLE_DUPLICATED                     = "table/duplicated"
+	TABLE_DUPLICATED_KEY                 = "table/duplicated-key"
 	SUBROUTINE_SYNTAX                    = "subroutine/syntax"
 	SUBROUTINE_BOILERPLATE_MACRO         = "subroutine/boilerplate-macro"
 	SUBROUTINE_DUPLICATED                = "subroutine/duplicated"
 	SUBROUTINE_INVALID_RETURN_TYPE       = "subroutine/invalid-return-type"
+	SUBROUTINE_MISSING_RETURN            = "subroutine/missing-return"
 	PENALTYBOX_SYNTAX                    = "penaltybox/syntax"
 	PENALTYBOX_DUPLICATED                = "penaltybox/duplicated"
 	PENALTYBOX_NONEMPTY_BLOCK            = "penaltybox/nonempty-block"
@@ -41,6 +80,8 @@ const (
 	DECLARE_STATEMENT_SYNTAX             = "declare-statement/syntax"
 	DECLARE_STATEMENT_INVALID_TYPE       = "declare-statement/invalid-type"
 	DECLARE_STATEMENT_DUPLICATED         = "declare-statement/duplicated"
+	DECLARE_STATEMENT_SHADOWED           = "declare-statement/shadowed"
+	NEW_STATEMENT_SYNTAX                 = "new-statement/syntax"
 	SET_STATEMENT_SYNTAX                 = "set-statement/syntax"
 	OPERATOR_ASSIGNMENT                  = "operator/assignment"
 	UNSET_STATEMENT_SYNTAX               = "unset-statement/syntax"
@@ -57,20 +98,64 @@ const (
 	GOTO_DUPLICATED                      = "goto/duplicated"
 	GOTO_SYNTAX                          = "goto/syntax"
 	CONDITION_LITERAL                    = "condition/literal"
+	CONDITION_TYPE                       = "condition/type"
 	VALID_IP                             = "valid-ip"
 	FUNCTION_ARGUMENTS                   = "function/arguments"
 	FUNCTION_ARGUMENT_TYPE               = "function/argument-type"
 	INCLUDE_STATEMENT_MODULE_NOT_FOUND   = "include/module-not-found"
 	INCLUDE_STATEMENT_MODULE_LOAD_FAILED = "include/module-load-failed"
 	REGEX_MATCHED_VALUE_MAY_OVERRIDE     = "regex/matched-value-override"
+	REGEX_SYNTAX                         = "regex/syntax"
+	REGEX_PCRE_INCOMPATIBLE              = "regex/pcre-incompatible"
+	REGEX_CATASTROPHIC_BACKTRACKING      = "regex/catastrophic-backtracking"
 	UNUSED_DECLARATION                   = "unused/declaration"
 	UNUSED_VARIABLE                      = "unused/variable"
 	UNUSED_GOTO                          = "unused/goto"
+	UNUSED_SUPPRESSION                   = "unused/suppression"
+	UNUSED_LOCAL_VARIABLE                = "unused/local-variable"
 	DISALLOW_EMPTY_RETURN                = "disallow-empty-return"
+	UNREACHABLE_CODE                     = "unreachable-code"
+	RESTART_LOOP                         = "restart-loop"
+	GOTO_LOOP                            = "goto-loop"
+	HEADER_TYPO                          = "header/typo"
+	DEPRECATED                           = "deprecated"
+	CONDITION_CONSTANT                   = "condition/constant"
+	DUPLICATE_BRANCH                     = "duplicate-branch"
+	COMPLEXITY_CYCLOMATIC                = "complexity/cyclomatic"
+	COMPLEXITY_NESTING                   = "complexity/nesting"
+	NAMING_CONVENTION                    = "naming-convention"
+	COMPARISON_CASE_SENSITIVITY          = "comparison/case-sensitivity"
+	RTIME_SANITY                         = "rtime/sanity"
+	OPERATOR_CONFUSION                   = "operator/confusion"
+	LIMIT_SYNTHETIC_SIZE                 = "limit/synthetic-size"
+	LIMIT_HEADER_COUNT                   = "limit/header-count"
+	LIMIT_HEADER_SIZE                    = "limit/header-size"
+	LIMIT_WORKSPACE                      = "limit/workspace"
+	LIMIT_ACL_ENTRIES                    = "limit/acl-entries"
+	LIMIT_ACL_COUNT                      = "limit/acl-count"
+	LIMIT_BACKEND_COUNT                  = "limit/backend-count"
+	LIMIT_DIRECTOR_COUNT                 = "limit/director-count"
+	LIMIT_SURROGATE_KEY                  = "limit/surrogate-key"
+	LIMIT_TABLE_SIZE                     = "limit/table-size"
+	STRING_INVALID_ESCAPE                = "string/invalid-escape"
+	DECLARATION_UNKNOWN_PROPERTY         = "declaration/unknown-property"
+	UNINITIALIZED_NOT_SET                = "uninitialized/not-set"
+	UNINITIALIZED_MAYBE_NOT_SET          = "uninitialized/maybe-not-set"
+	REFERENCE_UNDEFINED                  = "reference/undefined"
+	GOTO_DANGEROUS                       = "goto/dangerous"
+	ESI_MISCONFIGURATION                 = "esi/misconfiguration"
+	BANNED_IDENTIFIER                    = "banned/identifier"
+	SYNTHETIC_SANITY                     = "synthetic/sanity"
+	CACHE_IMPLICIT_TTL                   = "cache/implicit-ttl"
+	HASH_OUTSIDE_VCL_HASH                = "hash/outside-vcl-hash"
+	HASH_MISSING_KEY                     = "hash/missing-key"
+	CONCATENATION_INEFFICIENT            = "concatenation/inefficient"
+	TAINT_UNESCAPED_OUTPUT               = "taint/unescaped-output"
 )
 
 var references = map[Rule]string{
 	ACL_SYNTAX:                       "https://developer.fastly.com/reference/vcl/declarations/acl/",
+	ACL_OVERLAP:                      "https://developer.fastly.com/reference/vcl/declarations/acl/",
 	BACKEND_SYNTAX:                   "https://developer.fastly.com/reference/vcl/declarations/backend/",
 	DIRECTOR_SYNTAX:                  "https://developer.fastly.com/reference/vcl/declarations/director/",
 	DIRECTOR_PROPS_RANDOM:            "https://developer.fastly.com/reference/vcl/declarations/director/#random",
@@ -81,14 +166,18 @@ var references = map[Rule]string{
 	TABLE_SYNTAX:                     "https://developer.fastly.com/reference/vcl/declarations/table/",
 	TABLE_TYPE_VARIATION:             "https://developer.fastly.com/reference/vcl/declarations/table/#type-variations",
 	TABLE_ITEM_LIMITATION:            "https://developer.fastly.com/reference/vcl/declarations/table/#limitations",
+	TABLE_DUPLICATED_KEY:             "https://developer.fastly.com/reference/vcl/declarations/table/",
 	SUBROUTINE_SYNTAX:                "https://developer.fastly.com/reference/vcl/subroutines/",
 	SUBROUTINE_BOILERPLATE_MACRO:     "https://developer.fastly.com/learning/vcl/using/#adding-vcl-to-your-service-configuration",
+	SUBROUTINE_MISSING_RETURN:        "https://developer.fastly.com/reference/vcl/subroutines/",
 	PENALTYBOX_SYNTAX:                "https://developer.fastly.com/reference/vcl/declarations/penaltybox/",
 	PENALTYBOX_NONEMPTY_BLOCK:        "https://developer.fastly.com/reference/vcl/declarations/penaltybox/",
 	RATECOUNTER_SYNTAX:               "https://developer.fastly.com/reference/vcl/declarations/ratecounter/",
 	RATECOUNTER_NONEMPTY_BLOCK:       "https://developer.fastly.com/reference/vcl/declarations/ratecounter/",
 	DECLARE_STATEMENT_SYNTAX:         "https://developer.fastly.com/reference/vcl/variables/#user-defined-variables",
 	DECLARE_STATEMENT_INVALID_TYPE:   "https://developer.fastly.com/reference/vcl/variables/#user-defined-variables",
+	DECLARE_STATEMENT_SHADOWED:       "https://developer.fastly.com/reference/vcl/variables/#user-defined-variables",
+	NEW_STATEMENT_SYNTAX:             "https://varnish-cache.org/docs/4.1/reference/vcl.html#vmod-objects",
 	SET_STATEMENT_SYNTAX:             "https://developer.fastly.com/reference/vcl/statements/set/",
 	OPERATOR_ASSIGNMENT:              "https://developer.fastly.com/reference/vcl/operators/#assignment-operators",
 	UNSET_STATEMENT_SYNTAX:           "https://developer.fastly.com/reference/vcl/statements/unset/",
@@ -102,4 +191,42 @@ var references = map[Rule]string{
 	SYNTHETIC_STATEMENT_SCOPE:        "https://developer.fastly.com/reference/vcl/statements/synthetic/",
 	SYNTHETIC_BASE64_STATEMENT_SCOPE: "https://developer.fastly.com/reference/vcl/statements/synthetic-base64/",
 	DISALLOW_EMPTY_RETURN:            "https://developer.fastly.com/reference/vcl/subroutines#returning-a-state",
+	UNUSED_SUPPRESSION:               "https://github.com/ysugimoto/falco/blob/main/docs/linter.md#ignoring-errors",
+	UNUSED_LOCAL_VARIABLE:            "https://developer.fastly.com/reference/vcl/variables/#user-defined-variables",
+	CONDITION_TYPE:                   "https://docs.fastly.com/en/guides/using-conditions",
+	STRING_INVALID_ESCAPE:            "https://developer.fastly.com/reference/vcl/types/string/",
+	REGEX_SYNTAX:                     "https://developer.fastly.com/reference/vcl/regex/",
+	REGEX_PCRE_INCOMPATIBLE:          "https://developer.fastly.com/reference/vcl/regex/",
+	REGEX_CATASTROPHIC_BACKTRACKING:  "https://developer.fastly.com/reference/vcl/regex/",
+	LIMIT_SYNTHETIC_SIZE:             "https://docs.fastly.com/en/guides/resource-limits",
+	LIMIT_HEADER_COUNT:               "https://docs.fastly.com/en/guides/resource-limits",
+	LIMIT_HEADER_SIZE:                "https://docs.fastly.com/en/guides/resource-limits",
+	LIMIT_WORKSPACE:                  "https://docs.fastly.com/en/guides/resource-limits",
+	LIMIT_ACL_ENTRIES:                "https://docs.fastly.com/en/guides/resource-limits",
+	LIMIT_ACL_COUNT:                  "https://docs.fastly.com/en/guides/resource-limits",
+	LIMIT_BACKEND_COUNT:              "https://docs.fastly.com/en/guides/resource-limits",
+	LIMIT_DIRECTOR_COUNT:             "https://docs.fastly.com/en/guides/resource-limits",
+	LIMIT_SURROGATE_KEY:              "https://docs.fastly.com/en/guides/resource-limits",
+	LIMIT_TABLE_SIZE:                 "https://developer.fastly.com/reference/vcl/declarations/table/#limitations",
+	RESTART_LOOP:                     "https://developer.fastly.com/reference/vcl/statements/restart/",
+	GOTO_LOOP:                        "https://developer.fastly.com/reference/vcl/statements/goto/",
+	HEADER_TYPO:                      "https://developer.fastly.com/reference/http/http-headers/",
+	DEPRECATED:                       "https://developer.fastly.com/reference/vcl/",
+	CONDITION_CONSTANT:               "https://developer.fastly.com/reference/vcl/statements/if/",
+	DUPLICATE_BRANCH:                 "https://developer.fastly.com/reference/vcl/statements/if/",
+	NAMING_CONVENTION:                "https://developer.fastly.com/reference/vcl/declarations/",
+	COMPARISON_CASE_SENSITIVITY:      "https://developer.fastly.com/reference/vcl/operators/#comparison-operators",
+	RTIME_SANITY:                     "https://developer.fastly.com/reference/vcl/types/rtime/",
+	OPERATOR_CONFUSION:               "https://developer.fastly.com/reference/vcl/operators/",
+	UNINITIALIZED_NOT_SET:            "https://developer.fastly.com/reference/vcl/types/string/",
+	UNINITIALIZED_MAYBE_NOT_SET:      "https://developer.fastly.com/reference/vcl/types/string/",
+	REFERENCE_UNDEFINED:              "https://developer.fastly.com/reference/vcl/declarations/",
+	GOTO_DANGEROUS:                   "https://developer.fastly.com/reference/vcl/statements/goto/",
+	ESI_MISCONFIGURATION:             "https://developer.fastly.com/reference/vcl/statements/esi/",
+	SYNTHETIC_SANITY:                 "https://developer.fastly.com/reference/vcl/statements/synthetic/",
+	CACHE_IMPLICIT_TTL:               "https://docs.fastly.com/en/guides/cache-freshness-and-ttls",
+	HASH_OUTSIDE_VCL_HASH:            "https://developer.fastly.com/reference/vcl/variables/cache-object/req-hash/",
+	HASH_MISSING_KEY:                 "https://developer.fastly.com/reference/vcl/subroutines/hash/",
+	CONCATENATION_INEFFICIENT:        "https://developer.fastly.com/reference/vcl/functions/headers/std-collect/",
+	TAINT_UNESCAPED_OUTPUT:           "https://developer.fastly.com/reference/vcl/functions/strings/urlencode/",
 }
@@ -0,0 +1,123 @@
+package linter
+
+import (
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+	"github.com/ysugimoto/falco/context"
+	"github.com/ysugimoto/falco/loader"
+)
+
+// Config is the configuration of Run
+type Config struct {
+	// Options for the linter, e.g. WithRuleOptions or WithCustomRules
+	Options []OptionFunc
+	// Options for the linting context, e.g. context.WithResolver to resolve include statements
+	ContextOptions []context.Option
+	// Severity overrides keyed by rule name, IGNORE drops the issues of the rule
+	Severities map[Rule]Severity
+}
+
+// Position is the location in the VCL file, Line and Column are 1-origin
+type Position struct {
+	File   string
+	Line   int
+	Column int
+}
+
+// Range is the source range of the issue, End points to the next column of the last character
+type Range struct {
+	Start Position
+	End   Position
+}
+
+// Issue is the problem found by Run
+type Issue struct {
+	Rule          Rule
+	Category      string
+	Severity      Severity
+	Message       string
+	Range         Range
+	Reference     string
+	Documentation string
+	// Fixable is true when the issue could be fixed automatically, then Fix is set
+	Fixable bool
+	// Fix is the edit to fix the issue, see ApplyFixes
+	Fix *Fix
+}
+
+// Run lints the program which is loaded by loader package and returns issues sorted by position.
+// This is the entrypoint for Go programs which embed falco linting without invoking the CLI.
+// Load the program with loader.WithExpandIncludes() so that included files are linted from the loaded ASTs,
+// otherwise include statements are resolved by the resolver of ContextOptions.
+// Either way the range of the issue points to the included file where it is found.
+// An error is returned when the program could not be linted, e.g. an included module fails to parse.
+func Run(program *loader.Program, config *Config) ([]Issue, error) {
+	if program == nil || program.VCL == nil {
+		return nil, errors.New("Program must not be nil")
+	}
+	if config == nil {
+		config = &Config{}
+	}
+
+	l := New(config.Options...)
+	l.Lint(program.VCL, context.New(config.ContextOptions...))
+	if l.FatalError != nil {
+		return nil, errors.WithStack(l.FatalError.Error)
+	}
+
+	issues := []Issue{}
+	for _, err := range l.Errors {
+		le, ok := err.(*LintError)
+		if !ok {
+			continue
+		}
+		severity := le.Severity
+		if v, ok := config.Severities[le.Rule]; ok {
+			severity = v
+		}
+		if severity == IGNORE {
+			continue
+		}
+		issues = append(issues, Issue{
+			Rule:          le.Rule,
+			Category:      le.Category,
+			Severity:      severity,
+			Message:       le.Message,
+			Range:         tokenRange(le),
+			Reference:     le.Reference,
+			Documentation: le.Documentation,
+			Fixable:       le.Fixable(),
+			Fix:           le.Fix,
+		})
+	}
+
+	sort.SliceStable(issues, func(i, j int) bool {
+		a, b := issues[i].Range.Start, issues[j].Range.Start
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Column < b.Column
+	})
+	return issues, nil
+}
+
+// tokenRange returns the range which the token literal of the error spans
+func tokenRange(le *LintError) Range {
+	tok := le.Token
+	start := Position{File: tok.File, Line: tok.Line, Column: tok.Position}
+	end := start
+
+	lines := strings.Split(tok.Literal, "\n")
+	if len(lines) > 1 {
+		end.Line += len(lines) - 1
+		end.Column = 1
+	}
+	end.Column += utf8.RuneCountInString(lines[len(lines)-1])
+	return Range{Start: start, End: end}
+}
@@ -0,0 +1,125 @@
+package linter
+
+import (
+	"strings"
+
+	"github.com/ysugimoto/falco/ast"
+)
+
+// Status codes from 600 are used to pass the error to vcl_error internally, they are invalid as HTTP response
+const minInternalStatusCode = 600
+
+// lintSyntheticResponses reports synthetic responses in the subroutine which would not be sent to the client as expected:
+// - synthetic response is not followed by return(deliver), the response could be discarded or overwritten
+// - synthetic response for the internal status code like 601 without setting obj.status to valid status code
+// Size of the synthetic response is reported by limit/synthetic-size rule.
+func (l *Linter) lintSyntheticResponses(decl *ast.SubroutineDeclaration) {
+	defer l.measure(SYNTHETIC_SANITY)()
+	tree := ast.NewTree(decl.Block)
+	for _, n := range tree.Nodes() {
+		switch n.(type) {
+		case *ast.SyntheticStatement, *ast.SyntheticBase64Statement:
+		default:
+			continue
+		}
+		l.lintSyntheticDeliver(tree, decl, n)
+		l.lintSyntheticStatus(tree, n)
+	}
+}
+
+// lintSyntheticDeliver traces statements after the synthetic statement through enclosing blocks
+// and checks the response is delivered by return(deliver)
+func (l *Linter) lintSyntheticDeliver(tree *ast.Tree, decl *ast.SubroutineDeclaration, synthetic ast.Node) {
+	cur := synthetic
+	for _, p := range tree.Ancestors(synthetic) {
+		block, ok := p.(*ast.BlockStatement)
+		if !ok {
+			cur = p
+			continue
+		}
+		var after bool
+		for _, stmt := range block.Statements {
+			if stmt == cur {
+				after = true
+				continue
+			}
+			if !after {
+				continue
+			}
+			switch t := stmt.(type) {
+			case *ast.ReturnStatement:
+				if t.ReturnExpression == nil {
+					return
+				}
+				state := strings.TrimSpace((*t.ReturnExpression).String())
+				if ident, ok := (*t.ReturnExpression).(*ast.Ident); ok {
+					state = ident.Value
+				}
+				if state != "deliver" {
+					l.Error(SyntheticDiscarded(synthetic.GetMeta(), "return("+state+")").Match(SYNTHETIC_SANITY))
+				}
+				return
+			case *ast.RestartStatement:
+				l.Error(SyntheticDiscarded(synthetic.GetMeta(), "restart").Match(SYNTHETIC_SANITY))
+				return
+			}
+		}
+		cur = p
+	}
+
+	// Subroutines called from vcl_error could return to the caller which delivers the response
+	if decl.Name.Value == "vcl_error" {
+		l.Error(SyntheticWithoutDeliver(synthetic.GetMeta()).Match(SYNTHETIC_SANITY))
+	}
+}
+
+// lintSyntheticStatus checks obj.status is set when the synthetic response is served for the internal status code
+func (l *Linter) lintSyntheticStatus(tree *ast.Tree, synthetic ast.Node) {
+	cur := synthetic
+	for _, p := range tree.Ancestors(synthetic) {
+		if stmt, ok := p.(*ast.IfStatement); ok && cur == stmt.Consequence {
+			if code, ok := internalStatusCondition(stmt.Condition); ok && !setsObjStatus(stmt.Consequence) {
+				l.Error(SyntheticInternalStatus(synthetic.GetMeta(), code).Match(SYNTHETIC_SANITY))
+				return
+			}
+		}
+		cur = p
+	}
+}
+
+// internalStatusCondition returns the status code if the condition is like obj.status == 601
+func internalStatusCondition(cond ast.Expression) (int64, bool) {
+	switch t := cond.(type) {
+	case *ast.GroupedExpression:
+		return internalStatusCondition(t.Right)
+	case *ast.InfixExpression:
+		if t.Operator == "&&" {
+			if code, ok := internalStatusCondition(t.Left); ok {
+				return code, ok
+			}
+			return internalStatusCondition(t.Right)
+		}
+		if t.Operator != "==" {
+			return 0, false
+		}
+		ident, ok := t.Left.(*ast.Ident)
+		if !ok || ident.Value != "obj.status" {
+			return 0, false
+		}
+		code, ok := t.Right.(*ast.Integer)
+		if !ok || code.Value < minInternalStatusCode {
+			return 0, false
+		}
+		return code.Value, true
+	}
+	return 0, false
+}
+
+func setsObjStatus(block *ast.BlockStatement) bool {
+	for _, n := range ast.NewTree(block).Nodes() {
+		if set, ok := n.(*ast.SetStatement); ok && set.Ident.Value == "obj.status" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,195 @@
+package linter
+
+import (
+	"strings"
+
+	"github.com/ysugimoto/falco/ast"
+	"github.com/ysugimoto/falco/context"
+	"github.com/ysugimoto/falco/types"
+)
+
+// Functions which escape the value so that it could not break headers or responses
+var defaultSanitizers = []string{
+	"urlencode",
+	"json.escape",
+	"xml_escape",
+	"cstr_escape",
+	"digest.*",
+}
+
+// Prefixes of variables which hold the value sent by the client
+var taintSources = []string{
+	"req.url",
+	"req.http.",
+	"bereq.url",
+	"bereq.http.",
+}
+
+// Prefixes of response headers which are sent to the client
+var taintHeaderSinks = []string{
+	"resp.http.",
+	"obj.http.",
+	"beresp.http.",
+}
+
+// lintTaintedOutputs reports user controlled input which flows into synthetic responses or response headers
+// without escaping. The value could contain newlines or quotes, then it injects headers or breaks the response.
+// Log statement is not treated as the output because logging raw request values is common practice for debugging.
+// Taint is propagated through local variables in source order, and it is cleared by sanitizer functions,
+// functions which do not return STRING, or if statements which validate the value by regular expression.
+func (l *Linter) lintTaintedOutputs(decl *ast.SubroutineDeclaration, ctx *context.Context) {
+	defer l.measure(TAINT_UNESCAPED_OUTPUT)()
+
+	t := &taintAnalysis{
+		tree:       ast.NewTree(decl.Block),
+		ctx:        ctx,
+		sanitizers: append(l.stringsRuleOption(TAINT_UNESCAPED_OUTPUT, "sanitizers"), defaultSanitizers...),
+		tainted:    make(map[string]string),
+	}
+	for _, n := range t.tree.Nodes() {
+		switch stmt := n.(type) {
+		case *ast.DeclareStatement:
+			// Re-declaration resets the value
+			delete(t.tainted, strings.ToLower(stmt.Name.Value))
+		case *ast.SetStatement:
+			l.lintTaintedAssignment(t, stmt, stmt.Ident, stmt.Value)
+		case *ast.AddStatement:
+			l.lintTaintedAssignment(t, stmt, stmt.Ident, stmt.Value)
+		case *ast.SyntheticStatement:
+			if source := t.source(stmt, stmt.Value); source != "" {
+				l.Error(TaintedOutput(stmt.GetMeta(), source, "synthetic response").Match(TAINT_UNESCAPED_OUTPUT))
+			}
+		case *ast.SyntheticBase64Statement:
+			if source := t.source(stmt, stmt.Value); source != "" {
+				l.Error(TaintedOutput(stmt.GetMeta(), source, "synthetic response").Match(TAINT_UNESCAPED_OUTPUT))
+			}
+		}
+	}
+}
+
+// lintTaintedAssignment propagates taint to the local variable, or reports the tainted value assigned to the response header
+func (l *Linter) lintTaintedAssignment(t *taintAnalysis, stmt ast.Statement, ident *ast.Ident, value ast.Expression) {
+	name := strings.ToLower(ident.Value)
+	source := t.source(stmt, value)
+
+	if strings.HasPrefix(name, "var.") {
+		if source != "" {
+			t.tainted[name] = source
+		} else if t.tree.Parent(stmt) == t.tree.Root {
+			// Only unconditional assignment at the top level of the subroutine clears the taint
+			delete(t.tainted, name)
+		}
+		return
+	}
+	if source == "" {
+		return
+	}
+	for _, prefix := range taintHeaderSinks {
+		if strings.HasPrefix(name, prefix) {
+			l.Error(TaintedOutput(stmt.GetMeta(), source, ident.Value).Match(TAINT_UNESCAPED_OUTPUT))
+			return
+		}
+	}
+}
+
+type taintAnalysis struct {
+	tree       *ast.Tree
+	ctx        *context.Context
+	sanitizers []string
+	// tainted local variables and its original source
+	tainted map[string]string
+}
+
+// source returns the user controlled variable which the expression is derived from, or empty if the expression is clean
+func (t *taintAnalysis) source(stmt ast.Statement, exp ast.Expression) string {
+	source, name := t.expression(exp)
+	if source == "" || t.validated(stmt, source, name) {
+		return ""
+	}
+	return source
+}
+
+// expression returns the original source and the tainted name which appears in the expression
+func (t *taintAnalysis) expression(exp ast.Expression) (string, string) {
+	switch e := exp.(type) {
+	case *ast.Ident:
+		name := strings.ToLower(e.Value)
+		if v, ok := t.tainted[name]; ok {
+			return v, name
+		}
+		for _, prefix := range taintSources {
+			if strings.HasPrefix(name, prefix) {
+				return e.Value, name
+			}
+		}
+	case *ast.GroupedExpression:
+		return t.expression(e.Right)
+	case *ast.PrefixExpression:
+		return t.expression(e.Right)
+	case *ast.InfixExpression:
+		if source, name := t.expression(e.Left); source != "" {
+			return source, name
+		}
+		return t.expression(e.Right)
+	case *ast.IfExpression:
+		if source, name := t.expression(e.Consequence); source != "" {
+			return source, name
+		}
+		return t.expression(e.Alternative)
+	case *ast.FunctionCallExpression:
+		if t.sanitized(e.Function.Value) {
+			return "", ""
+		}
+		for _, arg := range e.Arguments {
+			if source, name := t.expression(arg); source != "" {
+				return source, name
+			}
+		}
+	}
+	return "", ""
+}
+
+// sanitized returns true if the function escapes the value or does not return string
+func (t *taintAnalysis) sanitized(name string) bool {
+	for _, s := range t.sanitizers {
+		if s == name || (strings.HasSuffix(s, ".*") && strings.HasPrefix(name, strings.TrimSuffix(s, "*"))) {
+			return true
+		}
+	}
+	fn, err := t.ctx.GetFunction(name)
+	if err != nil {
+		return false
+	}
+	return fn.Return != types.StringType
+}
+
+// validated returns true if the statement is in the if statement which matches the source or the tainted variable
+// with regular expression, e.g. if (req.http.Foo ~ "^[a-z]+$")
+func (t *taintAnalysis) validated(stmt ast.Statement, names ...string) bool {
+	var child ast.Node = stmt
+	for _, p := range t.tree.Ancestors(stmt) {
+		ifs, ok := p.(*ast.IfStatement)
+		prev := child
+		child = p
+		// Alternative is taken when the value does not match
+		if !ok || prev == ifs.Alternative {
+			continue
+		}
+		for _, n := range ast.NewTree(ifs.Condition).Nodes() {
+			infix, ok := n.(*ast.InfixExpression)
+			if !ok || infix.Operator != "~" {
+				continue
+			}
+			ident, ok := infix.Left.(*ast.Ident)
+			if !ok {
+				continue
+			}
+			for _, name := range names {
+				if strings.EqualFold(ident.Value, name) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,49 @@
+package linter
+
+import (
+	"time"
+)
+
+// Name of the elapsed time which is not attributed to specific checks, e.g. type checking of statements
+const timingCore = "core"
+
+// Timings returns elapsed time of checks keyed by the rule name, or the category like "complexity"
+// when the check reports multiple rules. Available when the linter is created with WithTiming option.
+func (l *Linter) Timings() map[string]time.Duration {
+	return l.timings
+}
+
+// measure starts measuring elapsed time of the check and returns the function to stop it, used like:
+//
+//	defer l.measure(GOTO_LOOP)()
+//
+// Checks are measured both in post-pass and per node. Nested measurement is attributed to the outer check,
+// otherwise the elapsed time is counted twice.
+func (l *Linter) measure(rule Rule) func() {
+	if !l.option.Timing || l.measuring {
+		return func() {}
+	}
+	l.measuring = true
+	start := time.Now()
+	return func() {
+		l.timings[string(rule)] += time.Since(start)
+		l.measuring = false
+	}
+}
+
+// measureCore attributes elapsed time of whole linting which is not measured by checks to the core
+func (l *Linter) measureCore() func() {
+	if !l.option.Timing {
+		return func() {}
+	}
+	start := time.Now()
+	return func() {
+		elapsed := time.Since(start)
+		for name, d := range l.timings {
+			if name != timingCore {
+				elapsed -= d
+			}
+		}
+		l.timings[timingCore] = elapsed
+	}
+}
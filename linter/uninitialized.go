@@ -0,0 +1,402 @@
+package linter
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/ysugimoto/falco/ast"
+	"github.com/ysugimoto/falco/context"
+	"github.com/ysugimoto/falco/token"
+)
+
+// initState is the state whether the local variable or the header is set at the point of program
+type initState int
+
+const (
+	// Assigned on all paths, or not tracked
+	initSet initState = iota
+	// Not assigned on some paths
+	initMaybeNotSet
+	// Not assigned on any paths
+	initNotSet
+)
+
+func (s initState) join(o initState) initState {
+	if s == o {
+		return s
+	}
+	return initMaybeNotSet
+}
+
+// initStates holds states of tracked names, untracked names are treated as set.
+// nil means the point is unreachable, e.g. after return statement.
+type initStates map[string]initState
+
+func (s initStates) copy() initStates {
+	if s == nil {
+		return nil
+	}
+	c := make(initStates, len(s))
+	for k, v := range s {
+		c[k] = v
+	}
+	return c
+}
+
+func (s initStates) join(o initStates) initStates {
+	if s == nil {
+		return o.copy()
+	}
+	if o == nil {
+		return s.copy()
+	}
+	c := make(initStates, len(s))
+	for k, v := range s {
+		c[k] = v.join(o[k])
+	}
+	for k, v := range o {
+		if _, ok := s[k]; !ok {
+			c[k] = initSet.join(v)
+		}
+	}
+	return c
+}
+
+// initAnalysis traces assignments of STRING local variables and headers through if branches and call chains,
+// and finds reads of them which may not be set.
+//
+// Local variables are tracked from the declaration because STRING local variable is initialized as not set.
+// Headers are tracked after they are unset because headers could be sent from clients or origins,
+// and the assignments in the called subroutines are propagated to the caller.
+type initAnalysis struct {
+	subroutines map[string]*ast.SubroutineDeclaration
+	// Remaining count of subroutines to follow, the same subroutine is analyzed on each call
+	budget int
+	// Joined states of reads over all paths
+	reads map[*ast.Ident]initState
+	// Subroutines in the current call chain to prevent infinite recursion
+	stack map[string]struct{}
+}
+
+// Limit of following subroutine calls to analyze large call graphs in reasonable time
+const maxInitAnalysisCalls = 10000
+
+// initFrame is the state of subroutine in analyzing
+type initFrame struct {
+	// Joined states at the return statements which return to the caller
+	returned initStates
+}
+
+func (l *Linter) lintUninitializedReads(ctx *context.Context) {
+	defer l.measure(Rule("uninitialized"))()
+	a := &initAnalysis{
+		subroutines: make(map[string]*ast.SubroutineDeclaration),
+		budget:      maxInitAnalysisCalls,
+		reads:       make(map[*ast.Ident]initState),
+		stack:       make(map[string]struct{}),
+	}
+
+	var names []string
+	for name, s := range ctx.Subroutines {
+		if s.Decl != nil {
+			a.subroutines[name] = s.Decl
+			names = append(names, name)
+		}
+	}
+	// Functional subroutines are not followed on the call, then analyze them independently
+	for name, s := range ctx.Functions {
+		if s.Decl != nil {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	// Subroutines which are called from others are analyzed in the flow of callers
+	called := make(map[string]struct{})
+	for _, decl := range a.subroutines {
+		for _, n := range ast.NewTree(decl.Block).Nodes() {
+			if c, ok := n.(*ast.CallStatement); ok {
+				called[c.Subroutine.Value] = struct{}{}
+			}
+		}
+	}
+	for _, name := range names {
+		if _, ok := called[name]; ok {
+			continue
+		}
+		decl, ok := a.subroutines[name]
+		if !ok {
+			decl = ctx.Functions[name].Decl
+		}
+		a.subroutine(decl, initStates{})
+	}
+
+	var idents []*ast.Ident
+	for ident, state := range a.reads {
+		if state != initSet {
+			idents = append(idents, ident)
+		}
+	}
+	sort.Slice(idents, func(i, j int) bool {
+		return tokenLess(idents[i].GetMeta().Token, idents[j].GetMeta().Token)
+	})
+	for _, ident := range idents {
+		if a.reads[ident] == initNotSet {
+			l.Error(NotSetRead(ident.GetMeta(), ident.Value).Match(UNINITIALIZED_NOT_SET))
+		} else {
+			l.Error(MaybeNotSetRead(ident.GetMeta(), ident.Value).Match(UNINITIALIZED_MAYBE_NOT_SET))
+		}
+	}
+}
+
+func tokenLess(a, b token.Token) bool {
+	if a.File != b.File {
+		return a.File < b.File
+	}
+	if a.Line != b.Line {
+		return a.Line < b.Line
+	}
+	return a.Position < b.Position
+}
+
+// subroutine analyzes the subroutine with states of headers at the start,
+// and returns states of headers when the subroutine returns to the caller.
+func (a *initAnalysis) subroutine(decl *ast.SubroutineDeclaration, in initStates) initStates {
+	name := decl.Name.Value
+	a.stack[name] = struct{}{}
+	defer delete(a.stack, name)
+
+	frame := &initFrame{}
+	out := a.block(decl.Block, in.copy(), frame)
+	return headerStates(out.join(frame.returned))
+}
+
+// headerStates drops states of local variables which are not visible from the caller
+func headerStates(s initStates) initStates {
+	if s == nil {
+		return nil
+	}
+	c := make(initStates, len(s))
+	for k, v := range s {
+		if !strings.HasPrefix(k, "var.") {
+			c[k] = v
+		}
+	}
+	return c
+}
+
+func (a *initAnalysis) block(b *ast.BlockStatement, in initStates, frame *initFrame) initStates {
+	s := in
+	for _, stmt := range b.Statements {
+		if s == nil {
+			// Unreachable statements are reported by unreachable-code rule
+			return nil
+		}
+		s = a.statement(stmt, s, frame)
+	}
+	return s
+}
+
+func (a *initAnalysis) statement(stmt ast.Statement, s initStates, frame *initFrame) initStates {
+	switch t := stmt.(type) {
+	case *ast.DeclareStatement:
+		// Only STRING local variable is initialized as not set, other types have zero value
+		if t.ValueType != nil && t.ValueType.Value == "STRING" {
+			s[t.Name.Value] = initNotSet
+		}
+		return s
+	case *ast.SetStatement:
+		a.read(t.Value, s)
+		a.assign(t.Ident.Value, s)
+		return s
+	case *ast.AddStatement:
+		a.read(t.Value, s)
+		a.assign(t.Ident.Value, s)
+		return s
+	case *ast.UnsetStatement:
+		a.unset(t.Ident.Value, s)
+		return s
+	case *ast.RemoveStatement:
+		a.unset(t.Ident.Value, s)
+		return s
+	case *ast.BlockStatement:
+		return a.block(t, s, frame)
+	case *ast.IfStatement:
+		return a.ifStatement(t, s, frame)
+	case *ast.CallStatement:
+		decl, ok := a.subroutines[t.Subroutine.Value]
+		if !ok {
+			return s
+		}
+		// Recursive call or too many calls are not followed
+		if _, ok := a.stack[t.Subroutine.Value]; ok || a.budget == 0 {
+			return s
+		}
+		a.budget--
+		out := a.subroutine(decl, headerStates(s))
+		if out == nil {
+			return nil
+		}
+		// Restore local variables of the caller
+		for k, v := range s {
+			if strings.HasPrefix(k, "var.") {
+				out[k] = v
+			}
+		}
+		return out
+	case *ast.ReturnStatement:
+		a.read(stmt, s)
+		// Returning a state finishes the state-machine subroutine, returns to the caller otherwise
+		if t.ReturnExpression == nil {
+			frame.returned = frame.returned.join(s)
+		}
+		return nil
+	case *ast.ErrorStatement, *ast.RestartStatement:
+		a.read(stmt, s)
+		return nil
+	case *ast.GotoStatement, *ast.GotoDestinationStatement:
+		// Could not trace the flow, conservatively stop tracking all names
+		return initStates{}
+	default:
+		a.read(stmt, s)
+		return s
+	}
+}
+
+func (a *initAnalysis) ifStatement(stmt *ast.IfStatement, s initStates, frame *initFrame) initStates {
+	var out initStates
+	cur := s
+	for _, b := range stmt.Branches() {
+		if b.Condition == nil {
+			return out.join(a.block(b.Consequence, cur.copy(), frame))
+		}
+		// Reads in the condition are not reported because they are existence checks mostly,
+		// and the names which are checked to exist are set in the consequence.
+		in := cur.copy()
+		for _, name := range truthyNames(b.Condition) {
+			a.assign(name, in)
+		}
+		out = out.join(a.block(b.Consequence, in, frame))
+
+		cur = cur.copy()
+		for _, name := range falsyNames(b.Condition) {
+			a.assign(name, cur)
+		}
+	}
+	return out.join(cur)
+}
+
+// truthyNames returns names which are set when the condition is true
+func truthyNames(cond ast.Expression) []string {
+	switch t := cond.(type) {
+	case *ast.Ident:
+		return []string{t.Value}
+	case *ast.GroupedExpression:
+		return truthyNames(t.Right)
+	case *ast.PrefixExpression:
+		if t.Operator == "!" {
+			return falsyNames(t.Right)
+		}
+	case *ast.InfixExpression:
+		switch t.Operator {
+		case "&&":
+			return append(truthyNames(t.Left), truthyNames(t.Right)...)
+		case "==", "~":
+			// Comparison with a value succeeds only when the left is set
+			if v, ok := t.Left.(*ast.Ident); ok {
+				return []string{v.Value}
+			}
+		}
+	}
+	return nil
+}
+
+// falsyNames returns names which are set when the condition is false
+func falsyNames(cond ast.Expression) []string {
+	switch t := cond.(type) {
+	case *ast.GroupedExpression:
+		return falsyNames(t.Right)
+	case *ast.PrefixExpression:
+		if t.Operator == "!" {
+			return truthyNames(t.Right)
+		}
+	case *ast.InfixExpression:
+		if t.Operator == "||" {
+			return append(falsyNames(t.Left), falsyNames(t.Right)...)
+		}
+	}
+	return nil
+}
+
+// initKey returns the key of tracked name, header names are case-insensitive
+func initKey(name string) (string, bool) {
+	if strings.HasPrefix(name, "var.") {
+		return name, true
+	}
+	if strings.Contains(name, ".http.") {
+		return strings.ToLower(name), true
+	}
+	return "", false
+}
+
+func (a *initAnalysis) assign(name string, s initStates) {
+	key, ok := initKey(name)
+	if !ok {
+		return
+	}
+	delete(s, key)
+	// Assigning subfield like req.http.Cookie:session also sets the header
+	if index := strings.Index(key, ":"); index > 0 {
+		delete(s, key[:index])
+	}
+}
+
+func (a *initAnalysis) unset(name string, s initStates) {
+	key, ok := initKey(name)
+	if !ok {
+		return
+	}
+	s[key] = initNotSet
+	// Unsetting the header also unsets all subfields
+	for k := range s {
+		if strings.HasPrefix(k, key+":") {
+			s[k] = initNotSet
+		}
+	}
+}
+
+// read records states of local variables and headers which are read in the node.
+// Reads inside if expression are not recorded because they are guarded by the condition mostly.
+func (a *initAnalysis) read(node ast.Node, s initStates) {
+	tree := ast.NewTree(node)
+	for _, n := range tree.Nodes() {
+		ident, ok := n.(*ast.Ident)
+		if !ok {
+			continue
+		}
+		key, ok := initKey(ident.Value)
+		if !ok {
+			continue
+		}
+		if hasIfExpressionAncestor(tree, ident) {
+			continue
+		}
+		state, ok := s[key]
+		if !ok {
+			state = initSet
+		}
+		if prev, ok := a.reads[ident]; ok {
+			state = prev.join(state)
+		}
+		a.reads[ident] = state
+	}
+}
+
+func hasIfExpressionAncestor(tree *ast.Tree, node ast.Node) bool {
+	for _, p := range tree.Ancestors(node) {
+		if _, ok := p.(*ast.IfExpression); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,150 @@
+package linter
+
+import (
+	"github.com/ysugimoto/falco/ast"
+)
+
+// isTerminated returns true when the statement always leaves the current flow.
+// If statement is terminated when all branches including else are terminated.
+func isTerminated(stmt ast.Statement) bool {
+	switch t := stmt.(type) {
+	case *ast.ReturnStatement, *ast.ErrorStatement, *ast.RestartStatement, *ast.GotoStatement:
+		return true
+	case *ast.BlockStatement:
+		return isBlockTerminated(t)
+	case *ast.IfStatement:
+		if t.Alternative == nil || !isBlockTerminated(t.Alternative) {
+			return false
+		}
+		if !isBlockTerminated(t.Consequence) {
+			return false
+		}
+		for _, a := range t.Another {
+			if !isBlockTerminated(a.Consequence) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+func isBlockTerminated(block *ast.BlockStatement) bool {
+	for _, stmt := range block.Statements {
+		if isTerminated(stmt) {
+			return true
+		}
+	}
+	return false
+}
+
+// unreachableStatements returns the first statement of each unreachable region in the statements.
+// Goto destination makes following statements reachable again.
+// Statements which are jumped over by goto are intended to be skipped, so goto does not start the region.
+func unreachableStatements(statements []ast.Statement) map[ast.Statement]struct{} {
+	unreachable := make(map[ast.Statement]struct{})
+	var terminated, reported bool
+	for _, stmt := range statements {
+		switch stmt.(type) {
+		case *ast.GotoDestinationStatement:
+			terminated, reported = false, false
+			continue
+		case *ast.GotoStatement:
+			continue
+		case *ast.ReturnStatement:
+			// Return statement is commonly kept after error or restart as the end of state-machine subroutine
+			if terminated {
+				continue
+			}
+		}
+		if terminated && !reported {
+			unreachable[stmt] = struct{}{}
+			reported = true
+		}
+		if isTerminated(stmt) {
+			terminated = true
+		}
+	}
+	return unreachable
+}
+
+// unwrapCondition strips grouped expressions from the condition
+func unwrapCondition(exp ast.Expression) ast.Expression {
+	for {
+		g, ok := exp.(*ast.GroupedExpression)
+		if !ok {
+			return exp
+		}
+		exp = g.Right
+	}
+}
+
+// conditionKey returns normalized condition string and whether the condition is negated
+func conditionKey(exp ast.Expression) (string, bool) {
+	exp = unwrapCondition(exp)
+	if p, ok := exp.(*ast.PrefixExpression); ok && p.Operator == "!" {
+		key, negated := conditionKey(p.Right)
+		return key, !negated
+	}
+	return exp.String(), false
+}
+
+// lintUnreachableBranches reports else-if and else branches which are never executed.
+// The branch is unreachable when the same condition appears in earlier branch,
+// or earlier branches have both of the condition and its negation so they cover all cases.
+func (l *Linter) lintUnreachableBranches(stmt *ast.IfStatement) {
+	seen := make(map[string]bool)
+	var covered bool
+	add := func(exp ast.Expression) {
+		key, negated := conditionKey(exp)
+		if v, ok := seen[key]; ok && v != negated {
+			covered = true
+		}
+		seen[key] = negated
+	}
+	add(stmt.Condition)
+
+	for _, a := range stmt.Another {
+		key, negated := conditionKey(a.Condition)
+		if v, ok := seen[key]; covered || (ok && v == negated) {
+			l.Error(UnreachableBranch(a.GetMeta()).Match(UNREACHABLE_CODE))
+			continue
+		}
+		add(a.Condition)
+	}
+
+	if covered && stmt.Alternative != nil {
+		l.Error(UnreachableBranch(stmt.Alternative.GetMeta()).Match(UNREACHABLE_CODE))
+	}
+}
+
+// alwaysReturns returns true when all control-flow paths of the block end with return statement.
+// Goto destination could be reached from other path so the statements after the destination must return again.
+func alwaysReturns(block *ast.BlockStatement) bool {
+	var returned bool
+	for _, stmt := range block.Statements {
+		switch t := stmt.(type) {
+		case *ast.GotoDestinationStatement:
+			returned = false
+		case *ast.ReturnStatement:
+			returned = true
+		case *ast.BlockStatement:
+			returned = returned || alwaysReturns(t)
+		case *ast.IfStatement:
+			returned = returned || ifAlwaysReturns(t)
+		}
+	}
+	return returned
+}
+
+func ifAlwaysReturns(stmt *ast.IfStatement) bool {
+	if stmt.Alternative == nil || !alwaysReturns(stmt.Alternative) || !alwaysReturns(stmt.Consequence) {
+		return false
+	}
+	for _, a := range stmt.Another {
+		if !alwaysReturns(a.Consequence) {
+			return false
+		}
+	}
+	return true
+}
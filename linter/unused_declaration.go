@@ -0,0 +1,118 @@
+package linter
+
+import (
+	"github.com/ysugimoto/falco/ast"
+	"github.com/ysugimoto/falco/context"
+	"github.com/ysugimoto/falco/parser"
+	"github.com/ysugimoto/falco/types"
+)
+
+// liveDeclarations traces references from state-machine subroutines and Fastly conditions through the declarations
+// and returns reachable ones. Declaration which is referenced only from dead declarations,
+// e.g. subroutine is called only from unused subroutine, is not reachable.
+// Returns nil when no state-machine subroutine is declared, like linting partial VCL,
+// then declarations are checked by whether they are referenced or not.
+func liveDeclarations(ctx *context.Context, conditions []*ast.Condition) map[ast.Node]struct{} {
+	decls := make(map[string][]ast.Node)
+	add := func(name string, decl ast.Node) {
+		decls[name] = append(decls[name], decl)
+	}
+
+	var roots []ast.Node
+	for name, s := range ctx.Subroutines {
+		if s.Decl == nil {
+			continue
+		}
+		if isEntrySubroutine(name, ctx) {
+			roots = append(roots, s.Decl)
+		} else {
+			add(name, s.Decl)
+		}
+	}
+	if len(roots) == 0 {
+		return nil
+	}
+	for _, c := range conditions {
+		roots = append(roots, c)
+	}
+
+	for name, s := range ctx.Functions {
+		add(name, s.Decl)
+	}
+	for name, t := range ctx.Tables {
+		if t.Decl != nil {
+			add(name, t.Decl)
+		}
+	}
+	for name, a := range ctx.Acls {
+		if a.Decl != nil {
+			add(name, a.Decl)
+		}
+	}
+	for name, b := range ctx.Backends {
+		if b.DirectorDecl != nil {
+			add(name, b.DirectorDecl)
+		} else if b.BackendDecl != nil {
+			add(name, b.BackendDecl)
+		}
+	}
+	for name, p := range ctx.Penaltyboxes {
+		add(name, p.Decl)
+	}
+	for name, rc := range ctx.Ratecounters {
+		add(name, rc.Decl)
+	}
+
+	live := make(map[ast.Node]struct{})
+	queue := roots
+	for len(queue) > 0 {
+		decl := queue[0]
+		queue = queue[1:]
+		if _, ok := live[decl]; ok {
+			continue
+		}
+		live[decl] = struct{}{}
+
+		// All of references are identifiers: call statement target, function name,
+		// table name of table.lookup arguments, backend in director and table values and so on
+		for _, n := range ast.NewTree(decl).Nodes() {
+			ident, ok := n.(*ast.Ident)
+			if !ok {
+				continue
+			}
+			queue = append(queue, decls[ident.Value]...)
+		}
+	}
+	return live
+}
+
+// isEntrySubroutine returns true when the subroutine is called by the runtime
+func isEntrySubroutine(name string, ctx *context.Context) bool {
+	if context.IsFastlySubroutine(name) {
+		return true
+	}
+	if _, ok := varnish4SubroutineScopes[name]; ok && ctx.Dialect() == parser.DialectVarnish4 {
+		return true
+	}
+	return false
+}
+
+func (l *Linter) isLive(decl ast.Node) bool {
+	if l.live == nil {
+		return true
+	}
+	_, ok := l.live[decl]
+	return ok
+}
+
+func (l *Linter) isLiveBackend(b *types.Backend) bool {
+	switch {
+	case b.DirectorDecl != nil:
+		return l.isLive(b.DirectorDecl)
+	case b.BackendDecl != nil:
+		return l.isLive(b.BackendDecl)
+	default:
+		// External backend
+		return true
+	}
+}
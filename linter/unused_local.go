@@ -0,0 +1,206 @@
+package linter
+
+import (
+	"strings"
+
+	"github.com/ysugimoto/falco/ast"
+	"github.com/ysugimoto/falco/token"
+)
+
+// localLiveness analyzes local variables in the subroutine by backward liveness data-flow.
+// The store to the variable is dead when the value is never read afterwards on any path.
+type localLiveness struct {
+	declares map[string]*ast.DeclareStatement
+	names    []string
+	stores   map[string][]*ast.SetStatement
+	dead     map[*ast.SetStatement]struct{}
+}
+
+type liveSet map[string]struct{}
+
+func (s liveSet) copy() liveSet {
+	c := make(liveSet, len(s))
+	for k := range s {
+		c[k] = struct{}{}
+	}
+	return c
+}
+
+func (s liveSet) merge(o liveSet) liveSet {
+	for k := range o {
+		s[k] = struct{}{}
+	}
+	return s
+}
+
+func (l *Linter) lintUnusedLocalAssignments(decl *ast.SubroutineDeclaration) {
+	defer l.measure(UNUSED_LOCAL_VARIABLE)()
+	lv := &localLiveness{
+		declares: make(map[string]*ast.DeclareStatement),
+		stores:   make(map[string][]*ast.SetStatement),
+		dead:     make(map[*ast.SetStatement]struct{}),
+	}
+	for _, n := range ast.NewTree(decl.Block).Nodes() {
+		switch t := n.(type) {
+		case *ast.DeclareStatement:
+			lv.declares[t.Name.Value] = t
+			lv.names = append(lv.names, t.Name.Value)
+		case *ast.GotoStatement:
+			// Data-flow does not follow goto jumps, then stores could not be decided as dead
+			return
+		}
+	}
+	if len(lv.declares) == 0 {
+		return
+	}
+
+	// Local variables are dead at the end of subroutine
+	lv.block(decl.Block, liveSet{})
+
+	// Report in declaration order
+	for _, name := range lv.names {
+		d := lv.declares[name]
+		stores := lv.stores[name]
+		// Variable which is never assigned is reported by unused/variable rule
+		if len(stores) == 0 {
+			continue
+		}
+
+		var alive []*ast.SetStatement
+		for _, s := range stores {
+			if _, ok := lv.dead[s]; !ok {
+				alive = append(alive, s)
+			}
+		}
+		// Some values are read
+		if len(alive) > 0 {
+			for _, s := range stores {
+				if _, ok := lv.dead[s]; !ok {
+					continue
+				}
+				err := UnusedAssignment(s.GetMeta(), name)
+				if !hasFunctionCall(s.Value) {
+					err.Fix = &Fix{
+						Description: "remove the assignment",
+						Remove:      []ast.Statement{s},
+					}
+				}
+				l.Error(err.Match(UNUSED_LOCAL_VARIABLE))
+			}
+			continue
+		}
+
+		// All values are never read, remove variable entirely
+		err := UnreadVariable(d.GetMeta(), name)
+		fix := &Fix{
+			Description: "remove the variable declaration and assignments",
+			Remove:      []ast.Statement{d},
+		}
+		for _, s := range stores {
+			if hasFunctionCall(s.Value) {
+				fix = nil
+				break
+			}
+			fix.Remove = append(fix.Remove, s)
+		}
+		err.Fix = fix
+		l.Error(err.Match(UNUSED_LOCAL_VARIABLE))
+	}
+}
+
+// all returns live set which contains all declared variables
+func (lv *localLiveness) all() liveSet {
+	s := liveSet{}
+	for name := range lv.declares {
+		s[name] = struct{}{}
+	}
+	return s
+}
+
+// reads collects local variables which are read in the node
+func (lv *localLiveness) reads(node ast.Node) liveSet {
+	s := liveSet{}
+	if node == nil {
+		return s
+	}
+	for _, n := range ast.NewTree(node).Nodes() {
+		ident, ok := n.(*ast.Ident)
+		if !ok || !strings.HasPrefix(ident.Value, "var.") {
+			continue
+		}
+		if _, ok := lv.declares[ident.Value]; ok {
+			s[ident.Value] = struct{}{}
+		}
+	}
+	return s
+}
+
+// block returns live variables at the start of block with live variables at the end of block
+func (lv *localLiveness) block(b *ast.BlockStatement, out liveSet) liveSet {
+	live := out
+	for i := len(b.Statements) - 1; i >= 0; i-- {
+		live = lv.statement(b.Statements[i], live)
+	}
+	return live
+}
+
+func (lv *localLiveness) statement(stmt ast.Statement, out liveSet) liveSet {
+	switch t := stmt.(type) {
+	case *ast.DeclareStatement:
+		// declaration is not a store, the variable is initialized with zero value
+		return out
+	case *ast.SetStatement:
+		name := t.Ident.Value
+		if _, ok := lv.declares[name]; !ok {
+			return out.copy().merge(lv.reads(t.Value))
+		}
+		lv.stores[name] = append(lv.stores[name], t)
+		live := out.copy()
+		if _, ok := out[name]; !ok {
+			// Dead store, its own read for compound assignment does not make the variable live
+			lv.dead[t] = struct{}{}
+			return live.merge(lv.reads(t.Value))
+		}
+		delete(live, name)
+		// Check token type because literal of "+=" operator is "="
+		if t.Operator.Token.Type != token.ASSIGN {
+			live[name] = struct{}{}
+		}
+		return live.merge(lv.reads(t.Value))
+	case *ast.BlockStatement:
+		return lv.block(t, out)
+	case *ast.IfStatement:
+		// Evaluate else-if chain from the last branch
+		var live liveSet
+		if t.Alternative != nil {
+			live = lv.block(t.Alternative, out.copy())
+		} else {
+			live = out.copy()
+		}
+		for i := len(t.Another) - 1; i >= 0; i-- {
+			a := t.Another[i]
+			live = lv.block(a.Consequence, out.copy()).merge(live).merge(lv.reads(a.Condition))
+		}
+		return lv.block(t.Consequence, out.copy()).merge(live).merge(lv.reads(t.Condition))
+	case *ast.ReturnStatement, *ast.ErrorStatement, *ast.RestartStatement:
+		// Subroutine exits, local variables are dead after here
+		return lv.reads(stmt)
+	case *ast.GotoStatement, *ast.IncludeStatement:
+		// Could not trace the flow, conservatively treat all variables as live
+		return lv.all()
+	default:
+		return out.copy().merge(lv.reads(stmt))
+	}
+}
+
+func hasFunctionCall(exp ast.Expression) bool {
+	if exp == nil {
+		return false
+	}
+	for _, n := range ast.NewTree(exp).Nodes() {
+		if _, ok := n.(*ast.FunctionCallExpression); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,299 @@
+package linter
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/pkg/errors"
+	"github.com/ysugimoto/falco/ast"
+	"github.com/ysugimoto/falco/context"
+	"github.com/ysugimoto/falco/token"
+)
+
+// WasmModule is an instantiated WASM rule module.
+// Call invokes exported function with JSON input bytes and returns JSON output bytes,
+// memory allocation and string passing are runtime specific so it's hidden in the implementation.
+type WasmModule interface {
+	Call(function string, input []byte) ([]byte, error)
+	Close() error
+}
+
+// WasmEngine instantiates WASM rule module from .wasm file path.
+// falco runs modules on wazero by default, embedders could replace the engine via SetWasmEngine.
+type WasmEngine func(path string) (WasmModule, error)
+
+// Exported function names which WASM rule module must implement
+const (
+	WasmRuleMetaFunction  = "rule_meta"
+	WasmRuleCheckFunction = "check"
+)
+
+var (
+	wasmEngineMu sync.RWMutex
+	wasmEngine   WasmEngine
+)
+
+// SetWasmEngine sets WASM runtime to load WASM rule modules, nil restores the default wazero engine
+func SetWasmEngine(engine WasmEngine) {
+	wasmEngineMu.Lock()
+	defer wasmEngineMu.Unlock()
+
+	wasmEngine = engine
+}
+
+// WasmNode is serialized AST node which is passed to "check" function of WASM rule module.
+// Fields hold exported fields of the node in snake_case, child nodes are serialized as WasmNode recursively.
+// Source and Scope are set only for the checked node.
+type WasmNode struct {
+	Type     string         `json:"type"`
+	File     string         `json:"file"`
+	Line     int            `json:"line"`
+	Position int            `json:"position"`
+	Source   string         `json:"source,omitempty"`
+	Scope    string         `json:"scope,omitempty"`
+	Fields   map[string]any `json:"fields"`
+}
+
+// WasmToken is serialized token which appears in the node fields, e.g. tokens of BadNode
+type WasmToken struct {
+	Type     string `json:"type"`
+	Literal  string `json:"literal"`
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+	Position int    `json:"position"`
+}
+
+// WasmDiagnostic is returned from "check" function of WASM rule module.
+// Line and Position could be omitted, then the node position is used.
+type WasmDiagnostic struct {
+	Message  string `json:"message"`
+	Severity string `json:"severity"`
+	Line     int    `json:"line"`
+	Position int    `json:"position"`
+}
+
+type wasmRuleMeta struct {
+	Name     string `json:"name"`
+	Severity string `json:"severity"`
+	// Node types to check, empty means all nodes
+	Nodes []string `json:"nodes"`
+}
+
+// wasmRule adapts WASM rule module to CustomRule
+type wasmRule struct {
+	module WasmModule
+	meta   wasmRuleMeta
+	nodes  map[string]struct{}
+}
+
+// LoadWasmRule loads WASM rule module via the engine which is set by SetWasmEngine, or wazero by default
+func LoadWasmRule(path string) (CustomRule, error) {
+	wasmEngineMu.RLock()
+	engine := wasmEngine
+	wasmEngineMu.RUnlock()
+
+	if engine == nil {
+		engine = NewWazeroEngine()
+	}
+	module, err := engine(path)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return NewWasmRule(module)
+}
+
+// NewWasmRule creates CustomRule from instantiated WASM rule module
+func NewWasmRule(module WasmModule) (CustomRule, error) {
+	out, err := module.Call(WasmRuleMetaFunction, nil)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	r := &wasmRule{module: module}
+	if err := json.Unmarshal(out, &r.meta); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if r.meta.Name == "" {
+		return nil, errors.New("WASM rule module must return rule name from rule_meta")
+	}
+	if len(r.meta.Nodes) > 0 {
+		r.nodes = make(map[string]struct{})
+		for _, n := range r.meta.Nodes {
+			r.nodes[n] = struct{}{}
+		}
+	}
+	return r, nil
+}
+
+func (r *wasmRule) Name() Rule {
+	return Rule(r.meta.Name)
+}
+
+func (r *wasmRule) Severity() Severity {
+	return parseWasmSeverity(r.meta.Severity)
+}
+
+func (r *wasmRule) Check(node ast.Node, ctx *context.Context) []*LintError {
+	if r.nodes != nil {
+		if _, ok := r.nodes[wasmNodeType(node)]; !ok {
+			return nil
+		}
+	}
+
+	tok := node.GetMeta().Token
+	payload := serializeWasmNode(node)
+	payload.Source = node.String()
+	payload.Scope = context.ScopeString(ctx.Mode())
+	input, err := json.Marshal(payload)
+	if err != nil {
+		return []*LintError{wasmRuleError(tok, r.meta.Name, err)}
+	}
+	out, err := r.module.Call(WasmRuleCheckFunction, input)
+	if err != nil {
+		return []*LintError{wasmRuleError(tok, r.meta.Name, err)}
+	}
+
+	var diagnostics []WasmDiagnostic
+	if len(out) > 0 {
+		if err := json.Unmarshal(out, &diagnostics); err != nil {
+			return []*LintError{wasmRuleError(tok, r.meta.Name, err)}
+		}
+	}
+
+	errs := make([]*LintError, 0, len(diagnostics))
+	for _, d := range diagnostics {
+		t := tok
+		if d.Line > 0 {
+			t.Line, t.Position = d.Line, d.Position
+		}
+		errs = append(errs, &LintError{
+			Severity: parseWasmSeverity(d.Severity),
+			Token:    t,
+			Message:  d.Message,
+		})
+	}
+	return errs
+}
+
+func wasmNodeType(node ast.Node) string {
+	return reflect.Indirect(reflect.ValueOf(node)).Type().Name()
+}
+
+var (
+	wasmNodeInterface = reflect.TypeOf((*ast.Node)(nil)).Elem()
+	wasmTokenType     = reflect.TypeOf(token.Token{})
+)
+
+// serializeWasmNode converts AST node to WasmNode which keeps the node tree
+func serializeWasmNode(node ast.Node) *WasmNode {
+	w := &WasmNode{
+		Type:   wasmNodeType(node),
+		Fields: make(map[string]any),
+	}
+	if meta := node.GetMeta(); meta != nil {
+		w.File, w.Line, w.Position = meta.Token.File, meta.Token.Line, meta.Token.Position
+	}
+
+	v := reflect.Indirect(reflect.ValueOf(node))
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		// Meta is serialized as the node position
+		if !field.IsExported() || field.Anonymous {
+			continue
+		}
+		w.Fields[wasmFieldName(field.Name)] = serializeWasmValue(v.Field(i))
+	}
+	return w
+}
+
+func serializeWasmValue(v reflect.Value) any {
+	switch v.Kind() {
+	case reflect.Interface, reflect.Pointer:
+		if v.IsNil() {
+			return nil
+		}
+	}
+	if v.Type().Implements(wasmNodeInterface) {
+		return serializeWasmNode(v.Interface().(ast.Node)) // nolint:forcetypeassert
+	}
+	if v.Type() == wasmTokenType {
+		t := v.Interface().(token.Token) // nolint:forcetypeassert
+		return WasmToken{
+			Type:     string(t.Type),
+			Literal:  t.Literal,
+			File:     t.File,
+			Line:     t.Line,
+			Position: t.Position,
+		}
+	}
+
+	switch v.Kind() {
+	case reflect.Interface, reflect.Pointer:
+		return serializeWasmValue(v.Elem())
+	case reflect.Slice, reflect.Array:
+		values := make([]any, v.Len())
+		for i := range values {
+			values[i] = serializeWasmValue(v.Index(i))
+		}
+		return values
+	case reflect.Struct:
+		// Non-node values like comments
+		fields := make(map[string]any)
+		for i := 0; i < v.NumField(); i++ {
+			if field := v.Type().Field(i); field.IsExported() {
+				fields[wasmFieldName(field.Name)] = serializeWasmValue(v.Field(i))
+			}
+		}
+		return fields
+	case reflect.String:
+		return v.String()
+	case reflect.Bool:
+		return v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return v.Uint()
+	case reflect.Float32, reflect.Float64:
+		return v.Float()
+	}
+	return nil
+}
+
+// wasmFieldName converts Go field name to snake_case, e.g. ReturnType to return_type
+func wasmFieldName(name string) string {
+	var buf strings.Builder
+	for i, r := range name {
+		if unicode.IsUpper(r) {
+			// Keep acronym like CIDRs or IP in one word
+			if i > 0 && (!unicode.IsUpper(rune(name[i-1])) || (i+1 < len(name) && unicode.IsLower(rune(name[i+1])) && name[i+1] != 's')) {
+				buf.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		buf.WriteRune(r)
+	}
+	return buf.String()
+}
+
+func parseWasmSeverity(s string) Severity {
+	switch s {
+	case "error":
+		return ERROR
+	case "warning":
+		return WARNING
+	case "info":
+		return INFO
+	}
+	// Empty means rule default
+	return ""
+}
+
+func wasmRuleError(tok token.Token, name string, err error) *LintError {
+	return &LintError{
+		Severity: ERROR,
+		Token:    tok,
+		Message:  "WASM rule " + name + " failed: " + err.Error(),
+	}
+}
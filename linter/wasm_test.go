@@ -0,0 +1,195 @@
+package linter
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ysugimoto/falco/context"
+	"github.com/ysugimoto/falco/lexer"
+	"github.com/ysugimoto/falco/parser"
+)
+
+// fakeWasmModule behaves as WASM rule module which bans "legacy" backend
+type fakeWasmModule struct {
+	checked []WasmNode
+}
+
+func (m *fakeWasmModule) Call(function string, input []byte) ([]byte, error) {
+	switch function {
+	case WasmRuleMetaFunction:
+		return []byte(`{"name":"custom/banned-backend","severity":"warning","nodes":["BackendDeclaration"]}`), nil
+	case WasmRuleCheckFunction:
+		var node WasmNode
+		if err := json.Unmarshal(input, &node); err != nil {
+			return nil, err
+		}
+		m.checked = append(m.checked, node)
+		if wasmNodeName(node.Fields) == "legacy" {
+			return []byte(`[{"message":"Backend legacy is banned"}]`), nil
+		}
+		return []byte(`[]`), nil
+	}
+	return nil, nil
+}
+
+func (m *fakeWasmModule) Close() error {
+	return nil
+}
+
+// wasmNodeName returns value of name identifier from serialized node fields
+func wasmNodeName(fields map[string]any) string {
+	name, _ := fields["name"].(map[string]any)
+	nameFields, _ := name["fields"].(map[string]any)
+	value, _ := nameFields["value"].(string)
+	return value
+}
+
+func TestWasmRule(t *testing.T) {
+	input := `
+backend legacy {
+  .host = "example.com";
+}
+
+backend F_origin {
+  .host = "example.com";
+}
+
+sub vcl_recv {
+  #FASTLY RECV
+  set req.backend = legacy;
+  set req.backend = F_origin;
+}`
+	vcl, err := parser.New(lexer.NewFromString(input)).ParseVCL()
+	if err != nil {
+		t.Errorf("unexpected parser error: %s", err)
+		t.FailNow()
+	}
+
+	module := &fakeWasmModule{}
+	rule, err := NewWasmRule(module)
+	if err != nil {
+		t.Errorf("unexpected error: %s", err)
+		t.FailNow()
+	}
+	l := New(WithCustomRules(rule))
+	l.Lint(vcl, context.New())
+
+	if len(module.checked) != 2 {
+		t.Errorf("Expect only backend declarations are checked but got %d nodes", len(module.checked))
+	}
+	if len(l.Errors) != 1 {
+		t.Errorf("Expect one lint error but got %v", l.Errors)
+		return
+	}
+	le := l.Errors[0].(*LintError)
+	if le.Rule != "custom/banned-backend" || le.Severity != WARNING || le.Token.Line != 2 {
+		t.Errorf("Unexpected lint error: %s", le)
+	}
+
+	node := module.checked[0]
+	if node.Type != "BackendDeclaration" || node.Scope != "RECV" || !strings.HasPrefix(node.Source, "backend legacy") {
+		t.Errorf("Unexpected serialized node: %+v", node)
+	}
+	props, _ := node.Fields["properties"].([]any)
+	if len(props) != 1 {
+		t.Errorf("Expect one backend property but got %v", node.Fields["properties"])
+		return
+	}
+	prop, _ := props[0].(map[string]any)
+	if prop["type"] != "BackendProperty" || prop["line"] != float64(3) || prop["source"] != nil {
+		t.Errorf("Unexpected serialized child node: %v", prop)
+	}
+}
+
+func TestWasmFieldName(t *testing.T) {
+	tests := map[string]string{
+		"Name":           "name",
+		"ReturnType":     "return_type",
+		"HasParenthesis": "has_parenthesis",
+		"IP":             "ip",
+		"CIDRs":          "cidrs",
+	}
+	for in, expect := range tests {
+		if actual := wasmFieldName(in); actual != expect {
+			t.Errorf("Expect %s is converted to %s but got %s", in, expect, actual)
+		}
+	}
+}
+
+// wasmRuleBinary is a module which is equivalent to following WAT:
+//
+//	(module
+//	  (memory (export "memory") 1)
+//	  (global $heap (mut i32) (i32.const 1024))
+//	  (func (export "alloc") (param $size i32) (result i32)
+//	    global.get $heap
+//	    (global.set $heap (i32.add (global.get $heap) (local.get $size))))
+//	  (func (export "rule_meta") (result i64) (i64.const 79))
+//	  (func (export "check") (param i32 i32) (result i64) (i64.const 549755813922))
+//	  (data (i32.const 0) "{\"name\":\"custom/wasm-rule\",\"severity\":\"warning\",\"nodes\":[\"BackendDeclaration\"]}")
+//	  (data (i32.const 128) "[{\"message\":\"Backend is checked\"}]"))
+var wasmRuleBinary = []byte{
+	0x00, 0x61, 0x73, 0x6d, 0x01, 0x00, 0x00, 0x00, 0x01, 0x10, 0x03, 0x60, 0x01, 0x7f, 0x01, 0x7f,
+	0x60, 0x00, 0x01, 0x7e, 0x60, 0x02, 0x7f, 0x7f, 0x01, 0x7e, 0x03, 0x04, 0x03, 0x00, 0x01, 0x02,
+	0x05, 0x03, 0x01, 0x00, 0x01, 0x06, 0x07, 0x01, 0x7f, 0x01, 0x41, 0x80, 0x08, 0x0b, 0x07, 0x26,
+	0x04, 0x06, 0x6d, 0x65, 0x6d, 0x6f, 0x72, 0x79, 0x02, 0x00, 0x05, 0x61, 0x6c, 0x6c, 0x6f, 0x63,
+	0x00, 0x00, 0x09, 0x72, 0x75, 0x6c, 0x65, 0x5f, 0x6d, 0x65, 0x74, 0x61, 0x00, 0x01, 0x05, 0x63,
+	0x68, 0x65, 0x63, 0x6b, 0x00, 0x02, 0x0a, 0x1d, 0x03, 0x0b, 0x00, 0x23, 0x00, 0x23, 0x00, 0x20,
+	0x00, 0x6a, 0x24, 0x00, 0x0b, 0x05, 0x00, 0x42, 0xcf, 0x00, 0x0b, 0x09, 0x00, 0x42, 0xa2, 0x80,
+	0x80, 0x80, 0x80, 0x10, 0x0b, 0x0b, 0x7d, 0x02, 0x00, 0x41, 0x00, 0x0b, 0x4f, 0x7b, 0x22, 0x6e,
+	0x61, 0x6d, 0x65, 0x22, 0x3a, 0x22, 0x63, 0x75, 0x73, 0x74, 0x6f, 0x6d, 0x2f, 0x77, 0x61, 0x73,
+	0x6d, 0x2d, 0x72, 0x75, 0x6c, 0x65, 0x22, 0x2c, 0x22, 0x73, 0x65, 0x76, 0x65, 0x72, 0x69, 0x74,
+	0x79, 0x22, 0x3a, 0x22, 0x77, 0x61, 0x72, 0x6e, 0x69, 0x6e, 0x67, 0x22, 0x2c, 0x22, 0x6e, 0x6f,
+	0x64, 0x65, 0x73, 0x22, 0x3a, 0x5b, 0x22, 0x42, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x44, 0x65,
+	0x63, 0x6c, 0x61, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x22, 0x5d, 0x7d, 0x00, 0x41, 0x80, 0x01,
+	0x0b, 0x22, 0x5b, 0x7b, 0x22, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x22, 0x3a, 0x22, 0x42,
+	0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x20, 0x69, 0x73, 0x20, 0x63, 0x68, 0x65, 0x63, 0x6b, 0x65,
+	0x64, 0x22, 0x7d, 0x5d,
+}
+
+func TestLoadWasmRule(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rule.wasm")
+	if err := os.WriteFile(path, wasmRuleBinary, 0o644); err != nil {
+		t.Errorf("unexpected error: %s", err)
+		t.FailNow()
+	}
+	rule, err := LoadWasmRule(path)
+	if err != nil {
+		t.Errorf("unexpected error: %s", err)
+		t.FailNow()
+	}
+
+	vcl, err := parser.New(lexer.NewFromString(`
+backend F_origin {
+  .host = "example.com";
+}
+
+sub vcl_recv {
+  #FASTLY RECV
+  set req.backend = F_origin;
+}`)).ParseVCL()
+	if err != nil {
+		t.Errorf("unexpected parser error: %s", err)
+		t.FailNow()
+	}
+	l := New(WithCustomRules(rule))
+	l.Lint(vcl, context.New())
+
+	if len(l.Errors) != 1 {
+		t.Errorf("Expect one lint error but got %v", l.Errors)
+		return
+	}
+	le := l.Errors[0].(*LintError)
+	if le.Rule != "custom/wasm-rule" || le.Severity != WARNING || le.Message != "Backend is checked" || le.Token.Line != 2 {
+		t.Errorf("Unexpected lint error: %s", le)
+	}
+}
+
+func TestLoadWasmRuleNotFound(t *testing.T) {
+	if _, err := LoadWasmRule(filepath.Join(t.TempDir(), "rule.wasm")); err == nil {
+		t.Errorf("Expected error when WASM module does not exist")
+	}
+}
@@ -0,0 +1,118 @@
+package linter
+
+import (
+	"context"
+	"os"
+	"sync"
+
+	"github.com/pkg/errors"
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/api"
+	"github.com/tetratelabs/wazero/imports/wasi_snapshot_preview1"
+)
+
+// Exported function name which allocates input buffer in the module memory
+const wasmAllocFunction = "alloc"
+
+// wazeroModule is WASM rule module which runs on wazero runtime.
+// JSON input is written to the buffer which is allocated by "alloc(size i32) i32",
+// and exported functions return the output location packed as i64 of (pointer << 32 | length).
+type wazeroModule struct {
+	mu      sync.Mutex
+	runtime wazero.Runtime
+	module  api.Module
+}
+
+// NewWazeroEngine returns WasmEngine backed by wazero, the default engine of LoadWasmRule.
+// Modules could import WASI functions so that modules built by TinyGo or Rust wasm32-wasi target work.
+func NewWazeroEngine() WasmEngine {
+	return func(path string) (WasmModule, error) {
+		bin, err := os.ReadFile(path)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+
+		ctx := context.Background()
+		r := wazero.NewRuntime(ctx)
+		if _, err := wasi_snapshot_preview1.Instantiate(ctx, r); err != nil {
+			r.Close(ctx) // nolint:errcheck
+			return nil, errors.WithStack(err)
+		}
+		// Rule modules are built as reactor (e.g. -buildmode=c-shared) which initializes runtime in "_initialize"
+		config := wazero.NewModuleConfig().WithStartFunctions("_initialize")
+		module, err := r.InstantiateWithConfig(ctx, bin, config)
+		if err != nil {
+			r.Close(ctx) // nolint:errcheck
+			return nil, errors.WithStack(err)
+		}
+		if module.Memory() == nil {
+			r.Close(ctx) // nolint:errcheck
+			return nil, errors.New("WASM rule module must export memory")
+		}
+		return &wazeroModule{runtime: r, module: module}, nil
+	}
+}
+
+func (m *wazeroModule) Call(function string, input []byte) ([]byte, error) {
+	// Module memory is shared between calls so calls are serialized
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	ctx := context.Background()
+	fn := m.module.ExportedFunction(function)
+	if fn == nil {
+		return nil, errors.Errorf("WASM rule module does not export %s function", function)
+	}
+
+	var params []uint64
+	if input != nil {
+		ptr, err := m.write(ctx, input)
+		if err != nil {
+			return nil, err
+		}
+		params = []uint64{uint64(ptr), uint64(len(input))}
+	}
+
+	results, err := fn.Call(ctx, params...)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if len(results) != 1 {
+		return nil, errors.Errorf("%s function must return packed pointer and length as i64", function)
+	}
+
+	ptr, size := uint32(results[0]>>32), uint32(results[0])
+	if size == 0 {
+		return nil, nil
+	}
+	out, ok := m.module.Memory().Read(ptr, size)
+	if !ok {
+		return nil, errors.Errorf("%s function returned out of range memory, pointer=%d, length=%d", function, ptr, size)
+	}
+	// Read returns a view of the module memory which may be overwritten by following calls
+	return append([]byte(nil), out...), nil
+}
+
+// write copies input into the buffer which is allocated by the module
+func (m *wazeroModule) write(ctx context.Context, input []byte) (uint32, error) {
+	alloc := m.module.ExportedFunction(wasmAllocFunction)
+	if alloc == nil {
+		return 0, errors.Errorf("WASM rule module does not export %s function", wasmAllocFunction)
+	}
+	results, err := alloc.Call(ctx, uint64(len(input)))
+	if err != nil {
+		return 0, errors.WithStack(err)
+	}
+	if len(results) != 1 {
+		return 0, errors.Errorf("%s function must return pointer as i32", wasmAllocFunction)
+	}
+	ptr := uint32(results[0])
+	if !m.module.Memory().Write(ptr, input) {
+		return 0, errors.Errorf("%s function returned out of range memory, pointer=%d, length=%d", wasmAllocFunction, ptr, len(input))
+	}
+	return ptr, nil
+}
+
+func (m *wazeroModule) Close() error {
+	return errors.WithStack(m.runtime.Close(context.Background()))
+}
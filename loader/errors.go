@@ -0,0 +1,22 @@
+package loader
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ysugimoto/falco/token"
+)
+
+// CycleError is returned when include graph has a cycle
+type CycleError struct {
+	Token token.Token
+	// Chain is file names from the first file of the cycle to the file which is included again
+	Chain []string
+}
+
+func (e *CycleError) Error() string {
+	return fmt.Sprintf(
+		"Include cycle detected: %s, line: %d, position: %d",
+		strings.Join(e.Chain, " -> "), e.Token.Line, e.Token.Position,
+	)
+}
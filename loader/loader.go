@@ -0,0 +1,161 @@
+// Package loader parses a main VCL and all included files into a Program
+// which keeps per-file ASTs and the include graph.
+package loader
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/ysugimoto/falco/ast"
+	"github.com/ysugimoto/falco/lexer"
+	"github.com/ysugimoto/falco/parser"
+	"github.com/ysugimoto/falco/resolver"
+)
+
+const snippetPrefix = "snippet::"
+
+type Loader struct {
+	resolver resolver.Resolver
+	option   *Option
+
+	program *Program
+	// file names which are being loaded, used for cycle detection
+	stack []string
+}
+
+func New(r resolver.Resolver, opts ...OptionFunc) *Loader {
+	return &Loader{
+		resolver: r,
+		option:   collect(opts),
+	}
+}
+
+// Load is shorthand of New(r, opts...).Load()
+func Load(r resolver.Resolver, opts ...OptionFunc) (*Program, error) {
+	return New(r, opts...).Load()
+}
+
+// Load parses main VCL and all included files recursively
+func (l *Loader) Load() (*Program, error) {
+	main, err := l.resolver.MainVCL()
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	l.program = newProgram()
+	l.stack = nil
+	f, err := l.loadFile(main.Name, main.Data, false)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	l.program.Main = f
+
+	if l.option.ExpandIncludes {
+		l.program.VCL = &ast.VCL{Statements: expand(f)}
+	} else {
+		l.program.VCL = &ast.VCL{Statements: f.Statements}
+	}
+
+	return l.program, nil
+}
+
+func (l *Loader) loadFile(name, data string, snippet bool) (*File, error) {
+	f := &File{
+		Name:    name,
+		Snippet: snippet,
+	}
+	l.program.add(f)
+
+	p := parser.New(lexer.NewFromString(data, lexer.WithFile(name)), l.option.ParserOptions...)
+	if snippet {
+		statements, err := p.ParseSnippetVCL()
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		f.Statements = statements
+	} else {
+		vcl, err := p.ParseVCL()
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		f.Statements = vcl.Statements
+	}
+
+	l.stack = append(l.stack, name)
+	defer func() {
+		l.stack = l.stack[:len(l.stack)-1]
+	}()
+
+	root := &ast.VCL{Statements: f.Statements}
+	tree := ast.NewTree(root)
+	for _, node := range tree.Nodes() {
+		stmt, ok := node.(*ast.IncludeStatement)
+		if !ok {
+			continue
+		}
+		inc, err := l.loadInclude(stmt, !snippet && tree.Parent(stmt) == root)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		f.Includes = append(f.Includes, inc)
+	}
+
+	return f, nil
+}
+
+func (l *Loader) loadInclude(stmt *ast.IncludeStatement, isRoot bool) (*Include, error) {
+	inc := &Include{
+		Statement: stmt,
+		Root:      isRoot,
+	}
+
+	var name, data string
+	if strings.HasPrefix(stmt.Module.Value, snippetPrefix) {
+		snip, ok := l.findSnippet(strings.TrimPrefix(stmt.Module.Value, snippetPrefix))
+		if !ok {
+			return nil, fmt.Errorf("Snippet %s was not found among Fastly managed snippets", stmt.Module.Value)
+		}
+		name, data = stmt.Module.Value, snip
+	} else {
+		module, err := l.resolver.Resolve(stmt)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		name, data = module.Name, module.Data
+	}
+
+	for i := range l.stack {
+		if l.stack[i] == name {
+			return nil, &CycleError{
+				Token: stmt.GetMeta().Token,
+				Chain: append(append([]string{}, l.stack[i:]...), name),
+			}
+		}
+	}
+
+	// Duplicated include shares the parsed file
+	if f, ok := l.program.Files[name]; ok {
+		inc.File = f
+		inc.Duplicated = true
+		return inc, nil
+	}
+
+	f, err := l.loadFile(name, data, !isRoot)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	inc.File = f
+	return inc, nil
+}
+
+func (l *Loader) findSnippet(name string) (string, bool) {
+	if l.option.Snippets == nil {
+		return "", false
+	}
+	snip, ok := l.option.Snippets.IncludeSnippets[name]
+	if !ok {
+		return "", false
+	}
+	return snip.Data, true
+}
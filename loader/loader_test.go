@@ -0,0 +1,159 @@
+package loader
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	"github.com/ysugimoto/falco/ast"
+	"github.com/ysugimoto/falco/resolver"
+	"github.com/ysugimoto/falco/snippets"
+)
+
+func setupFiles(t *testing.T, files map[string]string) resolver.Resolver {
+	dir := t.TempDir()
+	for name, data := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(data), 0o644); err != nil {
+			t.Fatalf("Failed to write file: %s", err)
+		}
+	}
+	rs, err := resolver.NewFileResolvers(filepath.Join(dir, "main.vcl"), nil)
+	if err != nil {
+		t.Fatalf("Failed to create resolver: %s", err)
+	}
+	return rs[0]
+}
+
+func baseNames(names []string) []string {
+	ret := make([]string, len(names))
+	for i := range names {
+		ret[i] = filepath.Base(names[i])
+	}
+	return ret
+}
+
+func TestLoad(t *testing.T) {
+	rslv := setupFiles(t, map[string]string{
+		"main.vcl": `
+include "backends";
+include "recv";
+sub vcl_recv {
+	#FASTLY RECV
+	include "recv_body";
+	include "snippet::recv_snippet";
+}`,
+		"backends.vcl":  `backend F_origin { .host = "example.com"; }`,
+		"recv.vcl":      `include "backends";`,
+		"recv_body.vcl": `set req.http.Foo = "foo";`,
+	})
+
+	program, err := Load(rslv, WithSnippets(&snippets.Snippets{
+		IncludeSnippets: map[string]snippets.SnippetItem{
+			"recv_snippet": {Name: "recv_snippet", Data: `set req.http.Bar = "bar";`},
+		},
+	}))
+	if err != nil {
+		t.Fatalf("Unexpected error: %+v", err)
+	}
+
+	expect := []string{"main.vcl", "backends.vcl", "recv.vcl", "recv_body.vcl", "snippet::recv_snippet"}
+	if diff := cmp.Diff(expect, baseNames(program.FileNames())); diff != "" {
+		t.Errorf("Loaded files mismatch, diff=%s", diff)
+	}
+
+	main := program.Main
+	if len(main.Includes) != 4 {
+		t.Fatalf("Main must have 4 includes, got %d", len(main.Includes))
+	}
+	if !main.Includes[0].Root || main.Includes[2].Root {
+		t.Errorf("Include placement mismatch")
+	}
+	if !main.Includes[2].File.Snippet {
+		t.Errorf("Include inside subroutine must be parsed as snippet")
+	}
+
+	recv := main.Includes[1].File
+	if !recv.Includes[0].Duplicated || recv.Includes[0].File != main.Includes[0].File {
+		t.Errorf("Duplicated include must share the parsed file")
+	}
+	backends := main.Includes[0].File.Name
+	if diff := cmp.Diff([]string{"main.vcl", "recv.vcl"}, baseNames(program.Dependents(backends))); diff != "" {
+		t.Errorf("Dependents mismatch, diff=%s", diff)
+	}
+	if diff := cmp.Diff([]string{"backends.vcl"}, baseNames(program.Dependencies(recv.Name))); diff != "" {
+		t.Errorf("Dependencies mismatch, diff=%s", diff)
+	}
+}
+
+func TestLoadIncludeCycle(t *testing.T) {
+	rslv := setupFiles(t, map[string]string{
+		"main.vcl": `include "a";`,
+		"a.vcl":    `include "b";`,
+		"b.vcl":    `include "a";`,
+	})
+
+	_, err := Load(rslv)
+	var ce *CycleError
+	if !errors.As(err, &ce) {
+		t.Fatalf("Expected CycleError, got %v", err)
+	}
+	if diff := cmp.Diff([]string{"a.vcl", "b.vcl", "a.vcl"}, baseNames(ce.Chain)); diff != "" {
+		t.Errorf("Cycle chain mismatch, diff=%s", diff)
+	}
+}
+
+func TestLoadExpandIncludes(t *testing.T) {
+	files := map[string]string{
+		"main.vcl": `include "backends";
+sub vcl_recv {
+	#FASTLY RECV
+	include "recv_body";
+	if (req.http.Foo) {
+		include "recv_body";
+	}
+}`,
+		"backends.vcl":  `backend F_origin { .host = "example.com"; }`,
+		"recv_body.vcl": `set req.http.Foo = "foo";`,
+	}
+
+	t.Run("keep include statements", func(t *testing.T) {
+		program, err := Load(setupFiles(t, files))
+		if err != nil {
+			t.Fatalf("Unexpected error: %+v", err)
+		}
+		if len(program.VCL.Statements) != 2 {
+			t.Errorf("Include statement must be kept, got %d statements", len(program.VCL.Statements))
+		}
+	})
+
+	t.Run("expand include statements", func(t *testing.T) {
+		program, err := Load(setupFiles(t, files), WithExpandIncludes())
+		if err != nil {
+			t.Fatalf("Unexpected error: %+v", err)
+		}
+		stmts := program.VCL.Statements
+		if len(stmts) != 2 {
+			t.Fatalf("Expected 2 statements, got %d", len(stmts))
+		}
+		if _, ok := stmts[0].(*ast.BackendDeclaration); !ok {
+			t.Errorf("Root include must be expanded to backend declaration, got %T", stmts[0])
+		}
+		body := stmts[1].(*ast.SubroutineDeclaration).Block.Statements
+		if _, ok := body[0].(*ast.SetStatement); !ok {
+			t.Errorf("Include in subroutine must be expanded, got %T", body[0])
+		}
+		nested := body[1].(*ast.IfStatement).Consequence.Statements
+		if _, ok := nested[0].(*ast.SetStatement); !ok {
+			t.Errorf("Include in if statement must be expanded, got %T", nested[0])
+		}
+		if body[0] == nested[0] {
+			t.Errorf("Duplicated include must be expanded as distinct nodes")
+		}
+		// Per-file AST must not be modified
+		if len(program.Main.Statements) != 2 || len(program.Main.Includes) != 3 {
+			t.Errorf("Main file AST must be kept")
+		}
+	})
+}
@@ -0,0 +1,49 @@
+package loader
+
+import (
+	"github.com/ysugimoto/falco/parser"
+	"github.com/ysugimoto/falco/snippets"
+)
+
+type OptionFunc func(o *Option)
+
+// Option controls how the program is loaded
+type Option struct {
+	// Fastly managed snippets for "snippet::" inclusion
+	Snippets *snippets.Snippets
+	// Options which are passed to each file parser
+	ParserOptions []parser.OptionFunc
+	// If true, include statements are expanded into Program.VCL for interpretation.
+	// Otherwise they are kept as nodes for formatting and per-file linting
+	ExpandIncludes bool
+}
+
+// WithSnippets sets Fastly managed snippets to resolve "snippet::" inclusion
+func WithSnippets(s *snippets.Snippets) OptionFunc {
+	return func(o *Option) {
+		o.Snippets = s
+	}
+}
+
+// WithParserOptions passes options to each file parser
+func WithParserOptions(opts ...parser.OptionFunc) OptionFunc {
+	return func(o *Option) {
+		o.ParserOptions = append(o.ParserOptions, opts...)
+	}
+}
+
+// WithExpandIncludes expands include statements into Program.VCL
+func WithExpandIncludes() OptionFunc {
+	return func(o *Option) {
+		o.ExpandIncludes = true
+	}
+}
+
+func collect(opts []OptionFunc) *Option {
+	o := &Option{}
+
+	for i := range opts {
+		opts[i](o)
+	}
+	return o
+}
@@ -0,0 +1,121 @@
+package loader
+
+import (
+	"github.com/ysugimoto/falco/ast"
+)
+
+// File is a parsed VCL file of the program
+type File struct {
+	Name       string
+	Statements []ast.Statement
+	// Snippet is true when the file is included inside subroutine and parsed as snippet
+	Snippet bool
+	// Includes in the file, in order of appearance
+	Includes []*Include
+}
+
+// Include is an edge of include graph
+type Include struct {
+	Statement *ast.IncludeStatement
+	// File is included file, nil when the module is not resolved
+	File *File
+	// Root is true when the include statement is placed at root of the file
+	Root bool
+	// Duplicated is true when the file has already been included from another place
+	Duplicated bool
+}
+
+// Program is a main VCL and all included files with include graph
+type Program struct {
+	Main  *File
+	Files map[string]*File
+	// VCL is the AST of main file. Included statements are expanded into this AST
+	// when loaded with WithExpandIncludes(), otherwise include statements are kept as they are.
+	// Per-file ASTs are never modified by expansion.
+	VCL *ast.VCL
+
+	// file names in loaded order, main is the first
+	order []string
+}
+
+func newProgram() *Program {
+	return &Program{
+		Files: make(map[string]*File),
+	}
+}
+
+func (p *Program) add(f *File) {
+	p.Files[f.Name] = f
+	p.order = append(p.order, f.Name)
+}
+
+// FileNames returns all file names in loaded order, main file is the first
+func (p *Program) FileNames() []string {
+	return p.order
+}
+
+// Dependencies returns file names which are directly included from the file
+func (p *Program) Dependencies(name string) []string {
+	f, ok := p.Files[name]
+	if !ok {
+		return nil
+	}
+	var deps []string
+	for _, inc := range f.Includes {
+		if inc.File != nil {
+			deps = append(deps, inc.File.Name)
+		}
+	}
+	return deps
+}
+
+// Dependents returns file names which directly include the file
+func (p *Program) Dependents(name string) []string {
+	var deps []string
+	for _, n := range p.order {
+		for _, inc := range p.Files[n].Includes {
+			if inc.File != nil && inc.File.Name == name {
+				deps = append(deps, n)
+				break
+			}
+		}
+	}
+	return deps
+}
+
+// expand returns cloned statements of the file which include statements are replaced with included statements
+func expand(f *File) []ast.Statement {
+	cloned := ast.Clone(&ast.VCL{Statements: f.Statements})
+	tree := ast.NewTree(cloned)
+
+	// Include statements appear in the same order as File.Includes because cloned tree has the same structure
+	includes := make(map[*ast.IncludeStatement]*Include)
+	var blocks []*ast.BlockStatement
+	for _, node := range tree.Nodes() {
+		switch t := node.(type) {
+		case *ast.IncludeStatement:
+			includes[t] = f.Includes[len(includes)]
+		case *ast.BlockStatement:
+			blocks = append(blocks, t)
+		}
+	}
+	if len(includes) == 0 {
+		return cloned.Statements
+	}
+
+	replace := func(statements []ast.Statement) []ast.Statement {
+		var ret []ast.Statement
+		for _, stmt := range statements {
+			if inc, ok := stmt.(*ast.IncludeStatement); ok {
+				ret = append(ret, expand(includes[inc].File)...)
+				continue
+			}
+			ret = append(ret, stmt)
+		}
+		return ret
+	}
+	for _, b := range blocks {
+		b.Statements = replace(b.Statements)
+	}
+	return replace(cloned.Statements)
+}
@@ -433,8 +433,11 @@ func (p *Parser) parseSubroutineDeclaration() (*ast.SubroutineDeclaration, error
 
 	// Custom subroutines might be returning a type
 	// https://developer.fastly.com/reference/vcl/subroutines/
-	// we dont need to validate the type here, linter will do that later.
+	// Return type must be one of VCL types, otherwise it is a syntax error.
 	if p.expectPeek(token.IDENT) {
+		if !isValidReturnType(p.curToken.Token.Literal) {
+			return nil, errors.WithStack(InvalidReturnType(p.curToken))
+		}
 		s.ReturnType = p.parseIdent()
 	}
 
@@ -497,3 +500,35 @@ func (p *Parser) parseRatecounterDeclaration() (*ast.RatecounterDeclaration, err
 
 	return r, nil
 }
+
+func (p *Parser) parseGenericProperty() (*ast.GenericProperty, error) {
+	prop := &ast.GenericProperty{
+		Meta: p.curToken,
+	}
+
+	if !p.expectPeek(token.IDENT) {
+		return nil, errors.WithStack(UnexpectedToken(p.peekToken, "IDENT"))
+	}
+	prop.Key = p.parseIdent()
+
+	if !p.expectPeek(token.ASSIGN) {
+		return nil, errors.WithStack(UnexpectedToken(p.peekToken, "ASSIGN"))
+	}
+	swapLeadingTrailing(p.curToken, prop.Key.Meta)
+
+	p.nextToken() // point to expression start token
+
+	exp, err := p.parseExpression(LOWEST)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	prop.Value = exp
+
+	if !p.peekTokenIs(token.SEMICOLON) {
+		return nil, errors.WithStack(MissingSemicolon(p.curToken))
+	}
+	prop.Meta.Trailing = p.trailing()
+	p.nextToken() // point to SEMICOLON
+
+	return prop, nil
+}
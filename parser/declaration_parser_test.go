@@ -434,3 +434,74 @@ func TestParseRatecounter(t *testing.T) {
 	}
 	assert(t, vcl, expect)
 }
+
+func TestParseFunctionalSubroutine(t *testing.T) {
+	t.Run("valid return type", func(t *testing.T) {
+		input := `sub custom_sub STRING {
+	return "x";
+}`
+		vcl, err := New(lexer.NewFromString(input)).ParseVCL()
+		if err != nil {
+			t.Errorf("%+v\n", err)
+			return
+		}
+		sub, ok := vcl.Statements[0].(*ast.SubroutineDeclaration)
+		if !ok {
+			t.Errorf("Expected SubroutineDeclaration, got %T", vcl.Statements[0])
+			return
+		}
+		if sub.ReturnType == nil || sub.ReturnType.Value != "STRING" {
+			t.Errorf("Expected return type STRING, got %v", sub.ReturnType)
+		}
+	})
+
+	t.Run("invalid return type", func(t *testing.T) {
+		input := `sub custom_sub FOO {
+	return "x";
+}`
+		_, err := New(lexer.NewFromString(input)).ParseVCL()
+		if err == nil {
+			t.Errorf("Expected parse error but got nil")
+		}
+	})
+
+	for _, typ := range []string{"ID", "ACL"} {
+		t.Run("unsupported return type "+typ, func(t *testing.T) {
+			input := `sub custom_sub ` + typ + ` {
+	return "x";
+}`
+			_, err := New(lexer.NewFromString(input)).ParseVCL()
+			if err == nil {
+				t.Errorf("Expected parse error but got nil")
+			}
+		})
+	}
+}
+
+func TestParseTolerantProperties(t *testing.T) {
+	input := `ratecounter counter_60 {
+	.new_field = 10;
+}`
+	t.Run("strict mode", func(t *testing.T) {
+		if _, err := New(lexer.NewFromString(input)).ParseVCL(); err == nil {
+			t.Errorf("Expected parse error but got nil")
+		}
+	})
+
+	t.Run("tolerant mode", func(t *testing.T) {
+		vcl, err := New(lexer.NewFromString(input), WithTolerantProperties()).ParseVCL()
+		if err != nil {
+			t.Errorf("%+v\n", err)
+			return
+		}
+		rc := vcl.Statements[0].(*ast.RatecounterDeclaration)
+		prop, ok := rc.Block.Statements[0].(*ast.GenericProperty)
+		if !ok {
+			t.Errorf("Expected GenericProperty, got %T", rc.Block.Statements[0])
+			return
+		}
+		if prop.Key.Value != "new_field" {
+			t.Errorf("Unexpected property key %s", prop.Key.Value)
+		}
+	})
+}
@@ -5,12 +5,15 @@ import (
 	"strings"
 
 	"github.com/ysugimoto/falco/ast"
+	"github.com/ysugimoto/falco/diagnostics"
 	"github.com/ysugimoto/falco/token"
 )
 
 type ParseError struct {
 	Token   token.Token
 	Message string
+	// Hint is an optional short description how to fix the error
+	Hint string
 }
 
 func (e *ParseError) Error() string {
@@ -28,21 +31,87 @@ func (e *ParseError) ErrorToken() token.Token {
 	return e.Token
 }
 
+// Diagnostic converts the error to render code frame
+func (e *ParseError) Diagnostic() *diagnostics.Diagnostic {
+	return &diagnostics.Diagnostic{
+		Severity: diagnostics.ERROR,
+		Message:  e.Message,
+		Token:    e.Token,
+		Hint:     e.Hint,
+	}
+}
+
+// Kinds of input limits
+const (
+	LimitInputBytes = "input bytes"
+	LimitTokens     = "tokens"
+)
+
+// LimitError is returned when the input exceeds the limits set by WithMaxInputBytes or WithMaxTokens.
+// Parsing is aborted immediately even in recovery mode.
+type LimitError struct {
+	Token token.Token
+	Kind  string
+	Limit int64
+}
+
+func (e *LimitError) Error() string {
+	var file string
+	if e.Token.File != "" {
+		file = " at " + e.Token.File
+	}
+	return fmt.Sprintf(
+		"Limit Error: %s exceeds the limit of %d%s, line: %d, position: %d",
+		e.Kind, e.Limit, file, e.Token.Line, e.Token.Position,
+	)
+}
+
+func (e *LimitError) ErrorToken() token.Token {
+	return e.Token
+}
+
+// PanicError is returned when the parser recovers from unexpected panic as the last resort.
+// It means a bug of the parser, Stack holds the stack trace at the panic to report it.
+type PanicError struct {
+	Token token.Token
+	Value interface{}
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	var file string
+	if e.Token.File != "" {
+		file = " at " + e.Token.File
+	}
+	return fmt.Sprintf(
+		"Parse Error: Unexpected parser failure: %v%s, line: %d, position: %d\n%s",
+		e.Value, file, e.Token.Line, e.Token.Position, e.Stack,
+	)
+}
+
+func (e *PanicError) ErrorToken() token.Token {
+	return e.Token
+}
+
 func MissingSemicolon(m *ast.Meta) *ParseError {
 	return &ParseError{
 		Token:   m.Token,
 		Message: "Missing semicolon",
+		Hint:    `add ";" after this`,
 	}
 }
 
 func UnexpectedToken(m *ast.Meta, expects ...string) *ParseError {
 	message := fmt.Sprintf(`Unexpected token "%s"`, m.Token.Literal)
+	var hint string
 	if len(expects) > 0 {
-		message += fmt.Sprintf(`, expects %s`, strings.Join(expects, " or "))
+		hint = fmt.Sprintf(`expects %s`, strings.Join(expects, " or "))
+		message += ", " + hint
 	}
 	return &ParseError{
 		Token:   m.Token,
 		Message: message,
+		Hint:    hint,
 	}
 }
 
@@ -59,3 +128,78 @@ func TypeConversionError(m *ast.Meta, tt string) *ParseError {
 		Message: fmt.Sprintf("Failed type conversion for token %s to %s ", m.Token.Literal, tt),
 	}
 }
+
+func InvalidReturnType(m *ast.Meta) *ParseError {
+	return &ParseError{
+		Token:   m.Token,
+		Message: fmt.Sprintf(`Invalid subroutine return type "%s"`, m.Token.Literal),
+	}
+}
+
+func TooDeepNesting(m *ast.Meta, max int) *ParseError {
+	return &ParseError{
+		Token:   m.Token,
+		Message: fmt.Sprintf("Nesting depth exceeds the limit of %d", max),
+	}
+}
+
+func TooManyStatements(m *ast.Meta, max int) *ParseError {
+	return &ParseError{
+		Token:   m.Token,
+		Message: fmt.Sprintf("Statement count exceeds the limit of %d", max),
+	}
+}
+
+func UnknownImportModule(m *ast.Meta) *ParseError {
+	return &ParseError{
+		Token:   m.Token,
+		Message: fmt.Sprintf(`Unknown import module "%s"`, m.Token.Literal),
+	}
+}
+
+func UnknownVariableType(m *ast.Meta, name string) *ParseError {
+	return &ParseError{
+		Token:   m.Token,
+		Message: fmt.Sprintf(`Unknown variable type "%s"`, name),
+	}
+}
+
+func UnterminatedString(t token.Token) *ParseError {
+	return &ParseError{
+		Token:   t,
+		Message: "Unterminated string literal",
+		Hint:    `add closing '"' for the string which starts here`,
+	}
+}
+
+func UnterminatedLongString(t token.Token) *ParseError {
+	return &ParseError{
+		Token:   t,
+		Message: "Unterminated long string literal",
+		Hint:    `add closing '"}' for the string which starts here`,
+	}
+}
+
+func UnterminatedComment(t token.Token) *ParseError {
+	return &ParseError{
+		Token:   t,
+		Message: "Unterminated block comment",
+		Hint:    `add closing '*/' for the comment which starts here`,
+	}
+}
+
+func UnterminatedPlaceholder(t token.Token) *ParseError {
+	return &ParseError{
+		Token:   t,
+		Message: "Unterminated template placeholder",
+		Hint:    "add closing delimiter for the placeholder which starts here",
+	}
+}
+
+func UnterminatedBlock(m *ast.Meta) *ParseError {
+	return &ParseError{
+		Token:   m.Token,
+		Message: "Unterminated block",
+		Hint:    `add closing '}' for the block which starts here`,
+	}
+}
@@ -44,7 +44,10 @@ func (p *Parser) registerExpressionParsers() {
 }
 
 // Expose global function to be called externally
-func (p *Parser) ParseExpression(precedence int) (ast.Expression, error) {
+func (p *Parser) ParseExpression(precedence int) (_ ast.Expression, err error) {
+	defer p.reportInputErrors(&err)
+	defer p.recoverPanic(&err)
+
 	return p.parseExpression(precedence)
 }
 
@@ -57,13 +60,24 @@ func (p *Parser) parseExpression(precedence int) (ast.Expression, error) {
 	//   # Some line comment here // trim this line
 	//   req.http,Bar
 	// ) { ... }
-	prefix, ok := p.prefixParsers[p.curToken.Token.Type]
-	if !ok {
-		return nil, errors.WithStack(UndefinedPrefix(p.curToken))
+	if err := p.enterNest(); err != nil {
+		return nil, errors.WithStack(err)
 	}
+	defer p.leaveNest()
 
-	left, err := prefix()
-	if err != nil {
+	var left ast.Expression
+	var err error
+	prefix, ok := p.prefixParsers[p.curToken.Token.Type]
+	if !ok {
+		if !p.canRecoverExpression() {
+			return nil, errors.WithStack(UndefinedPrefix(p.curToken))
+		}
+		p.errors = append(p.errors, errors.WithStack(UndefinedPrefix(p.curToken)))
+		left = &ast.BadExpression{
+			Meta:   p.curToken,
+			Tokens: []token.Token{p.curToken.Token},
+		}
+	} else if left, err = prefix(); err != nil {
 		return nil, errors.WithStack(err)
 	}
 
@@ -115,10 +129,27 @@ func (p *Parser) parseGroupedExpression() (*ast.GroupedExpression, error) {
 	if !p.expectPeek(token.RIGHT_PAREN) {
 		return nil, errors.WithStack(UnexpectedToken(p.peekToken, "RIGHT_PAREN"))
 	}
+	// Comments before RIGHT_PAREN belong to the inner expression
+	appendLeadingTrailing(p.curToken, exp.Right.GetMeta())
 
 	return exp, nil
 }
 
+// parseConditionExpression parses the condition of if statement and if expression.
+// Assignment operator in the condition is a common mistake of "==" operator,
+// so it is parsed as infix expression and the linter reports it instead of parse error.
+func (p *Parser) parseConditionExpression() (ast.Expression, error) {
+	cond, err := p.parseExpression(LOWEST)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if !p.peekTokenIs(token.ASSIGN) {
+		return cond, nil
+	}
+	p.nextToken() // point to assignment operator
+	return p.parseInfixExpression(cond)
+}
+
 func (p *Parser) parseIfExpression() (*ast.IfExpression, error) {
 	exp := &ast.IfExpression{
 		Meta: p.curToken,
@@ -129,7 +160,7 @@ func (p *Parser) parseIfExpression() (*ast.IfExpression, error) {
 	}
 
 	p.nextToken() // point to condition expression start
-	cond, err := p.parseExpression(LOWEST)
+	cond, err := p.parseConditionExpression()
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
@@ -187,9 +218,13 @@ func (p *Parser) parseInfixStringConcatExpression(left ast.Expression) (ast.Expr
 		// But we explicitly define as "+" operator to make clearly
 		Operator: "+",
 		Left:     left,
+		Implicit: !p.curTokenIs(token.PLUS),
 	}
 
 	precedence := p.curPrecedence()
+	if !exp.Implicit {
+		p.nextToken() // point to right expression start
+	}
 	right, err := p.parseExpression(precedence)
 	if err != nil {
 		return nil, errors.WithStack(err)
@@ -202,7 +237,7 @@ func (p *Parser) parseInfixStringConcatExpression(left ast.Expression) (ast.Expr
 func (p *Parser) parseFunctionCallExpression(fn ast.Expression) (ast.Expression, error) {
 	ident, ok := fn.(*ast.Ident)
 	if !ok {
-		return nil, errors.New("Function name must be IDENT")
+		return nil, errors.WithStack(UnexpectedToken(p.curToken, "IDENT"))
 	}
 	exp := &ast.FunctionCallExpression{
 		Meta:     p.curToken,
@@ -246,6 +281,8 @@ func (p *Parser) parseFunctionArgumentExpressions() ([]ast.Expression, error) {
 	if !p.expectPeek(token.RIGHT_PAREN) {
 		return nil, errors.WithStack(UnexpectedToken(p.peekToken, "RIGHT_PAREN"))
 	}
+	// Comments before RIGHT_PAREN belong to the last argument
+	appendLeadingTrailing(p.curToken, list[len(list)-1].GetMeta())
 
 	return list, nil
 }
@@ -88,6 +88,7 @@ sub vcl_recv {
 							Value: &ast.InfixExpression{
 								Meta:     ast.New(T, 1),
 								Operator: "+",
+								Implicit: true,
 								Right: &ast.String{
 									Meta:  ast.New(T, 1),
 									Value: "baz",
@@ -95,6 +96,7 @@ sub vcl_recv {
 								Left: &ast.InfixExpression{
 									Meta:     ast.New(T, 1),
 									Operator: "+",
+									Implicit: true,
 									Left: &ast.String{
 										Meta:  ast.New(T, 1),
 										Value: "foo bar",
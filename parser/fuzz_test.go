@@ -0,0 +1,118 @@
+package parser
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/ysugimoto/falco/ast"
+	"github.com/ysugimoto/falco/lexer"
+	"github.com/ysugimoto/falco/token"
+)
+
+func FuzzParseVCL(f *testing.F) {
+	seeds := []string{
+		`sub vcl_recv { set req.http.Foo = "bar"; }`,
+		`acl internal { "192.168.0.1"/32; !"10.0.0.1"; }`,
+		`backend example { .host = "example.com"; .probe = { .request = "GET / HTTP/1.1"; } }`,
+		`director d random { .quorum = 50%; { .backend = example; .weight = 1; } }`,
+		`table t STRING { "foo": "bar", }`,
+		`sub vcl_recv { if (req.http.Foo ~ "bar" && !req.http.Baz) { esi; } else if (req.http.A) { return(pass); } }`,
+		`sub custom STRING { return "x"; }`,
+		`sub vcl_recv { declare local var.s STRING; set var.s = if(req.http.X, "a", "b"); }`,
+		`sub vcl_recv { set req.http.Foo = `,
+		`sub vcl_recv { if (`,
+		`backend `,
+		`(`,
+	}
+	for _, s := range seeds {
+		f.Add(s)
+	}
+
+	f.Fuzz(func(t *testing.T, input string) {
+		// Parser must return an error for broken input, recovering from panic means a parser bug
+		_, err := New(lexer.NewFromString(input)).ParseVCL()
+		var pe *PanicError
+		if errors.As(err, &pe) {
+			t.Fatalf("Parser panicked for input %q: %s", input, pe)
+		}
+	})
+}
+
+func FuzzParseSnippetVCL(f *testing.F) {
+	seeds := []string{
+		`set req.http.Foo = "bar";`,
+		`if (req.http.Foo) { esi; } else { return(pass); }`,
+		`declare local var.s STRING; set var.s = if(req.http.X, "a", "b");`,
+		`set req.http.Foo = `,
+		`if (`,
+		`}`,
+	}
+	for _, s := range seeds {
+		f.Add(s)
+	}
+
+	f.Fuzz(func(t *testing.T, input string) {
+		_, err := New(lexer.NewFromString(input)).ParseSnippetVCL()
+		var pe *PanicError
+		if errors.As(err, &pe) {
+			t.Fatalf("Parser panicked for input %q: %s", input, pe)
+		}
+	})
+}
+
+func FuzzParseCondition(f *testing.F) {
+	seeds := []string{
+		`req.http.Foo == "bar"`,
+		`!req.http.Foo && (req.url ~ "^/api" || std.strlen(req.url) > 10)`,
+		`req.http.Foo ==`,
+		`(`,
+	}
+	for _, s := range seeds {
+		f.Add(s)
+	}
+
+	f.Fuzz(func(t *testing.T, input string) {
+		_, err := New(lexer.NewFromString(input)).ParseCondition(ast.RequestCondition)
+		var pe *PanicError
+		if errors.As(err, &pe) {
+			t.Fatalf("Parser panicked for input %q: %s", input, pe)
+		}
+	})
+}
+
+func TestParseBrokenInputReturnsError(t *testing.T) {
+	inputs := []string{
+		`sub vcl_recv { set req.http.Foo = `,
+		`sub vcl_recv { if (`,
+		`sub vcl_recv { set req.http.Foo = ("a")("b"); }`,
+		`backend `,
+		`acl foo { "192.168.0.1"/`,
+		`(`,
+	}
+	for _, input := range inputs {
+		_, err := New(lexer.NewFromString(input)).ParseVCL()
+		if err == nil {
+			t.Errorf("Expected error for input %q but got nil", input)
+		}
+		var pe *PanicError
+		if errors.As(err, &pe) {
+			t.Errorf("Error should be returned without panic for input %q: %s", input, pe)
+		}
+	}
+}
+
+func TestRecoverPanicRecordsStack(t *testing.T) {
+	p := New(lexer.NewFromString(`sub vcl_recv { set req.http.Foo = bar; }`))
+	p.prefixParsers[token.IDENT] = func() (ast.Expression, error) {
+		panic("boom")
+	}
+	_, err := p.ParseVCL()
+	var pe *PanicError
+	if !errors.As(err, &pe) {
+		t.Fatalf("Expected PanicError but got %v", err)
+	}
+	if pe.Value != "boom" || !strings.Contains(string(pe.Stack), "TestRecoverPanicRecordsStack") {
+		t.Errorf("Stack trace at the panic should be recorded: %s", pe.Stack)
+	}
+}
@@ -43,6 +43,53 @@ var assignmentOperatorLiterals = []string{
 	"||=",
 }
 
+// Valid VCL types which could be declared as functional subroutine return type
+// https://developer.fastly.com/reference/vcl/subroutines/#returning-a-value
+var returnTypes = map[string]struct{}{
+	"BOOL":    {},
+	"INTEGER": {},
+	"FLOAT":   {},
+	"STRING":  {},
+	"IP":      {},
+	"RTIME":   {},
+	"TIME":    {},
+	"BACKEND": {},
+}
+
+func isValidReturnType(name string) bool {
+	_, ok := returnTypes[name]
+	return ok
+}
+
+// Valid VCL types which could be declared as local variable, ACL and ID are available in addition to return types
+// https://developer.fastly.com/reference/vcl/variables/#user-defined-variables
+var localVariableTypes = map[string]struct{}{
+	"BOOL":    {},
+	"INTEGER": {},
+	"FLOAT":   {},
+	"STRING":  {},
+	"IP":      {},
+	"RTIME":   {},
+	"TIME":    {},
+	"ACL":     {},
+	"BACKEND": {},
+	"ID":      {},
+}
+
+func isValidLocalVariableType(name string) bool {
+	_, ok := localVariableTypes[name]
+	return ok
+}
+
+// Import module is valid when no modules are registered, keeps backward compatibility
+func (p *Parser) isKnownImportModule(name string) bool {
+	if len(p.option.ImportModules) == 0 {
+		return true
+	}
+	_, ok := p.option.ImportModules[name]
+	return ok
+}
+
 func isAssignmentOperator(t token.Token) bool {
 	if _, ok := assignmentOperators[t.Type]; ok {
 		return true
@@ -61,6 +108,12 @@ func swapLeadingInfix(from, to *ast.Meta) {
 	from.Leading = ast.Comments{}
 }
 
+// Move leading comments of closing token (e.g. RIGHT_PAREN) to trailing comments of the node
+func appendLeadingTrailing(from, to *ast.Meta) {
+	to.Trailing = append(to.Trailing, from.Leading...)
+	from.Leading = ast.Comments{}
+}
+
 func clearComments(m *ast.Meta) *ast.Meta {
 	mm := *m
 	mm.Leading = ast.Comments{}
@@ -0,0 +1,92 @@
+package parser
+
+import (
+	"github.com/ysugimoto/falco/ast"
+	"github.com/ysugimoto/falco/token"
+)
+
+// PrefixParseFunc parses the prefix expression which is registered by WithPrefixOperator.
+// On call, current token points to the operator token and
+// the function must leave current token at the end of the expression.
+type PrefixParseFunc func(p *Parser) (ast.Expression, error)
+
+// InfixParseFunc parses the infix expression which is registered by WithInfixOperator.
+// On call, current token points to the operator token and
+// the function must leave current token at the end of the expression.
+type InfixParseFunc func(p *Parser, left ast.Expression) (ast.Expression, error)
+
+type prefixOperator struct {
+	fn PrefixParseFunc
+}
+
+type infixOperator struct {
+	precedence int
+	fn         InfixParseFunc
+}
+
+// WithPrefixOperator registers additional prefix operator for the token type.
+// When fn is nil, the operator is parsed as ast.PrefixExpression with the token literal.
+func WithPrefixOperator(t token.TokenType, fn PrefixParseFunc) OptionFunc {
+	return func(o *Option) {
+		if o.prefixOperators == nil {
+			o.prefixOperators = make(map[token.TokenType]prefixOperator)
+		}
+		o.prefixOperators[t] = prefixOperator{fn: fn}
+	}
+}
+
+// WithInfixOperator registers additional infix operator for the token type with the precedence,
+// e.g. parser.EQUALS. Registering the builtin operator overrides its parser and precedence.
+// When fn is nil, the operator is parsed as ast.InfixExpression with the token literal.
+func WithInfixOperator(t token.TokenType, precedence int, fn InfixParseFunc) OptionFunc {
+	return func(o *Option) {
+		if o.infixOperators == nil {
+			o.infixOperators = make(map[token.TokenType]infixOperator)
+		}
+		o.infixOperators[t] = infixOperator{precedence: precedence, fn: fn}
+	}
+}
+
+// registerOperators applies operators registered by options over the builtin ones
+func (p *Parser) registerOperators() {
+	p.precedences = precedences
+	if len(p.option.infixOperators) > 0 {
+		p.precedences = make(map[token.TokenType]int, len(precedences)+len(p.option.infixOperators))
+		for k, v := range precedences {
+			p.precedences[k] = v
+		}
+	}
+
+	for t, op := range p.option.prefixOperators {
+		fn := op.fn
+		if fn == nil {
+			p.prefixParsers[t] = func() (ast.Expression, error) { return p.parsePrefixExpression() }
+			continue
+		}
+		p.prefixParsers[t] = func() (ast.Expression, error) { return fn(p) }
+	}
+	for t, op := range p.option.infixOperators {
+		p.precedences[t] = op.precedence
+		fn := op.fn
+		if fn == nil {
+			p.infixParsers[t] = p.parseInfixExpression
+			continue
+		}
+		p.infixParsers[t] = func(left ast.Expression) (ast.Expression, error) { return fn(p, left) }
+	}
+}
+
+// CurToken returns current token, used in custom operator parsers
+func (p *Parser) CurToken() *ast.Meta {
+	return p.curToken
+}
+
+// PeekToken returns next token, used in custom operator parsers
+func (p *Parser) PeekToken() *ast.Meta {
+	return p.peekToken
+}
+
+// NextToken advances a token, used in custom operator parsers
+func (p *Parser) NextToken() {
+	p.nextToken()
+}
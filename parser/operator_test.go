@@ -0,0 +1,62 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/ysugimoto/falco/ast"
+	"github.com/ysugimoto/falco/lexer"
+	"github.com/ysugimoto/falco/token"
+)
+
+func TestCustomOperators(t *testing.T) {
+	t.Run("default infix parser", func(t *testing.T) {
+		input := `req.http.A / req.http.B == "1"`
+		p := New(lexer.NewFromString(input), WithInfixOperator(token.SLASH, CONCAT, nil))
+		exp, err := p.ParseExpression(LOWEST)
+		if err != nil {
+			t.Errorf("Unexpected error: %s", err)
+			return
+		}
+		// "/" binds tighter than "==" so it should be the left side of equality
+		eq, ok := exp.(*ast.InfixExpression)
+		if !ok || eq.Operator != "==" {
+			t.Errorf("Expected == infix expression but got %s", exp.String())
+			return
+		}
+		if div, ok := eq.Left.(*ast.InfixExpression); !ok || div.Operator != "/" {
+			t.Errorf("Expected / infix expression but got %s", eq.Left.String())
+		}
+	})
+
+	t.Run("custom prefix parser", func(t *testing.T) {
+		input := `:req.http.A`
+		p := New(lexer.NewFromString(input), WithPrefixOperator(token.COLON, func(p *Parser) (ast.Expression, error) {
+			exp := &ast.PrefixExpression{
+				Meta:     p.CurToken(),
+				Operator: "typeof",
+			}
+			p.NextToken()
+			right, err := p.ParseExpression(PREFIX)
+			if err != nil {
+				return nil, err
+			}
+			exp.Right = right
+			return exp, nil
+		}))
+		exp, err := p.ParseExpression(LOWEST)
+		if err != nil {
+			t.Errorf("Unexpected error: %s", err)
+			return
+		}
+		if prefix, ok := exp.(*ast.PrefixExpression); !ok || prefix.Operator != "typeof" {
+			t.Errorf("Expected custom prefix expression but got %s", exp.String())
+		}
+	})
+
+	t.Run("builtin precedences are not changed", func(t *testing.T) {
+		New(lexer.NewFromString(""), WithInfixOperator(token.SLASH, CONCAT, nil))
+		if _, ok := precedences[token.SLASH]; ok {
+			t.Errorf("Global precedences must not be modified")
+		}
+	})
+}
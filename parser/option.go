@@ -0,0 +1,124 @@
+package parser
+
+import (
+	"github.com/ysugimoto/falco/lexer"
+	"github.com/ysugimoto/falco/token"
+)
+
+type OptionFunc func(o *Option)
+
+// Supported VCL dialects
+const (
+	DialectFastly   = "fastly"
+	DialectVarnish4 = "varnish4"
+)
+
+// Option controls parser guards. Zero value means unlimited.
+// These guards are useful for the service which parses untrusted VCL,
+// parser returns an error instead of unbounded recursion.
+type Option struct {
+	MaxNestingDepth int
+	MaxStatements   int
+	MaxInputBytes   int64
+	MaxTokens       int
+	ImportModules   map[string]struct{}
+
+	// In tolerant mode, unknown declaration properties like ".foo = bar;"
+	// are parsed as ast.GenericProperty instead of raising an error
+	TolerantProperties bool
+
+	// In recovery mode, unparseable statements and expressions are emitted as
+	// ast.BadStatement and ast.BadExpression and parsing continues
+	Recovery bool
+
+	// VCL dialect, default is DialectFastly
+	Dialect string
+
+	// Template placeholder delimiters which are passed to the lexer, see lexer.WithPlaceholders
+	Placeholders []lexer.Placeholder
+
+	// Additional operators registered by embedders
+	prefixOperators map[token.TokenType]prefixOperator
+	infixOperators  map[token.TokenType]infixOperator
+	// more field if exists
+}
+
+// WithMaxNestingDepth limits nesting depth of blocks and expressions
+func WithMaxNestingDepth(depth int) OptionFunc {
+	return func(o *Option) {
+		o.MaxNestingDepth = depth
+	}
+}
+
+// WithMaxStatements limits total count of parsed statements
+func WithMaxStatements(count int) OptionFunc {
+	return func(o *Option) {
+		o.MaxStatements = count
+	}
+}
+
+// WithMaxInputBytes limits total bytes of the input, lexer stops reading when exceeding it
+func WithMaxInputBytes(size int64) OptionFunc {
+	return func(o *Option) {
+		o.MaxInputBytes = size
+	}
+}
+
+// WithMaxTokens limits total count of lexed tokens including comments
+func WithMaxTokens(count int) OptionFunc {
+	return func(o *Option) {
+		o.MaxTokens = count
+	}
+}
+
+// WithImportModules registers known importable module names.
+// When any modules are registered, import statement with unknown module name is a parse error.
+func WithImportModules(names ...string) OptionFunc {
+	return func(o *Option) {
+		if o.ImportModules == nil {
+			o.ImportModules = make(map[string]struct{})
+		}
+		for i := range names {
+			o.ImportModules[names[i]] = struct{}{}
+		}
+	}
+}
+
+// WithTolerantProperties enables to parse unknown declaration properties
+func WithTolerantProperties() OptionFunc {
+	return func(o *Option) {
+		o.TolerantProperties = true
+	}
+}
+
+// WithRecovery enables to continue parsing on errors, parse errors are available via Parser.Errors()
+func WithRecovery() OptionFunc {
+	return func(o *Option) {
+		o.Recovery = true
+	}
+}
+
+// WithDialect switches VCL dialect, empty or unknown dialect is treated as DialectFastly.
+// DialectVarnish4 accepts "vcl 4.0;" and "new x = vmod.object();" syntax.
+func WithDialect(dialect string) OptionFunc {
+	return func(o *Option) {
+		o.Dialect = dialect
+	}
+}
+
+// WithPlaceholders lexes text enclosed by the placeholder delimiters as an identifier
+// so that templated VCL could be parsed
+func WithPlaceholders(placeholders ...lexer.Placeholder) OptionFunc {
+	return func(o *Option) {
+		o.Placeholders = append(o.Placeholders, placeholders...)
+	}
+}
+
+func collect(opts []OptionFunc) *Option {
+	o := &Option{}
+
+	for i := range opts {
+		opts[i](o)
+	}
+	return o
+}
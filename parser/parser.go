@@ -1,6 +1,7 @@
 package parser
 
 import (
+	"runtime/debug"
 	"strings"
 
 	"github.com/pkg/errors"
@@ -53,26 +54,80 @@ type Parser struct {
 	peekToken *ast.Meta
 	level     int
 
+	// guard counters
+	option     *Option
+	nest       int
+	statements int
+	tokens     int
+	limit      *LimitError
+
+	// non-fatal problems found while parsing
+	diagnostics []*ParseError
+
+	// first unterminated string or comment token found in the lexer
+	unterminated *ParseError
+
+	// recovery mode states
+	errors   []error
+	consumed []*ast.Meta
+
 	prefixParsers map[token.TokenType]prefixParser
 	infixParsers  map[token.TokenType]infixParser
+	precedences   map[token.TokenType]int
 }
 
-func New(l *lexer.Lexer) *Parser {
+func New(l *lexer.Lexer, opts ...OptionFunc) *Parser {
 	p := &Parser{
-		l: l,
+		option: collect(opts),
 	}
 
 	p.registerExpressionParsers()
+	p.registerOperators()
+	p.reset(l)
+
+	return p
+}
+
+// reset discards parser state and starts parsing with the lexer
+func (p *Parser) reset(l *lexer.Lexer) {
+	p.l = l
+	p.prevToken = nil
+	p.curToken = nil
+	p.peekToken = nil
+	p.level = 0
+	p.nest = 0
+	p.statements = 0
+	p.tokens = 0
+	p.limit = nil
+	p.diagnostics = nil
+	p.unterminated = nil
+	p.errors = nil
+	p.consumed = nil
+	p.l.Limit(p.option.MaxInputBytes)
+	if len(p.option.Placeholders) > 0 {
+		p.l.SetPlaceholders(p.option.Placeholders...)
+	}
 
 	p.nextToken()
 	p.nextToken()
+}
 
-	return p
+// Diagnostics returns non-fatal problems found while parsing, e.g. unknown variable types
+func (p *Parser) Diagnostics() []*ParseError {
+	return p.diagnostics
+}
+
+// Errors returns parse errors which are recovered in recovery mode
+func (p *Parser) Errors() []error {
+	return p.errors
 }
 
 func (p *Parser) nextToken() {
 	p.prevToken = p.curToken
 	p.curToken = p.peekToken
+	if p.option.Recovery && p.curToken != nil {
+		p.consumed = append(p.consumed, p.curToken)
+	}
 
 	p.readPeek()
 }
@@ -81,6 +136,11 @@ func (p *Parser) readPeek() {
 	leading := ast.Comments{}
 	for {
 		t := p.l.NextToken()
+		if p.checkLimits(t) {
+			// Stop parsing as if input reaches EOF, the error is reported on return
+			t = token.Token{Type: token.EOF, Line: t.Line, Position: t.Position, File: t.File}
+		}
+		p.checkUnterminated(t)
 		switch t.Type {
 		case token.LF:
 			continue
@@ -118,6 +178,10 @@ func (p *Parser) trailing() ast.Comments {
 			return cs
 		}
 		if tok.Type == token.COMMENT {
+			if p.checkLimits(tok) {
+				break
+			}
+			p.checkUnterminated(tok)
 			cs = append(cs, &ast.Comment{
 				Token: tok,
 				Value: tok.Literal,
@@ -132,6 +196,59 @@ func (p *Parser) trailing() ast.Comments {
 	return cs
 }
 
+// checkLimits counts lexed tokens and returns true when the input exceeds the limits
+func (p *Parser) checkLimits(t token.Token) bool {
+	if p.limit != nil {
+		return true
+	}
+	if t.Type != token.EOF {
+		p.tokens++
+	}
+	switch {
+	case p.l.Exceeded():
+		p.limit = &LimitError{Token: t, Kind: LimitInputBytes, Limit: p.option.MaxInputBytes}
+	case p.option.MaxTokens > 0 && p.tokens > p.option.MaxTokens:
+		p.limit = &LimitError{Token: t, Kind: LimitTokens, Limit: int64(p.option.MaxTokens)}
+	default:
+		return false
+	}
+	return true
+}
+
+// checkUnterminated remembers the first token which reached EOF without the terminator.
+// Subsequent parse errors are caused by it so the error is reported at the opening position.
+func (p *Parser) checkUnterminated(t token.Token) {
+	if !t.Unterminated || p.unterminated != nil {
+		return
+	}
+	switch {
+	case t.Type == token.COMMENT:
+		p.unterminated = UnterminatedComment(t)
+	case t.Type == token.ILLEGAL:
+		p.unterminated = UnterminatedPlaceholder(t)
+	case t.Offset == 4: // {" and "}
+		p.unterminated = UnterminatedLongString(t)
+	default:
+		p.unterminated = UnterminatedString(t)
+	}
+}
+
+// reportInputErrors overrides the returned error with input limit or unterminated construct error if found
+func (p *Parser) reportInputErrors(err *error) {
+	if p.limit != nil {
+		*err = errors.WithStack(p.limit)
+		return
+	}
+	if p.unterminated == nil {
+		return
+	}
+	if p.option.Recovery {
+		p.errors = append(p.errors, errors.WithStack(p.unterminated))
+		return
+	}
+	*err = errors.WithStack(p.unterminated)
+}
+
 func (p *Parser) curTokenIs(t token.TokenType) bool {
 	return p.curToken.Token.Type == t
 }
@@ -148,28 +265,80 @@ func (p *Parser) expectPeek(t token.TokenType) bool {
 	return true
 }
 
+// enterNest increments nesting depth and returns an error when exceeding the limit.
+// Caller must call leaveNest() on exit when enterNest succeeds.
+func (p *Parser) enterNest() error {
+	if p.option.MaxNestingDepth > 0 && p.nest >= p.option.MaxNestingDepth {
+		return TooDeepNesting(p.curToken, p.option.MaxNestingDepth)
+	}
+	p.nest++
+	return nil
+}
+
+func (p *Parser) leaveNest() {
+	p.nest--
+}
+
+// countStatement increments parsed statement count and returns an error when exceeding the limit
+func (p *Parser) countStatement(m *ast.Meta) error {
+	p.statements++
+	if p.option.MaxStatements > 0 && p.statements > p.option.MaxStatements {
+		return TooManyStatements(m, p.option.MaxStatements)
+	}
+	return nil
+}
+
 func (p *Parser) curPrecedence() int {
-	if v, ok := precedences[p.curToken.Token.Type]; ok {
+	if v, ok := p.precedences[p.curToken.Token.Type]; ok {
 		return v
 	}
 	return LOWEST
 }
 
 func (p *Parser) peekPrecedence() int {
-	if v, ok := precedences[p.peekToken.Token.Type]; ok {
+	if v, ok := p.precedences[p.peekToken.Token.Type]; ok {
 		return v
 	}
 	return LOWEST
 }
 
-func (p *Parser) ParseVCL() (*ast.VCL, error) {
+// recoverPanic converts unexpected runtime panic into PanicError with the stack trace.
+// Parser must return an error for broken input without panic, this is only the last resort not to crash the caller.
+func (p *Parser) recoverPanic(err *error) {
+	r := recover()
+	if r == nil {
+		return
+	}
+	var tok token.Token
+	if p.curToken != nil {
+		tok = p.curToken.Token
+	}
+	*err = &PanicError{
+		Token: tok,
+		Value: r,
+		Stack: debug.Stack(),
+	}
+}
+
+func (p *Parser) ParseVCL() (_ *ast.VCL, err error) {
+	defer p.reportInputErrors(&err)
+	defer p.recoverPanic(&err)
+
 	vcl := &ast.VCL{}
 
 	for !p.curTokenIs(token.EOF) {
+		mark := p.mark(true)
 		stmt, err := p.parse()
 		if err != nil {
-			return nil, err
+			if !p.option.Recovery {
+				return nil, err
+			}
+			vcl.Statements = append(vcl.Statements, p.recoverStatement(mark, err))
+			p.nextToken()
 		} else if stmt != nil {
+			if err := p.countStatement(stmt.GetMeta()); err != nil {
+				return nil, errors.WithStack(err)
+			}
 			vcl.Statements = append(vcl.Statements, stmt)
 		}
 	}
@@ -200,6 +369,12 @@ func (p *Parser) parse() (ast.Statement, error) {
 		stmt, err = p.parsePenaltyboxDeclaration()
 	case token.RATECOUNTER:
 		stmt, err = p.parseRatecounterDeclaration()
+	case token.IDENT:
+		if p.isVarnish4() && p.curToken.Token.Literal == "vcl" {
+			stmt, err = p.parseVersionStatement()
+			break
+		}
+		err = UnexpectedToken(p.curToken)
 	default:
 		err = UnexpectedToken(p.curToken)
 	}
@@ -214,13 +389,17 @@ func (p *Parser) parse() (ast.Statement, error) {
 // ParseSnippetVCL is used for snippet parsing.
 // VCL snippet is a piece of vcl code so we should parse like BlockStatement inside,
 // and returns slice of statement.
-func (p *Parser) ParseSnippetVCL() ([]ast.Statement, error) {
+func (p *Parser) ParseSnippetVCL() (_ []ast.Statement, err error) {
+	defer p.reportInputErrors(&err)
+	defer p.recoverPanic(&err)
+
 	var statements []ast.Statement
 
 	for !p.peekTokenIs(token.EOF) {
 		var stmt ast.Statement
 		var err error
 
+		mark := p.mark(true)
 		switch p.curToken.Token.Type {
 		// https://github.com/ysugimoto/falco/issues/17
 		// VCL accepts block syntax:
@@ -278,6 +457,11 @@ func (p *Parser) ParseSnippetVCL() ([]ast.Statement, error) {
 		}
 
 		if err != nil {
+			if !p.option.Recovery {
+				return nil, errors.WithStack(err)
+			}
+			stmt = p.recoverStatement(mark, errors.WithStack(err))
+		} else if err := p.countStatement(stmt.GetMeta()); err != nil {
 			return nil, errors.WithStack(err)
 		}
 		statements = append(statements, stmt)
@@ -287,3 +471,29 @@ func (p *Parser) ParseSnippetVCL() ([]ast.Statement, error) {
 	p.nextToken() // point to EOF
 	return statements, nil
 }
+
+// ParseCondition is used for Fastly condition parsing.
+// Condition is a single expression which is configured via UI, API or terraform,
+// and conditionType should be one of ast.RequestCondition, ast.CacheCondition, ast.ResponseCondition or ast.PrefetchCondition.
+func (p *Parser) ParseCondition(conditionType string) (_ *ast.Condition, err error) {
+	defer p.recoverPanic(&err)
+
+	cond := &ast.Condition{
+		Meta: p.curToken,
+		Type: conditionType,
+	}
+
+	exp, err := p.parseExpression(LOWEST)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	cond.Expression = exp
+
+	// Condition must be a single expression
+	if !p.peekTokenIs(token.EOF) {
+		return nil, errors.WithStack(UnexpectedToken(p.peekToken, "EOF"))
+	}
+	p.nextToken() // point to EOF
+
+	return cond, nil
+}
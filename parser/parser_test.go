@@ -2,9 +2,11 @@ package parser
 
 import (
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/pkg/errors"
 	"github.com/ysugimoto/falco/ast"
 	"github.com/ysugimoto/falco/lexer"
 	"github.com/ysugimoto/falco/token"
@@ -83,9 +85,26 @@ sub vcl_recv {
 	declare local var.S STRING;
 	set var.S = "foo" "bar" + "baz";
 }`
-	_, err := New(lexer.NewFromString(input)).ParseVCL()
+	vcl, err := New(lexer.NewFromString(input)).ParseVCL()
 	if err != nil {
-		t.Error(err)
+		t.Fatal(err)
+	}
+	set := vcl.Statements[0].(*ast.SubroutineDeclaration).Block.Statements[1].(*ast.SetStatement)
+	outer, ok := set.Value.(*ast.InfixExpression)
+	if !ok {
+		t.Fatalf("Expected InfixExpression, got %T", set.Value)
+	}
+	if outer.Implicit {
+		t.Errorf("Concatenation with + operator must not be implicit")
+	}
+	if _, ok := outer.Right.(*ast.String); !ok {
+		t.Errorf("Right expression must be String, got %T", outer.Right)
+	}
+	if inner, ok := outer.Left.(*ast.InfixExpression); !ok || !inner.Implicit {
+		t.Errorf("Consecutive strings must be implicit concatenation")
+	}
+	if v := set.Value.String(); v != `(("foo" "bar") + "baz")` {
+		t.Errorf("Unexpected stringified expression: %s", v)
 	}
 }
 
@@ -192,3 +211,322 @@ sub vcl_recv {
 	}
 	assert(t, vcl, expect)
 }
+
+func TestParseTemplatedVCL(t *testing.T) {
+	input := `
+backend %{BACKEND_NAME} {
+	.host = "{{ .Host }}";
+	.port = {{ .Port }};
+}
+
+sub vcl_recv {
+	set req.backend = %{BACKEND_NAME};
+	set req.http.Host = {{ .Host }};
+}`
+	lx := lexer.NewFromString(input, lexer.WithPlaceholders(lexer.DefaultPlaceholders...))
+	vcl, err := New(lx).ParseVCL()
+	if err != nil {
+		t.Errorf("%+v\n", err)
+		return
+	}
+	backend, ok := vcl.Statements[0].(*ast.BackendDeclaration)
+	if !ok {
+		t.Errorf("Expected BackendDeclaration, got %T", vcl.Statements[0])
+		return
+	}
+	if backend.Name.Value != "%{BACKEND_NAME}" {
+		t.Errorf("Expected placeholder backend name, got %s", backend.Name.Value)
+	}
+}
+
+func TestParseTemplatedVCLWithOption(t *testing.T) {
+	t.Run("placeholders are passed to lexer", func(t *testing.T) {
+		input := `sub vcl_recv {
+	set req.http.Host = {{ .Host }};
+}`
+		_, err := New(lexer.NewFromString(input), WithPlaceholders(lexer.DefaultPlaceholders...)).ParseVCL()
+		if err != nil {
+			t.Errorf("%+v\n", err)
+		}
+	})
+
+	t.Run("unterminated placeholder", func(t *testing.T) {
+		input := `sub vcl_recv {
+	set req.http.Host = {{ .Host;
+}`
+		_, err := New(lexer.NewFromString(input), WithPlaceholders(lexer.DefaultPlaceholders...)).ParseVCL()
+		var pe *ParseError
+		if !errors.As(err, &pe) {
+			t.Errorf("Expected ParseError but got %v", err)
+			return
+		}
+		if pe.Message != "Unterminated template placeholder" {
+			t.Errorf("Unexpected message: %s", pe.Message)
+		}
+		if pe.Token.Line != 2 || pe.Token.Position != 22 {
+			t.Errorf("Unexpected position, got=%d:%d", pe.Token.Line, pe.Token.Position)
+		}
+	})
+}
+
+func TestDecodeString(t *testing.T) {
+	tests := []struct {
+		input   string
+		expect  string
+		isError bool
+	}{
+		{input: "foo%20bar", expect: "foo bar"},
+		{input: "%u0041%u{1F600}", expect: "A\U0001F600"},
+		{input: "foo%00bar", expect: "foo"},
+		{input: "foo%u0000bar", expect: "foo"},
+		{input: "100%", expect: "100%", isError: true},
+		{input: "%zz", expect: "%zz", isError: true},
+		{input: "%u{}", expect: "%u{}", isError: true},
+	}
+
+	for _, tt := range tests {
+		decoded, err := DecodeString(tt.input)
+		if tt.isError && err == nil {
+			t.Errorf("Expected error for %q but nil", tt.input)
+		} else if !tt.isError && err != nil {
+			t.Errorf("Unexpected error for %q: %s", tt.input, err)
+		}
+		if decoded != tt.expect {
+			t.Errorf("Decoded value mismatch for %q, expect=%q, actual=%q", tt.input, tt.expect, decoded)
+		}
+	}
+}
+
+func TestParseEscapedString(t *testing.T) {
+	input := `sub vcl_recv { set req.http.Foo = "foo%20bar"; }`
+	vcl, err := New(lexer.NewFromString(input)).ParseVCL()
+	if err != nil {
+		t.Errorf("%+v\n", err)
+		return
+	}
+	set := vcl.Statements[0].(*ast.SubroutineDeclaration).Block.Statements[0].(*ast.SetStatement)
+	str := set.Value.(*ast.String)
+	if str.Value != "foo bar" {
+		t.Errorf("Decoded value mismatch, got %q", str.Value)
+	}
+	if str.RawValue() != "foo%20bar" {
+		t.Errorf("Raw value mismatch, got %q", str.RawValue())
+	}
+}
+
+func TestParseRTime(t *testing.T) {
+	tests := []struct {
+		input  string
+		expect time.Duration
+	}{
+		{input: "100ms", expect: 100 * time.Millisecond},
+		{input: "1.5s", expect: 1500 * time.Millisecond},
+		{input: "5m", expect: 5 * time.Minute},
+		{input: "2h", expect: 2 * time.Hour},
+		{input: "30d", expect: 30 * 24 * time.Hour},
+		{input: "1y", expect: 365 * 24 * time.Hour},
+	}
+
+	for _, tt := range tests {
+		exp, err := New(lexer.NewFromString(tt.input)).ParseExpression(LOWEST)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", tt.input, err)
+			continue
+		}
+		rtime, ok := exp.(*ast.RTime)
+		if !ok {
+			t.Errorf("%s: expected RTime but got %T", tt.input, exp)
+			continue
+		}
+		if rtime.Value != tt.input || rtime.Duration != tt.expect {
+			t.Errorf("%s: unexpected value %s, duration %s", tt.input, rtime.Value, rtime.Duration)
+		}
+	}
+
+	if _, err := parseDuration("10x"); err == nil {
+		t.Errorf("Expected error for unknown unit")
+	}
+}
+
+func TestParserGuards(t *testing.T) {
+	t.Run("nesting depth", func(t *testing.T) {
+		input := `sub vcl_recv { { { { log "deep"; } } } }`
+		if _, err := New(lexer.NewFromString(input), WithMaxNestingDepth(3)).ParseVCL(); err == nil {
+			t.Errorf("Expected nesting depth error but got nil")
+		}
+		if _, err := New(lexer.NewFromString(input), WithMaxNestingDepth(6)).ParseVCL(); err != nil {
+			t.Errorf("Unexpected error: %s", err)
+		}
+	})
+
+	t.Run("expression nesting depth", func(t *testing.T) {
+		input := `sub vcl_recv { set req.http.Foo = ((((((("a"))))))); }`
+		if _, err := New(lexer.NewFromString(input), WithMaxNestingDepth(5)).ParseVCL(); err == nil {
+			t.Errorf("Expected nesting depth error but got nil")
+		}
+	})
+
+	t.Run("input bytes", func(t *testing.T) {
+		input := `sub vcl_recv { log "1"; log "2"; }`
+		_, err := New(lexer.NewFromString(input), WithMaxInputBytes(20)).ParseVCL()
+		var le *LimitError
+		if !errors.As(err, &le) {
+			t.Errorf("Expected LimitError but got %v", err)
+		} else if le.Kind != LimitInputBytes {
+			t.Errorf("Unexpected limit kind %s", le.Kind)
+		}
+		if _, err := New(lexer.NewFromString(input), WithMaxInputBytes(int64(len(input)))).ParseVCL(); err != nil {
+			t.Errorf("Unexpected error: %s", err)
+		}
+	})
+
+	t.Run("token count", func(t *testing.T) {
+		input := `sub vcl_recv { log "1"; /* comment */ log "2"; }`
+		_, err := New(lexer.NewFromString(input), WithMaxTokens(9), WithRecovery()).ParseVCL()
+		var le *LimitError
+		if !errors.As(err, &le) {
+			t.Errorf("Expected LimitError but got %v", err)
+		} else if le.Kind != LimitTokens {
+			t.Errorf("Unexpected limit kind %s", le.Kind)
+		}
+		// 11 tokens including comment
+		if _, err := New(lexer.NewFromString(input), WithMaxTokens(11)).ParseVCL(); err != nil {
+			t.Errorf("Unexpected error: %s", err)
+		}
+	})
+
+	t.Run("statement count", func(t *testing.T) {
+		input := `sub vcl_recv { log "1"; log "2"; log "3"; }`
+		if _, err := New(lexer.NewFromString(input), WithMaxStatements(3)).ParseVCL(); err == nil {
+			t.Errorf("Expected statement count error but got nil")
+		}
+		if _, err := New(lexer.NewFromString(input), WithMaxStatements(4)).ParseVCL(); err != nil {
+			t.Errorf("Unexpected error: %s", err)
+		}
+	})
+}
+
+func TestUnterminatedConstructs(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		message  string
+		line     int
+		position int
+	}{
+		{
+			name:     "string",
+			input:    "sub vcl_recv {\n  set req.http.Foo = \"foo;\n}",
+			message:  "Unterminated string literal",
+			line:     2,
+			position: 22,
+		},
+		{
+			name:     "long string",
+			input:    "sub vcl_recv {\n  set req.http.Foo = {\"foo;\n}",
+			message:  "Unterminated long string literal",
+			line:     2,
+			position: 22,
+		},
+		{
+			name:     "block comment",
+			input:    "sub vcl_recv {\n  /* comment\n  log \"foo\";\n}",
+			message:  "Unterminated block comment",
+			line:     2,
+			position: 3,
+		},
+		{
+			name:     "block",
+			input:    "sub vcl_recv {\n  if (req.http.Foo) {\n    log \"foo\";\n}",
+			message:  "Unterminated block",
+			line:     1,
+			position: 14,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New(lexer.NewFromString(tt.input)).ParseVCL()
+			if err == nil {
+				t.Errorf("Expected error but got nil")
+				return
+			}
+			var pe *ParseError
+			if !errors.As(err, &pe) {
+				t.Errorf("Expected ParseError but got %T", err)
+				return
+			}
+			if pe.Message != tt.message {
+				t.Errorf("Unexpected message, expect=%s, got=%s", tt.message, pe.Message)
+			}
+			if pe.Token.Line != tt.line || pe.Token.Position != tt.position {
+				t.Errorf(
+					"Unexpected position, expect=%d:%d, got=%d:%d",
+					tt.line, tt.position, pe.Token.Line, pe.Token.Position,
+				)
+			}
+			if pe.Hint == "" {
+				t.Errorf("Expected hint for missing terminator")
+			}
+		})
+	}
+}
+
+func TestCommentsInParenthesesAndElse(t *testing.T) {
+	input := `
+sub vcl_recv {
+	if (req.http.Foo /* foo */) {
+		set req.http.A = (req.http.B /* group */);
+	}
+	// before else if
+	else /* between */ if (req.http.Bar) {
+		esi;
+	}
+	// before else
+	else {
+		esi;
+	}
+}`
+	vcl, err := New(lexer.NewFromString(input)).ParseVCL()
+	if err != nil {
+		t.Errorf("%+v", err)
+		return
+	}
+	stmt := vcl.Statements[0].(*ast.SubroutineDeclaration).Block.Statements[0].(*ast.IfStatement)
+	if v := stmt.Condition.TrailingComments().String(); v != "/* foo */" {
+		t.Errorf("Condition trailing comment mismatch, got %q", v)
+	}
+	set := stmt.Consequence.Statements[0].(*ast.SetStatement)
+	if v := set.Value.(*ast.GroupedExpression).Right.TrailingComments().String(); v != "/* group */" {
+		t.Errorf("Grouped expression trailing comment mismatch, got %q", v)
+	}
+	if v := stmt.Another[0].LeadingComments().String(); v != "// before else if/* between */" {
+		t.Errorf("Else if leading comment mismatch, got %q", v)
+	}
+	if v := stmt.AlternativeComments.String(); v != "// before else" {
+		t.Errorf("Else comment mismatch, got %q", v)
+	}
+}
+
+func TestParseCondition(t *testing.T) {
+	t.Run("valid condition", func(t *testing.T) {
+		cond, err := New(lexer.NewFromString(`req.url ~ "^/foo" && req.http.Host == "example.com"`)).ParseCondition(ast.RequestCondition)
+		if err != nil {
+			t.Errorf("%+v", err)
+			return
+		}
+		if cond.Type != ast.RequestCondition {
+			t.Errorf("Unexpected condition type %s", cond.Type)
+		}
+		if _, ok := cond.Expression.(*ast.InfixExpression); !ok {
+			t.Errorf("Expected InfixExpression, got %T", cond.Expression)
+		}
+	})
+
+	t.Run("multiple expressions", func(t *testing.T) {
+		_, err := New(lexer.NewFromString(`req.url ~ "^/foo"; set req.http.Foo = "bar";`)).ParseCondition(ast.RequestCondition)
+		if err == nil {
+			t.Errorf("Expected parse error but got nil")
+		}
+	})
+}
@@ -0,0 +1,64 @@
+package parser
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/ysugimoto/falco/ast"
+	"github.com/ysugimoto/falco/lexer"
+)
+
+// Pool reuses lexer and parser allocations for bulk parsing.
+// Pool is safe to use from multiple goroutines, each parse call takes its own parser from the pool.
+type Pool struct {
+	pool sync.Pool
+}
+
+func NewPool(opts ...OptionFunc) *Pool {
+	return &Pool{
+		pool: sync.Pool{
+			New: func() interface{} {
+				return New(lexer.NewFromString(""), opts...)
+			},
+		},
+	}
+}
+
+func (p *Pool) get(input string, opts []lexer.OptionFunc) *Parser {
+	ps := p.pool.Get().(*Parser)
+	ps.l.Reset(strings.NewReader(input), opts...)
+	ps.reset(ps.l)
+	return ps
+}
+
+// Result holds non-fatal problems found by the pooled parser.
+// They are copied out before the parser is put back to the pool and reused by another call
+type Result struct {
+	// Same as Parser.Diagnostics()
+	Diagnostics []*ParseError
+	// Same as Parser.Errors(), only filled with WithRecovery option
+	Errors []error
+}
+
+func (p *Pool) put(ps *Parser) *Result {
+	r := &Result{
+		Diagnostics: append([]*ParseError(nil), ps.Diagnostics()...),
+		Errors:      append([]error(nil), ps.Errors()...),
+	}
+	p.pool.Put(ps)
+	return r
+}
+
+// ParseVCL parses input as the VCL
+func (p *Pool) ParseVCL(input string, opts ...lexer.OptionFunc) (*ast.VCL, *Result, error) {
+	ps := p.get(input, opts)
+	vcl, err := ps.ParseVCL()
+	return vcl, p.put(ps), err
+}
+
+// ParseSnippetVCL parses input as the VCL snippet
+func (p *Pool) ParseSnippetVCL(input string, opts ...lexer.OptionFunc) ([]ast.Statement, *Result, error) {
+	ps := p.get(input, opts)
+	stmts, err := ps.ParseSnippetVCL()
+	return stmts, p.put(ps), err
+}
@@ -0,0 +1,81 @@
+package parser
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/ysugimoto/falco/ast"
+	"github.com/ysugimoto/falco/lexer"
+)
+
+func TestPoolParseVCL(t *testing.T) {
+	pool := NewPool()
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 100)
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			input := fmt.Sprintf(`sub vcl_recv { set req.http.Foo = "%d"; }`, i)
+			vcl, _, err := pool.ParseVCL(input, lexer.WithFile(fmt.Sprintf("%d.vcl", i)))
+			if err != nil {
+				errs <- err
+				return
+			}
+			sub := vcl.Statements[0].(*ast.SubroutineDeclaration)
+			set := sub.Block.Statements[0].(*ast.SetStatement)
+			if v := set.Value.(*ast.String).Value; v != fmt.Sprint(i) {
+				errs <- fmt.Errorf("Unexpected value %s, expect %d", v, i)
+			}
+			if f := sub.GetMeta().Token.File; f != fmt.Sprintf("%d.vcl", i) {
+				errs <- fmt.Errorf("Unexpected file %s, expect %d.vcl", f, i)
+			}
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Error(err)
+	}
+}
+
+func TestPoolReuseAfterError(t *testing.T) {
+	pool := NewPool()
+
+	if _, _, err := pool.ParseVCL(`sub vcl_recv { set req.http.Foo = `); err == nil {
+		t.Errorf("Expected parse error but got nil")
+	}
+	if _, _, err := pool.ParseSnippetVCL(`set req.http.Foo = "bar";`); err != nil {
+		t.Errorf("Unexpected error after reuse: %s", err)
+	}
+}
+
+func TestPoolResult(t *testing.T) {
+	pool := NewPool(WithRecovery())
+
+	_, result, err := pool.ParseVCL(`sub vcl_recv {
+	declare local var.foo BLOB;
+	set req.http.Foo = ;
+	set req.http.Bar = "bar";
+}`)
+	if err != nil {
+		t.Errorf("Unexpected error in recovery mode: %s", err)
+	}
+	if len(result.Diagnostics) != 1 {
+		t.Errorf("Expected one diagnostic, got %d", len(result.Diagnostics))
+	}
+	if len(result.Errors) == 0 {
+		t.Errorf("Recovered errors should be returned")
+	}
+
+	// Reusing the parser must not change the returned result
+	if _, _, err := pool.ParseVCL(`sub vcl_recv { declare local var.bar BLOB; }`); err != nil {
+		t.Errorf("Unexpected error after reuse: %s", err)
+	}
+	if len(result.Diagnostics) != 1 || result.Diagnostics[0].Token.Line != 2 {
+		t.Errorf("Result is changed after the parser is reused")
+	}
+}
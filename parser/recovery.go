@@ -0,0 +1,115 @@
+package parser
+
+import (
+	"github.com/ysugimoto/falco/ast"
+	"github.com/ysugimoto/falco/token"
+)
+
+// Tokens which start a statement or declaration, recovery stops before these tokens
+// in order to parse the following healthy statement
+var syncTokens = map[token.TokenType]struct{}{
+	token.ACL:              {},
+	token.IMPORT:           {},
+	token.INCLUDE:          {},
+	token.BACKEND:          {},
+	token.DIRECTOR:         {},
+	token.TABLE:            {},
+	token.SUBROUTINE:       {},
+	token.PENALTYBOX:       {},
+	token.RATECOUNTER:      {},
+	token.SET:              {},
+	token.UNSET:            {},
+	token.REMOVE:           {},
+	token.ADD:              {},
+	token.CALL:             {},
+	token.DECLARE:          {},
+	token.ERROR:            {},
+	token.ESI:              {},
+	token.LOG:              {},
+	token.RESTART:          {},
+	token.RETURN:           {},
+	token.SYNTHETIC:        {},
+	token.SYNTHETIC_BASE64: {},
+	token.IF:               {},
+	token.GOTO:             {},
+}
+
+// mark returns the position of current token in consumed tokens, used for recovery.
+// On top level statement, consumed tokens are no longer needed so truncate them.
+func (p *Parser) mark(truncate bool) int {
+	if !p.option.Recovery {
+		return -1
+	}
+	if truncate {
+		p.consumed = append(p.consumed[:0], p.curToken)
+	}
+	return len(p.consumed) - 1
+}
+
+// recoverStatement records the error and skips tokens until the end of broken statement.
+// Broken statement ends with semicolon, closing brace of broken block,
+// or right before the token which starts another statement.
+// After recovery, current token points to the last token of broken statement
+// as well as other statement parsers.
+func (p *Parser) recoverStatement(mark int, err error) *ast.BadStatement {
+	p.errors = append(p.errors, err)
+
+	var depth int
+	var block bool
+	for _, m := range p.consumed[mark:] {
+		switch m.Token.Type {
+		case token.LEFT_BRACE:
+			depth++
+			block = true
+		case token.RIGHT_BRACE:
+			depth--
+		}
+	}
+
+	for !p.curTokenIs(token.EOF) && depth >= 0 {
+		if depth == 0 {
+			if p.curTokenIs(token.SEMICOLON) || (block && p.curTokenIs(token.RIGHT_BRACE)) {
+				break
+			}
+			if p.peekTokenIs(token.RIGHT_BRACE) || p.peekTokenIs(token.EOF) {
+				break
+			}
+			if _, ok := syncTokens[p.peekToken.Token.Type]; ok {
+				break
+			}
+		}
+		p.nextToken()
+		switch p.curToken.Token.Type {
+		case token.LEFT_BRACE:
+			depth++
+			block = true
+		case token.RIGHT_BRACE:
+			depth--
+		}
+	}
+
+	bad := &ast.BadStatement{
+		Meta: p.consumed[mark],
+	}
+	for _, m := range p.consumed[mark:] {
+		if m.Token.Type == token.EOF {
+			break
+		}
+		bad.Tokens = append(bad.Tokens, m.Token)
+	}
+	return bad
+}
+
+// Expression is recoverable when the token is not a terminator of expression.
+// Terminators are left for the statement parser to report the error with proper range.
+func (p *Parser) canRecoverExpression() bool {
+	if !p.option.Recovery {
+		return false
+	}
+	switch p.curToken.Token.Type {
+	case token.SEMICOLON, token.LEFT_BRACE, token.RIGHT_BRACE,
+		token.RIGHT_PAREN, token.COMMA, token.EOF:
+		return false
+	}
+	return true
+}
@@ -0,0 +1,121 @@
+package parser
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/ysugimoto/falco/ast"
+	"github.com/ysugimoto/falco/lexer"
+)
+
+func TestRecoveryMode(t *testing.T) {
+	t.Run("broken top level statement", func(t *testing.T) {
+		input := `foo bar;
+sub vcl_recv {
+	set req.http.Foo = "foo";
+}`
+		p := New(lexer.NewFromString(input), WithRecovery())
+		vcl, err := p.ParseVCL()
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		if len(p.Errors()) != 1 {
+			t.Errorf("Expected one recovered error, got %d", len(p.Errors()))
+		}
+		if len(vcl.Statements) != 2 {
+			t.Fatalf("Expected 2 statements, got %d", len(vcl.Statements))
+		}
+		bad, ok := vcl.Statements[0].(*ast.BadStatement)
+		if !ok {
+			t.Fatalf("First statement must be BadStatement, got %T", vcl.Statements[0])
+		}
+		if bad.String() != "foo bar ;\n" {
+			t.Errorf("Unexpected bad statement range: %q", bad.String())
+		}
+		if _, ok := vcl.Statements[1].(*ast.SubroutineDeclaration); !ok {
+			t.Errorf("Second statement must be SubroutineDeclaration, got %T", vcl.Statements[1])
+		}
+	})
+
+	t.Run("broken statement in block", func(t *testing.T) {
+		input := `sub vcl_recv {
+	set req.http.Foo = "foo"
+	set req.http.Bar = "bar";
+	esi foo;
+	unset req.http.Baz;
+}`
+		p := New(lexer.NewFromString(input), WithRecovery())
+		vcl, err := p.ParseVCL()
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		if len(p.Errors()) != 2 {
+			t.Errorf("Expected two recovered errors, got %d", len(p.Errors()))
+		}
+		stmts := vcl.Statements[0].(*ast.SubroutineDeclaration).Block.Statements
+		if len(stmts) != 4 {
+			t.Fatalf("Expected 4 statements, got %d", len(stmts))
+		}
+		for i, expect := range []string{"*ast.BadStatement", "*ast.SetStatement", "*ast.BadStatement", "*ast.UnsetStatement"} {
+			if actual := typeName(stmts[i]); actual != expect {
+				t.Errorf("Statement %d type mismatch, expect=%s, actual=%s", i, expect, actual)
+			}
+		}
+	})
+
+	t.Run("broken expression", func(t *testing.T) {
+		input := `sub vcl_recv {
+	set req.http.Foo = == "foo";
+}`
+		p := New(lexer.NewFromString(input), WithRecovery())
+		vcl, err := p.ParseVCL()
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		if len(p.Errors()) != 1 {
+			t.Errorf("Expected one recovered error, got %d", len(p.Errors()))
+		}
+		stmt, ok := vcl.Statements[0].(*ast.SubroutineDeclaration).Block.Statements[0].(*ast.SetStatement)
+		if !ok {
+			t.Fatalf("Set statement must be parsed")
+		}
+		infix, ok := stmt.Value.(*ast.InfixExpression)
+		if !ok {
+			t.Fatalf("Value must be InfixExpression, got %T", stmt.Value)
+		}
+		if _, ok := infix.Left.(*ast.BadExpression); !ok {
+			t.Errorf("Left must be BadExpression, got %T", infix.Left)
+		}
+	})
+
+	t.Run("unterminated block", func(t *testing.T) {
+		input := `sub vcl_recv {
+	set req.http.Foo = "foo";
+`
+		p := New(lexer.NewFromString(input), WithRecovery())
+		vcl, err := p.ParseVCL()
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		if len(p.Errors()) != 1 {
+			t.Errorf("Expected one recovered error, got %d", len(p.Errors()))
+		}
+		if len(vcl.Statements) != 1 {
+			t.Fatalf("Expected 1 statement, got %d", len(vcl.Statements))
+		}
+		if _, ok := vcl.Statements[0].(*ast.BadStatement); !ok {
+			t.Errorf("Statement must be BadStatement, got %T", vcl.Statements[0])
+		}
+	})
+
+	t.Run("error is returned without recovery mode", func(t *testing.T) {
+		_, err := New(lexer.NewFromString(`foo bar;`)).ParseVCL()
+		if err == nil {
+			t.Errorf("Expected error but nil")
+		}
+	})
+}
+
+func typeName(v any) string {
+	return fmt.Sprintf("%T", v)
+}
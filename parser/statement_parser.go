@@ -15,6 +15,9 @@ func (p *Parser) parseImportStatement() (*ast.ImportStatement, error) {
 		return nil, errors.WithStack(UnexpectedToken(p.peekToken, "IDENT"))
 	}
 	i.Name = p.parseIdent()
+	if !p.isKnownImportModule(i.Name.Value) {
+		return nil, errors.WithStack(UnknownImportModule(i.Name.Meta))
+	}
 
 	if !p.peekTokenIs(token.SEMICOLON) {
 		return nil, errors.WithStack(MissingSemicolon(p.curToken))
@@ -55,11 +58,17 @@ func (p *Parser) parseBlockStatement() (*ast.BlockStatement, error) {
 		Statements: []ast.Statement{},
 	}
 
+	if err := p.enterNest(); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	defer p.leaveNest()
+
 	for !p.peekTokenIs(token.RIGHT_BRACE) {
 		var stmt ast.Statement
 		var err error
 
 		p.nextToken() // point to statement
+		mark := p.mark(false)
 		switch p.curToken.Token.Type {
 		// https://github.com/ysugimoto/falco/issues/17
 		// VCL accepts block syntax:
@@ -105,18 +114,35 @@ func (p *Parser) parseBlockStatement() (*ast.BlockStatement, error) {
 		case token.INCLUDE:
 			stmt, err = p.parseIncludeStatement()
 		case token.IDENT:
-			// Check if the current ident is a function call
-			if p.peekTokenIs(token.LEFT_PAREN) {
+			// Check if the current ident is VMOD object instantiation in Varnish 4.x dialect, or a function call
+			if p.isVarnish4() && p.curToken.Token.Literal == "new" && p.peekTokenIs(token.IDENT) {
+				stmt, err = p.parseNewStatement()
+			} else if p.peekTokenIs(token.LEFT_PAREN) {
 				stmt, err = p.parseFunctionCall()
 			} else {
 				// Could be a goto destination
 				stmt, err = p.parseGotoDestination()
 			}
+		case token.DOT:
+			// Unknown declaration property, e.g. new field of penaltybox and ratecounter
+			if !p.option.TolerantProperties {
+				err = UnexpectedToken(p.curToken)
+				break
+			}
+			stmt, err = p.parseGenericProperty()
+		case token.EOF:
+			err = UnterminatedBlock(b.Meta)
 		default:
 			err = UnexpectedToken(p.peekToken)
 		}
 
 		if err != nil {
+			// Unterminated block could not be recovered here, enclosing statement will be recovered
+			if !p.option.Recovery || p.curTokenIs(token.EOF) {
+				return nil, errors.WithStack(err)
+			}
+			stmt = p.recoverStatement(mark, errors.WithStack(err))
+		} else if err := p.countStatement(stmt.GetMeta()); err != nil {
 			return nil, errors.WithStack(err)
 		}
 		b.Statements = append(b.Statements, stmt)
@@ -292,10 +318,19 @@ func (p *Parser) parseDeclareStatement() (*ast.DeclareStatement, error) {
 	}
 	stmt.Name = p.parseIdent()
 
-	if !p.expectPeek(token.IDENT) {
-		return nil, errors.WithStack(UnexpectedToken(p.peekToken, "IDENT"))
+	valueType, err := p.parseVariableType()
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	stmt.ValueType = valueType
+	if !isValidLocalVariableType(valueType.Value) {
+		// Unknown type does not abort parsing, report as diagnostic and let linter decide
+		stmt.UnknownType = &ast.UnknownType{
+			Meta: valueType.Meta,
+			Name: valueType.Value,
+		}
+		p.diagnostics = append(p.diagnostics, UnknownVariableType(valueType.Meta, valueType.Value))
 	}
-	stmt.ValueType = p.parseIdent()
 
 	if !p.peekTokenIs(token.SEMICOLON) {
 		return nil, errors.WithStack(MissingSemicolon(p.curToken))
@@ -306,6 +341,26 @@ func (p *Parser) parseDeclareStatement() (*ast.DeclareStatement, error) {
 	return stmt, nil
 }
 
+// parseVariableType parses variable type of declare statement.
+// Newer or vendor-specific type may consist of multiple tokens like "STRING[]",
+// so all tokens until semicolon are concatenated as the raw type name.
+func (p *Parser) parseVariableType() (*ast.Ident, error) {
+	if !p.expectPeek(token.IDENT) {
+		return nil, UnexpectedToken(p.peekToken, "IDENT")
+	}
+	ident := p.parseIdent()
+
+	for !p.peekTokenIs(token.SEMICOLON) {
+		switch p.peekToken.Token.Type {
+		case token.EOF, token.LEFT_BRACE, token.RIGHT_BRACE:
+			return nil, MissingSemicolon(p.curToken)
+		}
+		p.nextToken()
+		ident.Value += p.curToken.Token.Literal
+	}
+	return ident, nil
+}
+
 func (p *Parser) parseErrorStatement() (*ast.ErrorStatement, error) {
 	stmt := &ast.ErrorStatement{
 		Meta: p.curToken,
@@ -504,7 +559,7 @@ func (p *Parser) parseIfStatement() (*ast.IfStatement, error) {
 	}
 
 	p.nextToken() // point to condition expression
-	cond, err := p.parseExpression(LOWEST)
+	cond, err := p.parseConditionExpression()
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
@@ -513,6 +568,8 @@ func (p *Parser) parseIfStatement() (*ast.IfStatement, error) {
 	if !p.expectPeek(token.RIGHT_PAREN) {
 		return nil, errors.WithStack(UnexpectedToken(p.peekToken, "RIGHT_PAREN"))
 	}
+	// Comments before RIGHT_PAREN belong to the condition
+	appendLeadingTrailing(p.curToken, stmt.Condition.GetMeta())
 
 	if !p.expectPeek(token.LEFT_BRACE) {
 		return nil, errors.WithStack(UnexpectedToken(p.peekToken, "LEFT_BRACE"))
@@ -530,6 +587,7 @@ func (p *Parser) parseIfStatement() (*ast.IfStatement, error) {
 		switch p.peekToken.Token.Type {
 		case token.ELSE: // else
 			p.nextToken() // point to ELSE
+			elseComments := p.curToken.Leading
 
 			// If more peek token is IF, it should be "else if"
 			if p.peekTokenIs(token.IF) { // else if
@@ -538,6 +596,8 @@ func (p *Parser) parseIfStatement() (*ast.IfStatement, error) {
 				if err != nil {
 					return nil, errors.WithStack(err)
 				}
+				// Comments before ELSE and between ELSE and IF are leading comments of "else if"
+				another.Leading = append(elseComments, another.Leading...)
 				stmt.Another = append(stmt.Another, another)
 				continue
 			}
@@ -546,6 +606,7 @@ func (p *Parser) parseIfStatement() (*ast.IfStatement, error) {
 			if !p.expectPeek(token.LEFT_BRACE) {
 				return nil, errors.WithStack(UnexpectedToken(p.peekToken, "LEFT_BRACE"))
 			}
+			stmt.AlternativeComments = elseComments
 			stmt.Alternative, err = p.parseBlockStatement()
 			if err != nil {
 				return nil, errors.WithStack(err)
@@ -580,7 +641,7 @@ func (p *Parser) parseAnotherIfStatement() (*ast.IfStatement, error) {
 	}
 
 	p.nextToken() // point to condition expression
-	cond, err := p.parseExpression(LOWEST)
+	cond, err := p.parseConditionExpression()
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
@@ -589,6 +650,8 @@ func (p *Parser) parseAnotherIfStatement() (*ast.IfStatement, error) {
 	if !p.expectPeek(token.RIGHT_PAREN) {
 		return nil, errors.WithStack(UnexpectedToken(p.peekToken, "RIGHT_PAREN"))
 	}
+	// Comments before RIGHT_PAREN belong to the condition
+	appendLeadingTrailing(p.curToken, stmt.Condition.GetMeta())
 
 	if !p.expectPeek(token.LEFT_BRACE) {
 		return nil, errors.WithStack(UnexpectedToken(p.peekToken, "LEFT_BRACE"))
@@ -3,6 +3,7 @@ package parser
 import (
 	"testing"
 
+	"github.com/pkg/errors"
 	"github.com/ysugimoto/falco/ast"
 	"github.com/ysugimoto/falco/lexer"
 	"github.com/ysugimoto/falco/token"
@@ -30,6 +31,28 @@ import boltsort; // Trailing comment`
 	assert(t, vcl, expect)
 }
 
+func TestParseImportWithKnownModules(t *testing.T) {
+	t.Run("known module", func(t *testing.T) {
+		input := `import boltsort;`
+		_, err := New(lexer.NewFromString(input), WithImportModules("boltsort", "querystring")).ParseVCL()
+		if err != nil {
+			t.Errorf("%+v", err)
+		}
+	})
+
+	t.Run("unknown module", func(t *testing.T) {
+		input := `import unknown_module;`
+		_, err := New(lexer.NewFromString(input), WithImportModules("boltsort", "querystring")).ParseVCL()
+		if err == nil {
+			t.Errorf("Expected parse error but got nil")
+			return
+		}
+		if _, ok := errors.Cause(err).(*ParseError); !ok {
+			t.Errorf("Expected ParseError but got %T", errors.Cause(err))
+		}
+	})
+}
+
 func TestParseInclude(t *testing.T) {
 	t.Run("with semicolon at the end", func(t *testing.T) {
 		input := `// Leading comment
@@ -150,9 +173,11 @@ sub vcl_recv {
 								Value: &ast.InfixExpression{
 									Meta:     ast.New(T, 1),
 									Operator: "+",
+									Implicit: true,
 									Left: &ast.InfixExpression{
 										Meta:     ast.New(T, 1),
 										Operator: "+",
+										Implicit: true,
 										Left: &ast.String{
 											Meta:  ast.New(T, 1),
 											Value: "example.",
@@ -777,6 +802,57 @@ sub vcl_recv {
 		}
 		assert(t, vcl, expect)
 	})
+	t.Run("assignment operator in condition", func(t *testing.T) {
+		input := `sub vcl_recv {
+	if (req.http.Host = "example.com") {
+		restart;
+	}
+}`
+		expect := &ast.VCL{
+			Statements: []ast.Statement{
+				&ast.SubroutineDeclaration{
+					Meta: ast.New(T, 0),
+					Name: &ast.Ident{
+						Meta:  ast.New(T, 0),
+						Value: "vcl_recv",
+					},
+					Block: &ast.BlockStatement{
+						Meta: ast.New(T, 1),
+						Statements: []ast.Statement{
+							&ast.IfStatement{
+								Meta: ast.New(T, 1),
+								Condition: &ast.InfixExpression{
+									Meta:     ast.New(T, 1),
+									Operator: "=",
+									Left: &ast.Ident{
+										Meta:  ast.New(T, 1),
+										Value: "req.http.Host",
+									},
+									Right: &ast.String{
+										Meta:  ast.New(T, 1),
+										Value: "example.com",
+									},
+								},
+								Consequence: &ast.BlockStatement{
+									Meta: ast.New(T, 2),
+									Statements: []ast.Statement{
+										&ast.RestartStatement{
+											Meta: ast.New(T, 2),
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		}
+		vcl, err := New(lexer.NewFromString(input)).ParseVCL()
+		if err != nil {
+			t.Errorf("%+v", err)
+		}
+		assert(t, vcl, expect)
+	})
 }
 
 func TestParseUnsetStatement(t *testing.T) {
@@ -890,9 +966,11 @@ sub vcl_recv {
 								Value: &ast.InfixExpression{
 									Meta:     ast.New(T, 1),
 									Operator: "+",
+									Implicit: true,
 									Left: &ast.InfixExpression{
 										Meta:     ast.New(T, 1),
 										Operator: "+",
+										Implicit: true,
 										Left: &ast.String{
 											Meta:  ast.New(T, 1),
 											Value: "example",
@@ -1035,6 +1113,60 @@ sub vcl_recv {
 	assert(t, vcl, expect)
 }
 
+func TestDeclareStatementWithUnknownType(t *testing.T) {
+	t.Run("single token type", func(t *testing.T) {
+		input := `sub vcl_recv {
+	declare local var.foo BLOB;
+}`
+		p := New(lexer.NewFromString(input))
+		vcl, err := p.ParseVCL()
+		if err != nil {
+			t.Fatalf("%+v", err)
+		}
+		stmt := vcl.Statements[0].(*ast.SubroutineDeclaration).Block.Statements[0].(*ast.DeclareStatement)
+		if stmt.UnknownType == nil {
+			t.Fatalf("UnknownType should be set")
+		}
+		if stmt.UnknownType.Name != "BLOB" {
+			t.Errorf("Unexpected raw type name: %s", stmt.UnknownType.Name)
+		}
+		if len(p.Diagnostics()) != 1 {
+			t.Errorf("Expected one diagnostic, got %d", len(p.Diagnostics()))
+		}
+	})
+
+	t.Run("multiple tokens type", func(t *testing.T) {
+		input := `sub vcl_recv {
+	declare local var.foo STRING[];
+}`
+		vcl, err := New(lexer.NewFromString(input)).ParseVCL()
+		if err != nil {
+			t.Fatalf("%+v", err)
+		}
+		stmt := vcl.Statements[0].(*ast.SubroutineDeclaration).Block.Statements[0].(*ast.DeclareStatement)
+		if stmt.UnknownType == nil || stmt.UnknownType.Name != "STRING[]" {
+			t.Errorf("Unexpected unknown type: %v", stmt.UnknownType)
+		}
+	})
+
+	for _, typ := range []string{"BACKEND", "ACL", "ID"} {
+		t.Run("known type does not report: "+typ, func(t *testing.T) {
+			input := `sub vcl_recv {
+	declare local var.foo ` + typ + `;
+}`
+			p := New(lexer.NewFromString(input))
+			vcl, err := p.ParseVCL()
+			if err != nil {
+				t.Fatalf("%+v", err)
+			}
+			stmt := vcl.Statements[0].(*ast.SubroutineDeclaration).Block.Statements[0].(*ast.DeclareStatement)
+			if stmt.UnknownType != nil || len(p.Diagnostics()) != 0 {
+				t.Errorf("Known type should not be reported")
+			}
+		})
+	}
+}
+
 func TestErrorStatement(t *testing.T) {
 	t.Run("without argument", func(t *testing.T) {
 		input := `// Subroutine
@@ -1102,6 +1234,7 @@ sub vcl_recv {
 										Value: "/foobar/",
 									},
 									Operator: "+",
+									Implicit: true,
 									Right: &ast.Ident{
 										Meta:  ast.New(T, 1),
 										Value: "req.http.Foo",
@@ -1239,6 +1372,7 @@ sub vcl_recv {
 							Value: &ast.InfixExpression{
 								Meta:     ast.New(T, 1),
 								Operator: "+",
+								Implicit: true,
 								Right: &ast.Ident{
 									Meta:  ast.New(T, 1),
 									Value: "req.http.Timestamp",
@@ -1246,6 +1380,7 @@ sub vcl_recv {
 								Left: &ast.InfixExpression{
 									Meta:     ast.New(T, 1),
 									Operator: "+",
+									Implicit: true,
 									Right: &ast.String{
 										Meta:  ast.New(T, 1),
 										Value: "	timestamp:",
@@ -1253,6 +1388,7 @@ sub vcl_recv {
 									Left: &ast.InfixExpression{
 										Meta:     ast.New(T, 1),
 										Operator: "+",
+										Implicit: true,
 										Right: &ast.String{
 											Meta:  ast.New(T, 1),
 											Value: " fastly-log :: ",
@@ -1343,6 +1479,7 @@ sub vcl_recv {
 									Value: "Access ",
 								},
 								Operator: "+",
+								Implicit: true,
 								Right: &ast.String{
 									Meta:  ast.New(T, 1),
 									Value: "denied",
@@ -1388,6 +1525,7 @@ sub vcl_recv {
 									Value: "Access ",
 								},
 								Operator: "+",
+								Implicit: true,
 								Right: &ast.String{
 									Meta:  ast.New(T, 1),
 									Value: "denied",
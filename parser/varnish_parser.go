@@ -0,0 +1,64 @@
+package parser
+
+import (
+	"github.com/pkg/errors"
+	"github.com/ysugimoto/falco/ast"
+	"github.com/ysugimoto/falco/token"
+)
+
+// Varnish 4.x dialect syntax parsers, enabled via WithDialect(DialectVarnish4)
+
+func (p *Parser) isVarnish4() bool {
+	return p.option.Dialect == DialectVarnish4
+}
+
+// parseVersionStatement parses "vcl 4.0;" declaration
+func (p *Parser) parseVersionStatement() (*ast.VersionStatement, error) {
+	stmt := &ast.VersionStatement{
+		Meta: p.curToken,
+	}
+
+	if !p.expectPeek(token.FLOAT) {
+		return nil, errors.WithStack(UnexpectedToken(p.peekToken, "FLOAT"))
+	}
+	stmt.Version = p.curToken.Token.Literal
+
+	if !p.peekTokenIs(token.SEMICOLON) {
+		return nil, errors.WithStack(MissingSemicolon(p.curToken))
+	}
+	stmt.Meta.Trailing = p.trailing()
+	p.nextToken() // point to SEMICOLON
+
+	return stmt, nil
+}
+
+// parseNewStatement parses VMOD object instantiation like "new rr = directors.round_robin();"
+func (p *Parser) parseNewStatement() (*ast.NewStatement, error) {
+	stmt := &ast.NewStatement{
+		Meta: p.curToken,
+	}
+
+	if !p.expectPeek(token.IDENT) {
+		return nil, errors.WithStack(UnexpectedToken(p.peekToken, "IDENT"))
+	}
+	stmt.Name = p.parseIdent()
+
+	if !p.expectPeek(token.ASSIGN) {
+		return nil, errors.WithStack(UnexpectedToken(p.peekToken, "="))
+	}
+	p.nextToken() // point to expression start
+
+	value, err := p.parseExpression(LOWEST)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	stmt.Value = value
+
+	if !p.peekTokenIs(token.SEMICOLON) {
+		return nil, errors.WithStack(MissingSemicolon(p.curToken))
+	}
+	stmt.Meta.Trailing = p.trailing()
+	p.nextToken() // point to SEMICOLON
+
+	return stmt, nil
+}
@@ -0,0 +1,52 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/ysugimoto/falco/ast"
+	"github.com/ysugimoto/falco/lexer"
+)
+
+func TestVarnish4Dialect(t *testing.T) {
+	input := `vcl 4.0;
+import std;
+import directors;
+
+sub vcl_init {
+	new rr = directors.round_robin();
+}
+
+sub vcl_backend_response {
+	set beresp.ttl = 10s;
+}`
+
+	t.Run("parse Varnish 4.x syntax", func(t *testing.T) {
+		vcl, err := New(lexer.NewFromString(input), WithDialect(DialectVarnish4)).ParseVCL()
+		if err != nil {
+			t.Fatalf("Unexpected error: %+v", err)
+		}
+		if len(vcl.Statements) != 5 {
+			t.Fatalf("Expected 5 statements, got %d", len(vcl.Statements))
+		}
+		version, ok := vcl.Statements[0].(*ast.VersionStatement)
+		if !ok || version.Version != "4.0" {
+			t.Errorf("First statement must be version statement, got %s", vcl.Statements[0].String())
+		}
+		stmt, ok := vcl.Statements[3].(*ast.SubroutineDeclaration).Block.Statements[0].(*ast.NewStatement)
+		if !ok {
+			t.Fatalf("New statement must be parsed")
+		}
+		if stmt.Name.Value != "rr" {
+			t.Errorf("Unexpected object name: %s", stmt.Name.Value)
+		}
+		if _, ok := stmt.Value.(*ast.FunctionCallExpression); !ok {
+			t.Errorf("Object constructor must be function call expression, got %T", stmt.Value)
+		}
+	})
+
+	t.Run("Varnish 4.x syntax is error in Fastly dialect", func(t *testing.T) {
+		if _, err := New(lexer.NewFromString(input)).ParseVCL(); err == nil {
+			t.Errorf("Expected error but nil")
+		}
+	})
+}
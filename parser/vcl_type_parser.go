@@ -1,8 +1,11 @@
 package parser
 
 import (
+	"fmt"
 	"strconv"
 	"strings"
+	"time"
+	"unicode/utf8"
 
 	"github.com/pkg/errors"
 	"github.com/ysugimoto/falco/ast"
@@ -24,12 +27,98 @@ func (p *Parser) parseIP() *ast.IP {
 }
 
 func (p *Parser) parseString() *ast.String {
+	value := p.curToken.Token.Literal
+	// Only double-quoted string decodes escape sequences, long string {"..."} is kept as it is.
+	// Invalid escape is kept literally and reported by linter.
+	if p.curToken.Token.Offset == 2 {
+		value, _ = DecodeString(value) // nolint:errcheck
+	}
 	return &ast.String{
 		Meta:  p.curToken,
-		Value: p.curToken.Token.Literal,
+		Value: value,
 	}
 }
 
+// DecodeString decodes escape sequences in double-quoted string literal as Fastly does.
+// Supported escapes are "%XX", "%uXXXX" and "%u{X...}", and NUL character terminates the string.
+// Invalid escape sequence is kept literally and the first one is returned as an error.
+// https://developer.fastly.com/reference/vcl/types/string/
+func DecodeString(raw string) (string, error) {
+	var buf strings.Builder
+	var err error
+
+	for i := 0; i < len(raw); i++ {
+		if raw[i] != '%' {
+			buf.WriteByte(raw[i])
+			continue
+		}
+
+		var r rune
+		var size int
+		var ok bool
+		if i+1 < len(raw) && raw[i+1] == 'u' {
+			r, size, ok = decodeUnicodeEscape(raw[i:])
+		} else {
+			r, size, ok = decodeHexEscape(raw[i:])
+		}
+		if !ok {
+			if err == nil {
+				err = fmt.Errorf("Invalid escape sequence at offset %d in %q", i, raw)
+			}
+			buf.WriteByte(raw[i])
+			continue
+		}
+		// NUL character terminates string
+		if r == 0 {
+			break
+		}
+		if size == 3 {
+			// %XX represents a single byte
+			buf.WriteByte(byte(r))
+		} else {
+			buf.WriteRune(r)
+		}
+		i += size - 1
+	}
+
+	return buf.String(), err
+}
+
+// decodeHexEscape decodes "%XX" form
+func decodeHexEscape(s string) (rune, int, bool) {
+	if len(s) < 3 {
+		return 0, 0, false
+	}
+	v, err := strconv.ParseUint(s[1:3], 16, 8)
+	if err != nil {
+		return 0, 0, false
+	}
+	return rune(v), 3, true
+}
+
+// decodeUnicodeEscape decodes "%uXXXX" or "%u{X...}" form
+func decodeUnicodeEscape(s string) (rune, int, bool) {
+	if len(s) > 2 && s[2] == '{' {
+		end := strings.IndexByte(s, '}')
+		if end < 4 || end > 9 { // between 1 and 6 hex digits
+			return 0, 0, false
+		}
+		v, err := strconv.ParseUint(s[3:end], 16, 32)
+		if err != nil || v > utf8.MaxRune {
+			return 0, 0, false
+		}
+		return rune(v), end + 1, true
+	}
+	if len(s) < 6 {
+		return 0, 0, false
+	}
+	v, err := strconv.ParseUint(s[2:6], 16, 16)
+	if err != nil {
+		return 0, 0, false
+	}
+	return rune(v), 6, true
+}
+
 func (p *Parser) parseInteger() (*ast.Integer, error) {
 	v, err := strconv.ParseInt(p.curToken.Token.Literal, 10, 64)
 	if err != nil {
@@ -63,31 +152,43 @@ func (p *Parser) parseBoolean() *ast.Boolean {
 }
 
 func (p *Parser) parseRTime() (*ast.RTime, error) {
+	duration, err := parseDuration(p.curToken.Token.Literal)
+	if err != nil {
+		return nil, errors.WithStack(TypeConversionError(p.curToken, "RTIME"))
+	}
+	return &ast.RTime{
+		Meta:     p.curToken,
+		Value:    p.curToken.Token.Literal,
+		Duration: duration,
+	}, nil
+}
+
+// parseDuration converts RTIME literal to time.Duration.
+// https://developer.fastly.com/reference/vcl/types/rtime/
+func parseDuration(literal string) (time.Duration, error) {
 	var value string
+	var unit time.Duration
 
-	literal := p.curToken.Token.Literal
 	switch {
 	case strings.HasSuffix(literal, "ms"):
-		value = strings.TrimSuffix(literal, "ms")
+		value, unit = strings.TrimSuffix(literal, "ms"), time.Millisecond
 	case strings.HasSuffix(literal, "s"):
-		value = strings.TrimSuffix(literal, "s")
+		value, unit = strings.TrimSuffix(literal, "s"), time.Second
 	case strings.HasSuffix(literal, "m"):
-		value = strings.TrimSuffix(literal, "m")
+		value, unit = strings.TrimSuffix(literal, "m"), time.Minute
 	case strings.HasSuffix(literal, "h"):
-		value = strings.TrimSuffix(literal, "h")
+		value, unit = strings.TrimSuffix(literal, "h"), time.Hour
 	case strings.HasSuffix(literal, "d"):
-		value = strings.TrimSuffix(literal, "d")
+		value, unit = strings.TrimSuffix(literal, "d"), 24*time.Hour
 	case strings.HasSuffix(literal, "y"):
-		value = strings.TrimSuffix(literal, "y")
+		value, unit = strings.TrimSuffix(literal, "y"), 365*24*time.Hour
 	default:
-		return nil, errors.WithStack(TypeConversionError(p.curToken, "RTIME"))
+		return 0, errors.Errorf("unknown RTIME unit in %s", literal)
 	}
 
-	if _, err := strconv.ParseFloat(value, 64); err != nil {
-		return nil, errors.WithStack(TypeConversionError(p.curToken, "RTIME"))
+	v, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, errors.WithStack(err)
 	}
-	return &ast.RTime{
-		Meta:  p.curToken,
-		Value: p.curToken.Token.Literal,
-	}, nil
+	return time.Duration(v * float64(unit)), nil
 }
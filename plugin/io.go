@@ -60,6 +60,13 @@ func init() {
 	gob.Register(&ast.GroupedExpression{})
 	gob.Register(&ast.GotoStatement{})
 	gob.Register(&ast.GotoDestinationStatement{})
+	gob.Register(&ast.GenericProperty{})
+	gob.Register(&ast.Condition{})
+	gob.Register(&ast.UnknownType{})
+	gob.Register(&ast.BadStatement{})
+	gob.Register(&ast.BadExpression{})
+	gob.Register(&ast.VersionStatement{})
+	gob.Register(&ast.NewStatement{})
 	gob.Register(&ast.VCL{})
 }
 
@@ -185,6 +185,16 @@ func (c *FastlyClient) ListBackends(ctx context.Context, version int64) ([]*Back
 	return backends, nil
 }
 
+func (c *FastlyClient) ListConditions(ctx context.Context, version int64) ([]*Condition, error) {
+	endpoint := fmt.Sprintf("/service/%s/version/%d/condition", c.serviceId, version)
+	var conditions []*Condition
+	if err := c.request(ctx, endpoint, &conditions); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return conditions, nil
+}
+
 func (c *FastlyClient) ListSnippets(ctx context.Context, version int64) ([]*VCLSnippet, error) {
 	endpoint := fmt.Sprintf("/service/%s/version/%d/snippet", c.serviceId, version)
 	var snippets []*VCLSnippet
@@ -258,3 +258,36 @@ func TestListBackends(t *testing.T) {
 		t.FailNow()
 	}
 }
+
+func TestListConditions(t *testing.T) {
+	c := NewFastlyClient(&http.Client{
+		Transport: &TestRoundTripper{
+			StatusCode: 200,
+			Body: `
+[
+  {
+    "name": "internal_request",
+    "priority": "10",
+    "statement": "client.ip ~ internal",
+    "type": "REQUEST",
+    "service_id": "0yGwmmav8rcXRC7yRwzPNQ",
+    "version": "10"
+  }
+]`,
+		},
+	}, "dummy", "dummy")
+
+	items, err := c.ListConditions(context.Background(), 10)
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+		t.FailNow()
+	}
+	if len(items) != 1 {
+		t.Errorf("conditions should have 1 items but got %d", len(items))
+		t.FailNow()
+	}
+	i := items[0]
+	if i.Name != "internal_request" || i.Type != "REQUEST" || i.Statement != "client.ip ~ internal" {
+		t.Errorf("condition assertion error, got=%+v", i)
+	}
+}
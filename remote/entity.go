@@ -50,6 +50,13 @@ type Director struct {
 	Backends []string     `json:"backends"`
 }
 
+type Condition struct {
+	Name      string `json:"name"`
+	Type      string `json:"type"`
+	Statement string `json:"statement"`
+	Priority  string `json:"priority"`
+}
+
 type VCLSnippet struct {
 	Id       string  `json:"id"`
 	Name     string  `json:"name"`
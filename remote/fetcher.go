@@ -138,6 +138,37 @@ func (f *FastlyApiFetcher) Snippets() ([]*types.RemoteVCL, error) {
 	return r, nil
 }
 
+func (f *FastlyApiFetcher) Conditions() ([]*types.RemoteCondition, error) {
+	c, timeout := context.WithTimeout(_context.Background(), f.timeout)
+	defer timeout()
+
+	version, err := f.getVersion(c)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get latest version %w", err)
+	}
+
+	fastlyConditions, err := f.client.ListConditions(c, version)
+	if err != nil {
+		return nil, err
+	}
+
+	var r []*types.RemoteCondition
+	for _, v := range fastlyConditions {
+		p, err := strconv.ParseInt(v.Priority, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to convert condition priority to int: %w", err)
+		}
+
+		r = append(r, &types.RemoteCondition{
+			Name:      v.Name,
+			Type:      v.Type,
+			Statement: v.Statement,
+			Priority:  p,
+		})
+	}
+	return r, nil
+}
+
 func (f *FastlyApiFetcher) LoggingEndpoints() ([]string, error) {
 	c, timeout := context.WithTimeout(_context.Background(), f.timeout)
 	defer timeout()
@@ -14,13 +14,16 @@ type TerraformResolver struct {
 	Modules     []*VCL
 	Main        *VCL
 	ServiceName string
+	// Resource address of the service in terraform plan
+	ResourceAddress string
 }
 
 func NewTerraformResolver(services []*terraform.FastlyService) []Resolver {
 	var resolvers []Resolver
 	for _, v := range services {
 		s := &TerraformResolver{
-			ServiceName: v.Name,
+			ServiceName:     v.Name,
+			ResourceAddress: v.Address,
 		}
 		for _, vcl := range v.Vcls {
 			// Always save module names with .vcl extension
@@ -47,6 +50,10 @@ func (s *TerraformResolver) Name() string {
 	return s.ServiceName
 }
 
+func (s *TerraformResolver) Address() string {
+	return s.ResourceAddress
+}
+
 func (s *TerraformResolver) MainVCL() (*VCL, error) {
 	return s.Main, nil
 }
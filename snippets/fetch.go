@@ -17,6 +17,7 @@ type Fetcher interface {
 	Dictionaries() ([]*types.RemoteDictionary, error)
 	Acls() ([]*types.RemoteAcl, error)
 	Snippets() ([]*types.RemoteVCL, error)
+	Conditions() ([]*types.RemoteCondition, error)
 	LoggingEndpoints() ([]string, error)
 }
 
@@ -45,6 +46,10 @@ func Fetch(fetcher Fetcher) (*Snippets, error) {
 		snippets.ScopedSnippets, snippets.IncludeSnippets, err = fetchVCLSnippets(fetcher)
 		return err
 	})
+	eg.Go(func() (err error) {
+		snippets.Conditions, err = fetchConditions(fetcher)
+		return err
+	})
 
 	if err := eg.Wait(); err != nil {
 		fmt.Println("Error!")
@@ -229,3 +234,25 @@ func fetchVCLSnippets(fetcher Fetcher) (
 
 	return scoped, include, nil
 }
+
+func fetchConditions(fetcher Fetcher) ([]ConditionItem, error) {
+	conditions, err := fetcher.Conditions()
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get conditions: %w", err)
+	}
+
+	// Sort by priority as same as VCL snippets
+	sort.Slice(conditions, func(i, j int) bool {
+		return conditions[i].Priority > conditions[j].Priority
+	})
+
+	var items []ConditionItem
+	for _, c := range conditions {
+		items = append(items, ConditionItem{
+			Name: c.Name,
+			Type: c.Type,
+			Data: c.Statement,
+		})
+	}
+	return items, nil
+}
@@ -5,12 +5,20 @@ type SnippetItem struct {
 	Name string
 }
 
+// ConditionItem is Fastly condition, Data is a single condition expression which is parsed with the condition type
+type ConditionItem struct {
+	Data string
+	Name string
+	Type string
+}
+
 type Snippets struct {
 	Dictionaries     []SnippetItem
 	Acls             []SnippetItem
 	Backends         []SnippetItem
 	ScopedSnippets   map[string][]SnippetItem
 	IncludeSnippets  map[string]SnippetItem
+	Conditions       []ConditionItem
 	LoggingEndpoints map[string]struct{}
 }
 
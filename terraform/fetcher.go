@@ -5,8 +5,9 @@ import (
 )
 
 type TerraformFetcher struct {
-	services    []*FastlyService
-	currentName string
+	services       []*FastlyService
+	currentName    string
+	currentAddress string
 }
 
 func NewTerraformFetcher(s []*FastlyService) *TerraformFetcher {
@@ -19,7 +20,21 @@ func (f *TerraformFetcher) SetName(name string) {
 	f.currentName = name
 }
 
+// SetAddress sets resource address to filter service.
+// Address takes precedence over the name because multiple services could have the same name, e.g. for_each instances.
+func (f *TerraformFetcher) SetAddress(address string) {
+	f.currentAddress = address
+}
+
 func (f *TerraformFetcher) filterService() []*FastlyService {
+	if f.currentAddress != "" {
+		for _, s := range f.services {
+			if s.Address == f.currentAddress {
+				return []*FastlyService{s}
+			}
+		}
+		return []*FastlyService{}
+	}
 	if f.currentName == "" {
 		return f.services
 	}
@@ -84,6 +99,21 @@ func (f *TerraformFetcher) Snippets() ([]*types.RemoteVCL, error) {
 	return v, nil
 }
 
+func (f *TerraformFetcher) Conditions() ([]*types.RemoteCondition, error) {
+	var c []*types.RemoteCondition
+	for _, s := range f.filterService() {
+		for _, cond := range s.Conditions {
+			c = append(c, &types.RemoteCondition{
+				Name:      cond.Name,
+				Type:      cond.Type,
+				Statement: cond.Statement,
+				Priority:  cond.Priority,
+			})
+		}
+	}
+	return c, nil
+}
+
 func (f *TerraformFetcher) LoggingEndpoints() ([]string, error) {
 	var v []string
 	for _, s := range f.filterService() {
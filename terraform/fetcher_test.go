@@ -52,3 +52,29 @@ func TestStuff(t *testing.T) {
 		}
 	}
 }
+
+func TestFilterServiceByAddress(t *testing.T) {
+	buf, err := os.ReadFile("./data/terraform-modules-nested.json")
+	if err != nil {
+		t.Fatalf("Unexpected error %s", err)
+	}
+	services, err := UnmarshalTerraformPlannedInput(buf)
+	if err != nil {
+		t.Fatalf("Unexpected error %s", err)
+	}
+
+	f := NewTerraformFetcher(services)
+	// Both for_each instances have the same name, then address identifies the service
+	f.SetName("cdn-service")
+	f.SetAddress(`module.cdn.fastly_service_vcl.this["production"]`)
+	if filtered := f.filterService(); len(filtered) != 1 || filtered[0] != services[2] {
+		t.Errorf("Unexpected filtered services %v", filtered)
+	}
+
+	f.SetName("edge-service")
+	f.SetAddress("module.cdn.module.edge.fastly_service_vcl.edge")
+	snippets, _ := f.Snippets()
+	if len(snippets) != 1 || snippets[0].Name != "redirects" {
+		t.Errorf("Unexpected snippets %v", snippets)
+	}
+}
@@ -10,6 +10,7 @@ const (
 	fastlyTerraformProviderName = "registry.terraform.io/fastly/fastly"
 	fastlyVCLServiceType        = "fastly_service_vcl"
 	fastlyVCLServiceTypeV1      = "fastly_service_v1"
+	fastlyDynamicSnippetType    = "fastly_service_dynamic_snippet_content"
 )
 
 // Terraform planned input struct
@@ -35,6 +36,27 @@ type TerraformSnippet struct {
 	Priority int64
 }
 
+// TerraformDynamicSnippet is declared in "dynamicsnippet" block of the service.
+// Its content is managed by separated fastly_service_dynamic_snippet_content resource.
+type TerraformDynamicSnippet struct {
+	Name      string `json:"name"`
+	Type      string `json:"type"`
+	Priority  int64  `json:"priority"`
+	SnippetID string `json:"snippet_id"`
+}
+
+type TerraformDynamicSnippetContent struct {
+	SnippetID string `json:"snippet_id"`
+	Content   string `json:"content"`
+}
+
+type TerraformCondition struct {
+	Name      string
+	Type      string
+	Statement string
+	Priority  int64
+}
+
 type TerraformLoggingEndpoint struct {
 	Name string
 }
@@ -48,12 +70,16 @@ type TerraformBackend struct {
 }
 
 type FastlyService struct {
-	Name             string
+	Name string
+	// Resource address in the plan like module.cdn.fastly_service_vcl.this["production"],
+	// which identifies the service even if multiple services have the same name
+	Address          string
 	Vcls             []*TerraformVcl
 	Backends         []*TerraformBackend
 	Acls             []*TerraformAcl
 	Dictionaries     []*TerraformDictionary
 	Snippets         []*TerraformSnippet
+	Conditions       []*TerraformCondition
 	LoggingEndpoints []string
 }
 
@@ -64,6 +90,9 @@ type FastlyServiceValues struct {
 	Backend    []*TerraformBackend    `json:"backend"`
 	Dictionary []*TerraformDictionary `json:"dictionary"`
 	Snippets   []*TerraformSnippet    `json:"snippet"`
+	Conditions []*TerraformCondition  `json:"condition"`
+
+	DynamicSnippets []*TerraformDynamicSnippet `json:"dynamicsnippet"`
 
 	// Various kinds of realtime logging endpoints
 	LoggingBigQuerty     []*TerraformLoggingEndpoint `json:"logging_bigqeury"`
@@ -94,19 +123,24 @@ type FastlyServiceValues struct {
 }
 
 type TerraformPlannedResource struct {
+	Address      string          `json:"address"`
 	ProviderName string          `json:"provider_name"`
 	Type         string          `json:"type"`
+	Index        json.RawMessage `json:"index"`
 	Values       json.RawMessage `json:"values"`
 }
 
+// TerraformPlannedModule is root module or child module in the plan.
+// Child modules could be nested when the module calls other modules.
+type TerraformPlannedModule struct {
+	Address      string                      `json:"address"`
+	Resources    []*TerraformPlannedResource `json:"resources"`
+	ChildModules []*TerraformPlannedModule   `json:"child_modules"`
+}
+
 type TerraformPlannedInput struct {
 	PlannedValues *struct {
-		RootModule *struct {
-			Resources    []*TerraformPlannedResource `json:"resources"`
-			ChildModules []*struct {
-				Resources []*TerraformPlannedResource `json:"resources"`
-			} `json:"child_modules"`
-		} `json:"root_module"`
+		RootModule *TerraformPlannedModule `json:"root_module"`
 	} `json:"planned_values"`
 }
 
@@ -125,59 +159,135 @@ func UnmarshalTerraformPlannedInput(buf []byte) ([]*FastlyService, error) {
 		return nil, errors.New(`Input does not seem to terraform planned JSON: "root_module" field does not exist`)
 	}
 
+	// Services could be declared in root module, child modules and also be expanded by count or for_each
+	services, err := factoryServices(root.PlannedValues.RootModule)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(services) == 0 {
+		return nil, errors.New(`Fastly service does not exist. Did you plan with fastly terraform provider?`)
+	}
+
+	return services, nil
+}
+
+// factoryServices collects services in the module and its child modules recursively
+func factoryServices(module *TerraformPlannedModule) ([]*FastlyService, error) {
 	var services []*FastlyService
-	var serviceValues *FastlyServiceValues
-	// Case: service is declared in root module
-	if len(root.PlannedValues.RootModule.Resources) > 0 {
-		for _, v := range root.PlannedValues.RootModule.Resources {
-			if !isFastlyVCLServiceResource(v) {
-				continue
-			}
+	var contents []*TerraformPlannedResource
+	declared := make(map[*FastlyService][]*TerraformDynamicSnippet)
 
-			if err := json.Unmarshal(v.Values, &serviceValues); err != nil {
-				return nil, errors.Wrap(err, "Failed to unmarshal values")
-			}
+	for _, v := range module.Resources {
+		if isFastlyDynamicSnippetResource(v) {
+			contents = append(contents, v)
+			continue
+		}
+		if !isFastlyVCLServiceResource(v) {
+			continue
+		}
+
+		var serviceValues *FastlyServiceValues
+		if err := json.Unmarshal(v.Values, &serviceValues); err != nil {
+			return nil, errors.Wrapf(err, "Failed to unmarshal values of %s", v.Address)
+		}
+
+		service := &FastlyService{
+			Name:             serviceValues.Name,
+			Address:          v.Address,
+			Vcls:             serviceValues.Vcl,
+			Acls:             serviceValues.Acl,
+			Backends:         serviceValues.Backend,
+			Dictionaries:     serviceValues.Dictionary,
+			Snippets:         serviceValues.Snippets,
+			Conditions:       serviceValues.Conditions,
+			LoggingEndpoints: factoryLoggingEndpoints(serviceValues),
+		}
+		declared[service] = serviceValues.DynamicSnippets
+		services = append(services, service)
+	}
 
-			services = append(services, &FastlyService{
-				Name:             serviceValues.Name,
-				Vcls:             serviceValues.Vcl,
-				Acls:             serviceValues.Acl,
-				Backends:         serviceValues.Backend,
-				Dictionaries:     serviceValues.Dictionary,
-				Snippets:         serviceValues.Snippets,
-				LoggingEndpoints: factoryLoggingEndpoints(serviceValues),
+	// Dynamic snippet contents are declared as separated resources in the same module
+	snippetContents, err := factoryDynamicSnippetContents(contents)
+	if err != nil {
+		return nil, err
+	}
+	for _, service := range services {
+		for _, d := range declared[service] {
+			service.Snippets = append(service.Snippets, &TerraformSnippet{
+				Name:     d.Name,
+				Type:     d.Type,
+				Priority: d.Priority,
+				Content:  findDynamicSnippetContent(d, snippetContents, declared),
 			})
 		}
 	}
 
-	// Case: service is declared in child module
-	for _, v := range root.PlannedValues.RootModule.ChildModules {
-		for _, v := range v.Resources {
-			if !isFastlyVCLServiceResource(v) {
-				continue
-			}
+	for _, v := range module.ChildModules {
+		s, err := factoryServices(v)
+		if err != nil {
+			return nil, err
+		}
+		services = append(services, s...)
+	}
+	return services, nil
+}
 
-			if err := json.Unmarshal(v.Values, &serviceValues); err != nil {
-				return nil, errors.Wrap(err, "Failed to unmarshal values")
-			}
+type dynamicSnippetContent struct {
+	TerraformDynamicSnippetContent
+	// Key of for_each instance, typically the snippet name
+	IndexKey string
+}
 
-			services = append(services, &FastlyService{
-				Name:             serviceValues.Name,
-				Vcls:             serviceValues.Vcl,
-				Acls:             serviceValues.Acl,
-				Backends:         serviceValues.Backend,
-				Dictionaries:     serviceValues.Dictionary,
-				Snippets:         serviceValues.Snippets,
-				LoggingEndpoints: factoryLoggingEndpoints(serviceValues),
-			})
+func factoryDynamicSnippetContents(resources []*TerraformPlannedResource) ([]*dynamicSnippetContent, error) {
+	var contents []*dynamicSnippetContent
+	for _, v := range resources {
+		c := &dynamicSnippetContent{}
+		if err := json.Unmarshal(v.Values, &c.TerraformDynamicSnippetContent); err != nil {
+			return nil, errors.Wrapf(err, "Failed to unmarshal values of %s", v.Address)
+		}
+		// Index is a number for count instances, then it is not a key
+		if len(v.Index) > 0 {
+			json.Unmarshal(v.Index, &c.IndexKey) // nolint:errcheck
 		}
+		contents = append(contents, c)
 	}
+	return contents, nil
+}
 
-	if len(services) == 0 {
-		return nil, errors.New(`Fastly service does not exist. Did you plan with fastly terraform provider?`)
+// findDynamicSnippetContent finds the content of dynamic snippet.
+// The snippet id is unknown on planning a new service, then the content is found by for_each key
+// which is the snippet name like the example of Fastly provider, only when the name is unique in the module.
+func findDynamicSnippetContent(
+	snippet *TerraformDynamicSnippet,
+	contents []*dynamicSnippetContent,
+	declared map[*FastlyService][]*TerraformDynamicSnippet,
+) string {
+	if snippet.SnippetID != "" {
+		for _, c := range contents {
+			if c.SnippetID == snippet.SnippetID {
+				return c.Content
+			}
+		}
 	}
 
-	return services, nil
+	var count int
+	for _, snippets := range declared {
+		for _, d := range snippets {
+			if d.Name == snippet.Name {
+				count++
+			}
+		}
+	}
+	if count != 1 {
+		return ""
+	}
+	for _, c := range contents {
+		if c.SnippetID == "" && c.IndexKey == snippet.Name {
+			return c.Content
+		}
+	}
+	return ""
 }
 
 func isFastlyVCLServiceResource(r *TerraformPlannedResource) bool {
@@ -185,6 +295,10 @@ func isFastlyVCLServiceResource(r *TerraformPlannedResource) bool {
 		(r.Type == fastlyVCLServiceType || r.Type == fastlyVCLServiceTypeV1)
 }
 
+func isFastlyDynamicSnippetResource(r *TerraformPlannedResource) bool {
+	return r.ProviderName == fastlyTerraformProviderName && r.Type == fastlyDynamicSnippetType
+}
+
 func factoryLoggingEndpoints(values *FastlyServiceValues) []string {
 	var endpoints []string
 	for _, v := range values.LoggingBigQuerty {
@@ -33,6 +33,10 @@ func TestUnmarshallValidTfJson(t *testing.T) {
 	if services[0].Dictionaries[0].Name != "foo_dictionary" {
 		t.Errorf("Dictionary name want %s, got %s", services[0].Dictionaries[0].Name, "foo_dictionary")
 	}
+
+	if c := services[0].Conditions[0]; c.Name != "internal_request" || c.Type != "REQUEST" || c.Statement != "client.ip ~ foo_acl" {
+		t.Errorf("Unexpected condition: %+v", c)
+	}
 }
 
 func TestUnmarshallInValidTfJson(t *testing.T) {
@@ -48,3 +52,52 @@ func TestUnmarshallInValidTfJson(t *testing.T) {
 		t.Fatalf("Expected error when unarshalling tf %s ", fileName)
 	}
 }
+
+func TestUnmarshallNestedModulesTfJson(t *testing.T) {
+	fileName := "./data/terraform-modules-nested.json"
+	buf, err := os.ReadFile(fileName)
+	if err != nil {
+		t.Fatalf("Unexpected error %s reading file %s ", fileName, err)
+	}
+
+	services, err := UnmarshalTerraformPlannedInput(buf)
+	if err != nil {
+		t.Fatalf("Unexpected error %s unarshalling %s ", fileName, err)
+	}
+
+	expects := []struct {
+		name    string
+		address string
+	}{
+		{name: "root-service", address: "fastly_service_vcl.root"},
+		{name: "cdn-service", address: `module.cdn.fastly_service_vcl.this["staging"]`},
+		{name: "cdn-service", address: `module.cdn.fastly_service_vcl.this["production"]`},
+		{name: "edge-service", address: "module.cdn.module.edge.fastly_service_vcl.edge"},
+	}
+	if len(services) != len(expects) {
+		t.Fatalf("Length of services should be %d, got %d", len(expects), len(services))
+	}
+	for i, e := range expects {
+		if services[i].Name != e.name {
+			t.Errorf("Service name want %s, got %s", e.name, services[i].Name)
+		}
+		if services[i].Address != e.address {
+			t.Errorf("Service address want %s, got %s", e.address, services[i].Address)
+		}
+	}
+
+	// Dynamic snippet content is found by snippet id
+	if len(services[0].Snippets) != 1 {
+		t.Fatalf("Length of snippets should be %d, got %d", 1, len(services[0].Snippets))
+	}
+	if s := services[0].Snippets[0]; s.Name != "blocklist" || s.Type != "recv" || s.Priority != 100 || s.Content == "" {
+		t.Errorf("Unexpected dynamic snippet %+v", s)
+	}
+	// Dynamic snippet content is found by for_each key when snippet id is unknown on planning
+	if len(services[3].Snippets) != 1 {
+		t.Fatalf("Length of snippets should be %d, got %d", 1, len(services[3].Snippets))
+	}
+	if s := services[3].Snippets[0]; s.Name != "redirects" || s.Content != "set req.http.X-Redirect = \"1\";\n" {
+		t.Errorf("Unexpected dynamic snippet %+v", s)
+	}
+}
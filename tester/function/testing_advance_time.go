@@ -0,0 +1,38 @@
+package function
+
+import (
+	"github.com/ysugimoto/falco/interpreter/context"
+	"github.com/ysugimoto/falco/interpreter/function/errors"
+	"github.com/ysugimoto/falco/interpreter/value"
+)
+
+const Testing_advance_time_Name = "testing.advance_time"
+
+func Testing_advance_time_Validate(args []value.Value) error {
+	if len(args) != 1 {
+		return errors.ArgumentNotEnough(Testing_advance_time_Name, 1, args)
+	}
+	return nil
+}
+
+// Testing_advance_time moves the clock forward by the RTIME argument,
+// so that cache expiration could be tested without waiting
+func Testing_advance_time(
+	ctx *context.Context,
+	args ...value.Value,
+) (value.Value, error) {
+
+	if err := Testing_advance_time_Validate(args); err != nil {
+		return nil, errors.NewTestingError(err.Error())
+	}
+
+	if args[0].Type() != value.RTimeType {
+		return value.Null, errors.NewTestingError(
+			"First argument of %s must be RTIME type, %s provided",
+			Testing_advance_time_Name,
+			args[0].Type(),
+		)
+	}
+	manualClock(ctx).Advance(value.Unwrap[*value.RTime](args[0]).Value)
+	return value.Null, nil
+}
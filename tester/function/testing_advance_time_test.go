@@ -0,0 +1,42 @@
+package function
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ysugimoto/falco/interpreter/context"
+	"github.com/ysugimoto/falco/interpreter/value"
+)
+
+func Test_advance_time(t *testing.T) {
+	t.Run("Advance frozen clock", func(t *testing.T) {
+		c := &context.Context{}
+		if _, err := Testing_freeze_time(c, &value.Integer{Value: 1694159940}); err != nil {
+			t.Errorf("Unexpected error on Testing_freeze_time, %s", err)
+			return
+		}
+		if _, err := Testing_advance_time(c, &value.RTime{Value: time.Hour}); err != nil {
+			t.Errorf("Unexpected error on Testing_advance_time, %s", err)
+			return
+		}
+		if c.Now().Unix() != 1694159940+3600 {
+			t.Errorf("Expected clock to be advanced, got %s", c.Now())
+		}
+	})
+	t.Run("Advance running clock", func(t *testing.T) {
+		c := &context.Context{}
+		if _, err := Testing_advance_time(c, &value.RTime{Value: time.Hour}); err != nil {
+			t.Errorf("Unexpected error on Testing_advance_time, %s", err)
+			return
+		}
+		if d := time.Until(c.Now()); d < 59*time.Minute {
+			t.Errorf("Expected clock to be advanced by an hour, got %s", d)
+		}
+	})
+	t.Run("Invalid argument type", func(t *testing.T) {
+		c := &context.Context{}
+		if _, err := Testing_advance_time(c, &value.Integer{Value: 3600}); err == nil {
+			t.Errorf("Expected error but nil")
+		}
+	})
+}
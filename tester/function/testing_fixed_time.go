@@ -3,6 +3,7 @@ package function
 import (
 	"time"
 
+	"github.com/ysugimoto/falco/interpreter/clock"
 	"github.com/ysugimoto/falco/interpreter/context"
 	"github.com/ysugimoto/falco/interpreter/function/errors"
 	"github.com/ysugimoto/falco/interpreter/value"
@@ -28,27 +29,45 @@ func Testing_fixed_time(
 		return nil, errors.NewTestingError(err.Error())
 	}
 
-	switch args[0].Type() {
+	t, err := argumentTime(Testing_fixed_time_Name, args[0])
+	if err != nil {
+		return value.Null, err
+	}
+	manualClock(ctx).Freeze(t)
+	return value.Null, nil
+}
+
+// argumentTime converts INTEGER, TIME or STRING argument to the time
+func argumentTime(name string, arg value.Value) (time.Time, error) {
+	switch arg.Type() {
 	case value.IntegerType:
-		v := value.Unwrap[*value.Integer](args[0])
-		t := time.Unix(v.Value, 0)
-		ctx.FixedTime = &t
+		v := value.Unwrap[*value.Integer](arg)
+		return time.Unix(v.Value, 0), nil
 	case value.TimeType:
-		t := value.Unwrap[*value.Time](args[0]).Value
-		ctx.FixedTime = &t
+		return value.Unwrap[*value.Time](arg).Value, nil
 	case value.StringType:
-		fixed := value.Unwrap[*value.String](args[0]).Value
+		fixed := value.Unwrap[*value.String](arg).Value
 		ft, err := time.Parse(expectedTimeFormat, fixed)
 		if err != nil {
-			return value.Null, errors.NewTestingError("Invalid time format: %s", err)
+			return time.Time{}, errors.NewTestingError("Invalid time format: %s", err)
 		}
-		ctx.FixedTime = &ft
+		return ft, nil
 	default:
-		return value.Null, errors.NewTestingError(
+		return time.Time{}, errors.NewTestingError(
 			"First argument of %s must be INTEGER or TIME or STRING type, %s provided",
-			Testing_fixed_time_Name,
-			args[0].Type(),
+			name,
+			arg.Type(),
 		)
 	}
-	return value.Null, nil
+}
+
+// manualClock returns the controllable clock of the context.
+// The clock is replaced if the context does not have it yet.
+func manualClock(ctx *context.Context) *clock.Manual {
+	if m, ok := ctx.Clock.(*clock.Manual); ok {
+		return m
+	}
+	m := clock.NewManual()
+	ctx.Clock = m
+	return m
 }
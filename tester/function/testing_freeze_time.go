@@ -0,0 +1,38 @@
+package function
+
+import (
+	"github.com/ysugimoto/falco/interpreter/context"
+	"github.com/ysugimoto/falco/interpreter/function/errors"
+	"github.com/ysugimoto/falco/interpreter/value"
+)
+
+const Testing_freeze_time_Name = "testing.freeze_time"
+
+func Testing_freeze_time_Validate(args []value.Value) error {
+	if len(args) > 1 {
+		return errors.ArgumentNotInRange(Testing_freeze_time_Name, 0, 1, args)
+	}
+	return nil
+}
+
+// Testing_freeze_time stops the clock at the time of the argument, or the current time if omitted.
+// The clock could be moved forward by testing.advance_time
+func Testing_freeze_time(
+	ctx *context.Context,
+	args ...value.Value,
+) (value.Value, error) {
+
+	if err := Testing_freeze_time_Validate(args); err != nil {
+		return nil, errors.NewTestingError(err.Error())
+	}
+
+	t := ctx.Now()
+	if len(args) > 0 {
+		var err error
+		if t, err = argumentTime(Testing_freeze_time_Name, args[0]); err != nil {
+			return value.Null, err
+		}
+	}
+	manualClock(ctx).Freeze(t)
+	return value.Null, nil
+}
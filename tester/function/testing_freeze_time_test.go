@@ -0,0 +1,41 @@
+package function
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ysugimoto/falco/interpreter/context"
+	"github.com/ysugimoto/falco/interpreter/value"
+)
+
+func Test_freeze_time(t *testing.T) {
+	t.Run("Freeze at current time", func(t *testing.T) {
+		c := &context.Context{}
+		if _, err := Testing_freeze_time(c); err != nil {
+			t.Errorf("Unexpected error on Testing_freeze_time, %s", err)
+			return
+		}
+		frozen := c.Now()
+		time.Sleep(10 * time.Millisecond)
+		if !c.Now().Equal(frozen) {
+			t.Errorf("Expected clock to be frozen, frozen=%s, now=%s", frozen, c.Now())
+		}
+	})
+	t.Run("Freeze at provided time", func(t *testing.T) {
+		c := &context.Context{}
+		if _, err := Testing_freeze_time(c, &value.Integer{Value: 1694159940}); err != nil {
+			t.Errorf("Unexpected error on Testing_freeze_time, %s", err)
+			return
+		}
+		if c.Now().Unix() != 1694159940 {
+			t.Errorf("Expected clock to be frozen at provided time, got %s", c.Now())
+		}
+	})
+	t.Run("Too many arguments", func(t *testing.T) {
+		c := &context.Context{}
+		_, err := Testing_freeze_time(c, &value.Integer{Value: 0}, &value.Integer{Value: 0})
+		if err == nil {
+			t.Errorf("Expected error but nil")
+		}
+	})
+}
@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 
@@ -140,7 +141,11 @@ func (t *Tester) run(testFile string) (*TestResult, error) {
 				return
 			}
 			suite, scopes := t.findTestSuites(sub)
+			seed, seeded := t.findRandomSeed(sub)
 			for _, s := range scopes {
+				if seeded {
+					t.interpreter.TestRandomSeed(seed)
+				}
 				start := time.Now()
 				err := t.interpreter.ProcessTestSubroutine(s, sub)
 				cases = append(cases, &TestCase{
@@ -185,6 +190,9 @@ func (t *Tester) findTestSuites(sub *ast.SubroutineDeclaration) (string, []icont
 			suiteName = strings.TrimPrefix(l, "@suite:")
 			continue
 		}
+		if strings.HasPrefix(l, "@seed:") {
+			continue
+		}
 		var an []string
 		if strings.HasPrefix(l, "@scope:") {
 			an = strings.Split(strings.TrimPrefix(l, "@scope:"), ",")
@@ -225,3 +233,22 @@ func (t *Tester) findTestSuites(sub *ast.SubroutineDeclaration) (string, []icont
 
 	return suiteName, scopes
 }
+
+// findRandomSeed finds the random seed from @seed annotation, or the configured seed.
+// Random values are seeded in each test case so that the results are reproducible.
+func (t *Tester) findRandomSeed(sub *ast.SubroutineDeclaration) (int64, bool) {
+	comments := sub.GetMeta().Leading
+	for i := range comments {
+		l := strings.TrimLeft(comments[i].Value, " */#")
+		if !strings.HasPrefix(l, "@seed:") {
+			continue
+		}
+		if seed, err := strconv.ParseInt(strings.TrimSpace(strings.TrimPrefix(l, "@seed:")), 10, 64); err == nil {
+			return seed, true
+		}
+	}
+	if t.config.Seed != 0 {
+		return t.config.Seed, true
+	}
+	return 0, false
+}
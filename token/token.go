@@ -14,6 +14,10 @@ type Token struct {
 	Offset   int    // for print problem
 	File     string // for print problem
 	Snippet  bool
+	// Unterminated is true when string or block comment reaches EOF without the terminator
+	Unterminated bool
+	// Placeholder is true when the identifier is a template placeholder
+	Placeholder bool
 }
 
 func (t Token) String() string {
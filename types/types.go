@@ -305,3 +305,12 @@ type RemoteVCL struct {
 	Content  string
 	Priority int64
 }
+
+// RemoteCondition is Fastly condition which is configured via UI, API or terraform.
+// Statement is a single condition expression and Type is one of REQUEST, CACHE, RESPONSE or PREFETCH
+type RemoteCondition struct {
+	Name      string
+	Type      string
+	Statement string
+	Priority  int64
+}